@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/ralph/internal/bisect"
+	"github.com/yarlson/ralph/internal/color"
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func newBisectCmd() *cobra.Command {
+	var check string
+
+	cmd := &cobra.Command{
+		Use:   "bisect",
+		Short: "Find the iteration that introduced a regression",
+		Long: `Check out each iteration's result commit and run a check there, reporting
+the first iteration where the check started failing.
+
+The latest iteration is checked first. If the check fails there, earlier
+iterations are checked in order, oldest first, until one fails. The check
+runs with sh -c from the repository root and fails on a non-zero exit.
+The original branch is checked out again when bisect finishes.
+
+Examples:
+  ralph bisect --check "go test ./..."
+  ralph bisect --check "npm run lint"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBisect(cmd, check)
+		},
+	}
+
+	cmd.Flags().StringVar(&check, "check", "", "shell command that passes when the regression is absent")
+	_ = cmd.MarkFlagRequired("check")
+
+	return cmd
+}
+
+func runBisect(cmd *cobra.Command, check string) error {
+	if check == "" {
+		return errors.New("--check must not be empty")
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	records, err := loop.LoadAllIterationRecords(state.LogsDirPath(workDir))
+	if err != nil {
+		return fmt.Errorf("failed to load iteration records: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	p := color.New(color.Enabled(out, noColor))
+	_, _ = fmt.Fprintf(out, "Bisecting %d iteration(s) with: %s\n", len(bisect.Iterations(records)), check)
+
+	result, err := bisect.Run(cmd.Context(), git.NewShellManager(workDir, ""), verifier.NewCommandRunner(workDir),
+		[]string{"sh", "-c", check}, records, func(step bisect.Step) {
+			symbol := "✓ passes"
+			if !step.Result.Passed {
+				symbol = "✗ fails"
+			}
+			_, _ = fmt.Fprint(out, p.Line(fmt.Sprintf("%s at %s  %s (%s)\n",
+				symbol, shortCommit(step.Record.ResultCommit), step.Record.IterationID, step.Record.TaskID)))
+		})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(out)
+	if result.FirstBad == nil {
+		_, _ = fmt.Fprintln(out, "The check passes at the latest iteration; there is no regression to find.")
+		return nil
+	}
+
+	bad := result.FirstBad
+	_, _ = fmt.Fprintf(out, "First failing iteration: %s\n", p.Red(bad.IterationID))
+	_, _ = fmt.Fprintf(out, "  Task:   %s\n", bad.TaskID)
+	_, _ = fmt.Fprintf(out, "  Commit: %s\n", bad.ResultCommit)
+	if result.LastGood != nil {
+		_, _ = fmt.Fprintf(out, "Last passing iteration: %s (%s)\n", p.Green(result.LastGood.IterationID), shortCommit(result.LastGood.ResultCommit))
+	} else {
+		_, _ = fmt.Fprintln(out, "The check already fails at the first iteration.")
+	}
+
+	for _, step := range result.Steps {
+		if step.Record == bad {
+			_, _ = fmt.Fprintf(out, "\nCheck output:\n%s\n", verifier.TrimOutput(step.Result.Output, verifier.DefaultTrimOptions()))
+		}
+	}
+	return nil
+}
+
+// shortCommit abbreviates a commit hash for display.
+func shortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/loop"
+)
+
+func TestBisectCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-b", "main")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	git("config", "commit.gpgsign", "false")
+
+	logsDir := filepath.Join(tmpDir, ".ralph", "logs")
+	require.NoError(t, os.MkdirAll(logsDir, 0755))
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, file := range []string{"a.txt", "broken.txt", "c.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, file), []byte(file), 0644))
+		git("add", file)
+		git("commit", "-m", "add "+file)
+
+		record := &loop.IterationRecord{
+			IterationID:  fmt.Sprintf("iter%d", i+1),
+			TaskID:       fmt.Sprintf("task-%d", i+1),
+			StartTime:    start.Add(time.Duration(i) * time.Minute),
+			ResultCommit: git("rev-parse", "HEAD"),
+			Outcome:      loop.OutcomeSuccess,
+		}
+		_, err := loop.SaveRecord(logsDir, record)
+		require.NoError(t, err)
+	}
+
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(tmpDir))
+
+	t.Run("reports the first failing iteration", func(t *testing.T) {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"bisect", "--check", "test ! -f broken.txt", "--no-color"})
+
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, out.String(), "First failing iteration: iter2")
+		assert.Contains(t, out.String(), "Task:   task-2")
+		assert.Contains(t, out.String(), "Last passing iteration: iter1")
+		assert.Equal(t, "main", git("rev-parse", "--abbrev-ref", "HEAD"))
+	})
+
+	t.Run("reports no regression when the latest iteration passes", func(t *testing.T) {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"bisect", "--check", "test -f a.txt", "--no-color"})
+
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, out.String(), "there is no regression to find")
+	})
+
+	t.Run("requires --check", func(t *testing.T) {
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"bisect"})
+
+		require.Error(t, cmd.Execute())
+	})
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
+)
+
+func newBudgetCmd() *cobra.Command {
+	var reset bool
+
+	cmd := &cobra.Command{
+		Use:   "budget",
+		Short: "Show or reset the persisted budget, or change the budget of a running loop",
+		Long: `Show the iterations, cost and tokens spent on the parent task across runs.
+These totals are kept in .ralph/state/budget.json when budget.persist is
+enabled, and every run counts them against its limits. --reset zeros them so
+the next run starts a fresh budget.
+
+Examples:
+  ralph budget
+  ralph budget --reset
+  ralph budget set --max-cost 10`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBudget(cmd, reset)
+		},
+	}
+
+	cmd.Flags().BoolVar(&reset, "reset", false, "zero the totals persisted for the parent task")
+	cmd.AddCommand(newBudgetSetCmd())
+
+	return cmd
+}
+
+func runBudget(cmd *cobra.Command, reset bool) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	parentIDBytes, err := os.ReadFile(filepath.Join(workDir, config.DefaultParentIDFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("parent-task-id file not found. Run 'ralph init' first")
+		}
+		return fmt.Errorf("failed to read parent-task-id: %w", err)
+	}
+	parentTaskID := strings.TrimSpace(string(parentIDBytes))
+	stateDir := state.StateDirPath(workDir)
+
+	out := cmd.OutOrStdout()
+	if reset {
+		if err := loop.ResetPersistedBudget(stateDir, parentTaskID); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "Budget reset for %s; the next run starts from zero.\n", parentTaskID)
+		return nil
+	}
+
+	spent, err := loop.LoadPersistedBudget(stateDir, parentTaskID)
+	if err != nil {
+		return err
+	}
+	if spent == nil {
+		_, _ = fmt.Fprintf(out, "No budget persisted for %s (enable budget.persist to carry totals across runs).\n", parentTaskID)
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "Spent on %s across runs: %d iterations, $%.2f, %d input tokens, %d output tokens\n",
+		parentTaskID, spent.Iterations, spent.TotalCostUSD, spent.InputTokens, spent.OutputTokens)
+	return nil
+}
+
+func newBudgetSetCmd() *cobra.Command {
+	var (
+		maxCost       float64
+		maxIterations int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Raise or lower the limits of the running loop",
+		Long: `Change the cost or iteration limit of a loop that is already running, without
+restarting it. The new limit is written to .ralph/state/budget-override.json and
+picked up at the loop's next budget check, before its next iteration. Raising
+the limit lets a run that is close to finishing carry on; lowering it below
+what has been spent stops the run at the next check. 0 means unlimited.
+
+The override lasts until the run ends: the next run starts from its own
+--max-cost and --max-iterations. The command refuses to run when no loop holds
+the state lock.
+
+Examples:
+  ralph budget set --max-cost 10
+  ralph budget set --max-iterations 80`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			override := &loop.BudgetOverride{}
+			if cmd.Flags().Changed("max-cost") {
+				override.MaxCostUSD = &maxCost
+			}
+			if cmd.Flags().Changed("max-iterations") {
+				override.MaxIterations = &maxIterations
+			}
+			return runBudgetSet(cmd, override)
+		},
+	}
+
+	cmd.Flags().Float64Var(&maxCost, "max-cost", 0, "new cost limit in USD (0 = unlimited)")
+	cmd.Flags().IntVar(&maxIterations, "max-iterations", 0, "new iteration limit (0 = unlimited)")
+
+	return cmd
+}
+
+func runBudgetSet(cmd *cobra.Command, override *loop.BudgetOverride) error {
+	if override.MaxCostUSD == nil && override.MaxIterations == nil {
+		return errors.New("nothing to set: pass --max-cost or --max-iterations")
+	}
+	if override.MaxCostUSD != nil && *override.MaxCostUSD < 0 {
+		return fmt.Errorf("invalid --max-cost %v: must not be negative", *override.MaxCostUSD)
+	}
+	if override.MaxIterations != nil && *override.MaxIterations < 0 {
+		return fmt.Errorf("invalid --max-iterations %d: must not be negative", *override.MaxIterations)
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if _, err := os.Stat(state.StateDirPath(workDir)); os.IsNotExist(err) {
+		return errors.New("no ralph state in this directory (.ralph/state not found)")
+	}
+	// Only a running loop reads the override, and it drops it when it ends
+	holder, err := state.ReadLock(workDir)
+	if err != nil {
+		return err
+	}
+	if holder == nil {
+		return errors.New("no ralph run is in progress; pass --max-cost or --max-iterations to the next run instead")
+	}
+
+	// Keep a limit set earlier in the same run when only the other one changes
+	path := state.BudgetOverrideFilePath(workDir)
+	existing, err := loop.LoadBudgetOverride(path)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if override.MaxCostUSD == nil {
+			override.MaxCostUSD = existing.MaxCostUSD
+		}
+		if override.MaxIterations == nil {
+			override.MaxIterations = existing.MaxIterations
+		}
+	}
+	override.UpdatedAt = time.Now()
+
+	if err := loop.SaveBudgetOverride(path, override); err != nil {
+		return err
+	}
+
+	var limits []string
+	if cost := override.MaxCostUSD; cost != nil {
+		limits = append(limits, "max cost "+loop.FormatCostLimit(*cost))
+	}
+	if iterations := override.MaxIterations; iterations != nil {
+		limits = append(limits, "max iterations "+loop.FormatIterationLimit(*iterations))
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Budget set: %s\nThe running loop (PID %d) applies it before its next iteration.\n", strings.Join(limits, ", "), holder.PID)
+	return nil
+}
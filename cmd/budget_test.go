@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
+)
+
+func TestBudgetSetCommand(t *testing.T) {
+	setup := func(t *testing.T, withState bool) string {
+		tmpDir := t.TempDir()
+		if withState {
+			require.NoError(t, os.MkdirAll(state.StateDirPath(tmpDir), 0755))
+			require.NoError(t, os.WriteFile(state.LockFilePath(tmpDir), []byte(`{"pid":4242}`), 0644))
+		}
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+		return tmpDir
+	}
+	run := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"budget", "set"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("writes the override and keeps earlier limits", func(t *testing.T) {
+		tmpDir := setup(t, true)
+
+		out, err := run("--max-cost", "10")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Budget set: max cost $10.00")
+		assert.Contains(t, out, "The running loop (PID 4242) applies it")
+
+		out, err = run("--max-iterations", "0")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Budget set: max cost $10.00, max iterations unlimited")
+
+		override, err := loop.LoadBudgetOverride(state.BudgetOverrideFilePath(tmpDir))
+		require.NoError(t, err)
+		require.NotNil(t, override)
+		assert.Equal(t, 10.0, *override.MaxCostUSD)
+		assert.Equal(t, 0, *override.MaxIterations)
+	})
+
+	t.Run("requires a limit", func(t *testing.T) {
+		setup(t, true)
+
+		_, err := run()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pass --max-cost or --max-iterations")
+	})
+
+	t.Run("rejects negative limits", func(t *testing.T) {
+		setup(t, true)
+
+		_, err := run("--max-cost", "-1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must not be negative")
+	})
+
+	t.Run("refuses when no run is in progress", func(t *testing.T) {
+		tmpDir := setup(t, true)
+		require.NoError(t, os.Remove(state.LockFilePath(tmpDir)))
+
+		_, err := run("--max-cost", "10")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no ralph run is in progress")
+		assert.NoFileExists(t, state.BudgetOverrideFilePath(tmpDir))
+	})
+
+	t.Run("requires ralph state", func(t *testing.T) {
+		setup(t, false)
+
+		_, err := run("--max-cost", "10")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ".ralph/state not found")
+	})
+}
+
+func TestBudgetCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(tmpDir))
+	require.NoError(t, os.MkdirAll(state.StateDirPath(tmpDir), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".ralph", "parent-task-id"), []byte("feature"), 0644))
+
+	run := func(args ...string) string {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"budget"}, args...))
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	assert.Contains(t, run(), "No budget persisted for feature")
+
+	tracker, err := loop.NewBudgetTrackerFromState(state.StateDirPath(tmpDir), "feature", loop.BudgetLimits{})
+	require.NoError(t, err)
+	tracker.RecordIteration(1.5, 1000, 200)
+	require.NoError(t, tracker.Flush())
+
+	assert.Contains(t, run(), "Spent on feature across runs: 1 iterations, $1.50, 1000 input tokens, 200 output tokens")
+	assert.Contains(t, run("--reset"), "Budget reset for feature")
+	assert.Contains(t, run(), "No budget persisted for feature")
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/ralph/cmd/tui"
+	"github.com/yarlson/ralph/internal/bootstrap"
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func newDecomposeCmd() *cobra.Command {
+	var (
+		model     string
+		parent    string
+		redo      bool
+		yes       bool
+		fromCache bool
+		merge     bool
+		estimate  bool
+		epic      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "decompose <prd-file>",
+		Short: "Decompose a PRD into tasks without running them",
+		Long: `Decompose a PRD into tasks.yaml and import the tasks, without starting the loop.
+
+With --redo, the PRD is decomposed again (typically with a different --model)
+and the result is compared with the existing task tree. The added, removed and
+changed tasks are shown, and the existing tasks are only replaced if you accept.
+Tasks kept by the new decomposition keep their status.
+
+With --epic, only that epic's subtree is decomposed again, from a PRD excerpt
+covering just that area. The new tasks replace the epic's children (reusing
+their IDs where the work is the same) after the same diff and confirmation,
+and the rest of the tree is left untouched. The merged tree is validated
+as a whole, so dependencies on removed tasks are caught before anything is
+written.
+
+With --merge, only tasks for what the existing tree does not cover yet are
+generated and added under the parent task. Existing tasks, including
+completed and hand-edited ones, are kept as they are, and tasks.yaml is not
+rewritten. New IDs must not collide with existing ones, and new tasks may
+depend on existing ones; the merged set is validated as a whole.
+
+With --estimate, the token count of the PRD and system prompt and a rough
+cost projection for --model are printed, and nothing is generated.
+
+If the generated YAML still fails validation after the fix attempts, the last
+version that parsed is saved to .ralph/state/decompose-cache.yaml. With
+--from-cache, decomposition resumes from that file (including any manual
+edits) instead of decomposing the PRD again.
+
+Examples:
+  ralph decompose prd.md
+  ralph decompose --model opus --redo prd.md
+  ralph decompose --redo --yes prd.md
+  ralph decompose --merge prd.md
+  ralph decompose --estimate --model opus prd.md
+  ralph decompose --from-cache prd.md
+  ralph decompose --epic api prd-api.md`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDecompose(cmd, args[0], model, parent, epic, redo, yes, fromCache, merge, estimate)
+		},
+	}
+
+	cmd.Flags().StringVar(&model, "model", "", "model to decompose with (default: provider's default)")
+	cmd.Flags().StringVarP(&parent, "parent", "p", "", "parent task of the existing tree (default: stored parent task)")
+	cmd.Flags().BoolVar(&redo, "redo", false, "re-run decomposition and diff against the existing tasks before replacing them")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "with --redo or --epic, accept the new decomposition without prompting")
+	cmd.Flags().BoolVar(&fromCache, "from-cache", false, "resume from the cached YAML of a decomposition that failed validation")
+	cmd.Flags().StringVar(&epic, "epic", "", "re-decompose only this task's subtree from a PRD excerpt")
+	cmd.Flags().BoolVar(&merge, "merge", false, "add new tasks under the parent task, keeping the existing ones")
+	cmd.Flags().BoolVar(&estimate, "estimate", false, "print estimated tokens and cost without decomposing")
+	cmd.MarkFlagsMutuallyExclusive("epic", "redo", "merge", "estimate")
+	cmd.MarkFlagsMutuallyExclusive("from-cache", "estimate")
+	cmd.MarkFlagsMutuallyExclusive("epic", "parent")
+
+	return cmd
+}
+
+func runDecompose(cmd *cobra.Command, prdPath, model, parent, epic string, redo, yes, fromCache, merge, estimate bool) error {
+	if _, err := os.Stat(prdPath); os.IsNotExist(err) {
+		return fmt.Errorf("file not found: %s", prdPath)
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	cfg, err := config.LoadConfigWithFile(GetConfigFile())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	opts := bootstrap.DecomposeOptions{
+		Provider:  rootProvider,
+		Model:     model,
+		Parent:    parent,
+		FromCache: fromCache,
+		Epic:      epic,
+	}
+
+	if estimate {
+		return bootstrap.EstimateDecomposition(prdPath, workDir, cfg, opts, cmd.OutOrStdout())
+	}
+	if merge {
+		return bootstrap.MergeDecompose(cmd.Context(), prdPath, workDir, cfg, opts, cmd.OutOrStdout())
+	}
+	if !redo && epic == "" {
+		return bootstrap.Decompose(cmd.Context(), prdPath, workDir, cfg, opts, cmd.OutOrStdout())
+	}
+
+	confirm := func(diff *taskstore.TaskDiff) (bool, error) {
+		if yes {
+			return true, nil
+		}
+		return tui.ConfirmDecomposition(cmd.OutOrStdout(), cmd.InOrStdin(), decompositionDiffInfo(diff))
+	}
+
+	if epic != "" {
+		return bootstrap.RedecomposeEpic(cmd.Context(), prdPath, workDir, cfg, opts, confirm, cmd.OutOrStdout())
+	}
+	return bootstrap.Redecompose(cmd.Context(), prdPath, workDir, cfg, opts, confirm, cmd.OutOrStdout())
+}
+
+// decompositionDiffInfo converts a task diff for display in the confirmation prompt.
+func decompositionDiffInfo(diff *taskstore.TaskDiff) tui.DecompositionDiffInfo {
+	var info tui.DecompositionDiffInfo
+	for _, t := range diff.Added {
+		info.Added = append(info.Added, tui.TaskDiffEntry{ID: t.ID, Title: t.Title})
+	}
+	for _, t := range diff.Removed {
+		info.Removed = append(info.Removed, tui.TaskDiffEntry{ID: t.ID, Title: t.Title})
+	}
+	for _, c := range diff.Changed {
+		info.Changed = append(info.Changed, tui.TaskDiffEntry{ID: c.New.ID, Title: c.New.Title, Fields: c.Fields})
+	}
+	return info
+}
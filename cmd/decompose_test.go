@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestDecomposeCommand(t *testing.T) {
+	setup := func(t *testing.T) string {
+		tmpDir := t.TempDir()
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+		return tmpDir
+	}
+
+	t.Run("requires an existing PRD file", func(t *testing.T) {
+		setup(t)
+
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"decompose", "missing.md"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "file not found")
+	})
+
+	t.Run("redo requires existing tasks", func(t *testing.T) {
+		tmpDir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.md"), []byte("# PRD\n"), 0644))
+
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"decompose", "--model", "opus", "--redo", "prd.md"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no existing tasks")
+	})
+
+	t.Run("from-cache requires a cached decomposition", func(t *testing.T) {
+		tmpDir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.md"), []byte("# PRD\n"), 0644))
+
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"decompose", "--from-cache", "prd.md"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no cached decomposition")
+	})
+
+	t.Run("from-cache imports the cached YAML without the agent", func(t *testing.T) {
+		tmpDir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.md"), []byte("# PRD\n"), 0644))
+		cachePath := state.DecomposeCacheFilePath(tmpDir)
+		require.NoError(t, os.MkdirAll(filepath.Dir(cachePath), 0755))
+		cached := `tasks:
+  - id: root
+    title: Root
+    description: Root task
+  - id: child
+    title: Child
+    description: Child task
+    parentId: root
+    acceptance:
+      - Works
+    verify:
+      - ["go", "test", "./..."]
+`
+		require.NoError(t, os.WriteFile(cachePath, []byte(cached), 0644))
+
+		cmd := NewRootCmd()
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"decompose", "--from-cache", "prd.md"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "Resuming from cached decomposition")
+		assert.FileExists(t, filepath.Join(tmpDir, "tasks.yaml"))
+		assert.NoFileExists(t, cachePath)
+	})
+
+	t.Run("epic replaces only the epic's subtree", func(t *testing.T) {
+		tmpDir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "api.md"), []byte("# API\n"), 0644))
+
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, ".ralph", "tasks"))
+		require.NoError(t, err)
+		newTask := func(id, parentID string, status taskstore.TaskStatus) *taskstore.Task {
+			task := &taskstore.Task{
+				ID:          id,
+				Title:       "Title " + id,
+				Description: "Description " + id,
+				Status:      status,
+				Verify:      [][]string{{"go", "test", "./..."}},
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			}
+			if parentID != "" {
+				task.ParentID = &parentID
+			}
+			return task
+		}
+		for _, task := range []*taskstore.Task{
+			newTask("root", "", taskstore.StatusOpen),
+			newTask("api", "root", taskstore.StatusOpen),
+			newTask("api-login", "api", taskstore.StatusCompleted),
+			newTask("api-old", "api", taskstore.StatusOpen),
+			newTask("ui", "root", taskstore.StatusOpen),
+			newTask("ui-form", "ui", taskstore.StatusOpen),
+		} {
+			require.NoError(t, store.Save(task))
+		}
+
+		cachePath := state.DecomposeCacheFilePath(tmpDir)
+		require.NoError(t, os.MkdirAll(filepath.Dir(cachePath), 0755))
+		cached := `tasks:
+  - id: api-login
+    title: Login endpoint
+    description: Create internal/api/login.go
+    parentId: api
+    verify:
+      - ["go", "test", "./..."]
+  - id: api-logout
+    title: Logout endpoint
+    description: Create internal/api/logout.go
+    parentId: api
+    verify:
+      - ["go", "test", "./..."]
+`
+		require.NoError(t, os.WriteFile(cachePath, []byte(cached), 0644))
+
+		cmd := NewRootCmd()
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"decompose", "--epic", "api", "--from-cache", "--yes", "api.md"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "Replaced api subtree: 1 added, 1 removed, 1 changed")
+
+		login, err := store.Get("api-login")
+		require.NoError(t, err)
+		assert.Equal(t, "Login endpoint", login.Title)
+		assert.Equal(t, taskstore.StatusCompleted, login.Status)
+		_, err = store.Get("api-logout")
+		require.NoError(t, err)
+		_, err = store.Get("api-old")
+		require.Error(t, err)
+		form, err := store.Get("ui-form")
+		require.NoError(t, err)
+		assert.Equal(t, "Title ui-form", form.Title)
+		assert.NoFileExists(t, filepath.Join(tmpDir, "tasks.yaml"))
+	})
+
+	t.Run("merge adds new tasks and keeps existing ones", func(t *testing.T) {
+		tmpDir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.md"), []byte("# PRD\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "tasks.yaml"), []byte("# hand-edited\n"), 0644))
+
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, ".ralph", "tasks"))
+		require.NoError(t, err)
+		root := "root"
+		for _, task := range []*taskstore.Task{
+			{ID: "root", Title: "Root", Description: "Build the app", Status: taskstore.StatusOpen, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+			{ID: "api", Title: "Hand-edited API", Description: "Build the API", ParentID: &root,
+				Status: taskstore.StatusCompleted, Verify: [][]string{{"go", "test", "./..."}}, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		} {
+			require.NoError(t, store.Save(task))
+		}
+
+		cachePath := state.DecomposeCacheFilePath(tmpDir)
+		require.NoError(t, os.MkdirAll(filepath.Dir(cachePath), 0755))
+		cached := `tasks:
+  - id: ui
+    title: UI
+    description: Create the login form
+    parentId: root
+    dependsOn: [api]
+    verify:
+      - ["go", "test", "./..."]
+`
+		require.NoError(t, os.WriteFile(cachePath, []byte(cached), 0644))
+
+		cmd := NewRootCmd()
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"decompose", "--merge", "--parent", "root", "--from-cache", "prd.md"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "Added 1 tasks to root")
+
+		api, err := store.Get("api")
+		require.NoError(t, err)
+		assert.Equal(t, "Hand-edited API", api.Title)
+		assert.Equal(t, taskstore.StatusCompleted, api.Status)
+		ui, err := store.Get("ui")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api"}, ui.DependsOn)
+		assert.Equal(t, taskstore.StatusOpen, ui.Status)
+
+		content, err := os.ReadFile(filepath.Join(tmpDir, "tasks.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, "# hand-edited\n", string(content))
+	})
+
+	t.Run("merge requires a parent task", func(t *testing.T) {
+		tmpDir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.md"), []byte("# PRD\n"), 0644))
+
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"decompose", "--merge", "prd.md"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no parent task to merge into")
+	})
+
+	t.Run("estimate prints tokens and cost without decomposing", func(t *testing.T) {
+		tmpDir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.md"), []byte("# PRD\n\nBuild it.\n"), 0644))
+
+		cmd := NewRootCmd()
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"decompose", "--estimate", "--model", "opus", "prd.md"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "PRD:           ~")
+		assert.Contains(t, out.String(), "(model: opus)")
+		assert.NoFileExists(t, filepath.Join(tmpDir, "tasks.yaml"))
+	})
+
+	t.Run("epic cannot be combined with redo", func(t *testing.T) {
+		tmpDir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "prd.md"), []byte("# PRD\n"), 0644))
+
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"decompose", "--epic", "api", "--redo", "prd.md"})
+
+		require.Error(t, cmd.Execute())
+	})
+}
+
+func TestDecompositionDiffInfo(t *testing.T) {
+	diff := &taskstore.TaskDiff{
+		Added:   []*taskstore.Task{{ID: "new", Title: "New"}},
+		Removed: []*taskstore.Task{{ID: "old", Title: "Old"}},
+		Changed: []taskstore.TaskChange{{
+			Old:    &taskstore.Task{ID: "api", Title: "API"},
+			New:    &taskstore.Task{ID: "api", Title: "Build API"},
+			Fields: []string{"title"},
+		}},
+	}
+
+	info := decompositionDiffInfo(diff)
+
+	require.Len(t, info.Added, 1)
+	assert.Equal(t, "new", info.Added[0].ID)
+	require.Len(t, info.Removed, 1)
+	assert.Equal(t, "Old", info.Removed[0].Title)
+	require.Len(t, info.Changed, 1)
+	assert.Equal(t, "Build API", info.Changed[0].Title)
+	assert.Equal(t, []string{"title"}, info.Changed[0].Fields)
+}
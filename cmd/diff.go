@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/reporter"
+	"github.com/yarlson/ralph/internal/state"
+)
+
+func newDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <iteration-id>",
+		Short: "Show what an iteration changed",
+		Long: `Show an iteration's outcome, cost, changed files and verification results,
+followed by the diff from its base commit to its result commit.
+
+An iteration that made no result commit (such as a failed one) is diffed
+against the working tree instead, which may include changes made since.
+Untracked files are not part of the diff. Use 'ralph fix --list' to find
+iteration IDs.
+
+Examples:
+  ralph diff 3f2c9a1e`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(cmd, args[0])
+		},
+	}
+}
+
+func runDiff(cmd *cobra.Command, iterationID string) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	path := filepath.Join(state.LogsDirPath(workDir), fmt.Sprintf("iteration-%s.json", iterationID))
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("iteration not found: %s", iterationID)
+	}
+	record, err := loop.LoadRecord(path)
+	if err != nil {
+		return fmt.Errorf("failed to load iteration record: %w", err)
+	}
+	if record.BaseCommit == "" {
+		return fmt.Errorf("iteration %q has no base commit recorded", iterationID)
+	}
+
+	diff, err := git.NewShellManager(workDir, "").Diff(cmd.Context(), record.BaseCommit, record.ResultCommit)
+	if err != nil {
+		return fmt.Errorf("failed to diff iteration %s: %w", iterationID, err)
+	}
+
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), reporter.FormatIterationDiff(record, diff))
+	return nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/loop"
+)
+
+func TestDiffCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-b", "main")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	git("config", "commit.gpgsign", "false")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte(".ralph/\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("one\n"), 0644))
+	git("add", "-A")
+	git("commit", "-m", "base")
+	base := git("rev-parse", "HEAD")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("two\n"), 0644))
+	git("commit", "-am", "task a")
+	result := git("rev-parse", "HEAD")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("three\n"), 0644))
+
+	logsDir := filepath.Join(tmpDir, ".ralph", "logs")
+	for _, record := range []*loop.IterationRecord{
+		{
+			IterationID:      "iter-ok",
+			TaskID:           "task-a",
+			Outcome:          loop.OutcomeSuccess,
+			BaseCommit:       base,
+			ResultCommit:     result,
+			FilesChanged:     []string{"a.txt"},
+			ClaudeInvocation: loop.ClaudeInvocationMeta{TotalCostUSD: 0.25},
+		},
+		{
+			IterationID:  "iter-failed",
+			TaskID:       "task-b",
+			Outcome:      loop.OutcomeFailed,
+			BaseCommit:   result,
+			FilesChanged: []string{"a.txt"},
+			VerificationOutputs: []loop.VerificationOutput{
+				{Command: []string{"go", "test"}, Passed: false, Output: "--- FAIL: TestA\n"},
+			},
+		},
+	} {
+		_, err := loop.SaveRecord(logsDir, record)
+		require.NoError(t, err)
+	}
+
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(tmpDir))
+
+	run := func(id string) (string, error) {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"diff", id})
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("successful iteration diffs its commits", func(t *testing.T) {
+		out, err := run("iter-ok")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Iteration iter-ok (task task-a): success")
+		assert.Contains(t, out, "Cost: $0.2500")
+		assert.Contains(t, out, "  - a.txt")
+		assert.Contains(t, out, "-one\n+two\n")
+		assert.NotContains(t, out, "three", "the working tree is not part of it")
+	})
+
+	t.Run("failed iteration diffs against the working tree", func(t *testing.T) {
+		out, err := run("iter-failed")
+		require.NoError(t, err)
+		assert.Contains(t, out, "working tree (no result commit)")
+		assert.Contains(t, out, "  - go test - FAIL")
+		assert.Contains(t, out, "--- FAIL: TestA")
+		assert.Contains(t, out, "-two\n+three\n")
+	})
+
+	t.Run("unknown iteration", func(t *testing.T) {
+		_, err := run("missing")
+		assert.ErrorContains(t, err, "iteration not found: missing")
+	})
+}
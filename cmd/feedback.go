@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/ralph/internal/state"
+)
+
+// feedbackPreviewLen is the maximum length of a feedback content preview.
+const feedbackPreviewLen = 60
+
+func newFeedbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "feedback",
+		Short: "Inspect and clear pending task feedback",
+		Long: `Feedback and skip-reason files in .ralph/state carry out-of-band guidance
+into the next run. These commands show and remove them.`,
+	}
+
+	cmd.AddCommand(newFeedbackListCmd())
+	cmd.AddCommand(newFeedbackClearCmd())
+
+	return cmd
+}
+
+func newFeedbackListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List pending feedback and skip-reason files",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFeedbackList(cmd)
+		},
+	}
+}
+
+func newFeedbackClearCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "clear [task-id]",
+		Short: "Remove feedback and skip-reason files",
+		Long: `Remove the feedback and skip-reason files for a task, or for all tasks with --all.
+
+Examples:
+  ralph feedback clear task-auth-login  # Clear one task's feedback
+  ralph feedback clear --all            # Clear all feedback`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID := ""
+			if len(args) == 1 {
+				taskID = args[0]
+			}
+			return runFeedbackClear(cmd, taskID, all)
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "remove feedback for all tasks")
+
+	return cmd
+}
+
+func runFeedbackList(cmd *cobra.Command) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	files, err := state.ListFeedbackFiles(workDir)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No pending feedback")
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Pending feedback (%d):\n", len(files))
+	for _, f := range files {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s [%s]: %s\n", f.TaskID, f.Kind, feedbackPreview(f.Content))
+	}
+
+	return nil
+}
+
+func runFeedbackClear(cmd *cobra.Command, taskID string, all bool) error {
+	if all == (taskID != "") {
+		return errors.New("specify either a task ID or --all")
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	taskIDs := []string{taskID}
+	if all {
+		files, err := state.ListFeedbackFiles(workDir)
+		if err != nil {
+			return err
+		}
+		taskIDs = taskIDs[:0]
+		for _, f := range files {
+			taskIDs = append(taskIDs, f.TaskID)
+		}
+	}
+
+	removed := 0
+	for _, id := range taskIDs {
+		paths, err := state.RemoveFeedbackFiles(workDir, id)
+		removed += len(paths)
+		if err != nil {
+			return err
+		}
+	}
+
+	if removed == 0 && !all {
+		return fmt.Errorf("no feedback found for task %q", taskID)
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed %d feedback file(s)\n", removed)
+	return nil
+}
+
+// feedbackPreview returns the first line of content, truncated for display.
+func feedbackPreview(content string) string {
+	preview, _, more := strings.Cut(strings.TrimSpace(content), "\n")
+	if runes := []rune(preview); len(runes) > feedbackPreviewLen {
+		return string(runes[:feedbackPreviewLen]) + "..."
+	}
+	if more {
+		return preview + " ..."
+	}
+	return preview
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedbackCommand(t *testing.T) {
+	setup := func(t *testing.T) string {
+		tmpDir := t.TempDir()
+		stateDir := filepath.Join(tmpDir, ".ralph", "state")
+		require.NoError(t, os.MkdirAll(stateDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(stateDir, "feedback-task-a.txt"), []byte("check nil pointers\nand more"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(stateDir, "skip-reason-task-b.txt"), []byte(strings.Repeat("x", 80)), 0644))
+
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+		return stateDir
+	}
+
+	execute := func(t *testing.T, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("lists pending feedback with previews", func(t *testing.T) {
+		setup(t)
+
+		out, err := execute(t, "feedback", "list")
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "Pending feedback (2):")
+		assert.Contains(t, out, "task-a [feedback]: check nil pointers ...")
+		assert.Contains(t, out, "task-b [skip-reason]: "+strings.Repeat("x", 60)+"...")
+	})
+
+	t.Run("clears one task", func(t *testing.T) {
+		stateDir := setup(t)
+
+		out, err := execute(t, "feedback", "clear", "task-a")
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "Removed 1 feedback file(s)")
+		assert.NoFileExists(t, filepath.Join(stateDir, "feedback-task-a.txt"))
+		assert.FileExists(t, filepath.Join(stateDir, "skip-reason-task-b.txt"))
+	})
+
+	t.Run("clears all tasks", func(t *testing.T) {
+		setup(t)
+
+		_, err := execute(t, "feedback", "clear", "--all")
+		require.NoError(t, err)
+
+		out, err := execute(t, "feedback", "list")
+		require.NoError(t, err)
+		assert.Contains(t, out, "No pending feedback")
+	})
+
+	t.Run("errors for unknown task", func(t *testing.T) {
+		setup(t)
+
+		_, err := execute(t, "feedback", "clear", "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `no feedback found for task "missing"`)
+	})
+
+	t.Run("requires a task ID or --all", func(t *testing.T) {
+		setup(t)
+
+		_, err := execute(t, "feedback", "clear")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "specify either a task ID or --all")
+	})
+}
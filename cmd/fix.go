@@ -1,60 +1,102 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/yarlson/ralph/cmd/tui"
-	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/color"
 	"github.com/yarlson/ralph/internal/fix"
+	"github.com/yarlson/ralph/internal/git"
 	"github.com/yarlson/ralph/internal/state"
 	"github.com/yarlson/ralph/internal/taskstore"
 )
 
 func newFixCmd() *cobra.Command {
-	var retryID, skipID, undoID, feedback, reason string
-	var force, list bool
+	var retryID, skipID, approveID, feedback, reason string
+	var force, list, cascade, all, stash, undo bool
+	var count int
 
 	cmd := &cobra.Command{
 		Use:   "fix",
 		Short: "Fix failed tasks or undo iterations",
-		Long: `Fix command provides options to retry failed tasks, skip tasks, or undo iterations.
+		Long: `Fix command provides options to retry failed tasks, skip tasks, approve tasks
+awaiting review, or undo iterations.
 
 Examples:
   ralph fix --retry task-123        # Retry a failed task
+  ralph fix --all                   # Retry every failed task
   ralph fix --skip task-123         # Skip a task
+  ralph fix --approve task-123      # Mark a task awaiting review completed
   ralph fix --undo iteration-001    # Undo an iteration
+  ralph fix --undo iter-1 iter-2    # Undo several iterations at once
+  ralph fix --undo --count 3        # Undo the 3 most recent successful iterations
+  ralph fix --undo iteration-001 --cascade  # Also reopen completed dependents
+  ralph fix --undo iteration-001 --stash    # Keep uncommitted changes
   ralph fix --list                  # List fixable issues`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runFix(cmd, retryID, skipID, undoID, feedback, reason, force, list)
+			var undoIDs []string
+			if undo {
+				ids, err := undoIterationIDs(args, count)
+				if err != nil {
+					return err
+				}
+				undoIDs = ids
+			} else if count > 0 || len(args) > 0 {
+				return fmt.Errorf("iteration IDs and --count require --undo")
+			}
+			return runFix(cmd, retryID, skipID, undoIDs, approveID, feedback, reason, force, list, cascade, all, stash, count)
 		},
 	}
 
 	cmd.Flags().StringVarP(&retryID, "retry", "r", "", "task ID to retry")
+	cmd.Flags().BoolVar(&all, "all", false, "retry every failed task")
 	cmd.Flags().StringVarP(&skipID, "skip", "s", "", "task ID to skip")
-	cmd.Flags().StringVarP(&undoID, "undo", "u", "", "iteration ID to undo")
+	cmd.Flags().BoolVarP(&undo, "undo", "u", false, "undo the iterations given as arguments, resetting to the earliest one's base commit")
+	cmd.Flags().IntVar(&count, "count", 0, "with --undo, undo the N most recent successful iterations")
+	cmd.Flags().StringVar(&approveID, "approve", "", "task ID awaiting review to mark completed")
 	cmd.Flags().StringVarP(&feedback, "feedback", "f", "", "feedback message for retry")
 	cmd.Flags().StringVar(&reason, "reason", "", "reason for skipping")
 	cmd.Flags().BoolVar(&force, "force", false, "skip confirmation prompts")
 	cmd.Flags().BoolVarP(&list, "list", "l", false, "list fixable issues")
+	cmd.Flags().BoolVar(&cascade, "cascade", false, "with --undo, also reopen completed tasks that depend on the reopened task")
+	cmd.Flags().BoolVar(&stash, "stash", false, "with --undo, stash uncommitted changes before the reset and restore them afterwards")
+
+	cmd.MarkFlagsMutuallyExclusive("retry", "all")
 
 	return cmd
 }
 
-func runFix(cmd *cobra.Command, retryID, skipID, undoID, feedback, reason string, force, list bool) error {
-	svc, err := newFixService()
+// undoIterationIDs returns the iteration IDs given to --undo, or nil when
+// they are to be picked with --count.
+func undoIterationIDs(args []string, count int) ([]string, error) {
+	switch {
+	case count < 0:
+		return nil, fmt.Errorf("invalid --count %d: must be at least 1", count)
+	case count > 0 && len(args) > 0:
+		return nil, fmt.Errorf("give --undo iteration IDs or --count, not both")
+	case count == 0 && len(args) == 0:
+		return nil, fmt.Errorf("--undo requires iteration IDs or --count")
+	}
+	return args, nil
+}
+
+func runFix(cmd *cobra.Command, retryID, skipID string, undoIDs []string, approveID, feedback, reason string, force, list, cascade, all, stash bool, count int) error {
+	svc, closeStore, err := newFixService()
 	if err != nil {
 		return err
 	}
+	defer closeStore()
 
 	if list {
 		return runFixList(cmd, svc)
 	}
 
-	hasActionFlag := retryID != "" || skipID != "" || undoID != ""
+	hasActionFlag := all || retryID != "" || skipID != "" || len(undoIDs) > 0 || count > 0 || approveID != ""
 
 	if !hasActionFlag {
 		if !tui.IsInteractive(os.Stdin.Fd()) {
@@ -63,6 +105,10 @@ func runFix(cmd *cobra.Command, retryID, skipID, undoID, feedback, reason string
 		return runFixInteractive(cmd, svc, force)
 	}
 
+	if all {
+		return runFixRetryAll(cmd, svc, feedback)
+	}
+
 	if retryID != "" {
 		return runFixRetry(cmd, svc, retryID, feedback)
 	}
@@ -71,29 +117,40 @@ func runFix(cmd *cobra.Command, retryID, skipID, undoID, feedback, reason string
 		return runFixSkip(cmd, svc, skipID, reason)
 	}
 
-	if undoID != "" {
-		return runFixUndo(cmd, svc, undoID, force)
+	if len(undoIDs) > 0 || count > 0 {
+		if count > 0 {
+			undoIDs, err = svc.RecentIterations(count)
+			if err != nil {
+				return err
+			}
+		}
+		return runFixUndo(cmd, svc, undoIDs, force, cascade, stash)
+	}
+
+	if approveID != "" {
+		return runFixApprove(cmd, svc, approveID)
 	}
 
 	return nil
 }
 
-func newFixService() (*fix.Service, error) {
+// newFixService returns the fix service for the current directory and a
+// function that closes its task store.
+func newFixService() (*fix.Service, func(), error) {
 	workDir, err := os.Getwd()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get working directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to get working directory: %w", err)
 	}
 
-	tasksPath := filepath.Join(workDir, config.DefaultTasksPath)
-	store, err := taskstore.NewLocalStore(tasksPath)
+	store, err := openTaskStore(workDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open task store: %w", err)
+		return nil, nil, fmt.Errorf("failed to open task store: %w", err)
 	}
 
 	logsDir := state.LogsDirPath(workDir)
 	stateDir := state.StateDirPath(workDir)
 
-	return fix.NewService(store, logsDir, stateDir, workDir), nil
+	return fix.NewService(store, logsDir, stateDir, workDir), func() { _ = taskstore.CloseStore(store) }, nil
 }
 
 func runFixList(cmd *cobra.Command, svc *fix.Service) error {
@@ -103,13 +160,14 @@ func runFixList(cmd *cobra.Command, svc *fix.Service) error {
 	}
 
 	iterations, _ := svc.ListIterations(10)
+	p := color.New(color.Enabled(cmd.OutOrStdout(), noColor))
 
 	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Failed Tasks:")
 	if len(failed) == 0 {
 		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  (none)")
 	} else {
 		for _, t := range failed {
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s: %s\n", t.TaskID, t.Title)
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s: %s\n", p.Red(t.TaskID), t.Title)
 		}
 	}
 	_, _ = fmt.Fprintln(cmd.OutOrStdout())
@@ -119,7 +177,7 @@ func runFixList(cmd *cobra.Command, svc *fix.Service) error {
 		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  (none)")
 	} else {
 		for _, t := range blocked {
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s: %s\n", t.TaskID, t.Title)
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s: %s\n", p.Yellow(t.TaskID), t.Title)
 		}
 	}
 	_, _ = fmt.Fprintln(cmd.OutOrStdout())
@@ -129,7 +187,7 @@ func runFixList(cmd *cobra.Command, svc *fix.Service) error {
 		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  (none)")
 	} else {
 		for _, i := range iterations {
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s: task=%s outcome=%s\n", i.IterationID, i.TaskID, i.Outcome)
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s: task=%s outcome=%s\n", i.IterationID, i.TaskID, p.Outcome(i.Outcome))
 		}
 	}
 
@@ -154,6 +212,27 @@ func runFixRetry(cmd *cobra.Command, svc *fix.Service, taskID, feedback string)
 	return nil
 }
 
+func runFixRetryAll(cmd *cobra.Command, svc *fix.Service, feedback string) error {
+	outcomes, err := svc.RetryAllFailed(feedback)
+	for _, o := range outcomes {
+		if o.Skipped != "" {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Skipped task %q: %s\n", o.TaskID, o.Skipped)
+			continue
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Retry initiated: task %q reset to open status\n", o.TaskID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(outcomes) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No failed tasks to retry")
+	} else if feedback != "" {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Feedback saved for each retried task")
+	}
+	return nil
+}
+
 func runFixSkip(cmd *cobra.Command, svc *fix.Service, taskID, reason string) error {
 	alreadySkipped, _ := svc.IsAlreadySkipped(taskID)
 	if alreadySkipped {
@@ -172,19 +251,31 @@ func runFixSkip(cmd *cobra.Command, svc *fix.Service, taskID, reason string) err
 	return nil
 }
 
-func runFixUndo(cmd *cobra.Command, svc *fix.Service, iterationID string, force bool) error {
-	info, err := svc.GetUndoInfo(cmd.Context(), iterationID)
+func runFixApprove(cmd *cobra.Command, svc *fix.Service, taskID string) error {
+	if err := svc.Approve(taskID); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %q approved and marked as completed\n", taskID)
+	return nil
+}
+
+func runFixUndo(cmd *cobra.Command, svc *fix.Service, iterationIDs []string, force, cascade, stash bool) error {
+	info, err := svc.GetUndoInfo(cmd.Context(), iterationIDs...)
 	if err != nil {
 		return err
 	}
 
 	if !force {
 		confirmInfo := tui.UndoConfirmationInfo{
-			IterationID:           info.IterationID,
+			IterationIDs:          info.IterationIDs,
 			CommitToResetTo:       info.CommitToResetTo,
-			TaskToReopen:          info.TaskToReopen,
+			TasksToReopen:         info.TasksToReopen,
 			FilesToRevert:         info.FilesToRevert,
 			HasUncommittedChanges: info.HasUncommittedChanges,
+			CompletedDependents:   info.CompletedDependents,
+			Cascade:               cascade,
+			Stash:                 stash,
 		}
 
 		confirmed, err := tui.ConfirmUndo(cmd.OutOrStdout(), cmd.InOrStdin(), confirmInfo)
@@ -198,27 +289,52 @@ func runFixUndo(cmd *cobra.Command, svc *fix.Service, iterationID string, force
 	}
 
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Reverting to commit %s...\n", info.CommitToResetTo)
-	if err := svc.Undo(iterationID); err != nil {
+	var reopened []string
+	var stashErr error
+	if stash {
+		reopened, err = svc.UndoWithStash(cmd.Context(), info.IterationIDs, cascade)
+		if errors.Is(err, git.ErrStashConflict) {
+			stashErr, err = err, nil
+		}
+	} else {
+		reopened, err = svc.UndoWithCascade(info.IterationIDs, cascade)
+	}
+	if err != nil {
 		return err
 	}
 
-	if info.TaskToReopen != "" {
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %q reset to open status\n", info.TaskToReopen)
+	for _, id := range info.TasksToReopen {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Task %q reset to open status\n", id)
+	}
+	for _, id := range reopened {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Dependent task %q reset to open status\n", id)
+	}
+	if !cascade && len(info.CompletedDependents) > 0 {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: completed tasks still depend on %s: %s (rerun with --cascade to reopen them)\n",
+			strings.Join(info.TasksToReopen, ", "), strings.Join(info.CompletedDependents, ", "))
+	}
+	if len(info.IterationIDs) == 1 {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Undo completed: reverted iteration %s\n", info.IterationIDs[0])
+	} else {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Undo completed: reverted %d iterations (%s)\n", len(info.IterationIDs), strings.Join(info.IterationIDs, ", "))
+	}
+	if stashErr != nil {
+		return fmt.Errorf("%w\nresolve the conflicts, then run 'git stash drop'", stashErr)
 	}
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Undo completed: reverted iteration %s\n", iterationID)
 	return nil
 }
 
 func runFixNonTTYError(cmd *cobra.Command, svc *fix.Service) error {
 	failed, _, _ := svc.ListIssues()
 	iterations, _ := svc.ListIterations(10)
+	p := color.New(color.Enabled(cmd.OutOrStdout(), noColor))
 
 	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Fixable Issues:")
 	if len(failed) == 0 {
 		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  (none)")
 	} else {
 		for _, t := range failed {
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s: %s\n", t.TaskID, t.Title)
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s: %s\n", p.Red(t.TaskID), t.Title)
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "    use: ralph fix --retry %s\n", t.TaskID)
 		}
 	}
@@ -229,7 +345,7 @@ func runFixNonTTYError(cmd *cobra.Command, svc *fix.Service) error {
 		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  (none)")
 	} else {
 		for _, i := range iterations {
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s: task=%s outcome=%s\n", i.IterationID, i.TaskID, i.Outcome)
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s: task=%s outcome=%s\n", i.IterationID, i.TaskID, p.Outcome(i.Outcome))
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "    use: ralph fix --undo %s\n", i.IterationID)
 		}
 	}
@@ -261,7 +377,7 @@ func runFixInteractive(cmd *cobra.Command, svc *fix.Service, force bool) error {
 		case tui.FixActionSkip:
 			return runFixSkip(cmd, svc, action.TargetID, "")
 		case tui.FixActionUndo:
-			return runFixUndo(cmd, svc, action.TargetID, force)
+			return runFixUndo(cmd, svc, []string{action.TargetID}, force, false, false)
 		default:
 			return fmt.Errorf("unknown action type: %s", action.Type)
 		}
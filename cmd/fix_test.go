@@ -92,6 +92,65 @@ func TestFixCommand_RetryFailedTask(t *testing.T) {
 	assert.Equal(t, taskstore.StatusOpen, updated.Status)
 }
 
+func TestFixCommand_RetryAll(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tasksDir := filepath.Join(tmpDir, ".ralph", "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0755))
+
+	store, err := taskstore.NewLocalStore(tasksDir)
+	require.NoError(t, err)
+	for id, status := range map[string]taskstore.TaskStatus{
+		"task-failed-1": taskstore.StatusFailed,
+		"task-failed-2": taskstore.StatusFailed,
+		"task-done":     taskstore.StatusCompleted,
+	} {
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID:        id,
+			Title:     id,
+			Status:    status,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}))
+	}
+
+	origDir, _ := os.Getwd()
+	defer func() { _ = os.Chdir(origDir) }()
+	require.NoError(t, os.Chdir(tmpDir))
+
+	cmd := NewRootCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"fix", "--all", "--feedback", "check the edge cases"})
+
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, `Retry initiated: task "task-failed-1" reset to open status
+Retry initiated: task "task-failed-2" reset to open status
+Feedback saved for each retried task
+`, out.String())
+	for _, id := range []string{"task-failed-1", "task-failed-2"} {
+		updated, err := store.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, taskstore.StatusOpen, updated.Status)
+		assert.FileExists(t, filepath.Join(tmpDir, ".ralph", "state", "feedback-"+id+".txt"))
+	}
+	done, err := store.Get("task-done")
+	require.NoError(t, err)
+	assert.Equal(t, taskstore.StatusCompleted, done.Status)
+}
+
+func TestFixCommand_RetryAllWithRetryFlag(t *testing.T) {
+	cmd := NewRootCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"fix", "--all", "--retry", "task-1"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "none of the others can be")
+}
+
 func TestFixCommand_SkipTask(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -155,3 +214,31 @@ func TestFixCommand_UndoIterationNotFound(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "iteration not found")
 }
+
+func TestFixCommand_UndoArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"undo without iterations", []string{"fix", "--undo"}, "--undo requires iteration IDs or --count"},
+		{"iterations and count", []string{"fix", "--undo", "iter-1", "--count", "2"}, "not both"},
+		{"count without undo", []string{"fix", "--count", "2"}, "require --undo"},
+		{"iterations without undo", []string{"fix", "iter-1"}, "require --undo"},
+		{"negative count", []string{"fix", "--undo", "--count", "-1"}, "invalid --count -1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewRootCmd()
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/ralph/internal/scaffold"
+)
+
+func newInitCmd() *cobra.Command {
+	var (
+		template string
+		force    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Scaffold ralph.yaml and starter tasks for a project",
+		Long: fmt.Sprintf(`Write a ralph.yaml and a starter tasks.yaml for a common project type.
+The tasks file includes verify commands for the project's toolchain;
+edit it, then import it with "ralph tasks.yaml".
+
+Available templates: %s
+
+Examples:
+  ralph init --from-template go
+  ralph init --from-template node --force`, strings.Join(scaffold.Names(), ", ")),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInit(cmd, template, force)
+		},
+	}
+
+	cmd.Flags().StringVar(&template, "from-template", "", "template to scaffold from ("+strings.Join(scaffold.Names(), ", ")+")")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite existing files")
+	_ = cmd.MarkFlagRequired("from-template")
+
+	return cmd
+}
+
+func runInit(cmd *cobra.Command, template string, force bool) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	written, err := scaffold.Apply(workDir, template, force)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Scaffolded %s template:\n", template)
+	for _, path := range written {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s\n", path)
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "\nEdit tasks.yaml, then run: ralph tasks.yaml")
+
+	return nil
+}
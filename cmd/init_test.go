@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitCommand(t *testing.T) {
+	setup := func(t *testing.T) string {
+		tmpDir := t.TempDir()
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+		return tmpDir
+	}
+
+	t.Run("scaffolds files from template", func(t *testing.T) {
+		tmpDir := setup(t)
+
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"init", "--from-template", "go"})
+
+		require.NoError(t, cmd.Execute())
+		assert.FileExists(t, filepath.Join(tmpDir, "ralph.yaml"))
+		assert.FileExists(t, filepath.Join(tmpDir, "tasks.yaml"))
+		assert.Contains(t, out.String(), "ralph tasks.yaml")
+	})
+
+	t.Run("refuses to overwrite without force", func(t *testing.T) {
+		tmpDir := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "ralph.yaml"), []byte("provider: claude\n"), 0644))
+
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"init", "--from-template", "node"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--force")
+	})
+
+	t.Run("rejects unknown template", func(t *testing.T) {
+		setup(t)
+
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"init", "--from-template", "cobol"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown template")
+	})
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/reporter"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func newLogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Inspect and maintain iteration logs",
+	}
+
+	cmd.AddCommand(newLogsRepairCmd())
+	cmd.AddCommand(newLogsOrphansCmd())
+	cmd.AddCommand(newLogsSessionsCmd())
+
+	return cmd
+}
+
+func newLogsRepairCmd() *cobra.Command {
+	var remove bool
+
+	cmd := &cobra.Command{
+		Use:   "repair",
+		Short: "Report and optionally remove corrupt iteration records",
+		Long: `Scan .ralph/logs for iteration records that cannot be parsed, such as
+files truncated when ralph was killed mid-write.
+
+Examples:
+  ralph logs repair           # Report corrupt records
+  ralph logs repair --remove  # Remove corrupt records`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLogsRepair(cmd, remove)
+		},
+	}
+
+	cmd.Flags().BoolVar(&remove, "remove", false, "remove corrupt records")
+
+	return cmd
+}
+
+func runLogsRepair(cmd *cobra.Command, remove bool) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	corrupt, err := loop.FindCorruptRecords(state.LogsDirPath(workDir))
+	if err != nil {
+		return err
+	}
+
+	if len(corrupt) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No corrupt iteration records found")
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Corrupt iteration records (%d):\n", len(corrupt))
+	for _, record := range corrupt {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s: %v\n", record.Path, record.Err)
+	}
+
+	if !remove {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "\nuse: ralph logs repair --remove")
+		return nil
+	}
+
+	for _, record := range corrupt {
+		if err := loop.RemoveCorruptRecord(record); err != nil {
+			return err
+		}
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed %d corrupt record(s)\n", len(corrupt))
+
+	return nil
+}
+
+func newLogsOrphansCmd() *cobra.Command {
+	var (
+		archive bool
+		remove  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "orphans",
+		Short: "Report and optionally archive or delete records of unknown tasks",
+		Long: `Scan .ralph/logs for iteration records whose task no longer exists in the
+task store, for example because it was deleted or renamed by hand.
+
+Archived records are moved to .ralph/archive/logs.
+
+Examples:
+  ralph logs orphans            # Report orphaned records
+  ralph logs orphans --archive  # Move orphaned records to the archive
+  ralph logs orphans --delete   # Delete orphaned records`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLogsOrphans(cmd, archive, remove)
+		},
+	}
+
+	cmd.Flags().BoolVar(&archive, "archive", false, "move orphaned records to .ralph/archive/logs")
+	cmd.Flags().BoolVar(&remove, "delete", false, "delete orphaned records")
+	cmd.MarkFlagsMutuallyExclusive("archive", "delete")
+
+	return cmd
+}
+
+func runLogsOrphans(cmd *cobra.Command, archive, remove bool) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	store, err := openTaskStore(workDir)
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+
+	tasks, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	logsDir := state.LogsDirPath(workDir)
+	orphans, err := loop.FindOrphanedRecords(logsDir, tasks)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(orphans) == 0 {
+		_, _ = fmt.Fprintln(out, "No orphaned iteration records found")
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(out, "Orphaned iteration records (%d):\n", len(orphans))
+	for _, record := range orphans {
+		_, _ = fmt.Fprintf(out, "  - %s: task %s (%s, %s)\n",
+			record.IterationID, record.TaskID, record.Outcome, record.StartTime.Format("2006-01-02 15:04"))
+	}
+
+	switch {
+	case archive:
+		archiveDir := state.LogsArchiveDirPath(workDir)
+		for _, record := range orphans {
+			if err := loop.ArchiveRecord(logsDir, archiveDir, record.IterationID); err != nil {
+				return err
+			}
+		}
+		_, _ = fmt.Fprintf(out, "Archived %d record(s) to %s\n", len(orphans), archiveDir)
+	case remove:
+		for _, record := range orphans {
+			if err := loop.RemoveRecord(logsDir, record.IterationID); err != nil {
+				return err
+			}
+		}
+		_, _ = fmt.Fprintf(out, "Deleted %d record(s)\n", len(orphans))
+	default:
+		_, _ = fmt.Fprintln(out, "\nuse: ralph logs orphans --archive (or --delete)")
+	}
+
+	return nil
+}
+
+func newLogsSessionsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sessions",
+		Short: "Summarize iterations by session",
+		Long: `Group iteration records by session, oldest first. A session is one run,
+from start until it completes, pauses, or is stopped, so a feature worked on
+over several days shows one line per sitting.
+
+Examples:
+  ralph logs sessions`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLogsSessions(cmd)
+		},
+	}
+}
+
+func runLogsSessions(cmd *cobra.Command) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	records, err := loop.LoadAllIterationRecords(state.LogsDirPath(workDir))
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), reporter.FormatSessions(reporter.SummarizeSessions(records)))
+	return nil
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestLogsRepairCommand(t *testing.T) {
+	setup := func(t *testing.T) string {
+		tmpDir := t.TempDir()
+		logsDir := filepath.Join(tmpDir, ".ralph", "logs")
+		require.NoError(t, os.MkdirAll(logsDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(logsDir, "iteration-good.json"), []byte(`{"iteration_id":"good","task_id":"t1","outcome":"success"}`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(logsDir, "iteration-bad.json"), []byte(`{"iteration_id":"ba`), 0644))
+
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+		return logsDir
+	}
+
+	t.Run("reports corrupt records", func(t *testing.T) {
+		logsDir := setup(t)
+
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"logs", "repair"})
+
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, out.String(), "iteration-bad.json")
+		assert.NotContains(t, out.String(), "iteration-good.json")
+		assert.FileExists(t, filepath.Join(logsDir, "iteration-bad.json"))
+	})
+
+	t.Run("removes corrupt records with --remove", func(t *testing.T) {
+		logsDir := setup(t)
+
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"logs", "repair", "--remove"})
+
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, out.String(), "Removed 1 corrupt record(s)")
+		assert.NoFileExists(t, filepath.Join(logsDir, "iteration-bad.json"))
+		assert.FileExists(t, filepath.Join(logsDir, "iteration-good.json"))
+	})
+}
+
+func TestLogsOrphansCommand(t *testing.T) {
+	setup := func(t *testing.T) string {
+		tmpDir := t.TempDir()
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, config.DefaultTasksPath))
+		require.NoError(t, err)
+		now := time.Now()
+		require.NoError(t, store.Save(&taskstore.Task{ID: "t1", Title: "Task 1", Status: taskstore.StatusCompleted, CreatedAt: now, UpdatedAt: now}))
+
+		logsDir := filepath.Join(tmpDir, ".ralph", "logs")
+		require.NoError(t, os.MkdirAll(logsDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(logsDir, "iteration-known.json"), []byte(`{"iteration_id":"known","task_id":"t1","outcome":"success"}`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(logsDir, "iteration-orphan.json"), []byte(`{"iteration_id":"orphan","task_id":"gone","outcome":"failed"}`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(logsDir, "iteration-orphan.txt"), []byte("text log"), 0644))
+
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+		return tmpDir
+	}
+
+	run := func(t *testing.T, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{"logs", "orphans"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("reports orphaned records", func(t *testing.T) {
+		tmpDir := setup(t)
+
+		out, err := run(t)
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "orphan: task gone (failed")
+		assert.NotContains(t, out, "known")
+		assert.FileExists(t, filepath.Join(tmpDir, ".ralph", "logs", "iteration-orphan.json"))
+	})
+
+	t.Run("archives orphaned records", func(t *testing.T) {
+		tmpDir := setup(t)
+
+		out, err := run(t, "--archive")
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "Archived 1 record(s)")
+		assert.NoFileExists(t, filepath.Join(tmpDir, ".ralph", "logs", "iteration-orphan.json"))
+		assert.FileExists(t, filepath.Join(tmpDir, ".ralph", "archive", "logs", "iteration-orphan.json"))
+		assert.FileExists(t, filepath.Join(tmpDir, ".ralph", "archive", "logs", "iteration-orphan.txt"))
+		assert.FileExists(t, filepath.Join(tmpDir, ".ralph", "logs", "iteration-known.json"))
+	})
+
+	t.Run("deletes orphaned records", func(t *testing.T) {
+		tmpDir := setup(t)
+
+		out, err := run(t, "--delete")
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "Deleted 1 record(s)")
+		assert.NoFileExists(t, filepath.Join(tmpDir, ".ralph", "logs", "iteration-orphan.json"))
+		assert.NoFileExists(t, filepath.Join(tmpDir, ".ralph", "logs", "iteration-orphan.txt"))
+		assert.FileExists(t, filepath.Join(tmpDir, ".ralph", "logs", "iteration-known.json"))
+	})
+
+	t.Run("archive and delete are exclusive", func(t *testing.T) {
+		setup(t)
+
+		_, err := run(t, "--archive", "--delete")
+		require.Error(t, err)
+	})
+}
+
+func TestLogsSessionsCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	logsDir := state.LogsDirPath(tmpDir)
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	for i, sessionID := range []string{"s1", "s1", "s2"} {
+		_, err := loop.SaveRecord(logsDir, &loop.IterationRecord{
+			IterationID:      fmt.Sprintf("iter-%d", i),
+			TaskID:           fmt.Sprintf("task-%d", i),
+			SessionID:        sessionID,
+			StartTime:        start.Add(time.Duration(i) * 24 * time.Hour),
+			Outcome:          loop.OutcomeSuccess,
+			ClaudeInvocation: loop.ClaudeInvocationMeta{TotalCostUSD: 1.0},
+		})
+		require.NoError(t, err)
+	}
+
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(tmpDir))
+
+	cmd := NewRootCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"logs", "sessions"})
+
+	require.NoError(t, cmd.Execute())
+
+	assert.Contains(t, out.String(), "Session 1 (Mon Mar 2 09:00): 2 task(s), 2 iteration(s), $2.00")
+	assert.Contains(t, out.String(), "Session 2 (Wed Mar 4 09:00): 1 task(s), 1 iteration(s), $1.00")
+}
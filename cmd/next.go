@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/memory"
+	"github.com/yarlson/ralph/internal/selector"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func newNextCmd() *cobra.Command {
+	var showPrompt bool
+
+	cmd := &cobra.Command{
+		Use:   "next",
+		Short: "Show the task the next run would pick, and why",
+		Long: `Show the task a run would select right now, why it is ready, and which
+attempt it would be, without starting a run or changing any state.
+With --prompt, also print the exact prompts that would be sent to the agent.
+
+Examples:
+  ralph next
+  ralph next --prompt`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runNext(cmd, showPrompt)
+		},
+	}
+
+	cmd.Flags().BoolVar(&showPrompt, "prompt", false, "also print the system and user prompts")
+
+	return cmd
+}
+
+func runNext(cmd *cobra.Command, showPrompt bool) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	cfg, err := config.LoadConfigWithFile(GetConfigFile())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	parentIDBytes, err := os.ReadFile(filepath.Join(workDir, config.DefaultParentIDFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("parent-task-id file not found. Run 'ralph init' first")
+		}
+		return fmt.Errorf("failed to read parent-task-id: %w", err)
+	}
+	parentTaskID := string(parentIDBytes)
+
+	store, err := taskstore.OpenStore(cfg.Tasks.Backend, filepath.Join(workDir, config.DefaultTasksPath))
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+	if _, err := store.Get(parentTaskID); err != nil {
+		return fmt.Errorf("parent task %q not found: %w", parentTaskID, err)
+	}
+
+	logsDir := state.LogsDirPath(workDir)
+	controller := loop.NewController(loop.ControllerDeps{
+		TaskStore:    store,
+		Git:          git.NewShellManager(workDir, config.DefaultBranchPrefix),
+		LogsDir:      logsDir,
+		ProgressFile: memory.NewProgressFile(filepath.Join(workDir, config.DefaultProgressFile)),
+		WorkDir:      workDir,
+	})
+	controller.SetCheckpoints(cfg.Experimental.Checkpoints)
+	controller.SetVerifySets(cfg.Verify.Sets)
+	if len(cfg.Selector.ExternalCommand) > 0 {
+		controller.SetExternalSelector(selector.NewExternalCommand(cfg.Selector.ExternalCommand, workDir))
+	}
+
+	task, reasons, err := controller.PreviewNext(cmd.Context(), parentTaskID)
+	if err != nil {
+		return fmt.Errorf("failed to select next task: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if task == nil {
+		_, _ = fmt.Fprintf(out, "No ready tasks under %s.\n", parentTaskID)
+		return nil
+	}
+
+	attempt, err := loop.NextAttemptNumber(logsDir, task.ID)
+	if err != nil {
+		return err
+	}
+	kind := "initial"
+	if attempt > 1 {
+		kind = "retry"
+	}
+
+	_, _ = fmt.Fprintf(out, "Next task: %s\n", task.ID)
+	_, _ = fmt.Fprintf(out, "Title:     %s\n", task.Title)
+	_, _ = fmt.Fprintf(out, "Why:       %s\n", strings.Join(reasons, "; "))
+	_, _ = fmt.Fprintf(out, "Attempt:   %d (%s prompt)\n", attempt, kind)
+
+	if !showPrompt {
+		return nil
+	}
+
+	systemPrompt, userPrompt, err := controller.BuildPromptForAttempt(cmd.Context(), task, attempt)
+	if err != nil {
+		return fmt.Errorf("failed to build prompt: %w", err)
+	}
+	_, _ = fmt.Fprintf(out, "\n=== System Prompt ===\n%s\n\n", systemPrompt)
+	_, _ = fmt.Fprintf(out, "=== User Prompt ===\n%s\n", userPrompt)
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestNextCommand(t *testing.T) {
+	setup := func(t *testing.T, leafStatus taskstore.TaskStatus) string {
+		tmpDir := t.TempDir()
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, config.DefaultTasksPath))
+		require.NoError(t, err)
+		now := time.Now()
+		parent := "root"
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID: "root", Title: "Feature", Status: taskstore.StatusOpen, CreatedAt: now, UpdatedAt: now,
+		}))
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID: "setup", Title: "Set up", Description: "Create go.mod", ParentID: &parent,
+			Status: taskstore.StatusCompleted, CreatedAt: now, UpdatedAt: now,
+		}))
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID: "leaf", Title: "Add leaf", Description: "Create cmd/leaf.go", ParentID: &parent,
+			DependsOn: []string{"setup"}, Status: leafStatus, CreatedAt: now, UpdatedAt: now,
+		}))
+		require.NoError(t, state.EnsureRalphDir(tmpDir))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, config.DefaultParentIDFile), []byte("root"), 0644))
+
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+		return tmpDir
+	}
+
+	execute := func(t *testing.T, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("shows the next task and why", func(t *testing.T) {
+		setup(t, taskstore.StatusOpen)
+
+		out, err := execute(t, "next")
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "Next task: leaf\n")
+		assert.Contains(t, out, "Title:     Add leaf\n")
+		assert.Contains(t, out, "Why:       dependencies satisfied: setup (completed)\n")
+		assert.Contains(t, out, "Attempt:   1 (initial prompt)\n")
+		assert.NotContains(t, out, "=== User Prompt ===")
+	})
+
+	t.Run("prints the prompt with --prompt", func(t *testing.T) {
+		tmpDir := setup(t, taskstore.StatusOpen)
+		_, err := loop.SaveRecord(state.LogsDirPath(tmpDir), &loop.IterationRecord{
+			IterationID: "iter-1",
+			TaskID:      "leaf",
+			StartTime:   time.Now(),
+			Outcome:     loop.OutcomeFailed,
+		})
+		require.NoError(t, err)
+
+		out, err := execute(t, "next", "--prompt")
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "Attempt:   2 (retry prompt)\n")
+		assert.Contains(t, out, "=== System Prompt ===")
+		assert.Contains(t, out, "=== User Prompt ===")
+		assert.Contains(t, out, "Create cmd/leaf.go")
+	})
+
+	t.Run("reports when nothing is ready", func(t *testing.T) {
+		setup(t, taskstore.StatusCompleted)
+
+		out, err := execute(t, "next")
+		require.NoError(t, err)
+
+		assert.Equal(t, "No ready tasks under root.\n", out)
+	})
+
+	t.Run("errors without a parent task", func(t *testing.T) {
+		tmpDir := setup(t, taskstore.StatusOpen)
+		require.NoError(t, os.Remove(filepath.Join(tmpDir, config.DefaultParentIDFile)))
+
+		_, err := execute(t, "next")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Run 'ralph init' first")
+	})
+}
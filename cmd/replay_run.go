@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/reporter"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func newReplayRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "replay-run <run-id>",
+		Short: "Narrate a past run from its iteration records",
+		Long: `Narrate a past run step by step from its stored iteration records: the task
+selected at each iteration, why it was selectable, how the iteration ended, its
+cost, the files it changed, and where the run first failed.
+
+Selection reasons are reconstructed from the records (which tasks had
+completed by then) and the tasks' current dependencies. Nothing is executed:
+no agent, verify or git commands run. Use 'ralph runs' to find run IDs.
+
+Examples:
+  ralph replay-run 3f2c9a1e-...`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReplayRun(cmd, args[0])
+		},
+	}
+}
+
+func runReplayRun(cmd *cobra.Command, runID string) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	records, err := loop.LoadAllIterationRecords(state.LogsDirPath(workDir))
+	if err != nil {
+		return err
+	}
+
+	// Titles and dependencies are optional context; don't create a task
+	// store where there is none
+	var tasks []*taskstore.Task
+	tasksPath := filepath.Join(workDir, config.DefaultTasksPath)
+	_, dirErr := os.Stat(tasksPath)
+	_, dbErr := os.Stat(tasksPath + ".db")
+	if dirErr == nil || dbErr == nil {
+		if store, err := openTaskStore(workDir); err == nil {
+			tasks, _ = store.List()
+			_ = taskstore.CloseStore(store)
+		}
+	}
+
+	out, err := reporter.FormatReplay(runID, records, tasks)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), out)
+	return nil
+}
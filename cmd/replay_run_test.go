@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
+)
+
+func TestReplayRunCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+	_, err := loop.SaveRecord(state.LogsDirPath(tmpDir), &loop.IterationRecord{
+		IterationID:      "iter-1",
+		TaskID:           "task-1",
+		SessionID:        "run-one",
+		StartTime:        start,
+		EndTime:          start.Add(time.Minute),
+		Outcome:          loop.OutcomeSuccess,
+		ClaudeInvocation: loop.ClaudeInvocationMeta{TotalCostUSD: 0.25},
+	})
+	require.NoError(t, err)
+
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(tmpDir))
+
+	execute := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(append([]string{"replay-run"}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("narrates the run", func(t *testing.T) {
+		out, err := execute("run-one")
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "Run run-one\n")
+		assert.Contains(t, out, "#1 09:00:00 task-1\n")
+		assert.Contains(t, out, "   outcome:  success in 1m0s, $0.25\n")
+		assert.Contains(t, out, "No failed iterations.\n")
+		assert.NoDirExists(t, state.TasksDirPath(tmpDir), "replaying creates no task store")
+	})
+
+	t.Run("errors for an unknown run", func(t *testing.T) {
+		_, err := execute("missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `run "missing" not found`)
+	})
+}
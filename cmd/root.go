@@ -16,22 +16,55 @@ import (
 	"github.com/yarlson/ralph/internal/taskstore"
 )
 
-var cfgFile string
+var (
+	cfgFile string
+	noColor bool
+)
 
 // GetConfigFile returns the config file path from the flag.
 func GetConfigFile() string {
 	return cfgFile
 }
 
+// openTaskStore opens the task store in workDir with the configured backend.
+func openTaskStore(workDir string) (taskstore.Store, error) {
+	cfg, err := config.LoadConfigWithFile(GetConfigFile())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return taskstore.OpenStore(cfg.Tasks.Backend, filepath.Join(workDir, config.DefaultTasksPath))
+}
+
 // Root command flags
 var (
 	rootOnce          bool
+	rootFocus         string
 	rootMaxIterations int
+	rootMaxCost       float64
+	rootBudgetWarnAt  float64
 	rootParent        string
 	rootBranch        string
 	rootDryRun        bool
 	rootStream        bool
 	rootProvider      string
+	rootShuffle       bool
+	rootShuffleSeed   int64
+	rootForce         bool
+
+	rootContinueOnFailure bool
+	rootAnnotations       map[string]string
+	rootStashDirty        bool
+	rootEventSocket       string
+	rootEventLog          string
+	rootQuiet             bool
+	rootVerbose           bool
+	rootProfileRun        bool
+	rootStep              bool
+	rootNoVerifyCache     bool
+	rootIsolated          bool
+	rootConcurrency       int
+	rootEstimate          bool
+	rootWatch             bool
 )
 
 // NewRootCmd creates the root command for ralph CLI.
@@ -45,29 +78,72 @@ delegate to Claude Code → verify → commit → repeat.
 
 Optionally, you can provide a file argument:
   - A PRD .md file to decompose into tasks
-  - A task .yaml file to import tasks`,
+  - A plain-text .txt spec to decompose into tasks
+  - A task .yaml or .json file to import tasks`,
 		SilenceUsage: true,
 		Args:         cobra.MaximumNArgs(1),
 		RunE:         runRoot,
 	}
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: ~/.config/ralph/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file overlaid on ~/.config/ralph/config.yaml and ralph.yaml")
 	rootCmd.Flags().BoolVarP(&rootOnce, "once", "1", false, "run only a single iteration")
+	rootCmd.Flags().StringVar(&rootFocus, "focus", "", "run only this task, retrying it until it completes or exhausts retries")
+	rootCmd.MarkFlagsMutuallyExclusive("once", "focus")
 	rootCmd.Flags().IntVarP(&rootMaxIterations, "max-iterations", "n", 0, "maximum iterations (0 uses config)")
+	rootCmd.Flags().Float64Var(&rootMaxCost, "max-cost", 0, "stop once the run has cost this many USD (0 = unlimited)")
+	rootCmd.Flags().Float64Var(&rootBudgetWarnAt, "budget-warn-at", 0, "warn once spending reaches this fraction of --max-cost, e.g. 0.8 (0 = never)")
 	rootCmd.Flags().StringVarP(&rootParent, "parent", "p", "", "explicit parent task ID")
 	rootCmd.Flags().StringVarP(&rootBranch, "branch", "b", "", "git branch override")
 	rootCmd.Flags().BoolVar(&rootDryRun, "dry-run", false, "show what would be done")
+	rootCmd.Flags().BoolVar(&rootEstimate, "estimate", false, "print estimated decomposition tokens and cost for a PRD file, then exit")
+	rootCmd.Flags().BoolVar(&rootWatch, "watch", false, "keep running: re-import the task file (re-decompose the PRD) and resume the loop when it changes")
+	rootCmd.MarkFlagsMutuallyExclusive("watch", "estimate")
 	rootCmd.Flags().BoolVar(&rootStream, "stream", false, "stream agent output to console")
+	rootCmd.Flags().BoolVar(&rootShuffle, "shuffle", false, "randomize selection among ready tasks (off by default for determinism)")
+	rootCmd.Flags().Int64Var(&rootShuffleSeed, "shuffle-seed", 0, "seed for --shuffle (derived from the current time if not set)")
+	rootCmd.Flags().BoolVar(&rootForce, "force", false, "clear gutter history from a previous run before starting")
+	rootCmd.Flags().BoolVar(&rootContinueOnFailure, "continue-on-failure", false, "still attempt tasks whose dependencies failed (risky)")
+	rootCmd.Flags().BoolVar(&rootStashDirty, "stash-dirty", false, "stash uncommitted changes before starting instead of refusing")
+	rootCmd.Flags().StringVar(&rootEventSocket, "event-socket", "", "serve JSON run events on this Unix socket path")
+	rootCmd.Flags().StringVar(&rootEventLog, "event-log", "", "append JSON Lines run events to this file")
+	rootCmd.Flags().StringToStringVar(&rootAnnotations, "annotate", nil, "key=value metadata attached to every iteration record (repeatable)")
+	rootCmd.Flags().BoolVarP(&rootQuiet, "quiet", "q", false, "only print the final run summary")
+	rootCmd.Flags().BoolVarP(&rootVerbose, "verbose", "v", false, "also print diff stats, selection reasoning, and verification output")
+	rootCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	rootCmd.Flags().BoolVar(&rootProfileRun, "profile-run", false, "print time spent in prompt building, agent, verification, and git per iteration")
+	rootCmd.Flags().BoolVar(&rootStep, "step", false, "ask before each iteration whether to run, skip, or stop (interactive terminals only)")
+	rootCmd.MarkFlagsMutuallyExclusive("once", "step")
+	rootCmd.Flags().BoolVar(&rootNoVerifyCache, "no-verify-cache", false, "always run verify commands, even on a working tree they already passed on")
+	rootCmd.Flags().BoolVar(&rootIsolated, "isolated", false, "run in a temporary clone and fetch the resulting branch back, leaving the working directory untouched")
+	rootCmd.Flags().IntVar(&rootConcurrency, "concurrency", 1, "run up to this many independent ready tasks at once, each in its own git worktree")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output (also honors NO_COLOR)")
 	rootCmd.PersistentFlags().StringVar(&rootProvider, "provider", "", "LLM provider (claude or opencode)")
 
 	rootCmd.AddCommand(newStatusCmd())
 	rootCmd.AddCommand(newFixCmd())
+	rootCmd.AddCommand(newDiffCmd())
+	rootCmd.AddCommand(newLogsCmd())
+	rootCmd.AddCommand(newFeedbackCmd())
+	rootCmd.AddCommand(newTasksCmd())
+	rootCmd.AddCommand(newInitCmd())
+	rootCmd.AddCommand(newDecomposeCmd())
+	rootCmd.AddCommand(newBisectCmd())
+	rootCmd.AddCommand(newNextCmd())
+	rootCmd.AddCommand(newRunsCmd())
+	rootCmd.AddCommand(newReplayRunCmd())
+	rootCmd.AddCommand(newBudgetCmd())
 
 	return rootCmd
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 {
+		if rootEstimate {
+			return fmt.Errorf("--estimate requires a PRD file")
+		}
+		if rootWatch {
+			return fmt.Errorf("--watch requires a PRD or task file")
+		}
 		return runRootAutoInit(cmd)
 	}
 
@@ -81,18 +157,49 @@ func runRoot(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
-	fileType := detect.DetectFileType(string(content))
+	fileType := detect.DetectFile(filePath, string(content))
 
 	switch fileType {
-	case detect.FileTypePRD:
-		return runPRDBootstrap(cmd, filePath)
+	case detect.FileTypePRD, detect.FileTypeText:
+		if rootEstimate {
+			return runEstimate(cmd, filePath)
+		}
+		return runPRDBootstrap(cmd, filePath, fileType)
 	case detect.FileTypeTasks:
+		if rootEstimate {
+			return fmt.Errorf("--estimate requires a PRD file; %s is a task file", filePath)
+		}
 		return runYAMLBootstrap(cmd, filePath)
 	default:
 		return fmt.Errorf("unknown file type: cannot determine if %s is a PRD or task file", filePath)
 	}
 }
 
+// shuffleSeed returns the --shuffle-seed value, or nil when the flag was not
+// given so that 0 remains a usable seed.
+func shuffleSeed(cmd *cobra.Command) *int64 {
+	if !cmd.Flags().Changed("shuffle-seed") {
+		return nil
+	}
+	seed := rootShuffleSeed
+	return &seed
+}
+
+func runEstimate(cmd *cobra.Command, prdPath string) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	cfg, err := config.LoadConfigWithFile(GetConfigFile())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	opts := bootstrap.DecomposeOptions{Provider: rootProvider}
+	return bootstrap.EstimateDecomposition(prdPath, workDir, cfg, opts, cmd.OutOrStdout())
+}
+
 func runRootAutoInit(cmd *cobra.Command) error {
 	workDir, err := os.Getwd()
 	if err != nil {
@@ -141,19 +248,40 @@ func runRootAutoInit(cmd *cobra.Command) error {
 
 	opts := runner.Options{
 		Once:          rootOnce,
+		Focus:         rootFocus,
 		MaxIterations: rootMaxIterations,
+		MaxCostUSD:    rootMaxCost,
+		BudgetWarnAt:  rootBudgetWarnAt,
 		Branch:        rootBranch,
 		Stream:        rootStream,
 		Provider:      rootProvider,
+		Shuffle:       rootShuffle,
+		ShuffleSeed:   shuffleSeed(cmd),
+		Force:         rootForce,
+
+		ContinueOnFailure: rootContinueOnFailure,
+		Annotations:       rootAnnotations,
+		StashDirty:        rootStashDirty,
+		EventSocket:       rootEventSocket,
+		EventLog:          rootEventLog,
+		Quiet:             rootQuiet,
+		Verbose:           rootVerbose,
+		ProfileRun:        rootProfileRun,
+		NoColor:           noColor,
+		Step:              rootStep,
+		Stdin:             cmd.InOrStdin(),
+		NoVerifyCache:     rootNoVerifyCache,
+		Isolated:          rootIsolated,
+		Concurrency:       rootConcurrency,
 	}
 
 	return runner.Run(cmd.Context(), workDir, cfg, parentTaskID, opts, cmd.OutOrStdout(), cmd.ErrOrStderr())
 }
 
-func runPRDBootstrap(cmd *cobra.Command, prdPath string) error {
+func runPRDBootstrap(cmd *cobra.Command, prdPath string, fileType detect.FileType) error {
 	if rootDryRun {
 		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Would decompose PRD file: %s\n", prdPath)
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Detected file type: prd\n")
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Detected file type: %s\n", fileType)
 		if rootParent != "" {
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Parent task: %s\n", rootParent)
 		}
@@ -163,9 +291,15 @@ func runPRDBootstrap(cmd *cobra.Command, prdPath string) error {
 		if rootOnce {
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Would run once\n")
 		}
+		if rootFocus != "" {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Would focus on task: %s\n", rootFocus)
+		}
 		if rootMaxIterations > 0 {
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Max iterations: %d\n", rootMaxIterations)
 		}
+		if rootWatch {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Would watch %s for changes\n", prdPath)
+		}
 		return nil
 	}
 
@@ -181,11 +315,33 @@ func runPRDBootstrap(cmd *cobra.Command, prdPath string) error {
 
 	opts := bootstrap.Options{
 		Once:          rootOnce,
+		Focus:         rootFocus,
 		MaxIterations: rootMaxIterations,
+		MaxCostUSD:    rootMaxCost,
+		BudgetWarnAt:  rootBudgetWarnAt,
 		Parent:        rootParent,
 		Branch:        rootBranch,
 		Stream:        rootStream,
 		Provider:      rootProvider,
+		Shuffle:       rootShuffle,
+		ShuffleSeed:   shuffleSeed(cmd),
+		Force:         rootForce,
+
+		ContinueOnFailure: rootContinueOnFailure,
+		Annotations:       rootAnnotations,
+		StashDirty:        rootStashDirty,
+		EventSocket:       rootEventSocket,
+		EventLog:          rootEventLog,
+		Quiet:             rootQuiet,
+		Verbose:           rootVerbose,
+		ProfileRun:        rootProfileRun,
+		NoColor:           noColor,
+		Step:              rootStep,
+		Stdin:             cmd.InOrStdin(),
+		NoVerifyCache:     rootNoVerifyCache,
+		Isolated:          rootIsolated,
+		Concurrency:       rootConcurrency,
+		Watch:             rootWatch,
 	}
 
 	return bootstrap.RunFromPRD(cmd.Context(), prdPath, workDir, cfg, opts, cmd.OutOrStdout(), cmd.ErrOrStderr())
@@ -204,9 +360,15 @@ func runYAMLBootstrap(cmd *cobra.Command, yamlPath string) error {
 		if rootOnce {
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Would run once\n")
 		}
+		if rootFocus != "" {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Would focus on task: %s\n", rootFocus)
+		}
 		if rootMaxIterations > 0 {
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Max iterations: %d\n", rootMaxIterations)
 		}
+		if rootWatch {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "[dry-run] Would watch %s for changes\n", yamlPath)
+		}
 		return nil
 	}
 
@@ -222,11 +384,33 @@ func runYAMLBootstrap(cmd *cobra.Command, yamlPath string) error {
 
 	opts := bootstrap.Options{
 		Once:          rootOnce,
+		Focus:         rootFocus,
 		MaxIterations: rootMaxIterations,
+		MaxCostUSD:    rootMaxCost,
+		BudgetWarnAt:  rootBudgetWarnAt,
 		Parent:        rootParent,
 		Branch:        rootBranch,
 		Stream:        rootStream,
 		Provider:      rootProvider,
+		Shuffle:       rootShuffle,
+		ShuffleSeed:   shuffleSeed(cmd),
+		Force:         rootForce,
+
+		ContinueOnFailure: rootContinueOnFailure,
+		Annotations:       rootAnnotations,
+		StashDirty:        rootStashDirty,
+		EventSocket:       rootEventSocket,
+		EventLog:          rootEventLog,
+		Quiet:             rootQuiet,
+		Verbose:           rootVerbose,
+		ProfileRun:        rootProfileRun,
+		NoColor:           noColor,
+		Step:              rootStep,
+		Stdin:             cmd.InOrStdin(),
+		NoVerifyCache:     rootNoVerifyCache,
+		Isolated:          rootIsolated,
+		Concurrency:       rootConcurrency,
+		Watch:             rootWatch,
 	}
 
 	return bootstrap.RunFromYAML(cmd.Context(), yamlPath, workDir, cfg, opts, cmd.OutOrStdout(), cmd.ErrOrStderr())
@@ -234,10 +418,11 @@ func runYAMLBootstrap(cmd *cobra.Command, yamlPath string) error {
 
 func autoInitParentTask(cmd *cobra.Command, workDir string, cfg *config.Config) (string, bool, error) {
 	tasksPath := filepath.Join(workDir, config.DefaultTasksPath)
-	store, err := taskstore.NewLocalStore(tasksPath)
+	store, err := taskstore.OpenStore(cfg.Tasks.Backend, tasksPath)
 	if err != nil {
 		return "", false, fmt.Errorf("failed to open task store: %w", err)
 	}
+	defer func() { _ = taskstore.CloseStore(store) }()
 
 	rootTasks, err := store.ListByParent("")
 	if err != nil {
@@ -67,6 +67,71 @@ func TestRootCommand_Flags(t *testing.T) {
 		assert.Equal(t, "false", flag.DefValue)
 	})
 
+	t.Run("has --shuffle flags defaulting to off", func(t *testing.T) {
+		cmd := NewRootCmd()
+		shuffle := cmd.Flags().Lookup("shuffle")
+		require.NotNil(t, shuffle)
+		assert.Equal(t, "false", shuffle.DefValue)
+
+		seed := cmd.Flags().Lookup("shuffle-seed")
+		require.NotNil(t, seed)
+		assert.Equal(t, "0", seed.DefValue)
+	})
+
+	t.Run("an explicit --shuffle-seed 0 is kept", func(t *testing.T) {
+		cmd := NewRootCmd()
+		assert.Nil(t, shuffleSeed(cmd))
+
+		require.NoError(t, cmd.Flags().Parse([]string{"--shuffle-seed", "0"}))
+		seed := shuffleSeed(cmd)
+		require.NotNil(t, seed)
+		assert.Equal(t, int64(0), *seed)
+	})
+
+	t.Run("has --force flag", func(t *testing.T) {
+		cmd := NewRootCmd()
+		flag := cmd.Flags().Lookup("force")
+		require.NotNil(t, flag)
+		assert.Equal(t, "false", flag.DefValue)
+	})
+
+	t.Run("has --continue-on-failure flag defaulting to off", func(t *testing.T) {
+		cmd := NewRootCmd()
+		flag := cmd.Flags().Lookup("continue-on-failure")
+		require.NotNil(t, flag)
+		assert.Equal(t, "false", flag.DefValue)
+	})
+
+	t.Run("has --stash-dirty flag defaulting to off", func(t *testing.T) {
+		cmd := NewRootCmd()
+		flag := cmd.Flags().Lookup("stash-dirty")
+		require.NotNil(t, flag)
+		assert.Equal(t, "false", flag.DefValue)
+	})
+
+	t.Run("has --event-socket flag defaulting to empty", func(t *testing.T) {
+		cmd := NewRootCmd()
+		flag := cmd.Flags().Lookup("event-socket")
+		require.NotNil(t, flag)
+		assert.Equal(t, "", flag.DefValue)
+	})
+
+	t.Run("rejects --quiet with --verbose", func(t *testing.T) {
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--quiet", "--verbose"})
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "none of the others can be")
+	})
+
+	t.Run("parses repeatable --annotate flag", func(t *testing.T) {
+		cmd := NewRootCmd()
+		require.NoError(t, cmd.Flags().Parse([]string{"--annotate", "build=1234", "--annotate", "pr=42"}))
+		assert.Equal(t, map[string]string{"build": "1234", "pr": "42"}, rootAnnotations)
+	})
+
 	t.Run("accepts optional file argument", func(t *testing.T) {
 		cmd := NewRootCmd()
 		var buf bytes.Buffer
@@ -89,7 +154,7 @@ func TestRootCommand_FileValidation(t *testing.T) {
 
 	t.Run("errors on unknown file type", func(t *testing.T) {
 		tmpDir := t.TempDir()
-		unknownPath := filepath.Join(tmpDir, "random.txt")
+		unknownPath := filepath.Join(tmpDir, "random.md")
 		require.NoError(t, os.WriteFile(unknownPath, []byte("Hello world"), 0644))
 
 		cmd := NewRootCmd()
@@ -128,6 +193,88 @@ func TestRootCommand_FileValidation(t *testing.T) {
 		assert.Contains(t, out.String(), "[dry-run]")
 		assert.Contains(t, out.String(), "task")
 	})
+
+	t.Run("dry-run detects plain-text spec", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		txtPath := filepath.Join(tmpDir, "notes.txt")
+		require.NoError(t, os.WriteFile(txtPath, []byte("Build a CLI that greets the user."), 0644))
+
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--dry-run", txtPath})
+		err := cmd.Execute()
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "Would decompose")
+		assert.Contains(t, out.String(), "Detected file type: text")
+	})
+
+	t.Run("estimate prints the projected cost of a PRD", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		oldWd, _ := os.Getwd()
+		defer func() { _ = os.Chdir(oldWd) }()
+		require.NoError(t, os.Chdir(tmpDir))
+		require.NoError(t, os.WriteFile("prd.md", []byte("# Product\n\n## Objectives\n\nBuild something."), 0644))
+
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--estimate", "prd.md"})
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "Estimate for prd.md (no tasks generated)")
+		assert.Contains(t, out.String(), "Cost:          ~$")
+		assert.NoDirExists(t, filepath.Join(tmpDir, ".ralph", "tasks"))
+	})
+
+	t.Run("estimate rejects task files", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		yamlPath := filepath.Join(tmpDir, "tasks.yaml")
+		require.NoError(t, os.WriteFile(yamlPath, []byte("tasks:\n  - id: a\n"), 0644))
+
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--estimate", yamlPath})
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--estimate requires a PRD file")
+	})
+
+	t.Run("dry-run reports watch mode", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		yamlPath := filepath.Join(tmpDir, "tasks.yaml")
+		require.NoError(t, os.WriteFile(yamlPath, []byte("tasks:\n  - id: a\n"), 0644))
+
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--dry-run", "--watch", yamlPath})
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, out.String(), "Would watch "+yamlPath+" for changes")
+	})
+
+	t.Run("watch requires a file", func(t *testing.T) {
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"--watch"})
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--watch requires a PRD or task file")
+	})
+
+	t.Run("dry-run detects JSON task file", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		jsonPath := filepath.Join(tmpDir, "spec.json")
+		require.NoError(t, os.WriteFile(jsonPath, []byte(`{"tasks": [{"id": "task-001", "title": "Do something"}]}`), 0644))
+
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--dry-run", jsonPath})
+		err := cmd.Execute()
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "Would import task file")
+		assert.Contains(t, out.String(), "Detected file type: tasks")
+	})
 }
 
 func TestRootCommand_NoTasks(t *testing.T) {
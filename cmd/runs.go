@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/reporter"
+	"github.com/yarlson/ralph/internal/state"
+)
+
+func newRunsCmd() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "runs",
+		Short: "List every run and its outcome",
+		Long: `List every run recorded in this repository, oldest first: its ID, when it
+started and ended, the parent task, the outcome, the number of iterations,
+and the total cost. Runs are reconstructed from the session ID on iteration
+records, so iterations recorded before sessions were tracked are not listed.
+
+The outcome is inferred from how each task's last iteration in the run ended:
+completed (all succeeded), partial (some succeeded), or failed (none did).
+
+Examples:
+  ralph runs
+  ralph runs --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRuns(cmd, asJSON)
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the runs as a JSON array")
+
+	return cmd
+}
+
+func runRuns(cmd *cobra.Command, asJSON bool) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	records, err := loop.LoadAllIterationRecords(state.LogsDirPath(workDir))
+	if err != nil {
+		return err
+	}
+	runs := reporter.SummarizeSessions(records)
+
+	out := cmd.OutOrStdout()
+	if !asJSON {
+		_, _ = fmt.Fprint(out, reporter.FormatRuns(runs))
+		return nil
+	}
+
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal runs: %w", err)
+	}
+	_, _ = fmt.Fprintln(out, string(data))
+	return nil
+}
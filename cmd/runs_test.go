@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/reporter"
+	"github.com/yarlson/ralph/internal/state"
+)
+
+func TestRunsCommand(t *testing.T) {
+	setup := func(t *testing.T) {
+		tmpDir := t.TempDir()
+		logsDir := state.LogsDirPath(tmpDir)
+		start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.Local)
+		runs := []struct {
+			session string
+			outcome loop.IterationOutcome
+		}{
+			{"run-one", loop.OutcomeSuccess},
+			{"run-one", loop.OutcomeSuccess},
+			{"run-two", loop.OutcomeFailed},
+		}
+		for i, r := range runs {
+			_, err := loop.SaveRecord(logsDir, &loop.IterationRecord{
+				IterationID:      fmt.Sprintf("iter-%d", i),
+				TaskID:           fmt.Sprintf("task-%d", i),
+				SessionID:        r.session,
+				ParentTaskID:     "feature",
+				StartTime:        start.Add(time.Duration(i) * 24 * time.Hour),
+				EndTime:          start.Add(time.Duration(i)*24*time.Hour + 10*time.Minute),
+				Outcome:          r.outcome,
+				ClaudeInvocation: loop.ClaudeInvocationMeta{TotalCostUSD: 1.0},
+			})
+			require.NoError(t, err)
+		}
+
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+	}
+
+	execute := func(t *testing.T, args ...string) string {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"runs"}, args...))
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	t.Run("lists one row per run", func(t *testing.T) {
+		setup(t)
+
+		out := execute(t)
+
+		assert.Contains(t, out, "RUN      STARTED           ENDED             PARENT   OUTCOME    ITERATIONS  COST\n")
+		assert.Contains(t, out, "run-one  2026-03-02 09:00  2026-03-03 09:10  feature  completed  2           $2.00\n")
+		assert.Contains(t, out, "run-two  2026-03-04 09:00  2026-03-04 09:10  feature  failed     1           $1.00\n")
+	})
+
+	t.Run("prints JSON with --json", func(t *testing.T) {
+		setup(t)
+
+		var runs []reporter.SessionSummary
+		require.NoError(t, json.Unmarshal([]byte(execute(t, "--json")), &runs))
+
+		require.Len(t, runs, 2)
+		assert.Equal(t, "run-one", runs[0].ID)
+		assert.Equal(t, reporter.SessionCompleted, runs[0].Outcome)
+		assert.Equal(t, "feature", runs[0].ParentTaskID)
+		assert.Equal(t, 2, runs[0].Iterations)
+		assert.Equal(t, reporter.SessionFailed, runs[1].Outcome)
+	})
+
+	t.Run("reports when there are no runs", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+
+		assert.Equal(t, "No runs found.\n", execute(t))
+		assert.Equal(t, "[]\n", execute(t, "--json"))
+	})
+}
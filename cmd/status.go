@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
 
+	"github.com/yarlson/ralph/internal/color"
 	"github.com/yarlson/ralph/internal/config"
 	"github.com/yarlson/ralph/internal/reporter"
 	"github.com/yarlson/ralph/internal/state"
@@ -14,17 +16,26 @@ import (
 )
 
 func newStatusCmd() *cobra.Command {
-	return &cobra.Command{
+	var asJSON bool
+
+	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show current status",
-		Long:  "Display task counts, next selected task, and last iteration outcome.",
+		Long: `Display task counts, next selected task, and last iteration outcome.
+
+With --json, the same status is printed as a JSON object for scripts and
+dashboards.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runStatus(cmd)
+			return runStatus(cmd, asJSON)
 		},
 	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "print the status as a JSON object")
+
+	return cmd
 }
 
-func runStatus(cmd *cobra.Command) error {
+func runStatus(cmd *cobra.Command, asJSON bool) error {
 	// Get working directory
 	workDir, err := os.Getwd()
 	if err != nil {
@@ -43,11 +54,11 @@ func runStatus(cmd *cobra.Command) error {
 	parentTaskID := string(parentIDBytes)
 
 	// Open task store
-	tasksPath := filepath.Join(workDir, config.DefaultTasksPath)
-	store, err := taskstore.NewLocalStore(tasksPath)
+	store, err := openTaskStore(workDir)
 	if err != nil {
 		return fmt.Errorf("failed to open task store: %w", err)
 	}
+	defer func() { _ = taskstore.CloseStore(store) }()
 
 	// Validate parent task exists
 	_, err = store.Get(parentTaskID)
@@ -68,8 +79,18 @@ func runStatus(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to get status: %w", err)
 	}
 
+	out := cmd.OutOrStdout()
+	if asJSON {
+		data, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal status: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, string(data))
+		return nil
+	}
+
 	// Format and output
-	_, _ = fmt.Fprint(cmd.OutOrStdout(), reporter.FormatStatus(status))
+	_, _ = fmt.Fprint(out, reporter.FormatStatusColor(status, color.New(color.Enabled(out, noColor))))
 
 	return nil
 }
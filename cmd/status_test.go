@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -162,4 +163,57 @@ func TestStatusCommand(t *testing.T) {
 		assert.Contains(t, output, "Ready: 1")
 		assert.Contains(t, output, "Failed: 1")
 	})
+
+	t.Run("prints status as JSON", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, ".ralph", "tasks"))
+		require.NoError(t, err)
+
+		parentID := "feature"
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID:        parentID,
+			Title:     "Feature",
+			Status:    taskstore.StatusOpen,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}))
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID:        "ready",
+			Title:     "Ready Task",
+			Status:    taskstore.StatusOpen,
+			ParentID:  &parentID,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}))
+
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".ralph", "logs"), 0755))
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".ralph", "state"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".ralph", "state", "feedback-ready.txt"), []byte("use the new API"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".ralph", "parent-task-id"), []byte(parentID), 0644))
+
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"status", "--json"})
+
+		oldWd, _ := os.Getwd()
+		require.NoError(t, os.Chdir(tmpDir))
+		defer func() { _ = os.Chdir(oldWd) }()
+
+		require.NoError(t, cmd.Execute())
+
+		var status map[string]any
+		require.NoError(t, json.Unmarshal(out.Bytes(), &status))
+		assert.Equal(t, "feature", status["parent_task_id"])
+		assert.Equal(t, map[string]any{
+			"total": 1.0, "completed": 0.0, "ready": 1.0, "blocked": 0.0,
+			"failed": 0.0, "skipped": 0.0, "awaiting_review": 0.0,
+		}, status["counts"])
+		require.IsType(t, map[string]any{}, status["next_task"])
+		assert.Equal(t, "ready", status["next_task"].(map[string]any)["id"])
+		assert.Contains(t, status, "last_iteration")
+		assert.Nil(t, status["last_iteration"])
+		assert.Equal(t, "use the new API", status["next_task_feedback"])
+	})
 }
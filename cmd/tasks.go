@@ -0,0 +1,518 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/detect"
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/memory"
+	"github.com/yarlson/ralph/internal/reporter"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func newTasksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tasks",
+		Short: "Inspect and maintain tasks",
+	}
+
+	cmd.AddCommand(newTasksInferVerifyCmd())
+	cmd.AddCommand(newTasksAuditCmd())
+	cmd.AddCommand(newTasksRenameCmd())
+	cmd.AddCommand(newTasksPromptCmd())
+	cmd.AddCommand(newTasksValidateVerifyCmd())
+	cmd.AddCommand(newTasksVerifyCompletedCmd())
+	cmd.AddCommand(newTasksStatsCmd())
+	cmd.AddCommand(newTasksMergeCmd())
+
+	return cmd
+}
+
+func newTasksInferVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "infer-verify",
+		Short: "Fill in missing verify commands from the project language",
+		Long: `Detect the project language from go.mod, package.json, or Cargo.toml and
+add default verify commands (go test ./..., npm test, cargo test) to leaf
+tasks that have none. Existing verify commands are never overwritten.
+
+Examples:
+  ralph tasks infer-verify`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTasksInferVerify(cmd)
+		},
+	}
+}
+
+func runTasksInferVerify(cmd *cobra.Command) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	commands := detect.DetectVerifyCommands(workDir)
+	if len(commands) == 0 {
+		return fmt.Errorf("no go.mod, package.json, or Cargo.toml found in %s", workDir)
+	}
+
+	store, err := openTaskStore(workDir)
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+
+	updated, err := taskstore.FillMissingVerify(store, commands)
+	if err != nil {
+		return err
+	}
+
+	if len(updated) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "All leaf tasks already have verify commands")
+		return nil
+	}
+
+	formatted := make([]string, len(commands))
+	for i, c := range commands {
+		formatted[i] = strings.Join(c, " ")
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Added verify commands (%s) to %d task(s):\n", strings.Join(formatted, "; "), len(updated))
+	for _, task := range updated {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  - %s (%s)\n", task.ID, task.Title)
+	}
+
+	return nil
+}
+
+func newTasksAuditCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "audit <task-id>",
+		Short: "Compare a task's planned file changes with its actual changes",
+		Long: `Extract the file paths declared in a task's description and acceptance
+criteria and compare them with the files changed by its successful
+iterations. Reports files changed without being declared and declared
+files that were never changed.
+
+Examples:
+  ralph tasks audit my-feature-add-fix-command`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTasksAudit(cmd, args[0])
+		},
+	}
+}
+
+func runTasksAudit(cmd *cobra.Command, taskID string) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	store, err := openTaskStore(workDir)
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+
+	audit, err := reporter.AuditTaskScope(store, state.LogsDirPath(workDir), taskID)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), reporter.FormatScopeAudit(audit))
+	return nil
+}
+
+func newTasksRenameCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <id> <new-id>",
+		Short: "Rename a task and rewrite all references to it",
+		Long: `Change a task's ID and rewrite every parentId and dependsOn that references
+it. The rename is refused if it would leave the task set invalid.
+
+Per-task state is migrated too: feedback and skip-reason files, the stored
+parent task ID, and the task ID in iteration records.
+
+Examples:
+  ralph tasks rename auth-login auth-password-login`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTasksRename(cmd, args[0], args[1])
+		},
+	}
+}
+
+func runTasksRename(cmd *cobra.Command, oldID, newID string) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	store, err := openTaskStore(workDir)
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+
+	updated, err := taskstore.RenameTask(store, oldID, newID)
+	if err != nil {
+		return err
+	}
+
+	if err := state.RenameTaskStateFiles(workDir, oldID, newID); err != nil {
+		return err
+	}
+
+	records, err := loop.RenameTaskInRecords(state.LogsDirPath(workDir), oldID, newID)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Renamed task %s to %s\n", oldID, newID)
+	for _, task := range updated {
+		_, _ = fmt.Fprintf(out, "  - updated references in %s\n", task.ID)
+	}
+	if records > 0 {
+		_, _ = fmt.Fprintf(out, "  - updated %d iteration record(s)\n", records)
+	}
+
+	return nil
+}
+
+func newTasksPromptCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prompt <task-id>",
+		Short: "Print the prompt the next iteration would send for a task",
+		Long: `Build and print the system and user prompts the loop would send for a
+task's next attempt, without invoking the agent. Tasks with failed iterations
+since their last success get the retry prompt, including the previous failure
+output and any feedback left with 'ralph fix --retry'.
+
+Examples:
+  ralph tasks prompt my-feature-add-fix-command`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTasksPrompt(cmd, args[0])
+		},
+	}
+}
+
+func runTasksPrompt(cmd *cobra.Command, taskID string) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	cfg, err := config.LoadConfigWithFile(GetConfigFile())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := taskstore.OpenStore(cfg.Tasks.Backend, filepath.Join(workDir, config.DefaultTasksPath))
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+
+	task, err := store.Get(taskID)
+	if err != nil {
+		return fmt.Errorf("task %q not found: %w", taskID, err)
+	}
+
+	logsDir := state.LogsDirPath(workDir)
+	attempt, err := loop.NextAttemptNumber(logsDir, taskID)
+	if err != nil {
+		return err
+	}
+
+	controller := loop.NewController(loop.ControllerDeps{
+		TaskStore:    store,
+		Git:          git.NewShellManager(workDir, config.DefaultBranchPrefix),
+		LogsDir:      logsDir,
+		ProgressFile: memory.NewProgressFile(filepath.Join(workDir, config.DefaultProgressFile)),
+		WorkDir:      workDir,
+	})
+	controller.SetCheckpoints(cfg.Experimental.Checkpoints)
+	controller.SetVerifySets(cfg.Verify.Sets)
+
+	systemPrompt, userPrompt, err := controller.BuildPromptForAttempt(cmd.Context(), task, attempt)
+	if err != nil {
+		return fmt.Errorf("failed to build prompt: %w", err)
+	}
+
+	kind := "initial"
+	if attempt > 1 {
+		kind = "retry"
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Task: %s (attempt %d, %s prompt)\n\n", task.ID, attempt, kind)
+	_, _ = fmt.Fprintf(out, "=== System Prompt ===\n%s\n\n", systemPrompt)
+	_, _ = fmt.Fprintf(out, "=== User Prompt ===\n%s\n", userPrompt)
+	return nil
+}
+
+func newTasksValidateVerifyCmd() *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "validate-verify",
+		Short: "Run every task's verify commands once and report the results",
+		Long: `Run each task's verify commands against the current codebase and report
+which pass, fail, or error (cannot run at all). Commands shared by several
+tasks run once. This is a baseline for the plan's verification setup: a task
+whose verify already fails before any work is likely mis-specified or depends
+on unfinished work.
+
+Verify commands are expected to be read-only; a warning is printed if running
+them changed the working tree. Exits non-zero if any command did not pass.
+
+Examples:
+  ralph tasks validate-verify
+  ralph tasks validate-verify --timeout 2m`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTasksValidateVerify(cmd, timeout)
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "maximum time per verify command (0 for no limit)")
+
+	return cmd
+}
+
+func runTasksValidateVerify(cmd *cobra.Command, timeout time.Duration) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	cfg, err := config.LoadConfigWithFile(GetConfigFile())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := taskstore.OpenStore(cfg.Tasks.Backend, filepath.Join(workDir, config.DefaultTasksPath))
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+
+	ver := verifier.NewCommandRunner(workDir)
+	if cfg.Safety.Sandbox && len(cfg.Safety.AllowedCommands) > 0 {
+		ver.SetAllowedCommands(cfg.Safety.AllowedCommands)
+	}
+
+	// Snapshot the working tree to detect verify commands that write to it
+	gitManager := git.NewShellManager(workDir, config.DefaultBranchPrefix)
+	before, beforeErr := gitManager.GetChangedFiles(cmd.Context())
+
+	baseline, err := reporter.RunVerifyBaseline(cmd.Context(), store, cfg.Verify.Sets, ver, timeout)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprint(out, reporter.FormatVerifyBaseline(baseline))
+
+	if beforeErr == nil {
+		after, err := gitManager.GetChangedFiles(cmd.Context())
+		if err == nil && !slices.Equal(before, after) {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: verify commands changed the working tree (%d file(s) with changes before, %d after)\n", len(before), len(after))
+		}
+	}
+
+	if _, failed, errored := baseline.Counts(); failed+errored > 0 {
+		return fmt.Errorf("%d of %d verify command(s) did not pass", failed+errored, len(baseline.Checks))
+	}
+	return nil
+}
+
+func newTasksVerifyCompletedCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify-completed",
+		Short: "Flag completed tasks whose changes appear to have been undone",
+		Long: `For each completed task, check the files changed by the iteration that
+completed it against the working tree. A task is flagged when a file it
+created or modified no longer exists, a file it deleted is back, or a file's
+content has been reverted to what it was before the task. Later edits on top
+of a task's work are not flagged.
+
+Tasks completed without a successful iteration (e.g. by hand) are listed but
+cannot be checked. Exits non-zero if any completed task appears undone.
+
+Examples:
+  ralph tasks verify-completed`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTasksVerifyCompleted(cmd)
+		},
+	}
+}
+
+func runTasksVerifyCompleted(cmd *cobra.Command) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	store, err := openTaskStore(workDir)
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+
+	gitManager := git.NewShellManager(workDir, config.DefaultBranchPrefix)
+	result, err := reporter.VerifyCompleted(cmd.Context(), store, state.LogsDirPath(workDir), gitManager)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), reporter.FormatCompletedVerification(result))
+
+	if undone := result.UndoneTasks(); len(undone) > 0 {
+		return fmt.Errorf("%d completed task(s) appear undone", len(undone))
+	}
+	return nil
+}
+
+func newTasksStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show quality metrics for the task set",
+		Long: `Summarize the shape of the task set to judge a decomposition before
+running it: average acceptance criteria per leaf, average and maximum depth,
+leaves without verify commands, tasks without dependencies, and the largest
+group of sibling tasks. Unlike 'ralph status', this describes the plan, not
+run progress.
+
+Examples:
+  ralph tasks stats`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTasksStats(cmd)
+		},
+	}
+}
+
+func runTasksStats(cmd *cobra.Command) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	store, err := openTaskStore(workDir)
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+
+	stats, err := reporter.ComputeTaskStats(store)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprint(cmd.OutOrStdout(), reporter.FormatTaskStats(stats))
+	return nil
+}
+
+func newTasksMergeCmd() *cobra.Command {
+	var (
+		output string
+		prefix bool
+		root   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "merge <file> <file>...",
+		Short: "Merge separately authored task YAML files into one",
+		Long: `Combine task YAML files authored in pieces (for example one per subsystem)
+into a single file that can be imported with 'ralph <file>'.
+
+Task IDs must be unique across the files. With --prefix, a task whose ID is
+already used by an earlier file is renamed to <file>-<id> (for example
+"setup" in billing.yaml becomes "billing-setup"), and the parentId and
+dependsOn references to it in its own file are rewritten. With --root, a
+root task with that title becomes the parent of every file's top-level
+tasks. The merged tasks are validated as a whole, so cycles, dependencies
+on missing tasks and orphaned parents are caught before anything is written.
+
+Without -o, the merged YAML is printed.
+
+Examples:
+  ralph tasks merge api.yaml web.yaml -o tasks.yaml
+  ralph tasks merge api.yaml web.yaml --prefix --root "Launch v2" -o tasks.yaml`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTasksMerge(cmd, args, output, taskstore.MergeYAMLOptions{Prefix: prefix, RootTitle: root})
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "file to write the merged YAML to (default: print it)")
+	cmd.Flags().BoolVar(&prefix, "prefix", false, "rename colliding task IDs to <file>-<id> instead of failing")
+	cmd.Flags().StringVar(&root, "root", "", "title of a root task to parent every file's top-level tasks")
+
+	return cmd
+}
+
+func runTasksMerge(cmd *cobra.Command, paths []string, output string, opts taskstore.MergeYAMLOptions) error {
+	sources := make([]taskstore.YAMLSource, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		file, err := taskstore.ParseYAML(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		sources = append(sources, taskstore.YAMLSource{Name: path, File: file})
+	}
+
+	result, err := taskstore.MergeYAML(sources, opts)
+	if err != nil {
+		var collision *taskstore.IDCollisionError
+		if errors.As(err, &collision) {
+			return fmt.Errorf("%w (use --prefix to rename colliding IDs)", err)
+		}
+		return err
+	}
+
+	data, err := yaml.Marshal(result.File)
+	if err != nil {
+		return fmt.Errorf("failed to encode merged tasks: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if output == "" {
+		_, _ = out.Write(data)
+		return nil
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	_, _ = fmt.Fprintf(out, "Merged %d tasks from %d files into %s\n", len(result.File.Tasks), len(paths), output)
+	if opts.RootTitle != "" {
+		_, _ = fmt.Fprintf(out, "  - root task: %s\n", result.File.Tasks[0].ID)
+	}
+	for _, r := range result.Renames {
+		_, _ = fmt.Fprintf(out, "  - renamed %s to %s (%s)\n", r.OldID, r.NewID, r.Source)
+	}
+	for _, w := range result.Warnings {
+		_, _ = fmt.Fprintf(out, "  warning: %s\n", w)
+	}
+	return nil
+}
@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestTasksInferVerifyCommand(t *testing.T) {
+	setup := func(t *testing.T, manifest string) *taskstore.LocalStore {
+		tmpDir := t.TempDir()
+		if manifest != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, manifest), []byte{}, 0644))
+		}
+
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, config.DefaultTasksPath))
+		require.NoError(t, err)
+		now := time.Now()
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID: "leaf", Title: "Leaf", Status: taskstore.StatusOpen, CreatedAt: now, UpdatedAt: now,
+		}))
+
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+		return store
+	}
+
+	t.Run("fills verify commands for detected language", func(t *testing.T) {
+		store := setup(t, "Cargo.toml")
+
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"tasks", "infer-verify"})
+
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, out.String(), "cargo test")
+		assert.Contains(t, out.String(), "leaf")
+
+		task, err := store.Get("leaf")
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"cargo", "test"}}, task.Verify)
+	})
+
+	t.Run("fails when no language detected", func(t *testing.T) {
+		setup(t, "")
+
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"tasks", "infer-verify"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no go.mod")
+	})
+}
+
+func TestTasksAuditCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, config.DefaultTasksPath))
+	require.NoError(t, err)
+	now := time.Now()
+	require.NoError(t, store.Save(&taskstore.Task{
+		ID: "leaf", Title: "Leaf", Description: "Create cmd/leaf.go", Status: taskstore.StatusCompleted, CreatedAt: now, UpdatedAt: now,
+	}))
+
+	_, err = loop.SaveRecord(state.LogsDirPath(tmpDir), &loop.IterationRecord{
+		IterationID:  "iter-1",
+		TaskID:       "leaf",
+		Outcome:      loop.OutcomeSuccess,
+		FilesChanged: []string{"cmd/leaf.go", "cmd/root.go"},
+	})
+	require.NoError(t, err)
+
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(tmpDir))
+
+	cmd := NewRootCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"tasks", "audit", "leaf"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "Changed but not planned")
+	assert.Contains(t, out.String(), "+ cmd/root.go")
+}
+
+func TestTasksRenameCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, config.DefaultTasksPath))
+	require.NoError(t, err)
+	now := time.Now()
+	require.NoError(t, store.Save(&taskstore.Task{
+		ID: "old", Title: "Old", Status: taskstore.StatusOpen, CreatedAt: now, UpdatedAt: now,
+	}))
+	require.NoError(t, store.Save(&taskstore.Task{
+		ID: "dependent", Title: "Dependent", DependsOn: []string{"old"}, Status: taskstore.StatusOpen, CreatedAt: now, UpdatedAt: now,
+	}))
+	require.NoError(t, state.EnsureRalphDir(tmpDir))
+	require.NoError(t, os.WriteFile(filepath.Join(state.StateDirPath(tmpDir), "feedback-old.txt"), []byte("hint"), 0644))
+	_, err = loop.SaveRecord(state.LogsDirPath(tmpDir), &loop.IterationRecord{IterationID: "iter-1", TaskID: "old"})
+	require.NoError(t, err)
+
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(tmpDir))
+
+	cmd := NewRootCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"tasks", "rename", "old", "new"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "Renamed task old to new")
+	assert.Contains(t, out.String(), "updated references in dependent")
+	assert.Contains(t, out.String(), "updated 1 iteration record(s)")
+
+	dependent, err := store.Get("dependent")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new"}, dependent.DependsOn)
+	assert.FileExists(t, filepath.Join(state.StateDirPath(tmpDir), "feedback-new.txt"))
+}
+
+func TestTasksPromptCommand(t *testing.T) {
+	setup := func(t *testing.T) string {
+		tmpDir := t.TempDir()
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, config.DefaultTasksPath))
+		require.NoError(t, err)
+		now := time.Now()
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID: "leaf", Title: "Add leaf", Description: "Create cmd/leaf.go", Status: taskstore.StatusOpen,
+			Verify: [][]string{{"go", "test", "./..."}}, CreatedAt: now, UpdatedAt: now,
+		}))
+
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+		return tmpDir
+	}
+
+	execute := func(t *testing.T, args ...string) (string, error) {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("prints initial prompt for a fresh task", func(t *testing.T) {
+		setup(t)
+
+		out, err := execute(t, "tasks", "prompt", "leaf")
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "Task: leaf (attempt 1, initial prompt)")
+		assert.Contains(t, out, "=== System Prompt ===")
+		assert.Contains(t, out, "=== User Prompt ===")
+		assert.Contains(t, out, "Create cmd/leaf.go")
+	})
+
+	t.Run("prints retry prompt after a failed iteration", func(t *testing.T) {
+		tmpDir := setup(t)
+		_, err := loop.SaveRecord(state.LogsDirPath(tmpDir), &loop.IterationRecord{
+			IterationID: "iter-1",
+			TaskID:      "leaf",
+			StartTime:   time.Now(),
+			Outcome:     loop.OutcomeFailed,
+			VerificationOutputs: []loop.VerificationOutput{
+				{Command: []string{"go", "test", "./..."}, Passed: false, Output: "FAIL TestLeaf"},
+			},
+		})
+		require.NoError(t, err)
+
+		out, err := execute(t, "tasks", "prompt", "leaf")
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "Task: leaf (attempt 2, retry prompt)")
+		assert.Contains(t, out, "FAIL TestLeaf")
+	})
+
+	t.Run("errors for unknown task", func(t *testing.T) {
+		setup(t)
+
+		_, err := execute(t, "tasks", "prompt", "missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `task "missing" not found`)
+	})
+}
+
+func TestTasksValidateVerifyCommand(t *testing.T) {
+	setup := func(t *testing.T, verify [][]string) {
+		tmpDir := t.TempDir()
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, config.DefaultTasksPath))
+		require.NoError(t, err)
+		now := time.Now()
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID: "leaf", Title: "Leaf", Status: taskstore.StatusOpen,
+			Verify: verify, CreatedAt: now, UpdatedAt: now,
+		}))
+
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+	}
+
+	execute := func(args ...string) (string, error) {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("passes when every command passes", func(t *testing.T) {
+		setup(t, [][]string{{"true"}})
+
+		out, err := execute("tasks", "validate-verify")
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "leaf (Leaf) [open]\n  ✓ true\n")
+		assert.Contains(t, out, "1 passed, 0 failed, 0 error(s)")
+	})
+
+	t.Run("fails when a command fails or cannot run", func(t *testing.T) {
+		setup(t, [][]string{{"false"}, {"ralph-missing-command-xyz"}})
+
+		out, err := execute("tasks", "validate-verify", "--timeout", "1m")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2 of 2 verify command(s) did not pass")
+
+		assert.Contains(t, out, "✗ false: fail")
+		assert.Contains(t, out, "! ralph-missing-command-xyz: error")
+		assert.Contains(t, out, "Verification already fails before work on: leaf")
+	})
+}
+
+func TestTasksVerifyCompletedCommand(t *testing.T) {
+	setup := func(t *testing.T, files ...string) {
+		tmpDir := t.TempDir()
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, config.DefaultTasksPath))
+		require.NoError(t, err)
+		now := time.Now()
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID: "done", Title: "Done", Status: taskstore.StatusCompleted, CreatedAt: now, UpdatedAt: now,
+		}))
+		_, err = loop.SaveRecord(state.LogsDirPath(tmpDir), &loop.IterationRecord{
+			IterationID: "iter-1", TaskID: "done", Outcome: loop.OutcomeSuccess, FilesChanged: []string{"main.go"},
+		})
+		require.NoError(t, err)
+		for _, f := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, f), []byte("package main\n"), 0644))
+		}
+
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+	}
+
+	execute := func() (string, error) {
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"tasks", "verify-completed"})
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("passes when the work is still there", func(t *testing.T) {
+		setup(t, "main.go")
+
+		out, err := execute()
+		require.NoError(t, err)
+		assert.Contains(t, out, "No completed work appears undone.")
+	})
+
+	t.Run("fails when a changed file is gone", func(t *testing.T) {
+		setup(t)
+
+		out, err := execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "1 completed task(s) appear undone")
+		assert.Contains(t, out, "main.go: deleted since the task completed")
+	})
+}
+
+func TestTasksStatsCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, config.DefaultTasksPath))
+	require.NoError(t, err)
+	now := time.Now()
+	parentID := "root"
+	require.NoError(t, store.Save(&taskstore.Task{ID: "root", Title: "Root", Status: taskstore.StatusOpen, CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, store.Save(&taskstore.Task{
+		ID: "leaf", Title: "Leaf", Status: taskstore.StatusOpen, ParentID: &parentID,
+		Acceptance: []string{"works"}, CreatedAt: now, UpdatedAt: now,
+	}))
+
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	require.NoError(t, os.Chdir(tmpDir))
+
+	cmd := NewRootCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"tasks", "stats"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Contains(t, out.String(), "Leaf tasks")
+	assert.Contains(t, out.String(), "Leaves without verify commands    1 (leaf)")
+	assert.Contains(t, out.String(), "Largest sibling group             1 (root tasks)")
+}
+
+func TestTasksMergeCommand(t *testing.T) {
+	setup := func(t *testing.T) string {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "api.yaml"), []byte(`tasks:
+  - id: api
+    title: API
+    description: Build the API
+  - id: setup
+    title: Set up the API
+    description: Create the server
+    parentId: api
+    verify: [["go", "test", "./..."]]
+`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "web.yaml"), []byte(`tasks:
+  - id: web
+    title: Web
+    description: Build the web app
+    dependsOn: [api]
+  - id: setup
+    title: Set up the web app
+    description: Create the app shell
+    parentId: web
+    verify: [["npm", "test"]]
+`), 0644))
+
+		origDir, _ := os.Getwd()
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+		require.NoError(t, os.Chdir(tmpDir))
+		return tmpDir
+	}
+
+	t.Run("suggests --prefix on collisions", func(t *testing.T) {
+		setup(t)
+
+		cmd := NewRootCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"tasks", "merge", "api.yaml", "web.yaml"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "task setup in web.yaml is also defined in api.yaml (use --prefix")
+	})
+
+	t.Run("writes the merged file", func(t *testing.T) {
+		tmpDir := setup(t)
+
+		cmd := NewRootCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"tasks", "merge", "api.yaml", "web.yaml", "--prefix", "--root", "Launch", "-o", "combined.yaml"})
+
+		require.NoError(t, cmd.Execute())
+
+		assert.Contains(t, out.String(), "Merged 5 tasks from 2 files into combined.yaml")
+		assert.Contains(t, out.String(), "renamed setup to web-setup (web.yaml)")
+
+		data, err := os.ReadFile(filepath.Join(tmpDir, "combined.yaml"))
+		require.NoError(t, err)
+		file, err := taskstore.ParseYAML(data)
+		require.NoError(t, err)
+		require.Len(t, file.Tasks, 5)
+		assert.Equal(t, "launch", file.Tasks[0].ID)
+		assert.Equal(t, "launch", file.Tasks[3].ParentID)
+		assert.Equal(t, "web-setup", file.Tasks[4].ID)
+		assert.Equal(t, "web", file.Tasks[4].ParentID)
+	})
+}
@@ -133,16 +133,22 @@ func IsInteractive(fd uintptr) bool {
 
 // UndoConfirmationInfo contains the information to display in the undo confirmation prompt.
 type UndoConfirmationInfo struct {
-	// IterationID is the ID of the iteration being undone.
-	IterationID string
+	// IterationIDs are the IDs of the iterations being undone.
+	IterationIDs []string
 	// CommitToResetTo is the commit hash to reset to.
 	CommitToResetTo string
-	// TaskToReopen is the task ID that will be reopened (empty if none).
-	TaskToReopen string
-	// FilesToRevert is the list of files that will be reverted.
+	// TasksToReopen are the task IDs that will be reopened.
+	TasksToReopen []string
+	// FilesToRevert is the combined list of files that will be reverted.
 	FilesToRevert []string
 	// HasUncommittedChanges indicates if there are uncommitted changes that will be lost.
 	HasUncommittedChanges bool
+	// CompletedDependents lists completed tasks that depend on TasksToReopen.
+	CompletedDependents []string
+	// Cascade indicates CompletedDependents will be reopened too.
+	Cascade bool
+	// Stash indicates uncommitted changes will be stashed and restored.
+	Stash bool
 }
 
 // ConfirmUndo displays a confirmation prompt for the undo operation and reads the user's response.
@@ -150,7 +156,11 @@ type UndoConfirmationInfo struct {
 // Returns true if the user confirms, false otherwise.
 func ConfirmUndo(w io.Writer, r io.Reader, info UndoConfirmationInfo) (bool, error) {
 	// Show what will happen
-	_, _ = fmt.Fprintf(w, "Undo iteration %s:\n\n", info.IterationID)
+	if len(info.IterationIDs) == 1 {
+		_, _ = fmt.Fprintf(w, "Undo iteration %s:\n\n", info.IterationIDs[0])
+	} else {
+		_, _ = fmt.Fprintf(w, "Undo %d iterations: %s\n\n", len(info.IterationIDs), strings.Join(info.IterationIDs, ", "))
+	}
 
 	// Show commit to reset to (short hash)
 	shortHash := info.CommitToResetTo
@@ -159,9 +169,13 @@ func ConfirmUndo(w io.Writer, r io.Reader, info UndoConfirmationInfo) (bool, err
 	}
 	_, _ = fmt.Fprintf(w, "  Commit to reset to: %s\n", shortHash)
 
-	// Show task to reopen (if any)
-	if info.TaskToReopen != "" {
-		_, _ = fmt.Fprintf(w, "  Task to reopen: %s\n", info.TaskToReopen)
+	// Show tasks to reopen (if any)
+	switch len(info.TasksToReopen) {
+	case 0:
+	case 1:
+		_, _ = fmt.Fprintf(w, "  Task to reopen: %s\n", info.TasksToReopen[0])
+	default:
+		_, _ = fmt.Fprintf(w, "  Tasks to reopen: %s\n", strings.Join(info.TasksToReopen, ", "))
 	}
 
 	// Show files to revert
@@ -173,9 +187,25 @@ func ConfirmUndo(w io.Writer, r io.Reader, info UndoConfirmationInfo) (bool, err
 	}
 	_, _ = fmt.Fprintln(w)
 
+	// Show or warn about completed dependents of the reopened task
+	if len(info.CompletedDependents) > 0 {
+		if info.Cascade {
+			_, _ = fmt.Fprintf(w, "Dependent tasks to reopen:\n")
+		} else {
+			_, _ = fmt.Fprintf(w, "WARNING: These completed tasks depend on %s and will stay completed (use --cascade to reopen them):\n", strings.Join(info.TasksToReopen, ", "))
+		}
+		for _, id := range info.CompletedDependents {
+			_, _ = fmt.Fprintf(w, "    - %s\n", id)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
 	// Warn about uncommitted changes
-	if info.HasUncommittedChanges {
-		_, _ = fmt.Fprintln(w, "WARNING: You have uncommitted changes that will be lost!")
+	if info.HasUncommittedChanges && info.Stash {
+		_, _ = fmt.Fprintln(w, "Uncommitted changes will be stashed and restored after the undo.")
+		_, _ = fmt.Fprintln(w)
+	} else if info.HasUncommittedChanges {
+		_, _ = fmt.Fprintln(w, "WARNING: You have uncommitted changes that will be lost! (use --stash to keep them)")
 		_, _ = fmt.Fprintln(w)
 	}
 
@@ -192,6 +222,64 @@ func ConfirmUndo(w io.Writer, r io.Reader, info UndoConfirmationInfo) (bool, err
 	return response == "yes" || response == "y", nil
 }
 
+// TaskDiffEntry describes a task added, removed, or changed by a new decomposition.
+type TaskDiffEntry struct {
+	// ID is the task identifier.
+	ID string
+	// Title is the task title (the new title for changed tasks).
+	Title string
+	// Fields lists the changed fields (changed tasks only).
+	Fields []string
+}
+
+// DecompositionDiffInfo contains the information to display in the decomposition confirmation prompt.
+type DecompositionDiffInfo struct {
+	Added   []TaskDiffEntry
+	Removed []TaskDiffEntry
+	Changed []TaskDiffEntry
+}
+
+// ConfirmDecomposition displays how a new decomposition differs from the existing tasks
+// and asks whether to replace them. Returns true if the user confirms, false otherwise.
+func ConfirmDecomposition(w io.Writer, r io.Reader, info DecompositionDiffInfo) (bool, error) {
+	_, _ = fmt.Fprintf(w, "New decomposition differs from the existing tasks:\n\n")
+
+	sections := []struct {
+		label   string
+		marker  string
+		entries []TaskDiffEntry
+	}{
+		{"Added", "+", info.Added},
+		{"Removed", "-", info.Removed},
+		{"Changed", "~", info.Changed},
+	}
+	for _, section := range sections {
+		if len(section.entries) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "  %s (%d):\n", section.label, len(section.entries))
+		for _, e := range section.entries {
+			_, _ = fmt.Fprintf(w, "    %s %s  %s", section.marker, e.ID, e.Title)
+			if len(e.Fields) > 0 {
+				_, _ = fmt.Fprintf(w, " (%s)", strings.Join(e.Fields, ", "))
+			}
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+	_, _ = fmt.Fprintln(w)
+
+	_, _ = fmt.Fprint(w, "Replace existing tasks? (yes/no): ")
+
+	reader := bufio.NewReader(r)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "yes" || response == "y", nil
+}
+
 // FixInteractiveMode runs the interactive fix mode.
 // It displays issues and iterations, prompts for commands, and executes actions.
 // Commands: r <id> (retry), s <id> (skip), u <id> (undo), rf <id> (retry with feedback), q (quit).
@@ -46,9 +46,9 @@ func TestConfirmUndo_ShowsAllInfo(t *testing.T) {
 	in := bytes.NewReader([]byte("no\n"))
 
 	info := UndoConfirmationInfo{
-		IterationID:           "abc123",
+		IterationIDs:          []string{"abc123"},
 		CommitToResetTo:       "a1b2c3d4e5f6g7h8",
-		TaskToReopen:          "task-42",
+		TasksToReopen:         []string{"task-42"},
 		FilesToRevert:         []string{"file1.go", "file2.go"},
 		HasUncommittedChanges: false,
 	}
@@ -69,12 +69,34 @@ func TestConfirmUndo_ShowsAllInfo(t *testing.T) {
 	assert.Contains(t, output, "file2.go")            // File
 }
 
+func TestConfirmUndo_MultipleIterations(t *testing.T) {
+	var out bytes.Buffer
+	in := bytes.NewReader([]byte("no\n"))
+
+	info := UndoConfirmationInfo{
+		IterationIDs:    []string{"iter-1", "iter-2", "iter-3"},
+		CommitToResetTo: "a1b2c3d4e5f6g7h8",
+		TasksToReopen:   []string{"task-a", "task-b"},
+		FilesToRevert:   []string{"a.go", "b.go"},
+	}
+
+	result, err := ConfirmUndo(&out, in, info)
+	require.NoError(t, err)
+	assert.False(t, result)
+
+	output := out.String()
+	assert.Contains(t, output, "Undo 3 iterations: iter-1, iter-2, iter-3")
+	assert.Contains(t, output, "Tasks to reopen: task-a, task-b")
+	assert.Contains(t, output, "- a.go")
+	assert.Contains(t, output, "- b.go")
+}
+
 func TestConfirmUndo_ShowsWarningForUncommittedChanges(t *testing.T) {
 	var out bytes.Buffer
 	in := bytes.NewReader([]byte("no\n"))
 
 	info := UndoConfirmationInfo{
-		IterationID:           "abc123",
+		IterationIDs:          []string{"abc123"},
 		CommitToResetTo:       "a1b2c3d4e5f6g7h8",
 		HasUncommittedChanges: true,
 	}
@@ -88,12 +110,60 @@ func TestConfirmUndo_ShowsWarningForUncommittedChanges(t *testing.T) {
 	assert.Contains(t, output, "uncommitted changes")
 }
 
+func TestConfirmUndo_StashKeepsUncommittedChanges(t *testing.T) {
+	var out bytes.Buffer
+	in := bytes.NewReader([]byte("no\n"))
+
+	info := UndoConfirmationInfo{
+		IterationIDs:          []string{"abc123"},
+		CommitToResetTo:       "a1b2c3d4e5f6g7h8",
+		HasUncommittedChanges: true,
+		Stash:                 true,
+	}
+
+	_, err := ConfirmUndo(&out, in, info)
+	require.NoError(t, err)
+
+	output := out.String()
+	assert.NotContains(t, output, "WARNING")
+	assert.Contains(t, output, "stashed and restored")
+}
+
+func TestConfirmUndo_CompletedDependents(t *testing.T) {
+	tests := []struct {
+		name    string
+		cascade bool
+		want    string
+	}{
+		{name: "warns without cascade", cascade: false, want: "--cascade"},
+		{name: "lists dependents to reopen with cascade", cascade: true, want: "Dependent tasks to reopen"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			info := UndoConfirmationInfo{
+				IterationIDs:        []string{"abc123"},
+				CommitToResetTo:     "a1b2c3d4",
+				TasksToReopen:       []string{"task-a"},
+				CompletedDependents: []string{"task-b"},
+				Cascade:             tt.cascade,
+			}
+
+			_, err := ConfirmUndo(&out, bytes.NewReader([]byte("no\n")), info)
+			require.NoError(t, err)
+			assert.Contains(t, out.String(), tt.want)
+			assert.Contains(t, out.String(), "- task-b")
+		})
+	}
+}
+
 func TestConfirmUndo_AcceptsYes(t *testing.T) {
 	var out bytes.Buffer
 	in := bytes.NewReader([]byte("yes\n"))
 
 	info := UndoConfirmationInfo{
-		IterationID:     "abc123",
+		IterationIDs:    []string{"abc123"},
 		CommitToResetTo: "a1b2c3d4e5f6g7h8",
 	}
 
@@ -107,7 +177,7 @@ func TestConfirmUndo_AcceptsY(t *testing.T) {
 	in := bytes.NewReader([]byte("y\n"))
 
 	info := UndoConfirmationInfo{
-		IterationID:     "abc123",
+		IterationIDs:    []string{"abc123"},
 		CommitToResetTo: "a1b2c3d4e5f6g7h8",
 	}
 
@@ -121,7 +191,7 @@ func TestConfirmUndo_RejectsNo(t *testing.T) {
 	in := bytes.NewReader([]byte("no\n"))
 
 	info := UndoConfirmationInfo{
-		IterationID:     "abc123",
+		IterationIDs:    []string{"abc123"},
 		CommitToResetTo: "a1b2c3d4e5f6g7h8",
 	}
 
@@ -135,7 +205,7 @@ func TestConfirmUndo_RejectsOther(t *testing.T) {
 	in := bytes.NewReader([]byte("maybe\n"))
 
 	info := UndoConfirmationInfo{
-		IterationID:     "abc123",
+		IterationIDs:    []string{"abc123"},
 		CommitToResetTo: "a1b2c3d4e5f6g7h8",
 	}
 
@@ -149,9 +219,9 @@ func TestConfirmUndo_HidesTaskWhenEmpty(t *testing.T) {
 	in := bytes.NewReader([]byte("no\n"))
 
 	info := UndoConfirmationInfo{
-		IterationID:     "abc123",
+		IterationIDs:    []string{"abc123"},
 		CommitToResetTo: "a1b2c3d4e5f6g7h8",
-		TaskToReopen:    "", // Empty task
+		TasksToReopen:   nil, // No task
 	}
 
 	result, err := ConfirmUndo(&out, in, info)
@@ -167,7 +237,7 @@ func TestConfirmUndo_HidesFilesWhenEmpty(t *testing.T) {
 	in := bytes.NewReader([]byte("no\n"))
 
 	info := UndoConfirmationInfo{
-		IterationID:     "abc123",
+		IterationIDs:    []string{"abc123"},
 		CommitToResetTo: "a1b2c3d4e5f6g7h8",
 		FilesToRevert:   nil, // Empty files
 	}
@@ -182,6 +252,41 @@ func TestConfirmUndo_HidesFilesWhenEmpty(t *testing.T) {
 
 // FixInteractiveMode tests
 
+func TestConfirmDecomposition(t *testing.T) {
+	info := DecompositionDiffInfo{
+		Added:   []TaskDiffEntry{{ID: "app-new", Title: "New task"}},
+		Removed: []TaskDiffEntry{{ID: "app-old", Title: "Old task"}},
+		Changed: []TaskDiffEntry{{ID: "app-api", Title: "Build API", Fields: []string{"title", "verify"}}},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "accepts yes", input: "yes\n", want: true},
+		{name: "accepts y", input: "y\n", want: true},
+		{name: "rejects no", input: "no\n", want: false},
+		{name: "rejects other", input: "maybe\n", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := ConfirmDecomposition(&out, bytes.NewReader([]byte(tt.input)), info)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			output := out.String()
+			assert.Contains(t, output, "Added (1):")
+			assert.Contains(t, output, "+ app-new  New task")
+			assert.Contains(t, output, "- app-old  Old task")
+			assert.Contains(t, output, "~ app-api  Build API (title, verify)")
+			assert.Contains(t, output, "Replace existing tasks? (yes/no):")
+		})
+	}
+}
+
 func TestFixInteractiveMode_ShowsIssuesList(t *testing.T) {
 	var out bytes.Buffer
 	// Input: 'q' to quit immediately
@@ -0,0 +1,21 @@
+// Package atomicfile writes files so that readers never see a partial write.
+package atomicfile
+
+import "os"
+
+// WriteFile writes data to a temp file next to path, then renames it into
+// place. The temp file is removed if either step fails.
+func WriteFile(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
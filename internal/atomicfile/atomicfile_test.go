@@ -0,0 +1,31 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	t.Run("replaces the file and leaves no temp file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "out.json")
+		require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+		require.NoError(t, WriteFile(path, []byte("new")))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(data))
+		assert.NoFileExists(t, path+".tmp")
+	})
+
+	t.Run("cleans up when the directory is missing", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "out.json")
+
+		assert.Error(t, WriteFile(path, []byte("data")))
+		assert.NoFileExists(t, path+".tmp")
+	})
+}
@@ -0,0 +1,179 @@
+// Package bisect finds the iteration that introduced a regression by running
+// a check against each iteration's result commit.
+package bisect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// Git is the subset of git operations bisect needs.
+type Git interface {
+	GetCurrentBranch(ctx context.Context) (string, error)
+	GetCurrentCommit(ctx context.Context) (string, error)
+	GetChangedFiles(ctx context.Context) ([]string, error)
+	Checkout(ctx context.Context, ref string) error
+}
+
+// Checker runs the check command.
+type Checker interface {
+	Verify(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error)
+}
+
+// Step is the outcome of the check at one iteration's result commit.
+type Step struct {
+	Record *loop.IterationRecord
+	Result verifier.VerificationResult
+}
+
+// Result is the outcome of a bisect.
+type Result struct {
+	// Steps lists every check that ran, in the order it ran: the latest
+	// iteration first, then earlier iterations from the oldest.
+	Steps []Step
+
+	// FirstBad is the earliest iteration whose result commit fails the check,
+	// or nil if the latest iteration passes.
+	FirstBad *loop.IterationRecord
+
+	// LastGood is the iteration before FirstBad, whose result commit passes
+	// the check. It is nil when FirstBad is the first iteration.
+	LastGood *loop.IterationRecord
+}
+
+// Iterations returns the records that produced a commit, oldest first. When
+// several records share a result commit, only the first is kept.
+func Iterations(records []*loop.IterationRecord) []*loop.IterationRecord {
+	var iterations []*loop.IterationRecord
+	for _, r := range records {
+		if r.ResultCommit != "" {
+			iterations = append(iterations, r)
+		}
+	}
+	sort.SliceStable(iterations, func(i, j int) bool {
+		return iterations[i].StartTime.Before(iterations[j].StartTime)
+	})
+
+	seen := make(map[string]bool, len(iterations))
+	unique := iterations[:0]
+	for _, r := range iterations {
+		if !seen[r.ResultCommit] {
+			seen[r.ResultCommit] = true
+			unique = append(unique, r)
+		}
+	}
+	return unique
+}
+
+// Run checks out the result commit of each iteration in records and runs
+// check there, reporting the first iteration where the check started failing.
+// The latest iteration is checked first; if it passes there is no regression.
+// Otherwise earlier iterations are checked in order until one fails. onStep,
+// if set, is called after each check. The original branch (or commit, if HEAD
+// was detached) is checked out again before Run returns.
+func Run(ctx context.Context, g Git, checker Checker, check []string, records []*loop.IterationRecord, onStep func(Step)) (result *Result, err error) {
+	iterations := Iterations(records)
+	if len(iterations) == 0 {
+		return nil, errors.New("no iterations with a result commit to bisect")
+	}
+
+	if err := ensureClean(ctx, g); err != nil {
+		return nil, err
+	}
+
+	original, err := g.GetCurrentBranch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if original == "HEAD" {
+		if original, err = g.GetCurrentCommit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get current commit: %w", err)
+		}
+	}
+	defer func() {
+		if restoreErr := g.Checkout(context.WithoutCancel(ctx), original); restoreErr != nil && err == nil {
+			err = fmt.Errorf("failed to check out %s again: %w", original, restoreErr)
+		}
+	}()
+
+	result = &Result{}
+	checkAt := func(record *loop.IterationRecord) (bool, error) {
+		if err := g.Checkout(ctx, record.ResultCommit); err != nil {
+			return false, fmt.Errorf("failed to check out %s: %w", record.ResultCommit, err)
+		}
+		results, err := checker.Verify(ctx, [][]string{check})
+		if err != nil {
+			return false, fmt.Errorf("check failed to run: %w", err)
+		}
+		if len(results) != 1 {
+			return false, fmt.Errorf("check returned %d results, want 1", len(results))
+		}
+		if results[0].Error != "" {
+			return false, fmt.Errorf("check could not run at %s: %s", record.ResultCommit, results[0].Error)
+		}
+
+		step := Step{Record: record, Result: results[0]}
+		result.Steps = append(result.Steps, step)
+		if onStep != nil {
+			onStep(step)
+		}
+		return step.Result.Passed, nil
+	}
+
+	latest := iterations[len(iterations)-1]
+	passed, err := checkAt(latest)
+	if err != nil {
+		return nil, err
+	}
+	if passed {
+		return result, nil
+	}
+
+	for i, record := range iterations[:len(iterations)-1] {
+		passed, err := checkAt(record)
+		if err != nil {
+			return nil, err
+		}
+		if !passed {
+			result.FirstBad = record
+			if i > 0 {
+				result.LastGood = iterations[i-1]
+			}
+			return result, nil
+		}
+	}
+
+	result.FirstBad = latest
+	if len(iterations) > 1 {
+		result.LastGood = iterations[len(iterations)-2]
+	}
+	return result, nil
+}
+
+// ensureClean refuses to bisect when files outside .ralph have uncommitted
+// changes, which checking out other commits could carry along or overwrite.
+func ensureClean(ctx context.Context, g Git) error {
+	files, err := g.GetChangedFiles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check working tree: %w", err)
+	}
+
+	var dirty []string
+	for _, f := range files {
+		if f != state.RalphDir && !strings.HasPrefix(f, state.RalphDir+"/") {
+			dirty = append(dirty, f)
+		}
+	}
+	if len(dirty) > 0 {
+		return fmt.Errorf("%w: %s (commit or stash them before bisecting)", git.ErrDirtyWorkingTree, strings.Join(dirty, ", "))
+	}
+	return nil
+}
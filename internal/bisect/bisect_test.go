@@ -0,0 +1,180 @@
+package bisect
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+type mockGit struct {
+	branch    string
+	commit    string
+	changed   []string
+	checkouts []string
+	head      string
+}
+
+func (m *mockGit) GetCurrentBranch(ctx context.Context) (string, error) { return m.branch, nil }
+func (m *mockGit) GetCurrentCommit(ctx context.Context) (string, error) { return m.commit, nil }
+func (m *mockGit) GetChangedFiles(ctx context.Context) ([]string, error) {
+	return m.changed, nil
+}
+func (m *mockGit) Checkout(ctx context.Context, ref string) error {
+	m.checkouts = append(m.checkouts, ref)
+	m.head = ref
+	return nil
+}
+
+// mockChecker passes unless the checked-out commit is in failing.
+type mockChecker struct {
+	git     *mockGit
+	failing map[string]bool
+	errAt   string
+}
+
+func (m *mockChecker) Verify(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+	result := verifier.VerificationResult{Command: commands[0], Passed: !m.failing[m.git.head]}
+	if m.git.head == m.errAt {
+		result.Passed = false
+		result.Error = "executable file not found"
+	}
+	return []verifier.VerificationResult{result}, nil
+}
+
+func newRecord(id, commit string, start time.Time) *loop.IterationRecord {
+	return &loop.IterationRecord{IterationID: id, TaskID: "task-" + id, ResultCommit: commit, StartTime: start}
+}
+
+func testRecords() []*loop.IterationRecord {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []*loop.IterationRecord{
+		newRecord("3", "c3", start.Add(3*time.Minute)),
+		newRecord("1", "c1", start.Add(1*time.Minute)),
+		newRecord("failed", "", start.Add(2*time.Minute)),
+		newRecord("2", "c2", start.Add(2*time.Minute)),
+		newRecord("4", "c4", start.Add(4*time.Minute)),
+	}
+}
+
+func TestIterations(t *testing.T) {
+	records := append(testRecords(), newRecord("dup", "c2", time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC)))
+
+	var ids []string
+	for _, r := range Iterations(records) {
+		ids = append(ids, r.IterationID)
+	}
+
+	assert.Equal(t, []string{"1", "2", "3", "4"}, ids)
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		failing      []string
+		wantFirstBad string
+		wantLastGood string
+		wantChecked  []string
+	}{
+		{
+			name:        "latest passes",
+			wantChecked: []string{"c4"},
+		},
+		{
+			name:         "regression in the middle",
+			failing:      []string{"c3", "c4"},
+			wantFirstBad: "3",
+			wantLastGood: "2",
+			wantChecked:  []string{"c4", "c1", "c2", "c3"},
+		},
+		{
+			name:         "regression in the latest iteration",
+			failing:      []string{"c4"},
+			wantFirstBad: "4",
+			wantLastGood: "3",
+			wantChecked:  []string{"c4", "c1", "c2", "c3"},
+		},
+		{
+			name:         "failing from the first iteration",
+			failing:      []string{"c1", "c2", "c3", "c4"},
+			wantFirstBad: "1",
+			wantChecked:  []string{"c4", "c1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &mockGit{branch: "ralph/feature"}
+			checker := &mockChecker{git: g, failing: map[string]bool{}}
+			for _, c := range tt.failing {
+				checker.failing[c] = true
+			}
+
+			var checked []string
+			result, err := Run(context.Background(), g, checker, []string{"sh", "-c", "go test ./..."}, testRecords(), func(s Step) {
+				checked = append(checked, s.Record.ResultCommit)
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantChecked, checked)
+			assert.Len(t, result.Steps, len(tt.wantChecked))
+			if tt.wantFirstBad == "" {
+				assert.Nil(t, result.FirstBad)
+			} else {
+				require.NotNil(t, result.FirstBad)
+				assert.Equal(t, tt.wantFirstBad, result.FirstBad.IterationID)
+			}
+			if tt.wantLastGood == "" {
+				assert.Nil(t, result.LastGood)
+			} else {
+				require.NotNil(t, result.LastGood)
+				assert.Equal(t, tt.wantLastGood, result.LastGood.IterationID)
+			}
+			assert.Equal(t, "ralph/feature", g.head, "the original branch should be checked out again")
+		})
+	}
+}
+
+func TestRun_RestoresDetachedHead(t *testing.T) {
+	g := &mockGit{branch: "HEAD", commit: "abc123"}
+
+	_, err := Run(context.Background(), g, &mockChecker{git: g}, []string{"true"}, testRecords(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "abc123", g.head)
+}
+
+func TestRun_Errors(t *testing.T) {
+	t.Run("no iterations with commits", func(t *testing.T) {
+		g := &mockGit{branch: "main"}
+		_, err := Run(context.Background(), g, &mockChecker{git: g}, []string{"true"}, nil, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no iterations")
+	})
+
+	t.Run("dirty working tree", func(t *testing.T) {
+		g := &mockGit{branch: "main", changed: []string{".ralph/logs/x.json", "main.go"}}
+		_, err := Run(context.Background(), g, &mockChecker{git: g}, []string{"true"}, testRecords(), nil)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, git.ErrDirtyWorkingTree))
+		assert.Contains(t, err.Error(), "main.go")
+		assert.NotContains(t, err.Error(), ".ralph")
+		assert.Empty(t, g.checkouts)
+	})
+
+	t.Run("check cannot run", func(t *testing.T) {
+		g := &mockGit{branch: "main"}
+		checker := &mockChecker{git: g, failing: map[string]bool{"c4": true}, errAt: "c1"}
+		_, err := Run(context.Background(), g, checker, []string{"missing"}, testRecords(), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "executable file not found")
+		assert.Equal(t, "main", g.head)
+	})
+}
@@ -3,6 +3,7 @@ package bootstrap
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -24,15 +25,43 @@ import (
 // Options configures a bootstrap run.
 type Options struct {
 	Once          bool
+	Focus         string
 	MaxIterations int
+	MaxCostUSD    float64
+	BudgetWarnAt  float64
 	Parent        string
 	Branch        string
 	Stream        bool
 	Provider      string
+	Shuffle       bool
+	ShuffleSeed   *int64
+	Force         bool
+
+	ContinueOnFailure bool
+	Annotations       map[string]string
+	StashDirty        bool
+	EventSocket       string
+	EventLog          string
+	Quiet             bool
+	Verbose           bool
+	ProfileRun        bool
+	NoColor           bool
+	Step              bool
+	Stdin             io.Reader
+	NoVerifyCache     bool
+	Isolated          bool
+	Concurrency       int
+	Watch             bool
 }
 
 // RunFromPRD runs the full pipeline: decompose → import → init → run.
 func RunFromPRD(ctx context.Context, prdPath, workDir string, cfg *config.Config, opts Options, stdout, stderr io.Writer) error {
+	lock, err := state.AcquireLock(workDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+
 	_, _ = fmt.Fprintf(stdout, "Analyzing PRD: %s\n", prdPath)
 
 	providerName, err := provider.Resolve(opts.Provider, cfg.Provider)
@@ -41,13 +70,14 @@ func RunFromPRD(ctx context.Context, prdPath, workDir string, cfg *config.Config
 	}
 
 	// Step 1: Decompose PRD to YAML
-	yamlPath, err := decomposePRD(ctx, prdPath, workDir, cfg, providerName, stdout)
+	yamlPath, err := decomposePRD(ctx, prdPath, workDir, cfg, providerName, "", false, stdout)
 	if err != nil {
 		return err
 	}
 
 	// Step 2: Import tasks
-	if err := importTasks(yamlPath, cfg, stdout); err != nil {
+	imported, err := importTasks(yamlPath, cfg, stdout)
+	if err != nil {
 		return err
 	}
 
@@ -60,17 +90,62 @@ func RunFromPRD(ctx context.Context, prdPath, workDir string, cfg *config.Config
 	// Step 4: Run
 	runOpts := runner.Options{
 		Once:          opts.Once,
+		Focus:         opts.Focus,
 		MaxIterations: opts.MaxIterations,
+		MaxCostUSD:    opts.MaxCostUSD,
+		BudgetWarnAt:  opts.BudgetWarnAt,
 		Branch:        opts.Branch,
 		Stream:        opts.Stream,
 		Provider:      providerName,
+		Shuffle:       opts.Shuffle,
+		ShuffleSeed:   opts.ShuffleSeed,
+		Force:         opts.Force,
+
+		ContinueOnFailure: opts.ContinueOnFailure,
+		Annotations:       opts.Annotations,
+		StashDirty:        opts.StashDirty,
+		EventSocket:       opts.EventSocket,
+		EventLog:          opts.EventLog,
+		Quiet:             opts.Quiet,
+		Verbose:           opts.Verbose,
+		ProfileRun:        opts.ProfileRun,
+		NoColor:           opts.NoColor,
+		Step:              opts.Step,
+		Stdin:             opts.Stdin,
+		NoVerifyCache:     opts.NoVerifyCache,
+		Isolated:          opts.Isolated,
+		Concurrency:       opts.Concurrency,
+	}
+	if err := runner.Run(ctx, workDir, cfg, parentTaskID, runOpts, stdout, stderr); err != nil || !opts.Watch {
+		return err
 	}
-	return runner.Run(ctx, workDir, cfg, parentTaskID, runOpts, stdout, stderr)
+
+	session := &watchSession{
+		prdPath:      prdPath,
+		tasksPath:    yamlPath,
+		workDir:      workDir,
+		cfg:          cfg,
+		providerName: providerName,
+		parent:       opts.Parent,
+		parentTaskID: parentTaskID,
+		listed:       imported.IDs,
+		runOpts:      runOpts,
+		stdout:       stdout,
+		stderr:       stderr,
+	}
+	return session.watch(ctx)
 }
 
-// RunFromYAML runs the pipeline: import → init → run.
+// RunFromYAML runs the pipeline: import → init → run. The task file may be
+// YAML or, with a .json extension, JSON.
 func RunFromYAML(ctx context.Context, yamlPath, workDir string, cfg *config.Config, opts Options, stdout, stderr io.Writer) error {
-	_, _ = fmt.Fprintf(stdout, "Initializing from YAML: %s\n", yamlPath)
+	lock, err := state.AcquireLock(workDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+
+	_, _ = fmt.Fprintf(stdout, "Initializing from task file: %s\n", yamlPath)
 
 	providerName, err := provider.Resolve(opts.Provider, cfg.Provider)
 	if err != nil {
@@ -78,7 +153,8 @@ func RunFromYAML(ctx context.Context, yamlPath, workDir string, cfg *config.Conf
 	}
 
 	// Step 1: Import tasks
-	if err := importTasks(yamlPath, cfg, stdout); err != nil {
+	imported, err := importTasks(yamlPath, cfg, stdout)
+	if err != nil {
 		return err
 	}
 
@@ -91,21 +167,103 @@ func RunFromYAML(ctx context.Context, yamlPath, workDir string, cfg *config.Conf
 	// Step 3: Run
 	runOpts := runner.Options{
 		Once:          opts.Once,
+		Focus:         opts.Focus,
 		MaxIterations: opts.MaxIterations,
+		MaxCostUSD:    opts.MaxCostUSD,
+		BudgetWarnAt:  opts.BudgetWarnAt,
 		Branch:        opts.Branch,
 		Stream:        opts.Stream,
 		Provider:      providerName,
+		Shuffle:       opts.Shuffle,
+		ShuffleSeed:   opts.ShuffleSeed,
+		Force:         opts.Force,
+
+		ContinueOnFailure: opts.ContinueOnFailure,
+		Annotations:       opts.Annotations,
+		StashDirty:        opts.StashDirty,
+		EventSocket:       opts.EventSocket,
+		EventLog:          opts.EventLog,
+		Quiet:             opts.Quiet,
+		Verbose:           opts.Verbose,
+		ProfileRun:        opts.ProfileRun,
+		NoColor:           opts.NoColor,
+		Step:              opts.Step,
+		Stdin:             opts.Stdin,
+		NoVerifyCache:     opts.NoVerifyCache,
+		Isolated:          opts.Isolated,
+		Concurrency:       opts.Concurrency,
+	}
+	if err := runner.Run(ctx, workDir, cfg, parentTaskID, runOpts, stdout, stderr); err != nil || !opts.Watch {
+		return err
+	}
+
+	session := &watchSession{
+		tasksPath:    yamlPath,
+		workDir:      workDir,
+		cfg:          cfg,
+		providerName: providerName,
+		parent:       opts.Parent,
+		parentTaskID: parentTaskID,
+		listed:       imported.IDs,
+		runOpts:      runOpts,
+		stdout:       stdout,
+		stderr:       stderr,
+	}
+	return session.watch(ctx)
+}
+
+func decomposePRD(ctx context.Context, prdPath, workDir string, cfg *config.Config, providerName, model string, fromCache bool, output io.Writer) (string, error) {
+	dec, err := newDecomposer(workDir, cfg, providerName)
+	if err != nil {
+		return "", err
+	}
+
+	req := decomposeRequest(prdPath, workDir, model, fromCache, cfg.Decompose, output)
+	printDecomposeStart(req, providerName, output)
+
+	ctx, cancel := decomposeContext(ctx, req)
+	defer cancel()
+
+	result, err := dec.Decompose(ctx, req)
+	if err != nil {
+		return "", decomposeError(err, prdPath)
+	}
+
+	outputPath := filepath.Join(workDir, "tasks.yaml")
+	if err := os.WriteFile(outputPath, []byte(result.YAMLContent), 0644); err != nil {
+		return "", fmt.Errorf("failed to write tasks file: %w", err)
+	}
+
+	taskCount := countTasksInYAML(result.YAMLContent)
+
+	_, _ = fmt.Fprintf(output, "✓ Generated %d tasks: %s\n", taskCount, outputPath)
+	printDecomposeResult(result, output)
+
+	return outputPath, nil
+}
+
+// decomposeTimeout bounds a single PRD decomposition, including validation
+// retries, or each section of a sectioned one.
+const decomposeTimeout = 5 * time.Minute
+
+// decomposeContext bounds a decomposition by decomposeTimeout. Sectioned
+// decompositions are bounded per section instead (see
+// DecomposeRequest.SectionTimeout), since a long PRD may take longer overall.
+func decomposeContext(ctx context.Context, req decomposer.DecomposeRequest) (context.Context, context.CancelFunc) {
+	if req.Sectioned {
+		return context.WithCancel(ctx)
 	}
-	return runner.Run(ctx, workDir, cfg, parentTaskID, runOpts, stdout, stderr)
+	return context.WithTimeout(ctx, decomposeTimeout)
 }
 
-func decomposePRD(ctx context.Context, prdPath, workDir string, cfg *config.Config, providerName string, output io.Writer) (string, error) {
+// newDecomposer creates a decomposer backed by the configured provider.
+func newDecomposer(workDir string, cfg *config.Config, providerName string) (*decomposer.Decomposer, error) {
 	providerLogsDir := state.ClaudeLogsDirPath(workDir)
 	if providerName == provider.OpenCode {
 		providerLogsDir = state.OpenCodeLogsDirPath(workDir)
 	}
 	if err := os.MkdirAll(providerLogsDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create provider logs directory: %w", err)
+		return nil, fmt.Errorf("failed to create provider logs directory: %w", err)
 	}
 
 	var runnerImpl claude.Runner
@@ -142,39 +300,61 @@ func decomposePRD(ctx context.Context, prdPath, workDir string, cfg *config.Conf
 		runnerImpl = claudeRunner
 	}
 
-	dec := decomposer.NewDecomposer(runnerImpl)
-
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-	defer cancel()
+	return decomposer.NewDecomposer(runnerImpl), nil
+}
 
-	providerLabel := providerName
+// providerLabel returns the display name of a provider.
+func providerLabel(providerName string) string {
 	switch providerName {
 	case provider.Claude:
-		providerLabel = "Claude"
+		return "Claude"
 	case provider.OpenCode:
-		providerLabel = "OpenCode"
+		return "OpenCode"
 	}
+	return providerName
+}
 
-	_, _ = fmt.Fprintf(output, "Using %s to analyze and generate tasks...\n", providerLabel)
-
-	req := decomposer.DecomposeRequest{
-		PRDPath: prdPath,
-		WorkDir: workDir,
+// decomposeRequest builds a decomposition request that caches YAML failing
+// validation in the state directory, or resumes from it when fromCache is set.
+// Sectioned decompositions checkpoint completed sections in the state
+// directory too. Progress is written to output.
+func decomposeRequest(prdPath, workDir, model string, fromCache bool, cfg config.DecomposeConfig, output io.Writer) decomposer.DecomposeRequest {
+	return decomposer.DecomposeRequest{
+		PRDPath:        prdPath,
+		WorkDir:        workDir,
+		Model:          model,
+		CachePath:      state.DecomposeCacheFilePath(workDir),
+		FromCache:      fromCache,
+		MaxDepth:       cfg.MaxDepth,
+		Sectioned:      cfg.Sectioned && !fromCache,
+		CheckpointPath: state.DecomposeSectionsFilePath(workDir),
+		SectionTimeout: decomposeTimeout,
+		Progress:       output,
+
+		SystemPromptFile: cfg.SystemPromptFile,
 	}
+}
 
-	result, err := dec.Decompose(ctx, req)
-	if err != nil {
-		return "", fmt.Errorf("decomposition failed: %w", err)
+func printDecomposeStart(req decomposer.DecomposeRequest, providerName string, output io.Writer) {
+	if req.FromCache {
+		_, _ = fmt.Fprintf(output, "Resuming from cached decomposition: %s\n", req.CachePath)
+		return
 	}
+	_, _ = fmt.Fprintf(output, "Using %s to analyze and generate tasks...\n", providerLabel(providerName))
+}
 
-	outputPath := filepath.Join(workDir, "tasks.yaml")
-	if err := os.WriteFile(outputPath, []byte(result.YAMLContent), 0644); err != nil {
-		return "", fmt.Errorf("failed to write tasks file: %w", err)
+// decomposeError wraps a decomposition error, pointing to --from-cache when
+// the YAML that failed validation was cached.
+func decomposeError(err error, prdPath string) error {
+	var cached *decomposer.CachedError
+	if errors.As(err, &cached) {
+		return fmt.Errorf("decomposition failed: %w\nEdit the cached YAML if needed, then run 'ralph decompose --from-cache %s'", err, prdPath)
 	}
+	return fmt.Errorf("decomposition failed: %w", err)
+}
 
-	taskCount := countTasksInYAML(result.YAMLContent)
-
-	_, _ = fmt.Fprintf(output, "✓ Generated %d tasks: %s\n", taskCount, outputPath)
+// printDecomposeResult prints the session, model and cost of a decomposition.
+func printDecomposeResult(result *decomposer.DecomposeResult, output io.Writer) {
 	if result.SessionID != "" {
 		_, _ = fmt.Fprintf(output, "  Session: %s\n", result.SessionID)
 	}
@@ -185,24 +365,43 @@ func decomposePRD(ctx context.Context, prdPath, workDir string, cfg *config.Conf
 		_, _ = fmt.Fprintf(output, "  Cost: $%.4f\n", result.TotalCostUSD)
 	}
 	_, _ = fmt.Fprintln(output)
+}
 
-	return outputPath, nil
+func importTasks(yamlPath string, cfg *config.Config, output io.Writer) (*taskstore.ImportResult, error) {
+	return importTasksWith(taskstore.ImportFromFile, yamlPath, cfg, output)
+}
+
+// reimportTasks imports an edited task file; tasks already in the store keep
+// their status, and tasks in previous that the file no longer lists are
+// removed.
+func reimportTasks(yamlPath string, previous []string, cfg *config.Config, output io.Writer) (*taskstore.ImportResult, error) {
+	reimport := func(store taskstore.Store, path string) (*taskstore.ImportResult, error) {
+		return taskstore.ReimportFromFile(store, path, previous)
+	}
+	return importTasksWith(reimport, yamlPath, cfg, output)
 }
 
-func importTasks(yamlPath string, cfg *config.Config, output io.Writer) error {
+// importTasksWith imports the task file and validates the resulting task set.
+// The import result is returned even when validation fails, since the store
+// has already been updated.
+func importTasksWith(importFile func(taskstore.Store, string) (*taskstore.ImportResult, error), yamlPath string, cfg *config.Config, output io.Writer) (*taskstore.ImportResult, error) {
 	_, _ = fmt.Fprintf(output, "Importing tasks into store...\n")
 
-	store, err := taskstore.NewLocalStore(config.DefaultTasksPath)
+	store, err := taskstore.OpenStore(cfg.Tasks.Backend, config.DefaultTasksPath)
 	if err != nil {
-		return fmt.Errorf("import failed: %w", err)
+		return nil, fmt.Errorf("import failed: %w", err)
 	}
+	defer func() { _ = taskstore.CloseStore(store) }()
 
-	result, err := taskstore.ImportFromYAML(store, yamlPath)
+	result, err := importFile(store, yamlPath)
 	if err != nil {
-		return fmt.Errorf("import failed: %w", err)
+		return nil, fmt.Errorf("import failed: %w", err)
 	}
 
 	_, _ = fmt.Fprintf(output, "✓ Imported %d task(s)\n", result.Imported)
+	if len(result.Removed) > 0 {
+		_, _ = fmt.Fprintf(output, "✓ Removed %d task(s) no longer in the task file\n", len(result.Removed))
+	}
 
 	if len(result.Errors) > 0 {
 		_, _ = fmt.Fprintf(output, "\n%d error(s) occurred during import:\n", len(result.Errors))
@@ -213,28 +412,32 @@ func importTasks(yamlPath string, cfg *config.Config, output io.Writer) error {
 
 	allTasks, err := store.List()
 	if err != nil {
-		return fmt.Errorf("import failed: %w", err)
+		return result, fmt.Errorf("import failed: %w", err)
 	}
 
 	lintResult := taskstore.LintTaskSet(allTasks)
 	if !lintResult.Valid {
 		if err := lintResult.Error(); err != nil {
-			return fmt.Errorf("import failed: task validation failed:\n%w", err)
+			return result, fmt.Errorf("import failed: task validation failed:\n%w", err)
 		}
 	}
+	for _, w := range taskstore.DepthWarnings(allTasks, cfg.Decompose.MaxDepth) {
+		_, _ = fmt.Fprintf(output, "⚠ Task %q is %s\n", w.TaskID, w.Warning)
+	}
 
 	_, _ = fmt.Fprintln(output)
-	return nil
+	return result, nil
 }
 
 func initRalph(workDir string, cfg *config.Config, parentID string, output io.Writer) (string, error) {
 	_, _ = fmt.Fprintf(output, "Initializing ralph...\n")
 
 	tasksPath := filepath.Join(workDir, config.DefaultTasksPath)
-	store, err := taskstore.NewLocalStore(tasksPath)
+	store, err := taskstore.OpenStore(cfg.Tasks.Backend, tasksPath)
 	if err != nil {
 		return "", fmt.Errorf("init failed: %w", err)
 	}
+	defer func() { _ = taskstore.CloseStore(store) }()
 
 	var parentTaskID string
 	if parentID != "" {
@@ -0,0 +1,389 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/decomposer"
+	"github.com/yarlson/ralph/internal/provider"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// DecomposeOptions configures a standalone decomposition.
+type DecomposeOptions struct {
+	Provider string
+	Model    string
+	Parent   string
+
+	// FromCache resumes from the cached YAML of a decomposition that failed
+	// validation instead of decomposing the PRD again.
+	FromCache bool
+
+	// Epic re-decomposes only the subtree of this existing task (see
+	// RedecomposeEpic).
+	Epic string
+
+	// Merge adds the decomposition under the parent task instead of
+	// replacing the existing tasks (see MergeDecompose).
+	Merge bool
+}
+
+// ConfirmFunc decides whether to accept a new decomposition given how it
+// differs from the existing task tree.
+type ConfirmFunc func(diff *taskstore.TaskDiff) (bool, error)
+
+// Decompose runs the pipeline without starting the loop: decompose → import → init.
+func Decompose(ctx context.Context, prdPath, workDir string, cfg *config.Config, opts DecomposeOptions, stdout io.Writer) error {
+	lock, err := state.AcquireLock(workDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+
+	_, _ = fmt.Fprintf(stdout, "Analyzing PRD: %s\n", prdPath)
+
+	providerName, err := provider.Resolve(opts.Provider, cfg.Provider)
+	if err != nil {
+		return err
+	}
+
+	yamlPath, err := decomposePRD(ctx, prdPath, workDir, cfg, providerName, opts.Model, opts.FromCache, stdout)
+	if err != nil {
+		return err
+	}
+
+	if _, err := importTasks(yamlPath, cfg, stdout); err != nil {
+		return err
+	}
+
+	_, err = initRalph(workDir, cfg, opts.Parent, stdout)
+	return err
+}
+
+// Redecompose re-runs decomposition of a PRD and compares the result with the
+// existing task tree (the parent task and its descendants). The existing tree
+// is replaced only if confirm accepts the diff; otherwise nothing is written.
+func Redecompose(ctx context.Context, prdPath, workDir string, cfg *config.Config, opts DecomposeOptions, confirm ConfirmFunc, stdout io.Writer) error {
+	lock, err := state.AcquireLock(workDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+
+	providerName, err := provider.Resolve(opts.Provider, cfg.Provider)
+	if err != nil {
+		return err
+	}
+
+	store, err := taskstore.OpenStore(cfg.Tasks.Backend, filepath.Join(workDir, config.DefaultTasksPath))
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+
+	existing, err := existingTaskTree(workDir, store, opts.Parent)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return fmt.Errorf("no existing tasks to compare against; run 'ralph decompose %s' first", prdPath)
+	}
+
+	_, _ = fmt.Fprintf(stdout, "Re-analyzing PRD: %s\n", prdPath)
+
+	dec, err := newDecomposer(workDir, cfg, providerName)
+	if err != nil {
+		return err
+	}
+
+	req := decomposeRequest(prdPath, workDir, opts.Model, opts.FromCache, cfg.Decompose, stdout)
+	printDecomposeStart(req, providerName, stdout)
+
+	decomposeCtx, cancel := decomposeContext(ctx, req)
+	defer cancel()
+
+	tasks, result, err := dec.DecomposeToTasks(decomposeCtx, req)
+	if err != nil {
+		return decomposeError(err, prdPath)
+	}
+
+	_, _ = fmt.Fprintf(stdout, "✓ Generated %d tasks\n", len(tasks))
+	printDecomposeResult(result, stdout)
+
+	diff := taskstore.DiffTasks(existing, tasks)
+	if diff.Empty() {
+		_, _ = fmt.Fprintln(stdout, "No changes: the new decomposition matches the existing tasks.")
+		return nil
+	}
+
+	accepted, err := confirm(diff)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		_, _ = fmt.Fprintln(stdout, "Kept existing tasks.")
+		return nil
+	}
+
+	outputPath := filepath.Join(workDir, "tasks.yaml")
+	if err := os.WriteFile(outputPath, []byte(result.YAMLContent), 0644); err != nil {
+		return fmt.Errorf("failed to write tasks file: %w", err)
+	}
+
+	if err := taskstore.ReplaceTasks(store, existing, tasks); err != nil {
+		return fmt.Errorf("failed to replace tasks: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(stdout, "✓ Replaced tasks: %d added, %d removed, %d changed\n\n",
+		len(diff.Added), len(diff.Removed), len(diff.Changed))
+
+	if rootID := newRootID(tasks); rootID != "" {
+		if _, err := initRalph(workDir, cfg, rootID, stdout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RedecomposeEpic re-decomposes the subtree of the existing task opts.Epic
+// from a PRD excerpt. The generated tasks replace the epic's descendants only
+// if confirm accepts the diff; the epic itself and the rest of the tree are
+// left untouched. The merged task set is linted before anything is written.
+func RedecomposeEpic(ctx context.Context, prdPath, workDir string, cfg *config.Config, opts DecomposeOptions, confirm ConfirmFunc, stdout io.Writer) error {
+	lock, err := state.AcquireLock(workDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+
+	providerName, err := provider.Resolve(opts.Provider, cfg.Provider)
+	if err != nil {
+		return err
+	}
+
+	store, err := taskstore.OpenStore(cfg.Tasks.Backend, filepath.Join(workDir, config.DefaultTasksPath))
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+
+	all, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list tasks: %w", err)
+	}
+	if _, err := store.Get(opts.Epic); err != nil {
+		return fmt.Errorf("epic %q not found: %w", opts.Epic, err)
+	}
+	existing := taskstore.Descendants(all, opts.Epic)
+
+	_, _ = fmt.Fprintf(stdout, "Re-analyzing epic %s from: %s\n", opts.Epic, prdPath)
+
+	dec, err := newDecomposer(workDir, cfg, providerName)
+	if err != nil {
+		return err
+	}
+
+	req := decomposeRequest(prdPath, workDir, opts.Model, opts.FromCache, cfg.Decompose, stdout)
+	req.Epic = &decomposer.EpicScope{EpicID: opts.Epic, Tasks: all}
+	req.Sectioned = false
+	printDecomposeStart(req, providerName, stdout)
+
+	decomposeCtx, cancel := decomposeContext(ctx, req)
+	defer cancel()
+
+	tasks, result, err := dec.DecomposeToTasks(decomposeCtx, req)
+	if err != nil {
+		return decomposeError(err, prdPath)
+	}
+
+	_, _ = fmt.Fprintf(stdout, "✓ Generated %d tasks under %s\n", len(tasks), opts.Epic)
+	printDecomposeResult(result, stdout)
+
+	if _, err := taskstore.MergeSubtree(all, opts.Epic, tasks); err != nil {
+		return fmt.Errorf("merged task tree is invalid: %w", err)
+	}
+
+	diff := taskstore.DiffTasks(existing, tasks)
+	if diff.Empty() {
+		_, _ = fmt.Fprintln(stdout, "No changes: the new decomposition matches the existing subtree.")
+		return nil
+	}
+
+	accepted, err := confirm(diff)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		_, _ = fmt.Fprintln(stdout, "Kept existing tasks.")
+		return nil
+	}
+
+	if err := taskstore.ReplaceTasks(store, existing, tasks); err != nil {
+		return fmt.Errorf("failed to replace tasks: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(stdout, "✓ Replaced %s subtree: %d added, %d removed, %d changed\n",
+		opts.Epic, len(diff.Added), len(diff.Removed), len(diff.Changed))
+	return nil
+}
+
+// MergeDecompose decomposes a PRD into new tasks only, and adds them under
+// the parent task (opts.Parent, or the stored parent task). Existing tasks,
+// including their status, are left untouched and tasks.yaml is not written.
+// The generated tasks are validated against the merged task set, so ID
+// collisions and dependencies on unknown tasks go through the fix loop.
+func MergeDecompose(ctx context.Context, prdPath, workDir string, cfg *config.Config, opts DecomposeOptions, stdout io.Writer) error {
+	lock, err := state.AcquireLock(workDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+
+	providerName, err := provider.Resolve(opts.Provider, cfg.Provider)
+	if err != nil {
+		return err
+	}
+
+	store, err := taskstore.OpenStore(cfg.Tasks.Backend, filepath.Join(workDir, config.DefaultTasksPath))
+	if err != nil {
+		return fmt.Errorf("failed to open task store: %w", err)
+	}
+	defer func() { _ = taskstore.CloseStore(store) }()
+
+	parentID := opts.Parent
+	if parentID == "" {
+		if parentID, err = state.GetStoredParentTaskID(workDir); err != nil {
+			return err
+		}
+	}
+	if parentID == "" {
+		return fmt.Errorf("no parent task to merge into; pass --parent or run 'ralph decompose %s' first", prdPath)
+	}
+
+	all, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list tasks: %w", err)
+	}
+	if _, err := store.Get(parentID); err != nil {
+		return fmt.Errorf("parent task %q not found: %w", parentID, err)
+	}
+
+	_, _ = fmt.Fprintf(stdout, "Analyzing PRD for new tasks under %s: %s\n", parentID, prdPath)
+
+	dec, err := newDecomposer(workDir, cfg, providerName)
+	if err != nil {
+		return err
+	}
+
+	req := decomposeRequest(prdPath, workDir, opts.Model, opts.FromCache, cfg.Decompose, stdout)
+	req.Merge = &decomposer.MergeScope{ParentID: parentID, Tasks: all}
+	req.Sectioned = false
+	printDecomposeStart(req, providerName, stdout)
+
+	decomposeCtx, cancel := decomposeContext(ctx, req)
+	defer cancel()
+
+	tasks, result, err := dec.DecomposeToTasks(decomposeCtx, req)
+	if err != nil {
+		return decomposeError(err, prdPath)
+	}
+
+	_, _ = fmt.Fprintf(stdout, "✓ Generated %d new tasks under %s\n", len(tasks), parentID)
+	printDecomposeResult(result, stdout)
+
+	if len(tasks) == 0 {
+		_, _ = fmt.Fprintln(stdout, "No changes: the existing tasks already cover the PRD.")
+		return nil
+	}
+
+	if _, err := taskstore.AppendSubtree(all, parentID, tasks); err != nil {
+		return fmt.Errorf("merged task tree is invalid: %w", err)
+	}
+	if err := taskstore.ReplaceTasks(store, nil, tasks); err != nil {
+		return fmt.Errorf("failed to add tasks: %w", err)
+	}
+
+	for _, t := range tasks {
+		_, _ = fmt.Fprintf(stdout, "  + %s: %s\n", t.ID, t.Title)
+	}
+	_, _ = fmt.Fprintf(stdout, "✓ Added %d tasks to %s\n", len(tasks), parentID)
+	return nil
+}
+
+// EstimateDecomposition prints a rough token count and cost projection for
+// decomposing a PRD with opts.Model, without calling the agent.
+func EstimateDecomposition(prdPath, workDir string, cfg *config.Config, opts DecomposeOptions, stdout io.Writer) error {
+	providerName, err := provider.Resolve(opts.Provider, cfg.Provider)
+	if err != nil {
+		return err
+	}
+
+	req := decomposeRequest(prdPath, workDir, opts.Model, false, cfg.Decompose, stdout)
+	est, err := decomposer.EstimateDecomposition(req)
+	if err != nil {
+		return fmt.Errorf("estimate failed: %w", err)
+	}
+
+	model := opts.Model
+	if model == "" {
+		model = "default"
+	}
+
+	_, _ = fmt.Fprintf(stdout, "Estimate for %s (no tasks generated):\n", prdPath)
+	_, _ = fmt.Fprintf(stdout, "  System prompt: ~%d tokens\n", est.SystemPromptTokens)
+	_, _ = fmt.Fprintf(stdout, "  PRD:           ~%d tokens\n", est.PRDTokens)
+	_, _ = fmt.Fprintf(stdout, "  Input:         ~%d tokens\n", est.InputTokens)
+	_, _ = fmt.Fprintf(stdout, "  Output:        ~%d tokens (projected)\n", est.OutputTokens)
+	_, _ = fmt.Fprintf(stdout, "  Cost:          ~$%.4f (model: %s)\n", est.CostUSD, model)
+	if providerName != provider.Claude {
+		_, _ = fmt.Fprintf(stdout, "  Priced at Claude list prices; %s may differ.\n", providerLabel(providerName))
+	}
+	if req.Sectioned {
+		_, _ = fmt.Fprintln(stdout, "  Assumes one pass; decompose.sectioned sends a request per section.")
+	}
+	_, _ = fmt.Fprintln(stdout, "Validation retries and the agent's own overhead are not included.")
+	return nil
+}
+
+// existingTaskTree returns the parent task and its descendants. The parent is
+// parentID if given, otherwise the stored parent task. Without either, all
+// tasks in the store are returned.
+func existingTaskTree(workDir string, store taskstore.Store, parentID string) ([]*taskstore.Task, error) {
+	tasks, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	if parentID == "" {
+		parentID, err = state.GetStoredParentTaskID(workDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if parentID == "" {
+		return tasks, nil
+	}
+
+	for _, t := range tasks {
+		if t.ID == parentID {
+			return append([]*taskstore.Task{t}, taskstore.Descendants(tasks, parentID)...), nil
+		}
+	}
+	return nil, fmt.Errorf("parent task %q not found", parentID)
+}
+
+// newRootID returns the ID of the first task without a parent, or "" if none.
+func newRootID(tasks []*taskstore.Task) string {
+	for _, t := range tasks {
+		if t.ParentID == nil {
+			return t.ID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,208 @@
+package bootstrap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/runner"
+	"github.com/yarlson/ralph/internal/state"
+)
+
+// Watched files are checked every watchInterval. A change is acted on once
+// the files have stayed unchanged for watchDebounce, so a burst of saves
+// triggers a single re-run.
+const (
+	watchInterval = 500 * time.Millisecond
+	watchDebounce = time.Second
+)
+
+// watchSession re-imports and resumes the loop whenever the task file, or
+// the PRD it was generated from, changes.
+type watchSession struct {
+	prdPath      string // Empty when started from a task file
+	tasksPath    string
+	workDir      string
+	cfg          *config.Config
+	providerName string
+	parent       string // --parent; empty to use the first root task
+	parentTaskID string
+	listed       []string // Task IDs listed by the last import of the task file
+	runOpts      runner.Options
+	stdout       io.Writer
+	stderr       io.Writer
+}
+
+// watch blocks until ctx is cancelled or the process is interrupted. Errors
+// from an edit (an invalid task file, a failed run) are reported and the
+// session keeps watching for the next change.
+func (s *watchSession) watch(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	paths := []string{s.tasksPath}
+	if s.prdPath != "" {
+		paths = []string{s.prdPath, s.tasksPath}
+	}
+	watcher := newFileWatcher(watchInterval, watchDebounce, paths...)
+
+	for {
+		_, _ = fmt.Fprintf(s.stdout, "\n👀 Watching %s for changes (Ctrl+C to stop)\n", strings.Join(paths, ", "))
+
+		changed, err := watcher.wait(ctx)
+		if err != nil {
+			return nil
+		}
+		_, _ = fmt.Fprintf(s.stdout, "\n↻ Changed: %s\n", strings.Join(changed, ", "))
+
+		if !s.waitWhilePaused(ctx) {
+			return nil
+		}
+
+		err = s.refresh(ctx, changed)
+		// Decomposition rewrites the task file; that is not a new edit.
+		watcher.mark()
+		if err != nil {
+			_, _ = fmt.Fprintf(s.stderr, "✗ %v\n", err)
+			continue
+		}
+
+		if err := runner.Run(ctx, s.workDir, s.cfg, s.parentTaskID, s.runOpts, s.stdout, s.stderr); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			_, _ = fmt.Fprintf(s.stderr, "✗ Run failed: %v\n", err)
+		}
+	}
+}
+
+// refresh re-decomposes the PRD if it changed, then re-imports the task
+// file, keeping the progress of tasks that already exist and removing those
+// the file no longer lists. If the parent task was dropped from the file, a
+// new one is chosen as at startup.
+func (s *watchSession) refresh(ctx context.Context, changed []string) error {
+	if s.prdPath != "" && slices.Contains(changed, s.prdPath) {
+		if _, err := decomposePRD(ctx, s.prdPath, s.workDir, s.cfg, s.providerName, "", false, s.stdout); err != nil {
+			return err
+		}
+	}
+
+	result, err := reimportTasks(s.tasksPath, s.listed, s.cfg, s.stdout)
+	if result != nil {
+		s.listed = result.IDs
+	}
+	if err != nil {
+		return err
+	}
+	if slices.Contains(result.IDs, s.parentTaskID) {
+		return nil
+	}
+
+	parentTaskID, err := initRalph(s.workDir, s.cfg, s.parent, s.stdout)
+	if err != nil {
+		return err
+	}
+	s.parentTaskID = parentTaskID
+	return nil
+}
+
+// waitWhilePaused holds changes back while the loop is paused. It returns
+// false if ctx is cancelled first.
+func (s *watchSession) waitWhilePaused(ctx context.Context) bool {
+	announced := false
+	for {
+		paused, err := state.IsPaused(s.workDir)
+		if err != nil || !paused {
+			return true
+		}
+		if !announced {
+			_, _ = fmt.Fprintf(s.stdout, "⏸ Paused: changes will be picked up once %s is removed\n", state.PausedFilePath(s.workDir))
+			announced = true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(watchInterval):
+		}
+	}
+}
+
+// fileWatcher detects changes to files by polling their content.
+type fileWatcher struct {
+	paths    []string
+	interval time.Duration
+	debounce time.Duration
+	seen     map[string]string
+}
+
+func newFileWatcher(interval, debounce time.Duration, paths ...string) *fileWatcher {
+	w := &fileWatcher{paths: paths, interval: interval, debounce: debounce}
+	w.mark()
+	return w
+}
+
+// mark records the current content of the files as seen.
+func (w *fileWatcher) mark() {
+	w.seen = w.fingerprints()
+}
+
+// fingerprints hashes each file's content. A missing or unreadable file has
+// an empty fingerprint.
+func (w *fileWatcher) fingerprints() map[string]string {
+	prints := make(map[string]string, len(w.paths))
+	for _, path := range w.paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			prints[path] = ""
+			continue
+		}
+		sum := sha256.Sum256(data)
+		prints[path] = hex.EncodeToString(sum[:])
+	}
+	return prints
+}
+
+// wait blocks until a file differs from what was last seen and has stayed
+// the same for the debounce period, then returns the changed paths and
+// marks them seen. Edits that are reverted before settling are ignored.
+func (w *fileWatcher) wait(ctx context.Context) ([]string, error) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	last := w.seen
+	var settling time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		current := w.fingerprints()
+		switch {
+		case maps.Equal(current, w.seen):
+			last, settling = current, time.Time{}
+		case !maps.Equal(current, last) || settling.IsZero():
+			last, settling = current, time.Now()
+		case time.Since(settling) >= w.debounce:
+			var changed []string
+			for _, path := range w.paths {
+				if current[path] != w.seen[path] {
+					changed = append(changed, path)
+				}
+			}
+			w.seen = current
+			return changed, nil
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestFileWatcher(t *testing.T) {
+	const interval, debounce = 5 * time.Millisecond, 50 * time.Millisecond
+
+	t.Run("reports the changed file once edits settle", func(t *testing.T) {
+		dir := t.TempDir()
+		tasks := filepath.Join(dir, "tasks.yaml")
+		prd := filepath.Join(dir, "prd.md")
+		require.NoError(t, os.WriteFile(tasks, []byte("tasks: []\n"), 0644))
+		require.NoError(t, os.WriteFile(prd, []byte("# PRD\n"), 0644))
+		w := newFileWatcher(interval, debounce, prd, tasks)
+
+		go func() {
+			for i := range 5 {
+				_ = os.WriteFile(tasks, []byte(fmt.Sprintf("tasks: []\n# edit %d\n", i)), 0644)
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		start := time.Now()
+		changed, err := w.wait(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{tasks}, changed)
+		assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond+debounce, "waits for the burst of edits to settle")
+	})
+
+	t.Run("ignores an edit that is reverted", func(t *testing.T) {
+		tasks := filepath.Join(t.TempDir(), "tasks.yaml")
+		require.NoError(t, os.WriteFile(tasks, []byte("tasks: []\n"), 0644))
+		w := newFileWatcher(interval, 200*time.Millisecond, tasks)
+
+		go func() {
+			_ = os.WriteFile(tasks, []byte("tasks: [oops]\n"), 0644)
+			time.Sleep(20 * time.Millisecond)
+			_ = os.WriteFile(tasks, []byte("tasks: []\n"), 0644)
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		_, err := w.wait(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("mark accepts the current content", func(t *testing.T) {
+		tasks := filepath.Join(t.TempDir(), "tasks.yaml")
+		w := newFileWatcher(interval, debounce, tasks)
+
+		require.NoError(t, os.WriteFile(tasks, []byte("tasks: []\n"), 0644))
+		w.mark()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 4*debounce)
+		defer cancel()
+		_, err := w.wait(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestWatchSession_Refresh(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	cfg, err := config.LoadConfigFromPath(filepath.Join(dir, "ralph.yaml"))
+	require.NoError(t, err)
+
+	tasksPath := filepath.Join(dir, "tasks.yaml")
+	require.NoError(t, os.WriteFile(tasksPath, []byte(`tasks:
+  - id: old-root
+    title: Old root
+    description: Old root
+  - id: old-child
+    title: Old child
+    description: Old child
+    verify:
+      - [go, test, ./...]
+    parentId: old-root
+`), 0644))
+	imported, err := importTasks(tasksPath, cfg, io.Discard)
+	require.NoError(t, err)
+	parentTaskID, err := initRalph(dir, cfg, "", io.Discard)
+	require.NoError(t, err)
+	require.Equal(t, "old-root", parentTaskID)
+
+	session := &watchSession{
+		tasksPath:    tasksPath,
+		workDir:      dir,
+		cfg:          cfg,
+		parentTaskID: parentTaskID,
+		listed:       imported.IDs,
+		stdout:       io.Discard,
+		stderr:       io.Discard,
+	}
+
+	require.NoError(t, os.WriteFile(tasksPath, []byte(`tasks:
+  - id: new-root
+    title: New root
+    description: New root
+  - id: new-child
+    title: New child
+    description: New child
+    verify:
+      - [go, test, ./...]
+    parentId: new-root
+`), 0644))
+	require.NoError(t, session.refresh(context.Background(), []string{tasksPath}))
+
+	assert.Equal(t, "new-root", session.parentTaskID, "a parent dropped from the file is replaced")
+	assert.Equal(t, []string{"new-root", "new-child"}, session.listed)
+	stored, err := state.GetStoredParentTaskID(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "new-root", stored)
+
+	store, err := taskstore.OpenStore(cfg.Tasks.Backend, filepath.Join(dir, config.DefaultTasksPath))
+	require.NoError(t, err)
+	defer func() { _ = taskstore.CloseStore(store) }()
+	tasks, err := store.List()
+	require.NoError(t, err)
+	var ids []string
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	assert.ElementsMatch(t, []string{"new-root", "new-child"}, ids, "tasks dropped from the file are removed")
+}
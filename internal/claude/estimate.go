@@ -0,0 +1,41 @@
+package claude
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// charsPerToken is the rough number of characters per token for English
+// prose and code, used when no tokenizer is available.
+const charsPerToken = 4
+
+// EstimateTokens returns a rough token count for text, at about four
+// characters per token. It is meant for cost projections, not limits.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// Pricing is a model's price in USD per million tokens.
+type Pricing struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// Cost returns the price of inputTokens and outputTokens.
+func (p Pricing) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.InputPerMTok + float64(outputTokens)*p.OutputPerMTok) / 1_000_000
+}
+
+// PricingForModel returns the list price for a model by family: opus, haiku,
+// or sonnet, which is also assumed for an empty or unknown model.
+func PricingForModel(model string) Pricing {
+	model = strings.ToLower(model)
+	switch {
+	case strings.Contains(model, "opus"):
+		return Pricing{InputPerMTok: 15, OutputPerMTok: 75}
+	case strings.Contains(model, "haiku"):
+		return Pricing{InputPerMTok: 1, OutputPerMTok: 5}
+	default:
+		return Pricing{InputPerMTok: 3, OutputPerMTok: 15}
+	}
+}
@@ -0,0 +1,54 @@
+package claude
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected int
+	}{
+		{"empty", "", 0},
+		{"rounds up", "abcde", 2},
+		{"exact", strings.Repeat("a", 400), 100},
+		{"counts runes, not bytes", "ééééé", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EstimateTokens(tt.text))
+		})
+	}
+}
+
+func TestPricingForModel(t *testing.T) {
+	tests := []struct {
+		model    string
+		expected Pricing
+	}{
+		{"", Pricing{InputPerMTok: 3, OutputPerMTok: 15}},
+		{"sonnet", Pricing{InputPerMTok: 3, OutputPerMTok: 15}},
+		{"claude-opus-latest", Pricing{InputPerMTok: 15, OutputPerMTok: 75}},
+		{"Haiku", Pricing{InputPerMTok: 1, OutputPerMTok: 5}},
+		{"something-else", Pricing{InputPerMTok: 3, OutputPerMTok: 15}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PricingForModel(tt.model))
+		})
+	}
+}
+
+func TestPricing_Cost(t *testing.T) {
+	p := Pricing{InputPerMTok: 3, OutputPerMTok: 15}
+
+	assert.InDelta(t, 0.0, p.Cost(0, 0), 1e-9)
+	assert.InDelta(t, 0.003+0.015, p.Cost(1000, 1000), 1e-9)
+	assert.InDelta(t, 3.0, p.Cost(1_000_000, 0), 1e-9)
+}
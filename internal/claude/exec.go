@@ -5,13 +5,17 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/yarlson/ralph/internal/procenv"
+	"github.com/yarlson/ralph/internal/redact"
 	"github.com/yarlson/ralph/internal/stream"
 )
 
@@ -29,6 +33,10 @@ type SubprocessRunner struct {
 	streamOutput bool
 	// streamOpts configures the stream processor when streamOutput is enabled.
 	streamOpts stream.Options
+	// env holds passed-through environment variables set on every invocation.
+	env map[string]string
+	// redactor removes the passed-through values from the raw NDJSON log.
+	redactor *redact.Redactor
 }
 
 // NewSubprocessRunner creates a new SubprocessRunner with the given command and logs directory.
@@ -54,6 +62,15 @@ func (r *SubprocessRunner) WithStreamOutput(opts stream.Options) *SubprocessRunn
 	return r
 }
 
+// WithEnvPassthrough limits every invocation's environment to a base set of
+// variables (PATH, HOME and the like) plus env, and keeps env's values out of
+// the raw NDJSON log.
+func (r *SubprocessRunner) WithEnvPassthrough(env map[string]string) *SubprocessRunner {
+	r.env = env
+	r.redactor = redact.New(slices.Collect(maps.Values(env))...)
+	return r
+}
+
 // Run executes Claude Code with the given request and returns the response.
 // It streams stdout to both the parser and a log file simultaneously.
 // The context can be used for cancellation/timeout.
@@ -70,13 +87,12 @@ func (r *SubprocessRunner) Run(ctx context.Context, req ClaudeRequest) (*ClaudeR
 	}
 
 	// Set environment variables
-	if len(req.Env) > 0 {
-		// Start with current environment
-		cmd.Env = os.Environ()
-		// Add custom environment variables
-		for k, v := range req.Env {
-			cmd.Env = append(cmd.Env, k+"="+v)
-		}
+	switch {
+	case r.env != nil:
+		// Only the base environment and the passed-through variables
+		cmd.Env = procenv.Restricted(r.env, req.Env)
+	case len(req.Env) > 0:
+		cmd.Env = procenv.Inherited(req.Env)
 	}
 
 	// Create log file
@@ -88,6 +104,7 @@ func (r *SubprocessRunner) Run(ctx context.Context, req ClaudeRequest) (*ClaudeR
 		return nil, fmt.Errorf("failed to create log file %s: %w", logPath, err)
 	}
 	defer func() { _ = logFile.Close() }()
+	logWriter := r.redactor.Writer(logFile)
 
 	// Capture stdout for parsing and logging
 	stdoutPipe, err := cmd.StdoutPipe()
@@ -108,7 +125,7 @@ func (r *SubprocessRunner) Run(ctx context.Context, req ClaudeRequest) (*ClaudeR
 	var stdoutBuf bytes.Buffer
 
 	// Set up writers: always write to log file and parse buffer
-	writers := []io.Writer{logFile, &stdoutBuf}
+	writers := []io.Writer{logWriter, &stdoutBuf}
 
 	// If streaming is enabled, also process through the stream processor
 	var streamPipeWriter *io.PipeWriter
@@ -131,6 +148,7 @@ func (r *SubprocessRunner) Run(ctx context.Context, req ClaudeRequest) (*ClaudeR
 	// Tee stdout to all writers
 	multiWriter := io.MultiWriter(writers...)
 	_, copyErr := io.Copy(multiWriter, stdoutPipe)
+	_ = logWriter.Close()
 
 	// Close the stream pipe writer to signal EOF to processor
 	if streamPipeWriter != nil {
@@ -283,6 +283,33 @@ echo '{"type":"result","subtype":"success","result":"'"$TEST_VAR"'"}'
 	assert.Equal(t, "test_value_123", resp.FinalText)
 }
 
+func TestSubprocessRunner_EnvPassthrough(t *testing.T) {
+	logsDir := t.TempDir()
+
+	// The agent echoes the secret back, as it might when debugging a failure
+	mockScript := filepath.Join(t.TempDir(), "mock-secret.sh")
+	scriptContent := `#!/bin/bash
+echo '{"type":"system","subtype":"init","session_id":"secret-session"}'
+echo '{"type":"result","subtype":"success","result":"token is '"$SERVICE_TOKEN"''"$UNLISTED_SECRET"'"}'
+`
+	require.NoError(t, os.WriteFile(mockScript, []byte(scriptContent), 0755))
+	t.Setenv("UNLISTED_SECRET", " and more")
+
+	runner := NewSubprocessRunner(mockScript, logsDir).
+		WithEnvPassthrough(map[string]string{"SERVICE_TOKEN": "s3cr3t-value"})
+
+	resp, err := runner.Run(context.Background(), ClaudeRequest{Cwd: t.TempDir(), Prompt: "use the service"})
+	require.NoError(t, err)
+
+	// The agent sees the value but no unlisted variables, the raw log does not
+	assert.Equal(t, "token is s3cr3t-value", resp.FinalText)
+
+	logData, err := os.ReadFile(resp.RawEventsPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(logData), "s3cr3t-value")
+	assert.Contains(t, string(logData), "token is [REDACTED]")
+}
+
 // Helper function to find index of string in slice
 func indexOf(slice []string, item string) int {
 	for i, s := range slice {
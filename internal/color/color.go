@@ -0,0 +1,100 @@
+// Package color adds ANSI colors to terminal output.
+package color
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	green  = "\x1b[32m"
+	red    = "\x1b[31m"
+	yellow = "\x1b[33m"
+	reset  = "\x1b[0m"
+)
+
+// Palette colors text. The zero value leaves text unchanged.
+type Palette struct {
+	enabled bool
+}
+
+// New returns a Palette that colors text only when enabled is true.
+func New(enabled bool) Palette {
+	return Palette{enabled: enabled}
+}
+
+// Enabled reports whether output written to w should be colored. Color is
+// off when noColor is set (--no-color), when the NO_COLOR environment
+// variable is non-empty (https://no-color.org), or when w is not a terminal.
+func Enabled(w io.Writer, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Green colors s as a success.
+func (p Palette) Green(s string) string {
+	return p.wrap(green, s)
+}
+
+// Red colors s as a failure.
+func (p Palette) Red(s string) string {
+	return p.wrap(red, s)
+}
+
+// Yellow colors s as a warning.
+func (p Palette) Yellow(s string) string {
+	return p.wrap(yellow, s)
+}
+
+// Line colors a line of progress output by its leading symbol: ✓ green, ✗
+// and ⛔ red, ⚠ yellow. Other lines are returned unchanged. Leading
+// indentation and trailing newlines are kept outside the color codes.
+func (p Palette) Line(s string) string {
+	if !p.enabled {
+		return s
+	}
+	body := strings.TrimLeft(s, " ")
+	indent := s[:len(s)-len(body)]
+	text := strings.TrimRight(body, "\n")
+	newlines := body[len(text):]
+
+	switch {
+	case strings.HasPrefix(text, "✓"):
+		text = p.Green(text)
+	case strings.HasPrefix(text, "✗"), strings.HasPrefix(text, "⛔"):
+		text = p.Red(text)
+	case strings.HasPrefix(text, "⚠"):
+		text = p.Yellow(text)
+	default:
+		return s
+	}
+	return indent + text + newlines
+}
+
+// Outcome colors an iteration or run outcome: success and completed green;
+// failed and error red; anything else (blocked, budget exceeded, ...) yellow.
+func (p Palette) Outcome(outcome string) string {
+	switch outcome {
+	case "success", "completed":
+		return p.Green(outcome)
+	case "failed", "error":
+		return p.Red(outcome)
+	case "":
+		return outcome
+	default:
+		return p.Yellow(outcome)
+	}
+}
+
+func (p Palette) wrap(code, s string) string {
+	if !p.enabled || s == "" {
+		return s
+	}
+	return code + s + reset
+}
@@ -0,0 +1,55 @@
+package color
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPalette_Line(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "success", in: "✓ Completed in 3s\n\n", want: "\x1b[32m✓ Completed in 3s\x1b[0m\n\n"},
+		{name: "indented failure", in: "  ✗ Build failed: exit 1\n", want: "  \x1b[31m✗ Build failed: exit 1\x1b[0m\n"},
+		{name: "unrecoverable", in: "  ⛔ Unrecoverable failure\n", want: "  \x1b[31m⛔ Unrecoverable failure\x1b[0m\n"},
+		{name: "warning", in: "⚠ Task t selected 4 times\n", want: "\x1b[33m⚠ Task t selected 4 times\x1b[0m\n"},
+		{name: "other lines unchanged", in: "  ⏳ Invoking agent...\n", want: "  ⏳ Invoking agent...\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, New(true).Line(tt.in))
+			assert.Equal(t, tt.in, New(false).Line(tt.in))
+		})
+	}
+}
+
+func TestPalette_Outcome(t *testing.T) {
+	p := New(true)
+
+	assert.Equal(t, "\x1b[32msuccess\x1b[0m", p.Outcome("success"))
+	assert.Equal(t, "\x1b[31mfailed\x1b[0m", p.Outcome("failed"))
+	assert.Equal(t, "\x1b[33mbudget_exceeded\x1b[0m", p.Outcome("budget_exceeded"))
+	assert.Equal(t, "success", Palette{}.Outcome("success"))
+}
+
+func TestEnabled(t *testing.T) {
+	t.Run("not a terminal", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		assert.False(t, Enabled(&bytes.Buffer{}, false))
+	})
+
+	t.Run("no-color flag", func(t *testing.T) {
+		assert.False(t, Enabled(os.Stdout, true))
+	})
+
+	t.Run("NO_COLOR set", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		assert.False(t, Enabled(os.Stdout, false))
+	})
+}
@@ -2,22 +2,50 @@ package config
 
 import (
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
+// EnvPrefix is the prefix for environment variable overrides (e.g., RALPH_PROVIDER).
+const EnvPrefix = "RALPH"
+
 // Config holds all Ralph harness configuration
 type Config struct {
-	Provider string         `mapstructure:"provider"`
-	Claude   ClaudeConfig   `mapstructure:"claude"`
-	OpenCode OpenCodeConfig `mapstructure:"opencode"`
-	Safety   SafetyConfig   `mapstructure:"safety"`
+	Provider  string          `mapstructure:"provider"`
+	Tasks     TasksConfig     `mapstructure:"tasks"`
+	Claude    ClaudeConfig    `mapstructure:"claude"`
+	OpenCode  OpenCodeConfig  `mapstructure:"opencode"`
+	Safety    SafetyConfig    `mapstructure:"safety"`
+	Retry     RetryConfig     `mapstructure:"retry"`
+	Git       GitConfig       `mapstructure:"git"`
+	Run       RunConfig       `mapstructure:"run"`
+	Verify    VerifyConfig    `mapstructure:"verify"`
+	Selector  SelectorConfig  `mapstructure:"selector"`
+	Decompose DecomposeConfig `mapstructure:"decompose"`
+	Planning  PlanningConfig  `mapstructure:"planning"`
+	Logs      LogsConfig      `mapstructure:"logs"`
+	Budget    BudgetConfig    `mapstructure:"budget"`
+
+	Experimental ExperimentalConfig `mapstructure:"experimental"`
+}
+
+// TasksConfig holds task store settings
+type TasksConfig struct {
+	// Backend is "local" (one JSON file per task in .ralph/tasks) or
+	// "sqlite" (a single .ralph/tasks.db database)
+	Backend string `mapstructure:"backend"`
 }
 
 // ClaudeConfig holds Claude Code invocation settings
 type ClaudeConfig struct {
 	Command []string `mapstructure:"command"`
 	Args    []string `mapstructure:"args"`
+	// EnvPassthrough names environment variables forwarded to the agent and
+	// verify commands, which then inherit only a base environment besides
+	// them; their values are redacted from records and logs
+	EnvPassthrough []string `mapstructure:"env_passthrough"`
 }
 
 // OpenCodeConfig holds OpenCode invocation settings
@@ -30,21 +58,237 @@ type OpenCodeConfig struct {
 type SafetyConfig struct {
 	Sandbox         bool     `mapstructure:"sandbox"`
 	AllowedCommands []string `mapstructure:"allowed_commands"`
+	// OutOfScope is "fail" (fail the iteration) or "warn" (report and carry
+	// on) when a task changes files outside its allowedPaths
+	OutOfScope string `mapstructure:"out_of_scope"`
+}
+
+// GitConfig holds git safety settings
+type GitConfig struct {
+	// ProtectedBranches are branches the loop refuses to run on without --branch
+	ProtectedBranches []string `mapstructure:"protected_branches"`
+	// RequireClean refuses to start a run while the working tree has uncommitted changes
+	RequireClean bool `mapstructure:"require_clean"`
+	// CommitMode is "per_task" (commit each completed task) or "per_run"
+	// (stage completed tasks and commit once when the run completes)
+	CommitMode string `mapstructure:"commit_mode"`
+	// MergedStatus marks tasks completed when a branch named after them has
+	// been merged, reconciling ralph's status with the team's merge state
+	MergedStatus MergedStatusConfig `mapstructure:"merged_status"`
+	// FormatCommand is run after the agent's changes and before verification
+	// and commit, so formatting never fails a task (empty = disabled)
+	FormatCommand []string `mapstructure:"format_command"`
+	// AutoInit runs git init when the working directory is not a git
+	// repository; when false the run fails instead
+	AutoInit bool `mapstructure:"auto_init"`
+	// PushOnComplete pushes the feature branch to PushRemote when a run
+	// completes every task
+	PushOnComplete bool   `mapstructure:"push_on_complete"`
+	PushRemote     string `mapstructure:"push_remote"`
+	// PRCommand opens a pull request after the push, e.g. ["gh", "pr",
+	// "create", "--fill", "--head", "{branch}"]; {branch}, {remote} and {title}
+	// are replaced and the last line it prints is reported as the PR link
+	PRCommand []string `mapstructure:"pr_command"`
+	// CommitTemplate is the Go template for task commit messages, with
+	// .Type, .Title, .TaskID and .IterationID (empty = "type: title" with
+	// the iteration ID as a trailer)
+	CommitTemplate string `mapstructure:"commit_template"`
+	// SignCommits signs ralph's commits with SigningKey, or with git's
+	// user.signingkey when it is empty; a run fails rather than commit
+	// unsigned when no key is configured
+	SignCommits bool   `mapstructure:"sign_commits"`
+	SigningKey  string `mapstructure:"signing_key"`
+}
+
+// MergedStatusConfig holds settings for reading task status from merged branches
+type MergedStatusConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Target is the branch that merged work lands in
+	Target string `mapstructure:"target"`
+	// Branches are the branch names to check for each task; "{id}" is
+	// replaced by the task ID
+	Branches []string `mapstructure:"branches"`
+}
+
+// RunConfig holds settings for how a run decides its outcome
+type RunConfig struct {
+	// RequireAllCompleted reports a run as blocked unless every non-skipped leaf task
+	// under the parent is completed, so failed tasks cannot pass as success
+	RequireAllCompleted bool `mapstructure:"require_all_completed"`
+	// RetryBackoff is how long to wait before the second attempt at a failed
+	// task, e.g. "30s" (0 retries immediately)
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+	// RetryBackoffFactor multiplies the wait for each further attempt
+	RetryBackoffFactor float64 `mapstructure:"retry_backoff_factor"`
+}
+
+// VerifyConfig holds settings for task verification
+type VerifyConfig struct {
+	// BuildFirst is a build command run before each task's verify commands, so
+	// compile errors are reported as a build failure instead of a test failure
+	BuildFirst []string `mapstructure:"build_first"`
+	// FullEvery runs every verify command, ignoring verifyWhen globs, on every
+	// nth iteration that would otherwise skip some (0 disables the safety net)
+	FullEvery int `mapstructure:"full_every"`
+	// Sets are named lists of verify commands that tasks reference with
+	// verifySet; names are lowercased when loaded
+	Sets map[string][][]string `mapstructure:"sets"`
+	// ExitCodes says how the nonzero exit codes of specific verify commands
+	// are read; tasks can also set them per command with verifyExitCodes
+	ExitCodes []ExitCodeMapping `mapstructure:"exit_codes"`
+	// RequireCommands leaves a task that has no verify commands awaiting
+	// review instead of completed when the agent finishes it
+	RequireCommands bool `mapstructure:"require_commands"`
+	// Timeout kills a verify command that runs longer, e.g. "10m", and counts
+	// it as failed (0 leaves commands bounded only by the iteration timeout)
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Parallel is how many verify commands run at once (1 runs them in order);
+	// results are reported in command order either way
+	Parallel int `mapstructure:"parallel"`
+	// Env sets variables for verify commands as KEY=value entries, on top of
+	// the inherited environment and claude.env_passthrough
+	Env []string `mapstructure:"env"`
+	// Shell runs each verify command through a shell, e.g. ["bash", "-lc"]
+	Shell []string `mapstructure:"shell"`
+	// CacheInputs are files or directories, usually gitignored (e.g. .env),
+	// whose contents are part of the verify cache key
+	CacheInputs []string `mapstructure:"cache_inputs"`
+}
+
+// ExitCodeMapping maps the exit codes of one verify command, matched by its
+// full argv, to results: pass, warn, skip or fail. Unlisted codes fail
+type ExitCodeMapping struct {
+	Command   []string          `mapstructure:"command"`
+	ExitCodes map[string]string `mapstructure:"exit_codes"`
+}
+
+// SelectorConfig holds task selection settings
+type SelectorConfig struct {
+	// ExternalCommand chooses the next task: it reads the ready tasks as JSON on
+	// stdin and prints the chosen task ID. Empty uses the built-in selection.
+	ExternalCommand []string `mapstructure:"external_command"`
+}
+
+// DecomposeConfig holds PRD decomposition settings
+type DecomposeConfig struct {
+	// MaxDepth limits how many levels deep the generated task tree may nest,
+	// counting the root task as level 1 (0 = unlimited)
+	MaxDepth int `mapstructure:"max_depth"`
+	// Sectioned decomposes the PRD one "## " section at a time, checkpointing
+	// each completed section so a failed decomposition can resume
+	Sectioned bool `mapstructure:"sectioned"`
+	// SystemPromptFile is a text/template file, relative to the working
+	// directory, that replaces the built-in decomposer system prompt
+	// (empty = built-in prompt)
+	SystemPromptFile string `mapstructure:"system_prompt_file"`
+}
+
+// PlanningConfig holds settings for the planning step run before each task
+type PlanningConfig struct {
+	// Enabled asks the agent for a short plan before implementing every task;
+	// a task's "planning" label ("true" or "false") overrides it
+	Enabled bool `mapstructure:"enabled"`
+	// Model is the model for the planning call (empty = the provider's default)
+	Model string `mapstructure:"model"`
+}
+
+// LogsConfig holds settings for pruning iteration records at run start
+type LogsConfig struct {
+	// RetentionDays prunes records that ended more than this many days ago (0 = keep all)
+	RetentionDays int `mapstructure:"retention_days"`
+	// MaxRecords prunes the oldest records beyond this count (0 = no limit)
+	MaxRecords int `mapstructure:"max_records"`
+	// Archive moves pruned records to .ralph/archive/logs instead of deleting them
+	Archive bool `mapstructure:"archive"`
 }
 
-// LoadConfigWithFile loads configuration from a specific file if provided,
-// otherwise falls back to GlobalConfigPath.
+// BudgetConfig holds settings for sharing the run's cost limit between tasks
+type BudgetConfig struct {
+	// PerTaskAllocation is "none" (any task may spend the whole remaining
+	// budget) or "proportional" (each task gets a slice of --max-cost weighted
+	// by its effort label, and is failed once it has spent it)
+	PerTaskAllocation string `mapstructure:"per_task_allocation"`
+	// MaxInputTokens and MaxOutputTokens stop the run once it has used this
+	// many input or output tokens in total (0 = unlimited)
+	MaxInputTokens  int `mapstructure:"max_input_tokens"`
+	MaxOutputTokens int `mapstructure:"max_output_tokens"`
+	// Persist carries the iterations, cost and tokens spent on a parent task
+	// over to its next run (.ralph/state/budget.json), so the limits apply
+	// across runs until reset with `ralph budget --reset`
+	Persist bool `mapstructure:"persist"`
+}
+
+// RetryConfig holds settings for retrying failed tasks
+type RetryConfig struct {
+	// PreserveChanges starts a retried task from the previous attempt's uncommitted
+	// changes. When false, those changes are stashed and the retry starts clean.
+	PreserveChanges bool `mapstructure:"preserve_changes"`
+	// UnrecoverablePatterns are regexes matched against failure feedback; a match
+	// blocks the task immediately instead of spending retries on it
+	UnrecoverablePatterns []string `mapstructure:"unrecoverable_patterns"`
+	// AutoSplitOnFailure asks the agent to propose smaller sub-tasks when a task
+	// exhausts its retries; they are saved blocked under the failed task for review
+	AutoSplitOnFailure bool `mapstructure:"auto_split_on_failure"`
+}
+
+// ExperimentalConfig holds opt-in features that may change or be removed
+type ExperimentalConfig struct {
+	// Checkpoints commits intermediate progress when the agent reports a completed sub-goal
+	Checkpoints bool `mapstructure:"checkpoints"`
+}
+
+// LoadConfigWithFile loads configuration by merging layers in order of increasing precedence:
+//  1. Global config (GlobalConfigPath, e.g. ~/.config/ralph/config.yaml)
+//  2. Repo config (DefaultRepoConfigFile in the current working directory)
+//  3. The explicit config file, if provided
+//  4. RALPH_* environment variables (e.g., RALPH_PROVIDER, RALPH_CLAUDE_ARGS)
+//
+// Missing files are skipped. CLI flags are applied by callers on top of the result.
 func LoadConfigWithFile(configFile string) (*Config, error) {
+	globalPath, err := GlobalConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{globalPath, DefaultRepoConfigFile}
 	if configFile != "" {
-		return LoadConfigFromPath(configFile)
+		paths = append(paths, configFile)
 	}
 
-	globalPath, err := GlobalConfigPath()
-	if err != nil {
+	return LoadConfigLayers(paths...)
+}
+
+// LoadConfigLayers loads configuration by merging the given files in order,
+// with later files overriding earlier ones, then applying environment overrides.
+// Files that don't exist are skipped.
+func LoadConfigLayers(paths ...string) (*Config, error) {
+	v := viper.New()
+	setDefaults(v)
+
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		v.SetConfigFile(path)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	cfg := &Config{}
+	if err := v.Unmarshal(cfg); err != nil {
 		return nil, err
 	}
 
-	return LoadConfigFromPath(globalPath)
+	return cfg, nil
 }
 
 // LoadConfigFromPath loads configuration from a specific file path
@@ -89,6 +333,7 @@ func setDefaults(v *viper.Viper) {
 	// Claude defaults
 	v.SetDefault("claude.command", []string{"claude"})
 	v.SetDefault("claude.args", []string{})
+	v.SetDefault("claude.env_passthrough", []string{})
 
 	// OpenCode defaults
 	v.SetDefault("opencode.command", []string{"opencode", "run"})
@@ -97,7 +342,69 @@ func setDefaults(v *viper.Viper) {
 	// Provider defaults
 	v.SetDefault("provider", "claude")
 
+	// Tasks defaults
+	v.SetDefault("tasks.backend", DefaultTasksBackend)
+
 	// Safety defaults
 	v.SetDefault("safety.sandbox", false)
 	v.SetDefault("safety.allowed_commands", []string{"npm", "go", "git"})
+	v.SetDefault("safety.out_of_scope", "fail")
+
+	// Git defaults
+	v.SetDefault("git.protected_branches", []string{"main", "master"})
+	v.SetDefault("git.require_clean", false)
+	v.SetDefault("git.commit_mode", "per_task")
+	v.SetDefault("git.merged_status.enabled", false)
+	v.SetDefault("git.merged_status.target", "main")
+	v.SetDefault("git.merged_status.branches", []string{"{id}"})
+	v.SetDefault("git.format_command", []string{})
+	v.SetDefault("git.auto_init", true)
+	v.SetDefault("git.push_on_complete", false)
+	v.SetDefault("git.push_remote", "origin")
+	v.SetDefault("git.pr_command", []string{})
+	v.SetDefault("git.commit_template", "")
+	v.SetDefault("git.sign_commits", false)
+	v.SetDefault("git.signing_key", "")
+	v.SetDefault("run.require_all_completed", false)
+	v.SetDefault("run.retry_backoff", "0s")
+	v.SetDefault("run.retry_backoff_factor", 2.0)
+
+	// Verify defaults
+	v.SetDefault("verify.build_first", []string{})
+	v.SetDefault("verify.full_every", 10)
+	v.SetDefault("verify.require_commands", false)
+	v.SetDefault("verify.timeout", "0s")
+	v.SetDefault("verify.parallel", 1)
+	v.SetDefault("verify.env", []string{})
+	v.SetDefault("verify.shell", []string{})
+	v.SetDefault("verify.cache_inputs", []string{})
+
+	// Selector defaults
+	v.SetDefault("selector.external_command", []string{})
+
+	// Decompose defaults
+	v.SetDefault("decompose.max_depth", 0)
+	v.SetDefault("decompose.sectioned", false)
+	v.SetDefault("decompose.system_prompt_file", "")
+
+	// Planning defaults
+	v.SetDefault("planning.enabled", false)
+	v.SetDefault("planning.model", "")
+	v.SetDefault("logs.retention_days", 0)
+	v.SetDefault("logs.max_records", 0)
+	v.SetDefault("logs.archive", false)
+
+	// Budget defaults
+	v.SetDefault("budget.per_task_allocation", "none")
+	v.SetDefault("budget.max_input_tokens", 0)
+	v.SetDefault("budget.max_output_tokens", 0)
+	v.SetDefault("budget.persist", false)
+
+	// Retry defaults
+	v.SetDefault("retry.preserve_changes", true)
+	v.SetDefault("retry.unrecoverable_patterns", []string{})
+	v.SetDefault("retry.auto_split_on_failure", false)
+
+	// Experimental defaults
+	v.SetDefault("experimental.checkpoints", false)
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -145,3 +146,774 @@ safety:
 		assert.Empty(t, cfg.Safety.AllowedCommands)
 	})
 }
+
+func TestConfig_EnvPassthrough(t *testing.T) {
+	t.Run("nothing passed through by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Empty(t, cfg.Claude.EnvPassthrough)
+	})
+
+	t.Run("variables can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("claude:\n  env_passthrough: [\"GITHUB_TOKEN\", \"NPM_TOKEN\"]\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"GITHUB_TOKEN", "NPM_TOKEN"}, cfg.Claude.EnvPassthrough)
+	})
+}
+
+func TestConfig_Selector(t *testing.T) {
+	t.Run("built-in selection by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Empty(t, cfg.Selector.ExternalCommand)
+	})
+
+	t.Run("external command can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("selector:\n  external_command: [\"./pick-task\", \"--by-priority\"]\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"./pick-task", "--by-priority"}, cfg.Selector.ExternalCommand)
+	})
+}
+
+func TestConfig_GitProtectedBranches(t *testing.T) {
+	t.Run("protects main and master by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"main", "master"}, cfg.Git.ProtectedBranches)
+	})
+
+	t.Run("protected branches can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("git:\n  protected_branches: [\"trunk\"]\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"trunk"}, cfg.Git.ProtectedBranches)
+	})
+}
+
+func TestConfig_GitCommitMode(t *testing.T) {
+	t.Run("commits per task by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Equal(t, "per_task", cfg.Git.CommitMode)
+	})
+
+	t.Run("commit mode can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("git:\n  commit_mode: per_run\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, "per_run", cfg.Git.CommitMode)
+	})
+}
+
+func TestConfig_GitFormatCommand(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Empty(t, cfg.Git.FormatCommand)
+	})
+
+	t.Run("can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("git:\n  format_command: [\"gofmt\", \"-w\", \".\"]\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"gofmt", "-w", "."}, cfg.Git.FormatCommand)
+	})
+}
+
+func TestConfig_GitMergedStatus(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.False(t, cfg.Git.MergedStatus.Enabled)
+		assert.Equal(t, "main", cfg.Git.MergedStatus.Target)
+		assert.Equal(t, []string{"{id}"}, cfg.Git.MergedStatus.Branches)
+	})
+
+	t.Run("can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		content := "git:\n  merged_status:\n    enabled: true\n    target: develop\n    branches: [\"origin/feature/{id}\"]\n"
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Git.MergedStatus.Enabled)
+		assert.Equal(t, "develop", cfg.Git.MergedStatus.Target)
+		assert.Equal(t, []string{"origin/feature/{id}"}, cfg.Git.MergedStatus.Branches)
+	})
+}
+
+func TestConfig_GitRequireClean(t *testing.T) {
+	t.Run("does not require a clean tree by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.False(t, cfg.Git.RequireClean)
+	})
+
+	t.Run("clean tree check can be enabled", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("git:\n  require_clean: true\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Git.RequireClean)
+	})
+}
+
+func TestConfig_GitAutoInit(t *testing.T) {
+	t.Run("initializes a repository by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Git.AutoInit)
+	})
+
+	t.Run("auto-init can be disabled", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("git:\n  auto_init: false\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.False(t, cfg.Git.AutoInit)
+	})
+}
+
+func TestConfig_GitPush(t *testing.T) {
+	t.Run("does not push by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.False(t, cfg.Git.PushOnComplete)
+		assert.Equal(t, "origin", cfg.Git.PushRemote)
+		assert.Empty(t, cfg.Git.PRCommand)
+	})
+
+	t.Run("push and pull request can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("git:\n  push_on_complete: true\n  push_remote: upstream\n  pr_command: [gh, pr, create, --fill, --head, \"{branch}\"]\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Git.PushOnComplete)
+		assert.Equal(t, "upstream", cfg.Git.PushRemote)
+		assert.Equal(t, []string{"gh", "pr", "create", "--fill", "--head", "{branch}"}, cfg.Git.PRCommand)
+	})
+}
+
+func TestConfig_GitSigning(t *testing.T) {
+	t.Run("does not sign by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.False(t, cfg.Git.SignCommits)
+		assert.Empty(t, cfg.Git.SigningKey)
+	})
+
+	t.Run("signing can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("git:\n  sign_commits: true\n  signing_key: ABCD1234\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Git.SignCommits)
+		assert.Equal(t, "ABCD1234", cfg.Git.SigningKey)
+	})
+}
+
+func TestConfig_GitCommitTemplate(t *testing.T) {
+	t.Run("uses the built-in format by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Empty(t, cfg.Git.CommitTemplate)
+	})
+
+	t.Run("template can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("git:\n  commit_template: \"{{.Type}}({{.TaskID}}): {{.Title}}\"\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, "{{.Type}}({{.TaskID}}): {{.Title}}", cfg.Git.CommitTemplate)
+	})
+}
+
+func TestConfig_SafetyOutOfScope(t *testing.T) {
+	t.Run("fails out-of-scope changes by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Equal(t, "fail", cfg.Safety.OutOfScope)
+	})
+
+	t.Run("out-of-scope changes can warn", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("safety:\n  out_of_scope: warn\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, "warn", cfg.Safety.OutOfScope)
+	})
+}
+
+func TestConfig_Run(t *testing.T) {
+	t.Run("does not require all tasks completed by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.False(t, cfg.Run.RequireAllCompleted)
+	})
+
+	t.Run("require all completed can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("run:\n  require_all_completed: true\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Run.RequireAllCompleted)
+	})
+
+	t.Run("retries immediately by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Zero(t, cfg.Run.RetryBackoff)
+		assert.Equal(t, 2.0, cfg.Run.RetryBackoffFactor)
+	})
+
+	t.Run("retry backoff can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("run:\n  retry_backoff: 30s\n  retry_backoff_factor: 3\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, 30*time.Second, cfg.Run.RetryBackoff)
+		assert.Equal(t, 3.0, cfg.Run.RetryBackoffFactor)
+	})
+}
+
+func TestConfig_Verify(t *testing.T) {
+	t.Run("no build step by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Empty(t, cfg.Verify.BuildFirst)
+	})
+
+	t.Run("build step can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("verify:\n  build_first: [\"go\", \"build\", \"./...\"]\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"go", "build", "./..."}, cfg.Verify.BuildFirst)
+	})
+
+	t.Run("full verification every 10 iterations by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Equal(t, 10, cfg.Verify.FullEvery)
+	})
+
+	t.Run("full verification interval can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("verify:\n  full_every: 3\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, cfg.Verify.FullEvery)
+	})
+
+	t.Run("tasks without verify commands can complete by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.False(t, cfg.Verify.RequireCommands)
+	})
+
+	t.Run("verify commands can be required", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("verify:\n  require_commands: true\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Verify.RequireCommands)
+	})
+
+	t.Run("no command timeout by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Zero(t, cfg.Verify.Timeout)
+	})
+
+	t.Run("command timeout can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("verify:\n  timeout: 10m\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, 10*time.Minute, cfg.Verify.Timeout)
+	})
+
+	t.Run("runs commands one at a time by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, cfg.Verify.Parallel)
+	})
+
+	t.Run("parallel commands can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("verify:\n  parallel: 4\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, 4, cfg.Verify.Parallel)
+	})
+
+	t.Run("environment and shell can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("verify:\n  env: [\"DATABASE_URL=postgres://localhost/test\"]\n  shell: [bash, -lc]\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"DATABASE_URL=postgres://localhost/test"}, cfg.Verify.Env)
+		assert.Equal(t, []string{"bash", "-lc"}, cfg.Verify.Shell)
+	})
+
+	t.Run("verify cache inputs can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("verify:\n  cache_inputs: [.env, gen/]\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{".env", "gen/"}, cfg.Verify.CacheInputs)
+	})
+}
+
+func TestConfig_Retry(t *testing.T) {
+	t.Run("preserves changes by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Retry.PreserveChanges)
+	})
+
+	t.Run("clean retries can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("retry:\n  preserve_changes: false\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.False(t, cfg.Retry.PreserveChanges)
+	})
+
+	t.Run("no unrecoverable patterns by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Empty(t, cfg.Retry.UnrecoverablePatterns)
+	})
+
+	t.Run("unrecoverable patterns can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("retry:\n  unrecoverable_patterns: [\"no editor found\"]\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"no editor found"}, cfg.Retry.UnrecoverablePatterns)
+	})
+
+	t.Run("auto split disabled by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.False(t, cfg.Retry.AutoSplitOnFailure)
+	})
+
+	t.Run("auto split can be enabled", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("retry:\n  auto_split_on_failure: true\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Retry.AutoSplitOnFailure)
+	})
+}
+
+func TestConfig_Tasks(t *testing.T) {
+	t.Run("local backend by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Equal(t, "local", cfg.Tasks.Backend)
+	})
+
+	t.Run("backend can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("tasks:\n  backend: sqlite\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, "sqlite", cfg.Tasks.Backend)
+	})
+}
+
+func TestConfig_Decompose(t *testing.T) {
+	t.Run("max depth unlimited by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, cfg.Decompose.MaxDepth)
+	})
+
+	t.Run("max depth can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("decompose:\n  max_depth: 3\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, cfg.Decompose.MaxDepth)
+	})
+
+	t.Run("sectioned decomposition can be enabled", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("decompose:\n  sectioned: true\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Decompose.Sectioned)
+	})
+
+	t.Run("built-in system prompt by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Empty(t, cfg.Decompose.SystemPromptFile)
+	})
+
+	t.Run("system prompt file can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("decompose:\n  system_prompt_file: .ralph/prompts/decompose.md\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, ".ralph/prompts/decompose.md", cfg.Decompose.SystemPromptFile)
+	})
+}
+
+func TestConfig_Planning(t *testing.T) {
+	t.Run("planning disabled by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.False(t, cfg.Planning.Enabled)
+		assert.Empty(t, cfg.Planning.Model)
+	})
+
+	t.Run("planning can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("planning:\n  enabled: true\n  model: haiku\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Planning.Enabled)
+		assert.Equal(t, "haiku", cfg.Planning.Model)
+	})
+}
+
+func TestConfig_Experimental(t *testing.T) {
+	t.Run("checkpoints disabled by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.False(t, cfg.Experimental.Checkpoints)
+	})
+
+	t.Run("checkpoints can be enabled", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		err := os.WriteFile(configPath, []byte("experimental:\n  checkpoints: true\n"), 0644)
+		require.NoError(t, err)
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Experimental.Checkpoints)
+	})
+}
+
+func TestLoadConfigLayers_LaterFilesOverrideEarlier(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalPath := filepath.Join(tmpDir, "global.yaml")
+	repoPath := filepath.Join(tmpDir, "repo.yaml")
+
+	require.NoError(t, os.WriteFile(globalPath, []byte(`
+provider: "opencode"
+claude:
+  args: ["--model", "global-model"]
+safety:
+  allowed_commands: ["go"]
+`), 0644))
+	require.NoError(t, os.WriteFile(repoPath, []byte(`
+provider: "claude"
+safety:
+  sandbox: true
+`), 0644))
+
+	cfg, err := LoadConfigLayers(globalPath, repoPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "claude", cfg.Provider)
+	assert.Equal(t, []string{"--model", "global-model"}, cfg.Claude.Args)
+	assert.True(t, cfg.Safety.Sandbox)
+	assert.Equal(t, []string{"go"}, cfg.Safety.AllowedCommands)
+}
+
+func TestLoadConfigLayers_SkipsMissingFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoPath := filepath.Join(tmpDir, "repo.yaml")
+	require.NoError(t, os.WriteFile(repoPath, []byte("provider: \"opencode\"\n"), 0644))
+
+	cfg, err := LoadConfigLayers(filepath.Join(tmpDir, "missing.yaml"), repoPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "opencode", cfg.Provider)
+	assert.Equal(t, []string{"claude"}, cfg.Claude.Command)
+}
+
+func TestLoadConfigLayers_EnvOverridesFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("provider: \"claude\"\n"), 0644))
+
+	t.Setenv("RALPH_PROVIDER", "opencode")
+	t.Setenv("RALPH_SAFETY_SANDBOX", "true")
+
+	cfg, err := LoadConfigLayers(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "opencode", cfg.Provider)
+	assert.True(t, cfg.Safety.Sandbox)
+}
+
+func TestLoadConfigWithFile_MergesGlobalRepoAndExplicit(t *testing.T) {
+	globalDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", globalDir)
+	globalPath := filepath.Join(globalDir, "ralph", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(globalPath), 0755))
+	require.NoError(t, os.WriteFile(globalPath, []byte(`
+provider: "opencode"
+claude:
+  args: ["--model", "global-model"]
+`), 0644))
+
+	repoDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, DefaultRepoConfigFile), []byte(`
+safety:
+  sandbox: true
+`), 0644))
+
+	explicitPath := filepath.Join(t.TempDir(), "explicit.yaml")
+	require.NoError(t, os.WriteFile(explicitPath, []byte("provider: \"claude\"\n"), 0644))
+
+	oldWd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(repoDir))
+	defer func() { _ = os.Chdir(oldWd) }()
+
+	cfg, err := LoadConfigWithFile(explicitPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "claude", cfg.Provider)
+	assert.Equal(t, []string{"--model", "global-model"}, cfg.Claude.Args)
+	assert.True(t, cfg.Safety.Sandbox)
+}
+
+func TestConfig_Budget(t *testing.T) {
+	t.Run("does not allocate per task by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Equal(t, "none", cfg.Budget.PerTaskAllocation)
+		assert.Zero(t, cfg.Budget.MaxInputTokens)
+		assert.Zero(t, cfg.Budget.MaxOutputTokens)
+		assert.False(t, cfg.Budget.Persist)
+	})
+
+	t.Run("persistence can be enabled", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("budget:\n  persist: true\n"), 0644))
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.True(t, cfg.Budget.Persist)
+	})
+
+	t.Run("token limits can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("budget:\n  max_input_tokens: 5000000\n  max_output_tokens: 200000\n"), 0644))
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, 5000000, cfg.Budget.MaxInputTokens)
+		assert.Equal(t, 200000, cfg.Budget.MaxOutputTokens)
+	})
+
+	t.Run("proportional allocation can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte("budget:\n  per_task_allocation: proportional\n"), 0644))
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, "proportional", cfg.Budget.PerTaskAllocation)
+	})
+}
+
+func TestConfig_Logs(t *testing.T) {
+	t.Run("keeps all records by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, cfg.Logs.RetentionDays)
+		assert.Equal(t, 0, cfg.Logs.MaxRecords)
+		assert.False(t, cfg.Logs.Archive)
+	})
+
+	t.Run("can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		content := "logs:\n  retention_days: 30\n  max_records: 500\n  archive: true\n"
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, 30, cfg.Logs.RetentionDays)
+		assert.Equal(t, 500, cfg.Logs.MaxRecords)
+		assert.True(t, cfg.Logs.Archive)
+	})
+}
+
+func TestConfig_VerifySets(t *testing.T) {
+	t.Run("none by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Empty(t, cfg.Verify.Sets)
+	})
+
+	t.Run("can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		content := "verify:\n  sets:\n    Go:\n      - [\"go\", \"test\", \"./...\"]\n      - [\"go\", \"vet\", \"./...\"]\n"
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string][][]string{
+			"go": {{"go", "test", "./..."}, {"go", "vet", "./..."}},
+		}, cfg.Verify.Sets)
+	})
+}
+
+func TestConfig_VerifyExitCodes(t *testing.T) {
+	t.Run("none by default", func(t *testing.T) {
+		cfg, err := LoadConfigWithFile("")
+		require.NoError(t, err)
+
+		assert.Empty(t, cfg.Verify.ExitCodes)
+	})
+
+	t.Run("can be configured", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "ralph.yaml")
+		content := "verify:\n  exit_codes:\n    - command: [golangci-lint, run]\n      exit_codes: {2: warn, 3: skip}\n    - command: [go, vet, ./...]\n      exit_codes: {1: warn}\n"
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+		cfg, err := LoadConfigFromPath(configPath)
+		require.NoError(t, err)
+
+		assert.Equal(t, []ExitCodeMapping{
+			{Command: []string{"golangci-lint", "run"}, ExitCodes: map[string]string{"2": "warn", "3": "skip"}},
+			{Command: []string{"go", "vet", "./..."}, ExitCodes: map[string]string{"1": "warn"}},
+		}, cfg.Verify.ExitCodes)
+	})
+}
@@ -6,6 +6,12 @@ const (
 	DefaultBranchPrefix = "ralph/"
 )
 
+// Config file defaults
+const (
+	// DefaultRepoConfigFile is the repo-level config file, overlaid on the global config.
+	DefaultRepoConfigFile = "ralph.yaml"
+)
+
 // Tasks defaults
 const (
 	DefaultTasksBackend = "local"
@@ -0,0 +1,61 @@
+package decomposer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CachedError reports that validation failed and the last YAML that parsed
+// was saved to Path, so decomposition can resume from it (see
+// DecomposeRequest.FromCache).
+type CachedError struct {
+	Path string
+	Err  error
+}
+
+func (e *CachedError) Error() string {
+	return fmt.Sprintf("%v (last parsed YAML saved to %s)", e.Err, e.Path)
+}
+
+func (e *CachedError) Unwrap() error {
+	return e.Err
+}
+
+// readCache reads the cached YAML at path.
+func readCache(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("no cache path configured")
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("no cached decomposition at %s", path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read cached YAML: %w", err)
+	}
+	return string(content), nil
+}
+
+// writeCache saves YAML to path, creating its directory if needed.
+func writeCache(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to cache YAML: %w", err)
+	}
+	return nil
+}
+
+// removeCache removes the cached YAML at path, if any.
+func removeCache(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove cached YAML: %w", err)
+	}
+	return nil
+}
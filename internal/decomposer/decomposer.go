@@ -4,6 +4,7 @@ package decomposer
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -53,7 +54,9 @@ tasks:
     status: string (optional; omit unless PRD explicitly provides status; default is "open")
     acceptance: [string] (optional but strongly preferred for leaf tasks; testable statements)
     verify: [[string]] (optional; each inner list is argv tokens for a command)
+    verifyWhen: [string] (optional; a file glob per verify command, by index, e.g. "*.go"; the command only runs when a changed file matches)
     labels: {string: string} (optional; lightweight metadata)
+    contextFiles: [string] (optional; repository paths whose contents the agent is shown for context)
 
 TASK MODEL
 - Provide exactly ONE root task representing the entire PRD delivery scope.
@@ -75,7 +78,7 @@ FILE EXPLICITNESS (CRITICAL)
 
 REQUIRED STEPS FROM PRD (CRITICAL)
 - If the PRD includes required steps or procedures, carry them into the leaf task descriptions.
-- Preserve explicit steps like reading particular files (e.g., "Read internal/selector/graph.go") and include them verbatim in the task description.
+- Preserve explicit steps like reading particular files (e.g., "Read internal/selector/graph.go") and include them verbatim in the task description. Also list those files in contextFiles.
 - Treat required steps as mandatory context, not optional guidance.
 
 ID RULES
@@ -174,6 +177,57 @@ type DecomposeRequest struct {
 
 	// WorkDir is the working directory for the operation (typically repo root).
 	WorkDir string
+
+	// Model overrides the provider's default model (passed via --model).
+	Model string
+
+	// CachePath is where the last YAML that parsed but failed validation is
+	// saved when retries run out, and is removed after a successful
+	// decomposition. Empty disables caching.
+	CachePath string
+
+	// FromCache resumes from the YAML at CachePath instead of asking the
+	// agent to decompose the PRD. The PRD is still used for fix prompts.
+	FromCache bool
+
+	// MaxDepth limits how many levels deep the task tree may nest, where the
+	// root task is level 1. Deeper trees go through the fix loop. 0 means
+	// unlimited.
+	MaxDepth int
+
+	// Epic, if set, re-decomposes only the subtree of an existing epic from
+	// the PRD (typically an excerpt of it).
+	Epic *EpicScope
+
+	// Merge, if set, generates only new tasks to add to an existing tree
+	// instead of a whole new one.
+	Merge *MergeScope
+
+	// Sectioned decomposes the PRD one level-2 ("## ") section at a time
+	// under a root task built from its title. PRDs with fewer than two
+	// sections, epic and merge scopes and FromCache decompose in one pass.
+	Sectioned bool
+
+	// CheckpointPath is where a sectioned decomposition saves the tasks of
+	// each completed section. A later decomposition of the same PRD resumes
+	// after them; the file is removed once decomposition succeeds. Empty
+	// disables checkpoints.
+	CheckpointPath string
+
+	// SectionTimeout bounds each section of a sectioned decomposition
+	// (0 = no limit beyond ctx).
+	SectionTimeout time.Duration
+
+	// Progress receives progress lines (sections, tasks generated so far,
+	// validation attempts). Nil disables progress output.
+	Progress io.Writer
+
+	// SystemPromptFile replaces the built-in system prompt with this
+	// text/template file (see SystemPromptData). Empty uses the built-in one.
+	SystemPromptFile string
+
+	// systemPrompt is the resolved system prompt, set once per decomposition.
+	systemPrompt string
 }
 
 // DecomposeResult contains the results of PRD decomposition.
@@ -211,73 +265,193 @@ func NewDecomposer(runner claude.Runner) *Decomposer {
 
 // Decompose converts a PRD file into a task.yaml using Claude Code.
 func (d *Decomposer) Decompose(ctx context.Context, req DecomposeRequest) (*DecomposeResult, error) {
+	// Determine output path - use WorkDir as base if provided
+	outputPath := config.DefaultTasksFile
+	if req.WorkDir != "" {
+		outputPath = filepath.Join(req.WorkDir, config.DefaultTasksFile)
+	}
+
+	_, result, err := d.decompose(ctx, req, outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the tasks directory if it doesn't exist
+	tasksDir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create tasks directory: %w", err)
+	}
+
+	// Write the validated YAML to the output file (overwrites if Claude created it)
+	if err := os.WriteFile(outputPath, []byte(result.YAMLContent), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write tasks file: %w", err)
+	}
+
+	result.OutputPath = outputPath
+	return result, nil
+}
+
+// DecomposeToTasks converts a PRD file into validated tasks without writing any files.
+// Claude is not given the Write tool, so the YAML is taken from its response.
+// The returned result has an empty OutputPath.
+func (d *Decomposer) DecomposeToTasks(ctx context.Context, req DecomposeRequest) ([]*taskstore.Task, *DecomposeResult, error) {
+	return d.decompose(ctx, req, "")
+}
+
+// decompose runs Claude on the PRD and validates the generated YAML.
+// If outputPath is non-empty, Claude may write the tasks file there and it is read back;
+// otherwise YAML is only extracted from the response.
+func (d *Decomposer) decompose(ctx context.Context, req DecomposeRequest, outputPath string) ([]*taskstore.Task, *DecomposeResult, error) {
 	// Read PRD file
 	prdContent, err := os.ReadFile(req.PRDPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read PRD file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read PRD file: %w", err)
 	}
 
-	// Determine output path - use WorkDir as base if provided
-	outputPath := config.DefaultTasksFile
-	if req.WorkDir != "" {
-		outputPath = filepath.Join(req.WorkDir, config.DefaultTasksFile)
+	if req.systemPrompt, err = loadSystemPrompt(req, string(prdContent)); err != nil {
+		return nil, nil, err
+	}
+
+	var yamlContent string
+	resp := &claude.ClaudeResponse{}
+	sectioned := false
+	if req.FromCache {
+		yamlContent, err = readCache(req.CachePath)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if req.Sectioned && req.Epic == nil && req.Merge == nil && isPlainText(req.PRDPath) {
+		d.progress(req, "Plain-text spec has no sections; decomposing it whole\n")
+	} else if req.Sectioned && req.Epic == nil && req.Merge == nil {
+		yamlContent, resp, sectioned, err = d.generateSectioned(ctx, req, string(prdContent))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if !req.FromCache && !sectioned {
+		d.progress(req, "Generating tasks from the whole PRD...\n")
+		start := time.Now()
+		yamlContent, resp, err = d.generate(ctx, req, string(prdContent), outputPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		d.progress(req, "  ✓ Response received in %s\n", time.Since(start).Round(time.Second))
+	}
+
+	// Validate YAML and retry if needed
+	validatedYAML, err := d.validateAndRetry(ctx, req, string(prdContent), yamlContent)
+	if err != nil {
+		return nil, nil, fmt.Errorf("YAML validation failed: %w", err)
+	}
+
+	yamlFile, err := taskstore.ParseYAML([]byte(validatedYAML))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse validated YAML: %w", err)
+	}
+
+	if err := removeCache(req.CachePath); err != nil {
+		return nil, nil, err
+	}
+	if sectioned {
+		if err := removeCheckpoint(req.CheckpointPath); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	tasks := make([]*taskstore.Task, 0, len(yamlFile.Tasks))
+	for _, yt := range yamlFile.Tasks {
+		tasks = append(tasks, convertYAMLTaskToTask(yt))
 	}
 
-	// Construct user prompt with PRD content
-	userPrompt := fmt.Sprintf("Convert the following PRD into %s:\n\n%s", config.DefaultTasksFile, string(prdContent))
+	return tasks, &DecomposeResult{
+		YAMLContent:   validatedYAML,
+		SessionID:     resp.SessionID,
+		Model:         resp.Model,
+		TotalCostUSD:  resp.TotalCostUSD,
+		RawEventsPath: resp.RawEventsPath,
+	}, nil
+}
+
+// generate asks the agent to decompose the PRD and returns the YAML it produced.
+func (d *Decomposer) generate(ctx context.Context, req DecomposeRequest, prdContent, outputPath string) (string, *claude.ClaudeResponse, error) {
+	userPrompt, err := generatePrompt(req, prdContent)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Only allow Write tool to create tasks file when a file is wanted
+	allowedTools := []string{}
+	if outputPath != "" {
+		allowedTools = []string{"Write"}
+	}
 
 	// Call Claude Code
 	claudeReq := claude.ClaudeRequest{
 		Cwd:          req.WorkDir,
-		SystemPrompt: getSystemPrompt(),
+		SystemPrompt: req.systemPrompt,
 		Prompt:       userPrompt,
-		AllowedTools: []string{"Write"}, // Only allow Write tool to create tasks file
+		AllowedTools: allowedTools,
+		ExtraArgs:    modelArgs(req.Model),
 	}
 
 	resp, err := d.runner.Run(ctx, claudeReq)
 	if err != nil {
-		return nil, fmt.Errorf("claude execution failed: %w", err)
+		return "", nil, fmt.Errorf("claude execution failed: %w", err)
 	}
 
 	// Try to get YAML content - first check if Claude wrote the file directly
 	var yamlContent string
-	if fileContent, err := os.ReadFile(outputPath); err == nil {
-		// File was created by Claude using Write tool
-		yamlContent = string(fileContent)
-	} else {
+	if outputPath != "" {
+		if fileContent, err := os.ReadFile(outputPath); err == nil {
+			// File was created by Claude using Write tool
+			yamlContent = string(fileContent)
+		}
+	}
+	if yamlContent == "" {
 		// File wasn't created, try to extract YAML from response text
 		yamlContent = extractYAMLContent(resp)
 	}
 
 	if yamlContent == "" {
-		return nil, fmt.Errorf("no YAML content found: file not created and no YAML in response")
+		return "", nil, fmt.Errorf("no YAML content found: file not created and no YAML in response")
 	}
 
-	// Validate YAML and retry if needed
-	validatedYAML, err := d.validateAndRetry(ctx, string(prdContent), yamlContent)
-	if err != nil {
-		return nil, fmt.Errorf("YAML validation failed: %w", err)
-	}
+	return yamlContent, resp, nil
+}
 
-	// Create the tasks directory if it doesn't exist
-	tasksDir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(tasksDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create tasks directory: %w", err)
+// generatePrompt builds the user prompt that asks for the whole
+// decomposition of prdContent in one pass.
+func generatePrompt(req DecomposeRequest, prdContent string) (string, error) {
+	userPrompt := fmt.Sprintf("Convert the following PRD into %s:\n\n%s", config.DefaultTasksFile, prdContent)
+	if isPlainText(req.PRDPath) {
+		userPrompt = fmt.Sprintf(plainTextPromptTemplate, config.DefaultTasksFile, prdContent)
 	}
-
-	// Write the validated YAML to the output file (overwrites if Claude created it)
-	if err := os.WriteFile(outputPath, []byte(validatedYAML), 0644); err != nil {
-		return nil, fmt.Errorf("failed to write tasks file: %w", err)
+	if req.Epic != nil {
+		var err error
+		if userPrompt, err = epicPrompt(req.Epic, prdContent); err != nil {
+			return "", err
+		}
+	} else if req.Merge != nil {
+		var err error
+		if userPrompt, err = mergePrompt(req.Merge, prdContent); err != nil {
+			return "", err
+		}
+	}
+	if req.MaxDepth > 0 {
+		userPrompt += fmt.Sprintf(depthPromptTemplate, req.MaxDepth)
 	}
+	return userPrompt, nil
+}
 
-	return &DecomposeResult{
-		YAMLContent:   validatedYAML,
-		SessionID:     resp.SessionID,
-		Model:         resp.Model,
-		TotalCostUSD:  resp.TotalCostUSD,
-		RawEventsPath: resp.RawEventsPath,
-		OutputPath:    outputPath,
-	}, nil
+// plainTextPromptTemplate is the user prompt for .txt specs, which carry no
+// Markdown structure to lean on.
+const plainTextPromptTemplate = `Convert the following plain text spec into %s. It is plain text, not Markdown: infer its structure (goals, requirements, acceptance criteria) from the content rather than from headings.
+
+%s`
+
+// isPlainText reports whether path is a plain-text (.txt) spec.
+func isPlainText(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".txt")
 }
 
 // extractYAMLContent extracts YAML content from Claude response.
@@ -322,11 +496,35 @@ Please fix the YAML and output ONLY the corrected YAML (no explanations).
 
 Output the corrected YAML only:`
 
+// depthPromptTemplate is appended to the decomposition prompt when
+// DecomposeRequest.MaxDepth is set.
+const depthPromptTemplate = `
+
+Limit the task tree to at most %d levels: the root task is level 1, and no task may be nested deeper.`
+
 // validateAndRetry validates YAML content and retries with Claude if there are errors.
 // It parses the YAML, converts to tasks, and runs the linter.
 // If validation fails, it asks Claude to fix the YAML and retries up to maxValidationRetries times.
-func (d *Decomposer) validateAndRetry(ctx context.Context, prdContent, yamlContent string) (string, error) {
+// If it still fails, the last YAML that parsed is saved to req.CachePath.
+func (d *Decomposer) validateAndRetry(ctx context.Context, req DecomposeRequest, prdContent, yamlContent string) (string, error) {
+	validated, lastParsed, err := d.validateWithFixes(ctx, req, prdContent, yamlContent, func(tasks []*taskstore.Task) error {
+		return lintTasks(tasks, req)
+	})
+	if err != nil && lastParsed != "" && req.CachePath != "" {
+		if cacheErr := writeCache(req.CachePath, lastParsed); cacheErr != nil {
+			return "", fmt.Errorf("%w (%v)", err, cacheErr)
+		}
+		return "", &CachedError{Path: req.CachePath, Err: err}
+	}
+	return validated, err
+}
+
+// validateWithFixes runs the validate-and-fix loop, checking the parsed tasks
+// with lint. On failure it also returns the last YAML that parsed but failed
+// linting, if any.
+func (d *Decomposer) validateWithFixes(ctx context.Context, req DecomposeRequest, prdContent, yamlContent string, lint func([]*taskstore.Task) error) (string, string, error) {
 	currentYAML := yamlContent
+	var lastParsed string
 
 	for attempt := 0; attempt <= maxValidationRetries; attempt++ {
 		// Parse YAML
@@ -334,11 +532,12 @@ func (d *Decomposer) validateAndRetry(ctx context.Context, prdContent, yamlConte
 		if err != nil {
 			// YAML syntax error - ask Claude to fix
 			if attempt >= maxValidationRetries {
-				return "", fmt.Errorf("validation failed after %d retries: YAML parse error: %w", maxValidationRetries, err)
+				return "", lastParsed, fmt.Errorf("validation failed after %d retries: YAML parse error: %w", maxValidationRetries, err)
 			}
-			fixedYAML, fixErr := d.askClaudeToFix(ctx, prdContent, currentYAML, err.Error())
+			d.progress(req, "  YAML does not parse; asking for a fix (%d/%d)\n", attempt+1, maxValidationRetries)
+			fixedYAML, fixErr := d.askClaudeToFix(ctx, req, prdContent, currentYAML, err.Error())
 			if fixErr != nil {
-				return "", fixErr
+				return "", lastParsed, fixErr
 			}
 			currentYAML = fixedYAML
 			continue
@@ -352,39 +551,58 @@ func (d *Decomposer) validateAndRetry(ctx context.Context, prdContent, yamlConte
 		}
 
 		// Run linter
-		lintResult := taskstore.LintTaskSet(tasks)
-		if lintResult.Valid {
-			return currentYAML, nil
+		lintErr := lint(tasks)
+		if lintErr == nil {
+			return currentYAML, "", nil
 		}
 
 		// Validation failed - collect errors
+		lastParsed = currentYAML
 		if attempt >= maxValidationRetries {
-			return "", fmt.Errorf("validation failed after %d retries: %v", maxValidationRetries, lintResult.Error())
+			return "", lastParsed, fmt.Errorf("validation failed after %d retries: %v", maxValidationRetries, lintErr)
 		}
 
 		// Ask Claude to fix
-		errMsg := lintResult.Error().Error()
-		fixedYAML, fixErr := d.askClaudeToFix(ctx, prdContent, currentYAML, errMsg)
+		d.progress(req, "  %d tasks failed validation; asking for a fix (%d/%d)\n", len(tasks), attempt+1, maxValidationRetries)
+		errMsg := lintErr.Error()
+		fixedYAML, fixErr := d.askClaudeToFix(ctx, req, prdContent, currentYAML, errMsg)
 		if fixErr != nil {
-			return "", fixErr
+			return "", lastParsed, fixErr
 		}
 		currentYAML = fixedYAML
 	}
 
-	return "", fmt.Errorf("validation failed after %d retries", maxValidationRetries)
+	return "", lastParsed, fmt.Errorf("validation failed after %d retries", maxValidationRetries)
+}
+
+// depthError reports the tasks nested deeper than maxDepth, or nil if there
+// are none.
+func depthError(tasks []*taskstore.Task, maxDepth int) error {
+	warnings := taskstore.DepthWarnings(tasks, maxDepth)
+	if len(warnings) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		msgs = append(msgs, w.String())
+	}
+	return fmt.Errorf("%d tasks exceed the maximum depth of %d (flatten the tree by attaching them to a shallower parent):\n%s",
+		len(warnings), maxDepth, strings.Join(msgs, "\n"))
 }
 
 // askClaudeToFix asks Claude to fix YAML validation errors.
-func (d *Decomposer) askClaudeToFix(ctx context.Context, prdContent, yamlContent, errorMsg string) (string, error) {
+func (d *Decomposer) askClaudeToFix(ctx context.Context, req DecomposeRequest, prdContent, yamlContent, errorMsg string) (string, error) {
 	fixPrompt := fmt.Sprintf(fixPromptTemplate, prdContent, yamlContent, errorMsg)
 
-	req := claude.ClaudeRequest{
-		SystemPrompt: getSystemPrompt(),
+	fixReq := claude.ClaudeRequest{
+		SystemPrompt: req.systemPrompt,
 		Prompt:       fixPrompt,
 		AllowedTools: []string{}, // No tools needed for text-only response
+		ExtraArgs:    modelArgs(req.Model),
 	}
 
-	resp, err := d.runner.Run(ctx, req)
+	resp, err := d.runner.Run(ctx, fixReq)
 	if err != nil {
 		return "", fmt.Errorf("failed to get fixed YAML from Claude: %w", err)
 	}
@@ -401,7 +619,15 @@ func (d *Decomposer) askClaudeToFix(ctx context.Context, prdContent, yamlContent
 	return fixedYAML, nil
 }
 
-// convertYAMLTaskToTask converts a YAMLTask to a Task for linting purposes.
+// modelArgs returns the CLI arguments selecting model, or nil for the default model.
+func modelArgs(model string) []string {
+	if model == "" {
+		return nil
+	}
+	return []string{"--model", model}
+}
+
+// convertYAMLTaskToTask converts a YAMLTask to a Task for linting and DecomposeToTasks.
 func convertYAMLTaskToTask(yt taskstore.YAMLTask) *taskstore.Task {
 	now := time.Now()
 	task := &taskstore.Task{
@@ -411,10 +637,17 @@ func convertYAMLTaskToTask(yt taskstore.YAMLTask) *taskstore.Task {
 		DependsOn:   yt.DependsOn,
 		Acceptance:  yt.Acceptance,
 		Verify:      yt.Verify,
+		VerifySet:   yt.VerifySet,
+		VerifyWhen:  yt.VerifyWhen,
 		Labels:      yt.Labels,
+		Priority:    taskstore.PriorityFromLabel(yt.Labels["priority"]),
 		Status:      taskstore.StatusOpen,
 		CreatedAt:   now,
 		UpdatedAt:   now,
+
+		VerifyExitCodes: yt.VerifyExitCodes,
+		ContextFiles:    yt.ContextFiles,
+		AllowedPaths:    yt.AllowedPaths,
 	}
 
 	if yt.ParentID != "" {
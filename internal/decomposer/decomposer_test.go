@@ -1,6 +1,7 @@
 package decomposer
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/yarlson/ralph/internal/claude"
 	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/taskstore"
 )
 
 // mockRunner is a mock implementation of claude.Runner for testing
@@ -296,7 +298,7 @@ func TestValidateAndRetry_Success(t *testing.T) {
 	ctx := context.Background()
 	prdContent := "# Test PRD\nThis is a test PRD."
 
-	result, err := dec.validateAndRetry(ctx, prdContent, validTaskYAML)
+	result, err := dec.validateAndRetry(ctx, DecomposeRequest{}, prdContent, validTaskYAML)
 
 	require.NoError(t, err)
 	assert.Equal(t, validTaskYAML, result)
@@ -315,7 +317,7 @@ func TestValidateAndRetry_RetryOnError(t *testing.T) {
 	ctx := context.Background()
 	prdContent := "# Test PRD\nThis is a test PRD."
 
-	result, err := dec.validateAndRetry(ctx, prdContent, invalidTaskYAML)
+	result, err := dec.validateAndRetry(ctx, DecomposeRequest{}, prdContent, invalidTaskYAML)
 
 	require.NoError(t, err)
 	assert.Contains(t, result, "test-root")
@@ -336,7 +338,7 @@ func TestValidateAndRetry_MaxRetriesExceeded(t *testing.T) {
 	ctx := context.Background()
 	prdContent := "# Test PRD\nThis is a test PRD."
 
-	_, err := dec.validateAndRetry(ctx, prdContent, invalidTaskYAML)
+	_, err := dec.validateAndRetry(ctx, DecomposeRequest{}, prdContent, invalidTaskYAML)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "validation failed after")
@@ -356,7 +358,7 @@ func TestValidateAndRetry_InvalidYAMLSyntax(t *testing.T) {
 	prdContent := "# Test PRD\nThis is a test PRD."
 	invalidSyntax := "tasks:\n  - id: test\n    title: [invalid yaml"
 
-	result, err := dec.validateAndRetry(ctx, prdContent, invalidSyntax)
+	result, err := dec.validateAndRetry(ctx, DecomposeRequest{}, prdContent, invalidSyntax)
 
 	require.NoError(t, err)
 	assert.Contains(t, result, "id: test-root")
@@ -374,7 +376,7 @@ func TestValidateAndRetry_ClaudeError(t *testing.T) {
 	ctx := context.Background()
 	prdContent := "# Test PRD\nThis is a test PRD."
 
-	_, err := dec.validateAndRetry(ctx, prdContent, invalidTaskYAML)
+	_, err := dec.validateAndRetry(ctx, DecomposeRequest{}, prdContent, invalidTaskYAML)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get fixed YAML from Claude")
@@ -645,3 +647,270 @@ func TestDecompose_FixPromptContainsContext(t *testing.T) {
 	// The invalid YAML has orphan parent reference, so error should mention parent
 	assert.Contains(t, fixRequest.Prompt, "Validation Errors", "fix prompt should have validation errors section")
 }
+
+// recordingRunner records requests and returns a fixed response.
+type recordingRunner struct {
+	response *claude.ClaudeResponse
+	requests []claude.ClaudeRequest
+}
+
+func (m *recordingRunner) Run(ctx context.Context, req claude.ClaudeRequest) (*claude.ClaudeResponse, error) {
+	m.requests = append(m.requests, req)
+	return m.response, nil
+}
+
+func TestDecomposeToTasks_ReturnsTasksWithoutWriting(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# Test PRD"), 0644))
+
+	runner := &recordingRunner{
+		response: &claude.ClaudeResponse{
+			SessionID: "dry-session",
+			FinalText: validTaskYAML,
+		},
+	}
+
+	dec := NewDecomposer(runner)
+	tasks, result, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath: prdPath,
+		WorkDir: tmpDir,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "test-root", tasks[0].ID)
+	assert.Equal(t, "Test Root", tasks[0].Title)
+	assert.Equal(t, [][]string{{"go", "test", "./..."}}, tasks[0].Verify)
+	assert.Equal(t, "dry-session", result.SessionID)
+	assert.Empty(t, result.OutputPath)
+
+	// Claude must not be allowed to write, and nothing is written
+	require.Len(t, runner.requests, 1)
+	assert.Empty(t, runner.requests[0].AllowedTools)
+	_, err = os.Stat(filepath.Join(tmpDir, config.DefaultTasksFile))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestConvertYAMLTaskToTask_MapsPriorityLabel(t *testing.T) {
+	tests := []struct {
+		labels map[string]string
+		want   taskstore.Priority
+	}{
+		{labels: map[string]string{"priority": "P0"}, want: taskstore.PriorityP0},
+		{labels: map[string]string{"priority": "P2"}, want: taskstore.PriorityP2},
+		{labels: map[string]string{"priority": "soon"}, want: ""},
+		{labels: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		task := convertYAMLTaskToTask(taskstore.YAMLTask{ID: "task-1", Title: "Task", Labels: tt.labels})
+		assert.Equal(t, tt.want, task.Priority)
+	}
+}
+
+func TestDecomposeToTasks_IgnoresExistingTasksFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# Test PRD"), 0644))
+
+	// A stale tasks file from an earlier run must not be picked up
+	tasksPath := filepath.Join(tmpDir, config.DefaultTasksFile)
+	require.NoError(t, os.MkdirAll(filepath.Dir(tasksPath), 0755))
+	require.NoError(t, os.WriteFile(tasksPath, []byte(invalidTaskYAML), 0644))
+
+	dec := NewDecomposer(&mockRunner{
+		response: &claude.ClaudeResponse{FinalText: validTaskYAML},
+	})
+	tasks, _, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath: prdPath,
+		WorkDir: tmpDir,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "test-root", tasks[0].ID)
+}
+
+func TestDecompose_PassesModel(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# Test PRD"), 0644))
+
+	runner := &capturingMockRunner{
+		responses: []*claude.ClaudeResponse{
+			{FinalText: invalidTaskYAML},
+			{FinalText: fixedTaskYAML},
+		},
+		errors: []error{nil, nil},
+	}
+
+	dec := NewDecomposer(runner)
+	_, _, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath: prdPath,
+		WorkDir: tmpDir,
+		Model:   "opus",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, runner.requests, 2)
+	for _, req := range runner.requests {
+		assert.Equal(t, []string{"--model", "opus"}, req.ExtraArgs)
+	}
+}
+
+func TestDecompose_PlainTextSpec(t *testing.T) {
+	tmpDir := t.TempDir()
+	specPath := filepath.Join(tmpDir, "notes.txt")
+	spec := "Build a greeter CLI.\n\n## Not a section\nIt prints hello.\n"
+	require.NoError(t, os.WriteFile(specPath, []byte(spec), 0644))
+
+	runner := &capturingMockRunner{
+		responses: []*claude.ClaudeResponse{{FinalText: fixedTaskYAML}},
+		errors:    []error{nil},
+	}
+	var progress bytes.Buffer
+
+	dec := NewDecomposer(runner)
+	_, _, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath:   specPath,
+		WorkDir:   tmpDir,
+		Sectioned: true,
+		Progress:  &progress,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, runner.requests, 1, "plain text is decomposed in one request, not per section")
+	assert.Contains(t, runner.requests[0].Prompt, "plain text spec")
+	assert.Contains(t, runner.requests[0].Prompt, "not Markdown")
+	assert.Contains(t, runner.requests[0].Prompt, spec)
+	assert.Contains(t, progress.String(), "Plain-text spec has no sections")
+}
+
+func TestDecompose_CachesYAMLFailingValidation(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# Test PRD\n"), 0644))
+	cachePath := filepath.Join(tmpDir, ".ralph", "state", "decompose-cache.yaml")
+
+	runner := &sequentialMockRunner{
+		responses: []*claude.ClaudeResponse{
+			{FinalText: invalidTaskYAML},
+			{FinalText: invalidTaskYAML},
+			{FinalText: "tasks:\n  - id: test\n    title: [broken"},
+		},
+		errors: []error{nil, nil, nil},
+	}
+	dec := NewDecomposer(runner)
+
+	_, err := dec.Decompose(context.Background(), DecomposeRequest{
+		PRDPath:   prdPath,
+		WorkDir:   tmpDir,
+		CachePath: cachePath,
+	})
+
+	require.Error(t, err)
+	var cachedErr *CachedError
+	require.ErrorAs(t, err, &cachedErr)
+	assert.Equal(t, cachePath, cachedErr.Path)
+	assert.Contains(t, err.Error(), "validation failed after")
+
+	// The last YAML that parsed is cached, not the final unparseable one
+	cached, err := os.ReadFile(cachePath)
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(invalidTaskYAML), strings.TrimSpace(string(cached)))
+}
+
+func TestDecompose_FromCache(t *testing.T) {
+	setup := func(t *testing.T, cachedYAML string) (prdPath, cachePath string) {
+		tmpDir := t.TempDir()
+		prdPath = filepath.Join(tmpDir, "PRD.md")
+		require.NoError(t, os.WriteFile(prdPath, []byte("# Cached PRD\n"), 0644))
+		cachePath = filepath.Join(tmpDir, "decompose-cache.yaml")
+		require.NoError(t, os.WriteFile(cachePath, []byte(cachedYAML), 0644))
+		return prdPath, cachePath
+	}
+
+	t.Run("valid cache does not call the agent", func(t *testing.T) {
+		prdPath, cachePath := setup(t, validTaskYAML)
+		runner := &capturingMockRunner{}
+		dec := NewDecomposer(runner)
+
+		tasks, result, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+			PRDPath:   prdPath,
+			CachePath: cachePath,
+			FromCache: true,
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		assert.Equal(t, validTaskYAML, result.YAMLContent)
+		assert.Empty(t, runner.requests)
+		assert.NoFileExists(t, cachePath)
+	})
+
+	t.Run("invalid cache is fixed with the PRD as context", func(t *testing.T) {
+		prdPath, cachePath := setup(t, invalidTaskYAML)
+		runner := &capturingMockRunner{
+			responses: []*claude.ClaudeResponse{{FinalText: fixedTaskYAML}},
+			errors:    []error{nil},
+		}
+		dec := NewDecomposer(runner)
+
+		tasks, _, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+			PRDPath:   prdPath,
+			CachePath: cachePath,
+			FromCache: true,
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, tasks, 2)
+		require.Len(t, runner.requests, 1)
+		assert.Contains(t, runner.requests[0].Prompt, "# Cached PRD")
+		assert.Contains(t, runner.requests[0].Prompt, "id: test-child")
+		assert.NoFileExists(t, cachePath)
+	})
+
+	t.Run("missing cache", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		prdPath := filepath.Join(tmpDir, "PRD.md")
+		require.NoError(t, os.WriteFile(prdPath, []byte("# PRD\n"), 0644))
+		dec := NewDecomposer(&capturingMockRunner{})
+
+		_, _, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+			PRDPath:   prdPath,
+			CachePath: filepath.Join(tmpDir, "missing.yaml"),
+			FromCache: true,
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no cached decomposition")
+	})
+}
+
+func TestDecompose_MaxDepth(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# Test PRD"), 0644))
+
+	runner := &capturingMockRunner{
+		responses: []*claude.ClaudeResponse{
+			{FinalText: fixedTaskYAML},
+			{FinalText: validTaskYAML},
+		},
+		errors: []error{nil, nil},
+	}
+
+	dec := NewDecomposer(runner)
+	tasks, _, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath:  prdPath,
+		WorkDir:  tmpDir,
+		MaxDepth: 1,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Len(t, runner.requests, 2)
+	assert.Contains(t, runner.requests[0].Prompt, "at most 1 levels")
+	assert.Contains(t, runner.requests[1].Prompt, "test-child: nested 2 levels deep (max 1)")
+}
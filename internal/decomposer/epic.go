@@ -0,0 +1,134 @@
+package decomposer
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// EpicScope limits a decomposition to the subtree of one existing task.
+type EpicScope struct {
+	// EpicID is the task whose descendants are regenerated. The epic itself
+	// is kept as is.
+	EpicID string
+
+	// Tasks is the whole existing task set. The generated tasks replace the
+	// epic's descendants and are validated merged with the rest of it.
+	Tasks []*taskstore.Task
+}
+
+// epicPromptTemplate is the user prompt for re-decomposing one epic.
+const epicPromptTemplate = `Re-decompose ONLY the subtree of the existing epic %s from the PRD excerpt below.
+The rest of the plan is fine and stays untouched.
+
+Unlike a full decomposition:
+- Do NOT output a root task or the epic itself; output only its new descendants.
+- Top-level tasks of the new subtree must have parentId: %s.
+- Reuse the ID of a current subtree task when the new task covers the same work.
+- Tasks may depend on the other existing tasks listed below, but must not reuse their IDs.
+
+## Epic (%s)
+Title: %s
+
+%s
+
+## Current subtree
+%s
+## Other existing tasks
+%s
+## PRD excerpt
+%s
+
+Output the YAML for %s only:`
+
+// epicPrompt builds the user prompt for re-decomposing scope's epic.
+func epicPrompt(scope *EpicScope, prdContent string) (string, error) {
+	var epic *taskstore.Task
+	for _, t := range scope.Tasks {
+		if t.ID == scope.EpicID {
+			epic = t
+		}
+	}
+	if epic == nil {
+		return "", fmt.Errorf("epic %q not found", scope.EpicID)
+	}
+
+	subtree := taskstore.Descendants(scope.Tasks, scope.EpicID)
+	current := "(none)\n"
+	if len(subtree) > 0 {
+		yamlTasks := make([]taskstore.YAMLTask, 0, len(subtree))
+		for _, t := range subtree {
+			yamlTasks = append(yamlTasks, toYAMLTask(t))
+		}
+		data, err := yaml.Marshal(taskstore.YAMLFile{Tasks: yamlTasks})
+		if err != nil {
+			return "", fmt.Errorf("failed to encode current subtree: %w", err)
+		}
+		current = string(data)
+	}
+
+	inSubtree := make(map[string]bool, len(subtree))
+	for _, t := range subtree {
+		inSubtree[t.ID] = true
+	}
+	var others strings.Builder
+	for _, t := range scope.Tasks {
+		if !inSubtree[t.ID] {
+			others.WriteString(fmt.Sprintf("- %s: %s\n", t.ID, t.Title))
+		}
+	}
+
+	return fmt.Sprintf(epicPromptTemplate, epic.ID, epic.ID, epic.ID, epic.Title, describeTask(epic),
+		current, others.String(), prdContent, config.DefaultTasksFile), nil
+}
+
+// toYAMLTask converts a task back to its YAML form, without status.
+func toYAMLTask(t *taskstore.Task) taskstore.YAMLTask {
+	yt := taskstore.YAMLTask{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		DependsOn:   t.DependsOn,
+		Acceptance:  t.Acceptance,
+		Verify:      t.Verify,
+		VerifySet:   t.VerifySet,
+		VerifyWhen:  t.VerifyWhen,
+		Labels:      t.Labels,
+
+		VerifyExitCodes: t.VerifyExitCodes,
+		ContextFiles:    t.ContextFiles,
+		AllowedPaths:    t.AllowedPaths,
+	}
+	if t.ParentID != nil {
+		yt.ParentID = *t.ParentID
+	}
+	return yt
+}
+
+// lintTasks validates generated tasks against req: on their own, or merged
+// into the existing tree when req.Epic or req.Merge is set, and against
+// req.MaxDepth.
+func lintTasks(tasks []*taskstore.Task, req DecomposeRequest) error {
+	if req.Merge != nil {
+		merged, err := taskstore.AppendSubtree(req.Merge.Tasks, req.Merge.ParentID, tasks)
+		if err != nil {
+			return err
+		}
+		return depthError(merged, req.MaxDepth)
+	}
+	if req.Epic != nil {
+		merged, err := taskstore.MergeSubtree(req.Epic.Tasks, req.Epic.EpicID, tasks)
+		if err != nil {
+			return err
+		}
+		return depthError(merged, req.MaxDepth)
+	}
+	if err := taskstore.LintTaskSet(tasks).Error(); err != nil {
+		return err
+	}
+	return depthError(tasks, req.MaxDepth)
+}
@@ -0,0 +1,116 @@
+package decomposer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func newEpicTestTask(id, parentID string) *taskstore.Task {
+	task := &taskstore.Task{
+		ID:          id,
+		Title:       "Title " + id,
+		Description: "Description " + id,
+		Status:      taskstore.StatusOpen,
+		Verify:      [][]string{{"go", "test", "./..."}},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if parentID != "" {
+		task.ParentID = &parentID
+	}
+	return task
+}
+
+// epicYAML regenerates the api epic, keeping api-login and adding api-logout.
+const epicYAML = `tasks:
+  - id: api-login
+    title: Login endpoint
+    description: Create internal/api/login.go
+    parentId: api
+    verify:
+      - ["go", "test", "./internal/api/..."]
+  - id: api-logout
+    title: Logout endpoint
+    description: Create internal/api/logout.go
+    parentId: api
+    dependsOn: [api-login]
+    verify:
+      - ["go", "test", "./internal/api/..."]
+`
+
+// epicOutsideYAML attaches a task to the ui epic instead of api.
+const epicOutsideYAML = `tasks:
+  - id: api-login
+    title: Login endpoint
+    description: Create internal/api/login.go
+    parentId: ui
+    verify:
+      - ["go", "test", "./internal/api/..."]
+`
+
+func TestDecomposeToTasks_Epic(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "api.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# API\nLogin and logout."), 0644))
+
+	existing := []*taskstore.Task{
+		newEpicTestTask("root", ""),
+		newEpicTestTask("api", "root"),
+		newEpicTestTask("api-login", "api"),
+		newEpicTestTask("api-session", "api"),
+		newEpicTestTask("ui", "root"),
+		newEpicTestTask("ui-form", "ui"),
+	}
+
+	runner := &capturingMockRunner{
+		responses: []*claude.ClaudeResponse{
+			{FinalText: epicOutsideYAML},
+			{FinalText: epicYAML},
+		},
+		errors: []error{nil, nil},
+	}
+
+	dec := NewDecomposer(runner)
+	tasks, _, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath: prdPath,
+		WorkDir: tmpDir,
+		Epic:    &EpicScope{EpicID: "api", Tasks: existing},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "api-login", tasks[0].ID)
+	assert.Equal(t, "api-logout", tasks[1].ID)
+
+	require.Len(t, runner.requests, 2)
+	prompt := runner.requests[0].Prompt
+	assert.Contains(t, prompt, "Re-decompose ONLY the subtree of the existing epic api")
+	assert.Contains(t, prompt, "id: api-session", "the current subtree should be shown")
+	assert.Contains(t, prompt, "- ui-form: Title ui-form", "other tasks should be listed")
+	assert.NotContains(t, prompt, "- api-login: Title api-login")
+	assert.Contains(t, runner.requests[1].Prompt, `task "api-login" is not a descendant of api`)
+}
+
+func TestDecomposeToTasks_EpicNotFound(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "api.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# API"), 0644))
+
+	dec := NewDecomposer(&mockRunner{})
+	_, _, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath: prdPath,
+		Epic:    &EpicScope{EpicID: "missing", Tasks: []*taskstore.Task{newEpicTestTask("root", "")}},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `epic "missing" not found`)
+}
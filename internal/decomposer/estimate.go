@@ -0,0 +1,64 @@
+package decomposer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/yarlson/ralph/internal/claude"
+)
+
+// outputTokensPerPRDToken projects the size of the generated tasks YAML from
+// the PRD: tasks restate the PRD with file paths, acceptance criteria and
+// verify commands, so the YAML is typically a few times longer.
+const outputTokensPerPRDToken = 3
+
+// minOutputTokens is the projected output for very short PRDs.
+const minOutputTokens = 1000
+
+// Estimate is a rough projection of a decomposition's size and cost.
+type Estimate struct {
+	// SystemPromptTokens and UserPromptTokens make up InputTokens. The user
+	// prompt includes the PRD.
+	SystemPromptTokens int
+	UserPromptTokens   int
+	InputTokens        int
+
+	// PRDTokens is the size of the PRD alone.
+	PRDTokens int
+
+	// OutputTokens is the projected size of the generated YAML.
+	OutputTokens int
+
+	// CostUSD prices InputTokens and OutputTokens for req.Model.
+	CostUSD float64
+}
+
+// EstimateDecomposition projects the tokens and cost of decomposing
+// req.PRDPath in one pass, without calling the agent. Token counts are
+// approximations, and validation retries and the agent's own overhead are not
+// included.
+func EstimateDecomposition(req DecomposeRequest) (*Estimate, error) {
+	prdContent, err := os.ReadFile(req.PRDPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PRD file: %w", err)
+	}
+
+	systemPrompt, err := loadSystemPrompt(req, string(prdContent))
+	if err != nil {
+		return nil, err
+	}
+	userPrompt, err := generatePrompt(req, string(prdContent))
+	if err != nil {
+		return nil, err
+	}
+
+	est := &Estimate{
+		SystemPromptTokens: claude.EstimateTokens(systemPrompt),
+		UserPromptTokens:   claude.EstimateTokens(userPrompt),
+		PRDTokens:          claude.EstimateTokens(string(prdContent)),
+	}
+	est.InputTokens = est.SystemPromptTokens + est.UserPromptTokens
+	est.OutputTokens = max(est.PRDTokens*outputTokensPerPRDToken, minOutputTokens)
+	est.CostUSD = claude.PricingForModel(req.Model).Cost(est.InputTokens, est.OutputTokens)
+	return est, nil
+}
@@ -0,0 +1,64 @@
+package decomposer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+)
+
+func TestEstimateDecomposition(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "prd.md")
+	prd := "# Product\n\n" + strings.Repeat("The service must do one more thing. ", 200)
+	require.NoError(t, os.WriteFile(prdPath, []byte(prd), 0644))
+
+	t.Run("projects tokens and cost", func(t *testing.T) {
+		est, err := EstimateDecomposition(DecomposeRequest{PRDPath: prdPath, WorkDir: tmpDir})
+		require.NoError(t, err)
+
+		assert.Equal(t, claude.EstimateTokens(getSystemPrompt()), est.SystemPromptTokens)
+		assert.Equal(t, claude.EstimateTokens(prd), est.PRDTokens)
+		assert.Greater(t, est.UserPromptTokens, est.PRDTokens, "the user prompt wraps the PRD")
+		assert.Equal(t, est.SystemPromptTokens+est.UserPromptTokens, est.InputTokens)
+		assert.Equal(t, est.PRDTokens*outputTokensPerPRDToken, est.OutputTokens)
+		assert.InDelta(t, claude.PricingForModel("").Cost(est.InputTokens, est.OutputTokens), est.CostUSD, 1e-9)
+	})
+
+	t.Run("prices the requested model", func(t *testing.T) {
+		def, err := EstimateDecomposition(DecomposeRequest{PRDPath: prdPath, WorkDir: tmpDir})
+		require.NoError(t, err)
+		opus, err := EstimateDecomposition(DecomposeRequest{PRDPath: prdPath, WorkDir: tmpDir, Model: "opus"})
+		require.NoError(t, err)
+
+		assert.Greater(t, opus.CostUSD, def.CostUSD)
+	})
+
+	t.Run("short PRDs project a minimum output", func(t *testing.T) {
+		shortPath := filepath.Join(tmpDir, "short.md")
+		require.NoError(t, os.WriteFile(shortPath, []byte("# Tiny"), 0644))
+
+		est, err := EstimateDecomposition(DecomposeRequest{PRDPath: shortPath, WorkDir: tmpDir})
+		require.NoError(t, err)
+		assert.Equal(t, minOutputTokens, est.OutputTokens)
+	})
+
+	t.Run("uses the custom system prompt", func(t *testing.T) {
+		promptPath := filepath.Join(tmpDir, "prompt.md")
+		require.NoError(t, os.WriteFile(promptPath, []byte("Short prompt."), 0644))
+
+		est, err := EstimateDecomposition(DecomposeRequest{PRDPath: prdPath, WorkDir: tmpDir, SystemPromptFile: promptPath})
+		require.NoError(t, err)
+		assert.Equal(t, claude.EstimateTokens("Short prompt."), est.SystemPromptTokens)
+	})
+
+	t.Run("missing PRD", func(t *testing.T) {
+		_, err := EstimateDecomposition(DecomposeRequest{PRDPath: filepath.Join(tmpDir, "missing.md")})
+		assert.ErrorContains(t, err, "failed to read PRD file")
+	})
+}
@@ -0,0 +1,89 @@
+package decomposer
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// MergeScope adds a decomposition to an existing task tree instead of
+// replacing it.
+type MergeScope struct {
+	// ParentID is the existing task the generated tasks are added under.
+	ParentID string
+
+	// Tasks is the whole existing task set. The generated tasks are added to
+	// it and validated against the merged set; existing tasks are kept as is.
+	Tasks []*taskstore.Task
+}
+
+// mergePromptTemplate is the user prompt for adding to an existing plan.
+const mergePromptTemplate = `Extend the existing plan below with tasks for the parts of the PRD it does not cover yet.
+The existing tasks stay as they are, including completed ones.
+
+Unlike a full decomposition:
+- Do NOT output a root task or any existing task; output only the NEW tasks.
+- New tasks must sit below %s: use parentId: %s, an existing task below it, or another new task.
+- New task IDs must not reuse any existing ID.
+- New tasks may depend on existing tasks by ID.
+- If the existing plan already covers the whole PRD, output "tasks: []".
+
+## Parent (%s)
+Title: %s
+
+## Existing tasks
+%s
+## PRD
+%s
+
+Output the YAML for %s only:`
+
+// mergePrompt builds the user prompt for adding to scope's tree.
+func mergePrompt(scope *MergeScope, prdContent string) (string, error) {
+	var parent *taskstore.Task
+	for _, t := range scope.Tasks {
+		if t.ID == scope.ParentID {
+			parent = t
+		}
+	}
+	if parent == nil {
+		return "", fmt.Errorf("parent task %q not found", scope.ParentID)
+	}
+
+	subtree := taskstore.Descendants(scope.Tasks, scope.ParentID)
+	existing := "(none)\n"
+	if len(subtree) > 0 {
+		yamlTasks := make([]taskstore.YAMLTask, 0, len(subtree))
+		for _, t := range subtree {
+			yt := toYAMLTask(t)
+			yt.Status = string(t.Status)
+			yamlTasks = append(yamlTasks, yt)
+		}
+		data, err := yaml.Marshal(taskstore.YAMLFile{Tasks: yamlTasks})
+		if err != nil {
+			return "", fmt.Errorf("failed to encode existing tasks: %w", err)
+		}
+		existing = string(data)
+	}
+
+	inSubtree := make(map[string]bool, len(subtree))
+	for _, t := range subtree {
+		inSubtree[t.ID] = true
+	}
+	var others strings.Builder
+	for _, t := range scope.Tasks {
+		if !inSubtree[t.ID] && t.ID != parent.ID {
+			others.WriteString(fmt.Sprintf("- %s: %s\n", t.ID, t.Title))
+		}
+	}
+	if others.Len() > 0 {
+		existing += "\nOther tasks (outside " + parent.ID + "):\n" + others.String()
+	}
+
+	return fmt.Sprintf(mergePromptTemplate, parent.ID, parent.ID, parent.ID, parent.Title,
+		existing, prdContent, config.DefaultTasksFile), nil
+}
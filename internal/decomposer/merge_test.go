@@ -0,0 +1,95 @@
+package decomposer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// mergeYAML adds a ui epic that depends on the existing api work.
+const mergeYAML = `tasks:
+  - id: ui
+    title: UI
+    description: Build the UI
+    parentId: root
+  - id: ui-form
+    title: Login form
+    description: Create web/login.tsx
+    parentId: ui
+    dependsOn: [api-login]
+    verify:
+      - ["npm", "test"]
+`
+
+// mergeCollisionYAML regenerates an existing task.
+const mergeCollisionYAML = `tasks:
+  - id: api-login
+    title: Login endpoint
+    description: Create internal/api/login.go
+    parentId: api
+    verify:
+      - ["go", "test", "./internal/api/..."]
+`
+
+func TestDecomposeToTasks_Merge(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "prd.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# App\n\n## Requirements\nAPI and UI."), 0644))
+
+	existing := []*taskstore.Task{
+		newEpicTestTask("root", ""),
+		newEpicTestTask("api", "root"),
+		newEpicTestTask("api-login", "api"),
+	}
+	existing[2].Status = taskstore.StatusCompleted
+
+	runner := &capturingMockRunner{
+		responses: []*claude.ClaudeResponse{
+			{FinalText: mergeCollisionYAML},
+			{FinalText: mergeYAML},
+		},
+		errors: []error{nil, nil},
+	}
+
+	dec := NewDecomposer(runner)
+	tasks, _, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath:   prdPath,
+		WorkDir:   tmpDir,
+		Sectioned: true,
+		Merge:     &MergeScope{ParentID: "root", Tasks: existing},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "ui", tasks[0].ID)
+	assert.Equal(t, "ui-form", tasks[1].ID)
+
+	require.Len(t, runner.requests, 2)
+	prompt := runner.requests[0].Prompt
+	assert.Contains(t, prompt, "Extend the existing plan below")
+	assert.Contains(t, prompt, "id: api-login", "the existing tasks should be shown")
+	assert.Contains(t, prompt, "status: completed")
+	assert.Contains(t, runner.requests[1].Prompt, `task "api-login" already exists`)
+}
+
+func TestDecomposeToTasks_MergeParentNotFound(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "prd.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# App"), 0644))
+
+	dec := NewDecomposer(&mockRunner{})
+	_, _, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath: prdPath,
+		Merge:   &MergeScope{ParentID: "missing", Tasks: []*taskstore.Task{newEpicTestTask("root", "")}},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `parent task "missing" not found`)
+}
@@ -0,0 +1,87 @@
+package decomposer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/detect"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// SystemPromptData is what a custom decomposer system prompt template can
+// refer to.
+type SystemPromptData struct {
+	// Default is the built-in system prompt, for templates that extend it
+	// rather than replace it.
+	Default string
+
+	// ProjectSlug is derived from the PRD's "# " title, or from the working
+	// directory name when the PRD has none.
+	ProjectSlug string
+
+	// VerifyCommand is the default verify command for the project's
+	// toolchain (e.g. "go test ./..."), or "" if none is detected.
+	VerifyCommand string
+
+	// TasksFile is the path of the tasks file the agent should produce.
+	TasksFile string
+}
+
+// loadSystemPrompt returns the system prompt for req: the built-in one, or
+// req.SystemPromptFile rendered as a text/template with SystemPromptData.
+// A relative SystemPromptFile is resolved against req.WorkDir.
+func loadSystemPrompt(req DecomposeRequest, prdContent string) (string, error) {
+	if req.SystemPromptFile == "" {
+		return getSystemPrompt(), nil
+	}
+
+	path := req.SystemPromptFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(req.WorkDir, path)
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read system prompt file: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Parse(string(text))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse system prompt file: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, systemPromptData(req.WorkDir, prdContent)); err != nil {
+		return "", fmt.Errorf("failed to render system prompt file: %w", err)
+	}
+	prompt := strings.TrimSpace(buf.String())
+	if prompt == "" {
+		return "", fmt.Errorf("system prompt file %s rendered an empty prompt", req.SystemPromptFile)
+	}
+	return prompt, nil
+}
+
+// systemPromptData builds the template data for a PRD in workDir.
+func systemPromptData(workDir, prdContent string) SystemPromptData {
+	title, _, _ := splitPRD(prdContent)
+	slug := taskstore.IDFromTitle(title)
+	if slug == "" {
+		slug = taskstore.IDFromTitle(filepath.Base(workDir))
+	}
+
+	var verify string
+	if commands := detect.DetectVerifyCommands(workDir); len(commands) > 0 {
+		verify = strings.Join(commands[0], " ")
+	}
+
+	return SystemPromptData{
+		Default:       getSystemPrompt(),
+		ProjectSlug:   slug,
+		VerifyCommand: verify,
+		TasksFile:     config.DefaultTasksFile,
+	}
+}
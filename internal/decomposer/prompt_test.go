@@ -0,0 +1,110 @@
+package decomposer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/config"
+)
+
+func TestLoadSystemPrompt(t *testing.T) {
+	workDir := filepath.Join(t.TempDir(), "My Project")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "go.mod"), []byte("module x\n"), 0644))
+
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(workDir, "decompose.md")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return "decompose.md"
+	}
+
+	t.Run("built-in prompt by default", func(t *testing.T) {
+		prompt, err := loadSystemPrompt(DecomposeRequest{WorkDir: workDir}, "# PRD")
+		require.NoError(t, err)
+		assert.Equal(t, getSystemPrompt(), prompt)
+	})
+
+	t.Run("renders template variables", func(t *testing.T) {
+		file := write(t, "Plan {{.ProjectSlug}} into {{.TasksFile}}; verify with {{.VerifyCommand}}.\n")
+
+		prompt, err := loadSystemPrompt(DecomposeRequest{WorkDir: workDir, SystemPromptFile: file}, "# Acme Onboarding\n\nText.")
+		require.NoError(t, err)
+		assert.Equal(t, "Plan acme-onboarding into "+config.DefaultTasksFile+"; verify with go test ./....", prompt)
+	})
+
+	t.Run("slug falls back to the working directory", func(t *testing.T) {
+		file := write(t, "{{.ProjectSlug}}")
+
+		prompt, err := loadSystemPrompt(DecomposeRequest{WorkDir: workDir, SystemPromptFile: file}, "No title here.")
+		require.NoError(t, err)
+		assert.Equal(t, "my-project", prompt)
+	})
+
+	t.Run("extends the built-in prompt", func(t *testing.T) {
+		file := write(t, "{{.Default}}\n\nThis is a documentation project; tasks may edit several files.")
+
+		prompt, err := loadSystemPrompt(DecomposeRequest{WorkDir: workDir, SystemPromptFile: file}, "# PRD")
+		require.NoError(t, err)
+		assert.Contains(t, prompt, "You are Task Decomposer")
+		assert.Contains(t, prompt, "documentation project")
+	})
+
+	errTests := []struct {
+		name    string
+		content string
+		file    string
+		wantErr string
+	}{
+		{name: "missing file", file: "missing.md", wantErr: "failed to read system prompt file"},
+		{name: "invalid template", content: "{{.ProjectSlug", wantErr: "failed to parse system prompt file"},
+		{name: "unknown variable", content: "{{.Unknown}}", wantErr: "failed to render system prompt file"},
+		{name: "empty prompt", content: "  \n", wantErr: "rendered an empty prompt"},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := tt.file
+			if file == "" {
+				file = write(t, tt.content)
+			}
+
+			_, err := loadSystemPrompt(DecomposeRequest{WorkDir: workDir, SystemPromptFile: file}, "# PRD")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestDecompose_CustomSystemPrompt(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# Docs Site"), 0644))
+	promptPath := filepath.Join(tmpDir, "prompt.md")
+	require.NoError(t, os.WriteFile(promptPath, []byte("Custom decomposer for {{.ProjectSlug}}."), 0644))
+
+	runner := &capturingMockRunner{
+		responses: []*claude.ClaudeResponse{
+			{FinalText: invalidTaskYAML},
+			{FinalText: fixedTaskYAML},
+		},
+		errors: []error{nil, nil},
+	}
+
+	dec := NewDecomposer(runner)
+	_, _, err := dec.DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath:          prdPath,
+		WorkDir:          tmpDir,
+		SystemPromptFile: promptPath,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, runner.requests, 2)
+	for _, req := range runner.requests {
+		assert.Equal(t, "Custom decomposer for docs-site.", req.SystemPrompt, "generation and fixes use the custom prompt")
+	}
+}
@@ -0,0 +1,303 @@
+package decomposer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// prdSection is one level-2 ("## ") section of a PRD.
+type prdSection struct {
+	Heading string
+	Content string
+}
+
+// splitPRD splits a PRD at its level-2 headings. It returns the title (the
+// first level-1 heading, if any), the text before the first section, and the
+// sections in order. Headings inside fenced code blocks are ignored.
+func splitPRD(content string) (title, preamble string, sections []prdSection) {
+	var pre strings.Builder
+	var current *prdSection
+	var body strings.Builder
+	inFence := false
+
+	flush := func() {
+		if current != nil {
+			current.Content = strings.TrimSpace(body.String())
+			sections = append(sections, *current)
+		}
+		body.Reset()
+	}
+
+	for line := range strings.SplitSeq(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+		switch {
+		case !inFence && strings.HasPrefix(line, "## "):
+			flush()
+			current = &prdSection{Heading: strings.TrimSpace(strings.TrimPrefix(line, "## "))}
+			body.WriteString(line + "\n")
+		case current != nil:
+			body.WriteString(line + "\n")
+		case !inFence && title == "" && strings.HasPrefix(line, "# "):
+			title = strings.TrimSpace(strings.TrimPrefix(line, "# "))
+		default:
+			pre.WriteString(line + "\n")
+		}
+	}
+	flush()
+	return title, strings.TrimSpace(pre.String()), sections
+}
+
+// sectionCheckpoint records the sections of a sectioned decomposition that
+// have completed, so a failed decomposition can resume after them.
+type sectionCheckpoint struct {
+	// PRDHash identifies the PRD the sections were generated from. A
+	// checkpoint for a different PRD is ignored.
+	PRDHash  string                `yaml:"prdHash"`
+	Root     *taskstore.YAMLTask   `yaml:"root"`
+	Sections []checkpointedSection `yaml:"sections"`
+}
+
+// checkpointedSection is a completed section and the tasks generated for it.
+type checkpointedSection struct {
+	Heading string               `yaml:"heading"`
+	Tasks   []taskstore.YAMLTask `yaml:"tasks"`
+}
+
+// readCheckpoint reads the checkpoint at path. It returns nil when there is
+// none or it belongs to a different PRD.
+func readCheckpoint(path, prdHash string) (*sectionCheckpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read section checkpoint: %w", err)
+	}
+	var cp sectionCheckpoint
+	if err := yaml.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse section checkpoint: %w", err)
+	}
+	if cp.PRDHash != prdHash {
+		return nil, nil
+	}
+	return &cp, nil
+}
+
+// writeCheckpoint saves cp to path, creating its directory if needed.
+func writeCheckpoint(path string, cp *sectionCheckpoint) error {
+	if path == "" {
+		return nil
+	}
+	data, err := yaml.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to encode section checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write section checkpoint: %w", err)
+	}
+	return nil
+}
+
+// removeCheckpoint removes the checkpoint at path, if any.
+func removeCheckpoint(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove section checkpoint: %w", err)
+	}
+	return nil
+}
+
+// sectionPromptTemplate is the user prompt for decomposing one PRD section.
+const sectionPromptTemplate = `Decompose ONLY section %d of %d ("%s") of the PRD into tasks.
+The other sections are decomposed separately.
+
+Unlike a full decomposition:
+- Do NOT output a root task; top-level tasks of this section must have parentId: %s.
+- Tasks may depend on the tasks already generated for earlier sections, listed below, but must not reuse their IDs.
+
+## Root task (%s)
+Title: %s
+
+## PRD outline
+%s
+## Tasks from earlier sections
+%s
+## Section to decompose
+%s
+
+Output the YAML for this section's tasks only:`
+
+// sectionPrompt builds the user prompt for decomposing sections[index].
+func sectionPrompt(root taskstore.YAMLTask, sections []prdSection, index int, earlier []taskstore.YAMLTask) string {
+	var outline strings.Builder
+	for i, s := range sections {
+		_, _ = fmt.Fprintf(&outline, "%d. %s\n", i+1, s.Heading)
+	}
+	var prior strings.Builder
+	for _, t := range earlier {
+		_, _ = fmt.Fprintf(&prior, "- %s: %s\n", t.ID, t.Title)
+	}
+	if prior.Len() == 0 {
+		prior.WriteString("(none)\n")
+	}
+	s := sections[index]
+	return fmt.Sprintf(sectionPromptTemplate, index+1, len(sections), s.Heading, root.ID,
+		root.ID, root.Title, outline.String(), prior.String(), s.Content)
+}
+
+// sectionRoot builds the root task of a sectioned decomposition from the
+// PRD's title and preamble.
+func sectionRoot(prdPath, title, preamble string) taskstore.YAMLTask {
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(prdPath), filepath.Ext(prdPath))
+	}
+	id := taskstore.IDFromTitle(title)
+	if id == "" {
+		id = "root"
+	}
+	description := preamble
+	if description == "" {
+		description = "Implement " + title + "."
+	}
+	return taskstore.YAMLTask{ID: id, Title: title, Description: description}
+}
+
+// generateSectioned decomposes the PRD one level-2 section at a time. Each
+// section's tasks are validated together with the root and earlier sections
+// and checkpointed to req.CheckpointPath, so a failed decomposition resumes
+// after the last completed section. It returns the combined YAML, or ok
+// false when the PRD has fewer than two sections.
+func (d *Decomposer) generateSectioned(ctx context.Context, req DecomposeRequest, prdContent string) (string, *claude.ClaudeResponse, bool, error) {
+	title, preamble, sections := splitPRD(prdContent)
+	if len(sections) < 2 {
+		d.progress(req, "PRD has fewer than two sections; decomposing in one pass\n")
+		return "", nil, false, nil
+	}
+
+	sum := sha256.Sum256([]byte(prdContent))
+	prdHash := hex.EncodeToString(sum[:])
+	cp, err := readCheckpoint(req.CheckpointPath, prdHash)
+	if err != nil {
+		return "", nil, false, err
+	}
+	if cp == nil || cp.Root == nil {
+		root := sectionRoot(req.PRDPath, title, preamble)
+		cp = &sectionCheckpoint{PRDHash: prdHash, Root: &root}
+	}
+	root := *cp.Root
+	rootTask := convertYAMLTaskToTask(root)
+
+	var generated []taskstore.YAMLTask
+	for _, s := range cp.Sections {
+		generated = append(generated, s.Tasks...)
+	}
+
+	total := &claude.ClaudeResponse{}
+	for i := range sections {
+		if i < len(cp.Sections) {
+			d.progress(req, "Section %d/%d: %s (from checkpoint, %d task(s))\n", i+1, len(sections), sections[i].Heading, len(cp.Sections[i].Tasks))
+			continue
+		}
+
+		d.progress(req, "Section %d/%d: %s\n", i+1, len(sections), sections[i].Heading)
+		start := time.Now()
+		tasks, resp, err := d.generateSection(ctx, req, root, rootTask, sections, i, generated)
+		if err != nil {
+			if len(cp.Sections) > 0 && req.CheckpointPath != "" {
+				return "", nil, false, fmt.Errorf("section %d (%s): %w (%d completed sections checkpointed to %s; run again to resume)",
+					i+1, sections[i].Heading, err, len(cp.Sections), req.CheckpointPath)
+			}
+			return "", nil, false, fmt.Errorf("section %d (%s): %w", i+1, sections[i].Heading, err)
+		}
+		total.SessionID = resp.SessionID
+		total.Model = resp.Model
+		total.RawEventsPath = resp.RawEventsPath
+		total.TotalCostUSD += resp.TotalCostUSD
+
+		generated = append(generated, tasks...)
+		cp.Sections = append(cp.Sections, checkpointedSection{Heading: sections[i].Heading, Tasks: tasks})
+		if err := writeCheckpoint(req.CheckpointPath, cp); err != nil {
+			return "", nil, false, err
+		}
+		d.progress(req, "  ✓ %d task(s) (%d so far) in %s\n", len(tasks), len(generated), time.Since(start).Round(time.Second))
+	}
+
+	content, err := yaml.Marshal(taskstore.YAMLFile{Tasks: append([]taskstore.YAMLTask{root}, generated...)})
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to encode generated tasks: %w", err)
+	}
+	return string(content), total, true, nil
+}
+
+// generateSection asks the agent to decompose sections[index] and validates
+// the result against the root task and the tasks of earlier sections.
+func (d *Decomposer) generateSection(ctx context.Context, req DecomposeRequest, root taskstore.YAMLTask, rootTask *taskstore.Task, sections []prdSection, index int, earlier []taskstore.YAMLTask) ([]taskstore.YAMLTask, *claude.ClaudeResponse, error) {
+	if req.SectionTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.SectionTimeout)
+		defer cancel()
+	}
+
+	resp, err := d.runner.Run(ctx, claude.ClaudeRequest{
+		Cwd:          req.WorkDir,
+		SystemPrompt: req.systemPrompt,
+		Prompt:       sectionPrompt(root, sections, index, earlier),
+		AllowedTools: []string{},
+		ExtraArgs:    modelArgs(req.Model),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("claude execution failed: %w", err)
+	}
+	yamlContent := extractYAMLContent(resp)
+	if yamlContent == "" {
+		return nil, nil, fmt.Errorf("no YAML content found in response")
+	}
+
+	prior := []*taskstore.Task{rootTask}
+	for _, yt := range earlier {
+		prior = append(prior, convertYAMLTaskToTask(yt))
+	}
+	lint := func(tasks []*taskstore.Task) error {
+		return taskstore.LintTaskSet(append(prior[:len(prior):len(prior)], tasks...)).Error()
+	}
+
+	validated, _, err := d.validateWithFixes(ctx, req, sections[index].Content, yamlContent, lint)
+	if err != nil {
+		return nil, nil, err
+	}
+	yamlFile, err := taskstore.ParseYAML([]byte(validated))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse validated YAML: %w", err)
+	}
+	return yamlFile.Tasks, resp, nil
+}
+
+// progress writes a decomposition progress line to req.Progress, if set.
+func (d *Decomposer) progress(req DecomposeRequest, format string, args ...any) {
+	if req.Progress != nil {
+		_, _ = fmt.Fprintf(req.Progress, "  "+format, args...)
+	}
+}
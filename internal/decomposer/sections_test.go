@@ -0,0 +1,146 @@
+package decomposer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// sectionedPRD has a title, a preamble and two sections.
+const sectionedPRD = "# Billing Service\n\nCharge customers monthly.\n\n## Invoices\nGenerate invoices.\n\n```md\n## not a section\n```\n\n## Payments\nCollect payments.\n"
+
+// invoicesYAML is the decomposition of sectionedPRD's first section.
+const invoicesYAML = "```yaml\ntasks:\n  - id: invoices\n    title: Invoices\n    description: Generate invoices\n    parentId: billing-service\n    acceptance: [\"Invoices are generated\"]\n    verify: [[\"go\", \"test\", \"./...\"]]\n```"
+
+// paymentsYAML is the decomposition of sectionedPRD's second section.
+const paymentsYAML = "```yaml\ntasks:\n  - id: payments\n    title: Payments\n    description: Collect payments\n    parentId: billing-service\n    dependsOn: [invoices]\n    acceptance: [\"Payments are collected\"]\n    verify: [[\"go\", \"test\", \"./...\"]]\n```"
+
+func TestSplitPRD(t *testing.T) {
+	title, preamble, sections := splitPRD(sectionedPRD)
+
+	assert.Equal(t, "Billing Service", title)
+	assert.Equal(t, "Charge customers monthly.", preamble)
+	require.Len(t, sections, 2)
+	assert.Equal(t, "Invoices", sections[0].Heading)
+	assert.Contains(t, sections[0].Content, "## not a section")
+	assert.Equal(t, "Payments", sections[1].Heading)
+	assert.Equal(t, "## Payments\nCollect payments.", sections[1].Content)
+}
+
+func TestDecompose_Sectioned(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte(sectionedPRD), 0644))
+	checkpointPath := filepath.Join(tmpDir, "state", "sections.yaml")
+
+	runner := &capturingMockRunner{responses: []*claude.ClaudeResponse{
+		{FinalText: invoicesYAML, TotalCostUSD: 0.01},
+		{FinalText: paymentsYAML, TotalCostUSD: 0.02},
+	}}
+	var progress bytes.Buffer
+
+	tasks, result, err := NewDecomposer(runner).DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath:        prdPath,
+		WorkDir:        tmpDir,
+		Sectioned:      true,
+		CheckpointPath: checkpointPath,
+		Progress:       &progress,
+	})
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, []string{"billing-service", "invoices", "payments"}, ids)
+	assert.Equal(t, "Charge customers monthly.", tasks[0].Description)
+	assert.InDelta(t, 0.03, result.TotalCostUSD, 1e-9)
+
+	require.Len(t, runner.requests, 2)
+	assert.Contains(t, runner.requests[0].Prompt, "section 1 of 2 (\"Invoices\")")
+	assert.Contains(t, runner.requests[0].Prompt, "parentId: billing-service")
+	assert.NotContains(t, runner.requests[0].Prompt, "Collect payments")
+	assert.Contains(t, runner.requests[1].Prompt, "- invoices: Invoices")
+
+	assert.Contains(t, progress.String(), "Section 1/2: Invoices\n")
+	assert.Contains(t, progress.String(), "✓ 1 task(s) (2 so far)")
+	assert.NoFileExists(t, checkpointPath)
+}
+
+func TestDecompose_SectionedResumesFromCheckpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte(sectionedPRD), 0644))
+	req := DecomposeRequest{
+		PRDPath:        prdPath,
+		WorkDir:        tmpDir,
+		Sectioned:      true,
+		CheckpointPath: filepath.Join(tmpDir, "sections.yaml"),
+	}
+
+	failing := &capturingMockRunner{
+		responses: []*claude.ClaudeResponse{{FinalText: invoicesYAML}, nil},
+		errors:    []error{nil, errors.New("connection reset")},
+	}
+	_, _, err := NewDecomposer(failing).DecomposeToTasks(context.Background(), req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "section 2 (Payments)")
+	assert.Contains(t, err.Error(), "run again to resume")
+	assert.FileExists(t, req.CheckpointPath)
+
+	var progress bytes.Buffer
+	req.Progress = &progress
+	resumed := &capturingMockRunner{responses: []*claude.ClaudeResponse{{FinalText: paymentsYAML}}}
+	tasks, _, err := NewDecomposer(resumed).DecomposeToTasks(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.Len(t, tasks, 3)
+	require.Len(t, resumed.requests, 1)
+	assert.Contains(t, resumed.requests[0].Prompt, "section 2 of 2")
+	assert.Contains(t, progress.String(), "Section 1/2: Invoices (from checkpoint, 1 task(s))")
+	assert.NoFileExists(t, req.CheckpointPath)
+}
+
+func TestDecompose_SectionedIgnoresCheckpointOfOtherPRD(t *testing.T) {
+	tmpDir := t.TempDir()
+	checkpointPath := filepath.Join(tmpDir, "sections.yaml")
+	require.NoError(t, writeCheckpoint(checkpointPath, &sectionCheckpoint{
+		PRDHash:  "other",
+		Root:     &taskstore.YAMLTask{ID: "old-root", Title: "Old"},
+		Sections: []checkpointedSection{{Heading: "Invoices"}},
+	}))
+
+	cp, err := readCheckpoint(checkpointPath, "current")
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+}
+
+func TestDecompose_SectionedFallsBackToOnePass(t *testing.T) {
+	tmpDir := t.TempDir()
+	prdPath := filepath.Join(tmpDir, "PRD.md")
+	require.NoError(t, os.WriteFile(prdPath, []byte("# Test PRD\nNo sections here."), 0644))
+
+	runner := &capturingMockRunner{responses: []*claude.ClaudeResponse{{FinalText: validTaskYAML}}}
+	var progress bytes.Buffer
+
+	tasks, _, err := NewDecomposer(runner).DecomposeToTasks(context.Background(), DecomposeRequest{
+		PRDPath:   prdPath,
+		WorkDir:   tmpDir,
+		Sectioned: true,
+		Progress:  &progress,
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, tasks, 1)
+	assert.Contains(t, progress.String(), "decomposing in one pass")
+	assert.Contains(t, progress.String(), "Generating tasks from the whole PRD")
+}
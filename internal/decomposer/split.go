@@ -0,0 +1,158 @@
+package decomposer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// maxSplitTasks caps how many sub-tasks a split may propose.
+const maxSplitTasks = 5
+
+// splitSystemPrompt instructs the agent to break one failing task into smaller sub-tasks.
+const splitSystemPrompt = `You are Task Splitter. A task executed by an autonomous Claude Code session failed on every attempt.
+Propose 2 to 5 smaller sub-tasks that together deliver the original task, each small enough to succeed in one session.
+
+OUTPUT (HARD REQUIREMENTS)
+- Output YAML only. No prose, no markdown fences, no commentary.
+- The YAML MUST conform to this schema:
+
+tasks:
+  - id: string (required, unique, prefixed with the original task id and a dash)
+    title: string (required)
+    description: string (optional, use YAML block scalar | when >1 line)
+    dependsOn: [string] (optional, ids of the other sub-tasks only)
+    acceptance: [string] (strongly preferred; testable statements)
+    verify: [[string]] (optional; each inner list is argv tokens for a command)
+
+- Do not include parentId or status; they are set for you.
+- Address the failure: isolate the part that failed so it can be attempted on its own.`
+
+// splitPromptTemplate is the user prompt for splitting a failing task.
+const splitPromptTemplate = `## Failing task (%s)
+Title: %s
+
+%s
+
+## Last failure
+%s
+
+Output the sub-task YAML only:`
+
+// SplitResult contains the sub-tasks proposed for a failing task.
+type SplitResult struct {
+	// Tasks are the proposed sub-tasks, children of the failing task.
+	Tasks []*taskstore.Task
+
+	// TotalCostUSD is the cost of the agent call.
+	TotalCostUSD float64
+}
+
+// Splitter asks the agent to split a repeatedly failing task into smaller
+// sub-tasks.
+type Splitter struct {
+	runner  claude.Runner
+	workDir string
+	model   string
+}
+
+// NewSplitter creates a Splitter that runs the agent in workDir with model
+// (empty for the provider's default).
+func NewSplitter(runner claude.Runner, workDir, model string) *Splitter {
+	return &Splitter{runner: runner, workDir: workDir, model: model}
+}
+
+// Split makes a single agent call proposing sub-tasks for task, given the
+// feedback from its last failure. The sub-tasks are children of task with
+// status open; dependencies outside the proposed set are dropped.
+func (s *Splitter) Split(ctx context.Context, task *taskstore.Task, failure string) (*SplitResult, error) {
+	resp, err := s.runner.Run(ctx, claude.ClaudeRequest{
+		Cwd:          s.workDir,
+		SystemPrompt: splitSystemPrompt,
+		Prompt:       fmt.Sprintf(splitPromptTemplate, task.ID, task.Title, describeTask(task), strings.TrimSpace(failure)),
+		AllowedTools: []string{}, // Text-only response
+		ExtraArgs:    modelArgs(s.model),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("claude execution failed: %w", err)
+	}
+
+	yamlContent := extractYAMLContent(resp)
+	if yamlContent == "" {
+		return nil, errors.New("no YAML content found in response")
+	}
+	tasks, err := parseSplitTasks(task, yamlContent)
+	if err != nil {
+		return nil, err
+	}
+	return &SplitResult{Tasks: tasks, TotalCostUSD: resp.TotalCostUSD}, nil
+}
+
+// describeTask renders the parts of task the agent needs to split it.
+func describeTask(task *taskstore.Task) string {
+	var sb strings.Builder
+	if task.Description != "" {
+		sb.WriteString(strings.TrimSpace(task.Description) + "\n\n")
+	}
+	if len(task.Acceptance) > 0 {
+		sb.WriteString("Acceptance:\n")
+		for _, a := range task.Acceptance {
+			sb.WriteString("- " + a + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	if len(task.Verify) > 0 {
+		sb.WriteString("Verify:\n")
+		for _, cmd := range task.Verify {
+			sb.WriteString("- " + strings.Join(cmd, " ") + "\n")
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// parseSplitTasks parses the proposed sub-tasks and attaches them to parent.
+func parseSplitTasks(parent *taskstore.Task, yamlContent string) ([]*taskstore.Task, error) {
+	yamlFile, err := taskstore.ParseYAML([]byte(yamlContent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sub-task YAML: %w", err)
+	}
+	if len(yamlFile.Tasks) == 0 {
+		return nil, errors.New("no sub-tasks proposed")
+	}
+	if len(yamlFile.Tasks) > maxSplitTasks {
+		return nil, fmt.Errorf("%d sub-tasks proposed (at most %d allowed)", len(yamlFile.Tasks), maxSplitTasks)
+	}
+
+	ids := make(map[string]bool, len(yamlFile.Tasks))
+	for _, yt := range yamlFile.Tasks {
+		if ids[yt.ID] || yt.ID == parent.ID {
+			return nil, fmt.Errorf("duplicate sub-task id %q", yt.ID)
+		}
+		ids[yt.ID] = true
+	}
+
+	tasks := make([]*taskstore.Task, 0, len(yamlFile.Tasks))
+	for _, yt := range yamlFile.Tasks {
+		t := convertYAMLTaskToTask(yt)
+		t.ParentID = &parent.ID
+		t.Status = taskstore.StatusOpen
+
+		var deps []string
+		for _, dep := range t.DependsOn {
+			if ids[dep] {
+				deps = append(deps, dep)
+			}
+		}
+		t.DependsOn = deps
+
+		if err := t.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid sub-task %q: %w", t.ID, err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
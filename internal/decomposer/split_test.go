@@ -0,0 +1,65 @@
+package decomposer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestSplitter_Split(t *testing.T) {
+	parent := &taskstore.Task{ID: "auth", Title: "Add auth", Status: taskstore.StatusFailed}
+
+	tests := []struct {
+		name    string
+		text    string
+		err     error
+		wantIDs []string
+		wantErr string
+	}{
+		{
+			name:    "attaches sub-tasks to the failing task",
+			text:    "```yaml\ntasks:\n  - id: auth-model\n    title: Add user model\n  - id: auth-login\n    title: Add login\n    dependsOn: [auth-model, elsewhere]\n    status: completed\n```",
+			wantIDs: []string{"auth-model", "auth-login"},
+		},
+		{name: "agent error", err: errors.New("boom"), wantErr: "claude execution failed"},
+		{name: "no YAML", text: "I cannot split this task.", wantErr: "no YAML content found"},
+		{name: "duplicate ids", text: "tasks:\n  - id: a\n    title: A\n  - id: a\n    title: B", wantErr: `duplicate sub-task id "a"`},
+		{name: "missing title", text: "tasks:\n  - id: a", wantErr: `invalid sub-task "a"`},
+		{
+			name:    "too many sub-tasks",
+			text:    "tasks:\n  - {id: a, title: A}\n  - {id: b, title: B}\n  - {id: c, title: C}\n  - {id: d, title: D}\n  - {id: e, title: E}\n  - {id: f, title: F}",
+			wantErr: "6 sub-tasks proposed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &mockRunner{response: &claude.ClaudeResponse{FinalText: tt.text, TotalCostUSD: 0.01}, err: tt.err}
+
+			result, err := NewSplitter(runner, t.TempDir(), "").Split(context.Background(), parent, "Verification failed")
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, 0.01, result.TotalCostUSD, 1e-9)
+			var ids []string
+			for _, task := range result.Tasks {
+				ids = append(ids, task.ID)
+				require.NotNil(t, task.ParentID)
+				assert.Equal(t, "auth", *task.ParentID)
+				assert.Equal(t, taskstore.StatusOpen, task.Status)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, []string{"auth-model"}, result.Tasks[1].DependsOn, "dependencies outside the split are dropped")
+		})
+	}
+}
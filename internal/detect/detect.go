@@ -2,6 +2,8 @@
 package detect
 
 import (
+	"encoding/json"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -14,6 +16,7 @@ const (
 	FileTypeUnknown FileType = iota
 	FileTypePRD
 	FileTypeTasks
+	FileTypeText
 )
 
 // String returns the string representation of the FileType.
@@ -23,6 +26,8 @@ func (ft FileType) String() string {
 		return "prd"
 	case FileTypeTasks:
 		return "tasks"
+	case FileTypeText:
+		return "text"
 	default:
 		return "unknown"
 	}
@@ -45,9 +50,29 @@ var taskPatterns = []string{
 	"depends_on:",
 }
 
+// DetectFile returns the file type for path, using its extension when it is
+// decisive (.txt is a plain-text spec, .json a task list) and falling back to
+// content detection otherwise.
+func DetectFile(path, content string) FileType {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt":
+		return FileTypeText
+	case ".json":
+		return FileTypeTasks
+	default:
+		return DetectFileType(content)
+	}
+}
+
 // FileType analyzes content and returns the detected file type.
-// It checks for PRD markers first, then task YAML patterns.
+// It checks for a JSON task list first, then PRD markers, then task YAML patterns.
 func DetectFileType(content string) FileType {
+	// Check for JSON task lists
+	trimmed := strings.TrimSpace(content)
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return FileTypeTasks
+	}
+
 	// Check for PRD patterns (markdown sections)
 	for _, pattern := range prdPatterns {
 		if pattern.MatchString(content) {
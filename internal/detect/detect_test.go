@@ -15,6 +15,7 @@ func TestFileType_String(t *testing.T) {
 		{"unknown", FileTypeUnknown, "unknown"},
 		{"prd", FileTypePRD, "prd"},
 		{"tasks", FileTypeTasks, "tasks"},
+		{"text", FileTypeText, "text"},
 	}
 
 	for _, tt := range tests {
@@ -125,9 +126,50 @@ func TestDetectFileType_Unknown(t *testing.T) {
 	}
 }
 
+func TestDetectFileType_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected FileType
+	}{
+		{"task object", `{"tasks": [{"id": "a", "title": "A"}]}`, FileTypeTasks},
+		{"task array", "\n[{\"id\": \"a\", \"title\": \"A\"}]\n", FileTypeTasks},
+		{"invalid JSON", `{"tasks": [`, FileTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectFileType(tt.content))
+		})
+	}
+}
+
+func TestDetectFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		expected FileType
+	}{
+		{"txt is plain text", "notes.txt", "## Requirements\n- id: a", FileTypeText},
+		{"txt is case insensitive", "NOTES.TXT", "", FileTypeText},
+		{"json is tasks", "spec.json", "", FileTypeTasks},
+		{"markdown falls back to content", "spec.md", "## Objectives\nShip it.", FileTypePRD},
+		{"yaml falls back to content", "tasks.yaml", "tasks:\n  - id: a", FileTypeTasks},
+		{"unknown content", "notes.md", "Just some text.", FileTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectFile(tt.path, tt.content))
+		})
+	}
+}
+
 func TestFileType_Constants(t *testing.T) {
 	// Verify constants have expected values
 	assert.Equal(t, FileType(0), FileTypeUnknown)
 	assert.Equal(t, FileType(1), FileTypePRD)
 	assert.Equal(t, FileType(2), FileTypeTasks)
+	assert.Equal(t, FileType(3), FileTypeText)
 }
@@ -0,0 +1,30 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// verifyMarkers maps project manifest files to their default verify command,
+// in the order they are checked.
+var verifyMarkers = []struct {
+	file    string
+	command []string
+}{
+	{"go.mod", []string{"go", "test", "./..."}},
+	{"package.json", []string{"npm", "test"}},
+	{"Cargo.toml", []string{"cargo", "test"}},
+}
+
+// DetectVerifyCommands returns default verify commands for the languages
+// detected in dir by the presence of go.mod, package.json, or Cargo.toml.
+// Returns nil if no known project manifest is found.
+func DetectVerifyCommands(dir string) [][]string {
+	var commands [][]string
+	for _, marker := range verifyMarkers {
+		if _, err := os.Stat(filepath.Join(dir, marker.file)); err == nil {
+			commands = append(commands, append([]string(nil), marker.command...))
+		}
+	}
+	return commands
+}
@@ -0,0 +1,35 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectVerifyCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected [][]string
+	}{
+		{"go project", []string{"go.mod"}, [][]string{{"go", "test", "./..."}}},
+		{"node project", []string{"package.json"}, [][]string{{"npm", "test"}}},
+		{"rust project", []string{"Cargo.toml"}, [][]string{{"cargo", "test"}}},
+		{"polyglot project", []string{"package.json", "go.mod"}, [][]string{{"go", "test", "./..."}, {"npm", "test"}}},
+		{"unknown project", []string{"README.md"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, f), []byte{}, 0644))
+			}
+
+			assert.Equal(t, tt.expected, DetectVerifyCommands(dir))
+		})
+	}
+}
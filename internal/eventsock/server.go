@@ -0,0 +1,186 @@
+// Package eventsock serves structured run events over a Unix domain socket.
+package eventsock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yarlson/ralph/internal/loop"
+)
+
+// clientBuffer is the number of events queued per client before it is
+// considered too slow and disconnected.
+const clientBuffer = 256
+
+// writeTimeout bounds how long a single write to a client may block.
+const writeTimeout = 5 * time.Second
+
+// ErrSocketInUse indicates another process is already serving on the socket path.
+var ErrSocketInUse = errors.New("event socket already in use")
+
+// Server broadcasts events as newline-delimited JSON to every connected client.
+// Clients that connect mid-run first receive the current state: the run_started
+// event and the latest iteration event, if any.
+type Server struct {
+	path     string
+	listener net.Listener
+	writers  sync.WaitGroup
+
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	run     []byte // latest run_started or run_finished event
+	current []byte // latest iteration event
+	closed  bool
+}
+
+type client struct {
+	conn   net.Conn
+	events chan []byte
+}
+
+// Listen starts serving events on the Unix socket at path. A stale socket file
+// left by a previous run is replaced; a socket with a live listener is not.
+func Listen(path string) (*Server, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%w: %s", ErrSocketInUse, path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on event socket: %w", err)
+	}
+
+	s := &Server{
+		path:     path,
+		listener: listener,
+		clients:  make(map[*client]struct{}),
+	}
+	go s.accept()
+
+	return s, nil
+}
+
+// Emit broadcasts event to all connected clients and records it as current state.
+func (s *Server) Emit(event loop.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	switch event.Type {
+	case loop.EventRunStarted, loop.EventRunFinished:
+		s.run = data
+		s.current = nil
+	case loop.EventClaudeInvoked, loop.EventVerificationResult, loop.EventCommitted:
+		// Steps within an iteration are only broadcast
+	default:
+		s.current = data
+	}
+
+	for c := range s.clients {
+		s.send(c, data)
+	}
+}
+
+// Close stops accepting clients, flushes queued events to existing clients,
+// disconnects them, and removes the socket file.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	for c := range s.clients {
+		s.drop(c)
+	}
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	s.writers.Wait()
+	if rmErr := os.Remove(s.path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// accept registers new clients until the listener is closed.
+func (s *Server) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		c := &client{conn: conn, events: make(chan []byte, clientBuffer)}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.clients[c] = struct{}{}
+		s.writers.Add(1)
+		for _, data := range [][]byte{s.run, s.current} {
+			if data != nil {
+				s.send(c, data)
+			}
+		}
+		s.mu.Unlock()
+
+		go s.write(c)
+	}
+}
+
+// write delivers queued events to the client until it disconnects or is dropped.
+func (s *Server) write(c *client) {
+	defer s.writers.Done()
+	for data := range c.events {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err := c.conn.Write(data); err != nil {
+			s.mu.Lock()
+			s.drop(c)
+			s.mu.Unlock()
+		}
+	}
+	_ = c.conn.Close()
+}
+
+// send queues data for c, dropping clients that cannot keep up.
+// The caller must hold s.mu.
+func (s *Server) send(c *client, data []byte) {
+	select {
+	case c.events <- data:
+	default:
+		s.drop(c)
+	}
+}
+
+// drop disconnects c. The caller must hold s.mu.
+func (s *Server) drop(c *client) {
+	if _, ok := s.clients[c]; !ok {
+		return
+	}
+	delete(s.clients, c)
+	close(c.events)
+}
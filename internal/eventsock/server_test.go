@@ -0,0 +1,131 @@
+package eventsock
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/loop"
+)
+
+// socketPath returns a short socket path; t.TempDir can exceed the Unix socket path limit.
+func socketPath(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "ralph")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "events.sock")
+}
+
+func dial(t *testing.T, path string) *bufio.Scanner {
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	return bufio.NewScanner(conn)
+}
+
+func readEvent(t *testing.T, scanner *bufio.Scanner) loop.Event {
+	require.True(t, scanner.Scan(), "expected an event: %v", scanner.Err())
+	var event loop.Event
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+	return event
+}
+
+// waitForClients blocks until the server has registered n clients.
+func waitForClients(t *testing.T, s *Server, n int) {
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.clients) == n
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestServer_BroadcastsToClients(t *testing.T) {
+	path := socketPath(t)
+	s, err := Listen(path)
+	require.NoError(t, err)
+	defer func() { _ = s.Close() }()
+
+	first := dial(t, path)
+	second := dial(t, path)
+	waitForClients(t, s, 2)
+
+	s.Emit(loop.Event{Type: loop.EventIterationStarted, TaskID: "task-1"})
+
+	for _, scanner := range []*bufio.Scanner{first, second} {
+		event := readEvent(t, scanner)
+		assert.Equal(t, loop.EventIterationStarted, event.Type)
+		assert.Equal(t, "task-1", event.TaskID)
+	}
+}
+
+func TestServer_LateClientGetsCurrentState(t *testing.T) {
+	path := socketPath(t)
+	s, err := Listen(path)
+	require.NoError(t, err)
+	defer func() { _ = s.Close() }()
+
+	s.Emit(loop.Event{Type: loop.EventRunStarted, ParentTaskID: "parent"})
+	s.Emit(loop.Event{Type: loop.EventIterationStarted, TaskID: "task-1"})
+	s.Emit(loop.Event{Type: loop.EventIterationFinished, TaskID: "task-1", Outcome: "success"})
+	s.Emit(loop.Event{Type: loop.EventClaudeInvoked, TaskID: "task-2"})
+
+	scanner := dial(t, path)
+	waitForClients(t, s, 1)
+
+	run := readEvent(t, scanner)
+	assert.Equal(t, loop.EventRunStarted, run.Type)
+	assert.Equal(t, "parent", run.ParentTaskID)
+
+	current := readEvent(t, scanner)
+	assert.Equal(t, loop.EventIterationFinished, current.Type)
+	assert.Equal(t, "success", current.Outcome, "steps within an iteration are not replayed")
+
+	// Then live updates
+	s.Emit(loop.Event{Type: loop.EventIterationStarted, TaskID: "task-2"})
+	assert.Equal(t, "task-2", readEvent(t, scanner).TaskID)
+}
+
+func TestServer_CloseFlushesAndRemovesSocket(t *testing.T) {
+	path := socketPath(t)
+	s, err := Listen(path)
+	require.NoError(t, err)
+
+	scanner := dial(t, path)
+	waitForClients(t, s, 1)
+
+	s.Emit(loop.Event{Type: loop.EventRunFinished, Outcome: "completed"})
+	require.NoError(t, s.Close())
+
+	assert.Equal(t, loop.EventRunFinished, readEvent(t, scanner).Type)
+	assert.False(t, scanner.Scan(), "connection should be closed")
+	assert.NoFileExists(t, path)
+}
+
+func TestListen(t *testing.T) {
+	t.Run("replaces a stale socket file", func(t *testing.T) {
+		path := socketPath(t)
+		require.NoError(t, os.WriteFile(path, nil, 0600))
+
+		s, err := Listen(path)
+		require.NoError(t, err)
+		require.NoError(t, s.Close())
+	})
+
+	t.Run("refuses a socket in use", func(t *testing.T) {
+		path := socketPath(t)
+		s, err := Listen(path)
+		require.NoError(t, err)
+		defer func() { _ = s.Close() }()
+
+		_, err = Listen(path)
+		require.ErrorIs(t, err, ErrSocketInUse)
+	})
+}
@@ -10,6 +10,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/yarlson/ralph/internal/git"
 	"github.com/yarlson/ralph/internal/loop"
@@ -34,23 +36,28 @@ type Iteration struct {
 
 // UndoInfo contains information for undo confirmation.
 type UndoInfo struct {
-	IterationID           string
+	// IterationIDs are the iterations to undo, oldest first.
+	IterationIDs          []string
 	CommitToResetTo       string
-	TaskToReopen          string
+	TasksToReopen         []string
 	FilesToRevert         []string
 	HasUncommittedChanges bool
+
+	// CompletedDependents lists completed tasks that depend, directly or
+	// transitively, on TasksToReopen.
+	CompletedDependents []string
 }
 
 // Service provides fix operations.
 type Service struct {
-	store    *taskstore.LocalStore
+	store    taskstore.Store
 	logsDir  string
 	stateDir string
 	workDir  string
 }
 
 // NewService creates a new fix service.
-func NewService(store *taskstore.LocalStore, logsDir, stateDir, workDir string) *Service {
+func NewService(store taskstore.Store, logsDir, stateDir, workDir string) *Service {
 	return &Service{
 		store:    store,
 		logsDir:  logsDir,
@@ -59,7 +66,7 @@ func NewService(store *taskstore.LocalStore, logsDir, stateDir, workDir string)
 	}
 }
 
-// Retry resets a failed task to open status.
+// Retry resets a failed or blocked task to open status.
 func (s *Service) Retry(taskID, feedback string) error {
 	task, err := s.store.Get(taskID)
 	if err != nil {
@@ -71,14 +78,14 @@ func (s *Service) Retry(taskID, feedback string) error {
 	}
 
 	switch task.Status {
-	case taskstore.StatusFailed:
+	case taskstore.StatusFailed, taskstore.StatusBlocked, taskstore.StatusAwaitingReview:
 		// OK to retry
 	case taskstore.StatusOpen:
 		return nil // Already open, no-op
 	case taskstore.StatusCompleted:
 		return fmt.Errorf("cannot retry task %q: task is completed", taskID)
 	default:
-		return fmt.Errorf("cannot retry task %q: task status is %q (must be failed or open)", taskID, task.Status)
+		return fmt.Errorf("cannot retry task %q: task status is %q (must be failed, blocked, awaiting_review, or open)", taskID, task.Status)
 	}
 
 	if err := s.store.UpdateStatus(taskID, taskstore.StatusOpen); err != nil {
@@ -95,7 +102,7 @@ func (s *Service) Retry(taskID, feedback string) error {
 		}
 	}
 
-	return nil
+	return s.recordIntervention(taskID, state.InterventionRetry, task.Status, taskstore.StatusOpen, feedback != "")
 }
 
 // IsAlreadyOpen returns true if retry was a no-op because task is already open.
@@ -107,6 +114,48 @@ func (s *Service) IsAlreadyOpen(taskID string) (bool, error) {
 	return task.Status == taskstore.StatusOpen, nil
 }
 
+// RetryOutcome is what RetryAllFailed did with one failed task.
+type RetryOutcome struct {
+	TaskID string
+
+	// Skipped explains why the task was left alone ("" = reset to open).
+	Skipped string
+}
+
+// RetryAllFailed resets every failed task to open, writing feedback (if
+// any) for each as Retry does. A task that is no longer failed when its turn
+// comes, for example because a running loop completed or picked it up, is
+// skipped with the reason instead of stopping the others.
+func (s *Service) RetryAllFailed(feedback string) ([]RetryOutcome, error) {
+	tasks, err := s.store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	var outcomes []RetryOutcome
+	for _, t := range tasks {
+		if t.Status != taskstore.StatusFailed {
+			continue
+		}
+		outcome := RetryOutcome{TaskID: t.ID}
+		current, err := s.store.Get(t.ID)
+		switch {
+		case err != nil:
+			outcome.Skipped = fmt.Sprintf("could not be read: %v", err)
+		case current.Status == taskstore.StatusCompleted || current.Status == taskstore.StatusInProgress:
+			outcome.Skipped = fmt.Sprintf("task is now %s", current.Status)
+		case current.Status != taskstore.StatusFailed:
+			outcome.Skipped = fmt.Sprintf("task is now %s, not failed", current.Status)
+		default:
+			if err := s.Retry(t.ID, feedback); err != nil {
+				return outcomes, err
+			}
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes, nil
+}
+
 // Skip marks a task as skipped.
 func (s *Service) Skip(taskID, reason string) error {
 	task, err := s.store.Get(taskID)
@@ -143,6 +192,49 @@ func (s *Service) Skip(taskID, reason string) error {
 		}
 	}
 
+	return s.recordIntervention(taskID, state.InterventionSkip, task.Status, taskstore.StatusSkipped, reason != "")
+}
+
+// Approve marks a task that is awaiting review as completed.
+func (s *Service) Approve(taskID string) error {
+	task, err := s.store.Get(taskID)
+	if err != nil {
+		var notFoundErr *taskstore.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			return fmt.Errorf("task %q not found", taskID)
+		}
+		return fmt.Errorf("failed to get task: %w", err)
+	}
+
+	switch task.Status {
+	case taskstore.StatusAwaitingReview:
+		// OK to approve
+	case taskstore.StatusCompleted:
+		return nil // Already completed, no-op
+	default:
+		return fmt.Errorf("cannot approve task %q: task status is %q (must be awaiting_review)", taskID, task.Status)
+	}
+
+	if err := s.store.UpdateStatus(taskID, taskstore.StatusCompleted); err != nil {
+		return fmt.Errorf("failed to update task status: %w", err)
+	}
+	return s.recordIntervention(taskID, state.InterventionApprove, task.Status, taskstore.StatusCompleted, false)
+}
+
+// recordIntervention logs a manual status change so reports can tell which
+// tasks needed a person's help.
+func (s *Service) recordIntervention(taskID, action string, from, to taskstore.TaskStatus, feedback bool) error {
+	err := state.AppendIntervention(filepath.Join(s.stateDir, state.Interventions), state.Intervention{
+		TaskID:   taskID,
+		Action:   action,
+		From:     string(from),
+		To:       string(to),
+		Time:     time.Now(),
+		Feedback: feedback,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record intervention: %w", err)
+	}
 	return nil
 }
 
@@ -213,78 +305,258 @@ func (s *Service) ListIterations(limit int) ([]Iteration, error) {
 	return result, nil
 }
 
-// GetUndoInfo returns information needed for undo confirmation.
-func (s *Service) GetUndoInfo(ctx context.Context, iterationID string) (*UndoInfo, error) {
-	iterationFile := filepath.Join(s.logsDir, fmt.Sprintf("iteration-%s.json", iterationID))
-
-	if _, err := os.Stat(iterationFile); os.IsNotExist(err) {
-		return nil, errors.New("iteration not found")
+// GetUndoInfo returns information needed to confirm undoing the given
+// iterations.
+func (s *Service) GetUndoInfo(ctx context.Context, iterationIDs ...string) (*UndoInfo, error) {
+	records, err := s.loadUndoRecords(iterationIDs)
+	if err != nil {
+		return nil, err
 	}
 
-	record, err := loop.LoadRecord(iterationFile)
+	gitManager := git.NewShellManager(s.workDir, "")
+	hasChanges, _ := gitManager.HasChanges(ctx)
+
+	tasks := s.tasksToReopen(records)
+	dependents, err := s.dependentsOf(tasks)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load iteration record: %w", err)
+		return nil, err
+	}
+
+	info := &UndoInfo{
+		CommitToResetTo:       records[0].BaseCommit,
+		TasksToReopen:         tasks,
+		HasUncommittedChanges: hasChanges,
+		CompletedDependents:   dependents,
+	}
+	seen := make(map[string]bool)
+	for _, record := range records {
+		info.IterationIDs = append(info.IterationIDs, record.IterationID)
+		for _, file := range record.FilesChanged {
+			if !seen[file] {
+				seen[file] = true
+				info.FilesToRevert = append(info.FilesToRevert, file)
+			}
+		}
 	}
+	return info, nil
+}
 
-	if record.BaseCommit == "" {
-		return nil, fmt.Errorf("iteration %q has no base commit recorded", iterationID)
+// RecentIterations returns the IDs of the n most recent successful
+// iterations that can be undone, most recent first.
+func (s *Service) RecentIterations(n int) ([]string, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid count %d: must be at least 1", n)
+	}
+	records, err := loop.LoadAllIterationRecords(s.logsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load iteration records: %w", err)
 	}
 
-	gitManager := git.NewShellManager(s.workDir, "")
-	hasChanges, _ := gitManager.HasChanges(ctx)
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].EndTime.After(records[j].EndTime)
+	})
 
-	taskToReopen := ""
-	if record.Outcome == loop.OutcomeSuccess && record.TaskID != "" {
-		task, err := s.store.Get(record.TaskID)
-		if err == nil && task.Status == taskstore.StatusCompleted {
-			taskToReopen = record.TaskID
+	var ids []string
+	for _, record := range records {
+		if record.Outcome == loop.OutcomeSuccess && record.BaseCommit != "" {
+			ids = append(ids, record.IterationID)
+		}
+		if len(ids) == n {
+			return ids, nil
 		}
 	}
-
-	return &UndoInfo{
-		IterationID:           iterationID,
-		CommitToResetTo:       record.BaseCommit,
-		TaskToReopen:          taskToReopen,
-		FilesToRevert:         record.FilesChanged,
-		HasUncommittedChanges: hasChanges,
-	}, nil
+	return nil, fmt.Errorf("only %d successful iteration(s) can be undone", len(ids))
 }
 
 // Undo reverts an iteration.
 func (s *Service) Undo(iterationID string) error {
-	iterationFile := filepath.Join(s.logsDir, fmt.Sprintf("iteration-%s.json", iterationID))
+	_, err := s.UndoWithCascade([]string{iterationID}, false)
+	return err
+}
+
+// UndoWithCascade reverts the given iterations together, resetting to the
+// earliest base commit among them and reopening every task they completed.
+// When cascade is set, completed tasks that depend on the reopened tasks are
+// reopened too, keeping the task graph consistent with the reverted git
+// state. Returns the reopened dependents.
+func (s *Service) UndoWithCascade(iterationIDs []string, cascade bool) ([]string, error) {
+	records, err := s.loadUndoRecords(iterationIDs)
+	if err != nil {
+		return nil, err
+	}
+	tasks := s.tasksToReopen(records)
+
+	// Git reset
+	cmd := exec.Command("git", "reset", "--hard", records[0].BaseCommit)
+	cmd.Dir = s.workDir
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git reset failed: %w", err)
+	}
+
+	// Reopen the tasks the iterations completed
+	for _, id := range tasks {
+		if err := s.store.UpdateStatus(id, taskstore.StatusOpen); err != nil {
+			return nil, fmt.Errorf("failed to update task status: %w", err)
+		}
+		if err := s.recordIntervention(id, state.InterventionUndo, taskstore.StatusCompleted, taskstore.StatusOpen, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if !cascade {
+		return nil, nil
+	}
 
-	if _, err := os.Stat(iterationFile); os.IsNotExist(err) {
-		return errors.New("iteration not found")
+	dependents, err := s.dependentsOf(tasks)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range dependents {
+		if err := s.store.UpdateStatus(id, taskstore.StatusOpen); err != nil {
+			return nil, fmt.Errorf("failed to reopen dependent task %s: %w", id, err)
+		}
+		if err := s.recordIntervention(id, state.InterventionUndo, taskstore.StatusCompleted, taskstore.StatusOpen, false); err != nil {
+			return nil, err
+		}
 	}
 
-	record, err := loop.LoadRecord(iterationFile)
+	return dependents, nil
+}
+
+// UndoWithStash undoes iterations like UndoWithCascade, but stashes
+// uncommitted changes outside the .ralph directory before the reset and
+// restores them afterwards. If they no longer apply cleanly, the undo still
+// stands, the stash entry is kept and the error wraps git.ErrStashConflict.
+func (s *Service) UndoWithStash(ctx context.Context, iterationIDs []string, cascade bool) ([]string, error) {
+	gitManager := git.NewShellManager(s.workDir, "")
+	message := "ralph: uncommitted changes before undo of " + strings.Join(iterationIDs, ", ")
+	stash, err := gitManager.PushStash(ctx, message, []string{state.RalphDir})
 	if err != nil {
-		return fmt.Errorf("failed to load iteration record: %w", err)
+		return nil, fmt.Errorf("failed to stash uncommitted changes: %w", err)
 	}
 
-	if record.BaseCommit == "" {
-		return fmt.Errorf("iteration %q has no base commit recorded", iterationID)
+	reopened, undoErr := s.UndoWithCascade(iterationIDs, cascade)
+	if stash == "" {
+		return reopened, undoErr
+	}
+	if err := gitManager.PopStash(context.WithoutCancel(ctx), stash); err != nil {
+		err = fmt.Errorf("uncommitted changes were not restored and are kept in the stash (%s): %w", stash[:7], err)
+		return reopened, errors.Join(undoErr, err)
 	}
+	return reopened, undoErr
+}
 
-	// Git reset
-	cmd := exec.Command("git", "reset", "--hard", record.BaseCommit)
-	cmd.Dir = s.workDir
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("git reset failed: %w", err)
+// loadUndoRecords loads the records of the iterations to undo, oldest
+// first. Every iteration must exist and have a base commit.
+func (s *Service) loadUndoRecords(iterationIDs []string) ([]*loop.IterationRecord, error) {
+	if len(iterationIDs) == 0 {
+		return nil, errors.New("no iterations to undo")
+	}
+
+	var records []*loop.IterationRecord
+	seen := make(map[string]bool)
+	for _, id := range iterationIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		iterationFile := filepath.Join(s.logsDir, fmt.Sprintf("iteration-%s.json", id))
+		if _, err := os.Stat(iterationFile); os.IsNotExist(err) {
+			return nil, fmt.Errorf("iteration not found: %s", id)
+		}
+		record, err := loop.LoadRecord(iterationFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load iteration record: %w", err)
+		}
+		if record.BaseCommit == "" {
+			return nil, fmt.Errorf("iteration %q has no base commit recorded", id)
+		}
+		records = append(records, record)
 	}
 
-	// Reopen task if it was completed
-	if record.Outcome == loop.OutcomeSuccess && record.TaskID != "" {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].StartTime.Before(records[j].StartTime)
+	})
+	return records, nil
+}
+
+// tasksToReopen returns the tasks completed by the successful iterations
+// among records that are still completed.
+func (s *Service) tasksToReopen(records []*loop.IterationRecord) []string {
+	var tasks []string
+	seen := make(map[string]bool)
+	for _, record := range records {
+		if record.Outcome != loop.OutcomeSuccess || record.TaskID == "" || seen[record.TaskID] {
+			continue
+		}
+		seen[record.TaskID] = true
 		task, err := s.store.Get(record.TaskID)
 		if err == nil && task.Status == taskstore.StatusCompleted {
-			if err := s.store.UpdateStatus(record.TaskID, taskstore.StatusOpen); err != nil {
-				return fmt.Errorf("failed to update task status: %w", err)
+			tasks = append(tasks, record.TaskID)
+		}
+	}
+	return tasks
+}
+
+// dependentsOf returns the completed tasks, other than taskIDs themselves,
+// that depend directly or transitively on any of taskIDs.
+func (s *Service) dependentsOf(taskIDs []string) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, id := range taskIDs {
+		seen[id] = true
+	}
+
+	var dependents []string
+	for _, id := range taskIDs {
+		ids, err := s.completedDependents(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range ids {
+			if !seen[dep] {
+				seen[dep] = true
+				dependents = append(dependents, dep)
 			}
 		}
 	}
+	return dependents, nil
+}
 
-	return nil
+// completedDependents returns the completed tasks that depend on taskID,
+// directly or transitively, in breadth-first order.
+func (s *Service) completedDependents(taskID string) ([]string, error) {
+	tasks, err := s.store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	dependentsOf := make(map[string][]*taskstore.Task)
+	for _, t := range tasks {
+		for _, dep := range t.DependsOn {
+			dependentsOf[dep] = append(dependentsOf[dep], t)
+		}
+	}
+
+	var result []string
+	visited := map[string]bool{taskID: true}
+	queue := []string{taskID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, t := range dependentsOf[current] {
+			if visited[t.ID] {
+				continue
+			}
+			visited[t.ID] = true
+			queue = append(queue, t.ID)
+			if t.Status == taskstore.StatusCompleted {
+				result = append(result, t.ID)
+			}
+		}
+	}
+
+	return result, nil
 }
 
 func countTaskAttempts(iterations []*loop.IterationRecord, taskID string) int {
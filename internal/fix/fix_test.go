@@ -1,14 +1,20 @@
 package fix
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yarlson/ralph/internal/git"
 
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
 	"github.com/yarlson/ralph/internal/taskstore"
 )
 
@@ -41,6 +47,51 @@ func TestService_Retry(t *testing.T) {
 		assert.Equal(t, taskstore.StatusOpen, updated.Status)
 	})
 
+	t.Run("records the retry as an intervention", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		stateDir := state.StateDirPath(tmpDir)
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, "tasks"))
+		require.NoError(t, err)
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID:        "task-1",
+			Title:     "Test",
+			Status:    taskstore.StatusFailed,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}))
+
+		svc := NewService(store, filepath.Join(tmpDir, "logs"), stateDir, tmpDir)
+		require.NoError(t, svc.Retry("task-1", "use the existing helper"))
+
+		interventions, err := state.LoadInterventions(filepath.Join(stateDir, state.Interventions))
+		require.NoError(t, err)
+		require.Len(t, interventions, 1)
+		assert.Equal(t, "task-1", interventions[0].TaskID)
+		assert.Equal(t, state.InterventionRetry, interventions[0].Action)
+		assert.Equal(t, "failed", interventions[0].From)
+		assert.Equal(t, "open", interventions[0].To)
+		assert.True(t, interventions[0].Feedback)
+	})
+
+	t.Run("retries blocked task", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, "tasks"))
+		require.NoError(t, err)
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID:        "task-1",
+			Title:     "Test",
+			Status:    taskstore.StatusBlocked,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}))
+
+		svc := NewService(store, filepath.Join(tmpDir, "logs"), filepath.Join(tmpDir, "state"), tmpDir)
+		require.NoError(t, svc.Retry("task-1", ""))
+
+		updated, _ := store.Get("task-1")
+		assert.Equal(t, taskstore.StatusOpen, updated.Status)
+	})
+
 	t.Run("no-op for open task", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		tasksDir := filepath.Join(tmpDir, "tasks")
@@ -91,6 +142,53 @@ func TestService_Retry(t *testing.T) {
 	})
 }
 
+func TestService_RetryAllFailed(t *testing.T) {
+	tmpDir := t.TempDir()
+	stateDir := filepath.Join(tmpDir, "state")
+	require.NoError(t, os.MkdirAll(stateDir, 0755))
+	store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, "tasks"))
+	require.NoError(t, err)
+	for id, status := range map[string]taskstore.TaskStatus{
+		"task-b": taskstore.StatusFailed,
+		"task-a": taskstore.StatusFailed,
+		"task-c": taskstore.StatusCompleted,
+		"task-d": taskstore.StatusInProgress,
+	} {
+		require.NoError(t, store.Save(&taskstore.Task{
+			ID:        id,
+			Title:     "Test " + id,
+			Status:    status,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}))
+	}
+
+	svc := NewService(store, filepath.Join(tmpDir, "logs"), stateDir, tmpDir)
+	outcomes, err := svc.RetryAllFailed("try a smaller change")
+	require.NoError(t, err)
+	assert.Equal(t, []RetryOutcome{{TaskID: "task-a"}, {TaskID: "task-b"}}, outcomes)
+
+	for id, want := range map[string]taskstore.TaskStatus{
+		"task-a": taskstore.StatusOpen,
+		"task-b": taskstore.StatusOpen,
+		"task-c": taskstore.StatusCompleted,
+		"task-d": taskstore.StatusInProgress,
+	} {
+		task, err := store.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, want, task.Status, id)
+	}
+	for _, id := range []string{"task-a", "task-b"} {
+		data, err := os.ReadFile(filepath.Join(stateDir, "feedback-"+id+".txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "try a smaller change", string(data))
+	}
+
+	outcomes, err = svc.RetryAllFailed("")
+	require.NoError(t, err)
+	assert.Empty(t, outcomes)
+}
+
 func TestService_Skip(t *testing.T) {
 	t.Run("skips open task", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -146,6 +244,301 @@ func TestService_Skip(t *testing.T) {
 	})
 }
 
+func TestService_Approve(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     taskstore.TaskStatus
+		wantStatus taskstore.TaskStatus
+		wantErr    string
+	}{
+		{name: "completes a task awaiting review", status: taskstore.StatusAwaitingReview, wantStatus: taskstore.StatusCompleted},
+		{name: "completed task is a no-op", status: taskstore.StatusCompleted, wantStatus: taskstore.StatusCompleted},
+		{name: "errors for an open task", status: taskstore.StatusOpen, wantStatus: taskstore.StatusOpen, wantErr: "must be awaiting_review"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, "tasks"))
+			require.NoError(t, err)
+			require.NoError(t, store.Save(&taskstore.Task{
+				ID:        "task-1",
+				Title:     "Test",
+				Status:    tt.status,
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}))
+
+			svc := NewService(store, filepath.Join(tmpDir, "logs"), filepath.Join(tmpDir, "state"), tmpDir)
+			err = svc.Approve("task-1")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			updated, _ := store.Get("task-1")
+			assert.Equal(t, tt.wantStatus, updated.Status)
+		})
+	}
+}
+
+func TestService_UndoWithCascade(t *testing.T) {
+	// setup creates a git repo with two commits, tasks where b and c depend on a
+	// (c transitively via b), and an iteration record for a's commit.
+	setup := func(t *testing.T) (*Service, *taskstore.LocalStore) {
+		tmpDir := t.TempDir()
+		runGit := func(args ...string) string {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = tmpDir
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+			return string(out)
+		}
+		runGit("init", "-b", "main")
+		runGit("config", "user.email", "test@example.com")
+		runGit("config", "user.name", "Test User")
+		runGit("config", "commit.gpgsign", "false")
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("base"), 0644))
+		runGit("add", "-A")
+		runGit("commit", "-m", "base")
+		base := runGit("rev-parse", "HEAD")
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.txt"), []byte("task a"), 0644))
+		runGit("add", "-A")
+		runGit("commit", "-m", "task a")
+
+		logsDir := filepath.Join(tmpDir, "logs")
+		stateDir := filepath.Join(tmpDir, "state")
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, "tasks"))
+		require.NoError(t, err)
+
+		now := time.Now()
+		for _, task := range []*taskstore.Task{
+			{ID: "a", Title: "A", Status: taskstore.StatusCompleted},
+			{ID: "b", Title: "B", Status: taskstore.StatusCompleted, DependsOn: []string{"a"}},
+			{ID: "c", Title: "C", Status: taskstore.StatusCompleted, DependsOn: []string{"b"}},
+			{ID: "d", Title: "D", Status: taskstore.StatusOpen, DependsOn: []string{"a"}},
+			{ID: "e", Title: "E", Status: taskstore.StatusCompleted},
+		} {
+			task.CreatedAt, task.UpdatedAt = now, now
+			require.NoError(t, store.Save(task))
+		}
+
+		_, err = loop.SaveRecord(logsDir, &loop.IterationRecord{
+			IterationID: "iter-a",
+			TaskID:      "a",
+			Outcome:     loop.OutcomeSuccess,
+			BaseCommit:  strings.TrimSpace(base),
+		})
+		require.NoError(t, err)
+
+		return NewService(store, logsDir, stateDir, tmpDir), store
+	}
+
+	statusOf := func(t *testing.T, store *taskstore.LocalStore, id string) taskstore.TaskStatus {
+		task, err := store.Get(id)
+		require.NoError(t, err)
+		return task.Status
+	}
+
+	t.Run("undo info lists completed dependents", func(t *testing.T) {
+		svc, _ := setup(t)
+
+		info, err := svc.GetUndoInfo(context.Background(), "iter-a")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, info.TasksToReopen)
+		assert.Equal(t, []string{"b", "c"}, info.CompletedDependents)
+	})
+
+	t.Run("without cascade dependents stay completed", func(t *testing.T) {
+		svc, store := setup(t)
+
+		reopened, err := svc.UndoWithCascade([]string{"iter-a"}, false)
+		require.NoError(t, err)
+		assert.Empty(t, reopened)
+		assert.Equal(t, taskstore.StatusOpen, statusOf(t, store, "a"))
+		assert.Equal(t, taskstore.StatusCompleted, statusOf(t, store, "b"))
+	})
+
+	t.Run("cascade reopens completed dependents", func(t *testing.T) {
+		svc, store := setup(t)
+
+		reopened, err := svc.UndoWithCascade([]string{"iter-a"}, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "c"}, reopened)
+		for _, id := range []string{"a", "b", "c", "d"} {
+			assert.Equal(t, taskstore.StatusOpen, statusOf(t, store, id), id)
+		}
+		assert.Equal(t, taskstore.StatusCompleted, statusOf(t, store, "e"))
+	})
+
+	// keepUntracked keeps the task store, logs and state out of stashes, as
+	// they would be under .ralph.
+	keepUntracked := func(t *testing.T, svc *Service) {
+		exclude := filepath.Join(svc.workDir, ".git", "info", "exclude")
+		require.NoError(t, os.WriteFile(exclude, []byte("tasks/\nlogs/\nstate/\n"), 0644))
+	}
+
+	t.Run("stash restores uncommitted changes", func(t *testing.T) {
+		svc, store := setup(t)
+		keepUntracked(t, svc)
+		require.NoError(t, os.WriteFile(filepath.Join(svc.workDir, "notes.txt"), []byte("wip"), 0644))
+
+		_, err := svc.UndoWithStash(context.Background(), []string{"iter-a"}, false)
+		require.NoError(t, err)
+		assert.Equal(t, taskstore.StatusOpen, statusOf(t, store, "a"))
+
+		content, err := os.ReadFile(filepath.Join(svc.workDir, "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "base", string(content))
+		content, err = os.ReadFile(filepath.Join(svc.workDir, "notes.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "wip", string(content))
+		out, err := exec.Command("git", "-C", svc.workDir, "stash", "list").Output()
+		require.NoError(t, err)
+		assert.Empty(t, strings.TrimSpace(string(out)))
+	})
+
+	t.Run("stash with nothing to stash leaves older stashes alone", func(t *testing.T) {
+		svc, _ := setup(t)
+		keepUntracked(t, svc)
+		require.NoError(t, os.WriteFile(filepath.Join(svc.workDir, "old.txt"), []byte("old"), 0644))
+		out, err := exec.Command("git", "-C", svc.workDir, "stash", "push", "--include-untracked").CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		_, err = svc.UndoWithStash(context.Background(), []string{"iter-a"}, false)
+		require.NoError(t, err)
+		assert.NoFileExists(t, filepath.Join(svc.workDir, "old.txt"))
+		out, err = exec.Command("git", "-C", svc.workDir, "stash", "list").Output()
+		require.NoError(t, err)
+		assert.Len(t, strings.Split(strings.TrimSpace(string(out)), "\n"), 1)
+	})
+
+	t.Run("conflicting changes stay stashed", func(t *testing.T) {
+		svc, store := setup(t)
+		keepUntracked(t, svc)
+		require.NoError(t, os.WriteFile(filepath.Join(svc.workDir, "a.txt"), []byte("task a, edited"), 0644))
+
+		_, err := svc.UndoWithStash(context.Background(), []string{"iter-a"}, false)
+		require.ErrorIs(t, err, git.ErrStashConflict)
+		assert.Contains(t, err.Error(), "kept in the stash")
+		assert.Equal(t, taskstore.StatusOpen, statusOf(t, store, "a"), "the undo still stands")
+
+		out, err := exec.Command("git", "-C", svc.workDir, "stash", "list").Output()
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "before undo of iter-a")
+	})
+}
+
+func TestService_UndoMultiple(t *testing.T) {
+	// setup creates a git repo with a base commit and one commit per task,
+	// each with a successful iteration record, and task d depending on c.
+	setup := func(t *testing.T) (*Service, *taskstore.LocalStore, string) {
+		tmpDir := t.TempDir()
+		runGit := func(args ...string) string {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = tmpDir
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+			return strings.TrimSpace(string(out))
+		}
+		runGit("init", "-b", "main")
+		runGit("config", "user.email", "test@example.com")
+		runGit("config", "user.name", "Test User")
+		runGit("config", "commit.gpgsign", "false")
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("tasks/\nlogs/\nstate/\n"), 0644))
+		runGit("add", "-A")
+		runGit("commit", "-m", "base")
+		base := runGit("rev-parse", "HEAD")
+
+		logsDir := filepath.Join(tmpDir, "logs")
+		store, err := taskstore.NewLocalStore(filepath.Join(tmpDir, "tasks"))
+		require.NoError(t, err)
+
+		start := time.Now().Add(-time.Hour)
+		for i, id := range []string{"a", "b", "c"} {
+			commit := runGit("rev-parse", "HEAD")
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, id+".txt"), []byte(id), 0644))
+			runGit("add", "-A")
+			runGit("commit", "-m", "task "+id)
+
+			require.NoError(t, store.Save(&taskstore.Task{ID: id, Title: id, Status: taskstore.StatusCompleted, CreatedAt: start, UpdatedAt: start}))
+			_, err := loop.SaveRecord(logsDir, &loop.IterationRecord{
+				IterationID:  "iter-" + id,
+				TaskID:       id,
+				Outcome:      loop.OutcomeSuccess,
+				BaseCommit:   commit,
+				FilesChanged: []string{id + ".txt", "shared.go"},
+				StartTime:    start.Add(time.Duration(i) * time.Minute),
+				EndTime:      start.Add(time.Duration(i)*time.Minute + time.Second),
+			})
+			require.NoError(t, err)
+		}
+		require.NoError(t, store.Save(&taskstore.Task{ID: "d", Title: "d", Status: taskstore.StatusCompleted, DependsOn: []string{"c"}, CreatedAt: start, UpdatedAt: start}))
+		_, err = loop.SaveRecord(logsDir, &loop.IterationRecord{
+			IterationID: "iter-failed",
+			TaskID:      "d",
+			Outcome:     loop.OutcomeFailed,
+			BaseCommit:  base,
+			StartTime:   start.Add(10 * time.Minute),
+			EndTime:     start.Add(11 * time.Minute),
+		})
+		require.NoError(t, err)
+
+		return NewService(store, logsDir, filepath.Join(tmpDir, "state"), tmpDir), store, base
+	}
+
+	t.Run("recent iterations skips unsuccessful ones", func(t *testing.T) {
+		svc, _, _ := setup(t)
+
+		ids, err := svc.RecentIterations(2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"iter-c", "iter-b"}, ids)
+
+		_, err = svc.RecentIterations(4)
+		assert.ErrorContains(t, err, "only 3 successful iteration(s)")
+	})
+
+	t.Run("undo info combines the iterations", func(t *testing.T) {
+		svc, _, _ := setup(t)
+
+		info, err := svc.GetUndoInfo(context.Background(), "iter-c", "iter-b")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"iter-b", "iter-c"}, info.IterationIDs, "oldest first")
+		assert.Equal(t, []string{"b", "c"}, info.TasksToReopen)
+		assert.Equal(t, []string{"b.txt", "shared.go", "c.txt"}, info.FilesToRevert)
+		assert.Equal(t, []string{"d"}, info.CompletedDependents)
+	})
+
+	t.Run("undo resets to the earliest base commit", func(t *testing.T) {
+		svc, store, base := setup(t)
+
+		reopened, err := svc.UndoWithCascade([]string{"iter-c", "iter-a", "iter-b"}, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"d"}, reopened)
+
+		head, err := exec.Command("git", "-C", svc.workDir, "rev-parse", "HEAD").Output()
+		require.NoError(t, err)
+		assert.Equal(t, base, strings.TrimSpace(string(head)))
+		for _, id := range []string{"a", "b", "c", "d"} {
+			task, err := store.Get(id)
+			require.NoError(t, err)
+			assert.Equal(t, taskstore.StatusOpen, task.Status, id)
+		}
+	})
+
+	t.Run("unknown iteration undoes nothing", func(t *testing.T) {
+		svc, store, _ := setup(t)
+
+		_, err := svc.UndoWithCascade([]string{"iter-c", "iter-missing"}, false)
+		assert.ErrorContains(t, err, "iteration not found: iter-missing")
+		task, err := store.Get("c")
+		require.NoError(t, err)
+		assert.Equal(t, taskstore.StatusCompleted, task.Status)
+	})
+}
+
 func TestParseEditorContent(t *testing.T) {
 	t.Run("removes comment lines", func(t *testing.T) {
 		input := "# Comment\nactual content\n# Another comment\nmore content"
@@ -120,6 +120,22 @@ func FormatCommitMessageWithType(commitType CommitType, taskTitle, iterationID s
 	return fmt.Sprintf("%s\n\nRalph iteration: %s", subject, iterationID)
 }
 
+// FormatRunCommitMessage creates a conventional commit message for a run
+// whose tasks are committed together. The subject is the parent title (with
+// the type inferred from it) and the body lists the completed task titles and
+// their iteration IDs.
+func FormatRunCommitMessage(parentTitle string, taskTitles, iterationIDs []string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n\n", InferCommitType(parentTitle), parentTitle))
+	for _, title := range taskTitles {
+		sb.WriteString(fmt.Sprintf("- %s\n", title))
+	}
+	if len(iterationIDs) > 0 {
+		sb.WriteString(fmt.Sprintf("\nRalph iterations: %s", strings.Join(iterationIDs, ", ")))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
 // ParseConventionalCommit parses a conventional commit message and returns
 // the commit type, subject, and body. Returns empty values if the message
 // doesn't follow the conventional commit format.
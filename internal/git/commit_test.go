@@ -160,6 +160,36 @@ func TestFormatCommitMessageWithType(t *testing.T) {
 	}
 }
 
+func TestFormatRunCommitMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		parentTitle  string
+		taskTitles   []string
+		iterationIDs []string
+		expected     string
+	}{
+		{
+			name:         "lists tasks and iterations",
+			parentTitle:  "Add user accounts",
+			taskTitles:   []string{"Create user model", "Add login endpoint"},
+			iterationIDs: []string{"iter-001", "iter-002"},
+			expected:     "feat: Add user accounts\n\n- Create user model\n- Add login endpoint\n\nRalph iterations: iter-001, iter-002",
+		},
+		{
+			name:        "no iteration metadata",
+			parentTitle: "Refactor storage",
+			taskTitles:  []string{"Extract interface"},
+			expected:    "chore: Refactor storage\n\n- Extract interface",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatRunCommitMessage(tt.parentTitle, tt.taskTitles, tt.iterationIDs))
+		})
+	}
+}
+
 func TestParseConventionalCommit(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -251,3 +281,53 @@ func TestValidateCommitMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestCommitTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		data     CommitMessageData
+		expected string
+	}{
+		{
+			name:     "default matches FormatCommitMessage",
+			data:     CommitMessageData{Type: CommitTypeFeat, Title: "Add user authentication", TaskID: "task-1", IterationID: "iter-001"},
+			expected: FormatCommitMessage("Add user authentication", "iter-001"),
+		},
+		{
+			name:     "default without iteration",
+			data:     CommitMessageData{Type: CommitTypeChore, Title: "Task store model"},
+			expected: FormatCommitMessage("Task store model", ""),
+		},
+		{
+			name:     "custom",
+			template: "{{.Type}}({{.TaskID}}): {{.Title}} [ralph {{.IterationID}}]",
+			data:     CommitMessageData{Type: "docs", Title: "Document the API", TaskID: "api-docs", IterationID: "iter-7"},
+			expected: "docs(api-docs): Document the API [ralph iter-7]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseCommitTemplate(tt.template)
+			require.NoError(t, err)
+
+			msg, err := tmpl.Format(tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+}
+
+func TestParseCommitTemplate_Invalid(t *testing.T) {
+	for _, text := range []string{"{{.Title", "{{.Scope}}: {{.Title}}", "{{if false}}x{{end}}"} {
+		_, err := ParseCommitTemplate(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestCommitTypeForTask(t *testing.T) {
+	assert.Equal(t, CommitType("docs"), CommitTypeForTask("Add usage guide", map[string]string{"type": " Docs "}))
+	assert.Equal(t, CommitTypeFeat, CommitTypeForTask("Add usage guide", map[string]string{"area": "core"}))
+	assert.Equal(t, CommitTypeFix, CommitTypeForTask("Fix crash", nil))
+}
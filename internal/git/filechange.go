@@ -0,0 +1,66 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FileStatus describes how a file changed.
+type FileStatus string
+
+const (
+	// FileAdded is a new file, tracked or not.
+	FileAdded FileStatus = "added"
+	// FileModified is an edited file.
+	FileModified FileStatus = "modified"
+	// FileDeleted is a removed file.
+	FileDeleted FileStatus = "deleted"
+	// FileRenamed is a file moved to Path.
+	FileRenamed FileStatus = "renamed"
+)
+
+// FileChange is an uncommitted change to a single file.
+type FileChange struct {
+	Path   string     `json:"path"`
+	Status FileStatus `json:"status"`
+}
+
+// parsePorcelainStatus classifies the XY status code of a
+// `git status --porcelain` line.
+func parsePorcelainStatus(xy string) FileStatus {
+	switch {
+	case strings.Contains(xy, "?"), strings.Contains(xy, "A"):
+		return FileAdded
+	case strings.Contains(xy, "D"):
+		return FileDeleted
+	case strings.Contains(xy, "R"):
+		return FileRenamed
+	default:
+		return FileModified
+	}
+}
+
+// SummarizeFileChanges describes changes by status, e.g.
+// "2 created, 3 modified, 1 deleted". It returns "" for no changes.
+func SummarizeFileChanges(changes []FileChange) string {
+	counts := make(map[FileStatus]int)
+	for _, c := range changes {
+		counts[c.Status]++
+	}
+
+	var parts []string
+	for _, s := range []struct {
+		status FileStatus
+		label  string
+	}{
+		{FileAdded, "created"},
+		{FileModified, "modified"},
+		{FileRenamed, "renamed"},
+		{FileDeleted, "deleted"},
+	} {
+		if n := counts[s.status]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, s.label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
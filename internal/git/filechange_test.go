@@ -0,0 +1,59 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePorcelainStatus(t *testing.T) {
+	tests := []struct {
+		xy   string
+		want FileStatus
+	}{
+		{"??", FileAdded},
+		{"A ", FileAdded},
+		{"AM", FileAdded},
+		{" M", FileModified},
+		{"M ", FileModified},
+		{"MM", FileModified},
+		{" D", FileDeleted},
+		{"D ", FileDeleted},
+		{"R ", FileRenamed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.xy, func(t *testing.T) {
+			assert.Equal(t, tt.want, parsePorcelainStatus(tt.xy))
+		})
+	}
+}
+
+func TestSummarizeFileChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []FileChange
+		want    string
+	}{
+		{name: "no changes", want: ""},
+		{
+			name: "groups by status in a fixed order",
+			changes: []FileChange{
+				{Path: "a.go", Status: FileModified},
+				{Path: "b.go", Status: FileDeleted},
+				{Path: "c.go", Status: FileAdded},
+				{Path: "d.go", Status: FileAdded},
+				{Path: "e.go", Status: FileModified},
+				{Path: "f.go", Status: FileModified},
+			},
+			want: "2 created, 3 modified, 1 deleted",
+		},
+		{name: "single status", changes: []FileChange{{Path: "x.go", Status: FileRenamed}}, want: "1 renamed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SummarizeFileChanges(tt.changes))
+		})
+	}
+}
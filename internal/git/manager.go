@@ -23,6 +23,20 @@ var (
 
 	// ErrCommitFailed indicates the commit operation failed.
 	ErrCommitFailed = errors.New("commit failed")
+
+	// ErrSigningKeyMissing is returned when commit signing is enabled but no
+	// signing key is configured.
+	ErrSigningKeyMissing = errors.New("commit signing is enabled but no signing key is configured")
+
+	// ErrStashConflict is returned when stashed changes cannot be restored
+	// cleanly. The stash entry is kept.
+	ErrStashConflict = errors.New("stashed changes conflict with the working tree")
+
+	// ErrProtectedBranch indicates the current branch must not receive commits.
+	ErrProtectedBranch = errors.New("refusing to run on protected branch")
+
+	// ErrDirtyWorkingTree indicates uncommitted changes exist before a run starts.
+	ErrDirtyWorkingTree = errors.New("working tree has uncommitted changes")
 )
 
 // GitError represents a Git command error with additional context.
@@ -75,15 +89,31 @@ type Manager interface {
 	// This includes both staged and unstaged files.
 	GetChangedFiles(ctx context.Context) ([]string, error)
 
+	// GetFileChanges returns the files with uncommitted changes and whether
+	// each was added, modified, deleted, or renamed.
+	GetFileChanges(ctx context.Context) ([]FileChange, error)
+
 	// Commit creates a commit with the given message and returns the commit hash.
 	// It stages all changes before committing.
 	// Returns ErrNoChanges if there are no changes to commit.
 	Commit(ctx context.Context, message string) (string, error)
 
+	// Stage stages all changes, including untracked files, without committing.
+	Stage(ctx context.Context) error
+
+	// GetUnstagedFiles returns the files whose changes are not staged,
+	// including untracked files.
+	GetUnstagedFiles(ctx context.Context) ([]string, error)
+
 	// GetCurrentBranch returns the name of the current branch.
 	GetCurrentBranch(ctx context.Context) (string, error)
 
 	// GetCommitMessage returns the commit message for the given commit hash.
 	// It returns an error if the commit doesn't exist.
 	GetCommitMessage(ctx context.Context, hash string) (string, error)
+
+	// StashChanges moves all uncommitted changes, including untracked files,
+	// into a stash entry with the given message, leaving the working tree clean.
+	// Paths listed in exclude are left untouched.
+	StashChanges(ctx context.Context, message string, exclude []string) error
 }
@@ -78,6 +78,32 @@ func (m *mockManager) GetCommitMessage(_ context.Context, _ string) (string, err
 	return m.commitMessage, nil
 }
 
+func (m *mockManager) StashChanges(_ context.Context, _ string, _ []string) error {
+	return m.err
+}
+
+func (m *mockManager) GetFileChanges(_ context.Context) ([]FileChange, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	changes := make([]FileChange, 0, len(m.changedFiles))
+	for _, f := range m.changedFiles {
+		changes = append(changes, FileChange{Path: f, Status: FileModified})
+	}
+	return changes, nil
+}
+
+func (m *mockManager) Stage(_ context.Context) error {
+	return m.err
+}
+
+func (m *mockManager) GetUnstagedFiles(_ context.Context) ([]string, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.changedFiles, nil
+}
+
 func TestManagerInterface(t *testing.T) {
 	// Verify mockManager implements Manager interface
 	var _ Manager = (*mockManager)(nil)
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -12,6 +15,8 @@ import (
 type ShellManager struct {
 	workDir      string
 	branchPrefix string
+	sign         bool
+	signingKey   string
 }
 
 // NewShellManager creates a new ShellManager with the given working directory
@@ -24,10 +29,47 @@ func NewShellManager(workDir, branchPrefix string) *ShellManager {
 	}
 }
 
+// SetSigning makes Commit and CherryPick sign the commits they create, with
+// key (a GPG key ID, or an SSH key when gpg.format is ssh) or, when key is
+// empty, the key set in git's user.signingkey. With signing enabled and no
+// key configured, committing fails with ErrSigningKeyMissing instead of
+// creating an unsigned commit.
+func (m *ShellManager) SetSigning(enabled bool, key string) {
+	m.sign = enabled
+	m.signingKey = key
+}
+
+// signArgs returns the git arguments that sign a commit, or nil when
+// signing is disabled.
+func (m *ShellManager) signArgs(ctx context.Context) ([]string, error) {
+	if !m.sign {
+		return nil, nil
+	}
+	if m.signingKey != "" {
+		return []string{"--gpg-sign=" + m.signingKey}, nil
+	}
+	if key, err := m.runGit(ctx, "config", "--get", "user.signingkey"); err != nil || key == "" {
+		return nil, &GitError{
+			Command: "git config --get user.signingkey",
+			Output:  "set user.signingkey or git.signing_key",
+			Err:     ErrSigningKeyMissing,
+		}
+	}
+	return []string{"--gpg-sign"}, nil
+}
+
 // runGit executes a git command and returns the combined output.
 func (m *ShellManager) runGit(ctx context.Context, args ...string) (string, error) {
+	return m.runGitEnv(ctx, nil, args...)
+}
+
+// runGitEnv is runGit with extra environment variables (KEY=value) set.
+func (m *ShellManager) runGitEnv(ctx context.Context, env []string, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = m.workDir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -117,8 +159,23 @@ func (m *ShellManager) GetDiffStat(ctx context.Context) (string, error) {
 }
 
 // GetChangedFiles returns a list of files with uncommitted changes.
-// This includes staged, unstaged, and untracked files.
+// This includes both staged and unstaged files.
 func (m *ShellManager) GetChangedFiles(ctx context.Context) ([]string, error) {
+	changes, err := m.GetFileChanges(ctx)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(changes))
+	for _, c := range changes {
+		files = append(files, c.Path)
+	}
+	return files, nil
+}
+
+// GetFileChanges returns the files with uncommitted changes and how each one
+// changed. This includes both staged and unstaged files.
+func (m *ShellManager) GetFileChanges(ctx context.Context) ([]FileChange, error) {
 	output, err := m.runGit(ctx, "status", "--porcelain")
 	if err != nil {
 		return nil, err
@@ -128,7 +185,7 @@ func (m *ShellManager) GetChangedFiles(ctx context.Context) ([]string, error) {
 		return nil, nil
 	}
 
-	var files []string
+	var changes []FileChange
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
 		if len(line) > 3 {
@@ -139,11 +196,11 @@ func (m *ShellManager) GetChangedFiles(ctx context.Context) ([]string, error) {
 			if idx := strings.Index(file, " -> "); idx != -1 {
 				file = file[idx+4:]
 			}
-			files = append(files, file)
+			changes = append(changes, FileChange{Path: file, Status: parsePorcelainStatus(line[:2])})
 		}
 	}
 
-	return files, nil
+	return changes, nil
 }
 
 // Commit creates a commit with the given message and returns the commit hash.
@@ -162,6 +219,11 @@ func (m *ShellManager) Commit(ctx context.Context, message string) (string, erro
 		}
 	}
 
+	signArgs, err := m.signArgs(ctx)
+	if err != nil {
+		return "", err
+	}
+
 	// Stage all changes
 	_, err = m.runGit(ctx, "add", "-A")
 	if err != nil {
@@ -169,7 +231,7 @@ func (m *ShellManager) Commit(ctx context.Context, message string) (string, erro
 	}
 
 	// Create commit
-	_, err = m.runGit(ctx, "commit", "-m", message)
+	_, err = m.runGit(ctx, append(append([]string{"commit"}, signArgs...), "-m", message)...)
 	if err != nil {
 		return "", &GitError{
 			Command: "git commit",
@@ -182,6 +244,247 @@ func (m *ShellManager) Commit(ctx context.Context, message string) (string, erro
 	return m.GetCurrentCommit(ctx)
 }
 
+// Stage stages all changes, including untracked files, without committing.
+func (m *ShellManager) Stage(ctx context.Context) error {
+	_, err := m.runGit(ctx, "add", "-A")
+	return err
+}
+
+// GetUnstagedFiles returns the files whose changes are not staged, including
+// untracked files.
+func (m *ShellManager) GetUnstagedFiles(ctx context.Context) ([]string, error) {
+	modified, err := m.runGit(ctx, "diff", "--name-only")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := m.runGit(ctx, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(modified+"\n"+untracked, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// BranchMergedInto reports whether branch exists and has been merged into
+// target: its tip is reachable from target, but is not on target's
+// first-parent history. A branch with no commits of its own, such as one
+// just created from target, is therefore not merged. A missing branch is not
+// merged. Squash and fast-forward merges are not detected.
+func (m *ShellManager) BranchMergedInto(ctx context.Context, branch, target string) (bool, error) {
+	reachable, err := m.IsAncestor(ctx, branch, target)
+	if err != nil || !reachable {
+		return false, err
+	}
+
+	// The oldest commit on target's first-parent history that descends from
+	// the tip is the merge commit, unless the tip is on that history itself
+	descendants, err := m.runGit(ctx, "rev-list", "--first-parent", "--ancestry-path", branch+".."+target)
+	if err != nil {
+		return false, err
+	}
+	if descendants == "" {
+		return false, nil // the tip is target's own tip
+	}
+	lines := strings.Split(descendants, "\n")
+	parent, err := m.runGit(ctx, "rev-parse", lines[len(lines)-1]+"^1")
+	if err != nil {
+		return false, err
+	}
+	tip, err := m.runGit(ctx, "rev-parse", branch+"^{commit}")
+	if err != nil {
+		return false, err
+	}
+	return parent != tip, nil
+}
+
+// IsAncestor reports whether ancestor exists and is reachable from
+// descendant. A missing ancestor (e.g. one dropped by a history rewrite)
+// is not an ancestor; a missing descendant is an error.
+func (m *ShellManager) IsAncestor(ctx context.Context, ancestor, descendant string) (bool, error) {
+	if _, err := m.runGit(ctx, "rev-parse", "--verify", "--quiet", ancestor+"^{commit}"); err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return false, nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", ancestor, descendant)
+	cmd.Dir = m.workDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, &GitError{
+		Command: "git merge-base --is-ancestor " + ancestor + " " + descendant,
+		Output:  stderr.String(),
+		Err:     err,
+	}
+}
+
+// FileHash returns the git blob hash of path at ref, or of the working tree
+// copy when ref is empty. It returns "" if path does not exist there, and an
+// error if ref does not name a commit.
+func (m *ShellManager) FileHash(ctx context.Context, ref, path string) (string, error) {
+	if ref == "" {
+		if _, err := os.Stat(filepath.Join(m.workDir, path)); errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		} else if err != nil {
+			return "", err
+		}
+		return m.runGit(ctx, "hash-object", "--", path)
+	}
+
+	if _, err := m.runGit(ctx, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return "", err
+	}
+	hash, err := m.runGit(ctx, "rev-parse", "--verify", "--quiet", ref+":"+path)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", nil
+	}
+	return hash, nil
+}
+
+// WorkingTreeHash returns the git tree hash of the working tree as it is now:
+// committed, staged, unstaged and untracked files, excluding ignored ones and
+// anything under .ralph.
+// Identical contents always hash the same, so the hash identifies the state
+// of the tree across runs. The real index is left untouched.
+func (m *ShellManager) WorkingTreeHash(ctx context.Context) (string, error) {
+	tmp, err := os.MkdirTemp("", "ralph-index-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	indexPath := filepath.Join(tmp, "index")
+
+	// Start from a copy of the real index so git can reuse its stat cache
+	// instead of rehashing every file.
+	realIndex, err := m.runGit(ctx, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(realIndex) {
+		realIndex = filepath.Join(m.workDir, realIndex)
+	}
+	if data, err := os.ReadFile(realIndex); err == nil {
+		if err := os.WriteFile(indexPath, data, 0644); err != nil {
+			return "", fmt.Errorf("failed to copy index: %w", err)
+		}
+	}
+
+	// Ralph's own files change during every run and never affect a build.
+	env := []string{"GIT_INDEX_FILE=" + indexPath}
+	if _, err := m.runGitEnv(ctx, env, "add", "-A", "--", ":/", ":(top,exclude).ralph"); err != nil {
+		return "", err
+	}
+	if _, err := m.runGitEnv(ctx, env, "rm", "-r", "--cached", "--quiet", "--ignore-unmatch", "--", ":(top).ralph"); err != nil {
+		return "", err
+	}
+	return m.runGitEnv(ctx, env, "write-tree")
+}
+
+// Clone clones the repository into dest, which must not exist yet. The clone
+// checks out the same branch as the repository and copies its local user.name,
+// user.email and signing settings so commits made in the clone are attributed
+// and signed the same way.
+// Uncommitted changes are not part of the clone.
+func (m *ShellManager) Clone(ctx context.Context, dest string) error {
+	if _, err := m.runGit(ctx, "clone", "--quiet", "--no-hardlinks", m.workDir, dest); err != nil {
+		return err
+	}
+	clone := m.withWorkDir(dest)
+	for _, key := range []string{"user.name", "user.email", "user.signingkey", "gpg.format"} {
+		value, err := m.runGit(ctx, "config", "--local", "--get", key)
+		if err != nil || value == "" {
+			continue
+		}
+		if _, err := clone.runGit(ctx, "config", key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FetchBranch fetches branch from the repository at source into a local
+// branch of the same name, without checking it out. The fetch fails if the
+// local branch exists and is not an ancestor of the fetched one, or if it is
+// the branch currently checked out.
+func (m *ShellManager) FetchBranch(ctx context.Context, source, branch string) error {
+	_, err := m.runGit(ctx, "fetch", "--quiet", source, "refs/heads/"+branch+":refs/heads/"+branch)
+	return err
+}
+
+// AddWorktree checks out HEAD, detached, in a new linked worktree at path
+// and returns a manager for it. Commits made there move no branch; bring
+// them back with CherryPick.
+func (m *ShellManager) AddWorktree(ctx context.Context, path string) (Manager, error) {
+	if _, err := m.runGit(ctx, "worktree", "add", "--quiet", "--detach", path, "HEAD"); err != nil {
+		return nil, err
+	}
+	return m.withWorkDir(path), nil
+}
+
+// withWorkDir returns a manager with the same settings as m working in dir.
+func (m *ShellManager) withWorkDir(dir string) *ShellManager {
+	clone := *m
+	clone.workDir = dir
+	return &clone
+}
+
+// RemoveWorktree removes the linked worktree at path, discarding any
+// uncommitted changes in it.
+func (m *ShellManager) RemoveWorktree(ctx context.Context, path string) error {
+	_, err := m.runGit(ctx, "worktree", "remove", "--force", path)
+	return err
+}
+
+// CherryPick applies the commits after base up to and including tip onto the
+// current branch and returns the new HEAD. If they do not apply cleanly, the
+// cherry-pick is aborted and the branch is left as it was.
+func (m *ShellManager) CherryPick(ctx context.Context, base, tip string) (string, error) {
+	signArgs, err := m.signArgs(ctx)
+	if err != nil {
+		return "", err
+	}
+	if _, err := m.runGit(ctx, append(append([]string{"cherry-pick"}, signArgs...), base+".."+tip)...); err != nil {
+		_, _ = m.runGit(context.WithoutCancel(ctx), "cherry-pick", "--abort")
+		return "", err
+	}
+	return m.GetCurrentCommit(ctx)
+}
+
+// Push pushes branch to remote and sets it as the branch's upstream.
+func (m *ShellManager) Push(ctx context.Context, remote, branch string) error {
+	_, err := m.runGit(ctx, "push", "--set-upstream", remote, branch)
+	return err
+}
+
+// Checkout switches the working tree to ref. A branch name is checked out
+// normally; any other ref (such as a commit hash) detaches HEAD.
+func (m *ShellManager) Checkout(ctx context.Context, ref string) error {
+	if _, err := m.runGit(ctx, "show-ref", "--verify", "--quiet", "refs/heads/"+ref); err == nil {
+		_, err = m.runGit(ctx, "checkout", "--quiet", ref)
+		return err
+	}
+	_, err := m.runGit(ctx, "checkout", "--quiet", "--detach", ref)
+	return err
+}
+
 // EnsureBranch ensures a branch exists and switches to it.
 // The branch name is prefixed with the configured branch prefix.
 // If the branch doesn't exist, it creates it. If it already exists, it switches to it.
@@ -225,8 +528,75 @@ func (m *ShellManager) EnsureBranch(ctx context.Context, branchName string) erro
 	return err
 }
 
+// Diff returns the patch between the commits base and head, or between base
+// and the working tree when head is empty. Untracked files are not included.
+func (m *ShellManager) Diff(ctx context.Context, base, head string) (string, error) {
+	args := []string{"diff", "--no-color", "--no-ext-diff", base}
+	if head != "" {
+		args = append(args, head)
+	}
+	return m.runGit(ctx, args...)
+}
+
 // GetCommitMessage returns the commit message for the given commit hash.
 // It uses the %B format to get the full commit message body.
 func (m *ShellManager) GetCommitMessage(ctx context.Context, hash string) (string, error) {
 	return m.runGit(ctx, "log", "-1", "--format=%B", hash)
 }
+
+// StashChanges stashes uncommitted changes, including untracked files,
+// except for the excluded paths. It is a no-op when there is nothing to stash.
+func (m *ShellManager) StashChanges(ctx context.Context, message string, exclude []string) error {
+	args := []string{"stash", "push", "--include-untracked", "-m", message, "--", "."}
+	for _, path := range exclude {
+		args = append(args, ":(exclude)"+path)
+	}
+	_, err := m.runGit(ctx, args...)
+	return err
+}
+
+// PushStash stashes uncommitted changes like StashChanges and returns the
+// new stash entry's commit, or "" when there was nothing to stash.
+func (m *ShellManager) PushStash(ctx context.Context, message string, exclude []string) (string, error) {
+	before := m.stashTop(ctx)
+	if err := m.StashChanges(ctx, message, exclude); err != nil {
+		return "", err
+	}
+	after := m.stashTop(ctx)
+	if after == before {
+		return "", nil
+	}
+	return after, nil
+}
+
+// PopStash restores the stash entry created by PushStash with the given
+// commit and drops it. If the changes conflict with the working tree, the
+// entry is kept and the error wraps ErrStashConflict.
+func (m *ShellManager) PopStash(ctx context.Context, stash string) error {
+	if top := m.stashTop(ctx); top != stash {
+		return fmt.Errorf("stash %s is no longer the latest stash entry", stash)
+	}
+	if _, err := m.runGit(ctx, "stash", "pop", "stash@{0}"); err != nil {
+		output := err.Error()
+		var gitErr *GitError
+		if errors.As(err, &gitErr) {
+			output = gitErr.Output
+		}
+		return &GitError{
+			Command: "git stash pop",
+			Output:  output,
+			Err:     ErrStashConflict,
+		}
+	}
+	return nil
+}
+
+// stashTop returns the commit of the latest stash entry, or "" if there is
+// none.
+func (m *ShellManager) stashTop(ctx context.Context) string {
+	hash, err := m.runGit(ctx, "rev-parse", "--verify", "--quiet", "refs/stash")
+	if err != nil {
+		return ""
+	}
+	return hash
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -231,6 +232,242 @@ func TestShellManager_GetChangedFiles_NoChanges(t *testing.T) {
 	assert.Empty(t, files)
 }
 
+func TestShellManager_GetFileChanges(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+
+	commitTestFile(t, dir, "README.md", "# Test", "initial commit")
+	commitTestFile(t, dir, "old.txt", "old", "add old file")
+	createTestFile(t, dir, "README.md", "# Test Modified")
+	createTestFile(t, dir, "new.txt", "new content")
+	require.NoError(t, os.Remove(filepath.Join(dir, "old.txt")))
+
+	changes, err := mgr.GetFileChanges(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []FileChange{
+		{Path: "README.md", Status: FileModified},
+		{Path: "new.txt", Status: FileAdded},
+		{Path: "old.txt", Status: FileDeleted},
+	}, changes)
+}
+
+func TestShellManager_StageAndGetUnstagedFiles(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+	ctx := context.Background()
+
+	commitTestFile(t, dir, "README.md", "# Test", "initial commit")
+	createTestFile(t, dir, "README.md", "# Test Modified")
+	createTestFile(t, dir, "new.txt", "new content")
+
+	files, err := mgr.GetUnstagedFiles(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"README.md", "new.txt"}, files)
+
+	require.NoError(t, mgr.Stage(ctx))
+
+	files, err = mgr.GetUnstagedFiles(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	hasChanges, err := mgr.HasChanges(ctx)
+	require.NoError(t, err)
+	assert.True(t, hasChanges, "staged changes are still uncommitted")
+
+	createTestFile(t, dir, "later.txt", "later")
+	files, err = mgr.GetUnstagedFiles(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"later.txt"}, files)
+}
+
+func TestShellManager_BranchMergedInto(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+	ctx := context.Background()
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, string(out))
+	}
+
+	commitTestFile(t, dir, "README.md", "# Test", "initial commit")
+	git("checkout", "-b", "merged-task")
+	commitTestFile(t, dir, "merged.txt", "merged", "merged work")
+	git("checkout", "main")
+	git("merge", "--no-ff", "-m", "merge", "merged-task")
+	git("checkout", "-b", "open-task")
+	commitTestFile(t, dir, "open.txt", "open", "open work")
+	git("checkout", "main")
+	git("branch", "new-task")
+	git("branch", "old-task", "HEAD~1")
+
+	tests := []struct {
+		branch string
+		want   bool
+	}{
+		{branch: "merged-task", want: true},
+		{branch: "open-task", want: false},
+		{branch: "new-task", want: false},
+		{branch: "old-task", want: false},
+		{branch: "missing-task", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			merged, err := mgr.BranchMergedInto(ctx, tt.branch, "main")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, merged)
+		})
+	}
+
+	t.Run("missing target", func(t *testing.T) {
+		_, err := mgr.BranchMergedInto(ctx, "merged-task", "no-such-branch")
+		require.Error(t, err)
+	})
+}
+
+func TestShellManager_IsAncestor(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+	ctx := context.Background()
+
+	commitTestFile(t, dir, "README.md", "first", "initial commit")
+	first, err := mgr.GetCurrentCommit(ctx)
+	require.NoError(t, err)
+	commitTestFile(t, dir, "README.md", "second", "second commit")
+	second, err := mgr.GetCurrentCommit(ctx)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		ancestor   string
+		descendant string
+		want       bool
+	}{
+		{name: "older commit", ancestor: first, descendant: "HEAD", want: true},
+		{name: "same commit", ancestor: second, descendant: "HEAD", want: true},
+		{name: "newer commit", ancestor: second, descendant: first, want: false},
+		{name: "unknown commit", ancestor: "0000000000000000000000000000000000000000", descendant: "HEAD", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mgr.IsAncestor(ctx, tt.ancestor, tt.descendant)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestShellManager_Checkout(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+	ctx := context.Background()
+
+	commitTestFile(t, dir, "README.md", "first", "initial commit")
+	first, err := mgr.GetCurrentCommit(ctx)
+	require.NoError(t, err)
+	commitTestFile(t, dir, "README.md", "second", "second commit")
+
+	require.NoError(t, mgr.Checkout(ctx, first))
+	content, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+	branch, err := mgr.GetCurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "HEAD", branch, "a commit should be checked out detached")
+
+	require.NoError(t, mgr.Checkout(ctx, "main"))
+	branch, err = mgr.GetCurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+
+	require.Error(t, mgr.Checkout(ctx, "no-such-ref"))
+}
+
+func TestShellManager_FileHash(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+	ctx := context.Background()
+
+	commitTestFile(t, dir, "a.txt", "first", "initial commit")
+	first, err := mgr.GetCurrentCommit(ctx)
+	require.NoError(t, err)
+	commitTestFile(t, dir, "a.txt", "second", "second commit")
+
+	atFirst, err := mgr.FileHash(ctx, first, "a.txt")
+	require.NoError(t, err)
+	atHead, err := mgr.FileHash(ctx, "HEAD", "a.txt")
+	require.NoError(t, err)
+	assert.NotEmpty(t, atFirst)
+	assert.NotEqual(t, atFirst, atHead)
+
+	createTestFile(t, dir, "a.txt", "first")
+	working, err := mgr.FileHash(ctx, "", "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, atFirst, working)
+
+	missing, err := mgr.FileHash(ctx, "HEAD", "missing.txt")
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+	missing, err = mgr.FileHash(ctx, "", "missing.txt")
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	_, err = mgr.FileHash(ctx, "0000000000000000000000000000000000000000", "a.txt")
+	require.Error(t, err)
+}
+
+func TestShellManager_WorkingTreeHash(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+	ctx := context.Background()
+
+	commitTestFile(t, dir, "a.txt", "first", "initial commit")
+	clean, err := mgr.WorkingTreeHash(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, clean)
+
+	again, err := mgr.WorkingTreeHash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, clean, again, "an unchanged tree hashes the same")
+
+	createTestFile(t, dir, "a.txt", "changed")
+	modified, err := mgr.WorkingTreeHash(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, clean, modified, "unstaged edits change the hash")
+
+	createTestFile(t, dir, "a.txt", "first")
+	createTestFile(t, dir, "new.txt", "untracked")
+	untracked, err := mgr.WorkingTreeHash(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, clean, untracked, "untracked files change the hash")
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "new.txt")))
+	restored, err := mgr.WorkingTreeHash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, clean, restored, "restoring the contents restores the hash")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".ralph", "state"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".ralph", "tasks"), 0755))
+	createTestFile(t, dir, ".ralph/state/budget.json", "{}")
+	withState, err := mgr.WorkingTreeHash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, clean, withState, "untracked files under .ralph are ignored")
+
+	commitTestFile(t, dir, ".ralph/tasks/tasks.yaml", "tasks: []", "track ralph files")
+	createTestFile(t, dir, ".ralph/tasks/tasks.yaml", "tasks: [changed]")
+	tracked, err := mgr.WorkingTreeHash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, clean, tracked, "tracked files under .ralph are ignored")
+
+	cmd := exec.Command("git", "diff", "--cached", "--name-only")
+	cmd.Dir = dir
+	staged, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Empty(t, strings.TrimSpace(string(staged)), "the real index is left untouched")
+}
+
 func TestShellManager_Commit(t *testing.T) {
 	dir := setupTestRepo(t)
 	mgr := NewShellManager(dir, "ralph/")
@@ -281,6 +518,107 @@ func TestShellManager_Commit_StagedChanges(t *testing.T) {
 	assert.NotEmpty(t, hash)
 }
 
+// setupSSHSigning configures the repository at dir to sign with a new SSH
+// key and returns the key's path. It skips the test without ssh-keygen.
+func setupSSHSigning(t *testing.T, dir string) string {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput()
+	require.NoError(t, err, string(out))
+	out, err = exec.Command("git", "-C", dir, "config", "gpg.format", "ssh").CombinedOutput()
+	require.NoError(t, err, string(out))
+	return key
+}
+
+// isSigned reports whether the commit rev in dir carries a signature.
+func isSigned(t *testing.T, dir, rev string) bool {
+	t.Helper()
+	out, err := exec.Command("git", "-C", dir, "cat-file", "commit", rev).Output()
+	require.NoError(t, err)
+	return strings.Contains(string(out), "\ngpgsig ")
+}
+
+func TestShellManager_Commit_Signing(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	ctx := context.Background()
+
+	t.Run("unsigned by default", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		mgr := NewShellManager(dir, "ralph/")
+		createTestFile(t, dir, "a.txt", "a")
+
+		hash, err := mgr.Commit(ctx, "feat: unsigned")
+		require.NoError(t, err)
+		assert.False(t, isSigned(t, dir, hash))
+	})
+
+	t.Run("signs with the given key", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		key := setupSSHSigning(t, dir)
+		mgr := NewShellManager(dir, "ralph/")
+		mgr.SetSigning(true, key)
+		createTestFile(t, dir, "a.txt", "a")
+
+		hash, err := mgr.Commit(ctx, "feat: signed")
+		require.NoError(t, err)
+		assert.True(t, isSigned(t, dir, hash))
+	})
+
+	t.Run("signs with user.signingkey", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		key := setupSSHSigning(t, dir)
+		out, err := exec.Command("git", "-C", dir, "config", "user.signingkey", key).CombinedOutput()
+		require.NoError(t, err, string(out))
+		mgr := NewShellManager(dir, "ralph/")
+		mgr.SetSigning(true, "")
+		createTestFile(t, dir, "a.txt", "a")
+
+		hash, err := mgr.Commit(ctx, "feat: signed")
+		require.NoError(t, err)
+		assert.True(t, isSigned(t, dir, hash))
+	})
+
+	t.Run("fails without a key instead of committing unsigned", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		commitTestFile(t, dir, "README.md", "# Test", "initial commit")
+		mgr := NewShellManager(dir, "ralph/")
+		mgr.SetSigning(true, "")
+		createTestFile(t, dir, "a.txt", "a")
+
+		_, err := mgr.Commit(ctx, "feat: signed")
+		require.ErrorIs(t, err, ErrSigningKeyMissing)
+		log, err := exec.Command("git", "-C", dir, "log", "--format=%s").Output()
+		require.NoError(t, err)
+		assert.Equal(t, "initial commit", strings.TrimSpace(string(log)))
+	})
+
+	t.Run("worktrees sign too", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		key := setupSSHSigning(t, dir)
+		commitTestFile(t, dir, "README.md", "# Test", "initial commit")
+		mgr := NewShellManager(dir, "ralph/")
+		mgr.SetSigning(true, key)
+		base, err := mgr.GetCurrentCommit(ctx)
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "wt")
+		wt, err := mgr.AddWorktree(ctx, path)
+		require.NoError(t, err)
+		defer func() { _ = mgr.RemoveWorktree(ctx, path) }()
+		createTestFile(t, path, "a.txt", "a")
+		tip, err := wt.Commit(ctx, "feat: in worktree")
+		require.NoError(t, err)
+		assert.True(t, isSigned(t, dir, tip))
+
+		head, err := mgr.CherryPick(ctx, base, tip)
+		require.NoError(t, err)
+		assert.True(t, isSigned(t, dir, head))
+	})
+}
+
 func TestShellManager_EnsureBranch_CreateNew(t *testing.T) {
 	dir := setupTestRepo(t)
 	mgr := NewShellManager(dir, "ralph/")
@@ -457,3 +795,204 @@ func TestShellManager_Init_AlreadyInitialized(t *testing.T) {
 	err := mgr.Init(context.Background())
 	require.NoError(t, err)
 }
+
+func TestShellManager_StashChanges(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+
+	commitTestFile(t, dir, "README.md", "# Test", "initial commit")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".ralph"), 0755))
+	commitTestFile(t, dir, ".ralph/progress.md", "progress", "add progress")
+
+	createTestFile(t, dir, "README.md", "# Test Modified")
+	createTestFile(t, dir, "new.txt", "untracked")
+	createTestFile(t, dir, ".ralph/progress.md", "progress updated")
+
+	err := mgr.StashChanges(context.Background(), "ralph: task-1 attempt 1", []string{".ralph"})
+	require.NoError(t, err)
+
+	files, err := mgr.GetChangedFiles(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{".ralph/progress.md"}, files)
+
+	cmd := exec.Command("git", "stash", "list")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "ralph: task-1 attempt 1")
+}
+
+func TestShellManager_PushAndPopStash(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+	ctx := context.Background()
+	commitTestFile(t, dir, "README.md", "# Test", "initial commit")
+
+	stash, err := mgr.PushStash(ctx, "nothing", nil)
+	require.NoError(t, err)
+	assert.Empty(t, stash, "nothing to stash")
+
+	createTestFile(t, dir, "README.md", "# Test Modified")
+	stash, err = mgr.PushStash(ctx, "wip", nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, stash)
+	hasChanges, err := mgr.HasChanges(ctx)
+	require.NoError(t, err)
+	assert.False(t, hasChanges)
+
+	require.NoError(t, mgr.PopStash(ctx, stash))
+	content, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Test Modified", string(content))
+	assert.Error(t, mgr.PopStash(ctx, stash), "the entry was dropped")
+
+	createTestFile(t, dir, "README.md", "# Stashed")
+	stash, err = mgr.PushStash(ctx, "conflicting", nil)
+	require.NoError(t, err)
+	createTestFile(t, dir, "README.md", "# Conflict")
+	commitTestFile(t, dir, "README.md", "# Conflict", "conflicting change")
+
+	err = mgr.PopStash(ctx, stash)
+	require.ErrorIs(t, err, ErrStashConflict)
+	out, err := exec.Command("git", "-C", dir, "stash", "list").Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "conflicting", "the entry is kept")
+}
+
+func TestShellManager_Diff(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+	ctx := context.Background()
+
+	commitTestFile(t, dir, "a.txt", "one\n", "initial commit")
+	base, err := mgr.GetCurrentCommit(ctx)
+	require.NoError(t, err)
+	commitTestFile(t, dir, "a.txt", "two\n", "second commit")
+	head, err := mgr.GetCurrentCommit(ctx)
+	require.NoError(t, err)
+	createTestFile(t, dir, "a.txt", "three\n")
+
+	diff, err := mgr.Diff(ctx, base, head)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "-one\n+two")
+
+	diff, err = mgr.Diff(ctx, head, "")
+	require.NoError(t, err)
+	assert.Contains(t, diff, "-two\n+three")
+
+	_, err = mgr.Diff(ctx, "missing", "")
+	assert.Error(t, err)
+}
+
+func TestShellManager_CloneAndFetchBranch(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+	ctx := context.Background()
+
+	commitTestFile(t, dir, "a.txt", "first", "initial commit")
+	createTestFile(t, dir, "dirty.txt", "uncommitted")
+
+	dest := filepath.Join(t.TempDir(), "clone")
+	require.NoError(t, mgr.Clone(ctx, dest))
+
+	clone := NewShellManager(dest, "ralph/")
+	branch, err := clone.GetCurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+	assert.NoFileExists(t, filepath.Join(dest, "dirty.txt"), "uncommitted changes are not cloned")
+
+	require.NoError(t, clone.EnsureBranch(ctx, "work"))
+	commitTestFile(t, dest, "b.txt", "second", "work in clone")
+	want, err := clone.GetCurrentCommit(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.FetchBranch(ctx, dest, "ralph/work"))
+
+	got, err := mgr.runGit(ctx, "rev-parse", "refs/heads/ralph/work")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	current, err := mgr.GetCurrentBranch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "main", current, "the fetched branch is not checked out")
+	assert.NoFileExists(t, filepath.Join(dir, "b.txt"))
+}
+
+func TestShellManager_WorktreeAndCherryPick(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+	ctx := context.Background()
+
+	commitTestFile(t, dir, "shared.txt", "base\n", "initial commit")
+	base, err := mgr.GetCurrentCommit(ctx)
+	require.NoError(t, err)
+
+	addWorktree := func(name, file, content string) string {
+		path := filepath.Join(t.TempDir(), name)
+		wt, err := mgr.AddWorktree(ctx, path)
+		require.NoError(t, err)
+		createTestFile(t, path, file, content)
+		tip, err := wt.Commit(ctx, "change in "+name)
+		require.NoError(t, err)
+		return tip
+	}
+	tipA := addWorktree("a", "a.txt", "from a\n")
+	tipB := addWorktree("b", "shared.txt", "from b\n")
+	tipC := addWorktree("c", "shared.txt", "from c\n")
+
+	assert.NoFileExists(t, filepath.Join(dir, "a.txt"), "worktree commits move no branch")
+
+	head, err := mgr.CherryPick(ctx, base, tipA)
+	require.NoError(t, err)
+	assert.NotEqual(t, base, head)
+	assert.FileExists(t, filepath.Join(dir, "a.txt"))
+
+	_, err = mgr.CherryPick(ctx, base, tipB)
+	require.NoError(t, err)
+
+	before, err := mgr.GetCurrentCommit(ctx)
+	require.NoError(t, err)
+	_, err = mgr.CherryPick(ctx, base, tipC)
+	require.Error(t, err, "conflicting changes do not apply")
+	after, err := mgr.GetCurrentCommit(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+	hasChanges, err := mgr.HasChanges(ctx)
+	require.NoError(t, err)
+	assert.False(t, hasChanges, "the failed cherry-pick is aborted")
+
+	path := filepath.Join(t.TempDir(), "d")
+	_, err = mgr.AddWorktree(ctx, path)
+	require.NoError(t, err)
+	createTestFile(t, path, "dirty.txt", "uncommitted")
+	require.NoError(t, mgr.RemoveWorktree(ctx, path))
+	assert.NoDirExists(t, path)
+}
+
+func TestShellManager_Push(t *testing.T) {
+	dir := setupTestRepo(t)
+	mgr := NewShellManager(dir, "ralph/")
+	ctx := context.Background()
+
+	remote := t.TempDir()
+	out, err := exec.Command("git", "init", "--bare", remote).CombinedOutput()
+	require.NoError(t, err, string(out))
+	out, err = exec.Command("git", "-C", dir, "remote", "add", "origin", remote).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	commitTestFile(t, dir, "a.txt", "a\n", "initial commit")
+	require.NoError(t, mgr.EnsureBranch(ctx, "feature"))
+	commitTestFile(t, dir, "b.txt", "b\n", "feature work")
+	head, err := mgr.GetCurrentCommit(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, mgr.Push(ctx, "origin", "ralph/feature"))
+
+	pushed, err := exec.Command("git", "-C", remote, "rev-parse", "ralph/feature").Output()
+	require.NoError(t, err)
+	assert.Equal(t, head, strings.TrimSpace(string(pushed)))
+	upstream, err := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "@{upstream}").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "origin/ralph/feature", strings.TrimSpace(string(upstream)))
+
+	assert.Error(t, mgr.Push(ctx, "missing", "ralph/feature"))
+}
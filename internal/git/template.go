@@ -0,0 +1,71 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultCommitTemplate is the commit message template used when none is
+// configured. It produces the same messages as FormatCommitMessage.
+const DefaultCommitTemplate = "{{.Type}}: {{.Title}}{{if .IterationID}}\n\nRalph iteration: {{.IterationID}}{{end}}"
+
+// CommitMessageData is what a commit message template can refer to.
+type CommitMessageData struct {
+	// Type is the conventional commit type: the task's "type" label if it
+	// has one, otherwise inferred from the title.
+	Type        CommitType
+	Title       string
+	TaskID      string
+	IterationID string
+}
+
+// CommitTemplate renders commit messages from a text/template.
+type CommitTemplate struct {
+	tmpl *template.Template
+}
+
+// ParseCommitTemplate parses a commit message template, such as
+// "{{.Type}}: {{.Title}} [ralph {{.IterationID}}]". An empty text uses
+// DefaultCommitTemplate. The template is tried on sample data, so unknown
+// fields and templates that render an empty message are rejected here rather
+// than at commit time.
+func ParseCommitTemplate(text string) (*CommitTemplate, error) {
+	if text == "" {
+		text = DefaultCommitTemplate
+	}
+	tmpl, err := template.New("commit").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse commit template: %w", err)
+	}
+
+	t := &CommitTemplate{tmpl: tmpl}
+	if _, err := t.Format(CommitMessageData{Type: CommitTypeFeat, Title: "Add a feature", TaskID: "task-1", IterationID: "iter-1"}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Format renders the commit message for data.
+func (t *CommitTemplate) Format(data CommitMessageData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render commit template: %w", err)
+	}
+	msg := strings.TrimSpace(buf.String())
+	if msg == "" {
+		return "", fmt.Errorf("commit template rendered an empty message")
+	}
+	return msg, nil
+}
+
+// CommitTypeForTask returns the conventional commit type for a task: the
+// value of its "type" label (e.g. "docs" or "test") if set, otherwise the
+// type inferred from its title.
+func CommitTypeForTask(title string, labels map[string]string) CommitType {
+	if label := strings.ToLower(strings.TrimSpace(labels["type"])); label != "" {
+		return CommitType(label)
+	}
+	return InferCommitType(title)
+}
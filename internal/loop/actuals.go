@@ -0,0 +1,66 @@
+package loop
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Labels ralph sets on completed tasks with the effort they actually took.
+// Other labels are never modified.
+const (
+	LabelActualCost       = "actual_cost"
+	LabelActualDuration   = "actual_duration"
+	LabelActualIterations = "actual_iterations"
+)
+
+// recordActuals stores the task's actual cost, duration, and iteration count in
+// its ralph-managed actual_* labels. Totals cover the current successful
+// iteration and the failed attempts since the task last succeeded.
+func (c *Controller) recordActuals(taskID string, record *IterationRecord) {
+	var cost float64
+	var duration time.Duration
+	var iterations int
+
+	// Sum unsuccessful attempts since the last success
+	if records, err := c.loadRecords(); err == nil {
+		slices.SortStableFunc(records, func(a, b *IterationRecord) int {
+			return a.StartTime.Compare(b.StartTime)
+		})
+		for _, r := range records {
+			if r.TaskID != taskID || r.IterationID == record.IterationID {
+				continue
+			}
+			if r.Outcome == OutcomeSuccess {
+				cost, duration, iterations = 0, 0, 0
+				continue
+			}
+			cost += r.ClaudeInvocation.TotalCostUSD
+			duration += r.Duration()
+			iterations++
+		}
+	}
+
+	cost += record.ClaudeInvocation.TotalCostUSD
+	duration += time.Since(record.StartTime)
+	iterations++
+
+	task, err := c.taskStore.Get(taskID)
+	if err != nil {
+		return
+	}
+
+	labels := maps.Clone(task.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[LabelActualCost] = fmt.Sprintf("%.4f", cost)
+	labels[LabelActualDuration] = duration.Round(time.Second).String()
+	labels[LabelActualIterations] = strconv.Itoa(iterations)
+
+	task.Labels = labels
+	task.UpdatedAt = time.Now()
+	_ = c.taskStore.Save(task)
+}
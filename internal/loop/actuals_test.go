@@ -0,0 +1,59 @@
+package loop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestController_RecordsActualsOnCompletion(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	child := newTestTask("child", "Child Task", taskstore.StatusOpen, strPtr("parent"))
+	child.Labels = map[string]string{"area": "core", LabelActualCost: "stale"}
+	store.addTask(child)
+
+	logsDir := t.TempDir()
+
+	// A failed attempt from an earlier run, preceded by an older success (e.g. before an undo)
+	start := time.Now().Add(-time.Hour)
+	for _, r := range []*IterationRecord{
+		{IterationID: "old-success", TaskID: "child", StartTime: start, EndTime: start.Add(time.Minute), Outcome: OutcomeSuccess,
+			ClaudeInvocation: ClaudeInvocationMeta{TotalCostUSD: 5}},
+		{IterationID: "failed", TaskID: "child", StartTime: start.Add(10 * time.Minute), EndTime: start.Add(12 * time.Minute), Outcome: OutcomeFailed,
+			ClaudeInvocation: ClaudeInvocationMeta{TotalCostUSD: 0.25}},
+		{IterationID: "other", TaskID: "other", StartTime: start, Outcome: OutcomeFailed,
+			ClaudeInvocation: ClaudeInvocationMeta{TotalCostUSD: 1}},
+	} {
+		_, err := SaveRecord(logsDir, r)
+		require.NoError(t, err)
+	}
+
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "done", TotalCostUSD: 0.5}},
+		Verifier:  &mockVerifier{},
+		Git: &mockGitManager{
+			currentCommit: "abc123",
+			hasChanges:    true,
+			changedFiles:  []string{"file1.go"},
+			commitHash:    "def456",
+		},
+		LogsDir: logsDir,
+	})
+
+	result := ctrl.RunLoop(context.Background(), "parent")
+	require.Equal(t, RunOutcomeCompleted, result.Outcome)
+
+	labels := store.tasks["child"].Labels
+	assert.Equal(t, "core", labels["area"], "user labels are kept")
+	assert.Equal(t, "0.7500", labels[LabelActualCost])
+	assert.Equal(t, "2", labels[LabelActualIterations])
+	assert.Equal(t, "2m0s", labels[LabelActualDuration])
+}
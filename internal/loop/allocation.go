@@ -0,0 +1,122 @@
+package loop
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// BudgetAllocation controls how the run's cost limit is shared between tasks.
+type BudgetAllocation string
+
+const (
+	// AllocationNone lets any task spend the whole remaining budget (the default).
+	AllocationNone BudgetAllocation = "none"
+	// AllocationProportional gives each task a slice of the remaining budget
+	// weighted by its effort label.
+	AllocationProportional BudgetAllocation = "proportional"
+)
+
+// EffortLabel is the task label weighting a task's budget slice: "small" (1),
+// "medium" (2), "large" (4), or a positive number. Unlabeled tasks count as medium.
+const EffortLabel = "effort"
+
+// SetBudgetAllocation sets how the cost limit is shared between tasks. In
+// proportional mode, each task gets a slice of the remaining budget when it is
+// first selected, weighted by effort against the other unfinished tasks. A
+// task that has spent its slice is marked failed instead of being retried.
+// Budget a task leaves unspent goes back to the tasks that have not started.
+// It has no effect without a cost limit. An empty mode means none.
+func (c *Controller) SetBudgetAllocation(mode BudgetAllocation) error {
+	switch mode {
+	case "", AllocationNone:
+		c.budgetAllocation = AllocationNone
+		return nil
+	case AllocationProportional:
+		c.budgetAllocation = mode
+		return nil
+	default:
+		return fmt.Errorf("unknown budget allocation %q (want %s or %s)", mode, AllocationNone, AllocationProportional)
+	}
+}
+
+// effortWeight returns the budget weight of task from its effort label.
+func effortWeight(task *taskstore.Task) float64 {
+	switch value := strings.ToLower(strings.TrimSpace(task.Labels[EffortLabel])); value {
+	case "small", "s":
+		return 1
+	case "large", "l":
+		return 4
+	case "", "medium", "m":
+		return 2
+	default:
+		if n, err := strconv.ParseFloat(value, 64); err == nil && n > 0 {
+			return n
+		}
+		return 2
+	}
+}
+
+// recordTaskSpend adds cost to what task has spent in this run.
+func (c *Controller) recordTaskSpend(taskID string, cost float64) {
+	if c.taskSpent == nil {
+		c.taskSpent = make(map[string]float64)
+	}
+	c.taskSpent[taskID] += cost
+}
+
+// taskBudgetExhausted returns why task may not run another iteration because
+// it has spent its budget slice, or "" if it may. The slice is allocated the
+// first time the task is checked.
+func (c *Controller) taskBudgetExhausted(tasks []*taskstore.Task, parentTaskID string, task *taskstore.Task) string {
+	if c.budgetAllocation != AllocationProportional || c.budget.limits.MaxCostUSD <= 0 {
+		return ""
+	}
+	if c.taskSlices == nil {
+		c.taskSlices = make(map[string]float64)
+	}
+
+	slice, ok := c.taskSlices[task.ID]
+	if !ok {
+		slice = c.allocateTaskBudget(tasks, parentTaskID, task)
+		c.taskSlices[task.ID] = slice
+		c.writeVerbose("  Budget slice for %s: $%.2f\n", task.ID, slice)
+	}
+
+	if spent := c.taskSpent[task.ID]; spent >= slice {
+		return fmt.Sprintf("spent $%.2f of its $%.2f budget slice", spent, slice)
+	}
+	return ""
+}
+
+// allocateTaskBudget returns task's share of the budget that is neither spent
+// nor held by other unfinished tasks' slices, weighted by effort against the
+// unfinished leaf tasks under parentTaskID that have no slice yet.
+func (c *Controller) allocateTaskBudget(tasks []*taskstore.Task, parentTaskID string, task *taskstore.Task) float64 {
+	available := c.budget.limits.MaxCostUSD - c.budget.GetState().TotalCostUSD
+
+	byID := make(map[string]*taskstore.Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+
+	totalWeight := effortWeight(task)
+	for _, id := range unfinishedLeafDescendants(tasks, parentTaskID) {
+		t := byID[id]
+		if id == task.ID || (t.Status != taskstore.StatusOpen && t.Status != taskstore.StatusInProgress) {
+			continue
+		}
+		if slice, ok := c.taskSlices[id]; ok {
+			available -= max(0, slice-c.taskSpent[id])
+			continue
+		}
+		totalWeight += effortWeight(t)
+	}
+
+	if available <= 0 {
+		return 0
+	}
+	return available * effortWeight(task) / totalWeight
+}
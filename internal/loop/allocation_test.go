@@ -0,0 +1,136 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestEffortWeight(t *testing.T) {
+	tests := []struct {
+		effort string
+		want   float64
+	}{
+		{effort: "", want: 2},
+		{effort: "small", want: 1},
+		{effort: "Medium", want: 2},
+		{effort: "large", want: 4},
+		{effort: "3.5", want: 3.5},
+		{effort: "0", want: 2},
+		{effort: "huge", want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.effort, func(t *testing.T) {
+			task := newTestTask("t", "T", taskstore.StatusOpen, nil)
+			if tt.effort != "" {
+				task.Labels = map[string]string{EffortLabel: tt.effort}
+			}
+			assert.Equal(t, tt.want, effortWeight(task))
+		})
+	}
+}
+
+func TestController_SetBudgetAllocation(t *testing.T) {
+	ctrl := NewController(ControllerDeps{})
+
+	require.NoError(t, ctrl.SetBudgetAllocation(""))
+	require.NoError(t, ctrl.SetBudgetAllocation(AllocationProportional))
+	err := ctrl.SetBudgetAllocation("equal")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown budget allocation "equal"`)
+}
+
+func TestController_AllocateTaskBudget(t *testing.T) {
+	withEffort := func(task *taskstore.Task, effort string) *taskstore.Task {
+		task.Labels = map[string]string{EffortLabel: effort}
+		return task
+	}
+	parent := newTestTask("parent", "Parent", taskstore.StatusOpen, nil)
+	large := withEffort(newTestTask("large", "Large", taskstore.StatusOpen, strPtr("parent")), "large")
+	small := withEffort(newTestTask("small", "Small", taskstore.StatusOpen, strPtr("parent")), "small")
+	medium := newTestTask("medium", "Medium", taskstore.StatusOpen, strPtr("parent"))
+	done := newTestTask("done", "Done", taskstore.StatusCompleted, strPtr("parent"))
+	tasks := []*taskstore.Task{parent, large, small, medium, done}
+
+	ctrl := NewController(ControllerDeps{})
+	ctrl.SetBudgetLimits(BudgetLimits{MaxCostUSD: 7})
+	require.NoError(t, ctrl.SetBudgetAllocation(AllocationProportional))
+
+	// large (4) of large + small + medium (4 + 1 + 2)
+	assert.Empty(t, ctrl.taskBudgetExhausted(tasks, "parent", large))
+	assert.InDelta(t, 4.0, ctrl.taskSlices["large"], 0.001)
+
+	// large finishes $3 under budget; its surplus goes to the rest
+	ctrl.budget.RecordIteration(1, 0, 0)
+	ctrl.recordTaskSpend("large", 1)
+	large.Status = taskstore.StatusCompleted
+	assert.Empty(t, ctrl.taskBudgetExhausted(tasks, "parent", small))
+	assert.InDelta(t, 2.0, ctrl.taskSlices["small"], 0.001, "6 remaining, small (1) of small + medium (1 + 2)")
+
+	// small's unspent slice stays reserved while it is unfinished
+	assert.Empty(t, ctrl.taskBudgetExhausted(tasks, "parent", medium))
+	assert.InDelta(t, 4.0, ctrl.taskSlices["medium"], 0.001)
+
+	ctrl.recordTaskSpend("small", 2)
+	assert.Equal(t, "spent $2.00 of its $2.00 budget slice", ctrl.taskBudgetExhausted(tasks, "parent", small))
+}
+
+func TestController_AllocateTaskBudget_DisabledWithoutCostLimit(t *testing.T) {
+	task := newTestTask("task", "Task", taskstore.StatusOpen, strPtr("parent"))
+	ctrl := NewController(ControllerDeps{})
+	ctrl.SetBudgetLimits(BudgetLimits{MaxIterations: 10})
+	require.NoError(t, ctrl.SetBudgetAllocation(AllocationProportional))
+	ctrl.recordTaskSpend("task", 100)
+
+	assert.Empty(t, ctrl.taskBudgetExhausted([]*taskstore.Task{task}, "parent", task))
+	assert.Empty(t, ctrl.taskSlices)
+}
+
+func TestController_RunLoop_TaskBudgetSlice(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent")))
+	store.addTask(newTestTask("task-b", "Task B", taskstore.StatusOpen, strPtr("parent")))
+
+	var progress bytes.Buffer
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done", TotalCostUSD: 0.3}},
+		Verifier: &mockVerifier{
+			results: []verifier.VerificationResult{{Passed: false, Command: []string{"go", "test"}, Output: "FAIL"}},
+		},
+		Git: &mockGitManager{
+			currentCommit: "abc123",
+			hasChanges:    true,
+			changedFiles:  []string{"f.go"},
+		},
+		LogsDir:        t.TempDir(),
+		ProgressWriter: &progress,
+	})
+	ctrl.SetBudgetLimits(BudgetLimits{MaxIterations: 10, MaxCostUSD: 1.0})
+	ctrl.SetMaxVerificationRetries(0)
+	ctrl.SetGutterConfig(GutterConfig{})
+	require.NoError(t, ctrl.SetBudgetAllocation(AllocationProportional))
+
+	result := ctrl.RunLoop(context.Background(), "parent")
+
+	// task-a gets $0.50 and stops after two $0.30 attempts instead of three
+	assert.Equal(t, taskstore.StatusFailed, store.tasks["task-a"].Status)
+	assert.Contains(t, progress.String(), "✗ Task task-a spent $0.60 of its $0.50 budget slice, marking failed")
+	attemptsA := 0
+	for _, record := range result.Records {
+		if record.TaskID == "task-a" {
+			attemptsA++
+		}
+	}
+	assert.Equal(t, 2, attemptsA)
+	assert.Contains(t, result.FailedTasks, "task-a")
+}
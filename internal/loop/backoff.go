@@ -0,0 +1,64 @@
+package loop
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// pausePollInterval is how often a retry backoff checks the pause flag.
+const pausePollInterval = 250 * time.Millisecond
+
+// SetRetryBackoff makes the loop wait before selecting a task that has
+// already failed: base before the second attempt, base*factor before the
+// third, base*factor² before the fourth and so on, so a rate-limited API is
+// not hit again straight away. A base of zero (the default) retries
+// immediately; a factor below 1 keeps the delay at base.
+func (c *Controller) SetRetryBackoff(base time.Duration, factor float64) {
+	c.retryBackoffBase = base
+	c.retryBackoffFactor = max(factor, 1)
+}
+
+// retryDelay is the backoff before the next attempt at a task that has
+// failed attempts times.
+func (c *Controller) retryDelay(attempts int) time.Duration {
+	if attempts < 1 || c.retryBackoffBase <= 0 {
+		return 0
+	}
+	delay := float64(c.retryBackoffBase) * math.Pow(c.retryBackoffFactor, float64(attempts-1))
+	if delay >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// waitBeforeRetry sleeps the backoff delay before another attempt at task. It
+// returns why the loop must stop when the context is cancelled or the loop is
+// paused during the wait, and "" otherwise.
+func (c *Controller) waitBeforeRetry(ctx context.Context, task *taskstore.Task) string {
+	attempts := c.taskAttempts[task.ID]
+	delay := c.retryDelay(attempts)
+	if delay <= 0 {
+		return ""
+	}
+	c.writeProgress("⏳ Waiting %s before attempt %d of task %s\n\n", delay, attempts+1, task.ID)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	poll := time.NewTicker(pausePollInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return "loop cancelled"
+		case <-timer.C:
+			return ""
+		case <-poll.C:
+			if c.checkPaused() {
+				return "loop paused (use 'ralph resume' to continue)"
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestController_RetryDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     time.Duration
+		factor   float64
+		attempts int
+		want     time.Duration
+	}{
+		{"first attempt", time.Second, 2, 0, 0},
+		{"second attempt waits base", time.Second, 2, 1, time.Second},
+		{"third attempt waits base times factor", time.Second, 2, 2, 2 * time.Second},
+		{"fourth attempt", time.Second, 2, 3, 4 * time.Second},
+		{"factor below 1 keeps base", time.Second, 0.5, 3, time.Second},
+		{"disabled", 0, 2, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewController(ControllerDeps{TaskStore: newMockTaskStore()})
+			c.SetRetryBackoff(tt.base, tt.factor)
+			assert.Equal(t, tt.want, c.retryDelay(tt.attempts))
+		})
+	}
+}
+
+func TestController_RunLoop_WaitsBeforeRetry(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("task-1", "Task 1", taskstore.StatusOpen, strPtr("parent")))
+
+	calls := 0
+	var progress bytes.Buffer
+	c := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude: &mockClaudeRunnerWithCallback{callbackFn: func() error {
+			calls++
+			if calls == 1 {
+				return errors.New("rate limited")
+			}
+			return nil
+		}},
+		Verifier:       &mockVerifier{results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}}},
+		Git:            &mockGitManager{currentCommit: "abc", hasChanges: true, changedFiles: []string{"f.go"}, commitHash: "def"},
+		LogsDir:        t.TempDir(),
+		ProgressWriter: &progress,
+	})
+	c.SetRetryBackoff(20*time.Millisecond, 2)
+
+	start := time.Now()
+	result := c.RunLoop(context.Background(), "parent")
+
+	assert.Equal(t, RunOutcomeCompleted, result.Outcome, result.Message)
+	assert.Equal(t, 2, result.IterationsRun)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Contains(t, progress.String(), "Waiting 20ms before attempt 2 of task task-1")
+}
+
+func TestController_WaitBeforeRetry_Interrupted(t *testing.T) {
+	task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+
+	t.Run("by cancellation", func(t *testing.T) {
+		c := NewController(ControllerDeps{TaskStore: newMockTaskStore()})
+		c.SetRetryBackoff(time.Hour, 2)
+		c.taskAttempts[task.ID] = 1
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.Equal(t, "loop cancelled", c.waitBeforeRetry(ctx, task))
+	})
+
+	t.Run("by the pause flag", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, ".ralph", "state"), 0755))
+		require.NoError(t, state.SetPaused(dir, true))
+		c := NewController(ControllerDeps{TaskStore: newMockTaskStore(), WorkDir: dir})
+		c.SetRetryBackoff(time.Hour, 2)
+		c.taskAttempts[task.ID] = 1
+
+		assert.Contains(t, c.waitBeforeRetry(context.Background(), task), "loop paused")
+	})
+}
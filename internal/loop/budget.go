@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
@@ -21,6 +22,8 @@ const (
 	BudgetReasonTime BudgetReasonCode = "time"
 	// BudgetReasonCost indicates the cost limit was exceeded.
 	BudgetReasonCost BudgetReasonCode = "cost"
+	// BudgetReasonTokens indicates an input or output token limit was exceeded.
+	BudgetReasonTokens BudgetReasonCode = "tokens"
 )
 
 // BudgetLimits defines the configurable limits for budget tracking.
@@ -36,6 +39,16 @@ type BudgetLimits struct {
 
 	// MaxMinutesPerIteration is the maximum time per iteration in minutes.
 	MaxMinutesPerIteration int `json:"max_minutes_per_iteration"`
+
+	// WarnAtFraction is the fraction of MaxCostUSD at which to warn without
+	// stopping (0 = no warning).
+	WarnAtFraction float64 `json:"warn_at_fraction,omitempty"`
+
+	// MaxInputTokens is the maximum total input tokens (0 = unlimited).
+	MaxInputTokens int `json:"max_input_tokens,omitempty"`
+
+	// MaxOutputTokens is the maximum total output tokens (0 = unlimited).
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
 }
 
 // BudgetState tracks the current budget consumption.
@@ -46,6 +59,10 @@ type BudgetState struct {
 	// TotalCostUSD is the total cost incurred so far.
 	TotalCostUSD float64 `json:"total_cost_usd"`
 
+	// InputTokens and OutputTokens are the tokens used so far.
+	InputTokens  int `json:"input_tokens,omitempty"`
+	OutputTokens int `json:"output_tokens,omitempty"`
+
 	// StartTime is when the budget tracking started.
 	StartTime time.Time `json:"start_time"`
 }
@@ -60,12 +77,23 @@ type BudgetStatus struct {
 
 	// ReasonCode identifies the specific budget limit that was exceeded.
 	ReasonCode BudgetReasonCode
+
+	// WarningCrossed indicates the cost has reached the warning threshold
+	// (WarnAtFraction of MaxCostUSD) but not the limit itself.
+	WarningCrossed bool
 }
 
-// BudgetTracker tracks budget consumption and enforces limits.
+// BudgetTracker tracks budget consumption and enforces limits. It is safe
+// for concurrent use by parallel iterations.
 type BudgetTracker struct {
+	mu     sync.Mutex
 	limits BudgetLimits
 	state  BudgetState
+
+	// path and parentID locate the persisted state Flush writes to ("" =
+	// not persisted)
+	path     string
+	parentID string
 }
 
 // DefaultBudgetLimits returns sensible default budget limits.
@@ -86,8 +114,12 @@ func NewBudgetTracker(limits BudgetLimits) *BudgetTracker {
 	}
 }
 
-// RecordIteration records a completed iteration with its cost.
-func (bt *BudgetTracker) RecordIteration(costUSD float64) {
+// RecordIteration records a completed iteration with its cost and the
+// tokens it used.
+func (bt *BudgetTracker) RecordIteration(costUSD float64, inputTokens, outputTokens int) {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
 	// Set start time on first iteration
 	if bt.state.StartTime.IsZero() {
 		bt.state.StartTime = time.Now()
@@ -95,11 +127,29 @@ func (bt *BudgetTracker) RecordIteration(costUSD float64) {
 
 	bt.state.Iterations++
 	bt.state.TotalCostUSD += costUSD
+	bt.state.InputTokens += inputTokens
+	bt.state.OutputTokens += outputTokens
+}
+
+// RecordCost adds the cost of an agent call that is not an iteration, such
+// as splitting a failed task, without counting it against the iteration
+// limit.
+func (bt *BudgetTracker) RecordCost(costUSD float64) {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	if bt.state.StartTime.IsZero() {
+		bt.state.StartTime = time.Now()
+	}
+	bt.state.TotalCostUSD += costUSD
 }
 
 // CheckBudget checks if the current budget consumption is within limits.
 // Returns a BudgetStatus indicating whether the loop can continue.
 func (bt *BudgetTracker) CheckBudget() BudgetStatus {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
 	// Check iteration limit
 	if bt.limits.MaxIterations > 0 && bt.state.Iterations >= bt.limits.MaxIterations {
 		return BudgetStatus{
@@ -131,25 +181,57 @@ func (bt *BudgetTracker) CheckBudget() BudgetStatus {
 		}
 	}
 
+	// Check token limits
+	if bt.limits.MaxInputTokens > 0 && bt.state.InputTokens >= bt.limits.MaxInputTokens {
+		return BudgetStatus{
+			CanContinue: false,
+			Reason:      fmt.Sprintf("max input token limit reached (%d/%d)", bt.state.InputTokens, bt.limits.MaxInputTokens),
+			ReasonCode:  BudgetReasonTokens,
+		}
+	}
+	if bt.limits.MaxOutputTokens > 0 && bt.state.OutputTokens >= bt.limits.MaxOutputTokens {
+		return BudgetStatus{
+			CanContinue: false,
+			Reason:      fmt.Sprintf("max output token limit reached (%d/%d)", bt.state.OutputTokens, bt.limits.MaxOutputTokens),
+			ReasonCode:  BudgetReasonTokens,
+		}
+	}
+
 	return BudgetStatus{
-		CanContinue: true,
-		Reason:      "",
-		ReasonCode:  BudgetReasonNone,
+		CanContinue:    true,
+		Reason:         "",
+		ReasonCode:     BudgetReasonNone,
+		WarningCrossed: bt.limits.WarnAtFraction > 0 && bt.limits.MaxCostUSD > 0 && bt.state.TotalCostUSD >= bt.limits.WarnAtFraction*bt.limits.MaxCostUSD,
 	}
 }
 
+// CostWarning describes spending against the cost limit, for the warning
+// shown when WarningCrossed is set.
+func (bt *BudgetTracker) CostWarning() string {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+	return fmt.Sprintf("spent $%.2f of the $%.2f cost limit (warning at %.0f%%)",
+		bt.state.TotalCostUSD, bt.limits.MaxCostUSD, bt.limits.WarnAtFraction*100)
+}
+
 // GetState returns a copy of the current budget state.
 func (bt *BudgetTracker) GetState() BudgetState {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
 	return bt.state
 }
 
 // SetState sets the budget state (used for loading persisted state).
 func (bt *BudgetTracker) SetState(state BudgetState) {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
 	bt.state = state
 }
 
 // Reset resets the budget state for a new run.
 func (bt *BudgetTracker) Reset() {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
 	bt.state = BudgetState{
 		StartTime: time.Now(),
 	}
@@ -157,6 +239,8 @@ func (bt *BudgetTracker) Reset() {
 
 // ElapsedTime returns the time elapsed since the budget tracking started.
 func (bt *BudgetTracker) ElapsedTime() time.Duration {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
 	if bt.state.StartTime.IsZero() {
 		return 0
 	}
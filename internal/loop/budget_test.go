@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -77,16 +78,41 @@ func TestBudgetTracker_RecordIteration(t *testing.T) {
 	tracker := NewBudgetTracker(limits)
 
 	// Record first iteration
-	tracker.RecordIteration(0.5)
+	tracker.RecordIteration(0.5, 0, 0)
 	assert.Equal(t, 1, tracker.state.Iterations)
 	assert.Equal(t, 0.5, tracker.state.TotalCostUSD)
 
 	// Record second iteration
-	tracker.RecordIteration(0.75)
+	tracker.RecordIteration(0.75, 0, 0)
 	assert.Equal(t, 2, tracker.state.Iterations)
 	assert.Equal(t, 1.25, tracker.state.TotalCostUSD)
 }
 
+func TestBudgetTracker_RecordCost(t *testing.T) {
+	tracker := NewBudgetTracker(DefaultBudgetLimits())
+
+	tracker.RecordIteration(0.5, 0, 0)
+	tracker.RecordCost(0.25)
+
+	state := tracker.GetState()
+	assert.Equal(t, 1, state.Iterations)
+	assert.Equal(t, 0.75, state.TotalCostUSD)
+}
+
+func TestBudgetTracker_RecordIteration_Concurrent(t *testing.T) {
+	tracker := NewBudgetTracker(DefaultBudgetLimits())
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() { tracker.RecordIteration(0.5, 0, 0) })
+	}
+	wg.Wait()
+
+	state := tracker.GetState()
+	assert.Equal(t, 50, state.Iterations)
+	assert.InDelta(t, 25.0, state.TotalCostUSD, 1e-9)
+}
+
 func TestBudgetTracker_CheckBudget_UnderAllLimits(t *testing.T) {
 	limits := BudgetLimits{
 		MaxIterations:  10,
@@ -151,6 +177,71 @@ func TestBudgetTracker_CheckBudget_CostExceeded(t *testing.T) {
 	assert.Equal(t, BudgetReasonCost, status.ReasonCode)
 }
 
+func TestBudgetTracker_CheckBudget_TokensExceeded(t *testing.T) {
+	tests := []struct {
+		name         string
+		limits       BudgetLimits
+		inputTokens  int
+		outputTokens int
+		canContinue  bool
+		reason       string
+	}{
+		{"under both limits", BudgetLimits{MaxInputTokens: 1000, MaxOutputTokens: 100}, 999, 99, true, ""},
+		{"input limit reached", BudgetLimits{MaxInputTokens: 1000, MaxOutputTokens: 100}, 1000, 10, false, "max input token limit reached (1000/1000)"},
+		{"output limit reached", BudgetLimits{MaxInputTokens: 1000, MaxOutputTokens: 100}, 10, 150, false, "max output token limit reached (150/100)"},
+		{"zero limits are unlimited", BudgetLimits{}, 1_000_000, 1_000_000, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewBudgetTracker(tt.limits)
+			tracker.RecordIteration(0.1, tt.inputTokens, tt.outputTokens)
+
+			status := tracker.CheckBudget()
+
+			assert.Equal(t, tt.canContinue, status.CanContinue)
+			assert.Equal(t, tt.reason, status.Reason)
+			if !tt.canContinue {
+				assert.Equal(t, BudgetReasonTokens, status.ReasonCode)
+			}
+		})
+	}
+}
+
+func TestBudgetTracker_CheckBudget_WarningCrossed(t *testing.T) {
+	tests := []struct {
+		name        string
+		warnAt      float64
+		maxCost     float64
+		cost        float64
+		wantWarning bool
+	}{
+		{name: "below threshold", warnAt: 0.8, maxCost: 10.0, cost: 7.99, wantWarning: false},
+		{name: "at threshold", warnAt: 0.8, maxCost: 10.0, cost: 8.0, wantWarning: true},
+		{name: "no threshold", warnAt: 0, maxCost: 10.0, cost: 9.0, wantWarning: false},
+		{name: "no cost limit", warnAt: 0.8, maxCost: 0, cost: 100.0, wantWarning: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewBudgetTracker(BudgetLimits{MaxCostUSD: tt.maxCost, WarnAtFraction: tt.warnAt})
+			tracker.state.TotalCostUSD = tt.cost
+
+			status := tracker.CheckBudget()
+
+			assert.True(t, status.CanContinue)
+			assert.Equal(t, tt.wantWarning, status.WarningCrossed)
+		})
+	}
+}
+
+func TestBudgetTracker_CostWarning(t *testing.T) {
+	tracker := NewBudgetTracker(BudgetLimits{MaxCostUSD: 10.0, WarnAtFraction: 0.8})
+	tracker.state.TotalCostUSD = 8.5
+
+	assert.Equal(t, "spent $8.50 of the $10.00 cost limit (warning at 80%)", tracker.CostWarning())
+}
+
 func TestBudgetTracker_CheckBudget_ZeroLimits_Unlimited(t *testing.T) {
 	// Zero values mean unlimited
 	limits := BudgetLimits{
@@ -172,8 +263,8 @@ func TestBudgetTracker_CheckBudget_ZeroLimits_Unlimited(t *testing.T) {
 func TestBudgetTracker_GetState(t *testing.T) {
 	limits := DefaultBudgetLimits()
 	tracker := NewBudgetTracker(limits)
-	tracker.RecordIteration(1.0)
-	tracker.RecordIteration(2.0)
+	tracker.RecordIteration(1.0, 0, 0)
+	tracker.RecordIteration(2.0, 0, 0)
 
 	state := tracker.GetState()
 
@@ -201,8 +292,8 @@ func TestBudgetTracker_SetState(t *testing.T) {
 func TestBudgetTracker_Reset(t *testing.T) {
 	limits := DefaultBudgetLimits()
 	tracker := NewBudgetTracker(limits)
-	tracker.RecordIteration(5.0)
-	tracker.RecordIteration(5.0)
+	tracker.RecordIteration(5.0, 0, 0)
+	tracker.RecordIteration(5.0, 0, 0)
 
 	tracker.Reset()
 
@@ -313,6 +404,7 @@ func TestBudgetReasonCode_Values(t *testing.T) {
 	assert.Equal(t, BudgetReasonCode("iterations"), BudgetReasonIterations)
 	assert.Equal(t, BudgetReasonCode("time"), BudgetReasonTime)
 	assert.Equal(t, BudgetReasonCode("cost"), BudgetReasonCost)
+	assert.Equal(t, BudgetReasonCode("tokens"), BudgetReasonTokens)
 }
 
 func TestBudgetTracker_EnsureStartTime(t *testing.T) {
@@ -323,14 +415,14 @@ func TestBudgetTracker_EnsureStartTime(t *testing.T) {
 	assert.True(t, tracker.state.StartTime.IsZero())
 
 	// First record should set start time
-	tracker.RecordIteration(1.0)
+	tracker.RecordIteration(1.0, 0, 0)
 	assert.False(t, tracker.state.StartTime.IsZero())
 
 	startTime := tracker.state.StartTime
 
 	// Subsequent records should not change start time
 	time.Sleep(10 * time.Millisecond)
-	tracker.RecordIteration(1.0)
+	tracker.RecordIteration(1.0, 0, 0)
 	assert.Equal(t, startTime, tracker.state.StartTime)
 }
 
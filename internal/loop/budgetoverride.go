@@ -0,0 +1,111 @@
+package loop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yarlson/ralph/internal/state"
+)
+
+// BudgetOverride holds budget limits changed while a run is in progress
+// (see `ralph budget set`). Nil fields keep the run's own limit.
+type BudgetOverride struct {
+	// MaxCostUSD replaces the cost limit (0 = unlimited).
+	MaxCostUSD *float64 `json:"max_cost_usd,omitempty"`
+
+	// MaxIterations replaces the iteration limit (0 = unlimited).
+	MaxIterations *int `json:"max_iterations,omitempty"`
+
+	// UpdatedAt is when the override was last written.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SaveBudgetOverride writes the budget override to path.
+func SaveBudgetOverride(path string, override *BudgetOverride) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	data, err := json.MarshalIndent(override, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal budget override: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write budget override: %w", err)
+	}
+	return nil
+}
+
+// LoadBudgetOverride reads the budget override at path. It returns nil (not
+// an error) if there is none.
+func LoadBudgetOverride(path string) (*BudgetOverride, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget override: %w", err)
+	}
+	var override BudgetOverride
+	if err := json.Unmarshal(data, &override); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal budget override: %w", err)
+	}
+	return &override, nil
+}
+
+// ClearBudgetOverride removes the budget override at path, if any, so a new
+// run starts from its own limits.
+func ClearBudgetOverride(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove budget override: %w", err)
+	}
+	return nil
+}
+
+// applyBudgetOverride picks up limits changed with `ralph budget set` since
+// the last budget check. A changed cost limit re-arms the budget warning and
+// recomputes the per-task budget slices.
+func (c *Controller) applyBudgetOverride() {
+	if c.workDir == "" {
+		return
+	}
+	override, err := LoadBudgetOverride(state.BudgetOverrideFilePath(c.workDir))
+	if err != nil {
+		c.writeProgress("⚠ %v\n", err)
+		return
+	}
+	if override == nil {
+		return
+	}
+
+	limits := &c.budget.limits
+	if override.MaxCostUSD != nil && *override.MaxCostUSD != limits.MaxCostUSD {
+		c.writeProgress("💰 Cost limit changed: %s → %s\n", FormatCostLimit(limits.MaxCostUSD), FormatCostLimit(*override.MaxCostUSD))
+		limits.MaxCostUSD = *override.MaxCostUSD
+		c.budgetWarned = false
+		c.taskSlices = nil
+	}
+	if override.MaxIterations != nil && *override.MaxIterations != limits.MaxIterations {
+		c.writeProgress("💰 Iteration limit changed: %s → %s\n", FormatIterationLimit(limits.MaxIterations), FormatIterationLimit(*override.MaxIterations))
+		limits.MaxIterations = *override.MaxIterations
+	}
+}
+
+// FormatCostLimit formats a cost limit, where 0 means unlimited.
+func FormatCostLimit(usd float64) string {
+	if usd <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
+
+// FormatIterationLimit formats an iteration limit, where 0 means unlimited.
+func FormatIterationLimit(n int) string {
+	if n <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d", n)
+}
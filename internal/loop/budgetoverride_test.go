@@ -0,0 +1,102 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestBudgetOverride_SaveLoadClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "budget-override.json")
+
+	override, err := LoadBudgetOverride(path)
+	require.NoError(t, err)
+	assert.Nil(t, override)
+
+	cost := 10.0
+	require.NoError(t, SaveBudgetOverride(path, &BudgetOverride{MaxCostUSD: &cost}))
+
+	override, err = LoadBudgetOverride(path)
+	require.NoError(t, err)
+	require.NotNil(t, override.MaxCostUSD)
+	assert.Equal(t, 10.0, *override.MaxCostUSD)
+	assert.Nil(t, override.MaxIterations)
+
+	require.NoError(t, ClearBudgetOverride(path))
+	require.NoError(t, ClearBudgetOverride(path))
+	assert.NoFileExists(t, path)
+}
+
+func TestController_ApplyBudgetOverride(t *testing.T) {
+	workDir := t.TempDir()
+	var progress bytes.Buffer
+	ctrl := NewController(ControllerDeps{WorkDir: workDir, ProgressWriter: &progress})
+	ctrl.SetBudgetLimits(BudgetLimits{MaxCostUSD: 5, MaxIterations: 10, WarnAtFraction: 0.8})
+	ctrl.budgetWarned = true
+
+	ctrl.applyBudgetOverride()
+	assert.Equal(t, 5.0, ctrl.budget.limits.MaxCostUSD)
+
+	cost, iterations := 10.0, 0
+	require.NoError(t, SaveBudgetOverride(state.BudgetOverrideFilePath(workDir), &BudgetOverride{MaxCostUSD: &cost, MaxIterations: &iterations}))
+	ctrl.applyBudgetOverride()
+
+	assert.Equal(t, 10.0, ctrl.budget.limits.MaxCostUSD)
+	assert.Equal(t, 0, ctrl.budget.limits.MaxIterations)
+	assert.False(t, ctrl.budgetWarned)
+	assert.Contains(t, progress.String(), "Cost limit changed: $5.00 → $10.00")
+	assert.Contains(t, progress.String(), "Iteration limit changed: 10 → unlimited")
+
+	progress.Reset()
+	ctrl.applyBudgetOverride()
+	assert.Empty(t, progress.String(), "an unchanged override is not reported again")
+}
+
+func TestController_RunLoop_BudgetRaisedMidRun(t *testing.T) {
+	workDir := t.TempDir()
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+	for _, id := range []string{"a", "b", "c", "d"} {
+		store.addTask(newTestTask("child-"+id, "Child "+id, taskstore.StatusOpen, strPtr("parent")))
+	}
+
+	ctrl := NewController(ControllerDeps{
+		TaskStore:   store,
+		Claude:      &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"}},
+		Verifier:    &mockVerifier{results: []verifier.VerificationResult{{Passed: true, Command: []string{"echo"}}}},
+		Git:         &mockGitManager{currentCommit: "abc", hasChanges: true, changedFiles: []string{"f.go"}, commitHash: "def"},
+		LogsDir:     t.TempDir(),
+		ProgressDir: t.TempDir(),
+		WorkDir:     workDir,
+	})
+	ctrl.SetBudgetLimits(BudgetLimits{MaxIterations: 1})
+	iterations := 3
+	ctrl.SetEventSink(&overrideOnIteration{path: state.BudgetOverrideFilePath(workDir), override: &BudgetOverride{MaxIterations: &iterations}})
+
+	result := ctrl.RunLoop(context.Background(), "parent")
+
+	assert.Equal(t, RunOutcomeBudgetExceeded, result.Outcome)
+	assert.Equal(t, 3, result.IterationsRun)
+}
+
+// overrideOnIteration writes a budget override when the first iteration
+// finishes, as `ralph budget set` would during a run.
+type overrideOnIteration struct {
+	path     string
+	override *BudgetOverride
+}
+
+func (s *overrideOnIteration) Emit(event Event) {
+	if event.Type == EventIterationFinished {
+		_ = SaveBudgetOverride(s.path, s.override)
+	}
+}
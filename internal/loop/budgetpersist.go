@@ -0,0 +1,116 @@
+package loop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yarlson/ralph/internal/atomicfile"
+	"github.com/yarlson/ralph/internal/state"
+)
+
+// NewBudgetTrackerFromState creates a budget tracker that continues from the
+// iterations, cost and tokens persisted for parentID in the state directory
+// dir, so separate runs (such as a series of --once runs) share one budget.
+// Flush writes the totals back. The time limit still applies per run.
+func NewBudgetTrackerFromState(dir, parentID string, limits BudgetLimits) (*BudgetTracker, error) {
+	path := filepath.Join(dir, state.Budget)
+	persisted, err := loadBudgetStates(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tracker := NewBudgetTracker(limits)
+	tracker.path = path
+	tracker.parentID = parentID
+	if prior, ok := persisted[parentID]; ok {
+		tracker.state = BudgetState{
+			Iterations:   prior.Iterations,
+			TotalCostUSD: prior.TotalCostUSD,
+			InputTokens:  prior.InputTokens,
+			OutputTokens: prior.OutputTokens,
+		}
+	}
+	return tracker, nil
+}
+
+// Flush writes the tracker's totals to the file it was loaded from. It does
+// nothing for a tracker not created by NewBudgetTrackerFromState.
+func (bt *BudgetTracker) Flush() error {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	if bt.path == "" {
+		return nil
+	}
+	persisted, err := loadBudgetStates(bt.path)
+	if err != nil {
+		return err
+	}
+	persisted[bt.parentID] = bt.state
+	return saveBudgetStates(bt.path, persisted)
+}
+
+// LoadPersistedBudget returns the budget totals persisted for parentID in
+// the state directory dir, or nil if there are none.
+func LoadPersistedBudget(dir, parentID string) (*BudgetState, error) {
+	persisted, err := loadBudgetStates(filepath.Join(dir, state.Budget))
+	if err != nil {
+		return nil, err
+	}
+	prior, ok := persisted[parentID]
+	if !ok {
+		return nil, nil
+	}
+	return &prior, nil
+}
+
+// ResetPersistedBudget removes the budget totals persisted for parentID in
+// the state directory dir, so the next run starts from zero.
+func ResetPersistedBudget(dir, parentID string) error {
+	path := filepath.Join(dir, state.Budget)
+	persisted, err := loadBudgetStates(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := persisted[parentID]; !ok {
+		return nil
+	}
+	delete(persisted, parentID)
+	return saveBudgetStates(path, persisted)
+}
+
+// loadBudgetStates reads the persisted budget state of each parent task. A
+// missing file yields an empty map.
+func loadBudgetStates(path string) (map[string]BudgetState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]BudgetState), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget state: %w", err)
+	}
+
+	persisted := make(map[string]BudgetState)
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal budget state: %w", err)
+	}
+	return persisted, nil
+}
+
+// saveBudgetStates writes the persisted budget state of each parent task.
+func saveBudgetStates(path string, persisted map[string]BudgetState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	data, err := json.MarshalIndent(persisted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal budget state: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write budget state: %w", err)
+	}
+	return nil
+}
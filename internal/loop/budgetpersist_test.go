@@ -0,0 +1,83 @@
+package loop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestNewBudgetTrackerFromState(t *testing.T) {
+	dir := t.TempDir()
+
+	tracker, err := NewBudgetTrackerFromState(dir, "feature", BudgetLimits{})
+	require.NoError(t, err)
+	assert.Equal(t, BudgetState{}, tracker.GetState())
+
+	tracker.RecordIteration(1.5, 1000, 200)
+	require.NoError(t, tracker.Flush())
+	other, err := NewBudgetTrackerFromState(dir, "other", BudgetLimits{})
+	require.NoError(t, err)
+	other.RecordIteration(9, 1, 1)
+	require.NoError(t, other.Flush())
+
+	resumed, err := NewBudgetTrackerFromState(dir, "feature", BudgetLimits{})
+	require.NoError(t, err)
+	state := resumed.GetState()
+	assert.Equal(t, 1, state.Iterations)
+	assert.InDelta(t, 1.5, state.TotalCostUSD, 1e-9)
+	assert.Equal(t, 1000, state.InputTokens)
+	assert.Equal(t, 200, state.OutputTokens)
+	assert.True(t, state.StartTime.IsZero(), "the time limit applies per run")
+
+	require.NoError(t, ResetPersistedBudget(dir, "feature"))
+	spent, err := LoadPersistedBudget(dir, "feature")
+	require.NoError(t, err)
+	assert.Nil(t, spent)
+	spent, err = LoadPersistedBudget(dir, "other")
+	require.NoError(t, err)
+	require.NotNil(t, spent)
+	assert.InDelta(t, 9.0, spent.TotalCostUSD, 1e-9)
+}
+
+func TestBudgetTracker_Flush_NotPersisted(t *testing.T) {
+	tracker := NewBudgetTracker(DefaultBudgetLimits())
+	tracker.RecordIteration(1, 0, 0)
+	assert.NoError(t, tracker.Flush())
+}
+
+func TestController_RunOnce_AccumulatesPersistedBudget(t *testing.T) {
+	stateDir := t.TempDir()
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	for _, id := range []string{"task-a", "task-b", "task-c"} {
+		store.addTask(newTestTask(id, id, taskstore.StatusOpen, strPtr("parent")))
+	}
+
+	runOnce := func() RunResult {
+		ctrl := NewController(ControllerDeps{
+			TaskStore: store,
+			Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done", TotalCostUSD: 3}},
+			Verifier:  &mockVerifier{results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}}},
+			Git:       &mockGitManager{currentCommit: "abc", hasChanges: true, changedFiles: []string{"f.go"}, commitHash: "def"},
+			LogsDir:   t.TempDir(),
+		})
+		tracker, err := NewBudgetTrackerFromState(stateDir, "parent", BudgetLimits{MaxCostUSD: 5})
+		require.NoError(t, err)
+		ctrl.SetBudgetTracker(tracker)
+		return ctrl.RunOnce(context.Background(), "parent")
+	}
+
+	assert.Equal(t, RunOutcomeCompleted, runOnce().Outcome)
+	assert.Equal(t, RunOutcomeCompleted, runOnce().Outcome)
+
+	third := runOnce()
+	assert.Equal(t, RunOutcomeBudgetExceeded, third.Outcome)
+	assert.Equal(t, "max cost limit exceeded ($6.00/$5.00)", third.Message)
+	assert.Zero(t, third.IterationsRun)
+}
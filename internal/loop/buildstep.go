@@ -0,0 +1,90 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// buildFailedNote tells the agent why the task's verify commands produced no output.
+const buildFailedNote = "The build failed, so the task's verify commands were not run. Fix the build first."
+
+// SetBuildFirst sets a build command run before the task's verify commands.
+// When it fails, the verify commands are skipped and the failure is reported
+// as a build failure rather than a test failure. An empty command disables it.
+func (c *Controller) SetBuildFirst(command []string) {
+	c.buildFirst = command
+}
+
+// SetVerifySets sets the named verify command lists that tasks reference with
+// verifySet. A task's set runs before its own verify commands.
+func (c *Controller) SetVerifySets(sets map[string][][]string) {
+	c.verifySets = sets
+}
+
+// exitCodeVerifier is a verifier that can interpret the exit codes of
+// specific commands.
+type exitCodeVerifier interface {
+	WithExitCodes(codes verifier.ExitCodes) verifier.Verifier
+}
+
+// taskVerifier returns the verifier for task's verify commands: c.verifier,
+// with the task's verifyExitCodes mappings applied if it has any.
+func (c *Controller) taskVerifier(task *taskstore.Task) (verifier.Verifier, error) {
+	codes := make(verifier.ExitCodes)
+	for i, raw := range task.VerifyExitCodes {
+		if len(raw) == 0 || i >= len(task.Verify) {
+			continue
+		}
+		mapping, err := verifier.ParseExitCodeMapping(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verifyExitCodes for %v in task %s: %w", task.Verify[i], task.ID, err)
+		}
+		codes.Set(task.Verify[i], mapping)
+	}
+	v, ok := c.verifier.(exitCodeVerifier)
+	if len(codes) == 0 || !ok {
+		return c.verifier, nil
+	}
+	return v.WithExitCodes(codes), nil
+}
+
+// runVerification runs the build step, if configured, followed by the task's
+// verify commands, both through v. If the build fails, only its result is
+// returned and buildFailed is true.
+func (c *Controller) runVerification(ctx context.Context, v verifier.Verifier, verifyCommands [][]string) (results []verifier.VerificationResult, buildFailed bool, err error) {
+	if len(c.buildFirst) > 0 {
+		buildResults, err := c.verify(ctx, v, [][]string{c.buildFirst})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, r := range buildResults {
+			if !r.Passed {
+				return buildResults, true, nil
+			}
+		}
+		results = append(results, buildResults...)
+	}
+
+	testResults, err := c.verify(ctx, v, verifyCommands)
+	if err != nil {
+		return nil, false, err
+	}
+	return append(results, testResults...), false, nil
+}
+
+// formatBuildFeedback formats a build step failure for retry feedback.
+func (c *Controller) formatBuildFeedback(results []verifier.VerificationResult) string {
+	var feedback strings.Builder
+	feedback.WriteString("Build failed:\n")
+	for _, r := range results {
+		if !r.Passed {
+			fmt.Fprintf(&feedback, "\nCommand: %v\nOutput:\n%s\n", r.Command, r.Output)
+		}
+	}
+	feedback.WriteString("\n" + buildFailedNote + "\n")
+	return feedback.String()
+}
@@ -0,0 +1,241 @@
+package loop
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestController_RunIteration_BuildFirst(t *testing.T) {
+	build := []string{"go", "build", "./..."}
+	test := []string{"go", "test", "./..."}
+
+	tests := []struct {
+		name         string
+		buildFirst   []string
+		buildPasses  bool
+		wantCommands [][]string
+		wantFeedback string
+	}{
+		{
+			name:         "runs only task commands when unset",
+			buildFirst:   nil,
+			wantCommands: [][]string{test},
+			wantFeedback: "Verification failed:",
+		},
+		{
+			name:         "runs task commands after a passing build",
+			buildFirst:   build,
+			buildPasses:  true,
+			wantCommands: [][]string{build, test},
+			wantFeedback: "Verification failed:",
+		},
+		{
+			name:         "skips task commands when the build fails",
+			buildFirst:   build,
+			buildPasses:  false,
+			wantCommands: [][]string{build},
+			wantFeedback: "Build failed:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+			task.Verify = [][]string{test}
+			store.addTask(task)
+
+			var ran [][]string
+			verifierMock := &mockVerifier{
+				verifyFn: func(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+					var results []verifier.VerificationResult
+					for _, cmd := range commands {
+						ran = append(ran, cmd)
+						passed := slices.Equal(cmd, build) && tt.buildPasses
+						results = append(results, verifier.VerificationResult{Passed: passed, Command: cmd, Output: "output"})
+					}
+					return results, nil
+				},
+			}
+
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &sessionSequenceRunner{},
+				Verifier:  verifierMock,
+				Git: &mockGitManager{
+					currentCommit: "abc123",
+					hasChanges:    true,
+					changedFiles:  []string{"file1.go"},
+				},
+				LogsDir: t.TempDir(),
+			})
+			ctrl.SetMaxVerificationRetries(0)
+			ctrl.SetBuildFirst(tt.buildFirst)
+
+			record := ctrl.runIteration(context.Background(), task)
+
+			assert.Equal(t, tt.wantCommands, ran)
+			assert.Equal(t, OutcomeFailed, record.Outcome)
+			assert.Contains(t, record.Feedback, tt.wantFeedback)
+		})
+	}
+}
+
+func TestController_RunIteration_BuildFailureRetryPrompt(t *testing.T) {
+	build := []string{"go", "build", "./..."}
+
+	store := newMockTaskStore()
+	task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+	task.Verify = [][]string{{"go", "test", "./..."}}
+	store.addTask(task)
+
+	// The build fails once, then everything passes
+	builds := 0
+	verifierMock := &mockVerifier{
+		verifyFn: func(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+			var results []verifier.VerificationResult
+			for _, cmd := range commands {
+				passed := true
+				if slices.Equal(cmd, build) {
+					builds++
+					passed = builds > 1
+				}
+				results = append(results, verifier.VerificationResult{Passed: passed, Command: cmd, Output: "undefined: Foo"})
+			}
+			return results, nil
+		},
+	}
+
+	claudeRunner := &sessionSequenceRunner{}
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    claudeRunner,
+		Verifier:  verifierMock,
+		Git: &mockGitManager{
+			currentCommit: "abc123",
+			hasChanges:    true,
+			changedFiles:  []string{"file1.go"},
+			commitHash:    "def456",
+		},
+		LogsDir: t.TempDir(),
+	})
+	ctrl.SetBuildFirst(build)
+
+	record := ctrl.runIteration(context.Background(), task)
+
+	require.Len(t, claudeRunner.calls, 2)
+	assert.Contains(t, claudeRunner.calls[1].Prompt, buildFailedNote)
+	assert.Contains(t, claudeRunner.calls[1].Prompt, "undefined: Foo")
+	assert.Equal(t, OutcomeSuccess, record.Outcome)
+	assert.Len(t, record.VerificationOutputs, 2)
+}
+
+func TestController_RunIteration_VerifySet(t *testing.T) {
+	sets := map[string][][]string{"go": {{"go", "test", "./..."}, {"go", "vet", "./..."}}}
+
+	newController := func(task *taskstore.Task, runner *sessionSequenceRunner, ran *[][]string) *Controller {
+		store := newMockTaskStore()
+		store.addTask(task)
+		ctrl := NewController(ControllerDeps{
+			TaskStore: store,
+			Claude:    runner,
+			Verifier: &mockVerifier{
+				verifyFn: func(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+					var results []verifier.VerificationResult
+					for _, cmd := range commands {
+						*ran = append(*ran, cmd)
+						results = append(results, verifier.VerificationResult{Passed: true, Command: cmd})
+					}
+					return results, nil
+				},
+			},
+			Git: &mockGitManager{
+				currentCommit: "abc123",
+				hasChanges:    true,
+				changedFiles:  []string{"file1.go"},
+				commitHash:    "def456",
+			},
+			LogsDir: t.TempDir(),
+		})
+		ctrl.SetVerifySets(sets)
+		return ctrl
+	}
+
+	t.Run("runs the set before the task's own commands", func(t *testing.T) {
+		task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+		task.VerifySet = "go"
+		task.Verify = [][]string{{"make", "lint"}}
+		runner := &sessionSequenceRunner{}
+		var ran [][]string
+
+		record := newController(task, runner, &ran).runIteration(context.Background(), task)
+
+		require.Equal(t, OutcomeSuccess, record.Outcome, record.Feedback)
+		assert.Equal(t, [][]string{{"go", "test", "./..."}, {"go", "vet", "./..."}, {"make", "lint"}}, ran)
+		require.Len(t, runner.calls, 1)
+		assert.Contains(t, runner.calls[0].Prompt, "go vet ./...")
+	})
+
+	t.Run("fails without invoking the agent when the set is unknown", func(t *testing.T) {
+		task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+		task.VerifySet = "rust"
+		runner := &sessionSequenceRunner{}
+		var ran [][]string
+
+		record := newController(task, runner, &ran).runIteration(context.Background(), task)
+
+		assert.Equal(t, OutcomeFailed, record.Outcome)
+		assert.Contains(t, record.Feedback, `unknown verify set "rust"`)
+		assert.Empty(t, runner.calls)
+		assert.Empty(t, ran)
+	})
+}
+
+func TestController_RunIteration_VerifyExitCodes(t *testing.T) {
+	lint := []string{"sh", "-c", "exit 2 # lint"}
+	vet := []string{"sh", "-c", "exit 2 # vet"}
+
+	tests := []struct {
+		name        string
+		codes       []map[string]string
+		wantOutcome IterationOutcome
+		wantCalls   int
+	}{
+		{name: "unmapped exit code fails", wantOutcome: OutcomeFailed, wantCalls: 1},
+		{name: "each command has its own mapping", codes: []map[string]string{{"2": "warn"}, {"2": "skip"}}, wantOutcome: OutcomeSuccess, wantCalls: 1},
+		{name: "a mapping applies only to its command", codes: []map[string]string{{"2": "warn"}}, wantOutcome: OutcomeFailed, wantCalls: 1},
+		{name: "an invalid mapping fails before invoking the agent", codes: []map[string]string{{"2": "maybe"}}, wantOutcome: OutcomeFailed, wantCalls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+			task.Verify = [][]string{lint, vet}
+			task.VerifyExitCodes = tt.codes
+			store.addTask(task)
+
+			claudeRunner := &sessionSequenceRunner{}
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    claudeRunner,
+				Verifier:  verifier.NewCommandRunner(t.TempDir()),
+				Git:       &mockGitManager{currentCommit: "abc123", hasChanges: true, changedFiles: []string{"file1.go"}},
+				LogsDir:   t.TempDir(),
+			})
+			ctrl.SetMaxVerificationRetries(0)
+
+			record := ctrl.runIteration(context.Background(), task)
+
+			assert.Equal(t, tt.wantOutcome, record.Outcome)
+			assert.Len(t, claudeRunner.calls, tt.wantCalls)
+		})
+	}
+}
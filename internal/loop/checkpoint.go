@@ -0,0 +1,79 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// CheckpointMarker is the line prefix the agent uses to report a completed sub-goal.
+const CheckpointMarker = "RALPH_CHECKPOINT:"
+
+// ParseCheckpoint returns the description from the last checkpoint marker line in text.
+// Returns false if text contains no checkpoint marker.
+func ParseCheckpoint(text string) (string, bool) {
+	lines := strings.Split(text, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if desc, ok := strings.CutPrefix(line, CheckpointMarker); ok {
+			return strings.TrimSpace(desc), true
+		}
+	}
+	return "", false
+}
+
+// checkpoint commits intermediate progress when checkpoints are enabled and the
+// agent reported a completed sub-goal in finalText. Committed files are added
+// to the record's file changes and the commit to record.CheckpointCommits.
+func (c *Controller) checkpoint(ctx context.Context, task *taskstore.Task, record *IterationRecord, finalText string) {
+	if !c.checkpointsEnabled || c.commitPerRun {
+		return
+	}
+
+	desc, ok := ParseCheckpoint(finalText)
+	if !ok {
+		return
+	}
+
+	hasChanges, err := c.gitManager.HasChanges(ctx)
+	if err != nil || !hasChanges {
+		return
+	}
+
+	changes, _ := c.gitManager.GetFileChanges(ctx)
+
+	title := task.Title + " (checkpoint)"
+	if desc != "" {
+		title = fmt.Sprintf("%s (checkpoint: %s)", task.Title, desc)
+	}
+	commitMsg := c.commitMessage(task, title, record.IterationID)
+
+	commitHash, err := c.gitManager.Commit(ctx, commitMsg)
+	if err != nil {
+		c.writeProgress("  ⚠ Checkpoint commit failed: %v\n", err)
+		return
+	}
+
+	record.AddFileChanges(changes)
+	record.CheckpointCommits = append(record.CheckpointCommits, commitHash)
+	c.writeProgress("  💾 Checkpoint: %s\n", commitHash)
+}
+
+// mergeFileLists returns a followed by the entries of b not already in a.
+func mergeFileLists(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	merged := make([]string, 0, len(a)+len(b))
+	for _, f := range a {
+		seen[f] = true
+		merged = append(merged, f)
+	}
+	for _, f := range b {
+		if !seen[f] {
+			seen[f] = true
+			merged = append(merged, f)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,195 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestParseCheckpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantDesc string
+		wantOK   bool
+	}{
+		{name: "no marker", text: "Implemented the parser.", wantOK: false},
+		{name: "marker with description", text: "Done with step one.\nRALPH_CHECKPOINT: parser implemented\n", wantDesc: "parser implemented", wantOK: true},
+		{name: "marker without description", text: "RALPH_CHECKPOINT:", wantDesc: "", wantOK: true},
+		{name: "indented marker", text: "  RALPH_CHECKPOINT:  tests added  ", wantDesc: "tests added", wantOK: true},
+		{name: "last marker wins", text: "RALPH_CHECKPOINT: first\nmore work\nRALPH_CHECKPOINT: second", wantDesc: "second", wantOK: true},
+		{name: "marker mid-line ignored", text: "I will print RALPH_CHECKPOINT: later", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, ok := ParseCheckpoint(tt.text)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantDesc, desc)
+		})
+	}
+}
+
+func TestController_Checkpoint(t *testing.T) {
+	task := newTestTask("task-1", "Add parser", taskstore.StatusInProgress, nil)
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		hasChanges  bool
+		finalText   string
+		wantCommits int
+	}{
+		{name: "disabled", enabled: false, hasChanges: true, finalText: "RALPH_CHECKPOINT: lexer done", wantCommits: 0},
+		{name: "no marker", enabled: true, hasChanges: true, finalText: "lexer done", wantCommits: 0},
+		{name: "no changes", enabled: true, hasChanges: false, finalText: "RALPH_CHECKPOINT: lexer done", wantCommits: 0},
+		{name: "commits checkpoint", enabled: true, hasChanges: true, finalText: "RALPH_CHECKPOINT: lexer done", wantCommits: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := &mockGitManager{
+				hasChanges:   tt.hasChanges,
+				changedFiles: []string{"lexer.go"},
+				commitHash:   "abc123",
+			}
+			ctrl := NewController(ControllerDeps{
+				TaskStore: newMockTaskStore(),
+				Claude:    &mockClaudeRunner{},
+				Verifier:  &mockVerifier{},
+				Git:       git,
+				LogsDir:   t.TempDir(),
+			})
+			ctrl.SetCheckpoints(tt.enabled)
+
+			record := NewIterationRecord(task.ID)
+			record.FilesChanged = []string{"main.go"}
+			ctrl.checkpoint(context.Background(), task, record, tt.finalText)
+
+			require.Len(t, git.commitCalls, tt.wantCommits)
+			if tt.wantCommits == 0 {
+				assert.Empty(t, record.CheckpointCommits)
+				assert.Equal(t, []string{"main.go"}, record.FilesChanged)
+				return
+			}
+
+			assert.Contains(t, git.commitCalls[0], "Add parser (checkpoint: lexer done)")
+			assert.Equal(t, []string{"abc123"}, record.CheckpointCommits)
+			assert.Equal(t, []string{"main.go", "lexer.go"}, record.FilesChanged)
+		})
+	}
+}
+
+func TestController_CommitResult_UsesLastCheckpoint(t *testing.T) {
+	git := &mockGitManager{hasChanges: false, commitHash: "new"}
+	ctrl := NewController(ControllerDeps{
+		TaskStore: newMockTaskStore(),
+		Claude:    &mockClaudeRunner{},
+		Verifier:  &mockVerifier{},
+		Git:       git,
+		LogsDir:   t.TempDir(),
+	})
+
+	task := newTestTask("task-1", "Add parser", taskstore.StatusInProgress, nil)
+	record := NewIterationRecord(task.ID)
+	record.CheckpointCommits = []string{"first", "second"}
+
+	hash, err := ctrl.commitResult(context.Background(), task, record)
+	require.NoError(t, err)
+	assert.Equal(t, "second", hash)
+	assert.Empty(t, git.commitCalls)
+}
+
+func TestController_RunOnce_CheckpointKeepsTaskOpen(t *testing.T) {
+	tests := []struct {
+		name        string
+		finalText   string
+		wantOutcome IterationOutcome
+		wantStatus  taskstore.TaskStatus
+	}{
+		{name: "checkpoint marker", finalText: "Lexer done.\nRALPH_CHECKPOINT: lexer done", wantOutcome: OutcomeCheckpoint, wantStatus: taskstore.StatusOpen},
+		{name: "no marker", finalText: "Parser done.", wantOutcome: OutcomeSuccess, wantStatus: taskstore.StatusCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+			child := newTestTask("child", "Add parser", taskstore.StatusOpen, strPtr("parent"))
+			child.Verify = [][]string{{"go", "test"}}
+			store.addTask(child)
+
+			git := &mockGitManager{currentCommit: "base", hasChanges: true, changedFiles: []string{"lexer.go"}, commitHash: "abc123"}
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess-1", FinalText: tt.finalText}},
+				Verifier:  &mockVerifier{results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}}},
+				Git:       git,
+				LogsDir:   t.TempDir(),
+			})
+			ctrl.SetCheckpoints(true)
+
+			result := ctrl.RunOnce(context.Background(), "parent")
+			require.Len(t, result.Records, 1)
+			record := result.Records[0]
+			assert.Equal(t, tt.wantOutcome, record.Outcome)
+			assert.Equal(t, "abc123", record.ResultCommit)
+			assert.Equal(t, tt.wantStatus, store.tasks["child"].Status)
+			assert.Empty(t, result.FailedTasks)
+		})
+	}
+}
+
+// checkpointRunner reports a checkpoint for its first checkpoints calls, then
+// finishes the task. Each call changes a different file.
+type checkpointRunner struct {
+	git         *mockGitManager
+	checkpoints int
+	calls       int
+}
+
+func (m *checkpointRunner) Run(ctx context.Context, req claude.ClaudeRequest) (*claude.ClaudeResponse, error) {
+	m.calls++
+	m.git.changedFiles = []string{fmt.Sprintf("step%d.go", m.calls)}
+	text := "Parser done."
+	if m.calls <= m.checkpoints {
+		text = fmt.Sprintf("Step %d done.\nRALPH_CHECKPOINT: step %d", m.calls, m.calls)
+	}
+	return &claude.ClaudeResponse{SessionID: fmt.Sprintf("sess-%d", m.calls), FinalText: text, TotalCostUSD: 0.01}, nil
+}
+
+func TestController_RunLoop_RepeatedCheckpointsAreNotALivelock(t *testing.T) {
+	const maxRetries = 2
+
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	child := newTestTask("child", "Add parser", taskstore.StatusOpen, strPtr("parent"))
+	child.Verify = [][]string{{"go", "test"}}
+	store.addTask(child)
+
+	git := &mockGitManager{currentCommit: "base", hasChanges: true, commitHash: "abc123"}
+	runner := &checkpointRunner{git: git, checkpoints: maxRetries + 2}
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    runner,
+		Verifier:  &mockVerifier{results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}}},
+		Git:       git,
+		LogsDir:   t.TempDir(),
+	})
+	ctrl.SetCheckpoints(true)
+	ctrl.SetMaxRetries(maxRetries)
+
+	result := ctrl.RunLoop(context.Background(), "parent")
+
+	assert.Equal(t, RunOutcomeCompleted, result.Outcome)
+	assert.Equal(t, maxRetries+3, runner.calls)
+	assert.Equal(t, []string{"child"}, result.CompletedTasks)
+	assert.Equal(t, taskstore.StatusCompleted, store.tasks["child"].Status)
+}
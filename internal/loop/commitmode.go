@@ -0,0 +1,308 @@
+package loop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/yarlson/ralph/internal/atomicfile"
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// CommitMode controls when completed work is committed.
+type CommitMode string
+
+const (
+	// CommitPerTask commits each completed task (the default).
+	CommitPerTask CommitMode = "per_task"
+	// CommitPerRun stages each completed task and makes a single commit when
+	// the run completes.
+	CommitPerRun CommitMode = "per_run"
+)
+
+// SetCommitMode sets when completed work is committed. An empty mode keeps
+// per-task commits.
+//
+// In per_run mode, iterations only see unstaged changes once a task has been
+// staged, so each iteration's changed files are its own. Checkpoint commits
+// and clean retries are disabled, and iterations record no base commit, so
+// they cannot be undone with `ralph fix --undo`. If the run stops before
+// completing, the changes stay staged and the next per_run mode run resumes
+// with them.
+func (c *Controller) SetCommitMode(mode CommitMode) error {
+	switch mode {
+	case "", CommitPerTask:
+		return nil
+	case CommitPerRun:
+		if !c.commitPerRun {
+			c.commitPerRun = true
+			c.gitManager = &stagedGitManager{Manager: c.gitManager}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown commit mode %q (want %s or %s)", mode, CommitPerTask, CommitPerRun)
+	}
+}
+
+// stagedTask is a task whose changes are staged for the run commit.
+type stagedTask struct {
+	Title       string `json:"title"`
+	IterationID string `json:"iteration_id"`
+}
+
+// stageResult stages a completed task's changes for the run commit.
+func (c *Controller) stageResult(ctx context.Context, task *taskstore.Task, record *IterationRecord) error {
+	if err := c.gitManager.Stage(ctx); err != nil {
+		return err
+	}
+	c.stagedTasks = append(c.stagedTasks, stagedTask{Title: task.Title, IterationID: record.IterationID})
+	return nil
+}
+
+// commitRun commits the staged tasks once a per_run mode run completes. Runs
+// that stop early leave the changes staged and record the staged tasks, so
+// the next run resumes with them.
+func (c *Controller) commitRun(ctx context.Context, parentTaskID string, result *RunResult) {
+	if !c.commitPerRun || len(c.stagedTasks) == 0 {
+		return
+	}
+	if result.Outcome != RunOutcomeCompleted {
+		c.writeProgress("📥 Changes from %d completed task(s) are staged but not committed\n", len(c.stagedTasks))
+		c.saveStagedTasks()
+		return
+	}
+
+	parentTitle := parentTaskID
+	if parent, err := c.taskStore.Get(parentTaskID); err == nil {
+		parentTitle = parent.Title
+	}
+
+	titles := make([]string, 0, len(c.stagedTasks))
+	iterationIDs := make([]string, 0, len(c.stagedTasks))
+	for _, t := range c.stagedTasks {
+		titles = append(titles, t.Title)
+		iterationIDs = append(iterationIDs, t.IterationID)
+	}
+
+	hash, err := c.gitManager.Commit(ctx, git.FormatRunCommitMessage(parentTitle, titles, iterationIDs))
+	if err != nil {
+		result.Outcome = RunOutcomeError
+		result.Message = fmt.Sprintf("failed to commit run: %v", err)
+		return
+	}
+
+	c.stagedTasks = nil
+	c.saveStagedTasks()
+	result.RunCommit = hash
+	c.writeProgress("📝 Committed %d task(s): %s\n", len(titles), hash)
+}
+
+// resumeStagedRun picks up the tasks an earlier per_run mode run staged but
+// did not commit, so they are committed with this run's and their staged
+// changes do not count as uncommitted changes. If the changes are gone
+// (committed or discarded since), the record of them is dropped.
+func (c *Controller) resumeStagedRun(ctx context.Context) {
+	if !c.commitPerRun || c.workDir == "" || len(c.stagedTasks) > 0 {
+		return
+	}
+	staged, err := loadStagedTasks(state.StagedRunFilePath(c.workDir))
+	if err != nil {
+		c.writeProgress("⚠ Ignoring staged tasks of the previous run: %v\n", err)
+		return
+	}
+	if len(staged) == 0 {
+		return
+	}
+
+	files, err := baseGitManager(c.gitManager).GetChangedFiles(ctx)
+	if err != nil {
+		return
+	}
+	if !slices.ContainsFunc(files, func(f string) bool { return !isRalphPath(f) }) {
+		c.saveStagedTasks()
+		return
+	}
+
+	c.stagedTasks = staged
+	if g := findStagedGitManager(c.gitManager); g != nil {
+		g.staged = true
+	}
+	c.writeProgress("📥 Resuming with %d task(s) staged by the previous run\n", len(staged))
+}
+
+// saveStagedTasks records the staged tasks for the next run, removing the
+// record when nothing is staged. Failures are reported but not fatal.
+func (c *Controller) saveStagedTasks() {
+	if c.workDir == "" {
+		return
+	}
+	path := state.StagedRunFilePath(c.workDir)
+	if len(c.stagedTasks) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			c.writeProgress("⚠ Failed to remove %s: %v\n", path, err)
+		}
+		return
+	}
+	data, err := json.MarshalIndent(c.stagedTasks, "", "  ")
+	if err == nil {
+		err = atomicfile.WriteFile(path, data)
+	}
+	if err != nil {
+		c.writeProgress("⚠ Failed to record the staged tasks: %v\n", err)
+	}
+}
+
+// loadStagedTasks reads the staged tasks recorded at path. Returns nil, nil
+// if none are recorded.
+func loadStagedTasks(path string) ([]stagedTask, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read staged tasks: %w", err)
+	}
+	var staged []stagedTask
+	if err := json.Unmarshal(data, &staged); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal staged tasks: %w", err)
+	}
+	return staged, nil
+}
+
+// findStagedGitManager returns the stagedGitManager among m and the managers
+// it wraps, or nil.
+func findStagedGitManager(m git.Manager) *stagedGitManager {
+	for {
+		if g, ok := m.(*stagedGitManager); ok {
+			return g
+		}
+		w, ok := m.(gitWrapper)
+		if !ok {
+			return nil
+		}
+		m = w.Unwrap()
+	}
+}
+
+// stagedGitManager reports only unstaged changes once anything has been
+// staged, so each iteration of a per_run mode run sees its own changes.
+type stagedGitManager struct {
+	git.Manager
+	staged bool
+}
+
+func (g *stagedGitManager) Stage(ctx context.Context) error {
+	if err := g.Manager.Stage(ctx); err != nil {
+		return err
+	}
+	g.staged = true
+	return nil
+}
+
+func (g *stagedGitManager) HasChanges(ctx context.Context) (bool, error) {
+	if !g.staged {
+		return g.Manager.HasChanges(ctx)
+	}
+	files, err := g.Manager.GetUnstagedFiles(ctx)
+	return len(files) > 0, err
+}
+
+func (g *stagedGitManager) GetChangedFiles(ctx context.Context) ([]string, error) {
+	if !g.staged {
+		return g.Manager.GetChangedFiles(ctx)
+	}
+	return g.Manager.GetUnstagedFiles(ctx)
+}
+
+// GetFileChanges reports only the changes to unstaged files once anything
+// has been staged. A file staged as added and then edited again still reads
+// as added.
+func (g *stagedGitManager) GetFileChanges(ctx context.Context) ([]git.FileChange, error) {
+	changes, err := g.Manager.GetFileChanges(ctx)
+	if !g.staged || err != nil {
+		return changes, err
+	}
+	files, err := g.Manager.GetUnstagedFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	unstaged := make(map[string]bool, len(files))
+	for _, f := range files {
+		unstaged[f] = true
+	}
+	var filtered []git.FileChange
+	for _, c := range changes {
+		if unstaged[c.Path] {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, nil
+}
+
+func (g *stagedGitManager) Commit(ctx context.Context, message string) (string, error) {
+	hash, err := g.Manager.Commit(ctx, message)
+	if err == nil {
+		g.staged = false
+	}
+	return hash, err
+}
+
+// Unwrap returns the wrapped manager.
+func (g *stagedGitManager) Unwrap() git.Manager {
+	return g.Manager
+}
+
+// Push pushes with the wrapped manager, so per_run mode runs can be published.
+func (g *stagedGitManager) Push(ctx context.Context, remote, branch string) error {
+	p, ok := g.Manager.(pusher)
+	if !ok {
+		return errors.New("the git manager cannot push")
+	}
+	return p.Push(ctx, remote, branch)
+}
+
+func (g *stagedGitManager) IsAncestor(ctx context.Context, ancestor, descendant string) (bool, error) {
+	a, ok := g.Manager.(ancestryChecker)
+	if !ok {
+		return false, errors.New("the git manager cannot check ancestry")
+	}
+	return a.IsAncestor(ctx, ancestor, descendant)
+}
+
+func (g *stagedGitManager) WorkingTreeHash(ctx context.Context) (string, error) {
+	h, ok := g.Manager.(treeHasher)
+	if !ok {
+		return "", errors.New("the git manager cannot hash the working tree")
+	}
+	return h.WorkingTreeHash(ctx)
+}
+
+func (g *stagedGitManager) AddWorktree(ctx context.Context, path string) (git.Manager, error) {
+	w, ok := g.Manager.(worktreeManager)
+	if !ok {
+		return nil, errors.New("the git manager cannot manage worktrees")
+	}
+	return w.AddWorktree(ctx, path)
+}
+
+func (g *stagedGitManager) RemoveWorktree(ctx context.Context, path string) error {
+	w, ok := g.Manager.(worktreeManager)
+	if !ok {
+		return errors.New("the git manager cannot manage worktrees")
+	}
+	return w.RemoveWorktree(ctx, path)
+}
+
+func (g *stagedGitManager) CherryPick(ctx context.Context, base, tip string) (string, error) {
+	w, ok := g.Manager.(worktreeManager)
+	if !ok {
+		return "", errors.New("the git manager cannot manage worktrees")
+	}
+	return w.CherryPick(ctx, base, tip)
+}
@@ -0,0 +1,301 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func newCommitModeController(t *testing.T, gitMock *mockGitManager) *Controller {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Add user accounts", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("task-a", "Create user model", taskstore.StatusOpen, strPtr("parent")))
+	store.addTask(newTestTask("task-b", "Add login endpoint", taskstore.StatusOpen, strPtr("parent")))
+
+	return NewController(ControllerDeps{
+		TaskStore: store,
+		Claude: &mockClaudeRunner{
+			response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"},
+		},
+		Verifier: &mockVerifier{
+			results: []verifier.VerificationResult{{Passed: true, Command: []string{"echo"}}},
+		},
+		Git:         gitMock,
+		LogsDir:     t.TempDir(),
+		ProgressDir: t.TempDir(),
+	})
+}
+
+func TestController_SetCommitMode(t *testing.T) {
+	ctrl := newCommitModeController(t, &mockGitManager{})
+
+	require.NoError(t, ctrl.SetCommitMode(""))
+	require.NoError(t, ctrl.SetCommitMode(CommitPerTask))
+	assert.False(t, ctrl.commitPerRun)
+
+	err := ctrl.SetCommitMode("per_day")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "per_day")
+}
+
+func TestController_CommitPerRun(t *testing.T) {
+	t.Run("one commit when the run completes", func(t *testing.T) {
+		gitMock := &mockGitManager{
+			currentCommit: "base",
+			hasChanges:    true,
+			changedFiles:  []string{"a.go"},
+			unstagedFiles: []string{"a.go"},
+			commitHash:    "run123",
+		}
+		ctrl := newCommitModeController(t, gitMock)
+		require.NoError(t, ctrl.SetCommitMode(CommitPerRun))
+
+		result := ctrl.RunLoop(context.Background(), "parent")
+
+		assert.Equal(t, RunOutcomeCompleted, result.Outcome)
+		assert.Equal(t, 2, gitMock.stageCalls)
+		require.Len(t, gitMock.commitCalls, 1)
+		msg := gitMock.commitCalls[0]
+		assert.Contains(t, msg, "feat: Add user accounts")
+		assert.Contains(t, msg, "- Create user model")
+		assert.Contains(t, msg, "- Add login endpoint")
+		assert.Equal(t, "run123", result.RunCommit)
+
+		require.Len(t, result.Records, 2)
+		for _, record := range result.Records {
+			assert.Empty(t, record.ResultCommit)
+			assert.Empty(t, record.BaseCommit)
+			assert.Contains(t, msg, record.IterationID)
+		}
+	})
+
+	t.Run("changes stay staged when the run stops early", func(t *testing.T) {
+		gitMock := &mockGitManager{
+			hasChanges:    true,
+			changedFiles:  []string{"a.go"},
+			unstagedFiles: []string{"a.go"},
+			commitHash:    "run123",
+		}
+		ctrl := newCommitModeController(t, gitMock)
+		require.NoError(t, ctrl.SetCommitMode(CommitPerRun))
+		ctrl.budget = NewBudgetTracker(BudgetLimits{MaxIterations: 1})
+
+		result := ctrl.RunLoop(context.Background(), "parent")
+
+		assert.Equal(t, RunOutcomeBudgetExceeded, result.Outcome)
+		assert.Equal(t, 1, gitMock.stageCalls)
+		assert.Empty(t, gitMock.commitCalls)
+		assert.Empty(t, result.RunCommit)
+	})
+
+	t.Run("iterations only see unstaged changes", func(t *testing.T) {
+		gitMock := &mockGitManager{
+			hasChanges:   true,
+			changedFiles: []string{"a.go"},
+			commitHash:   "run123",
+		}
+		ctrl := newCommitModeController(t, gitMock)
+		require.NoError(t, ctrl.SetCommitMode(CommitPerRun))
+
+		result := ctrl.RunLoop(context.Background(), "parent")
+
+		// The second task made no unstaged changes after the first was staged
+		require.GreaterOrEqual(t, len(result.Records), 2)
+		assert.Equal(t, OutcomeSuccess, result.Records[0].Outcome)
+		for _, record := range result.Records[1:] {
+			assert.Equal(t, OutcomeFailed, record.Outcome)
+			assert.Equal(t, "No changes made by Claude", record.Feedback)
+		}
+		require.Len(t, gitMock.commitCalls, 1)
+		assert.Contains(t, gitMock.commitCalls[0], "- Create user model")
+		assert.NotContains(t, gitMock.commitCalls[0], "Add login endpoint")
+	})
+
+	t.Run("per task mode commits each task", func(t *testing.T) {
+		gitMock := &mockGitManager{
+			currentCommit: "base",
+			hasChanges:    true,
+			changedFiles:  []string{"a.go"},
+			commitHash:    "task123",
+		}
+		ctrl := newCommitModeController(t, gitMock)
+		require.NoError(t, ctrl.SetCommitMode(CommitPerTask))
+
+		result := ctrl.RunLoop(context.Background(), "parent")
+
+		assert.Equal(t, RunOutcomeCompleted, result.Outcome)
+		assert.Len(t, gitMock.commitCalls, 2)
+		assert.Zero(t, gitMock.stageCalls)
+		assert.Empty(t, result.RunCommit)
+	})
+}
+
+func TestController_CommitPerRun_ResumesStagedTasks(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(state.StateDirPath(workDir), 0755))
+
+	// The first run stages one task and stops
+	first := newCommitModeController(t, &mockGitManager{
+		hasChanges:    true,
+		changedFiles:  []string{"a.go"},
+		unstagedFiles: []string{"a.go"},
+	})
+	first.workDir = workDir
+	require.NoError(t, first.SetCommitMode(CommitPerRun))
+	first.budget = NewBudgetTracker(BudgetLimits{MaxIterations: 1})
+	result := first.RunLoop(context.Background(), "parent")
+	require.Equal(t, RunOutcomeBudgetExceeded, result.Outcome)
+	assert.FileExists(t, state.StagedRunFilePath(workDir))
+
+	t.Run("staged changes are not uncommitted changes", func(t *testing.T) {
+		gitMock := &mockGitManager{changedFiles: []string{"a.go"}, commitHash: "run123"}
+		ctrl := newCommitModeController(t, gitMock)
+		ctrl.workDir = workDir
+		require.NoError(t, ctrl.SetCommitMode(CommitPerRun))
+		ctrl.SetRequireCleanTree(true)
+
+		ctrl.resumeStagedRun(context.Background())
+		require.NoError(t, ctrl.ensureCleanTree(context.Background()))
+
+		result := RunResult{Outcome: RunOutcomeCompleted}
+		ctrl.commitRun(context.Background(), "parent", &result)
+		require.Len(t, gitMock.commitCalls, 1)
+		assert.Contains(t, gitMock.commitCalls[0], "- Create user model")
+		assert.NoFileExists(t, state.StagedRunFilePath(workDir))
+	})
+
+	t.Run("forgotten once the changes are gone", func(t *testing.T) {
+		ctrl := newCommitModeController(t, &mockGitManager{})
+		ctrl.workDir = workDir
+		require.NoError(t, ctrl.SetCommitMode(CommitPerRun))
+		ctrl.stagedTasks = []stagedTask{{Title: "Create user model", IterationID: "it-1"}}
+		ctrl.saveStagedTasks()
+		ctrl.stagedTasks = nil
+
+		ctrl.resumeStagedRun(context.Background())
+
+		assert.Empty(t, ctrl.stagedTasks)
+		assert.NoFileExists(t, state.StagedRunFilePath(workDir))
+	})
+}
+
+// worktreeGitManager adds the optional git methods to mockGitManager.
+type worktreeGitManager struct {
+	*mockGitManager
+	pushes []string
+}
+
+func (m *worktreeGitManager) Push(ctx context.Context, remote, branch string) error {
+	m.pushes = append(m.pushes, remote+"/"+branch)
+	return nil
+}
+
+func (m *worktreeGitManager) IsAncestor(ctx context.Context, ancestor, descendant string) (bool, error) {
+	return ancestor == "base", nil
+}
+
+func (m *worktreeGitManager) WorkingTreeHash(ctx context.Context) (string, error) {
+	return "tree", nil
+}
+
+func (m *worktreeGitManager) AddWorktree(ctx context.Context, path string) (git.Manager, error) {
+	return &mockGitManager{}, nil
+}
+
+func (m *worktreeGitManager) RemoveWorktree(ctx context.Context, path string) error {
+	return nil
+}
+
+func (m *worktreeGitManager) CherryPick(ctx context.Context, base, tip string) (string, error) {
+	return "picked", nil
+}
+
+func TestStagedGitManager_ForwardsOptionalMethods(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("supported by the wrapped manager", func(t *testing.T) {
+		base := &worktreeGitManager{mockGitManager: &mockGitManager{}}
+		staged := &stagedGitManager{Manager: base}
+
+		assert.Same(t, base, baseGitManager(staged))
+		require.NoError(t, staged.Push(ctx, "origin", "feature"))
+		assert.Equal(t, []string{"origin/feature"}, base.pushes)
+		contained, err := staged.IsAncestor(ctx, "base", "HEAD")
+		require.NoError(t, err)
+		assert.True(t, contained)
+		tree, err := staged.WorkingTreeHash(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "tree", tree)
+		worktree, err := staged.AddWorktree(ctx, "/tmp/w")
+		require.NoError(t, err)
+		assert.NotNil(t, worktree)
+		require.NoError(t, staged.RemoveWorktree(ctx, "/tmp/w"))
+		picked, err := staged.CherryPick(ctx, "base", "tip")
+		require.NoError(t, err)
+		assert.Equal(t, "picked", picked)
+	})
+
+	t.Run("unsupported methods fail and are not reported as supported", func(t *testing.T) {
+		staged := &stagedGitManager{Manager: &mockGitManager{}}
+
+		_, ok := baseGitManager(staged).(worktreeManager)
+		assert.False(t, ok)
+		_, err := staged.IsAncestor(ctx, "a", "b")
+		assert.Error(t, err)
+		_, err = staged.WorkingTreeHash(ctx)
+		assert.Error(t, err)
+		_, err = staged.AddWorktree(ctx, "/tmp/w")
+		assert.Error(t, err)
+	})
+}
+
+func TestController_CommitTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		labels   map[string]string
+		want     string // %s is the iteration ID
+	}{
+		{"default format", "", nil, "feat: Add login\n\nRalph iteration: %s"},
+		{"type from labels", "", map[string]string{"type": "docs"}, "docs: Add login\n\nRalph iteration: %s"},
+		{"custom template", "{{.Type}}({{.TaskID}}): {{.Title}} [ralph {{.IterationID}}]", map[string]string{"type": "test"}, "test(task-1): Add login [ralph %s]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+			task := newTestTask("task-1", "Add login", taskstore.StatusOpen, strPtr("parent"))
+			task.Labels = tt.labels
+			store.addTask(task)
+			gitManager := &mockGitManager{currentCommit: "abc", hasChanges: true, changedFiles: []string{"f.go"}, commitHash: "def"}
+
+			c := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"}},
+				Verifier:  &mockVerifier{results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}}},
+				Git:       gitManager,
+				LogsDir:   t.TempDir(),
+			})
+			tmpl, err := git.ParseCommitTemplate(tt.template)
+			require.NoError(t, err)
+			c.SetCommitTemplate(tmpl)
+
+			result := c.RunOnce(context.Background(), "parent")
+
+			require.Len(t, gitManager.commitCalls, 1)
+			require.Len(t, result.Records, 1)
+			assert.Equal(t, fmt.Sprintf(tt.want, result.Records[0].IterationID), gitManager.commitCalls[0])
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package loop
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/prompt"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// loadContextFiles reads the task's reference files from the repository. A
+// file that cannot be read is still listed, with the reason, so the agent
+// knows it was expected.
+func (c *Controller) loadContextFiles(task *taskstore.Task) []prompt.ContextFile {
+	if len(task.ContextFiles) == 0 {
+		return nil
+	}
+
+	files := make([]prompt.ContextFile, 0, len(task.ContextFiles))
+	for _, path := range task.ContextFiles {
+		file := prompt.ContextFile{Path: path}
+
+		rel := filepath.Clean(filepath.FromSlash(path))
+		if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			file.Err = "outside the repository, not read"
+			files = append(files, file)
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(c.codeDir(), rel))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			file.Err = "file not found"
+		case err != nil:
+			file.Err = "could not be read"
+		default:
+			file.Content = string(data)
+		}
+		files = append(files, file)
+	}
+	return files
+}
@@ -0,0 +1,34 @@
+package loop
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/prompt"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestController_LoadContextFiles(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, "internal", "selector"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "internal", "selector", "graph.go"), []byte("package selector\n"), 0644))
+
+	ctrl := NewController(ControllerDeps{TaskStore: newMockTaskStore(), WorkDir: workDir})
+	task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+
+	assert.Nil(t, ctrl.loadContextFiles(task))
+
+	task.ContextFiles = []string{"internal/selector/graph.go", "missing.go", "../outside.go", "internal/selector"}
+	files := ctrl.loadContextFiles(task)
+
+	assert.Equal(t, []prompt.ContextFile{
+		{Path: "internal/selector/graph.go", Content: "package selector\n"},
+		{Path: "missing.go", Err: "file not found"},
+		{Path: "../outside.go", Err: "outside the repository, not read"},
+		{Path: "internal/selector", Err: "could not be read"},
+	}, files)
+}
@@ -5,15 +5,22 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/color"
 	"github.com/yarlson/ralph/internal/git"
 	"github.com/yarlson/ralph/internal/memory"
 	"github.com/yarlson/ralph/internal/prompt"
+	"github.com/yarlson/ralph/internal/redact"
 	"github.com/yarlson/ralph/internal/selector"
 	"github.com/yarlson/ralph/internal/state"
 	"github.com/yarlson/ralph/internal/taskstore"
@@ -70,6 +77,14 @@ type RunResult struct {
 	// FailedTasks is the list of task IDs that failed.
 	FailedTasks []string
 
+	// UnverifiedTasks lists completed tasks that had no verify commands, so
+	// nothing but the agent vouched for them.
+	UnverifiedTasks []string
+
+	// AwaitingReviewTasks lists tasks the agent finished without verify
+	// commands that were left awaiting review (verify.require_commands).
+	AwaitingReviewTasks []string
+
 	// Records contains the iteration records from the run.
 	Records []*IterationRecord
 
@@ -78,6 +93,19 @@ type RunResult struct {
 
 	// ElapsedTime is the total time for the run.
 	ElapsedTime time.Duration
+
+	// Profile is the per-phase timing of each iteration (nil unless profiling is enabled).
+	Profile []IterationProfile
+
+	// RunCommit is the commit made at the end of a per_run commit mode run.
+	RunCommit string
+
+	// PullRequestURL is the pull request opened for a completed run, if any.
+	PullRequestURL string
+
+	// GutterInfo describes the gutter condition that stopped the run (nil
+	// unless Outcome is RunOutcomeGutterDetected).
+	GutterInfo *GutterStatus
 }
 
 // Summary provides an overview of task status for a parent task.
@@ -104,6 +132,7 @@ type ControllerDeps struct {
 	WorkDir        string
 	ProgressWriter io.Writer // for status output (nil = disabled)
 	StreamWriter   io.Writer // for Claude streaming (nil = disabled)
+	EventWriter    io.Writer // for JSON Lines run events (nil = disabled)
 }
 
 // Controller orchestrates the main iteration loop.
@@ -117,16 +146,81 @@ type Controller struct {
 	progressFile   *memory.ProgressFile
 	workDir        string
 	progressWriter io.Writer
+	eventSink      EventSink
+	eventWriter    EventSink
+	verbosity      Verbosity
+	palette        color.Palette
 	streamWriter   io.Writer
 
 	budget *BudgetTracker
 	gutter *GutterDetector
 
+	// budgetWarned is set once the budget warning threshold has been reported
+	budgetWarned bool
+
+	// budgetAllocation shares the cost limit between tasks; taskSlices holds
+	// each task's allocated slice and taskSpent what it has spent this run
+	budgetAllocation BudgetAllocation
+	taskSlices       map[string]float64
+	taskSpent        map[string]float64
+
+	// sessionID and parentTaskID tag the iteration records of the current run
+	sessionID    string
+	parentTaskID string
+
 	lastCompleted          *taskstore.Task
 	maxRetries             int
 	maxVerificationRetries int
 	taskAttempts           map[string]int // tracks attempt count per task ID
 	branchOverride         string         // optional branch name override
+	protectedBranches      []string       // branches the loop refuses to commit to
+
+	// commitTemplate renders task commit messages (nil = default format)
+	commitTemplate *git.CommitTemplate
+
+	// Publishing the feature branch when a run completes
+	push PushConfig
+
+	// Delay before retrying a failed task, growing by the factor per attempt
+	retryBackoffBase   time.Duration
+	retryBackoffFactor float64
+
+	// Working tree checks at run start
+	requireCleanTree bool // refuse to start with uncommitted changes
+	stashDirty       bool // stash uncommitted changes instead of refusing
+
+	// requireAllCompleted reports blocked unless every non-skipped leaf task is completed
+	requireAllCompleted bool
+	// requireVerifyCommands leaves tasks without verify commands awaiting review
+	requireVerifyCommands bool
+	// noGitAutoInit fails the run instead of running git init in a non-repository
+	noGitAutoInit bool
+
+	// unrecoverablePatterns match failure feedback that retries cannot fix
+	unrecoverablePatterns []*regexp.Regexp
+
+	// buildFirst is a build command run before the task's verify commands
+	buildFirst []string
+
+	// verifySets are named verify command lists that tasks reference by name
+	verifySets map[string][][]string
+
+	// verifyCachePath is the file of verify commands that passed per working
+	// tree hash ("" = cache disabled)
+	verifyCachePath string
+
+	// verifyCacheInputs are paths, usually gitignored, whose contents are
+	// part of the verify cache key
+	verifyCacheInputs []string
+
+	// formatCommand formats the agent's changes before verification (nil = disabled)
+	formatCommand []string
+
+	// Conditional verification: every verifyFullEvery-th iteration that would
+	// skip commands runs them all (0 = never); skippedVerifyRuns counts the
+	// iterations in a row that skipped commands
+	verifyFullEvery   int
+	skippedVerifyRuns int
 
 	// Memory configuration
 	maxProgressBytes    int
@@ -135,12 +229,85 @@ type Controller struct {
 	// Sandbox mode configuration
 	sandboxEnabled bool
 	allowedTools   []string
+
+	// selectionRand shuffles ready tasks when set (nil = deterministic order)
+	selectionRand *rand.Rand
+
+	// externalSelector chooses among ready tasks when set (nil = built-in selection)
+	externalSelector externalSelector
+
+	// focusTaskID restricts selection to a single task during RunFocus
+	focusTaskID string
+
+	// taskSplitter proposes sub-tasks for tasks that exhaust their retries (nil = disabled)
+	taskSplitter taskSplitter
+
+	// planning asks the agent for a plan before implementing a task;
+	// taskPlans caches each task's plan for its retries in this run
+	planning  PlanningConfig
+	taskPlans map[string]string
+
+	// continueOnFailure lets dependents of permanently failed tasks run
+	continueOnFailure bool
+
+	// checkpointsEnabled commits progress when the agent reports a sub-goal
+	checkpointsEnabled bool
+
+	// annotations are copied onto every iteration record
+	annotations map[string]string
+
+	// cleanRetries stashes a failed attempt's changes before retrying the task
+	// (false = the retry builds on the previous attempt's working tree)
+	cleanRetries bool
+
+	// commitPerRun stages completed tasks and commits them when the run
+	// completes; stagedTasks are the tasks staged so far
+	commitPerRun bool
+	stagedTasks  []stagedTask
+
+	// scopeWarnOnly reports changes outside a task's allowed paths instead of
+	// failing the iteration
+	scopeWarnOnly bool
+
+	// redactor removes passed-through secret values from stored records (nil = disabled)
+	redactor *redact.Redactor
+
+	// profiler times iteration phases when set (nil = profiling disabled)
+	profiler *profiler
+
+	// lastResultCommit is the commit made by this run's latest successful
+	// iteration; HEAD must still contain it before the next one starts
+	lastResultCommit string
+
+	// stepper confirms each selected task before it runs (nil = run all)
+	stepper stepper
+
+	// maxConcurrency is how many independent ready tasks may run at once,
+	// each in its own git worktree (0 or 1 = one at a time)
+	maxConcurrency int
+
+	// repoDir is where the agent and verify commands work when it is not
+	// workDir: a parallel worker's worktree ("" = workDir)
+	repoDir string
+
+	// sharedMu serializes parallel workers' writes to the shared progress
+	// file (nil when iterations run one at a time)
+	sharedMu *sync.Mutex
+
+	// Livelock guard: the task selected last and how many times in a row
+	lastSelectedID        string
+	consecutiveSelections int
 }
 
 // NewController creates a new loop controller with the given dependencies.
 func NewController(deps ControllerDeps) *Controller {
+	var eventWriter EventSink
+	if deps.EventWriter != nil {
+		eventWriter = &jsonLinesSink{w: deps.EventWriter}
+	}
+
 	return &Controller{
-		taskStore:              deps.TaskStore,
+		taskStore:              cacheTaskStore(deps.TaskStore),
 		claudeRunner:           deps.Claude,
 		verifier:               deps.Verifier,
 		gitManager:             deps.Git,
@@ -150,6 +317,7 @@ func NewController(deps ControllerDeps) *Controller {
 		workDir:                deps.WorkDir,
 		progressWriter:         deps.ProgressWriter,
 		streamWriter:           deps.StreamWriter,
+		eventWriter:            eventWriter,
 		budget:                 NewBudgetTracker(DefaultBudgetLimits()),
 		gutter:                 NewGutterDetector(DefaultGutterConfig()),
 		maxRetries:             2, // default
@@ -158,11 +326,19 @@ func NewController(deps ControllerDeps) *Controller {
 	}
 }
 
+// loadRecords loads the iteration records in the logs directory, reporting
+// corrupt ones in the progress output.
+func (c *Controller) loadRecords() ([]*IterationRecord, error) {
+	return loadIterationRecords(c.logsDir, func(path string, err error) {
+		c.writeProgress("⚠ Skipping corrupt iteration record %s: %v\n", path, err)
+	})
+}
+
 func (c *Controller) writeProgress(format string, args ...interface{}) {
-	if c.progressWriter == nil {
+	if c.progressWriter == nil || c.verbosity <= VerbosityQuiet {
 		return
 	}
-	_, _ = fmt.Fprintf(c.progressWriter, format, args...)
+	_, _ = fmt.Fprint(c.progressWriter, c.palette.Line(fmt.Sprintf(format, args...)))
 }
 
 func (c *Controller) iterationSummary(record *IterationRecord) {
@@ -180,11 +356,18 @@ func (c *Controller) iterationSummary(record *IterationRecord) {
 	if fileCount == 1 {
 		fileSummary = "1 file changed"
 	}
+	if breakdown := git.SummarizeFileChanges(record.FileChanges); breakdown != "" {
+		fileSummary += " (" + breakdown + ")"
+	}
 
 	if record.Outcome == OutcomeSuccess {
 		c.writeProgress("✓ Completed in %s ($%.4f) - %s\n\n", duration, record.ClaudeInvocation.TotalCostUSD, fileSummary)
 		return
 	}
+	if record.Outcome == OutcomeCheckpoint {
+		c.writeProgress("↻ Checkpointed in %s ($%.4f) - %s\n\n", duration, record.ClaudeInvocation.TotalCostUSD, fileSummary)
+		return
+	}
 
 	reason := strings.TrimSpace(record.Feedback)
 	if reason == "" {
@@ -200,6 +383,15 @@ func (c *Controller) iterationSummary(record *IterationRecord) {
 // SetBudgetLimits sets the budget limits for the controller.
 func (c *Controller) SetBudgetLimits(limits BudgetLimits) {
 	c.budget = NewBudgetTracker(limits)
+	c.budgetWarned = false
+}
+
+// SetBudgetTracker replaces the budget tracker, for example with one that
+// continues from totals persisted by earlier runs (see
+// NewBudgetTrackerFromState).
+func (c *Controller) SetBudgetTracker(tracker *BudgetTracker) {
+	c.budget = tracker
+	c.budgetWarned = false
 }
 
 // SetMemoryConfig sets the memory configuration for progress file size limits.
@@ -228,6 +420,64 @@ func (c *Controller) SetBranchOverride(branch string) {
 	c.branchOverride = branch
 }
 
+// SetProtectedBranches sets the branches the loop refuses to run on.
+// A branch override bypasses the check.
+func (c *Controller) SetProtectedBranches(branches []string) {
+	c.protectedBranches = branches
+}
+
+// SetRequireCleanTree makes RunLoop and RunOnce refuse to start while files outside .ralph
+// have uncommitted changes.
+func (c *Controller) SetRequireCleanTree(require bool) {
+	c.requireCleanTree = require
+}
+
+// SetStashDirty makes RunLoop stash uncommitted changes at start instead of refusing.
+func (c *Controller) SetStashDirty(stash bool) {
+	c.stashDirty = stash
+}
+
+// SetRequireAllCompleted makes RunLoop report RunOutcomeBlocked instead of
+// RunOutcomeCompleted while any non-skipped leaf task under the parent
+// is not completed (e.g. failed).
+func (c *Controller) SetRequireAllCompleted(require bool) {
+	c.requireAllCompleted = require
+}
+
+// SetRequireVerifyCommands makes a task that has no verify commands end up
+// awaiting review instead of completed when the agent finishes it, so
+// unverified work is never silently trusted.
+func (c *Controller) SetRequireVerifyCommands(require bool) {
+	c.requireVerifyCommands = require
+}
+
+// SetUnrecoverablePatterns sets regular expressions matched against failure
+// feedback. A matching failure blocks the task immediately instead of retrying.
+// Returns an error if a pattern does not compile.
+func (c *Controller) SetUnrecoverablePatterns(patterns []string) error {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid unrecoverable pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	c.unrecoverablePatterns = compiled
+	return nil
+}
+
+// matchUnrecoverable returns the first unrecoverable pattern matching feedback,
+// or "" if none match.
+func (c *Controller) matchUnrecoverable(feedback string) string {
+	for _, re := range c.unrecoverablePatterns {
+		if re.MatchString(feedback) {
+			return re.String()
+		}
+	}
+	return ""
+}
+
 // SetSandboxMode configures sandbox mode for Claude Code tool restrictions.
 // When enabled, only the specified allowed tools can be used.
 func (c *Controller) SetSandboxMode(enabled bool, allowedTools []string) {
@@ -235,6 +485,52 @@ func (c *Controller) SetSandboxMode(enabled bool, allowedTools []string) {
 	c.allowedTools = allowedTools
 }
 
+// SetSelectionRand enables randomized selection among ready tasks using rng.
+// Pass nil to restore deterministic ordering.
+func (c *Controller) SetSelectionRand(rng *rand.Rand) {
+	c.selectionRand = rng
+}
+
+// SetContinueOnFailure makes task selection treat failed dependencies as
+// satisfied, so the loop keeps attempting dependents of failed tasks.
+func (c *Controller) SetContinueOnFailure(enabled bool) {
+	c.continueOnFailure = enabled
+}
+
+// SetCheckpoints enables committing intermediate progress within a task when
+// the agent ends its response with a CheckpointMarker line.
+func (c *Controller) SetCheckpoints(enabled bool) {
+	c.checkpointsEnabled = enabled
+}
+
+// SetAnnotations sets key/value metadata attached to every iteration record,
+// letting external systems correlate iterations with their own identifiers.
+func (c *Controller) SetAnnotations(annotations map[string]string) {
+	c.annotations = annotations
+}
+
+// SetGitAutoInit controls whether a working directory that is not a git
+// repository is initialized as one when the run starts (the default). When
+// disabled, the run fails instead.
+func (c *Controller) SetGitAutoInit(enabled bool) {
+	c.noGitAutoInit = !enabled
+}
+
+// SetPreserveChanges controls whether a task retried in a new iteration starts
+// from the previous attempt's uncommitted changes (the default). When disabled,
+// those changes are stashed and the retry starts from a clean working tree.
+func (c *Controller) SetPreserveChanges(preserve bool) {
+	c.cleanRetries = !preserve
+}
+
+// selectOptions returns the task selection options configured on the controller.
+func (c *Controller) selectOptions() selector.SelectOptions {
+	return selector.SelectOptions{
+		Rand:                c.selectionRand,
+		FailedDepsSatisfied: c.continueOnFailure,
+	}
+}
+
 // slugify converts a string to a branch-safe slug by:
 // - converting to lowercase
 // - replacing spaces and underscores with hyphens
@@ -274,6 +570,32 @@ func slugify(s string) string {
 	return slug
 }
 
+// loadGutterState restores gutter history persisted by a previous run so that
+// resuming does not immediately re-enter the same stuck condition.
+func (c *Controller) loadGutterState() {
+	if c.workDir == "" {
+		return
+	}
+	saved, err := LoadGutterState(state.GutterStateFilePath(c.workDir))
+	if err != nil {
+		c.writeProgress("⚠ Ignoring gutter state: %v\n", err)
+		return
+	}
+	if saved != nil {
+		c.gutter.SetState(*saved)
+	}
+}
+
+// saveGutterState persists the current gutter history for future runs.
+func (c *Controller) saveGutterState() {
+	if c.workDir == "" {
+		return
+	}
+	if err := SaveGutterState(state.GutterStateFilePath(c.workDir), c.gutter.GetState()); err != nil {
+		c.writeProgress("⚠ Failed to save gutter state: %v\n", err)
+	}
+}
+
 // checkPaused checks if the loop has been paused by reading the pause flag file.
 func (c *Controller) checkPaused() bool {
 	if c.workDir == "" {
@@ -287,27 +609,29 @@ func (c *Controller) checkPaused() bool {
 }
 
 // ensureFeatureBranch ensures the feature branch exists and is checked out.
-// It uses the branch override if set, otherwise generates a branch name from the parent task title.
-// If the directory is not a git repository, it initializes one automatically.
+// It uses the branch override if set, otherwise the branch stored for the parent task,
+// otherwise generates a branch name from the parent task title. The branch used is stored.
+// If the directory is not a git repository, it initializes one automatically
+// unless auto-init is disabled.
+// Without an override, it refuses to start from a protected branch.
 func (c *Controller) ensureFeatureBranch(ctx context.Context, parentTaskID string) error {
-	var branchName string
+	// Checked before switching, which would always leave a protected branch
+	if err := c.checkProtectedBranch(ctx); err != nil {
+		return err
+	}
 
-	if c.branchOverride != "" {
-		// Use explicit branch override
-		branchName = c.branchOverride
-	} else {
-		// Generate branch name from parent task title
-		parentTask, err := c.taskStore.Get(parentTaskID)
-		if err != nil {
-			return fmt.Errorf("failed to get parent task: %w", err)
-		}
-		branchName = slugify(parentTask.Title)
+	branchName, err := c.featureBranchName(parentTaskID)
+	if err != nil {
+		return err
 	}
 
 	// Call git manager to ensure branch
 	if err := c.gitManager.EnsureBranch(ctx, branchName); err != nil {
 		// If not a git repo, auto-initialize and retry
 		if errors.Is(err, git.ErrNotAGitRepo) {
+			if c.noGitAutoInit {
+				return fmt.Errorf("%w; run git init or set git.auto_init", git.ErrNotAGitRepo)
+			}
 			if initErr := c.gitManager.Init(ctx); initErr != nil {
 				return fmt.Errorf("failed to initialize git repository: %w", initErr)
 			}
@@ -315,16 +639,139 @@ func (c *Controller) ensureFeatureBranch(ctx context.Context, parentTaskID strin
 			if retryErr := c.gitManager.EnsureBranch(ctx, branchName); retryErr != nil {
 				return fmt.Errorf("failed to ensure branch after git init: %w", retryErr)
 			}
+			c.storeFeatureBranch(parentTaskID, branchName)
 			return nil
 		}
 		return fmt.Errorf("failed to ensure branch: %w", err)
 	}
 
+	c.storeFeatureBranch(parentTaskID, branchName)
+	return nil
+}
+
+// featureBranchName returns the branch to work on for the parent task: the
+// explicit override, else the branch stored by an earlier run, else a branch
+// generated from the parent task title.
+func (c *Controller) featureBranchName(parentTaskID string) (string, error) {
+	if c.branchOverride != "" {
+		return c.branchOverride, nil
+	}
+
+	if c.workDir != "" {
+		stored, err := state.GetStoredBranch(c.workDir, parentTaskID)
+		if err != nil {
+			return "", err
+		}
+		if stored != "" {
+			return stored, nil
+		}
+	}
+
+	parentTask, err := c.taskStore.Get(parentTaskID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get parent task: %w", err)
+	}
+	return slugify(parentTask.Title), nil
+}
+
+// storeFeatureBranch records the branch for the parent task so later runs
+// continue on it regardless of title edits or a one-off override.
+func (c *Controller) storeFeatureBranch(parentTaskID, branchName string) {
+	if c.workDir == "" {
+		return
+	}
+	if stored, err := state.GetStoredBranch(c.workDir, parentTaskID); err == nil && stored == branchName {
+		return
+	}
+	if err := state.SetStoredBranch(c.workDir, parentTaskID, branchName); err != nil {
+		c.writeProgress("⚠ Failed to store branch for %s: %v\n", parentTaskID, err)
+	}
+}
+
+// checkProtectedBranch returns git.ErrProtectedBranch if the checked-out branch
+// is protected. An explicit branch override intentionally bypasses the check,
+// as does a repository without commits, which has nothing to protect yet.
+func (c *Controller) checkProtectedBranch(ctx context.Context) error {
+	if c.branchOverride != "" || len(c.protectedBranches) == 0 {
+		return nil
+	}
+
+	current, err := c.gitManager.GetCurrentBranch(ctx)
+	if errors.Is(err, git.ErrNotAGitRepo) || errors.Is(err, git.ErrNoCommits) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if slices.Contains(c.protectedBranches, current) {
+		return fmt.Errorf("%w %q (use --branch to override)", git.ErrProtectedBranch, current)
+	}
+
 	return nil
 }
 
+// ensureCleanTree checks that no files outside .ralph have uncommitted changes,
+// so every iteration's changes are attributable to that iteration. Dirty files
+// are stashed when stash-dirty is enabled; otherwise git.ErrDirtyWorkingTree is returned.
+func (c *Controller) ensureCleanTree(ctx context.Context) error {
+	if !c.requireCleanTree && !c.stashDirty {
+		return nil
+	}
+
+	files, err := c.gitManager.GetChangedFiles(ctx)
+	if err != nil {
+		// A missing repository is initialized by ensureFeatureBranch
+		if errors.Is(err, git.ErrNotAGitRepo) {
+			return nil
+		}
+		return fmt.Errorf("failed to check working tree: %w", err)
+	}
+
+	var dirty []string
+	for _, f := range files {
+		if !isRalphPath(f) {
+			dirty = append(dirty, f)
+		}
+	}
+	if len(dirty) == 0 {
+		return nil
+	}
+
+	summary := strings.Join(dirty, ", ")
+	if len(dirty) > 3 {
+		summary = fmt.Sprintf("%s and %d more", strings.Join(dirty[:3], ", "), len(dirty)-3)
+	}
+
+	if !c.stashDirty {
+		return fmt.Errorf("%w: %s (commit or stash them first, or rerun with --stash-dirty)", git.ErrDirtyWorkingTree, summary)
+	}
+
+	if err := c.gitManager.StashChanges(ctx, "ralph: uncommitted changes before run", []string{state.RalphDir}); err != nil {
+		return fmt.Errorf("failed to stash uncommitted changes: %w", err)
+	}
+	c.writeProgress("⚠ Stashed uncommitted changes (%s); restore them with 'git stash pop'\n\n", summary)
+	return nil
+}
+
+// isRalphPath reports whether path is in the .ralph directory.
+func isRalphPath(path string) bool {
+	return path == state.RalphDir || strings.HasPrefix(path, state.RalphDir+"/")
+}
+
 // RunLoop executes the main iteration loop until completion, blocked, or budget exceeded.
 func (c *Controller) RunLoop(ctx context.Context, parentTaskID string) RunResult {
+	c.sessionID = GenerateSessionID()
+	c.parentTaskID = parentTaskID
+	c.emit(Event{Type: EventRunStarted, ParentTaskID: parentTaskID})
+	result := c.runLoop(ctx, parentTaskID)
+	c.commitRun(ctx, parentTaskID, &result)
+	c.publishRun(ctx, parentTaskID, &result)
+	result.Profile = c.takeProfiles()
+	c.emitRunFinished(parentTaskID, result)
+	return result
+}
+
+func (c *Controller) runLoop(ctx context.Context, parentTaskID string) RunResult {
 	startTime := time.Now()
 	result := RunResult{
 		CompletedTasks: []string{},
@@ -332,6 +779,15 @@ func (c *Controller) RunLoop(ctx context.Context, parentTaskID string) RunResult
 		Records:        []*IterationRecord{},
 	}
 
+	// Refuse to mix pre-existing edits into the first iteration
+	c.resumeStagedRun(ctx)
+	if err := c.ensureCleanTree(ctx); err != nil {
+		result.Outcome = RunOutcomeError
+		result.Message = err.Error()
+		result.ElapsedTime = time.Since(startTime)
+		return result
+	}
+
 	// Ensure feature branch at start of run
 	if err := c.ensureFeatureBranch(ctx, parentTaskID); err != nil {
 		result.Outcome = RunOutcomeError
@@ -340,6 +796,8 @@ func (c *Controller) RunLoop(ctx context.Context, parentTaskID string) RunResult
 		return result
 	}
 
+	c.loadGutterState()
+
 	for {
 		// Check context cancellation
 		select {
@@ -359,7 +817,16 @@ func (c *Controller) RunLoop(ctx context.Context, parentTaskID string) RunResult
 			return result
 		}
 
-		// Check budget before iteration
+		// Don't commit on top of a branch that changed under us
+		if msg := c.branchDivergence(ctx); msg != "" {
+			result.Outcome = RunOutcomePaused
+			result.Message = msg
+			result.ElapsedTime = time.Since(startTime)
+			return result
+		}
+
+		// Check budget before iteration, with any limits changed mid-run
+		c.applyBudgetOverride()
 		budgetStatus := c.budget.CheckBudget()
 		if !budgetStatus.CanContinue {
 			result.Outcome = RunOutcomeBudgetExceeded
@@ -367,6 +834,12 @@ func (c *Controller) RunLoop(ctx context.Context, parentTaskID string) RunResult
 			result.ElapsedTime = time.Since(startTime)
 			return result
 		}
+		if budgetStatus.WarningCrossed && !c.budgetWarned {
+			c.budgetWarned = true
+			warning := c.budget.CostWarning()
+			c.writeProgress("⚠ Budget warning: %s; continuing\n\n", warning)
+			c.emit(Event{Type: EventBudgetWarning, ParentTaskID: parentTaskID, Message: warning, CostUSD: c.budget.GetState().TotalCostUSD})
+		}
 
 		// Check gutter before iteration
 		gutterStatus := c.gutter.Check()
@@ -386,28 +859,26 @@ func (c *Controller) RunLoop(ctx context.Context, parentTaskID string) RunResult
 			}
 			result.Outcome = RunOutcomeGutterDetected
 			result.Message = gutterStatus.Description
+			result.GutterInfo = &gutterStatus
 			result.ElapsedTime = time.Since(startTime)
 			return result
 		}
 
 		// Get tasks and select next
-		tasks, err := c.taskStore.List()
+		tasks, graph, err := c.listTasksWithGraph()
 		if err != nil {
 			result.Outcome = RunOutcomeError
-			result.Message = fmt.Sprintf("failed to list tasks: %v", err)
+			result.Message = err.Error()
 			result.ElapsedTime = time.Since(startTime)
 			return result
 		}
 
-		graph, err := selector.BuildGraph(tasks)
-		if err != nil {
-			result.Outcome = RunOutcomeError
-			result.Message = fmt.Sprintf("failed to build dependency graph: %v", err)
+		nextTask := c.selectNext(ctx, tasks, graph, parentTaskID)
+		if nextTask == nil && c.focusTaskID != "" {
+			result.Outcome, result.Message = focusOutcome(tasks, c.focusTaskID)
 			result.ElapsedTime = time.Since(startTime)
 			return result
 		}
-
-		nextTask := selector.SelectNext(tasks, graph, parentTaskID, c.lastCompleted)
 		if nextTask == nil {
 			// No more ready tasks - either completed or blocked
 			result.Outcome = RunOutcomeCompleted
@@ -433,35 +904,148 @@ func (c *Controller) RunLoop(ctx context.Context, parentTaskID string) RunResult
 				}
 			}
 
+			// Unverified work waiting for a person is not done yet
+			if result.Outcome == RunOutcomeCompleted {
+				if awaiting := descendantsWithStatus(tasks, parentTaskID, taskstore.StatusAwaitingReview); len(awaiting) > 0 {
+					result.Outcome = RunOutcomeBlocked
+					result.Message = fmt.Sprintf("no ready tasks available (awaiting review: %s)", strings.Join(awaiting, ", "))
+				}
+			}
+
+			// Failed tasks don't count as success when all must be completed
+			if result.Outcome == RunOutcomeCompleted && c.requireAllCompleted {
+				if unfinished := unfinishedLeafDescendants(tasks, parentTaskID); len(unfinished) > 0 {
+					result.Outcome = RunOutcomeBlocked
+					result.Message = fmt.Sprintf("no ready tasks available (not completed: %s)", strings.Join(unfinished, ", "))
+				}
+			}
+
+			// Nothing left to thrash on once the feature is done
+			if result.Outcome == RunOutcomeCompleted && c.workDir != "" {
+				_ = state.ClearGutterState(c.workDir)
+			}
+
+			result.ElapsedTime = time.Since(startTime)
+			return result
+		}
+
+		// Guard against spinning on a task that never progresses
+		if c.blockIfRepeatedlySelected(nextTask) {
+			continue
+		}
+		c.explainSelection(tasks, nextTask)
+
+		// Fail tasks that have used up their share of the budget
+		if msg := c.taskBudgetExhausted(tasks, parentTaskID, nextTask); msg != "" {
+			c.writeProgress("✗ Task %s %s, marking failed\n\n", nextTask.ID, msg)
+			_ = c.taskStore.UpdateStatus(nextTask.ID, taskstore.StatusFailed)
+			result.FailedTasks = append(result.FailedTasks, nextTask.ID)
+			c.lastSelectedID = ""
+			c.consecutiveSelections = 0
+			continue
+		}
+
+		// Let the user confirm, skip or stop when single-stepping
+		if msg, skipped := c.stepStopMessage(nextTask); skipped {
+			continue
+		} else if msg != "" {
+			result.Outcome = RunOutcomePaused
+			result.Message = msg
+			result.ElapsedTime = time.Since(startTime)
+			return result
+		}
+
+		// Give a failing task (and the API) a rest before trying it again
+		if msg := c.waitBeforeRetry(ctx, nextTask); msg != "" {
+			result.Outcome = RunOutcomePaused
+			result.Message = msg
 			result.ElapsedTime = time.Since(startTime)
 			return result
 		}
 
+		// Run independent ready tasks alongside it when allowed
+		if batch := c.parallelBatch(tasks, graph, parentTaskID, nextTask); len(batch) > 1 {
+			if c.runBatch(ctx, batch, &result) {
+				continue
+			}
+		}
+
 		// Run single iteration
 		record := c.runIteration(ctx, nextTask)
-		result.Records = append(result.Records, record)
-		result.IterationsRun++
-		result.TotalCostUSD += record.ClaudeInvocation.TotalCostUSD
-
-		// Track in budget and gutter
-		c.budget.RecordIteration(record.ClaudeInvocation.TotalCostUSD)
+		c.recordBudget(record)
 		c.gutter.RecordIteration(record)
+		c.finishIteration(ctx, nextTask, record, &result)
+	}
+}
 
-		// Handle outcome
-		if record.Outcome == OutcomeSuccess {
-			result.CompletedTasks = append(result.CompletedTasks, nextTask.ID)
-			c.lastCompleted = nextTask
-		} else {
-			result.FailedTasks = append(result.FailedTasks, nextTask.ID)
-		}
+// recordBudget adds an iteration's cost and tokens to the budget and writes
+// the totals back when they persist across runs.
+func (c *Controller) recordBudget(record *IterationRecord) {
+	invocation := record.ClaudeInvocation
+	c.budget.RecordIteration(invocation.TotalCostUSD, invocation.InputTokens, invocation.OutputTokens)
+	if err := c.budget.Flush(); err != nil {
+		c.writeProgress("⚠ %v\n", err)
+	}
+}
 
-		// Save iteration record
-		_, _ = SaveRecord(c.logsDir, record)
+// recordSplitCost adds the cost of splitting a failed task to the budget
+// and to result.
+func (c *Controller) recordSplitCost(cost float64, result *RunResult) {
+	if cost == 0 {
+		return
+	}
+	result.TotalCostUSD += cost
+	c.budget.RecordCost(cost)
+	if err := c.budget.Flush(); err != nil {
+		c.writeProgress("⚠ %v\n", err)
+	}
+}
+
+// finishIteration adds a finished iteration of task to result, tracks its
+// spending and outcome, and saves its record. The budget tracker and gutter
+// detector must already have recorded it.
+func (c *Controller) finishIteration(ctx context.Context, task *taskstore.Task, record *IterationRecord, result *RunResult) {
+	result.Records = append(result.Records, record)
+	result.IterationsRun++
+	result.TotalCostUSD += record.ClaudeInvocation.TotalCostUSD
+
+	c.recordTaskSpend(task.ID, record.ClaudeInvocation.TotalCostUSD)
+	c.saveGutterState()
+
+	// Handle outcome
+	if record.Outcome == OutcomeSuccess {
+		c.addSucceeded(result, task.ID, record)
+		c.lastCompleted = task
+		if record.ResultCommit != "" {
+			c.lastResultCommit = record.ResultCommit
+		}
+	} else if record.Outcome == OutcomeCheckpoint {
+		c.lastResultCommit = record.ResultCommit
+		// Committed progress is not a livelock
+		c.lastSelectedID = ""
+		c.consecutiveSelections = 0
+	} else {
+		result.FailedTasks = append(result.FailedTasks, task.ID)
+		c.recordSplitCost(c.splitFailedTask(ctx, task, record), result)
 	}
+
+	// Save iteration record
+	_, _ = SaveRecord(c.logsDir, record)
 }
 
 // RunOnce executes a single iteration and returns.
 func (c *Controller) RunOnce(ctx context.Context, parentTaskID string) RunResult {
+	c.sessionID = GenerateSessionID()
+	c.parentTaskID = parentTaskID
+	c.emit(Event{Type: EventRunStarted, ParentTaskID: parentTaskID})
+	result := c.runOnce(ctx, parentTaskID)
+	c.commitRun(ctx, parentTaskID, &result)
+	result.Profile = c.takeProfiles()
+	c.emitRunFinished(parentTaskID, result)
+	return result
+}
+
+func (c *Controller) runOnce(ctx context.Context, parentTaskID string) RunResult {
 	startTime := time.Now()
 	result := RunResult{
 		CompletedTasks: []string{},
@@ -479,24 +1063,33 @@ func (c *Controller) RunOnce(ctx context.Context, parentTaskID string) RunResult
 	default:
 	}
 
-	// Get tasks and select next
-	tasks, err := c.taskStore.List()
-	if err != nil {
+	// Refuse to mix pre-existing edits into the iteration
+	c.resumeStagedRun(ctx)
+	if err := c.ensureCleanTree(ctx); err != nil {
 		result.Outcome = RunOutcomeError
-		result.Message = fmt.Sprintf("failed to list tasks: %v", err)
+		result.Message = err.Error()
 		result.ElapsedTime = time.Since(startTime)
 		return result
 	}
 
-	graph, err := selector.BuildGraph(tasks)
+	// Check budget, which may include totals persisted by earlier runs
+	if budgetStatus := c.budget.CheckBudget(); !budgetStatus.CanContinue {
+		result.Outcome = RunOutcomeBudgetExceeded
+		result.Message = budgetStatus.Reason
+		result.ElapsedTime = time.Since(startTime)
+		return result
+	}
+
+	// Get tasks and select next
+	tasks, graph, err := c.listTasksWithGraph()
 	if err != nil {
 		result.Outcome = RunOutcomeError
-		result.Message = fmt.Sprintf("failed to build dependency graph: %v", err)
+		result.Message = err.Error()
 		result.ElapsedTime = time.Since(startTime)
 		return result
 	}
 
-	nextTask := selector.SelectNext(tasks, graph, parentTaskID, c.lastCompleted)
+	nextTask := c.selectNext(ctx, tasks, graph, parentTaskID)
 	if nextTask == nil {
 		result.Outcome = RunOutcomeBlocked
 		result.Message = "no ready tasks available"
@@ -505,7 +1098,9 @@ func (c *Controller) RunOnce(ctx context.Context, parentTaskID string) RunResult
 	}
 
 	// Run iteration
+	c.explainSelection(tasks, nextTask)
 	record := c.runIteration(ctx, nextTask)
+	c.recordBudget(record)
 	result.Records = append(result.Records, record)
 	result.IterationsRun = 1
 	result.TotalCostUSD = record.ClaudeInvocation.TotalCostUSD
@@ -513,12 +1108,16 @@ func (c *Controller) RunOnce(ctx context.Context, parentTaskID string) RunResult
 	if record.Outcome == OutcomeSuccess {
 		result.Outcome = RunOutcomeCompleted
 		result.Message = "iteration completed successfully"
-		result.CompletedTasks = append(result.CompletedTasks, nextTask.ID)
+		c.addSucceeded(&result, nextTask.ID, record)
 		c.lastCompleted = nextTask
+	} else if record.Outcome == OutcomeCheckpoint {
+		result.Outcome = RunOutcomeCompleted
+		result.Message = "iteration committed a checkpoint, task not finished"
 	} else {
 		result.Outcome = RunOutcomeBlocked
 		result.Message = "iteration failed"
 		result.FailedTasks = append(result.FailedTasks, nextTask.ID)
+		c.recordSplitCost(c.splitFailedTask(ctx, nextTask, record), &result)
 	}
 
 	// Save record
@@ -531,9 +1130,12 @@ func (c *Controller) RunOnce(ctx context.Context, parentTaskID string) RunResult
 // runIteration executes a single task iteration with in-iteration retry loop for verification failures.
 func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *IterationRecord {
 	record := NewIterationRecord(task.ID)
+	record.SessionID = c.sessionID
+	record.ParentTaskID = c.parentTaskID
+	record.Annotations = maps.Clone(c.annotations)
 
 	// Track attempt number
-	c.taskAttempts[task.ID]++
+	c.taskAttempts[task.ID] = c.previousAttempts(task.ID) + 1
 	record.AttemptNumber = c.taskAttempts[task.ID]
 
 	attemptSuffix := ""
@@ -543,6 +1145,17 @@ func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *It
 
 	c.writeProgress("▶ Task: %s%s\n", task.Title, attemptSuffix)
 	defer c.iterationSummary(record)
+	defer c.startProfile(record)()
+
+	c.emit(Event{
+		Type:        EventIterationStarted,
+		TaskID:      task.ID,
+		TaskTitle:   task.Title,
+		IterationID: record.IterationID,
+		Attempt:     record.AttemptNumber,
+	})
+	defer c.emitIterationFinished(task, record)
+	defer c.redactRecord(record)
 
 	// Create context with per-iteration timeout if configured
 	iterationCtx := ctx
@@ -553,35 +1166,67 @@ func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *It
 		defer cancel()
 	}
 
-	// Get base commit
-	baseCommit, err := c.gitManager.GetCurrentCommit(iterationCtx)
-	if err == nil {
-		record.BaseCommit = baseCommit
+	// Start retries from a clean tree if configured
+	if record.AttemptNumber > 1 && c.cleanRetries && !c.commitPerRun {
+		c.stashPreviousAttempt(iterationCtx, task, record.AttemptNumber-1)
+	}
+
+	// Get base commit (per_run iterations share it, so undo must not use it)
+	if !c.commitPerRun {
+		if baseCommit, err := c.gitManager.GetCurrentCommit(iterationCtx); err == nil {
+			record.BaseCommit = baseCommit
+		}
+	}
+
+	// Resolve the task's named verify set into its verify commands
+	expanded, err := task.ExpandVerifySet(c.verifySets)
+	if err != nil {
+		record.Complete(OutcomeFailed)
+		record.SetFeedback(err.Error())
+		c.handleTaskFailure(task.ID, record)
+		return record
+	}
+	taskVerifier, err := c.taskVerifier(expanded)
+	if err != nil {
+		record.Complete(OutcomeFailed)
+		record.SetFeedback(err.Error())
+		c.handleTaskFailure(task.ID, record)
+		return record
 	}
+	task = expanded
 
 	// Mark task as in progress
 	_ = c.taskStore.UpdateStatus(task.ID, taskstore.StatusInProgress)
 
 	// Build prompt for Claude
+	promptStart := time.Now()
 	systemPrompt, userPrompt, err := c.buildPrompt(iterationCtx, task)
+	c.profiler.track(PhasePrompt, promptStart)
 	if err != nil {
 		// Check if error is due to timeout or cancellation
 		if iterationCtx.Err() != nil {
 			record.Complete(OutcomeBudgetExceeded)
 			record.SetFeedback("Iteration timeout exceeded")
-			c.handleTaskFailure(task.ID)
+			c.handleTaskFailure(task.ID, record)
 			return record
 		}
 		record.Complete(OutcomeFailed)
 		record.SetFeedback(fmt.Sprintf("Failed to build prompt: %v", err))
-		c.handleTaskFailure(task.ID)
+		c.handleTaskFailure(task.ID, record)
 		return record
 	}
 
+	// Ask for a plan first when planning is enabled for this task. Its spend
+	// is recorded now so that a failed invocation doesn't drop it.
+	plan, planSpend := c.planTask(iterationCtx, task, userPrompt)
+	record.Plan = plan
+	record.ClaudeInvocation = planSpend
+
 	// Invoke Claude (initial attempt)
 	req := claude.ClaudeRequest{
 		SystemPrompt: systemPrompt,
-		Prompt:       userPrompt,
+		Prompt:       withPlan(userPrompt, plan),
+		Cwd:          c.codeDir(),
 	}
 
 	// Apply sandbox mode tool restrictions if enabled
@@ -596,12 +1241,12 @@ func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *It
 		if iterationCtx.Err() != nil {
 			record.Complete(OutcomeBudgetExceeded)
 			record.SetFeedback("Iteration timeout exceeded")
-			c.handleTaskFailure(task.ID)
+			c.handleTaskFailure(task.ID, record)
 			return record
 		}
 		record.Complete(OutcomeFailed)
 		record.SetFeedback(fmt.Sprintf("Claude invocation failed: %v", err))
-		c.handleTaskFailure(task.ID)
+		c.handleTaskFailure(task.ID, record)
 		return record
 	}
 
@@ -609,33 +1254,53 @@ func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *It
 	record.ClaudeInvocation = ClaudeInvocationMeta{
 		SessionID:    resp.SessionID,
 		Model:        resp.Model,
-		TotalCostUSD: resp.TotalCostUSD,
+		TotalCostUSD: resp.TotalCostUSD + planSpend.TotalCostUSD,
+		InputTokens:  resp.Usage.InputTokens + planSpend.InputTokens,
+		OutputTokens: resp.Usage.OutputTokens + planSpend.OutputTokens,
+	}
+	record.ClaudeInvocation.appendSession(resp.SessionID)
+	c.emitStep(task, record, Event{
+		Type:         EventClaudeInvoked,
+		CostUSD:      resp.TotalCostUSD,
 		InputTokens:  resp.Usage.InputTokens,
 		OutputTokens: resp.Usage.OutputTokens,
-	}
+	})
+	c.checkpoint(iterationCtx, task, record, resp.FinalText)
+	finalText := resp.FinalText
 
-	// Check for changes
+	// Check for changes (checkpoint commits count as progress)
 	hasChanges, err := c.gitManager.HasChanges(iterationCtx)
-	if err != nil || !hasChanges {
+	if err != nil || (!hasChanges && len(record.CheckpointCommits) == 0) {
 		// Check if error is due to timeout
 		if iterationCtx.Err() != nil {
 			record.Complete(OutcomeBudgetExceeded)
 			record.SetFeedback("Iteration timeout exceeded")
-			c.handleTaskFailure(task.ID)
+			c.handleTaskFailure(task.ID, record)
 			return record
 		}
 		record.Complete(OutcomeFailed)
 		record.SetFeedback("No changes made by Claude")
-		c.handleTaskFailure(task.ID)
+		c.handleTaskFailure(task.ID, record)
 		return record
 	}
 
 	// Get changed files
-	changedFiles, _ := c.gitManager.GetChangedFiles(iterationCtx)
-	record.FilesChanged = changedFiles
+	fileChanges, _ := c.gitManager.GetFileChanges(iterationCtx)
+	record.AddFileChanges(fileChanges)
+	if !c.checkScope(task, record, record.FilesChanged) {
+		return record
+	}
+	c.autoFormat(iterationCtx, record)
+	changedFiles := record.FilesChanged
+	if c.verbosity >= VerbosityVerbose {
+		if diffStat, _ := c.gitManager.GetDiffStat(iterationCtx); strings.TrimSpace(diffStat) != "" {
+			c.writeProgress("  Diff stat:\n%s\n", indentLines(strings.TrimRight(diffStat, "\n"), "    "))
+		}
+	}
 
 	// Run verification with retry loop
 	var results []verifier.VerificationResult
+	var buildFailed bool
 	verificationPassed := false
 	verificationAttempt := 1
 
@@ -643,48 +1308,73 @@ func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *It
 	// Config commands run first (typecheck/lint), then task commands (tests)
 	verifyCommands := c.mergeVerificationCommands(task.Verify)
 
-	if len(verifyCommands) > 0 {
+	// Skip commands whose verifyWhen glob matches none of the changed files
+	runCommands, fullVerify := c.selectVerifyCommands(verifyCommands, task.VerifyWhen, changedFiles)
+	if fullVerify {
+		c.writeProgress("  ↻ Running all verify commands (periodic full verification)\n")
+	} else if skipped := len(verifyCommands) - len(runCommands); skipped > 0 && len(runCommands) > 0 {
+		c.writeProgress("  ⏭ Skipped %d of %d verify commands (no matching changes)\n", skipped, len(verifyCommands))
+	}
+
+	if len(runCommands) > 0 {
 		for verificationAttempt <= c.maxVerificationRetries+1 {
 			// Run verification
-			results, err = c.verifier.Verify(iterationCtx, verifyCommands)
+			results, buildFailed, err = c.runVerification(iterationCtx, taskVerifier, runCommands)
 			if err != nil {
 				// Check if error is due to timeout
 				if iterationCtx.Err() != nil {
 					record.Complete(OutcomeBudgetExceeded)
 					record.SetFeedback("Iteration timeout exceeded during verification")
-					c.handleTaskFailure(task.ID)
+					c.handleTaskFailure(task.ID, record)
 					return record
 				}
 				record.Complete(OutcomeFailed)
 				record.SetFeedback(fmt.Sprintf("Verification error: %v", err))
-				c.handleTaskFailure(task.ID)
+				c.handleTaskFailure(task.ID, record)
 				return record
 			}
 
 			// Convert to VerificationOutput
 			record.VerificationOutputs = []VerificationOutput{} // Reset for each attempt
-			passedCount := 0
+			passedCount, warnedCount := 0, 0
 			for _, r := range results {
 				if r.Passed {
 					passedCount++
 				}
-				record.VerificationOutputs = append(record.VerificationOutputs, VerificationOutput{
-					Command:  r.Command,
-					Passed:   r.Passed,
-					Output:   r.Output,
-					Duration: r.Duration,
-				})
+				if r.Warning {
+					warnedCount++
+				}
+				record.VerificationOutputs = append(record.VerificationOutputs, NewVerificationOutput(r))
 			}
 			totalCount := len(results)
+			c.writeVerificationOutput(results)
+			verifyOutcome := "failed"
+			if record.AllPassed() {
+				verifyOutcome = "passed"
+			}
+			c.emitStep(task, record, Event{
+				Type:    EventVerificationResult,
+				Outcome: verifyOutcome,
+				Message: fmt.Sprintf("%d/%d passed", passedCount, totalCount),
+			})
 
 			// Check if all passed
 			if record.AllPassed() {
-				c.writeProgress("  ✓ Verification: %d/%d passed\n", passedCount, totalCount)
+				if warnedCount > 0 {
+					c.writeProgress("  ✓ Verification: %d/%d passed (%d with warnings)\n", passedCount, totalCount, warnedCount)
+				} else {
+					c.writeProgress("  ✓ Verification: %d/%d passed\n", passedCount, totalCount)
+				}
 				verificationPassed = true
+				record.VerificationPassedOnAttempt = verificationAttempt
 				break
 			}
 
-			c.writeProgress("  ✗ Verification: %d/%d passed\n", passedCount, totalCount)
+			if buildFailed {
+				c.writeProgress("  ✗ Build failed: %s\n", strings.Join(c.buildFirst, " "))
+			} else {
+				c.writeProgress("  ✗ Verification: %d/%d passed\n", passedCount, totalCount)
+			}
 
 			// If this was the last allowed attempt, fail
 			if verificationAttempt > c.maxVerificationRetries {
@@ -694,13 +1384,15 @@ func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *It
 			c.writeProgress("  ↻ Retrying (attempt %d/%d)...\n", verificationAttempt+1, c.maxVerificationRetries+1)
 
 			// Build retry prompt with failure context
-			systemPrompt, userPrompt, err = c.buildRetryPromptForVerificationFailure(iterationCtx, task, results, verificationAttempt)
+			promptStart := time.Now()
+			systemPrompt, userPrompt, err = c.buildRetryPromptForVerificationFailure(iterationCtx, task, results, buildFailed, verificationAttempt)
+			c.profiler.track(PhasePrompt, promptStart)
 			if err != nil {
 				// Check if error is due to timeout
 				if iterationCtx.Err() != nil {
 					record.Complete(OutcomeBudgetExceeded)
 					record.SetFeedback("Iteration timeout exceeded during retry")
-					c.handleTaskFailure(task.ID)
+					c.handleTaskFailure(task.ID, record)
 					return record
 				}
 				// If we can't build retry prompt, fail with current results
@@ -712,6 +1404,7 @@ func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *It
 				SystemPrompt: systemPrompt,
 				Prompt:       userPrompt,
 				Continue:     true, // Continue in the same session
+				Cwd:          c.codeDir(),
 			}
 
 			// Apply sandbox mode tool restrictions if enabled
@@ -725,7 +1418,7 @@ func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *It
 				if iterationCtx.Err() != nil {
 					record.Complete(OutcomeBudgetExceeded)
 					record.SetFeedback("Iteration timeout exceeded during retry")
-					c.handleTaskFailure(task.ID)
+					c.handleTaskFailure(task.ID, record)
 					return record
 				}
 				// If retry fails, break and use current verification results
@@ -737,45 +1430,99 @@ func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *It
 			record.ClaudeInvocation.InputTokens += retryResp.Usage.InputTokens
 			record.ClaudeInvocation.OutputTokens += retryResp.Usage.OutputTokens
 
+			// Track the continued session chain
+			record.ClaudeInvocation.Continued = true
+			record.ClaudeInvocation.appendSession(retryResp.SessionID)
+			c.emitStep(task, record, Event{
+				Type:         EventClaudeInvoked,
+				CostUSD:      retryResp.TotalCostUSD,
+				InputTokens:  retryResp.Usage.InputTokens,
+				OutputTokens: retryResp.Usage.OutputTokens,
+			})
+			c.checkpoint(iterationCtx, task, record, retryResp.FinalText)
+			finalText = retryResp.FinalText
+
 			// Update changed files (Claude may have modified more files)
-			changedFiles, _ = c.gitManager.GetChangedFiles(iterationCtx)
-			record.FilesChanged = changedFiles
+			retryChanges, _ := c.gitManager.GetFileChanges(iterationCtx)
+			previousFiles := len(record.FilesChanged)
+			record.AddFileChanges(retryChanges)
+			if !c.checkScope(task, record, record.FilesChanged[previousFiles:]) {
+				return record
+			}
+			c.autoFormat(iterationCtx, record)
+			changedFiles = record.FilesChanged
+			if !fullVerify {
+				runCommands = triggeredVerifyCommands(verifyCommands, task.VerifyWhen, changedFiles)
+			}
 
 			verificationAttempt++
 		}
 
 		if !verificationPassed {
 			record.Complete(OutcomeFailed)
-			record.SetFeedback(c.formatVerificationFeedback(results))
-			c.handleTaskFailure(task.ID)
+			if buildFailed {
+				record.SetFeedback(c.formatBuildFeedback(results))
+			} else {
+				record.SetFeedback(c.formatVerificationFeedback(results))
+			}
+			c.handleTaskFailure(task.ID, record)
 			return record
 		}
+	} else if len(verifyCommands) > 0 {
+		c.writeProgress("  ✓ Verification skipped (no changes match verifyWhen)\n")
 	} else {
-		c.writeProgress("  ✓ Verification skipped (no commands)\n")
+		record.Unverified = true
+		c.writeProgress("  ⚠ No verify commands: completing without verification\n")
 	}
 
-	// Commit changes
-	commitMsg := git.FormatCommitMessage(task.Title, record.IterationID)
-	commitHash, err := c.gitManager.Commit(iterationCtx, commitMsg)
+	// A response ending in a checkpoint marker means the agent stopped at a
+	// sub-goal, so the task is not finished even though verification passed
+	if c.checkpointsEnabled && !c.commitPerRun {
+		if _, ok := ParseCheckpoint(finalText); ok {
+			c.finishCheckpoint(iterationCtx, task, record)
+			return record
+		}
+	}
+
+	// Commit changes (all work may already be in checkpoint commits), or
+	// stage them for the run commit
+	var commitHash string
+	if c.commitPerRun {
+		err = c.stageResult(iterationCtx, task, record)
+	} else {
+		commitHash, err = c.commitResult(iterationCtx, task, record)
+	}
 	if err != nil {
 		// Check if error is due to timeout
 		if iterationCtx.Err() != nil {
 			record.Complete(OutcomeBudgetExceeded)
 			record.SetFeedback("Iteration timeout exceeded during commit")
-			c.handleTaskFailure(task.ID)
+			c.handleTaskFailure(task.ID, record)
 			return record
 		}
 		record.Complete(OutcomeFailed)
 		record.SetFeedback(fmt.Sprintf("Commit failed: %v", err))
-		c.handleTaskFailure(task.ID)
+		c.handleTaskFailure(task.ID, record)
 		return record
 	}
 
-	record.ResultCommit = commitHash
-	c.writeProgress("  📝 Committed: %s\n", commitHash)
+	if c.commitPerRun {
+		c.writeProgress("  📥 Staged changes (committed when the run completes)\n")
+	} else {
+		record.ResultCommit = commitHash
+		c.writeProgress("  📝 Committed: %s\n", commitHash)
+		c.emitStep(task, record, Event{Type: EventCommitted, Commit: commitHash})
+	}
 
-	// Mark task completed and reset attempt counter
-	_ = c.taskStore.UpdateStatus(task.ID, taskstore.StatusCompleted)
+	// Mark task completed (or awaiting review when unverified work must be
+	// checked) and reset attempt counter
+	if record.Unverified && c.requireVerifyCommands {
+		_ = c.taskStore.UpdateStatus(task.ID, taskstore.StatusAwaitingReview)
+		c.writeProgress("  ⏸ Task %s has no verify commands, awaiting review\n", task.ID)
+	} else {
+		_ = c.taskStore.UpdateStatus(task.ID, taskstore.StatusCompleted)
+	}
+	c.recordActuals(task.ID, record)
 	delete(c.taskAttempts, task.ID) // Clear attempt count on success
 
 	// Clear feedback file on success
@@ -786,10 +1533,12 @@ func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *It
 
 	// Update progress file
 	if c.progressFile != nil {
+		unlock := c.lockShared()
+		defer unlock()
 		entry := memory.IterationEntry{
 			TaskID:       task.ID,
 			TaskTitle:    task.Title,
-			WhatChanged:  []string{resp.FinalText},
+			WhatChanged:  []string{c.redactor.String(resp.FinalText)},
 			FilesTouched: changedFiles,
 			Outcome:      "Success",
 		}
@@ -807,25 +1556,112 @@ func (c *Controller) runIteration(ctx context.Context, task *taskstore.Task) *It
 	return record
 }
 
+// finishCheckpoint commits what the agent changed after its last checkpoint
+// and leaves the task open for the next iteration. The committed progress
+// resets the task's attempt count.
+func (c *Controller) finishCheckpoint(ctx context.Context, task *taskstore.Task, record *IterationRecord) {
+	commitHash, err := c.commitResult(ctx, task, record)
+	if err != nil {
+		if ctx.Err() != nil {
+			record.Complete(OutcomeBudgetExceeded)
+			record.SetFeedback("Iteration timeout exceeded during commit")
+		} else {
+			record.Complete(OutcomeFailed)
+			record.SetFeedback(fmt.Sprintf("Commit failed: %v", err))
+		}
+		c.handleTaskFailure(task.ID, record)
+		return
+	}
+
+	record.ResultCommit = commitHash
+	c.emitStep(task, record, Event{Type: EventCommitted, Commit: commitHash})
+	c.writeProgress("  ↻ Task %s is not finished, it stays open for the next iteration\n", task.ID)
+	_ = c.taskStore.UpdateStatus(task.ID, taskstore.StatusOpen)
+	delete(c.taskAttempts, task.ID)
+	record.Complete(OutcomeCheckpoint)
+}
+
+// stashPreviousAttempt stashes the uncommitted changes left by a failed attempt
+// so they are recoverable with `git stash` but don't carry into the retry.
+// Ralph's own state directory is left in place.
+func (c *Controller) stashPreviousAttempt(ctx context.Context, task *taskstore.Task, attempt int) {
+	hasChanges, err := c.gitManager.HasChanges(ctx)
+	if err != nil || !hasChanges {
+		return
+	}
+
+	message := fmt.Sprintf("ralph: %s attempt %d", task.ID, attempt)
+	if err := c.gitManager.StashChanges(ctx, message, []string{state.RalphDir}); err != nil {
+		c.writeProgress("  ⚠ Failed to stash previous attempt: %v\n", err)
+		return
+	}
+	c.writeProgress("  ↺ Stashed previous attempt's changes (%s)\n", message)
+}
+
+// commitResult commits the task's remaining changes. If everything was already
+// committed as checkpoints, the last checkpoint is the result.
+func (c *Controller) commitResult(ctx context.Context, task *taskstore.Task, record *IterationRecord) (string, error) {
+	if n := len(record.CheckpointCommits); n > 0 {
+		hasChanges, err := c.gitManager.HasChanges(ctx)
+		if err == nil && !hasChanges {
+			return record.CheckpointCommits[n-1], nil
+		}
+	}
+
+	commitMsg := c.commitMessage(task, task.Title, record.IterationID)
+	return c.gitManager.Commit(ctx, commitMsg)
+}
+
+// SetCommitTemplate sets the template for task commit messages (nil uses
+// git.DefaultCommitTemplate). Run commits in per_run mode keep their format.
+func (c *Controller) SetCommitTemplate(tmpl *git.CommitTemplate) {
+	c.commitTemplate = tmpl
+}
+
+// commitMessage renders the commit message for a commit of task titled title.
+func (c *Controller) commitMessage(task *taskstore.Task, title, iterationID string) string {
+	commitType := git.CommitTypeForTask(task.Title, task.Labels)
+	if c.commitTemplate != nil {
+		msg, err := c.commitTemplate.Format(git.CommitMessageData{
+			Type:        commitType,
+			Title:       title,
+			TaskID:      task.ID,
+			IterationID: iterationID,
+		})
+		if err == nil {
+			return msg
+		}
+		c.writeProgress("  ⚠ %v; using the default commit message\n", err)
+	}
+	return git.FormatCommitMessageWithType(commitType, title, iterationID)
+}
+
 // mergeVerificationCommands returns task-level verification commands.
 func (c *Controller) mergeVerificationCommands(taskVerify [][]string) [][]string {
 	return taskVerify
 }
 
+// previousAttempts returns the number of unsuccessful attempts at taskID. The
+// first time a task is seen, the count comes from the iteration records, so a
+// new run continues where the last one stopped, as ralph tasks prompt shows.
+func (c *Controller) previousAttempts(taskID string) int {
+	if attempts, ok := c.taskAttempts[taskID]; ok {
+		return attempts
+	}
+	if c.logsDir == "" {
+		return 0
+	}
+	records, err := c.loadRecords()
+	if err != nil {
+		return 0
+	}
+	return nextAttempt(records, taskID) - 1
+}
+
 // buildPrompt constructs the prompt for Claude using the full iteration prompt builder.
 // For retries (attemptNumber > 1), it uses the retry prompt builder with failure context.
 func (c *Controller) buildPrompt(ctx context.Context, task *taskstore.Task) (string, string, error) {
-	builder := prompt.NewBuilder(nil) // Use default size options
-
-	// Check if this is a retry (attempt > 1)
-	attemptNumber := c.taskAttempts[task.ID]
-	if attemptNumber > 1 {
-		// This is a retry - build retry prompt
-		return c.buildRetryPrompt(ctx, task, attemptNumber, builder)
-	}
-
-	// Initial attempt - build regular iteration prompt
-	return c.buildInitialPrompt(ctx, task, builder)
+	return c.BuildPromptForAttempt(ctx, task, c.taskAttempts[task.ID])
 }
 
 // buildInitialPrompt builds the prompt for the initial attempt.
@@ -845,12 +1681,23 @@ func (c *Controller) buildInitialPrompt(ctx context.Context, task *taskstore.Tas
 		changedFiles, _ = c.gitManager.GetChangedFiles(ctx)
 	}
 
+	// Load optional project-specific instructions
+	var customInstructions string
+	if c.workDir != "" {
+		if data, err := os.ReadFile(state.IterationPromptFilePath(c.workDir)); err == nil {
+			customInstructions = string(data)
+		}
+	}
+
 	// Build iteration context
 	iterCtx := prompt.IterationContext{
-		Task:             task,
-		CodebasePatterns: patterns,
-		DiffStat:         diffStat,
-		ChangedFiles:     changedFiles,
+		Task:               task,
+		CodebasePatterns:   patterns,
+		DiffStat:           diffStat,
+		ChangedFiles:       changedFiles,
+		CustomInstructions: customInstructions,
+		CheckpointsEnabled: c.checkpointsEnabled,
+		ContextFiles:       c.loadContextFiles(task),
 	}
 
 	// Build prompts using prompt builder
@@ -876,7 +1723,7 @@ func (c *Controller) buildRetryPrompt(ctx context.Context, task *taskstore.Task,
 	// Load the most recent iteration record to get failure output
 	var failureOutput string
 	var failureSignature string
-	if records, err := LoadAllIterationRecords(c.logsDir); err == nil {
+	if records, err := c.loadRecords(); err == nil {
 		// Find the most recent failed iteration for this task
 		for i := len(records) - 1; i >= 0; i-- {
 			if records[i].TaskID == task.ID && records[i].Outcome == OutcomeFailed {
@@ -901,6 +1748,14 @@ func (c *Controller) buildRetryPrompt(ctx context.Context, task *taskstore.Task,
 		}
 	}
 
+	// Show the previous attempt's changes so the agent builds on them
+	var diffStat string
+	var changedFiles []string
+	if hasChanges, _ := c.gitManager.HasChanges(ctx); hasChanges {
+		diffStat, _ = c.gitManager.GetDiffStat(ctx)
+		changedFiles, _ = c.gitManager.GetChangedFiles(ctx)
+	}
+
 	// Build retry context
 	retryCtx := prompt.RetryContext{
 		Task:             task,
@@ -908,6 +1763,8 @@ func (c *Controller) buildRetryPrompt(ctx context.Context, task *taskstore.Task,
 		FailureSignature: failureSignature,
 		UserFeedback:     userFeedback,
 		AttemptNumber:    attemptNumber,
+		DiffStat:         diffStat,
+		ChangedFiles:     changedFiles,
 	}
 
 	// Build retry prompts
@@ -920,7 +1777,8 @@ func (c *Controller) buildRetryPrompt(ctx context.Context, task *taskstore.Task,
 }
 
 // buildRetryPromptForVerificationFailure builds the prompt for an in-iteration verification retry.
-func (c *Controller) buildRetryPromptForVerificationFailure(ctx context.Context, task *taskstore.Task, results []verifier.VerificationResult, attemptNumber int) (string, string, error) {
+// When buildFailed is set, the failure output is marked as a build failure.
+func (c *Controller) buildRetryPromptForVerificationFailure(ctx context.Context, task *taskstore.Task, results []verifier.VerificationResult, buildFailed bool, attemptNumber int) (string, string, error) {
 	builder := prompt.NewBuilder(nil)
 
 	// Load user feedback if it exists (unlikely for in-iteration retries but check anyway)
@@ -935,17 +1793,15 @@ func (c *Controller) buildRetryPromptForVerificationFailure(ctx context.Context,
 	// Compute failure signature from current results
 	var verificationOutputs []VerificationOutput
 	for _, r := range results {
-		verificationOutputs = append(verificationOutputs, VerificationOutput{
-			Command:  r.Command,
-			Passed:   r.Passed,
-			Output:   r.Output,
-			Duration: r.Duration,
-		})
+		verificationOutputs = append(verificationOutputs, NewVerificationOutput(r))
 	}
 	failureSignature := ComputeFailureSignature(verificationOutputs)
 
 	// Trim failure output
 	failureOutput := verifier.TrimOutputForFeedback(results, verifier.DefaultTrimOptions())
+	if buildFailed {
+		failureOutput = buildFailedNote + "\n\n" + failureOutput
+	}
 
 	// Build retry context
 	retryCtx := prompt.RetryContext{
@@ -1004,8 +1860,91 @@ func (c *Controller) hasIncompleteDescendants(tasks []*taskstore.Task, parentID
 	return false
 }
 
+// addSucceeded files a successful iteration's task under the run result's
+// completed, unverified or awaiting review tasks.
+func (c *Controller) addSucceeded(result *RunResult, taskID string, record *IterationRecord) {
+	if record.Unverified && c.requireVerifyCommands {
+		result.AwaitingReviewTasks = append(result.AwaitingReviewTasks, taskID)
+		return
+	}
+	result.CompletedTasks = append(result.CompletedTasks, taskID)
+	if record.Unverified {
+		result.UnverifiedTasks = append(result.UnverifiedTasks, taskID)
+	}
+}
+
+// descendantsWithStatus returns the IDs of descendants of parentID that have status.
+func descendantsWithStatus(tasks []*taskstore.Task, parentID string, status taskstore.TaskStatus) []string {
+	var ids []string
+	for _, task := range taskstore.Descendants(tasks, parentID) {
+		if task.Status == status {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids
+}
+
+// unfinishedLeafDescendants returns the IDs of leaf descendants of parentID that
+// are neither completed nor skipped.
+func unfinishedLeafDescendants(tasks []*taskstore.Task, parentID string) []string {
+	children := make(map[string][]*taskstore.Task)
+	for _, t := range tasks {
+		if t.ParentID != nil {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		}
+	}
+
+	var unfinished []string
+	queue := children[parentID]
+	for len(queue) > 0 {
+		task := queue[0]
+		queue = queue[1:]
+
+		if kids := children[task.ID]; len(kids) > 0 {
+			queue = append(queue, kids...)
+			continue
+		}
+		if task.Status != taskstore.StatusCompleted && task.Status != taskstore.StatusSkipped {
+			unfinished = append(unfinished, task.ID)
+		}
+	}
+
+	return unfinished
+}
+
+// blockIfRepeatedlySelected tracks consecutive selections of the same task and
+// marks it blocked once it has been picked more times in a row than the retry
+// policy allows (1 initial attempt + maxRetries). This catches tasks that keep
+// returning to open without tripping gutter detection. Returns true if blocked.
+func (c *Controller) blockIfRepeatedlySelected(task *taskstore.Task) bool {
+	if task.ID == c.lastSelectedID {
+		c.consecutiveSelections++
+	} else {
+		c.lastSelectedID = task.ID
+		c.consecutiveSelections = 1
+	}
+
+	if c.consecutiveSelections <= c.maxRetries+1 {
+		return false
+	}
+
+	c.writeProgress("⚠ Task %s selected %d times in a row without progress, marking blocked\n\n", task.ID, c.consecutiveSelections)
+	_ = c.taskStore.UpdateStatus(task.ID, taskstore.StatusBlocked)
+	c.lastSelectedID = ""
+	c.consecutiveSelections = 0
+	return true
+}
+
 // handleTaskFailure handles a task failure, setting the appropriate status based on retry count.
-func (c *Controller) handleTaskFailure(taskID string) {
+// Failures whose feedback matches an unrecoverable pattern block the task without retrying.
+func (c *Controller) handleTaskFailure(taskID string, record *IterationRecord) {
+	if pattern := c.matchUnrecoverable(record.Feedback); pattern != "" {
+		c.writeProgress("  ⛔ Unrecoverable failure (matched %q), marking blocked without retrying\n", pattern)
+		record.SetFeedback(fmt.Sprintf("%s\n\nUnrecoverable failure (matched %q): task blocked without retrying.", record.Feedback, pattern))
+		_ = c.taskStore.UpdateStatus(taskID, taskstore.StatusBlocked)
+		return
+	}
+
 	attempts := c.taskAttempts[taskID]
 	// maxRetries is the number of retries allowed (not counting the initial attempt)
 	// So if maxRetries=2, we allow: 1 initial + 2 retries = 3 total attempts
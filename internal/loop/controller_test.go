@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"github.com/yarlson/ralph/internal/claude"
 	"github.com/yarlson/ralph/internal/git"
 	"github.com/yarlson/ralph/internal/memory"
+	"github.com/yarlson/ralph/internal/prompt"
 	"github.com/yarlson/ralph/internal/selector"
 	"github.com/yarlson/ralph/internal/state"
 	"github.com/yarlson/ralph/internal/taskstore"
@@ -162,12 +165,16 @@ type mockGitManager struct {
 	currentCommit string
 	hasChanges    bool
 	changedFiles  []string
+	fileChanges   []git.FileChange // overrides changedFiles for GetFileChanges
 	diffStat      string
 	commitHash    string
 	currentBranch string
 	commitMessage string
 	err           error
 	commitCalls   []string
+	stashCalls    []string
+	stageCalls    int
+	unstagedFiles []string
 }
 
 func (m *mockGitManager) Init(ctx context.Context) error {
@@ -221,6 +228,43 @@ func (m *mockGitManager) GetCurrentBranch(ctx context.Context) (string, error) {
 	return m.currentBranch, nil
 }
 
+func (m *mockGitManager) StashChanges(ctx context.Context, message string, exclude []string) error {
+	m.stashCalls = append(m.stashCalls, message)
+	if m.err != nil {
+		return m.err
+	}
+	m.hasChanges = false
+	m.changedFiles = nil
+	m.diffStat = ""
+	return nil
+}
+
+func (m *mockGitManager) GetFileChanges(ctx context.Context) ([]git.FileChange, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.fileChanges != nil {
+		return m.fileChanges, nil
+	}
+	changes := make([]git.FileChange, 0, len(m.changedFiles))
+	for _, f := range m.changedFiles {
+		changes = append(changes, git.FileChange{Path: f, Status: git.FileModified})
+	}
+	return changes, nil
+}
+
+func (m *mockGitManager) Stage(ctx context.Context) error {
+	m.stageCalls++
+	return m.err
+}
+
+func (m *mockGitManager) GetUnstagedFiles(ctx context.Context) ([]string, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.unstagedFiles, nil
+}
+
 func (m *mockGitManager) GetCommitMessage(ctx context.Context, hash string) (string, error) {
 	if m.err != nil {
 		return "", m.err
@@ -376,6 +420,146 @@ func TestController_RunLoop_SingleIteration_Success(t *testing.T) {
 	assert.Equal(t, taskstore.StatusCompleted, store.tasks["child"].Status)
 }
 
+func TestController_RunLoop_StampsAnnotations(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("child", "Child Task", taskstore.StatusOpen, strPtr("parent")))
+
+	logsDir := t.TempDir()
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess-123", FinalText: "done"}},
+		Verifier:  &mockVerifier{},
+		Git: &mockGitManager{
+			currentCommit: "abc123",
+			hasChanges:    true,
+			changedFiles:  []string{"file1.go"},
+			commitHash:    "def456",
+		},
+		LogsDir: logsDir,
+	})
+	ctrl.SetAnnotations(map[string]string{"build": "1234"})
+
+	result := ctrl.RunLoop(context.Background(), "parent")
+	require.Equal(t, RunOutcomeCompleted, result.Outcome)
+
+	records, err := LoadAllIterationRecords(logsDir)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, map[string]string{"build": "1234"}, records[0].Annotations)
+}
+
+func TestController_RunLoop_RequireAllCompleted(t *testing.T) {
+	tests := []struct {
+		name        string
+		require     bool
+		otherStatus taskstore.TaskStatus
+		wantOutcome RunLoopOutcome
+	}{
+		{name: "failed task counts as completed by default", otherStatus: taskstore.StatusFailed, wantOutcome: RunOutcomeCompleted},
+		{name: "failed task blocks when required", require: true, otherStatus: taskstore.StatusFailed, wantOutcome: RunOutcomeBlocked},
+		{name: "skipped task is ignored when required", require: true, otherStatus: taskstore.StatusSkipped, wantOutcome: RunOutcomeCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+			store.addTask(newTestTask("done", "Done Task", taskstore.StatusCompleted, strPtr("parent")))
+			store.addTask(newTestTask("other", "Other Task", tt.otherStatus, strPtr("parent")))
+
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &mockClaudeRunner{},
+				Verifier:  &mockVerifier{},
+				Git:       &mockGitManager{},
+				LogsDir:   t.TempDir(),
+			})
+			ctrl.SetRequireAllCompleted(tt.require)
+
+			result := ctrl.RunLoop(context.Background(), "parent")
+
+			assert.Equal(t, tt.wantOutcome, result.Outcome)
+			if tt.wantOutcome == RunOutcomeBlocked {
+				assert.Contains(t, result.Message, "not completed: other")
+			}
+		})
+	}
+}
+
+func TestController_RunLoop_RequireVerifyCommands(t *testing.T) {
+	tests := []struct {
+		name           string
+		require        bool
+		wantStatus     taskstore.TaskStatus
+		wantOutcome    RunLoopOutcome
+		wantCompleted  []string
+		wantUnverified []string
+		wantAwaiting   []string
+	}{
+		{
+			name:           "completes unverified by default",
+			wantStatus:     taskstore.StatusCompleted,
+			wantOutcome:    RunOutcomeCompleted,
+			wantCompleted:  []string{"child"},
+			wantUnverified: []string{"child"},
+		},
+		{
+			name:         "awaits review when required",
+			require:      true,
+			wantStatus:   taskstore.StatusAwaitingReview,
+			wantOutcome:  RunOutcomeBlocked,
+			wantAwaiting: []string{"child"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+			child := newTestTask("child", "Child Task", taskstore.StatusOpen, strPtr("parent"))
+			child.Verify = nil
+			store.addTask(child)
+
+			var progress bytes.Buffer
+			ctrl := NewController(ControllerDeps{
+				TaskStore:      store,
+				Claude:         &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess-123", FinalText: "Done"}},
+				Verifier:       &mockVerifier{},
+				Git:            &mockGitManager{currentCommit: "abc123", hasChanges: true, changedFiles: []string{"main.go"}, commitHash: "def456"},
+				LogsDir:        t.TempDir(),
+				ProgressWriter: &progress,
+			})
+			ctrl.SetRequireVerifyCommands(tt.require)
+
+			result := ctrl.RunLoop(context.Background(), "parent")
+
+			assert.Equal(t, tt.wantOutcome, result.Outcome)
+			assert.Equal(t, tt.wantCompleted, nilIfEmpty(result.CompletedTasks))
+			assert.Equal(t, tt.wantUnverified, result.UnverifiedTasks)
+			assert.Equal(t, tt.wantAwaiting, result.AwaitingReviewTasks)
+			require.Len(t, result.Records, 1)
+			assert.True(t, result.Records[0].Unverified)
+			assert.Contains(t, progress.String(), "No verify commands: completing without verification")
+
+			got, err := store.Get("child")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, got.Status)
+			if tt.require {
+				assert.Contains(t, result.Message, "awaiting review: child")
+			}
+		})
+	}
+}
+
+// nilIfEmpty returns nil for an empty slice so it compares equal to an unset one.
+func nilIfEmpty(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
 func TestController_RunLoop_VerificationFails(t *testing.T) {
 	store := newMockTaskStore()
 
@@ -479,6 +663,34 @@ func TestController_RunLoop_BudgetExceeded(t *testing.T) {
 	assert.Equal(t, 2, result.IterationsRun)
 }
 
+func TestController_RunLoop_TokenBudgetExceeded(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	for _, id := range []string{"child-a", "child-b", "child-c"} {
+		store.addTask(newTestTask(id, id, taskstore.StatusOpen, strPtr("parent")))
+	}
+
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude: &mockClaudeRunner{response: &claude.ClaudeResponse{
+			SessionID: "sess",
+			FinalText: "Done",
+			Usage:     claude.ClaudeUsage{InputTokens: 600, OutputTokens: 50},
+		}},
+		Verifier:    &mockVerifier{results: []verifier.VerificationResult{{Passed: true, Command: []string{"echo"}}}},
+		Git:         &mockGitManager{currentCommit: "abc", hasChanges: true, changedFiles: []string{"f.go"}, commitHash: "def"},
+		LogsDir:     t.TempDir(),
+		ProgressDir: t.TempDir(),
+	})
+	ctrl.SetBudgetLimits(BudgetLimits{MaxInputTokens: 1000})
+
+	result := ctrl.RunLoop(context.Background(), "parent")
+
+	assert.Equal(t, RunOutcomeBudgetExceeded, result.Outcome)
+	assert.Equal(t, "max input token limit reached (1200/1000)", result.Message)
+	assert.Equal(t, 2, result.IterationsRun)
+}
+
 func TestController_RunLoop_ContextCancellation(t *testing.T) {
 	store := newMockTaskStore()
 
@@ -722,6 +934,129 @@ func TestController_GetSummary(t *testing.T) {
 	assert.Equal(t, 1, summary.FailedCount)
 }
 
+func TestController_RunOnce_ContinueOnFailure(t *testing.T) {
+	tests := []struct {
+		name              string
+		continueOnFailure bool
+		wantOutcome       RunLoopOutcome
+		wantCalls         int
+	}{
+		{name: "dependent of failed task stays blocked by default", continueOnFailure: false, wantOutcome: RunOutcomeBlocked, wantCalls: 0},
+		{name: "dependent of failed task runs when enabled", continueOnFailure: true, wantOutcome: RunOutcomeCompleted, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+			store.addTask(newTestTask("a", "Task A", taskstore.StatusFailed, strPtr("parent")))
+			taskB := newTestTask("b", "Task B", taskstore.StatusOpen, strPtr("parent"))
+			taskB.DependsOn = []string{"a"}
+			store.addTask(taskB)
+
+			claudeRunner := &mockClaudeRunner{
+				response: &claude.ClaudeResponse{FinalText: "Done", TotalCostUSD: 0.01},
+			}
+			deps := ControllerDeps{
+				TaskStore: store,
+				Claude:    claudeRunner,
+				Verifier: &mockVerifier{
+					results: []verifier.VerificationResult{{Passed: true, Command: []string{"echo"}}},
+				},
+				Git: &mockGitManager{
+					currentCommit: "abc123",
+					hasChanges:    true,
+					changedFiles:  []string{"file1.go"},
+					commitHash:    "def456",
+				},
+				LogsDir:     t.TempDir(),
+				ProgressDir: t.TempDir(),
+			}
+
+			ctrl := NewController(deps)
+			ctrl.SetContinueOnFailure(tt.continueOnFailure)
+			result := ctrl.RunOnce(context.Background(), "parent")
+
+			assert.Equal(t, tt.wantOutcome, result.Outcome)
+			assert.Len(t, claudeRunner.calls, tt.wantCalls)
+		})
+	}
+}
+
+func TestController_BlockIfRepeatedlySelected(t *testing.T) {
+	store := newMockTaskStore()
+	stuck := newTestTask("stuck", "Stuck", taskstore.StatusOpen, nil)
+	other := newTestTask("other", "Other", taskstore.StatusOpen, nil)
+	store.addTask(stuck)
+	store.addTask(other)
+
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    &mockClaudeRunner{},
+		Verifier:  &mockVerifier{},
+		Git:       &mockGitManager{},
+		LogsDir:   t.TempDir(),
+	})
+	ctrl.SetMaxRetries(2)
+
+	// Selecting another task in between resets the count
+	assert.False(t, ctrl.blockIfRepeatedlySelected(stuck))
+	assert.False(t, ctrl.blockIfRepeatedlySelected(stuck))
+	assert.False(t, ctrl.blockIfRepeatedlySelected(other))
+
+	// 1 initial attempt + 2 retries are allowed, the 4th selection in a row is not
+	for i := 0; i < 3; i++ {
+		assert.False(t, ctrl.blockIfRepeatedlySelected(stuck))
+	}
+	assert.True(t, ctrl.blockIfRepeatedlySelected(stuck))
+	assert.Equal(t, taskstore.StatusBlocked, store.tasks["stuck"].Status)
+	assert.Equal(t, taskstore.StatusOpen, store.tasks["other"].Status)
+}
+
+func TestController_HandleTaskFailure_UnrecoverablePatterns(t *testing.T) {
+	tests := []struct {
+		name       string
+		feedback   string
+		attempts   int
+		wantStatus taskstore.TaskStatus
+	}{
+		{name: "unmatched failure is retried", feedback: "Verification failed: FAIL TestFoo", attempts: 1, wantStatus: taskstore.StatusOpen},
+		{name: "matched failure is blocked immediately", feedback: "Claude invocation failed: no editor found", attempts: 1, wantStatus: taskstore.StatusBlocked},
+		{name: "unmatched failure fails after retries", feedback: "Verification failed", attempts: 3, wantStatus: taskstore.StatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("task", "Task", taskstore.StatusInProgress, nil))
+
+			ctrl := NewController(ControllerDeps{TaskStore: store, Git: &mockGitManager{}})
+			ctrl.SetMaxRetries(2)
+			require.NoError(t, ctrl.SetUnrecoverablePatterns([]string{`no editor found`, `(?i)cannot install`}))
+			ctrl.taskAttempts["task"] = tt.attempts
+
+			record := NewIterationRecord("task")
+			record.SetFeedback(tt.feedback)
+			ctrl.handleTaskFailure("task", record)
+
+			assert.Equal(t, tt.wantStatus, store.tasks["task"].Status)
+			if tt.wantStatus == taskstore.StatusBlocked {
+				assert.Contains(t, record.Feedback, `Unrecoverable failure (matched "no editor found")`)
+			} else {
+				assert.Equal(t, tt.feedback, record.Feedback)
+			}
+		})
+	}
+}
+
+func TestController_SetUnrecoverablePatterns_InvalidRegex(t *testing.T) {
+	ctrl := NewController(ControllerDeps{TaskStore: newMockTaskStore(), Git: &mockGitManager{}})
+
+	err := ctrl.SetUnrecoverablePatterns([]string{"ok", "("})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid unrecoverable pattern "("`)
+}
+
 func TestController_RunLoop_WithDependencyGraph(t *testing.T) {
 	store := newMockTaskStore()
 
@@ -862,10 +1197,31 @@ func (m *dynamicGitManager) GetCurrentBranch(ctx context.Context) (string, error
 	return "main", nil
 }
 
+func (m *dynamicGitManager) StashChanges(ctx context.Context, message string, exclude []string) error {
+	return nil
+}
+
 func (m *dynamicGitManager) GetCommitMessage(ctx context.Context, hash string) (string, error) {
 	return "commit message", nil
 }
 
+func (m *dynamicGitManager) GetFileChanges(ctx context.Context) ([]git.FileChange, error) {
+	files, _ := m.GetChangedFiles(ctx)
+	changes := make([]git.FileChange, 0, len(files))
+	for _, f := range files {
+		changes = append(changes, git.FileChange{Path: f, Status: git.FileModified})
+	}
+	return changes, nil
+}
+
+func (m *dynamicGitManager) Stage(ctx context.Context) error {
+	return nil
+}
+
+func (m *dynamicGitManager) GetUnstagedFiles(ctx context.Context) ([]string, error) {
+	return m.GetChangedFiles(ctx)
+}
+
 func indexOf(slice []string, item string) int {
 	for i, s := range slice {
 		if s == item {
@@ -1048,7 +1404,7 @@ func TestController_RunIteration_EmitsProgressOutput(t *testing.T) {
 	assert.Contains(t, output, "📝 Committed: def456")
 	assert.Contains(t, output, "Completed in")
 	assert.Contains(t, output, "$0.0123")
-	assert.Contains(t, output, "1 file changed")
+	assert.Contains(t, output, "1 file changed (1 modified)")
 }
 
 func TestBuildGraph_ForSelector(t *testing.T) {
@@ -1082,6 +1438,116 @@ func (m *mockClaudeRunnerWithCallback) Run(ctx context.Context, req claude.Claud
 	}, nil
 }
 
+// sessionSequenceRunner returns a new session ID for each invocation and records requests.
+type sessionSequenceRunner struct {
+	calls []claude.ClaudeRequest
+}
+
+func (m *sessionSequenceRunner) Run(ctx context.Context, req claude.ClaudeRequest) (*claude.ClaudeResponse, error) {
+	m.calls = append(m.calls, req)
+	return &claude.ClaudeResponse{
+		SessionID:    fmt.Sprintf("sess-%d", len(m.calls)),
+		FinalText:    "Done",
+		TotalCostUSD: 0.01,
+	}, nil
+}
+
+func TestController_RunIteration_RecordsSessionChain(t *testing.T) {
+	store := newMockTaskStore()
+	task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+	task.Verify = [][]string{{"go", "test"}}
+	store.addTask(task)
+
+	// Fail verification once, then pass
+	verifyCalls := 0
+	verifierMock := &mockVerifier{
+		verifyFn: func(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+			verifyCalls++
+			return []verifier.VerificationResult{{Passed: verifyCalls > 1, Command: []string{"go", "test"}, Output: "FAIL"}}, nil
+		},
+	}
+
+	claudeRunner := &sessionSequenceRunner{}
+	deps := ControllerDeps{
+		TaskStore: store,
+		Claude:    claudeRunner,
+		Verifier:  verifierMock,
+		Git: &mockGitManager{
+			currentCommit: "abc123",
+			hasChanges:    true,
+			changedFiles:  []string{"file1.go"},
+			commitHash:    "def456",
+		},
+		LogsDir:     t.TempDir(),
+		ProgressDir: t.TempDir(),
+	}
+
+	ctrl := NewController(deps)
+	record := ctrl.runIteration(context.Background(), task)
+
+	require.Len(t, claudeRunner.calls, 2)
+	assert.True(t, claudeRunner.calls[1].Continue)
+	assert.Equal(t, OutcomeSuccess, record.Outcome)
+	assert.Equal(t, "sess-1", record.ClaudeInvocation.SessionID)
+	assert.Equal(t, []string{"sess-1", "sess-2"}, record.ClaudeInvocation.SessionIDs)
+	assert.True(t, record.ClaudeInvocation.Continued)
+	assert.Equal(t, 2, record.VerificationPassedOnAttempt)
+}
+
+func TestController_RunIteration_RetryPreservesChanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		preserve   bool
+		wantStash  bool
+		wantPrompt bool
+	}{
+		{name: "preserves previous attempt by default", preserve: true, wantStash: false, wantPrompt: true},
+		{name: "stashes previous attempt when disabled", preserve: false, wantStash: true, wantPrompt: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+			task.Verify = [][]string{{"go", "test"}}
+			store.addTask(task)
+
+			gitMock := &mockGitManager{
+				currentCommit: "abc123",
+				hasChanges:    true,
+				diffStat:      " file1.go | 2 +-",
+				changedFiles:  []string{"file1.go"},
+				commitHash:    "def456",
+			}
+			claudeRunner := &sessionSequenceRunner{}
+			ctrl := NewController(ControllerDeps{
+				TaskStore:   store,
+				Claude:      claudeRunner,
+				Verifier:    &mockVerifier{results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}}},
+				Git:         gitMock,
+				LogsDir:     t.TempDir(),
+				ProgressDir: t.TempDir(),
+			})
+			ctrl.SetPreserveChanges(tt.preserve)
+			ctrl.taskAttempts[task.ID] = 1
+
+			ctrl.runIteration(context.Background(), task)
+
+			if tt.wantStash {
+				assert.Equal(t, []string{"ralph: task-1 attempt 1"}, gitMock.stashCalls)
+			} else {
+				assert.Empty(t, gitMock.stashCalls)
+			}
+			require.NotEmpty(t, claudeRunner.calls)
+			if tt.wantPrompt {
+				assert.Contains(t, claudeRunner.calls[0].Prompt, "Changes From Previous Attempt")
+			} else {
+				assert.NotContains(t, claudeRunner.calls[0].Prompt, "Changes From Previous Attempt")
+			}
+		})
+	}
+}
+
 func TestController_RunLoop_ChecksPauseBetweenIterations(t *testing.T) {
 	// Create a temp dir for .ralph state
 	workDir := t.TempDir()
@@ -1149,6 +1615,108 @@ func TestController_RunLoop_ChecksPauseBetweenIterations(t *testing.T) {
 	assert.Equal(t, taskstore.StatusOpen, store.tasks["child2"].Status)
 }
 
+func TestController_RunLoop_ResumesWithPersistedGutterState(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, state.EnsureRalphDir(workDir))
+
+	// A previous run left the same failure recorded three times
+	require.NoError(t, SaveGutterState(state.GutterStateFilePath(workDir), GutterState{
+		FailureSignatures: map[string]int{"deadbeefcafe": 3},
+	}))
+
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("child", "Child", taskstore.StatusOpen, strPtr("parent")))
+
+	claudeRunner := &mockClaudeRunner{}
+	deps := ControllerDeps{
+		TaskStore:   store,
+		Claude:      claudeRunner,
+		Verifier:    &mockVerifier{},
+		Git:         &mockGitManager{},
+		LogsDir:     t.TempDir(),
+		ProgressDir: t.TempDir(),
+		WorkDir:     workDir,
+	}
+
+	ctrl := NewController(deps)
+	result := ctrl.RunLoop(context.Background(), "parent")
+
+	assert.Equal(t, RunOutcomeGutterDetected, result.Outcome)
+	assert.Equal(t, 0, result.IterationsRun)
+	assert.Empty(t, claudeRunner.calls)
+	require.NotNil(t, result.GutterInfo)
+	assert.Equal(t, GutterReasonRepeatedFailure, result.GutterInfo.Reason)
+	assert.Equal(t, "deadbeefcafe", result.GutterInfo.FailureSignature)
+	assert.Equal(t, 3, result.GutterInfo.Iterations)
+}
+
+func TestController_RunLoop_ClearsGutterStateOnCompletion(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, state.EnsureRalphDir(workDir))
+
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("child", "Child", taskstore.StatusOpen, strPtr("parent")))
+
+	deps := ControllerDeps{
+		TaskStore: store,
+		Claude: &mockClaudeRunner{
+			response: &claude.ClaudeResponse{FinalText: "Done", TotalCostUSD: 0.01},
+		},
+		Verifier: &mockVerifier{
+			results: []verifier.VerificationResult{{Passed: true, Command: []string{"echo"}}},
+		},
+		Git: &mockGitManager{
+			currentCommit: "abc123",
+			hasChanges:    true,
+			changedFiles:  []string{"file1.go"},
+			commitHash:    "def456",
+		},
+		LogsDir:     t.TempDir(),
+		ProgressDir: t.TempDir(),
+		WorkDir:     workDir,
+	}
+
+	ctrl := NewController(deps)
+	result := ctrl.RunLoop(context.Background(), "parent")
+
+	assert.Equal(t, RunOutcomeCompleted, result.Outcome)
+	_, err := os.Stat(state.GutterStateFilePath(workDir))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestController_BuildInitialPrompt_CustomInstructions(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, state.EnsureRalphDir(workDir))
+
+	deps := ControllerDeps{
+		TaskStore: newMockTaskStore(),
+		Claude:    &mockClaudeRunner{},
+		Verifier:  &mockVerifier{},
+		Git:       &mockGitManager{},
+		LogsDir:   t.TempDir(),
+		WorkDir:   workDir,
+	}
+	ctrl := NewController(deps)
+	task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+
+	t.Run("unchanged without prompt file", func(t *testing.T) {
+		_, userPrompt, err := ctrl.buildInitialPrompt(context.Background(), task, prompt.NewBuilder(nil))
+		require.NoError(t, err)
+		assert.NotContains(t, userPrompt, "### Project Instructions")
+	})
+
+	t.Run("appends prompt file content", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(state.PromptsDirPath(workDir), 0755))
+		require.NoError(t, os.WriteFile(state.IterationPromptFilePath(workDir), []byte("Prefer table-driven tests."), 0644))
+
+		_, userPrompt, err := ctrl.buildInitialPrompt(context.Background(), task, prompt.NewBuilder(nil))
+		require.NoError(t, err)
+		assert.Contains(t, userPrompt, "Prefer table-driven tests.")
+	})
+}
+
 func TestController_MergeVerificationCommands_NoConfigCommands(t *testing.T) {
 	store := newMockTaskStore()
 	deps := ControllerDeps{
@@ -1346,6 +1914,58 @@ func TestController_EnsureFeatureBranch_AutoGenerate(t *testing.T) {
 	assert.Equal(t, "feature-my-awesome-feature", capturedBranch)
 }
 
+// uninitializedGitManager reports "not a git repository" until Init is called.
+type uninitializedGitManager struct {
+	mockGitManager
+	initialized bool
+}
+
+func (m *uninitializedGitManager) Init(ctx context.Context) error {
+	m.initialized = true
+	return nil
+}
+
+func (m *uninitializedGitManager) EnsureBranch(ctx context.Context, branchName string) error {
+	if !m.initialized {
+		return git.ErrNotAGitRepo
+	}
+	return nil
+}
+
+func TestController_EnsureFeatureBranch_AutoInit(t *testing.T) {
+	tests := []struct {
+		name     string
+		autoInit bool
+		wantErr  string
+	}{
+		{name: "initializes a repository by default", autoInit: true},
+		{name: "fails when auto-init is disabled", autoInit: false, wantErr: "not a git repository; run git init or set git.auto_init"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.tasks["parent1"] = newTestTask("parent1", "Feature", taskstore.StatusOpen, nil)
+
+			gitMgr := &uninitializedGitManager{}
+			ctrl := NewController(ControllerDeps{TaskStore: store, Git: gitMgr})
+			ctrl.SetGitAutoInit(tt.autoInit)
+
+			err := ctrl.ensureFeatureBranch(context.Background(), "parent1")
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, git.ErrNotAGitRepo)
+				assert.EqualError(t, err, tt.wantErr)
+				assert.False(t, gitMgr.initialized, "git init must not run")
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, gitMgr.initialized)
+		})
+	}
+}
+
 func TestController_EnsureFeatureBranch_WithOverride(t *testing.T) {
 	// Create controller with mocks
 	store := newMockTaskStore()
@@ -1375,6 +1995,220 @@ func TestController_EnsureFeatureBranch_WithOverride(t *testing.T) {
 	assert.Equal(t, "custom-branch", capturedBranch)
 }
 
+func TestController_EnsureFeatureBranch_StoredBranch(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, state.EnsureRalphDir(workDir))
+
+	store := newMockTaskStore()
+	store.tasks["parent1"] = newTestTask("parent1", "Feature: Original Title", taskstore.StatusOpen, nil)
+
+	var capturedBranch string
+	newCtrl := func(override string) *Controller {
+		ctrl := NewController(ControllerDeps{
+			TaskStore: store,
+			Git: &dynamicGitManager{
+				ensureBranchFn: func(ctx context.Context, branch string) error {
+					capturedBranch = branch
+					return nil
+				},
+			},
+			WorkDir: workDir,
+		})
+		ctrl.SetBranchOverride(override)
+		return ctrl
+	}
+
+	// First run stores the generated branch
+	require.NoError(t, newCtrl("").ensureFeatureBranch(context.Background(), "parent1"))
+	assert.Equal(t, "feature-original-title", capturedBranch)
+
+	// A title edit does not change the branch
+	store.tasks["parent1"].Title = "Feature: Renamed"
+	require.NoError(t, newCtrl("").ensureFeatureBranch(context.Background(), "parent1"))
+	assert.Equal(t, "feature-original-title", capturedBranch)
+
+	// An override is used and remembered for later runs
+	require.NoError(t, newCtrl("custom-branch").ensureFeatureBranch(context.Background(), "parent1"))
+	assert.Equal(t, "custom-branch", capturedBranch)
+	require.NoError(t, newCtrl("").ensureFeatureBranch(context.Background(), "parent1"))
+	assert.Equal(t, "custom-branch", capturedBranch)
+
+	stored, err := state.GetStoredBranch(workDir, "parent1")
+	require.NoError(t, err)
+	assert.Equal(t, "custom-branch", stored)
+}
+
+func TestController_EnsureFeatureBranch_ProtectedBranch(t *testing.T) {
+	tests := []struct {
+		name          string
+		currentBranch string
+		override      string
+		wantErr       bool
+	}{
+		{name: "refuses protected branch", currentBranch: "main", wantErr: true},
+		{name: "allows feature branch", currentBranch: "ralph/feature-name", wantErr: false},
+		{name: "override bypasses protection", currentBranch: "main", override: "main", wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.tasks["parent1"] = newTestTask("parent1", "Feature Name", taskstore.StatusOpen, nil)
+
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Git:       &mockGitManager{currentBranch: tt.currentBranch},
+			})
+			ctrl.SetProtectedBranches([]string{"main", "master"})
+			if tt.override != "" {
+				ctrl.SetBranchOverride(tt.override)
+			}
+
+			err := ctrl.ensureFeatureBranch(context.Background(), "parent1")
+			if tt.wantErr {
+				require.ErrorIs(t, err, git.ErrProtectedBranch)
+				assert.Contains(t, err.Error(), "refusing to run on protected branch")
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestController_EnsureFeatureBranch_ProtectedBranchRealGit(t *testing.T) {
+	tests := []struct {
+		name     string
+		startOn  string
+		override string
+		wantErr  bool
+	}{
+		{name: "refuses to start from main", startOn: "main", wantErr: true},
+		{name: "continues from the feature branch", startOn: "ralph/feature", wantErr: false},
+		{name: "override bypasses protection", startOn: "main", override: "work", wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, store := setupParallelRepo(t, nil)
+			if tt.startOn != "main" {
+				out, err := exec.Command("git", "-C", dir, "checkout", "-b", tt.startOn).CombinedOutput()
+				require.NoError(t, err, string(out))
+			}
+			manager := git.NewShellManager(dir, "ralph/")
+
+			ctrl := NewController(ControllerDeps{TaskStore: store, Git: manager})
+			ctrl.SetProtectedBranches([]string{"main", "master"})
+			if tt.override != "" {
+				ctrl.SetBranchOverride(tt.override)
+			}
+
+			err := ctrl.ensureFeatureBranch(context.Background(), "feature")
+			current, branchErr := manager.GetCurrentBranch(context.Background())
+			require.NoError(t, branchErr)
+			if tt.wantErr {
+				require.ErrorIs(t, err, git.ErrProtectedBranch)
+				assert.Equal(t, "main", current, "nothing is checked out")
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEqual(t, "main", current)
+		})
+	}
+}
+
+func TestController_EnsureCleanTree(t *testing.T) {
+	tests := []struct {
+		name         string
+		require      bool
+		stash        bool
+		changedFiles []string
+		gitErr       error
+		wantErr      error
+		wantStashed  bool
+	}{
+		{name: "disabled ignores dirty tree", changedFiles: []string{"main.go"}},
+		{name: "clean tree passes", require: true},
+		{name: "ralph state is ignored", require: true, changedFiles: []string{".ralph/", ".ralph/tasks/t1.yaml"}},
+		{name: "dirty tree is refused", require: true, changedFiles: []string{"main.go", ".ralph/state/x"}, wantErr: git.ErrDirtyWorkingTree},
+		{name: "dirty tree is stashed", require: true, stash: true, changedFiles: []string{"main.go"}, wantStashed: true},
+		{name: "stash works without require", stash: true, changedFiles: []string{"main.go"}, wantStashed: true},
+		{name: "missing repository is skipped", require: true, gitErr: git.ErrNotAGitRepo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitMgr := &mockGitManager{changedFiles: tt.changedFiles, err: tt.gitErr}
+			var progress bytes.Buffer
+			ctrl := NewController(ControllerDeps{TaskStore: newMockTaskStore(), Git: gitMgr, ProgressWriter: &progress})
+			ctrl.SetRequireCleanTree(tt.require)
+			ctrl.SetStashDirty(tt.stash)
+
+			err := ctrl.ensureCleanTree(context.Background())
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Contains(t, err.Error(), "main.go")
+				assert.Contains(t, err.Error(), "--stash-dirty")
+				assert.NotContains(t, err.Error(), ".ralph")
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tt.wantStashed {
+				assert.Len(t, gitMgr.stashCalls, 1)
+				assert.Contains(t, progress.String(), "git stash pop")
+			} else {
+				assert.Empty(t, gitMgr.stashCalls)
+			}
+		})
+	}
+}
+
+func TestController_RunLoop_RefusesDirtyTree(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("child", "Child Task", taskstore.StatusOpen, strPtr("parent")))
+
+	claudeRunner := &mockClaudeRunner{response: &claude.ClaudeResponse{FinalText: "done"}}
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    claudeRunner,
+		Verifier:  &mockVerifier{},
+		Git:       &mockGitManager{hasChanges: true, changedFiles: []string{"main.go"}},
+		LogsDir:   t.TempDir(),
+	})
+	ctrl.SetRequireCleanTree(true)
+
+	result := ctrl.RunLoop(context.Background(), "parent")
+
+	assert.Equal(t, RunOutcomeError, result.Outcome)
+	assert.Contains(t, result.Message, "uncommitted changes")
+	assert.Equal(t, 0, result.IterationsRun)
+	assert.Equal(t, taskstore.StatusOpen, store.tasks["child"].Status)
+}
+
+func TestController_RunOnce_RefusesDirtyTree(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("child", "Child Task", taskstore.StatusOpen, strPtr("parent")))
+
+	claudeRunner := &mockClaudeRunner{response: &claude.ClaudeResponse{FinalText: "done"}}
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    claudeRunner,
+		Verifier:  &mockVerifier{},
+		Git:       &mockGitManager{hasChanges: true, changedFiles: []string{"main.go"}},
+		LogsDir:   t.TempDir(),
+	})
+	ctrl.SetRequireCleanTree(true)
+
+	result := ctrl.RunOnce(context.Background(), "parent")
+
+	assert.Equal(t, RunOutcomeError, result.Outcome)
+	assert.Contains(t, result.Message, "uncommitted changes")
+	assert.Equal(t, 0, result.IterationsRun)
+	assert.Equal(t, taskstore.StatusOpen, store.tasks["child"].Status)
+}
+
 func TestController_EnsureFeatureBranch_TaskNotFound(t *testing.T) {
 	// Create controller with empty store
 	store := newMockTaskStore()
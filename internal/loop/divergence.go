@@ -0,0 +1,51 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+)
+
+// ancestryChecker is a git manager that can tell whether one commit is
+// reachable from another.
+type ancestryChecker interface {
+	IsAncestor(ctx context.Context, ancestor, descendant string) (bool, error)
+}
+
+// branchDivergence returns a pause message if HEAD no longer contains the
+// commit made by this run's previous iteration, i.e. someone else committed
+// to the branch out of order or rewrote its history. Commits added on top of
+// ours are fine. It returns "" when nothing has been committed yet, when the
+// git manager cannot check ancestry, or when the check itself fails.
+func (c *Controller) branchDivergence(ctx context.Context) string {
+	if c.lastResultCommit == "" {
+		return ""
+	}
+	if _, ok := baseGitManager(c.gitManager).(ancestryChecker); !ok {
+		return ""
+	}
+
+	contained, err := c.gitManager.(ancestryChecker).IsAncestor(ctx, c.lastResultCommit, "HEAD")
+	if err != nil {
+		c.writeProgress("⚠ Could not check the branch for unexpected changes: %v\n", err)
+		return ""
+	}
+	if contained {
+		return ""
+	}
+
+	head := "unknown"
+	if commit, err := c.gitManager.GetCurrentCommit(ctx); err == nil {
+		head = shortCommit(commit)
+	}
+	return fmt.Sprintf("branch diverged: HEAD (%s) no longer contains %s from the previous iteration "+
+		"(reset, rebased or pushed to outside ralph); inspect the branch, then rerun to continue",
+		head, shortCommit(c.lastResultCommit))
+}
+
+// shortCommit abbreviates a commit hash for messages.
+func shortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
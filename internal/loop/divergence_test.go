@@ -0,0 +1,95 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// ancestryGitManager answers ancestry checks with a fixed result.
+type ancestryGitManager struct {
+	*mockGitManager
+	contained bool
+	err       error
+	asked     []string
+}
+
+func (m *ancestryGitManager) IsAncestor(ctx context.Context, ancestor, descendant string) (bool, error) {
+	m.asked = append(m.asked, ancestor+".."+descendant)
+	return m.contained, m.err
+}
+
+func TestController_BranchDivergence(t *testing.T) {
+	tests := []struct {
+		name          string
+		contained     bool
+		err           error
+		wantOutcome   RunLoopOutcome
+		wantCompleted []string
+		wantMessage   string
+	}{
+		{
+			name:          "continues while HEAD contains the previous commit",
+			contained:     true,
+			wantOutcome:   RunOutcomeCompleted,
+			wantCompleted: []string{"task-a", "task-b"},
+		},
+		{
+			name:          "pauses when HEAD no longer contains the previous commit",
+			contained:     false,
+			wantOutcome:   RunOutcomePaused,
+			wantCompleted: []string{"task-a"},
+			wantMessage:   "branch diverged: HEAD (def4567) no longer contains abc1234 from the previous iteration",
+		},
+		{
+			name:          "continues when the check fails",
+			err:           errors.New("git unavailable"),
+			wantOutcome:   RunOutcomeCompleted,
+			wantCompleted: []string{"task-a", "task-b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+			store.addTask(newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent")))
+			store.addTask(newTestTask("task-b", "Task B", taskstore.StatusOpen, strPtr("parent")))
+
+			gitMgr := &ancestryGitManager{
+				mockGitManager: &mockGitManager{
+					currentCommit: "def4567890",
+					hasChanges:    true,
+					changedFiles:  []string{"f.go"},
+					commitHash:    "abc1234567",
+				},
+				contained: tt.contained,
+				err:       tt.err,
+			}
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"}},
+				Verifier: &mockVerifier{
+					results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}},
+				},
+				Git:     gitMgr,
+				LogsDir: t.TempDir(),
+			})
+
+			result := ctrl.RunLoop(context.Background(), "parent")
+
+			assert.Equal(t, tt.wantOutcome, result.Outcome, result.Message)
+			assert.Equal(t, tt.wantCompleted, result.CompletedTasks)
+			assert.Contains(t, result.Message, tt.wantMessage)
+			require.NotEmpty(t, gitMgr.asked)
+			assert.Equal(t, "abc1234567..HEAD", gitMgr.asked[0])
+		})
+	}
+}
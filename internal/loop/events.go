@@ -0,0 +1,136 @@
+package loop
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// EventType identifies a structured run event.
+type EventType string
+
+const (
+	// EventRunStarted is emitted when a run begins.
+	EventRunStarted EventType = "run_started"
+	// EventIterationStarted is emitted when an iteration begins working on a task.
+	EventIterationStarted EventType = "iteration_started"
+	// EventClaudeInvoked is emitted each time the agent returns within an
+	// iteration, with the cost and tokens of that invocation.
+	EventClaudeInvoked EventType = "claude_invoked"
+	// EventVerificationResult is emitted after each verification attempt, with
+	// outcome "passed" or "failed".
+	EventVerificationResult EventType = "verification_result"
+	// EventCommitted is emitted when an iteration's changes are committed.
+	EventCommitted EventType = "committed"
+	// EventIterationFinished is emitted when an iteration ends, with its outcome.
+	EventIterationFinished EventType = "iteration_finished"
+	// EventIterationCompleted follows every EventIterationFinished with the
+	// same fields, for consumers that expect this name.
+	EventIterationCompleted EventType = "iteration_completed"
+	// EventRunFinished is emitted when a run ends, with the run outcome.
+	EventRunFinished EventType = "run_finished"
+	// EventBudgetWarning is emitted once per run when spending crosses the
+	// budget warning threshold.
+	EventBudgetWarning EventType = "budget_warning"
+)
+
+// Event is a structured progress event for external consumers such as IDEs.
+type Event struct {
+	Type         EventType `json:"type"`
+	Time         time.Time `json:"time"`
+	ParentTaskID string    `json:"parent_task_id,omitempty"`
+	TaskID       string    `json:"task_id,omitempty"`
+	TaskTitle    string    `json:"task_title,omitempty"`
+	IterationID  string    `json:"iteration_id,omitempty"`
+	Attempt      int       `json:"attempt,omitempty"`
+	Outcome      string    `json:"outcome,omitempty"`
+	Message      string    `json:"message,omitempty"`
+	CostUSD      float64   `json:"cost_usd,omitempty"`
+	InputTokens  int       `json:"input_tokens,omitempty"`
+	OutputTokens int       `json:"output_tokens,omitempty"`
+	Commit       string    `json:"commit,omitempty"`
+}
+
+// EventSink receives structured run events.
+type EventSink interface {
+	Emit(event Event)
+}
+
+// SetEventSink sets the receiver of structured run events. Events also go to
+// the EventWriter given in ControllerDeps, if any.
+func (c *Controller) SetEventSink(sink EventSink) {
+	c.eventSink = sink
+}
+
+// emit sends event to the event sink and event writer, if any.
+func (c *Controller) emit(event Event) {
+	if c.eventSink == nil && c.eventWriter == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if c.eventSink != nil {
+		c.eventSink.Emit(event)
+	}
+	if c.eventWriter != nil {
+		c.eventWriter.Emit(event)
+	}
+}
+
+// jsonLinesSink writes each event to w as one line of JSON. Write errors are
+// ignored so a full disk or closed pipe never stops the loop.
+type jsonLinesSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *jsonLinesSink) Emit(event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.w.Write(append(data, '\n'))
+}
+
+// emitStep reports a step of the iteration working on task.
+func (c *Controller) emitStep(task *taskstore.Task, record *IterationRecord, event Event) {
+	event.TaskID = task.ID
+	event.IterationID = record.IterationID
+	event.Attempt = record.AttemptNumber
+	c.emit(event)
+}
+
+// emitIterationFinished reports the outcome of a finished iteration, under
+// both of its event names.
+func (c *Controller) emitIterationFinished(task *taskstore.Task, record *IterationRecord) {
+	event := Event{
+		Type:        EventIterationFinished,
+		TaskID:      task.ID,
+		TaskTitle:   task.Title,
+		IterationID: record.IterationID,
+		Attempt:     record.AttemptNumber,
+		Outcome:     string(record.Outcome),
+		Message:     record.Feedback,
+		CostUSD:     record.ClaudeInvocation.TotalCostUSD,
+	}
+	c.emit(event)
+	event.Type = EventIterationCompleted
+	c.emit(event)
+}
+
+// emitRunFinished reports the outcome of a finished run.
+func (c *Controller) emitRunFinished(parentTaskID string, result RunResult) {
+	c.emit(Event{
+		Type:         EventRunFinished,
+		ParentTaskID: parentTaskID,
+		Outcome:      string(result.Outcome),
+		Message:      result.Message,
+		CostUSD:      result.TotalCostUSD,
+	})
+}
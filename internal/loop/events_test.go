@@ -0,0 +1,203 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+type recordingSink struct {
+	events []Event
+}
+
+func (s *recordingSink) Emit(event Event) {
+	s.events = append(s.events, event)
+}
+
+func TestController_EmitsRunEvents(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("child", "Child Task", taskstore.StatusOpen, strPtr("parent")))
+
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess-123", FinalText: "done", TotalCostUSD: 0.5}},
+		Verifier:  &mockVerifier{},
+		Git: &mockGitManager{
+			currentCommit: "abc123",
+			hasChanges:    true,
+			changedFiles:  []string{"file1.go"},
+			commitHash:    "def456",
+		},
+		LogsDir: t.TempDir(),
+	})
+	sink := &recordingSink{}
+	ctrl.SetEventSink(sink)
+
+	result := ctrl.RunLoop(context.Background(), "parent")
+	require.Equal(t, RunOutcomeCompleted, result.Outcome)
+
+	var types []EventType
+	for _, e := range sink.events {
+		types = append(types, e.Type)
+		assert.False(t, e.Time.IsZero())
+	}
+	require.Equal(t, []EventType{
+		EventRunStarted, EventIterationStarted,
+		EventClaudeInvoked, EventVerificationResult, EventCommitted,
+		EventIterationFinished, EventIterationCompleted, EventRunFinished,
+	}, types)
+
+	assert.Equal(t, "parent", sink.events[0].ParentTaskID)
+	assert.Equal(t, "child", sink.events[1].TaskID)
+	for _, step := range sink.events[2:5] {
+		assert.Equal(t, "child", step.TaskID)
+		assert.Equal(t, sink.events[1].IterationID, step.IterationID)
+	}
+	assert.Equal(t, 0.5, sink.events[2].CostUSD)
+	assert.Equal(t, "passed", sink.events[3].Outcome)
+	assert.Equal(t, "def456", sink.events[4].Commit)
+	assert.Equal(t, sink.events[1].IterationID, sink.events[5].IterationID)
+	assert.Equal(t, string(OutcomeSuccess), sink.events[5].Outcome)
+	assert.Equal(t, 0.5, sink.events[5].CostUSD)
+	completed := sink.events[6]
+	assert.Equal(t, sink.events[5].IterationID, completed.IterationID)
+	assert.Equal(t, sink.events[5].Outcome, completed.Outcome)
+	assert.Equal(t, sink.events[5].CostUSD, completed.CostUSD)
+	assert.Equal(t, string(RunOutcomeCompleted), sink.events[7].Outcome)
+}
+
+func TestController_EventWriter(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("child", "Child Task", taskstore.StatusOpen, strPtr("parent")))
+
+	var events, progress bytes.Buffer
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude: &mockClaudeRunner{response: &claude.ClaudeResponse{
+			SessionID:    "sess-123",
+			FinalText:    "done",
+			TotalCostUSD: 0.5,
+			Usage:        claude.ClaudeUsage{InputTokens: 100, OutputTokens: 20},
+		}},
+		Verifier: &mockVerifier{results: []verifier.VerificationResult{{Passed: false, Command: []string{"go", "test"}}}},
+		Git: &mockGitManager{
+			currentCommit: "abc123",
+			hasChanges:    true,
+			changedFiles:  []string{"file1.go"},
+		},
+		LogsDir:        t.TempDir(),
+		ProgressWriter: &progress,
+		EventWriter:    &events,
+	})
+	sink := &recordingSink{}
+	ctrl.SetEventSink(sink)
+	ctrl.SetMaxVerificationRetries(0)
+
+	ctrl.RunOnce(context.Background(), "parent")
+
+	lines := strings.Split(strings.TrimSuffix(events.String(), "\n"), "\n")
+	require.Len(t, lines, len(sink.events), "the writer gets the same events as the sink")
+	var invoked, verified Event
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &invoked))
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &verified))
+	assert.Equal(t, EventClaudeInvoked, invoked.Type)
+	assert.Equal(t, "child", invoked.TaskID)
+	assert.Equal(t, 100, invoked.InputTokens)
+	assert.Equal(t, 20, invoked.OutputTokens)
+	assert.False(t, invoked.Time.IsZero())
+	assert.Equal(t, EventVerificationResult, verified.Type)
+	assert.Equal(t, "failed", verified.Outcome)
+	assert.Equal(t, "0/1 passed", verified.Message)
+	assert.NotContains(t, progress.String(), "{", "progress output stays human-readable")
+}
+
+func TestController_BudgetWarning(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent")))
+	store.addTask(newTestTask("task-b", "Task B", taskstore.StatusOpen, strPtr("parent")))
+
+	var progress bytes.Buffer
+	sink := &recordingSink{}
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done", TotalCostUSD: 1.0}},
+		Verifier: &mockVerifier{
+			results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}},
+		},
+		Git: &mockGitManager{
+			currentCommit: "abc123",
+			hasChanges:    true,
+			changedFiles:  []string{"f.go"},
+			commitHash:    "def456",
+		},
+		LogsDir:        t.TempDir(),
+		ProgressWriter: &progress,
+	})
+	ctrl.SetBudgetLimits(BudgetLimits{MaxIterations: 10, MaxCostUSD: 10.0, WarnAtFraction: 0.1})
+	ctrl.SetEventSink(sink)
+
+	result := ctrl.RunLoop(context.Background(), "parent")
+
+	assert.Equal(t, RunOutcomeCompleted, result.Outcome, result.Message)
+	assert.Len(t, result.CompletedTasks, 2)
+	assert.Equal(t, 1, strings.Count(progress.String(), "⚠ Budget warning: spent $1.00 of the $10.00 cost limit (warning at 10%); continuing"))
+
+	var warnings []Event
+	for _, event := range sink.events {
+		if event.Type == EventBudgetWarning {
+			warnings = append(warnings, event)
+		}
+	}
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "parent", warnings[0].ParentTaskID)
+	assert.InDelta(t, 1.0, warnings[0].CostUSD, 0.001)
+}
+
+func TestController_SessionID(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent")))
+	store.addTask(newTestTask("task-b", "Task B", taskstore.StatusOpen, strPtr("parent")))
+	store.addTask(newTestTask("task-c", "Task C", taskstore.StatusOpen, strPtr("parent")))
+
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"}},
+		Verifier: &mockVerifier{
+			results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}},
+		},
+		Git: &mockGitManager{
+			currentCommit: "abc123",
+			hasChanges:    true,
+			changedFiles:  []string{"f.go"},
+			commitHash:    "def456",
+		},
+		LogsDir: t.TempDir(),
+	})
+
+	ctrl.SetBudgetLimits(BudgetLimits{MaxIterations: 2})
+	first := ctrl.RunLoop(context.Background(), "parent")
+	ctrl.SetBudgetLimits(BudgetLimits{MaxIterations: 2}) // RunOnce also checks the budget
+	second := ctrl.RunOnce(context.Background(), "parent")
+
+	require.Len(t, first.Records, 2)
+	require.Len(t, second.Records, 1)
+	assert.NotEmpty(t, first.Records[0].SessionID)
+	assert.Equal(t, first.Records[0].SessionID, first.Records[1].SessionID)
+	assert.NotEmpty(t, second.Records[0].SessionID)
+	assert.NotEqual(t, first.Records[0].SessionID, second.Records[0].SessionID)
+	assert.Equal(t, "parent", first.Records[0].ParentTaskID)
+	assert.Equal(t, "parent", second.Records[0].ParentTaskID)
+}
@@ -0,0 +1,53 @@
+package loop
+
+import (
+	"context"
+
+	"github.com/yarlson/ralph/internal/selector"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// externalSelector chooses the next task from the ready tasks.
+type externalSelector interface {
+	Select(ctx context.Context, req selector.ExternalRequest) (string, error)
+}
+
+// SetExternalSelector delegates task selection to s. If s fails or picks a
+// task that is not ready, the built-in selection is used for that iteration.
+// A nil selector (the default) always uses the built-in selection.
+func (c *Controller) SetExternalSelector(s externalSelector) {
+	c.externalSelector = s
+}
+
+// selectNext selects the next task under parentTaskID, asking the external
+// selector when one is configured. During RunFocus only the focus task is
+// selected, and only while it is ready.
+func (c *Controller) selectNext(ctx context.Context, tasks []*taskstore.Task, graph *selector.Graph, parentTaskID string) *taskstore.Task {
+	if c.focusTaskID != "" {
+		return focusTask(selector.ReadyLeaves(tasks, graph, parentTaskID, c.selectOptions()), c.focusTaskID)
+	}
+
+	builtin := selector.SelectNextWithOptions(tasks, graph, parentTaskID, c.lastCompleted, c.selectOptions())
+	if c.externalSelector == nil || builtin == nil {
+		return builtin
+	}
+
+	ready := selector.ReadyLeaves(tasks, graph, parentTaskID, c.selectOptions())
+	req := selector.ExternalRequest{ParentID: parentTaskID, Ready: ready}
+	if c.lastCompleted != nil {
+		req.LastCompletedID = c.lastCompleted.ID
+	}
+
+	id, err := c.externalSelector.Select(ctx, req)
+	if err != nil {
+		c.writeProgress("⚠ %v; using built-in task selection\n", err)
+		return builtin
+	}
+	for _, t := range ready {
+		if t.ID == id {
+			c.writeVerbose("    chosen by selection command\n")
+			return t
+		}
+	}
+	return builtin
+}
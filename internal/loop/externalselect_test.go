@@ -0,0 +1,111 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/selector"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// stubSelector returns a fixed choice and records the requests it received.
+type stubSelector struct {
+	id       string
+	err      error
+	requests []selector.ExternalRequest
+}
+
+func (s *stubSelector) Select(ctx context.Context, req selector.ExternalRequest) (string, error) {
+	s.requests = append(s.requests, req)
+	return s.id, s.err
+}
+
+func TestController_ExternalSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *stubSelector
+		want     string
+		warning  string
+	}{
+		{name: "uses the task chosen by the command", selector: &stubSelector{id: "task-b"}, want: "task-b"},
+		{name: "falls back when the command fails", selector: &stubSelector{err: errors.New("exit status 1")}, want: "task-a", warning: "exit status 1; using built-in task selection"},
+		{name: "falls back when the choice is not ready", selector: &stubSelector{id: "task-c"}, want: "task-a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+			store.addTask(newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent")))
+			store.addTask(newTestTask("task-b", "Task B", taskstore.StatusOpen, strPtr("parent")))
+			taskC := newTestTask("task-c", "Task C", taskstore.StatusOpen, strPtr("parent"))
+			taskC.DependsOn = []string{"task-a"}
+			store.addTask(taskC)
+
+			var progress bytes.Buffer
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude: &mockClaudeRunner{
+					response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"},
+				},
+				Verifier: &mockVerifier{
+					results: []verifier.VerificationResult{{Passed: true, Command: []string{"echo"}}},
+				},
+				Git: &mockGitManager{
+					currentCommit: "abc",
+					hasChanges:    true,
+					changedFiles:  []string{"f.go"},
+					commitHash:    "def",
+				},
+				LogsDir:        t.TempDir(),
+				ProgressDir:    t.TempDir(),
+				ProgressWriter: &progress,
+			})
+			ctrl.SetExternalSelector(tt.selector)
+
+			result := ctrl.RunOnce(context.Background(), "parent")
+
+			assert.Equal(t, []string{tt.want}, result.CompletedTasks)
+			if tt.warning != "" {
+				assert.Contains(t, progress.String(), tt.warning)
+			}
+			require.Len(t, tt.selector.requests, 1)
+			req := tt.selector.requests[0]
+			assert.Equal(t, "parent", req.ParentID)
+			var ready []string
+			for _, task := range req.Ready {
+				ready = append(ready, task.ID)
+			}
+			assert.Equal(t, []string{"task-a", "task-b"}, ready)
+		})
+	}
+
+	t.Run("not asked when nothing is ready", func(t *testing.T) {
+		store := newMockTaskStore()
+		store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+		store.addTask(newTestTask("done", "Done", taskstore.StatusCompleted, strPtr("parent")))
+
+		stub := &stubSelector{id: "done"}
+		ctrl := NewController(ControllerDeps{
+			TaskStore:   store,
+			Claude:      &mockClaudeRunner{},
+			Verifier:    &mockVerifier{},
+			Git:         &mockGitManager{},
+			LogsDir:     t.TempDir(),
+			ProgressDir: t.TempDir(),
+		})
+		ctrl.SetExternalSelector(stub)
+
+		result := ctrl.RunOnce(context.Background(), "parent")
+
+		assert.Equal(t, 0, result.IterationsRun)
+		assert.Empty(t, stub.requests)
+	})
+}
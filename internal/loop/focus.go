@@ -0,0 +1,51 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// RunFocus runs a single task through its full retry lifecycle. Unlike
+// RunOnce, it keeps iterating on taskID until the task completes, runs out of
+// retries, or the budget, gutter detection, or a pause stops the run. No other
+// task is selected.
+func (c *Controller) RunFocus(ctx context.Context, parentTaskID, taskID string) RunResult {
+	c.focusTaskID = taskID
+	defer func() { c.focusTaskID = "" }()
+	return c.RunLoop(ctx, parentTaskID)
+}
+
+// focusTask returns the task with the given ID from ready, or nil if it is not
+// ready.
+func focusTask(ready []*taskstore.Task, taskID string) *taskstore.Task {
+	for _, t := range ready {
+		if t.ID == taskID {
+			return t
+		}
+	}
+	return nil
+}
+
+// focusOutcome reports how a focus run ended once its task is no longer ready.
+func focusOutcome(tasks []*taskstore.Task, taskID string) (RunLoopOutcome, string) {
+	for _, t := range tasks {
+		if t.ID != taskID {
+			continue
+		}
+		switch t.Status {
+		case taskstore.StatusCompleted:
+			return RunOutcomeCompleted, fmt.Sprintf("task %s completed", taskID)
+		case taskstore.StatusSkipped:
+			return RunOutcomeCompleted, fmt.Sprintf("task %s is skipped", taskID)
+		case taskstore.StatusFailed:
+			return RunOutcomeBlocked, fmt.Sprintf("task %s failed after exhausting retries", taskID)
+		case taskstore.StatusBlocked:
+			return RunOutcomeBlocked, fmt.Sprintf("task %s is blocked", taskID)
+		default:
+			return RunOutcomeBlocked, fmt.Sprintf("task %s is not ready (it must be a leaf under the parent task with its dependencies completed)", taskID)
+		}
+	}
+	return RunOutcomeError, fmt.Sprintf("task %s not found", taskID)
+}
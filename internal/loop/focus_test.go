@@ -0,0 +1,95 @@
+package loop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestController_RunFocus(t *testing.T) {
+	tests := []struct {
+		name           string
+		failures       int // verification failures before passing
+		dependsOnOther bool
+		wantOutcome    RunLoopOutcome
+		wantMessage    string
+		wantIterations int
+		wantStatus     taskstore.TaskStatus
+	}{
+		{
+			name:           "retries until the task completes",
+			failures:       2,
+			wantOutcome:    RunOutcomeCompleted,
+			wantMessage:    "task task-b completed",
+			wantIterations: 3,
+			wantStatus:     taskstore.StatusCompleted,
+		},
+		{
+			name:           "stops when retries are exhausted",
+			failures:       10,
+			wantOutcome:    RunOutcomeBlocked,
+			wantMessage:    "task task-b failed after exhausting retries",
+			wantIterations: 3,
+			wantStatus:     taskstore.StatusFailed,
+		},
+		{
+			name:           "does not run a task that is not ready",
+			dependsOnOther: true,
+			wantOutcome:    RunOutcomeBlocked,
+			wantMessage:    "task task-b is not ready",
+			wantStatus:     taskstore.StatusOpen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+			store.addTask(newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent")))
+			taskB := newTestTask("task-b", "Task B", taskstore.StatusOpen, strPtr("parent"))
+			if tt.dependsOnOther {
+				taskB.DependsOn = []string{"task-a"}
+			}
+			store.addTask(taskB)
+
+			verify := &mockVerifier{}
+			verify.verifyFn = func(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+				passed := verify.calls > tt.failures
+				return []verifier.VerificationResult{{Passed: passed, Command: []string{"go", "test"}}}, nil
+			}
+
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude: &mockClaudeRunner{
+					response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"},
+				},
+				Verifier: verify,
+				Git: &mockGitManager{
+					currentCommit: "abc",
+					hasChanges:    true,
+					changedFiles:  []string{"f.go"},
+					commitHash:    "def",
+				},
+				LogsDir:     t.TempDir(),
+				ProgressDir: t.TempDir(),
+			})
+			ctrl.SetMaxRetries(2)
+			ctrl.SetMaxVerificationRetries(0)
+			// Repeated failures would otherwise trip gutter detection first
+			ctrl.SetGutterConfig(GutterConfig{})
+
+			result := ctrl.RunFocus(context.Background(), "parent", "task-b")
+
+			assert.Equal(t, tt.wantOutcome, result.Outcome)
+			assert.Contains(t, result.Message, tt.wantMessage)
+			assert.Equal(t, tt.wantIterations, result.IterationsRun)
+			assert.Equal(t, tt.wantStatus, store.tasks["task-b"].Status)
+			assert.Equal(t, taskstore.StatusOpen, store.tasks["task-a"].Status, "other tasks are never selected")
+		})
+	}
+}
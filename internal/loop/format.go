@@ -0,0 +1,42 @@
+package loop
+
+import (
+	"context"
+	"strings"
+)
+
+// SetFormatCommand sets a command (e.g. gofmt -w .) run after the agent
+// changes files and before verification, so formatting is fixed
+// automatically rather than failing the task. Its changes are committed with
+// the task's. A failing command is reported as a warning and never blocks the
+// task. An empty command disables it.
+func (c *Controller) SetFormatCommand(command []string) {
+	c.formatCommand = command
+}
+
+// autoFormat runs the format command and refreshes the record's changed
+// files, since formatting may touch files the agent did not.
+func (c *Controller) autoFormat(ctx context.Context, record *IterationRecord) {
+	if len(c.formatCommand) == 0 {
+		return
+	}
+
+	results, err := c.verifier.Verify(ctx, [][]string{c.formatCommand})
+	if err != nil {
+		c.writeProgress("  ⚠ Format command failed: %v\n", err)
+		return
+	}
+	for _, r := range results {
+		if !r.Passed {
+			c.writeProgress("  ⚠ Format command failed: %s\n", strings.Join(c.formatCommand, " "))
+			c.writeVerbose("%s\n", indentLines(strings.TrimRight(r.Output, "\n"), "    "))
+			return
+		}
+	}
+
+	record.AutoFormatted = true
+	c.writeProgress("  🧹 Formatted: %s\n", strings.Join(c.formatCommand, " "))
+	if changes, err := c.gitManager.GetFileChanges(ctx); err == nil {
+		record.AddFileChanges(changes)
+	}
+}
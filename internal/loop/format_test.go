@@ -0,0 +1,110 @@
+package loop
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestController_RunIteration_FormatCommand(t *testing.T) {
+	format := []string{"gofmt", "-w", "."}
+	test := []string{"go", "test", "./..."}
+
+	tests := []struct {
+		name          string
+		formatCommand []string
+		formatPasses  bool
+		wantCommands  [][]string
+		wantFormatted bool
+		wantFiles     []string
+	}{
+		{
+			name:         "runs only verify commands when unset",
+			wantCommands: [][]string{test},
+			wantFiles:    []string{"main.go"},
+		},
+		{
+			name:          "formats before verification and picks up formatted files",
+			formatCommand: format,
+			formatPasses:  true,
+			wantCommands:  [][]string{format, test},
+			wantFormatted: true,
+			wantFiles:     []string{"main.go", "util.go"},
+		},
+		{
+			name:          "a failing format command does not block the task",
+			formatCommand: format,
+			wantCommands:  [][]string{format, test},
+			wantFiles:     []string{"main.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+			task.Verify = [][]string{test}
+			store.addTask(task)
+
+			gitMgr := &mockGitManager{
+				currentCommit: "abc123",
+				hasChanges:    true,
+				changedFiles:  []string{"main.go"},
+				commitHash:    "def456",
+			}
+			var ran [][]string
+			verifierMock := &mockVerifier{
+				verifyFn: func(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+					var results []verifier.VerificationResult
+					for _, cmd := range commands {
+						ran = append(ran, cmd)
+						passed := true
+						if slices.Equal(cmd, format) {
+							passed = tt.formatPasses
+							if passed {
+								gitMgr.fileChanges = []git.FileChange{
+									{Path: "main.go", Status: git.FileModified},
+									{Path: "util.go", Status: git.FileModified},
+								}
+							}
+						}
+						results = append(results, verifier.VerificationResult{Passed: passed, Command: cmd})
+					}
+					return results, nil
+				},
+			}
+
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &sessionSequenceRunner{},
+				Verifier:  verifierMock,
+				Git:       gitMgr,
+				LogsDir:   t.TempDir(),
+			})
+			ctrl.SetFormatCommand(tt.formatCommand)
+
+			record := ctrl.runIteration(context.Background(), task)
+
+			require.Equal(t, OutcomeSuccess, record.Outcome, record.Feedback)
+			assert.Equal(t, tt.wantCommands, ran)
+			assert.Equal(t, tt.wantFormatted, record.AutoFormatted)
+			assert.Equal(t, tt.wantFiles, record.FilesChanged)
+			assert.Len(t, gitMgr.commitCalls, 1)
+		})
+	}
+}
+
+func TestGenerateTextLog_AutoFormatted(t *testing.T) {
+	record := NewIterationRecord("task-1")
+	assert.NotContains(t, GenerateTextLog(record), "Auto-formatted")
+
+	record.AutoFormatted = true
+	assert.Contains(t, GenerateTextLog(record), "Auto-formatted: yes\n")
+}
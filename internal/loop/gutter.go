@@ -3,9 +3,16 @@ package loop
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+
+	"github.com/yarlson/ralph/internal/atomicfile"
 )
 
 // GutterReason identifies why the loop is in the gutter.
@@ -80,6 +87,18 @@ type GutterStatus struct {
 
 	// Description is a human-readable explanation of the gutter condition.
 	Description string
+
+	// FailureSignature is the full signature of the repeated verification
+	// failure (GutterReasonRepeatedFailure only).
+	FailureSignature string
+
+	// Files are the churning or oscillating files, sorted
+	// (GutterReasonFileChurn and GutterReasonOscillation only).
+	Files []string
+
+	// Iterations is the number of recorded iterations involved: those that hit
+	// the repeated failure, or those that changed any of Files.
+	Iterations int
 }
 
 // GutterState represents the persistent state for gutter detection.
@@ -95,13 +114,15 @@ type GutterState struct {
 	ContentHashes map[string][]string `json:"content_hashes"`
 }
 
-// GutterDetector tracks iteration history and detects gutter conditions.
+// GutterDetector tracks iteration history and detects gutter conditions. It
+// is safe for concurrent use by parallel iterations.
 type GutterDetector struct {
+	mu                sync.Mutex
 	config            GutterConfig
-	failureSignatures map[string]int              // signature hash -> count
-	fileChanges       [][]string                  // list of file sets from recent iterations
-	contentHashes     map[string][]string         // file path -> list of content hashes
-	oscillationCounts map[string]int              // file path -> oscillation count
+	failureSignatures map[string]int      // signature hash -> count
+	fileChanges       [][]string          // list of file sets from recent iterations
+	contentHashes     map[string][]string // file path -> list of content hashes
+	oscillationCounts map[string]int      // file path -> oscillation count
 }
 
 // NewGutterDetector creates a new gutter detector with the given config.
@@ -149,6 +170,9 @@ func ComputeContentHash(content string) string {
 
 // RecordIteration records an iteration for gutter detection.
 func (d *GutterDetector) RecordIteration(record *IterationRecord) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	if record == nil {
 		return
 	}
@@ -200,6 +224,9 @@ func (d *GutterDetector) RecordIteration(record *IterationRecord) {
 
 // Check checks for gutter conditions based on recorded iterations.
 func (d *GutterDetector) Check() GutterStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	// Check for repeated failure
 	if status := d.checkRepeatedFailure(); status.InGutter {
 		return status
@@ -231,9 +258,11 @@ func (d *GutterDetector) checkRepeatedFailure() GutterStatus {
 	for sig, count := range d.failureSignatures {
 		if count >= d.config.MaxSameFailure {
 			return GutterStatus{
-				InGutter:    true,
-				Reason:      GutterReasonRepeatedFailure,
-				Description: fmt.Sprintf("same failure repeated %d times (threshold: %d), signature: %s", count, d.config.MaxSameFailure, sig[:8]),
+				InGutter:         true,
+				Reason:           GutterReasonRepeatedFailure,
+				Description:      fmt.Sprintf("same failure repeated %d times (threshold: %d), signature: %s", count, d.config.MaxSameFailure, sig[:8]),
+				FailureSignature: sig,
+				Iterations:       count,
 			}
 		}
 	}
@@ -261,6 +290,8 @@ func (d *GutterDetector) checkOscillation() GutterStatus {
 			InGutter:    true,
 			Reason:      GutterReasonOscillation,
 			Description: fmt.Sprintf("files oscillating (modified %d+ times non-consecutively): %s", d.config.MaxOscillations, strings.Join(oscillatingFiles, ", ")),
+			Files:       oscillatingFiles,
+			Iterations:  d.iterationsTouching(oscillatingFiles),
 		}
 	}
 
@@ -295,14 +326,33 @@ func (d *GutterDetector) checkFileChurn() GutterStatus {
 			InGutter:    true,
 			Reason:      GutterReasonFileChurn,
 			Description: fmt.Sprintf("files modified %d+ times in last %d iterations: %s", d.config.ChurnThreshold, len(d.fileChanges), strings.Join(churningFiles, ", ")),
+			Files:       churningFiles,
+			Iterations:  d.iterationsTouching(churningFiles),
 		}
 	}
 
 	return GutterStatus{InGutter: false, Reason: GutterReasonNone}
 }
 
+// iterationsTouching counts the recorded iterations that changed any of files.
+func (d *GutterDetector) iterationsTouching(files []string) int {
+	count := 0
+	for _, changed := range d.fileChanges {
+		for _, file := range changed {
+			if slices.Contains(files, file) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
 // Reset clears all tracked state.
 func (d *GutterDetector) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	d.failureSignatures = make(map[string]int)
 	d.fileChanges = [][]string{}
 	d.contentHashes = make(map[string][]string)
@@ -311,6 +361,9 @@ func (d *GutterDetector) Reset() {
 
 // GetState returns the current gutter detection state for persistence.
 func (d *GutterDetector) GetState() GutterState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	// Copy the map to avoid external mutation
 	sigs := make(map[string]int, len(d.failureSignatures))
 	for k, v := range d.failureSignatures {
@@ -340,6 +393,9 @@ func (d *GutterDetector) GetState() GutterState {
 
 // SetState restores gutter detection state from persistence.
 func (d *GutterDetector) SetState(state GutterState) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	if state.FailureSignatures != nil {
 		d.failureSignatures = make(map[string]int, len(state.FailureSignatures))
 		for k, v := range state.FailureSignatures {
@@ -378,3 +434,36 @@ func (d *GutterDetector) SetState(state GutterState) {
 		}
 	}
 }
+
+// SaveGutterState writes gutter detection state to path as JSON.
+func SaveGutterState(path string, state GutterState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal gutter state: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write gutter state: %w", err)
+	}
+
+	return nil
+}
+
+// LoadGutterState reads gutter detection state from path.
+// Returns nil, nil if no state has been saved.
+func LoadGutterState(path string) (*GutterState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read gutter state: %w", err)
+	}
+
+	var state GutterState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal gutter state: %w", err)
+	}
+
+	return &state, nil
+}
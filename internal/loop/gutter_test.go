@@ -3,6 +3,8 @@ package loop
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -239,6 +241,9 @@ func TestGutterDetector_RepeatedFailure(t *testing.T) {
 	assert.True(t, status.InGutter, "should be in gutter after 3 same failures")
 	assert.Equal(t, GutterReasonRepeatedFailure, status.Reason)
 	assert.Contains(t, status.Description, "same failure")
+	assert.Equal(t, ComputeFailureSignature(makeRecord("iter-3").VerificationOutputs), status.FailureSignature)
+	assert.Equal(t, 3, status.Iterations)
+	assert.Empty(t, status.Files)
 }
 
 // Test file churn detection
@@ -267,6 +272,9 @@ func TestGutterDetector_FileChurn(t *testing.T) {
 	assert.True(t, status.InGutter, "should detect file churn")
 	assert.Equal(t, GutterReasonFileChurn, status.Reason)
 	assert.Contains(t, status.Description, "churning-file.go")
+	assert.Equal(t, []string{"churning-file.go"}, status.Files)
+	assert.Equal(t, 3, status.Iterations)
+	assert.Empty(t, status.FailureSignature)
 }
 
 // Test no gutter when making progress
@@ -379,6 +387,8 @@ func TestGutterDetector_Oscillation(t *testing.T) {
 	assert.True(t, status.InGutter, "should detect oscillation pattern")
 	assert.Equal(t, GutterReasonOscillation, status.Reason)
 	assert.Contains(t, status.Description, "file1.go")
+	assert.Equal(t, []string{"file1.go"}, status.Files)
+	assert.Equal(t, 3, status.Iterations)
 }
 
 // Test GetState and SetState
@@ -524,3 +534,45 @@ func TestGutterDetector_OnlyFailedIterationsCounted(t *testing.T) {
 	status := detector.Check()
 	assert.True(t, status.InGutter, "should count consecutive/total failures correctly")
 }
+
+func TestSaveLoadGutterState(t *testing.T) {
+	t.Run("round trips state", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gutter.json")
+		saved := GutterState{
+			FailureSignatures: map[string]int{"abc123": 2},
+			FileChanges:       [][]string{{"a.go"}, {"a.go", "b.go"}},
+			ContentHashes:     map[string][]string{"a.go": {"1", "2"}},
+		}
+
+		require.NoError(t, SaveGutterState(path, saved))
+
+		loaded, err := LoadGutterState(path)
+		require.NoError(t, err)
+		require.NotNil(t, loaded)
+		assert.Equal(t, saved, *loaded)
+	})
+
+	t.Run("returns nil when no state saved", func(t *testing.T) {
+		loaded, err := LoadGutterState(filepath.Join(t.TempDir(), "gutter.json"))
+		require.NoError(t, err)
+		assert.Nil(t, loaded)
+	})
+}
+
+func TestGutterDetector_RecordIteration_Concurrent(t *testing.T) {
+	detector := NewGutterDetector(DefaultGutterConfig())
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			record := NewIterationRecord("task-1")
+			record.FilesChanged = []string{"a.go"}
+			record.Complete(OutcomeSuccess)
+			detector.RecordIteration(record)
+			_ = detector.Check()
+		})
+	}
+	wg.Wait()
+
+	assert.Len(t, detector.GetState().FileChanges, DefaultGutterConfig().MaxChurnIterations)
+}
@@ -0,0 +1,72 @@
+package loop
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// FindOrphanedRecords returns the iteration records whose task ID is not in
+// tasks (for example, because the task was deleted or renamed outside ralph),
+// oldest first. Corrupt records are skipped.
+func FindOrphanedRecords(logsDir string, tasks []*taskstore.Task) ([]*IterationRecord, error) {
+	records, err := LoadAllIterationRecords(logsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		known[t.ID] = true
+	}
+
+	var orphans []*IterationRecord
+	for _, record := range records {
+		if !known[record.TaskID] {
+			orphans = append(orphans, record)
+		}
+	}
+
+	sort.SliceStable(orphans, func(i, j int) bool {
+		return orphans[i].StartTime.Before(orphans[j].StartTime)
+	})
+	return orphans, nil
+}
+
+// RemoveRecord deletes an iteration record's JSON file and its text log.
+func RemoveRecord(logsDir, iterationID string) error {
+	for _, path := range recordPaths(logsDir, iterationID) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// ArchiveRecord moves an iteration record's JSON file and its text log from
+// logsDir to archiveDir, creating archiveDir if needed.
+func ArchiveRecord(logsDir, archiveDir, iterationID string) error {
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	for _, path := range recordPaths(logsDir, iterationID) {
+		dest := filepath.Join(archiveDir, filepath.Base(path))
+		if err := os.Rename(path, dest); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to archive %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// recordPaths returns the JSON record and text log paths of an iteration.
+func recordPaths(logsDir, iterationID string) []string {
+	return []string{
+		filepath.Join(logsDir, fmt.Sprintf("iteration-%s.json", iterationID)),
+		filepath.Join(logsDir, fmt.Sprintf("iteration-%s.txt", iterationID)),
+	}
+}
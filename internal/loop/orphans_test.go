@@ -0,0 +1,72 @@
+package loop
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func saveTestRecord(t *testing.T, logsDir, iterationID, taskID string, start time.Time) {
+	t.Helper()
+	record := NewIterationRecord(taskID)
+	record.IterationID = iterationID
+	record.StartTime = start
+	_, err := SaveRecord(logsDir, record)
+	require.NoError(t, err)
+}
+
+func TestFindOrphanedRecords(t *testing.T) {
+	logsDir := t.TempDir()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	saveTestRecord(t, logsDir, "kept", "task-a", base)
+	saveTestRecord(t, logsDir, "late", "renamed", base.Add(2*time.Hour))
+	saveTestRecord(t, logsDir, "early", "deleted", base.Add(time.Hour))
+
+	tasks := []*taskstore.Task{newTestTask("task-a", "Task A", taskstore.StatusCompleted, nil)}
+	orphans, err := FindOrphanedRecords(logsDir, tasks)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, record := range orphans {
+		ids = append(ids, record.IterationID)
+	}
+	assert.Equal(t, []string{"early", "late"}, ids)
+
+	t.Run("missing logs directory", func(t *testing.T) {
+		orphans, err := FindOrphanedRecords(filepath.Join(t.TempDir(), "missing"), tasks)
+		require.NoError(t, err)
+		assert.Empty(t, orphans)
+	})
+}
+
+func TestRemoveRecord(t *testing.T) {
+	logsDir := t.TempDir()
+	saveTestRecord(t, logsDir, "gone", "task-a", time.Now())
+
+	require.NoError(t, RemoveRecord(logsDir, "gone"))
+
+	assert.NoFileExists(t, filepath.Join(logsDir, "iteration-gone.json"))
+	assert.NoFileExists(t, filepath.Join(logsDir, "iteration-gone.txt"))
+	require.NoError(t, RemoveRecord(logsDir, "gone"))
+}
+
+func TestArchiveRecord(t *testing.T) {
+	logsDir := t.TempDir()
+	archiveDir := filepath.Join(t.TempDir(), "archive", "logs")
+	saveTestRecord(t, logsDir, "old", "task-a", time.Now())
+
+	require.NoError(t, ArchiveRecord(logsDir, archiveDir, "old"))
+
+	assert.NoFileExists(t, filepath.Join(logsDir, "iteration-old.json"))
+	assert.FileExists(t, filepath.Join(archiveDir, "iteration-old.json"))
+	assert.FileExists(t, filepath.Join(archiveDir, "iteration-old.txt"))
+
+	record, err := LoadRecord(filepath.Join(archiveDir, "iteration-old.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "task-a", record.TaskID)
+}
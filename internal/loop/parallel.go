@@ -0,0 +1,282 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/selector"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// worktreeManager is a git manager that can check out HEAD in separate
+// worktrees and bring the commits made there back onto the current branch.
+type worktreeManager interface {
+	AddWorktree(ctx context.Context, path string) (git.Manager, error)
+	RemoveWorktree(ctx context.Context, path string) error
+	CherryPick(ctx context.Context, base, tip string) (string, error)
+}
+
+// dirVerifier is a verifier that can run its commands in another directory.
+type dirVerifier interface {
+	InDir(dir string) verifier.Verifier
+}
+
+// SetMaxConcurrency sets how many independent ready tasks run at once. Each
+// runs in its own git worktree, and its commits are cherry-picked onto the
+// feature branch when the batch finishes. Values below 2 (the default) run
+// one task at a time, as do per_run commit mode, single-stepping, focused
+// runs and git managers or verifiers that cannot work in worktrees.
+func (c *Controller) SetMaxConcurrency(n int) {
+	c.maxConcurrency = n
+}
+
+// codeDir is the directory the agent and verify commands work in.
+func (c *Controller) codeDir() string {
+	if c.repoDir != "" {
+		return c.repoDir
+	}
+	return c.workDir
+}
+
+// lockShared locks the files parallel workers share and returns the unlock
+// function. It does nothing when iterations run one at a time.
+func (c *Controller) lockShared() func() {
+	if c.sharedMu == nil {
+		return func() {}
+	}
+	c.sharedMu.Lock()
+	return c.sharedMu.Unlock
+}
+
+// parallelBatch returns first followed by the other ready tasks to run
+// alongside it, up to the concurrency limit and the iterations left in the
+// budget. Ready tasks never depend on one another, since a task is only ready
+// once all its dependencies have completed. It returns nil when tasks must
+// run one at a time.
+func (c *Controller) parallelBatch(tasks []*taskstore.Task, graph *selector.Graph, parentTaskID string, first *taskstore.Task) []*taskstore.Task {
+	if c.maxConcurrency < 2 || c.commitPerRun || c.stepper != nil || c.focusTaskID != "" {
+		return nil
+	}
+	if _, ok := baseGitManager(c.gitManager).(worktreeManager); !ok {
+		return nil
+	}
+	if _, ok := baseVerifier(c.verifier).(dirVerifier); !ok {
+		return nil
+	}
+
+	limit := c.maxConcurrency
+	if maxIterations := c.budget.limits.MaxIterations; maxIterations > 0 {
+		limit = min(limit, maxIterations-c.budget.GetState().Iterations)
+	}
+
+	batch := []*taskstore.Task{first}
+	for _, t := range selector.ReadyLeaves(tasks, graph, parentTaskID, c.selectOptions()) {
+		if len(batch) >= limit {
+			break
+		}
+		if t.ID == first.ID || c.taskBudgetExhausted(tasks, parentTaskID, t) != "" {
+			continue
+		}
+		if c.retryDelay(c.taskAttempts[t.ID]) > 0 {
+			continue // waits out its backoff when selected on its own
+		}
+		batch = append(batch, t)
+	}
+	return batch
+}
+
+// runBatch runs the tasks of batch at the same time, each in its own
+// worktree of HEAD, then applies the commits of the successful ones to the
+// current branch in batch order. A task whose commits conflict with those
+// applied before it fails and reopens, to run again on top of the others'
+// changes. It returns false, having run nothing, when the worktrees cannot
+// be created.
+func (c *Controller) runBatch(ctx context.Context, batch []*taskstore.Task, result *RunResult) bool {
+	wm := c.gitManager.(worktreeManager)
+	base, err := c.gitManager.GetCurrentCommit(ctx)
+	if err != nil {
+		c.writeProgress("⚠ Cannot run tasks in parallel: %v\n\n", err)
+		return false
+	}
+
+	tmpDir, err := os.MkdirTemp("", "ralph-worktrees-")
+	if err != nil {
+		c.writeProgress("⚠ Cannot run tasks in parallel: %v\n\n", err)
+		return false
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	var paths []string
+	defer func() {
+		for _, path := range paths {
+			_ = wm.RemoveWorktree(context.WithoutCancel(ctx), path)
+		}
+	}()
+
+	outMu := &sync.Mutex{}
+	sharedMu := &sync.Mutex{}
+	workers := make([]*Controller, len(batch))
+	for i, task := range batch {
+		path := filepath.Join(tmpDir, task.ID)
+		worktree, err := wm.AddWorktree(ctx, path)
+		if err != nil {
+			c.writeProgress("⚠ Cannot run tasks in parallel: %v\n\n", err)
+			return false
+		}
+		paths = append(paths, path)
+		workers[i] = c.newWorker(task, path, worktree, outMu, sharedMu)
+	}
+
+	ids := make([]string, len(batch))
+	for i, task := range batch {
+		ids[i] = task.ID
+	}
+	c.writeProgress("⇉ Running %d tasks in parallel: %s\n\n", len(batch), strings.Join(ids, ", "))
+
+	records := make([]*IterationRecord, len(batch))
+	var wg sync.WaitGroup
+	for i, task := range batch {
+		wg.Go(func() {
+			record := workers[i].runIteration(ctx, task)
+			c.recordBudget(record)
+			c.gutter.RecordIteration(record)
+			records[i] = record
+		})
+	}
+	wg.Wait()
+
+	for i, task := range batch {
+		record := records[i]
+		c.mergeWorker(workers[i], task.ID)
+		if (record.Outcome == OutcomeSuccess || record.Outcome == OutcomeCheckpoint) && record.ResultCommit != "" {
+			c.applyWorkerCommits(ctx, wm, task, base, record)
+		}
+		c.finishIteration(ctx, task, record, result)
+	}
+	return true
+}
+
+// newWorker returns a copy of the controller that runs task in the worktree
+// at dir. The copy has its own per-task state, prefixes its progress lines
+// with the task ID and shares the budget tracker, gutter detector and task
+// store with c.
+func (c *Controller) newWorker(task *taskstore.Task, dir string, worktree git.Manager, outMu, sharedMu *sync.Mutex) *Controller {
+	w := *c
+	w.gitManager = worktree
+	w.verifier = c.verifier.(dirVerifier).InDir(dir)
+	w.repoDir = dir
+	w.sharedMu = sharedMu
+	w.lastResultCommit = ""
+
+	w.taskAttempts = make(map[string]int)
+	if attempts, ok := c.taskAttempts[task.ID]; ok {
+		w.taskAttempts[task.ID] = attempts
+	}
+	w.taskPlans = nil
+	if plan, ok := c.taskPlans[task.ID]; ok {
+		w.taskPlans = map[string]string{task.ID: plan}
+	}
+	if c.profiler != nil {
+		w.setProfiler(&profiler{})
+	}
+
+	if c.progressWriter != nil {
+		w.progressWriter = &prefixWriter{mu: outMu, w: c.progressWriter, prefix: "[" + task.ID + "] "}
+	}
+	if c.eventSink != nil {
+		w.eventSink = &lockedSink{mu: outMu, sink: c.eventSink}
+	}
+	return &w
+}
+
+// mergeWorker copies a finished worker's state for taskID back to c.
+func (c *Controller) mergeWorker(w *Controller, taskID string) {
+	if attempts, ok := w.taskAttempts[taskID]; ok {
+		c.taskAttempts[taskID] = attempts
+	} else {
+		delete(c.taskAttempts, taskID)
+	}
+	if plan, ok := w.taskPlans[taskID]; ok {
+		if c.taskPlans == nil {
+			c.taskPlans = make(map[string]string)
+		}
+		c.taskPlans[taskID] = plan
+	}
+	if w.profiler != nil {
+		c.profiler.iterations = append(c.profiler.iterations, w.profiler.iterations...)
+	}
+}
+
+// applyWorkerCommits cherry-picks the commits a worker made on top of base
+// onto the current branch and points record at them. When they conflict, the
+// iteration fails and the task reopens with its attempt counted.
+func (c *Controller) applyWorkerCommits(ctx context.Context, wm worktreeManager, task *taskstore.Task, base string, record *IterationRecord) {
+	head, err := c.gitManager.GetCurrentCommit(ctx)
+	if err == nil {
+		var commit string
+		commit, err = wm.CherryPick(ctx, base, record.ResultCommit)
+		if err == nil {
+			record.BaseCommit = head
+			record.ResultCommit = commit
+			c.writeProgress("📝 [%s] Applied to the branch: %s\n", task.ID, commit)
+			return
+		}
+	}
+
+	c.writeProgress("✗ [%s] Changes could not be applied alongside the other parallel tasks, reopening\n", task.ID)
+	record.Outcome = OutcomeFailed
+	record.ResultCommit = ""
+	record.SetFeedback(fmt.Sprintf("Changes conflicted with tasks run in parallel and were not applied: %v", err))
+	c.taskAttempts[task.ID] = record.AttemptNumber
+	_ = c.taskStore.UpdateStatus(task.ID, taskstore.StatusOpen)
+}
+
+// prefixWriter writes each line with a prefix, so the progress of parallel
+// workers can be told apart. Writers sharing mu never interleave mid-write.
+type prefixWriter struct {
+	mu      *sync.Mutex
+	w       io.Writer
+	prefix  string
+	midLine bool
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var out bytes.Buffer
+	for line := range bytes.SplitAfterSeq(b, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if !p.midLine {
+			out.WriteString(p.prefix)
+		}
+		out.Write(line)
+		p.midLine = !bytes.HasSuffix(line, []byte("\n"))
+	}
+	if _, err := p.w.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// lockedSink serializes parallel workers' events.
+type lockedSink struct {
+	mu   *sync.Mutex
+	sink EventSink
+}
+
+func (s *lockedSink) Emit(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sink.Emit(event)
+}
@@ -0,0 +1,224 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/selector"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// worktreeRunner is a Claude runner, safe for concurrent use, that writes a
+// file in the directory it is run in. Parallel workers run in worktrees named
+// after their task.
+type worktreeRunner struct {
+	mu    sync.Mutex
+	cwds  []string
+	write func(cwd string) (name, content string)
+}
+
+func (r *worktreeRunner) Run(ctx context.Context, req claude.ClaudeRequest) (*claude.ClaudeResponse, error) {
+	r.mu.Lock()
+	r.cwds = append(r.cwds, req.Cwd)
+	r.mu.Unlock()
+
+	name, content := r.write(req.Cwd)
+	if err := os.WriteFile(filepath.Join(req.Cwd, name), []byte(content), 0644); err != nil {
+		return nil, err
+	}
+	return &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done", TotalCostUSD: 0.01}, nil
+}
+
+// setupParallelRepo creates a git repository with one commit and a task
+// store holding a parent task with the given open leaf tasks, each verified
+// by the file the agent writes.
+func setupParallelRepo(t *testing.T, verify map[string][]string) (string, taskstore.Store) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".ralph", "state"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte(".ralph/\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared.txt"), []byte("base\n"), 0644))
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-m", "initial"}} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	store, err := taskstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Save(newTestTask("feature", "Feature", taskstore.StatusOpen, nil)))
+	for id, command := range verify {
+		task := newTestTask(id, "Task "+id, taskstore.StatusOpen, strPtr("feature"))
+		task.Verify = [][]string{command}
+		require.NoError(t, store.Save(task))
+	}
+	return dir, store
+}
+
+func newParallelController(dir string, store taskstore.Store, runner claude.Runner, progress *bytes.Buffer) *Controller {
+	c := NewController(ControllerDeps{
+		TaskStore:      store,
+		Claude:         runner,
+		Verifier:       verifier.NewCommandRunner(dir),
+		Git:            git.NewShellManager(dir, "ralph/"),
+		WorkDir:        dir,
+		LogsDir:        filepath.Join(dir, ".ralph", "logs"),
+		ProgressWriter: progress,
+	})
+	c.SetMaxConcurrency(3)
+	return c
+}
+
+func TestController_RunLoop_RunsIndependentTasksInParallel(t *testing.T) {
+	dir, store := setupParallelRepo(t, map[string][]string{
+		"task-a": {"test", "-f", "task-a.txt"},
+		"task-b": {"test", "-f", "task-b.txt"},
+		"task-c": {"test", "-f", "task-c.txt"},
+	})
+	runner := &worktreeRunner{write: func(cwd string) (string, string) {
+		return filepath.Base(cwd) + ".txt", "done\n"
+	}}
+	var progress bytes.Buffer
+	c := newParallelController(dir, store, runner, &progress)
+
+	result := c.RunLoop(context.Background(), "feature")
+
+	assert.Equal(t, RunOutcomeCompleted, result.Outcome, result.Message)
+	assert.ElementsMatch(t, []string{"task-a", "task-b", "task-c"}, result.CompletedTasks)
+	assert.Equal(t, 3, result.IterationsRun)
+	assert.Equal(t, 3, c.budget.GetState().Iterations)
+
+	require.Len(t, runner.cwds, 3)
+	for _, cwd := range runner.cwds {
+		assert.NotEqual(t, dir, cwd, "each task runs in its own worktree")
+	}
+	for _, id := range []string{"task-a", "task-b", "task-c"} {
+		assert.FileExists(t, filepath.Join(dir, id+".txt"), "commits are applied to the branch")
+	}
+
+	log, err := exec.Command("git", "-C", dir, "log", "--format=%s", "main..HEAD").Output()
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(log)), "\n"), 3)
+	for _, record := range result.Records {
+		head, err := exec.Command("git", "-C", dir, "merge-base", "--is-ancestor", record.ResultCommit, "HEAD").CombinedOutput()
+		assert.NoError(t, err, "record points at a commit on the branch: %s", head)
+	}
+
+	assert.Contains(t, progress.String(), "Running 3 tasks in parallel")
+	assert.Contains(t, progress.String(), "[task-b] ")
+	wt, err := exec.Command("git", "-C", dir, "worktree", "list").Output()
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(wt)), "\n"), 1, "worktrees are removed")
+}
+
+func TestController_RunLoop_ReopensTaskWhoseParallelChangesConflict(t *testing.T) {
+	dir, store := setupParallelRepo(t, map[string][]string{
+		"task-a": {"grep", "-qv", "^base$", "shared.txt"},
+		"task-b": {"grep", "-qv", "^base$", "shared.txt"},
+	})
+	runner := &worktreeRunner{write: func(cwd string) (string, string) {
+		return "shared.txt", filepath.Base(cwd) + "\n"
+	}}
+	var progress bytes.Buffer
+	c := newParallelController(dir, store, runner, &progress)
+	c.SetGutterConfig(GutterConfig{}) // every iteration edits shared.txt
+
+	result := c.RunLoop(context.Background(), "feature")
+
+	assert.Equal(t, RunOutcomeCompleted, result.Outcome, result.Message)
+	assert.ElementsMatch(t, []string{"task-a", "task-b"}, result.CompletedTasks)
+	require.Len(t, result.FailedTasks, 1)
+	assert.Equal(t, 3, result.IterationsRun, "the conflicting task runs again on its own")
+	assert.Equal(t, dir, runner.cwds[2])
+	assert.Contains(t, progress.String(), "could not be applied alongside the other parallel tasks")
+
+	failed := result.Records[1]
+	assert.Equal(t, OutcomeFailed, failed.Outcome)
+	assert.Empty(t, failed.ResultCommit)
+	assert.Contains(t, failed.Feedback, "conflicted with tasks run in parallel")
+	assert.Equal(t, 2, result.Records[2].AttemptNumber)
+}
+
+func TestController_ParallelBatch(t *testing.T) {
+	parent := strPtr("feature")
+	tasks := []*taskstore.Task{
+		newTestTask("feature", "Feature", taskstore.StatusOpen, nil),
+		newTestTask("task-a", "A", taskstore.StatusOpen, parent),
+		newTestTask("task-b", "B", taskstore.StatusOpen, parent),
+		newTestTask("task-c", "C", taskstore.StatusOpen, parent),
+		newTestTask("task-d", "D", taskstore.StatusOpen, parent),
+	}
+	tasks[4].DependsOn = []string{"task-a"}
+	graph, err := selector.BuildGraph(tasks)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		setup func(c *Controller)
+		want  []string
+	}{
+		{"one at a time by default", func(c *Controller) {}, nil},
+		{"ready tasks only", func(c *Controller) { c.SetMaxConcurrency(8) }, []string{"task-b", "task-a", "task-c"}},
+		{"capped by concurrency", func(c *Controller) { c.SetMaxConcurrency(2) }, []string{"task-b", "task-a"}},
+		{"capped by remaining iterations", func(c *Controller) {
+			c.SetMaxConcurrency(8)
+			c.SetBudgetLimits(BudgetLimits{MaxIterations: 2})
+		}, []string{"task-b", "task-a"}},
+		{"not with a git manager without worktrees", func(c *Controller) {
+			c.SetMaxConcurrency(8)
+			c.gitManager = &mockGitManager{}
+		}, nil},
+		{"not in per_run commit mode", func(c *Controller) {
+			c.SetMaxConcurrency(8)
+			c.commitPerRun = true
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewController(ControllerDeps{
+				TaskStore: newMockTaskStore(),
+				Verifier:  verifier.NewCommandRunner(t.TempDir()),
+				Git:       git.NewShellManager(t.TempDir(), "ralph/"),
+			})
+			tt.setup(c)
+
+			var ids []string
+			for _, task := range c.parallelBatch(tasks, graph, "feature", tasks[2]) {
+				ids = append(ids, task.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	mu := &sync.Mutex{}
+	w := &prefixWriter{mu: mu, w: &out, prefix: "[a] "}
+
+	_, _ = w.Write([]byte("one\ntw"))
+	_, _ = w.Write([]byte("o\n\nthree\n"))
+
+	assert.Equal(t, "[a] one\n[a] two\n[a] \n[a] three\n", out.String())
+}
@@ -0,0 +1,116 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// PlanningLabel is the task label that turns the planning step on ("true")
+// or off ("false") for a single task, overriding PlanningConfig.Enabled.
+const PlanningLabel = "planning"
+
+// PlanningConfig configures the planning step: before a task is implemented,
+// the agent is asked for a short plan (no code), which is recorded and added
+// to the implementation prompt.
+type PlanningConfig struct {
+	// Enabled plans every task unless its planning label is "false".
+	Enabled bool
+
+	// Model is the model used for the planning call (empty = the provider's
+	// default), so a cheaper model can plan for a more capable one.
+	Model string
+}
+
+// planningSystemPrompt instructs the agent to plan without implementing.
+const planningSystemPrompt = `You are planning a coding task that another session will implement.
+Do NOT write code or modify any files; you may only read the codebase.
+
+Produce a short plan (at most 10 numbered steps) covering:
+- the files to create or modify
+- the approach for each change
+- edge cases or risks to watch for
+- how to verify the change
+
+Output the plan only, with no preamble.`
+
+// planningTools are the read-only tools available to the planning call.
+var planningTools = []string{"Read", "Glob", "Grep"}
+
+// SetPlanning configures the planning step. The zero value disables it
+// except for tasks labelled planning: true.
+func (c *Controller) SetPlanning(cfg PlanningConfig) {
+	c.planning = cfg
+}
+
+// planningEnabled reports whether task should be planned before it is
+// implemented.
+func (c *Controller) planningEnabled(task *taskstore.Task) bool {
+	if value, ok := task.Labels[PlanningLabel]; ok {
+		enabled, err := strconv.ParseBool(value)
+		return err == nil && enabled
+	}
+	return c.planning.Enabled
+}
+
+// planTask returns the implementation plan for task, asking the agent for
+// one the first time the task is attempted in this run; retries reuse it.
+// userPrompt is the implementation prompt, which gives the planner the same
+// context. The returned spend holds the cost and tokens of the planning call
+// (zero when reused). A failed planning call is reported and the task
+// proceeds without a plan.
+func (c *Controller) planTask(ctx context.Context, task *taskstore.Task, userPrompt string) (string, ClaudeInvocationMeta) {
+	if !c.planningEnabled(task) {
+		return "", ClaudeInvocationMeta{}
+	}
+	if plan, ok := c.taskPlans[task.ID]; ok {
+		return plan, ClaudeInvocationMeta{}
+	}
+
+	c.writeProgress("  🧭 Planning...\n")
+	req := claude.ClaudeRequest{
+		SystemPrompt: planningSystemPrompt,
+		Prompt:       "Plan the following task. Do not implement it.\n\n" + userPrompt,
+		AllowedTools: planningTools,
+		Cwd:          c.codeDir(),
+	}
+	if c.planning.Model != "" {
+		req.ExtraArgs = []string{"--model", c.planning.Model}
+	}
+
+	resp, err := c.claudeRunner.Run(ctx, req)
+	if err != nil {
+		c.writeProgress("  ⚠ Planning failed, continuing without a plan: %v\n", err)
+		return "", ClaudeInvocationMeta{}
+	}
+	spend := ClaudeInvocationMeta{
+		TotalCostUSD: resp.TotalCostUSD,
+		InputTokens:  resp.Usage.InputTokens,
+		OutputTokens: resp.Usage.OutputTokens,
+	}
+
+	plan := strings.TrimSpace(resp.FinalText)
+	if plan == "" {
+		c.writeProgress("  ⚠ Planning produced no plan, continuing without one\n")
+		return "", spend
+	}
+
+	if c.taskPlans == nil {
+		c.taskPlans = make(map[string]string)
+	}
+	c.taskPlans[task.ID] = plan
+	c.writeVerbose("  Plan:\n%s\n", indentLines(plan, "    "))
+	return plan, spend
+}
+
+// withPlan appends plan to the implementation prompt.
+func withPlan(userPrompt, plan string) string {
+	if plan == "" {
+		return userPrompt
+	}
+	return fmt.Sprintf("%s\n\n## Implementation Plan\nA planning step produced this plan. Follow it unless you find it is wrong:\n\n%s\n", userPrompt, plan)
+}
@@ -0,0 +1,174 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// planningRunner answers planning calls with a plan and other calls with "Done".
+type planningRunner struct {
+	planErr error
+	implErr error
+	calls   []claude.ClaudeRequest
+}
+
+func (m *planningRunner) Run(ctx context.Context, req claude.ClaudeRequest) (*claude.ClaudeResponse, error) {
+	m.calls = append(m.calls, req)
+	if req.SystemPrompt == planningSystemPrompt {
+		if m.planErr != nil {
+			return nil, m.planErr
+		}
+		return &claude.ClaudeResponse{
+			SessionID:    "plan",
+			FinalText:    "1. Edit f.go\n2. Run go test",
+			TotalCostUSD: 0.01,
+			Usage:        claude.ClaudeUsage{InputTokens: 100, OutputTokens: 20},
+		}, nil
+	}
+	if m.implErr != nil {
+		return nil, m.implErr
+	}
+	return &claude.ClaudeResponse{SessionID: "impl", FinalText: "Done", TotalCostUSD: 0.05}, nil
+}
+
+func TestController_Planning(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   PlanningConfig
+		label    string
+		wantPlan bool
+	}{
+		{name: "disabled by default"},
+		{name: "enabled globally", config: PlanningConfig{Enabled: true, Model: "haiku"}, wantPlan: true},
+		{name: "enabled by label", label: "true", wantPlan: true},
+		{name: "disabled by label", config: PlanningConfig{Enabled: true}, label: "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+			task := newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent"))
+			if tt.label != "" {
+				task.Labels = map[string]string{PlanningLabel: tt.label}
+			}
+			store.addTask(task)
+
+			runner := &planningRunner{}
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    runner,
+				Verifier:  &mockVerifier{results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}}},
+				Git:       &mockGitManager{currentCommit: "abc", hasChanges: true, changedFiles: []string{"f.go"}},
+				LogsDir:   t.TempDir(),
+			})
+			ctrl.SetPlanning(tt.config)
+
+			result := ctrl.RunOnce(context.Background(), "parent")
+			require.Len(t, result.Records, 1)
+			record := result.Records[0]
+
+			if !tt.wantPlan {
+				require.Len(t, runner.calls, 1)
+				assert.Empty(t, record.Plan)
+				assert.NotContains(t, runner.calls[0].Prompt, "Implementation Plan")
+				return
+			}
+
+			require.Len(t, runner.calls, 2)
+			planReq := runner.calls[0]
+			assert.Equal(t, planningTools, planReq.AllowedTools)
+			if tt.config.Model != "" {
+				assert.Equal(t, []string{"--model", tt.config.Model}, planReq.ExtraArgs)
+			} else {
+				assert.Empty(t, planReq.ExtraArgs)
+			}
+			assert.Contains(t, planReq.Prompt, "Task A")
+
+			assert.Contains(t, runner.calls[1].Prompt, "## Implementation Plan")
+			assert.Contains(t, runner.calls[1].Prompt, "1. Edit f.go")
+			assert.Empty(t, runner.calls[1].ExtraArgs)
+			assert.Equal(t, "1. Edit f.go\n2. Run go test", record.Plan)
+			assert.InDelta(t, 0.06, record.ClaudeInvocation.TotalCostUSD, 1e-9, "the planning call's cost is counted")
+		})
+	}
+
+	t.Run("failed planning continues without a plan", func(t *testing.T) {
+		store := newMockTaskStore()
+		store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+		store.addTask(newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent")))
+
+		runner := &planningRunner{planErr: errors.New("rate limited")}
+		ctrl := NewController(ControllerDeps{
+			TaskStore: store,
+			Claude:    runner,
+			Verifier:  &mockVerifier{results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}}},
+			Git:       &mockGitManager{currentCommit: "abc", hasChanges: true, changedFiles: []string{"f.go"}},
+			LogsDir:   t.TempDir(),
+		})
+		ctrl.SetPlanning(PlanningConfig{Enabled: true})
+
+		result := ctrl.RunOnce(context.Background(), "parent")
+
+		require.Len(t, runner.calls, 2)
+		assert.Equal(t, taskstore.StatusCompleted, store.tasks["task-a"].Status)
+		assert.Empty(t, result.Records[0].Plan)
+	})
+
+	t.Run("retries reuse the plan", func(t *testing.T) {
+		ctrl := NewController(ControllerDeps{Claude: &planningRunner{}})
+		ctrl.SetPlanning(PlanningConfig{Enabled: true})
+		task := &taskstore.Task{ID: "task-a"}
+
+		plan, spend := ctrl.planTask(context.Background(), task, "prompt")
+		require.NotEmpty(t, plan)
+		assert.InDelta(t, 0.01, spend.TotalCostUSD, 1e-9)
+		assert.Equal(t, 100, spend.InputTokens)
+		assert.Equal(t, 20, spend.OutputTokens)
+
+		again, spend := ctrl.planTask(context.Background(), task, "prompt")
+		assert.Equal(t, plan, again)
+		assert.Zero(t, spend)
+	})
+
+	t.Run("a failed invocation keeps the planning spend", func(t *testing.T) {
+		store := newMockTaskStore()
+		store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+		store.addTask(newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent")))
+
+		ctrl := NewController(ControllerDeps{
+			TaskStore: store,
+			Claude:    &planningRunner{implErr: errors.New("connection reset")},
+			Verifier:  &mockVerifier{},
+			Git:       &mockGitManager{currentCommit: "abc"},
+			LogsDir:   t.TempDir(),
+		})
+		ctrl.SetPlanning(PlanningConfig{Enabled: true})
+
+		result := ctrl.RunOnce(context.Background(), "parent")
+
+		require.Len(t, result.Records, 1)
+		invocation := result.Records[0].ClaudeInvocation
+		assert.Equal(t, OutcomeFailed, result.Records[0].Outcome)
+		assert.InDelta(t, 0.01, invocation.TotalCostUSD, 1e-9)
+		assert.Equal(t, 100, invocation.InputTokens)
+		assert.Equal(t, 20, invocation.OutputTokens)
+		assert.InDelta(t, 0.01, result.TotalCostUSD, 1e-9)
+		assert.InDelta(t, 0.01, ctrl.budget.GetState().TotalCostUSD, 1e-9, "the budget tracker sees the planning spend")
+	})
+}
+
+func TestGenerateTextLog_Plan(t *testing.T) {
+	record := NewIterationRecord("task-a")
+	record.Plan = "1. Edit f.go\n2. Run go test"
+
+	assert.Contains(t, GenerateTextLog(record), "\nPlan:\n  1. Edit f.go\n  2. Run go test\n")
+}
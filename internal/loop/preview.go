@@ -0,0 +1,74 @@
+package loop
+
+import (
+	"context"
+	"slices"
+
+	"github.com/yarlson/ralph/internal/prompt"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// BuildPromptForAttempt builds the system and user prompts for the given attempt
+// of task without invoking the agent. Attempt 1 gets the initial prompt; later
+// attempts get the retry prompt with the previous failure and any user feedback.
+func (c *Controller) BuildPromptForAttempt(ctx context.Context, task *taskstore.Task, attempt int) (string, string, error) {
+	task, err := task.ExpandVerifySet(c.verifySets)
+	if err != nil {
+		return "", "", err
+	}
+
+	builder := prompt.NewBuilder(nil) // Use default size options
+	if attempt > 1 {
+		return c.buildRetryPrompt(ctx, task, attempt, builder)
+	}
+	return c.buildInitialPrompt(ctx, task, builder)
+}
+
+// PreviewNext returns the task a run under parentTaskID would select first,
+// and why, without changing any state. It returns a nil task when no task is
+// ready.
+func (c *Controller) PreviewNext(ctx context.Context, parentTaskID string) (*taskstore.Task, []string, error) {
+	tasks, graph, err := c.listTasksWithGraph()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	task := c.selectNext(ctx, tasks, graph, parentTaskID)
+	if task == nil {
+		return nil, nil, nil
+	}
+	return task, c.selectionReasons(tasks, task), nil
+}
+
+// NextAttemptNumber returns the attempt number of the task's next iteration,
+// based on the iteration records in logsDir: one more than the number of
+// unsuccessful iterations since the task last succeeded or checkpointed. The
+// loop numbers a task's first attempt in a run the same way.
+func NextAttemptNumber(logsDir, taskID string) (int, error) {
+	records, err := LoadAllIterationRecords(logsDir)
+	if err != nil {
+		return 0, err
+	}
+	return nextAttempt(records, taskID), nil
+}
+
+// nextAttempt returns the attempt number of the task's next iteration given
+// all iteration records.
+func nextAttempt(records []*IterationRecord, taskID string) int {
+	slices.SortStableFunc(records, func(a, b *IterationRecord) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+
+	failures := 0
+	for _, r := range records {
+		if r.TaskID != taskID {
+			continue
+		}
+		if r.Outcome == OutcomeSuccess || r.Outcome == OutcomeCheckpoint {
+			failures = 0
+		} else {
+			failures++
+		}
+	}
+	return failures + 1
+}
@@ -0,0 +1,81 @@
+package loop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestNextAttemptNumber(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		outcomes []IterationOutcome
+		want     int
+	}{
+		{name: "no history", want: 1},
+		{name: "one failure", outcomes: []IterationOutcome{OutcomeFailed}, want: 2},
+		{name: "failures after success", outcomes: []IterationOutcome{OutcomeFailed, OutcomeSuccess, OutcomeFailed, OutcomeBudgetExceeded}, want: 3},
+		{name: "last success resets", outcomes: []IterationOutcome{OutcomeFailed, OutcomeSuccess}, want: 1},
+		{name: "checkpoint resets", outcomes: []IterationOutcome{OutcomeFailed, OutcomeCheckpoint}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logsDir := t.TempDir()
+			// Iteration IDs are random, so file order is not chronological
+			for i := range tt.outcomes {
+				_, err := SaveRecord(logsDir, &IterationRecord{
+					IterationID: GenerateIterationID(),
+					TaskID:      "task-1",
+					StartTime:   now.Add(time.Duration(i) * time.Minute),
+					Outcome:     tt.outcomes[i],
+				})
+				require.NoError(t, err)
+			}
+			_, err := SaveRecord(logsDir, &IterationRecord{IterationID: "other", TaskID: "task-2", StartTime: now, Outcome: OutcomeFailed})
+			require.NoError(t, err)
+
+			got, err := NextAttemptNumber(logsDir, "task-1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestController_RunOnce_AttemptMatchesPreview(t *testing.T) {
+	logsDir := t.TempDir()
+	_, err := SaveRecord(logsDir, &IterationRecord{
+		IterationID: GenerateIterationID(),
+		TaskID:      "child",
+		StartTime:   time.Now().Add(-time.Hour),
+		Outcome:     OutcomeFailed,
+	})
+	require.NoError(t, err)
+
+	preview, err := NextAttemptNumber(logsDir, "child")
+	require.NoError(t, err)
+
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("child", "Child Task", taskstore.StatusOpen, strPtr("parent")))
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{FinalText: "done"}},
+		Verifier:  &mockVerifier{},
+		Git:       &mockGitManager{},
+		LogsDir:   logsDir,
+	})
+
+	result := ctrl.RunOnce(context.Background(), "parent")
+
+	require.Len(t, result.Records, 1)
+	assert.Equal(t, 2, preview)
+	assert.Equal(t, preview, result.Records[0].AttemptNumber)
+}
@@ -0,0 +1,336 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// Phase is a part of an iteration whose time is profiled.
+type Phase string
+
+const (
+	// PhasePrompt is building the initial and retry prompts.
+	PhasePrompt Phase = "prompt"
+	// PhaseAgent is waiting for the agent to respond.
+	PhaseAgent Phase = "agent"
+	// PhaseVerification is running the build and verify commands.
+	PhaseVerification Phase = "verification"
+	// PhaseGit is running git operations (status, diff, stash, commit).
+	PhaseGit Phase = "git"
+)
+
+// Phases returns the profiled phases in display order.
+func Phases() []Phase {
+	return []Phase{PhasePrompt, PhaseAgent, PhaseVerification, PhaseGit}
+}
+
+// IterationProfile is the time an iteration spent in each phase.
+type IterationProfile struct {
+	TaskID      string
+	IterationID string
+	Total       time.Duration
+	Phases      map[Phase]time.Duration
+}
+
+// Other returns the time not attributed to any phase (task store access,
+// state files, progress output).
+func (p IterationProfile) Other() time.Duration {
+	other := p.Total
+	for _, d := range p.Phases {
+		other -= d
+	}
+	return max(other, 0)
+}
+
+// SumProfiles aggregates iteration profiles into a single profile.
+func SumProfiles(profiles []IterationProfile) IterationProfile {
+	sum := IterationProfile{Phases: make(map[Phase]time.Duration)}
+	for _, p := range profiles {
+		sum.Total += p.Total
+		for phase, d := range p.Phases {
+			sum.Phases[phase] += d
+		}
+	}
+	return sum
+}
+
+// profiler collects phase timings for the iteration in progress.
+type profiler struct {
+	current    *IterationProfile
+	iterations []IterationProfile
+}
+
+// track adds the time since start to phase of the current iteration.
+// Calls outside an iteration (branch setup, clean-tree checks) are ignored.
+func (p *profiler) track(phase Phase, start time.Time) {
+	if p == nil || p.current == nil {
+		return
+	}
+	p.current.Phases[phase] += time.Since(start)
+}
+
+// SetProfile enables per-phase timing of iterations. Profiles are reported in
+// RunResult.Profile and after each iteration. When disabled (the default),
+// nothing is timed.
+func (c *Controller) SetProfile(enabled bool) {
+	if !enabled || c.profiler != nil {
+		return
+	}
+	c.setProfiler(&profiler{})
+}
+
+// setProfiler makes p time the controller's agent, verifier and git calls,
+// replacing any profiler they were timed by before.
+func (c *Controller) setProfiler(p *profiler) {
+	c.profiler = p
+	if r, ok := c.claudeRunner.(*timedRunner); ok {
+		c.claudeRunner = r.Runner
+	}
+	if v, ok := c.verifier.(*timedVerifier); ok {
+		c.verifier = v.Verifier
+	}
+	if g, ok := c.gitManager.(*timedGitManager); ok {
+		c.gitManager = g.Manager
+	}
+	c.claudeRunner = &timedRunner{Runner: c.claudeRunner, profiler: p}
+	c.verifier = &timedVerifier{Verifier: c.verifier, profiler: p}
+	c.gitManager = &timedGitManager{Manager: c.gitManager, profiler: p}
+}
+
+// startProfile begins profiling an iteration. The returned function finishes
+// it once the record is complete; it is a no-op when profiling is disabled.
+func (c *Controller) startProfile(record *IterationRecord) func() {
+	if c.profiler == nil {
+		return func() {}
+	}
+	c.profiler.current = &IterationProfile{
+		TaskID:      record.TaskID,
+		IterationID: record.IterationID,
+		Phases:      make(map[Phase]time.Duration),
+	}
+	return func() {
+		profile := *c.profiler.current
+		profile.Total = record.Duration()
+		c.profiler.iterations = append(c.profiler.iterations, profile)
+		c.profiler.current = nil
+		c.writeProgress("  ⏱ %s\n", FormatProfile(profile))
+	}
+}
+
+// takeProfiles returns the profiles collected since the last call.
+func (c *Controller) takeProfiles() []IterationProfile {
+	if c.profiler == nil {
+		return nil
+	}
+	profiles := c.profiler.iterations
+	c.profiler.iterations = nil
+	return profiles
+}
+
+// FormatProfile formats a profile on one line, e.g.
+// "prompt 12ms · agent 1m2s · verification 8s · git 210ms · other 5ms".
+func FormatProfile(p IterationProfile) string {
+	parts := make([]string, 0, len(Phases())+1)
+	for _, phase := range Phases() {
+		parts = append(parts, fmt.Sprintf("%s %s", phase, p.Phases[phase].Round(time.Millisecond)))
+	}
+	parts = append(parts, fmt.Sprintf("other %s", p.Other().Round(time.Millisecond)))
+	return strings.Join(parts, " · ")
+}
+
+// timedRunner records the time spent in the agent.
+type timedRunner struct {
+	claude.Runner
+	profiler *profiler
+}
+
+func (r *timedRunner) Run(ctx context.Context, req claude.ClaudeRequest) (*claude.ClaudeResponse, error) {
+	defer r.profiler.track(PhaseAgent, time.Now())
+	return r.Runner.Run(ctx, req)
+}
+
+// timedVerifier records the time spent running verification commands.
+type timedVerifier struct {
+	verifier.Verifier
+	profiler *profiler
+}
+
+func (v *timedVerifier) Verify(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+	defer v.profiler.track(PhaseVerification, time.Now())
+	return v.Verifier.Verify(ctx, commands)
+}
+
+func (v *timedVerifier) VerifyTask(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+	defer v.profiler.track(PhaseVerification, time.Now())
+	return v.Verifier.VerifyTask(ctx, commands)
+}
+
+// Unwrap returns the wrapped verifier.
+func (v *timedVerifier) Unwrap() verifier.Verifier {
+	return v.Verifier
+}
+
+// InDir returns a timed copy of the wrapped verifier that runs commands in
+// dir. If the wrapped verifier cannot change directory, v is returned.
+func (v *timedVerifier) InDir(dir string) verifier.Verifier {
+	d, ok := v.Verifier.(dirVerifier)
+	if !ok {
+		return v
+	}
+	return &timedVerifier{Verifier: d.InDir(dir), profiler: v.profiler}
+}
+
+// WithExitCodes returns a timed copy of the wrapped verifier that applies
+// codes. If the wrapped verifier cannot map exit codes, v is returned.
+func (v *timedVerifier) WithExitCodes(codes verifier.ExitCodes) verifier.Verifier {
+	e, ok := v.Verifier.(exitCodeVerifier)
+	if !ok {
+		return v
+	}
+	return &timedVerifier{Verifier: e.WithExitCodes(codes), profiler: v.profiler}
+}
+
+func (v *timedVerifier) Fingerprint() string {
+	f, ok := v.Verifier.(fingerprinter)
+	if !ok {
+		return ""
+	}
+	return f.Fingerprint()
+}
+
+// timedGitManager records the time spent in git operations.
+type timedGitManager struct {
+	git.Manager
+	profiler *profiler
+}
+
+func (g *timedGitManager) Init(ctx context.Context) error {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.Init(ctx)
+}
+
+func (g *timedGitManager) EnsureBranch(ctx context.Context, branchName string) error {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.EnsureBranch(ctx, branchName)
+}
+
+func (g *timedGitManager) GetCurrentCommit(ctx context.Context) (string, error) {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.GetCurrentCommit(ctx)
+}
+
+func (g *timedGitManager) HasChanges(ctx context.Context) (bool, error) {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.HasChanges(ctx)
+}
+
+func (g *timedGitManager) GetDiffStat(ctx context.Context) (string, error) {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.GetDiffStat(ctx)
+}
+
+func (g *timedGitManager) GetChangedFiles(ctx context.Context) ([]string, error) {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.GetChangedFiles(ctx)
+}
+
+func (g *timedGitManager) GetFileChanges(ctx context.Context) ([]git.FileChange, error) {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.GetFileChanges(ctx)
+}
+
+func (g *timedGitManager) Commit(ctx context.Context, message string) (string, error) {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.Commit(ctx, message)
+}
+
+func (g *timedGitManager) GetCurrentBranch(ctx context.Context) (string, error) {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.GetCurrentBranch(ctx)
+}
+
+func (g *timedGitManager) GetCommitMessage(ctx context.Context, hash string) (string, error) {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.GetCommitMessage(ctx, hash)
+}
+
+func (g *timedGitManager) StashChanges(ctx context.Context, message string, exclude []string) error {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.StashChanges(ctx, message, exclude)
+}
+
+func (g *timedGitManager) Stage(ctx context.Context) error {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.Stage(ctx)
+}
+
+func (g *timedGitManager) GetUnstagedFiles(ctx context.Context) ([]string, error) {
+	defer g.profiler.track(PhaseGit, time.Now())
+	return g.Manager.GetUnstagedFiles(ctx)
+}
+
+// Unwrap returns the wrapped manager.
+func (g *timedGitManager) Unwrap() git.Manager {
+	return g.Manager
+}
+
+func (g *timedGitManager) Push(ctx context.Context, remote, branch string) error {
+	p, ok := g.Manager.(pusher)
+	if !ok {
+		return errors.New("the git manager cannot push")
+	}
+	defer g.profiler.track(PhaseGit, time.Now())
+	return p.Push(ctx, remote, branch)
+}
+
+func (g *timedGitManager) IsAncestor(ctx context.Context, ancestor, descendant string) (bool, error) {
+	a, ok := g.Manager.(ancestryChecker)
+	if !ok {
+		return false, errors.New("the git manager cannot check ancestry")
+	}
+	defer g.profiler.track(PhaseGit, time.Now())
+	return a.IsAncestor(ctx, ancestor, descendant)
+}
+
+func (g *timedGitManager) WorkingTreeHash(ctx context.Context) (string, error) {
+	h, ok := g.Manager.(treeHasher)
+	if !ok {
+		return "", errors.New("the git manager cannot hash the working tree")
+	}
+	defer g.profiler.track(PhaseGit, time.Now())
+	return h.WorkingTreeHash(ctx)
+}
+
+func (g *timedGitManager) AddWorktree(ctx context.Context, path string) (git.Manager, error) {
+	w, ok := g.Manager.(worktreeManager)
+	if !ok {
+		return nil, errors.New("the git manager cannot manage worktrees")
+	}
+	defer g.profiler.track(PhaseGit, time.Now())
+	return w.AddWorktree(ctx, path)
+}
+
+func (g *timedGitManager) RemoveWorktree(ctx context.Context, path string) error {
+	w, ok := g.Manager.(worktreeManager)
+	if !ok {
+		return errors.New("the git manager cannot manage worktrees")
+	}
+	defer g.profiler.track(PhaseGit, time.Now())
+	return w.RemoveWorktree(ctx, path)
+}
+
+func (g *timedGitManager) CherryPick(ctx context.Context, base, tip string) (string, error) {
+	w, ok := g.Manager.(worktreeManager)
+	if !ok {
+		return "", errors.New("the git manager cannot manage worktrees")
+	}
+	defer g.profiler.track(PhaseGit, time.Now())
+	return w.CherryPick(ctx, base, tip)
+}
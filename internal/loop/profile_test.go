@@ -0,0 +1,236 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func newProfiledController(t *testing.T, progress *bytes.Buffer) *Controller {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+	task := newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent"))
+	task.Verify = [][]string{{"go", "test"}}
+	store.addTask(task)
+
+	return NewController(ControllerDeps{
+		TaskStore: store,
+		Claude: &mockClaudeRunner{
+			response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"},
+		},
+		Verifier: &mockVerifier{
+			results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}},
+		},
+		Git: &mockGitManager{
+			currentCommit: "abc",
+			hasChanges:    true,
+			changedFiles:  []string{"f.go"},
+			commitHash:    "def",
+		},
+		LogsDir:        t.TempDir(),
+		ProgressDir:    t.TempDir(),
+		ProgressWriter: progress,
+	})
+}
+
+func TestController_Profile(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		ctrl := newProfiledController(t, &bytes.Buffer{})
+
+		result := ctrl.RunOnce(context.Background(), "parent")
+
+		assert.Equal(t, 1, result.IterationsRun)
+		assert.Nil(t, result.Profile)
+	})
+
+	t.Run("records phases per iteration", func(t *testing.T) {
+		var progress bytes.Buffer
+		ctrl := newProfiledController(t, &progress)
+		ctrl.SetProfile(true)
+		ctrl.SetProfile(true) // enabling twice must not double count
+
+		result := ctrl.RunOnce(context.Background(), "parent")
+
+		require.Len(t, result.Profile, 1)
+		profile := result.Profile[0]
+		assert.Equal(t, "task-a", profile.TaskID)
+		assert.Equal(t, result.Records[0].IterationID, profile.IterationID)
+		assert.Equal(t, result.Records[0].Duration(), profile.Total)
+		for _, phase := range Phases() {
+			assert.Contains(t, profile.Phases, phase)
+		}
+		assert.Contains(t, progress.String(), "⏱ prompt ")
+	})
+
+	t.Run("profiles are reported once", func(t *testing.T) {
+		ctrl := newProfiledController(t, &bytes.Buffer{})
+		ctrl.SetProfile(true)
+
+		first := ctrl.RunOnce(context.Background(), "parent")
+		second := ctrl.RunOnce(context.Background(), "parent")
+
+		assert.Len(t, first.Profile, 1)
+		assert.Empty(t, second.Profile)
+	})
+}
+
+func TestTimedGitManager_ForwardsOptionalMethods(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("supported by the wrapped manager", func(t *testing.T) {
+		base := &worktreeGitManager{mockGitManager: &mockGitManager{}}
+		p := &profiler{current: &IterationProfile{Phases: make(map[Phase]time.Duration)}}
+		timed := &timedGitManager{Manager: &stagedGitManager{Manager: base}, profiler: p}
+
+		assert.Same(t, base, baseGitManager(timed))
+		require.NoError(t, timed.Push(ctx, "origin", "feature"))
+		assert.Equal(t, []string{"origin/feature"}, base.pushes)
+		contained, err := timed.IsAncestor(ctx, "base", "HEAD")
+		require.NoError(t, err)
+		assert.True(t, contained)
+		tree, err := timed.WorkingTreeHash(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "tree", tree)
+		worktree, err := timed.AddWorktree(ctx, "/tmp/w")
+		require.NoError(t, err)
+		assert.NotNil(t, worktree)
+		require.NoError(t, timed.RemoveWorktree(ctx, "/tmp/w"))
+		picked, err := timed.CherryPick(ctx, "base", "tip")
+		require.NoError(t, err)
+		assert.Equal(t, "picked", picked)
+		assert.Contains(t, p.current.Phases, PhaseGit)
+	})
+
+	t.Run("unsupported methods fail and are not reported as supported", func(t *testing.T) {
+		timed := &timedGitManager{Manager: &mockGitManager{}, profiler: &profiler{}}
+
+		_, ok := baseGitManager(timed).(pusher)
+		assert.False(t, ok)
+		assert.Error(t, timed.Push(ctx, "origin", "feature"))
+		_, err := timed.IsAncestor(ctx, "a", "b")
+		assert.Error(t, err)
+		_, err = timed.WorkingTreeHash(ctx)
+		assert.Error(t, err)
+		_, err = timed.AddWorktree(ctx, "/tmp/w")
+		assert.Error(t, err)
+		assert.Error(t, timed.RemoveWorktree(ctx, "/tmp/w"))
+		_, err = timed.CherryPick(ctx, "base", "tip")
+		assert.Error(t, err)
+	})
+}
+
+// dirMockVerifier is a mockVerifier that can run in another directory.
+type dirMockVerifier struct {
+	mockVerifier
+	dir   string
+	codes verifier.ExitCodes
+}
+
+func (v *dirMockVerifier) InDir(dir string) verifier.Verifier {
+	return &dirMockVerifier{dir: dir}
+}
+
+func (v *dirMockVerifier) WithExitCodes(codes verifier.ExitCodes) verifier.Verifier {
+	return &dirMockVerifier{dir: v.dir, codes: codes}
+}
+
+func (v *dirMockVerifier) Fingerprint() string {
+	return "settings"
+}
+
+func TestTimedVerifier_ForwardsOptionalMethods(t *testing.T) {
+	t.Run("supported by the wrapped verifier", func(t *testing.T) {
+		base := &dirMockVerifier{}
+		p := &profiler{}
+		timed := &timedVerifier{Verifier: base, profiler: p}
+
+		assert.Same(t, base, baseVerifier(timed))
+		assert.Equal(t, "settings", timed.Fingerprint())
+		moved, ok := timed.InDir("/tmp/w").(*timedVerifier)
+		require.True(t, ok, "the copy is still timed")
+		assert.Same(t, p, moved.profiler)
+		assert.Equal(t, "/tmp/w", moved.Verifier.(*dirMockVerifier).dir)
+		codes := verifier.ExitCodes{}
+		codes.Set([]string{"lint"}, map[int]verifier.ExitCodeResult{2: verifier.ExitCodeWarn})
+		mapped, ok := timed.WithExitCodes(codes).(*timedVerifier)
+		require.True(t, ok, "the copy is still timed")
+		assert.Same(t, p, mapped.profiler)
+		assert.Equal(t, codes, mapped.Verifier.(*dirMockVerifier).codes)
+	})
+
+	t.Run("unsupported methods are not reported as supported", func(t *testing.T) {
+		timed := &timedVerifier{Verifier: &mockVerifier{}, profiler: &profiler{}}
+
+		_, ok := baseVerifier(timed).(dirVerifier)
+		assert.False(t, ok)
+		assert.Empty(t, timed.Fingerprint())
+		assert.Same(t, timed, timed.InDir("/tmp/w"))
+		assert.Same(t, timed, timed.WithExitCodes(verifier.ExitCodes{}))
+	})
+}
+
+func TestController_NewWorker_TimesWithItsOwnProfiler(t *testing.T) {
+	ctrl := NewController(ControllerDeps{
+		Claude:   &mockClaudeRunner{},
+		Verifier: &dirMockVerifier{},
+		Git:      &worktreeGitManager{mockGitManager: &mockGitManager{}},
+		LogsDir:  t.TempDir(),
+	})
+	ctrl.SetProfile(true)
+
+	task := newTestTask("task-a", "Task A", taskstore.StatusOpen, nil)
+	worktree := &mockGitManager{}
+	w := ctrl.newWorker(task, "/tmp/w", worktree, &sync.Mutex{}, &sync.Mutex{})
+
+	require.NotSame(t, ctrl.profiler, w.profiler)
+	runner, ok := w.claudeRunner.(*timedRunner)
+	require.True(t, ok)
+	assert.Same(t, w.profiler, runner.profiler)
+	assert.IsType(t, &mockClaudeRunner{}, runner.Runner, "wrapped once")
+	v, ok := w.verifier.(*timedVerifier)
+	require.True(t, ok)
+	assert.Same(t, w.profiler, v.profiler)
+	assert.Equal(t, "/tmp/w", v.Verifier.(*dirMockVerifier).dir)
+	g, ok := w.gitManager.(*timedGitManager)
+	require.True(t, ok)
+	assert.Same(t, w.profiler, g.profiler)
+	assert.Same(t, worktree, g.Manager)
+}
+
+func TestSumProfiles(t *testing.T) {
+	profiles := []IterationProfile{
+		{Total: 10 * time.Second, Phases: map[Phase]time.Duration{PhaseAgent: 6 * time.Second, PhaseGit: time.Second}},
+		{Total: 5 * time.Second, Phases: map[Phase]time.Duration{PhaseAgent: 2 * time.Second, PhaseVerification: 2 * time.Second}},
+	}
+
+	sum := SumProfiles(profiles)
+
+	assert.Equal(t, 15*time.Second, sum.Total)
+	assert.Equal(t, 8*time.Second, sum.Phases[PhaseAgent])
+	assert.Equal(t, 2*time.Second, sum.Phases[PhaseVerification])
+	assert.Equal(t, time.Second, sum.Phases[PhaseGit])
+	assert.Equal(t, 4*time.Second, sum.Other())
+}
+
+func TestFormatProfile(t *testing.T) {
+	profile := IterationProfile{
+		Total: 3 * time.Second,
+		Phases: map[Phase]time.Duration{
+			PhasePrompt:       10 * time.Millisecond,
+			PhaseAgent:        2 * time.Second,
+			PhaseVerification: 500 * time.Millisecond,
+			PhaseGit:          90 * time.Millisecond,
+		},
+	}
+
+	assert.Equal(t, "prompt 10ms · agent 2s · verification 500ms · git 90ms · other 400ms", FormatProfile(profile))
+}
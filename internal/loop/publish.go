@@ -0,0 +1,118 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// pusher is a git manager that can push a branch to a remote.
+type pusher interface {
+	Push(ctx context.Context, remote, branch string) error
+}
+
+// PushConfig controls publishing the feature branch once a run completes.
+type PushConfig struct {
+	// PushOnComplete pushes the feature branch when every task completed.
+	PushOnComplete bool
+
+	// Remote is the remote pushed to (default "origin").
+	Remote string
+
+	// PRCommand, if set, runs after a successful push to open a pull
+	// request, e.g. ["gh", "pr", "create", "--fill", "--head", "{branch}"].
+	// {branch}, {remote} and {title} (the parent task's title) are replaced
+	// in each argument. The last line it prints is reported as the PR link.
+	PRCommand []string
+}
+
+// SetPush sets whether and how the feature branch is published when a run
+// completes. Blocked, paused, failed and over-budget runs are never pushed.
+func (c *Controller) SetPush(cfg PushConfig) {
+	if cfg.Remote == "" {
+		cfg.Remote = "origin"
+	}
+	c.push = cfg
+}
+
+// publishRun pushes the feature branch of a completed run and opens a pull
+// request for it, adding what happened to the result message. Failures are
+// reported there too; the tasks themselves stay completed.
+func (c *Controller) publishRun(ctx context.Context, parentTaskID string, result *RunResult) {
+	if !c.push.PushOnComplete || result.Outcome != RunOutcomeCompleted {
+		return
+	}
+	if _, ok := baseGitManager(c.gitManager).(pusher); !ok {
+		appendMessage(result, "push skipped: the git manager cannot push")
+		return
+	}
+	p := c.gitManager.(pusher)
+
+	branch, err := c.gitManager.GetCurrentBranch(ctx)
+	if err != nil {
+		appendMessage(result, fmt.Sprintf("push failed: %v", err))
+		return
+	}
+	if err := p.Push(ctx, c.push.Remote, branch); err != nil {
+		appendMessage(result, fmt.Sprintf("push of %s to %s failed: %v", branch, c.push.Remote, err))
+		return
+	}
+	c.writeProgress("⇪ Pushed %s to %s\n", branch, c.push.Remote)
+	appendMessage(result, fmt.Sprintf("pushed %s to %s", branch, c.push.Remote))
+
+	if len(c.push.PRCommand) == 0 {
+		return
+	}
+	title := parentTaskID
+	if parent, err := c.taskStore.Get(parentTaskID); err == nil {
+		title = parent.Title
+	}
+	link, err := c.openPullRequest(ctx, branch, title)
+	if err != nil {
+		appendMessage(result, fmt.Sprintf("opening a pull request failed: %v", err))
+		return
+	}
+	if link == "" {
+		appendMessage(result, "opened a pull request")
+		return
+	}
+	c.writeProgress("🔗 Pull request: %s\n", link)
+	result.PullRequestURL = link
+	appendMessage(result, "pull request: "+link)
+}
+
+// openPullRequest runs the PR command for branch and returns the last line
+// it printed, if any.
+func (c *Controller) openPullRequest(ctx context.Context, branch, title string) (string, error) {
+	replacer := strings.NewReplacer("{branch}", branch, "{remote}", c.push.Remote, "{title}", title)
+	args := make([]string, len(c.push.PRCommand))
+	for i, arg := range c.push.PRCommand {
+		args[i] = replacer.Replace(arg)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = c.codeDir()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+
+	output := strings.TrimSpace(stdout.String())
+	return strings.TrimSpace(output[strings.LastIndex(output, "\n")+1:]), nil
+}
+
+// appendMessage adds note to the result message.
+func appendMessage(result *RunResult, note string) {
+	if result.Message == "" {
+		result.Message = note
+		return
+	}
+	result.Message += "; " + note
+}
@@ -0,0 +1,117 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// pushingGitManager is a mock git manager that records pushes.
+type pushingGitManager struct {
+	*mockGitManager
+	pushes  []string
+	pushErr error
+}
+
+func (m *pushingGitManager) Push(ctx context.Context, remote, branch string) error {
+	m.pushes = append(m.pushes, remote+" "+branch)
+	return m.pushErr
+}
+
+func TestController_RunLoop_PushOnComplete(t *testing.T) {
+	prCommand := []string{"sh", "-c", "echo creating {title}; echo https://example.com/{remote}/{branch}/pull/1"}
+
+	tests := []struct {
+		name        string
+		push        PushConfig
+		setup       func(c *Controller, store *mockTaskStore)
+		pushErr     error
+		wantPushes  []string
+		wantMessage string
+		wantPR      string
+	}{
+		{
+			name:        "pushes and opens a pull request",
+			push:        PushConfig{PushOnComplete: true, PRCommand: prCommand},
+			wantPushes:  []string{"origin ralph/parent"},
+			wantMessage: "all tasks completed; pushed ralph/parent to origin; pull request: https://example.com/origin/ralph/parent/pull/1",
+			wantPR:      "https://example.com/origin/ralph/parent/pull/1",
+		},
+		{
+			name:        "pushes to the configured remote",
+			push:        PushConfig{PushOnComplete: true, Remote: "upstream"},
+			wantPushes:  []string{"upstream ralph/parent"},
+			wantMessage: "all tasks completed; pushed ralph/parent to upstream",
+		},
+		{
+			name:        "pushes a per_run commit",
+			push:        PushConfig{PushOnComplete: true},
+			setup:       func(c *Controller, store *mockTaskStore) { _ = c.SetCommitMode(CommitPerRun) },
+			wantPushes:  []string{"origin ralph/parent"},
+			wantMessage: "all tasks completed; pushed ralph/parent to origin",
+		},
+		{
+			name:        "reports a failed push",
+			push:        PushConfig{PushOnComplete: true, PRCommand: prCommand},
+			pushErr:     errors.New("rejected"),
+			wantPushes:  []string{"origin ralph/parent"},
+			wantMessage: "all tasks completed; push of ralph/parent to origin failed: rejected",
+		},
+		{
+			name:        "reports a failed pull request command",
+			push:        PushConfig{PushOnComplete: true, PRCommand: []string{"sh", "-c", "echo already exists >&2; exit 1"}},
+			wantPushes:  []string{"origin ralph/parent"},
+			wantMessage: "all tasks completed; pushed ralph/parent to origin; opening a pull request failed: exit status 1: already exists",
+		},
+		{
+			name:        "not when disabled",
+			push:        PushConfig{PRCommand: prCommand},
+			wantMessage: "all tasks completed",
+		},
+		{
+			name: "not when the run is blocked",
+			push: PushConfig{PushOnComplete: true, PRCommand: prCommand},
+			setup: func(c *Controller, store *mockTaskStore) {
+				store.addTask(newTestTask("task-failed", "Failed", taskstore.StatusFailed, strPtr("parent")))
+				c.SetRequireAllCompleted(true)
+			},
+			wantMessage: "no ready tasks available (not completed: task-failed)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+			store.addTask(newTestTask("task-1", "Task 1", taskstore.StatusOpen, strPtr("parent")))
+			gitManager := &pushingGitManager{
+				mockGitManager: &mockGitManager{currentCommit: "abc", currentBranch: "ralph/parent", hasChanges: true, changedFiles: []string{"f.go"}, commitHash: "def"},
+				pushErr:        tt.pushErr,
+			}
+			c := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"}},
+				Verifier:  &mockVerifier{results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}}},
+				Git:       gitManager,
+				LogsDir:   t.TempDir(),
+				WorkDir:   t.TempDir(),
+			})
+			c.SetPush(tt.push)
+			if tt.setup != nil {
+				tt.setup(c, store)
+			}
+
+			result := c.RunLoop(context.Background(), "parent")
+
+			assert.Equal(t, tt.wantPushes, gitManager.pushes)
+			assert.Equal(t, tt.wantMessage, result.Message)
+			assert.Equal(t, tt.wantPR, result.PullRequestURL)
+		})
+	}
+}
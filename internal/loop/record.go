@@ -7,10 +7,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/yarlson/ralph/internal/atomicfile"
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/verifier"
 )
 
 // IterationOutcome represents the result of an iteration.
@@ -25,6 +30,9 @@ const (
 	OutcomeBudgetExceeded IterationOutcome = "budget_exceeded"
 	// OutcomeBlocked indicates the iteration was blocked (e.g., no ready tasks).
 	OutcomeBlocked IterationOutcome = "blocked"
+	// OutcomeCheckpoint indicates the iteration committed progress on a task
+	// that is not finished; the task stays open.
+	OutcomeCheckpoint IterationOutcome = "checkpoint"
 )
 
 // validOutcomes is a set of valid iteration outcomes for validation.
@@ -33,6 +41,7 @@ var validOutcomes = map[IterationOutcome]bool{
 	OutcomeFailed:         true,
 	OutcomeBudgetExceeded: true,
 	OutcomeBlocked:        true,
+	OutcomeCheckpoint:     true,
 }
 
 // IsValid returns true if the outcome is a valid value.
@@ -49,6 +58,13 @@ type IterationRecord struct {
 	// TaskID is the ID of the task being executed in this iteration.
 	TaskID string `json:"task_id"`
 
+	// SessionID identifies the run (one start until it stops or pauses)
+	// this iteration belonged to.
+	SessionID string `json:"session_id,omitempty"`
+
+	// ParentTaskID is the parent task the run was working through.
+	ParentTaskID string `json:"parent_task_id,omitempty"`
+
 	// StartTime is when the iteration started.
 	StartTime time.Time `json:"start_time"`
 
@@ -64,12 +80,43 @@ type IterationRecord struct {
 	// ResultCommit is the git commit hash after successful completion.
 	ResultCommit string `json:"result_commit,omitempty"`
 
+	// CheckpointCommits lists intermediate commits made when the agent reported
+	// a completed sub-goal (experimental checkpoints).
+	CheckpointCommits []string `json:"checkpoint_commits,omitempty"`
+
+	// Plan is the implementation plan from the planning step, if it ran.
+	Plan string `json:"plan,omitempty"`
+
+	// AutoFormatted is true if the configured format command ran on the
+	// agent's changes before verification.
+	AutoFormatted bool `json:"auto_formatted,omitempty"`
+
+	// Annotations is caller-supplied metadata (e.g. a CI build number) from --annotate.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Unverified is true when the task was completed without running any verify
+	// command because it has none.
+	Unverified bool `json:"unverified,omitempty"`
+
+	// OutOfScopeFiles are changed files outside the task's allowed paths,
+	// recorded when violations only warn.
+	OutOfScopeFiles []string `json:"out_of_scope_files,omitempty"`
+
+	// VerificationPassedOnAttempt is the in-iteration attempt on which
+	// verification passed (1 = the agent's first try, 0 = did not pass or
+	// nothing ran).
+	VerificationPassedOnAttempt int `json:"verification_passed_on_attempt,omitempty"`
+
 	// VerificationOutputs contains the results of verification commands.
 	VerificationOutputs []VerificationOutput `json:"verification_outputs,omitempty"`
 
 	// FilesChanged lists the files modified during this iteration.
 	FilesChanged []string `json:"files_changed,omitempty"`
 
+	// FileChanges records whether each changed file was added, modified,
+	// deleted, or renamed (empty in records written before it was tracked).
+	FileChanges []git.FileChange `json:"file_changes,omitempty"`
+
 	// Outcome is the final result of the iteration.
 	Outcome IterationOutcome `json:"outcome"`
 
@@ -80,6 +127,25 @@ type IterationRecord struct {
 	AttemptNumber int `json:"attempt_number,omitempty"`
 }
 
+// AddFileChanges merges changes into FileChanges and FilesChanged. A file
+// keeps the status it was first recorded with, so a file created before a
+// checkpoint commit still reads as added when it is edited again.
+func (r *IterationRecord) AddFileChanges(changes []git.FileChange) {
+	seen := make(map[string]bool, len(r.FileChanges))
+	for _, c := range r.FileChanges {
+		seen[c.Path] = true
+	}
+	files := make([]string, 0, len(changes))
+	for _, c := range changes {
+		files = append(files, c.Path)
+		if !seen[c.Path] {
+			seen[c.Path] = true
+			r.FileChanges = append(r.FileChanges, c)
+		}
+	}
+	r.FilesChanged = mergeFileLists(r.FilesChanged, files)
+}
+
 // ClaudeInvocationMeta contains metadata about a Claude Code invocation.
 type ClaudeInvocationMeta struct {
 	// Command is the CLI command that was executed.
@@ -88,9 +154,16 @@ type ClaudeInvocationMeta struct {
 	// Model is the Claude model used (e.g., "claude-3-sonnet").
 	Model string `json:"model,omitempty"`
 
-	// SessionID is the Claude session identifier.
+	// SessionID is the Claude session identifier of the initial invocation.
 	SessionID string `json:"session_id,omitempty"`
 
+	// SessionIDs is the chain of session IDs reported by the initial invocation
+	// and each continued retry, in order.
+	SessionIDs []string `json:"session_ids,omitempty"`
+
+	// Continued indicates whether any retry continued the initial session.
+	Continued bool `json:"continued,omitempty"`
+
 	// TotalCostUSD is the cost of this invocation for budget tracking.
 	TotalCostUSD float64 `json:"total_cost_usd,omitempty"`
 
@@ -101,6 +174,13 @@ type ClaudeInvocationMeta struct {
 	OutputTokens int `json:"output_tokens,omitempty"`
 }
 
+// appendSession adds a session ID to the chain, skipping empty IDs.
+func (m *ClaudeInvocationMeta) appendSession(sessionID string) {
+	if sessionID != "" {
+		m.SessionIDs = append(m.SessionIDs, sessionID)
+	}
+}
+
 // VerificationOutput contains the result of a single verification command.
 type VerificationOutput struct {
 	// Command is the verification command that was executed.
@@ -114,6 +194,79 @@ type VerificationOutput struct {
 
 	// Duration is how long the command took to execute.
 	Duration time.Duration `json:"duration,omitempty"`
+
+	// Warning indicates the command passed with an exit code mapped to warn.
+	Warning bool `json:"warning,omitempty"`
+
+	// Skipped indicates the command passed with an exit code mapped to skip.
+	Skipped bool `json:"skipped,omitempty"`
+
+	// Format is how Output is structured. Empty means raw.
+	Format verifier.OutputFormat `json:"format,omitempty"`
+
+	// Tests holds test-level results parsed from Output when Format is recognized.
+	Tests []verifier.TestCase `json:"tests,omitempty"`
+}
+
+// NewVerificationOutput converts a verification result into a record entry,
+// parsing test-level detail when the output format is recognized.
+func NewVerificationOutput(result verifier.VerificationResult) VerificationOutput {
+	vo := VerificationOutput{
+		Command:  result.Command,
+		Passed:   result.Passed,
+		Output:   result.Output,
+		Duration: result.Duration,
+		Warning:  result.Warning,
+		Skipped:  result.Skipped,
+	}
+
+	format := verifier.DetectFormat(result.Command, result.Output)
+	if format == verifier.FormatRaw {
+		return vo
+	}
+
+	// Fall back to raw if the output doesn't actually parse
+	tests, err := verifier.ParseTestCases(format, result.Output)
+	if err != nil {
+		return vo
+	}
+	vo.Format = format
+	vo.Tests = tests
+	return vo
+}
+
+// verificationStatus labels a verify command's result for logs and progress.
+func verificationStatus(passed, warning, skipped bool) string {
+	switch {
+	case !passed:
+		return "FAIL"
+	case warning:
+		return "WARN"
+	case skipped:
+		return "SKIP"
+	default:
+		return "PASS"
+	}
+}
+
+// Status labels the command's result: PASS, FAIL, WARN or SKIP.
+func (o VerificationOutput) Status() string {
+	return verificationStatus(o.Passed, o.Warning, o.Skipped)
+}
+
+// FailedTests returns the names of failed tests parsed from structured output.
+func (o VerificationOutput) FailedTests() []string {
+	var failed []string
+	for _, tc := range o.Tests {
+		if tc.Status == verifier.TestFailed {
+			name := tc.Name
+			if tc.Package != "" {
+				name = tc.Package + "." + tc.Name
+			}
+			failed = append(failed, name)
+		}
+	}
+	return failed
 }
 
 // NewIterationRecord creates a new iteration record for the given task.
@@ -160,6 +313,11 @@ func GenerateIterationID() string {
 	return uuid.New().String()[:8]
 }
 
+// GenerateSessionID generates a unique session ID.
+func GenerateSessionID() string {
+	return uuid.New().String()[:8]
+}
+
 // GenerateTextLog generates a human-readable text summary of an iteration record.
 func GenerateTextLog(record *IterationRecord) string {
 	if record == nil {
@@ -171,6 +329,12 @@ func GenerateTextLog(record *IterationRecord) string {
 	// Header
 	sb.WriteString(fmt.Sprintf("Iteration: %s\n", record.IterationID))
 	sb.WriteString(fmt.Sprintf("Task: %s\n", record.TaskID))
+	if record.SessionID != "" {
+		sb.WriteString(fmt.Sprintf("Session: %s\n", record.SessionID))
+	}
+	if record.ParentTaskID != "" {
+		sb.WriteString(fmt.Sprintf("Parent Task: %s\n", record.ParentTaskID))
+	}
 
 	// Timing
 	if !record.StartTime.IsZero() {
@@ -193,12 +357,43 @@ func GenerateTextLog(record *IterationRecord) string {
 	if record.ResultCommit != "" {
 		sb.WriteString(fmt.Sprintf("Commit: %s\n", record.ResultCommit))
 	}
+	if len(record.CheckpointCommits) > 0 {
+		sb.WriteString(fmt.Sprintf("Checkpoints: %s\n", strings.Join(record.CheckpointCommits, ", ")))
+	}
+	if record.AutoFormatted {
+		sb.WriteString("Auto-formatted: yes\n")
+	}
+	if record.Unverified {
+		sb.WriteString("Verification: none (task has no verify commands)\n")
+	}
+	if len(record.OutOfScopeFiles) > 0 {
+		sb.WriteString(fmt.Sprintf("Out of Scope Files: %s\n", strings.Join(record.OutOfScopeFiles, ", ")))
+	}
+	if record.VerificationPassedOnAttempt > 0 {
+		sb.WriteString(fmt.Sprintf("Verification Passed On Attempt: %d\n", record.VerificationPassedOnAttempt))
+	}
+	if len(record.Annotations) > 0 {
+		sb.WriteString(fmt.Sprintf("Annotations: %s\n", FormatAnnotations(record.Annotations)))
+	}
+
+	if record.Plan != "" {
+		sb.WriteString("\nPlan:\n")
+		sb.WriteString(indentLines(record.Plan, "  ") + "\n")
+	}
 
 	// Files changed
 	if len(record.FilesChanged) > 0 {
 		sb.WriteString("\nFiles Changed:\n")
+		status := make(map[string]git.FileStatus, len(record.FileChanges))
+		for _, c := range record.FileChanges {
+			status[c.Path] = c.Status
+		}
 		for _, file := range record.FilesChanged {
-			sb.WriteString(fmt.Sprintf("  - %s\n", file))
+			if s, ok := status[file]; ok {
+				sb.WriteString(fmt.Sprintf("  - %s (%s)\n", file, s))
+			} else {
+				sb.WriteString(fmt.Sprintf("  - %s\n", file))
+			}
 		}
 	}
 
@@ -206,15 +401,15 @@ func GenerateTextLog(record *IterationRecord) string {
 	if len(record.VerificationOutputs) > 0 {
 		sb.WriteString("\nVerification Results:\n")
 		for _, vo := range record.VerificationOutputs {
-			status := "PASS"
-			if !vo.Passed {
-				status = "FAIL"
-			}
+			status := verificationStatus(vo.Passed, vo.Warning, vo.Skipped)
 			cmdStr := strings.Join(vo.Command, " ")
 			sb.WriteString(fmt.Sprintf("  - %s - %s\n", cmdStr, status))
 			if vo.Duration > 0 {
 				sb.WriteString(fmt.Sprintf("    Duration: %s\n", vo.Duration))
 			}
+			if failed := vo.FailedTests(); len(failed) > 0 {
+				sb.WriteString(fmt.Sprintf("    Failed tests: %s\n", strings.Join(failed, ", ")))
+			}
 		}
 	}
 
@@ -227,6 +422,9 @@ func GenerateTextLog(record *IterationRecord) string {
 		if record.ClaudeInvocation.SessionID != "" {
 			sb.WriteString(fmt.Sprintf("  Session ID: %s\n", record.ClaudeInvocation.SessionID))
 		}
+		if record.ClaudeInvocation.Continued {
+			sb.WriteString(fmt.Sprintf("  Session Chain: %s\n", strings.Join(record.ClaudeInvocation.SessionIDs, " -> ")))
+		}
 		if record.ClaudeInvocation.TotalCostUSD > 0 {
 			sb.WriteString(fmt.Sprintf("  Cost: $%.4f\n", record.ClaudeInvocation.TotalCostUSD))
 		}
@@ -276,8 +474,8 @@ func SaveRecord(logsDir string, record *IterationRecord) (string, error) {
 		return "", fmt.Errorf("failed to marshal record: %w", err)
 	}
 
-	// Write JSON file
-	if err := os.WriteFile(jsonPath, data, 0644); err != nil {
+	// Write JSON file atomically so a crash never leaves a half-written record
+	if err := atomicfile.WriteFile(jsonPath, data); err != nil {
 		return "", fmt.Errorf("failed to write record: %w", err)
 	}
 
@@ -308,7 +506,17 @@ func LoadRecord(path string) (*IterationRecord, error) {
 }
 
 // LoadAllIterationRecords loads all iteration records from the logs directory.
+// Records that cannot be parsed (e.g., truncated by a crash mid-write) are skipped
+// with a warning on stderr. Use FindCorruptRecords to inspect them.
 func LoadAllIterationRecords(logsDir string) ([]*IterationRecord, error) {
+	return loadIterationRecords(logsDir, func(path string, err error) {
+		fmt.Fprintf(os.Stderr, "warning: skipping corrupt iteration record %s: %v\n", path, err)
+	})
+}
+
+// loadIterationRecords loads all iteration records from the logs directory,
+// passing each record that cannot be parsed to skipped.
+func loadIterationRecords(logsDir string, skipped func(path string, err error)) ([]*IterationRecord, error) {
 	entries, err := os.ReadDir(logsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -319,20 +527,15 @@ func LoadAllIterationRecords(logsDir string) ([]*IterationRecord, error) {
 
 	var records []*IterationRecord
 	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		// Only process iteration JSON files
-		name := entry.Name()
-		if len(name) < 10 || name[:10] != "iteration-" || filepath.Ext(name) != ".json" {
+		if entry.IsDir() || !isIterationRecordFile(entry.Name()) {
 			continue
 		}
 
-		path := filepath.Join(logsDir, name)
+		path := filepath.Join(logsDir, entry.Name())
 		record, err := LoadRecord(path)
 		if err != nil {
-			continue // Skip invalid files
+			skipped(path, err)
+			continue
 		}
 
 		records = append(records, record)
@@ -340,3 +543,96 @@ func LoadAllIterationRecords(logsDir string) ([]*IterationRecord, error) {
 
 	return records, nil
 }
+
+// CorruptRecord describes an iteration record file that could not be loaded.
+type CorruptRecord struct {
+	// Path is the path to the corrupt JSON record.
+	Path string
+
+	// Err is the error encountered while loading the record.
+	Err error
+}
+
+// FindCorruptRecords scans the logs directory for iteration records that fail to load.
+// Returns nil if the directory does not exist.
+func FindCorruptRecords(logsDir string) ([]CorruptRecord, error) {
+	entries, err := os.ReadDir(logsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read logs directory: %w", err)
+	}
+
+	var corrupt []CorruptRecord
+	for _, entry := range entries {
+		if entry.IsDir() || !isIterationRecordFile(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(logsDir, entry.Name())
+		if _, err := LoadRecord(path); err != nil {
+			corrupt = append(corrupt, CorruptRecord{Path: path, Err: err})
+		}
+	}
+
+	return corrupt, nil
+}
+
+// RemoveCorruptRecord deletes a corrupt record's JSON file and its text log sibling.
+func RemoveCorruptRecord(record CorruptRecord) error {
+	if err := os.Remove(record.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove record: %w", err)
+	}
+
+	textPath := strings.TrimSuffix(record.Path, ".json") + ".txt"
+	if err := os.Remove(textPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove text log: %w", err)
+	}
+
+	return nil
+}
+
+// isIterationRecordFile returns true if the filename looks like an iteration JSON record.
+func isIterationRecordFile(name string) bool {
+	return strings.HasPrefix(name, "iteration-") && filepath.Ext(name) == ".json"
+}
+
+// RenameTaskInRecords rewrites the task ID of every iteration record for oldID
+// to newID. Corrupt records are skipped. Returns the number of records updated.
+func RenameTaskInRecords(logsDir, oldID, newID string) (int, error) {
+	records, err := LoadAllIterationRecords(logsDir)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, record := range records {
+		if record.TaskID != oldID {
+			continue
+		}
+		record.TaskID = newID
+		if _, err := SaveRecord(logsDir, record); err != nil {
+			return count, fmt.Errorf("failed to update record %s: %w", record.IterationID, err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// FormatAnnotations renders annotations as comma-separated key=value pairs
+// sorted by key.
+func FormatAnnotations(annotations map[string]string) string {
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+annotations[k])
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -9,6 +9,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/verifier"
 )
 
 func TestIterationOutcome_String(t *testing.T) {
@@ -206,6 +209,43 @@ func TestVerificationOutput_JSONSerialization(t *testing.T) {
 	assert.Equal(t, output.Output, decoded.Output)
 }
 
+func TestNewVerificationOutput(t *testing.T) {
+	t.Run("raw output has no format", func(t *testing.T) {
+		vo := NewVerificationOutput(verifier.VerificationResult{
+			Command: []string{"go", "test", "./..."},
+			Output:  "FAIL: TestSomething",
+		})
+
+		assert.Empty(t, vo.Format)
+		assert.Nil(t, vo.Tests)
+
+		data, err := json.Marshal(vo)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "format")
+	})
+
+	t.Run("go test json output is parsed", func(t *testing.T) {
+		vo := NewVerificationOutput(verifier.VerificationResult{
+			Command: []string{"go", "test", "-json", "./..."},
+			Output:  `{"Action":"fail","Package":"example.com/pkg","Test":"TestB"}`,
+		})
+
+		assert.Equal(t, verifier.FormatGoTestJSON, vo.Format)
+		require.Len(t, vo.Tests, 1)
+		assert.Equal(t, []string{"example.com/pkg.TestB"}, vo.FailedTests())
+	})
+
+	t.Run("unparseable junit falls back to raw", func(t *testing.T) {
+		vo := NewVerificationOutput(verifier.VerificationResult{
+			Command: []string{"npm", "test"},
+			Output:  "<testsuite><testcase",
+		})
+
+		assert.Empty(t, vo.Format)
+		assert.Nil(t, vo.Tests)
+	})
+}
+
 func TestNewIterationRecord(t *testing.T) {
 	record := NewIterationRecord("task-456")
 
@@ -453,6 +493,41 @@ func TestGenerateTextLog(t *testing.T) {
 				"Duration: 20m0s",
 			},
 		},
+		{
+			name: "continued session",
+			record: &IterationRecord{
+				IterationID: "cont1",
+				TaskID:      "task-retry",
+				StartTime:   now,
+				EndTime:     now.Add(3 * time.Minute),
+				Outcome:     OutcomeSuccess,
+				ClaudeInvocation: ClaudeInvocationMeta{
+					SessionID:  "sess-1",
+					SessionIDs: []string{"sess-1", "sess-2"},
+					Continued:  true,
+				},
+			},
+			want: []string{
+				"Session ID: sess-1",
+				"Session Chain: sess-1 -> sess-2",
+			},
+		},
+		{
+			name: "run session and parent",
+			record: &IterationRecord{
+				IterationID:  "run1",
+				TaskID:       "task-a",
+				SessionID:    "session-1",
+				ParentTaskID: "feature",
+				StartTime:    now,
+				EndTime:      now.Add(time.Minute),
+				Outcome:      OutcomeSuccess,
+			},
+			want: []string{
+				"Session: session-1",
+				"Parent Task: feature",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -498,3 +573,152 @@ func TestSaveRecord_CreatesTextLog(t *testing.T) {
 	assert.Contains(t, content, "Task: task-123")
 	assert.Contains(t, content, "Outcome: success")
 }
+
+func TestLoadAllIterationRecords_SkipsTruncatedRecords(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := SaveRecord(dir, &IterationRecord{IterationID: "good", TaskID: "task-1", Outcome: OutcomeSuccess})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "iteration-bad.json"), []byte(`{"iteration_id": "bad", "task_`), 0644))
+
+	records, err := LoadAllIterationRecords(dir)
+	require.NoError(t, err)
+
+	require.Len(t, records, 1)
+	assert.Equal(t, "good", records[0].IterationID)
+}
+
+func TestFindCorruptRecords(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := SaveRecord(dir, &IterationRecord{IterationID: "good", TaskID: "task-1", Outcome: OutcomeSuccess})
+	require.NoError(t, err)
+	badPath := filepath.Join(dir, "iteration-bad.json")
+	require.NoError(t, os.WriteFile(badPath, []byte(`{"iteration_id": "ba`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.json"), []byte("not json"), 0644))
+
+	corrupt, err := FindCorruptRecords(dir)
+	require.NoError(t, err)
+
+	require.Len(t, corrupt, 1)
+	assert.Equal(t, badPath, corrupt[0].Path)
+	assert.Error(t, corrupt[0].Err)
+}
+
+func TestFindCorruptRecords_MissingDir(t *testing.T) {
+	corrupt, err := FindCorruptRecords(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, corrupt)
+}
+
+func TestRemoveCorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "iteration-bad.json")
+	textPath := filepath.Join(dir, "iteration-bad.txt")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{`), 0644))
+	require.NoError(t, os.WriteFile(textPath, []byte("Iteration: bad\n"), 0644))
+
+	require.NoError(t, RemoveCorruptRecord(CorruptRecord{Path: jsonPath}))
+
+	assert.NoFileExists(t, jsonPath)
+	assert.NoFileExists(t, textPath)
+}
+
+func TestSaveRecord_FailedWriteLeavesExistingRecordIntact(t *testing.T) {
+	dir := t.TempDir()
+	original := &IterationRecord{IterationID: "iter-atomic", TaskID: "task-1", Outcome: OutcomeFailed}
+	path, err := SaveRecord(dir, original)
+	require.NoError(t, err)
+
+	// Block the temp file path so the next write fails mid-save
+	require.NoError(t, os.Mkdir(path+".tmp", 0755))
+
+	updated := &IterationRecord{IterationID: "iter-atomic", TaskID: "task-1", Outcome: OutcomeSuccess}
+	_, err = SaveRecord(dir, updated)
+	require.Error(t, err)
+
+	loaded, err := LoadRecord(path)
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeFailed, loaded.Outcome)
+}
+
+func TestRenameTaskInRecords(t *testing.T) {
+	dir := t.TempDir()
+	for _, r := range []*IterationRecord{
+		{IterationID: "iter-1", TaskID: "old", Outcome: OutcomeFailed},
+		{IterationID: "iter-2", TaskID: "old", Outcome: OutcomeSuccess},
+		{IterationID: "iter-3", TaskID: "other", Outcome: OutcomeSuccess},
+	} {
+		_, err := SaveRecord(dir, r)
+		require.NoError(t, err)
+	}
+
+	count, err := RenameTaskInRecords(dir, "old", "new")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	records, err := LoadAllIterationRecords(dir)
+	require.NoError(t, err)
+	byIteration := make(map[string]string)
+	for _, r := range records {
+		byIteration[r.IterationID] = r.TaskID
+	}
+	assert.Equal(t, map[string]string{"iter-1": "new", "iter-2": "new", "iter-3": "other"}, byIteration)
+}
+
+func TestIterationRecord_Annotations(t *testing.T) {
+	t.Run("round-trips through JSON", func(t *testing.T) {
+		record := NewIterationRecord("task-1")
+		record.Annotations = map[string]string{"build": "1234"}
+
+		data, err := json.Marshal(record)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"annotations":{"build":"1234"}`)
+
+		var decoded IterationRecord
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, record.Annotations, decoded.Annotations)
+	})
+
+	t.Run("omitted from JSON when empty", func(t *testing.T) {
+		data, err := json.Marshal(NewIterationRecord("task-1"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "annotations")
+	})
+
+	t.Run("listed in text log sorted by key", func(t *testing.T) {
+		record := NewIterationRecord("task-1")
+		record.Annotations = map[string]string{"pr": "42", "build": "1234"}
+		assert.Contains(t, GenerateTextLog(record), "Annotations: build=1234, pr=42\n")
+	})
+}
+
+func TestIterationRecord_AddFileChanges(t *testing.T) {
+	record := NewIterationRecord("task")
+
+	record.AddFileChanges([]git.FileChange{
+		{Path: "new.go", Status: git.FileAdded},
+		{Path: "main.go", Status: git.FileModified},
+	})
+	// After a checkpoint commit, the new file reads as modified
+	record.AddFileChanges([]git.FileChange{
+		{Path: "new.go", Status: git.FileModified},
+		{Path: "old.go", Status: git.FileDeleted},
+	})
+
+	assert.Equal(t, []string{"new.go", "main.go", "old.go"}, record.FilesChanged)
+	assert.Equal(t, []git.FileChange{
+		{Path: "new.go", Status: git.FileAdded},
+		{Path: "main.go", Status: git.FileModified},
+		{Path: "old.go", Status: git.FileDeleted},
+	}, record.FileChanges)
+	assert.Contains(t, GenerateTextLog(record), "  - old.go (deleted)\n")
+}
+
+func TestGenerateTextLog_VerificationPassedOnAttempt(t *testing.T) {
+	record := NewIterationRecord("task-1")
+	assert.NotContains(t, GenerateTextLog(record), "Verification Passed On Attempt")
+
+	record.VerificationPassedOnAttempt = 2
+	assert.Contains(t, GenerateTextLog(record), "Verification Passed On Attempt: 2\n")
+}
@@ -0,0 +1,42 @@
+package loop
+
+import (
+	"github.com/yarlson/ralph/internal/redact"
+)
+
+// SetRedactor sets the redactor applied to iteration records and progress
+// notes, so passed-through secret values are never stored. Nil disables it.
+func (c *Controller) SetRedactor(r *redact.Redactor) {
+	c.redactor = r
+}
+
+// redactRecord replaces secret values in the record's free-text fields: the
+// feedback, the plan, and each verification command, output, and test output.
+func (c *Controller) redactRecord(record *IterationRecord) {
+	if c.redactor == nil {
+		return
+	}
+
+	record.Feedback = c.redactor.String(record.Feedback)
+	record.Plan = c.redactor.String(record.Plan)
+	for i := range record.VerificationOutputs {
+		vo := &record.VerificationOutputs[i]
+		vo.Command = c.redactStrings(vo.Command)
+		vo.Output = c.redactor.String(vo.Output)
+		for j := range vo.Tests {
+			vo.Tests[j].Output = c.redactor.String(vo.Tests[j].Output)
+		}
+	}
+}
+
+// redactStrings returns a redacted copy of values.
+func (c *Controller) redactStrings(values []string) []string {
+	if c.redactor == nil || values == nil {
+		return values
+	}
+	redacted := make([]string, len(values))
+	for i, v := range values {
+		redacted[i] = c.redactor.String(v)
+	}
+	return redacted
+}
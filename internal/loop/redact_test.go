@@ -0,0 +1,92 @@
+package loop
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/memory"
+	"github.com/yarlson/ralph/internal/redact"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestController_RedactsRecords(t *testing.T) {
+	const secret = "s3cr3t-value"
+
+	tests := []struct {
+		name    string
+		passes  bool
+		outcome IterationOutcome
+	}{
+		{name: "failed iteration", passes: false, outcome: OutcomeFailed},
+		{name: "successful iteration", passes: true, outcome: OutcomeSuccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+			task := newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent"))
+			task.Verify = [][]string{{"curl", "-H", "token"}}
+			store.addTask(task)
+
+			logsDir := t.TempDir()
+			progressDir := t.TempDir()
+			progressFile := memory.NewProgressFile(filepath.Join(progressDir, "progress.md"))
+			require.NoError(t, progressFile.Init("Feature", "parent"))
+
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude: &mockClaudeRunner{
+					response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Used token " + secret},
+				},
+				Verifier: &mockVerifier{
+					results: []verifier.VerificationResult{{
+						Passed:  tt.passes,
+						Command: []string{"curl", "-H", "token"},
+						Output:  "401 for token " + secret,
+					}},
+				},
+				Git: &mockGitManager{
+					currentCommit: "abc",
+					hasChanges:    true,
+					changedFiles:  []string{"f.go"},
+					commitHash:    "def",
+				},
+				LogsDir:      logsDir,
+				ProgressDir:  progressDir,
+				ProgressFile: progressFile,
+			})
+			ctrl.SetMaxVerificationRetries(0)
+			ctrl.SetRedactor(redact.New(secret))
+
+			result := ctrl.RunOnce(context.Background(), "parent")
+
+			require.Len(t, result.Records, 1)
+			record := result.Records[0]
+			assert.Equal(t, tt.outcome, record.Outcome)
+			require.Len(t, record.VerificationOutputs, 1)
+			assert.Equal(t, "401 for token [REDACTED]", record.VerificationOutputs[0].Output)
+
+			// Nothing written to the logs or progress file contains the secret
+			for _, dir := range []string{logsDir, progressDir} {
+				err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+					if err != nil || d.IsDir() {
+						return err
+					}
+					data, err := os.ReadFile(path)
+					require.NoError(t, err)
+					assert.NotContains(t, string(data), secret, path)
+					return nil
+				})
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package loop
+
+import (
+	"sort"
+	"time"
+)
+
+// RetentionPolicy bounds the iteration records kept in the logs directory.
+// The zero value keeps every record.
+type RetentionPolicy struct {
+	// MaxAge prunes records that ended longer ago than this (0 = no age limit).
+	MaxAge time.Duration
+
+	// MaxRecords prunes the oldest records beyond this count (0 = no limit).
+	MaxRecords int
+
+	// ArchiveDir receives pruned records instead of deleting them ("" = delete).
+	ArchiveDir string
+}
+
+// Enabled reports whether the policy prunes anything.
+func (p RetentionPolicy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxRecords > 0
+}
+
+// PruneRecords removes (or archives) the iteration records in logsDir that
+// are older than policy.MaxAge or beyond policy.MaxRecords, oldest first by
+// EndTime, and returns the pruned records. Each task's latest record and its
+// latest successful record are always kept, since status, retries, undo and
+// completed-work checks read them, so more than MaxRecords may remain.
+func PruneRecords(logsDir string, policy RetentionPolicy, now time.Time) ([]*IterationRecord, error) {
+	if !policy.Enabled() {
+		return nil, nil
+	}
+
+	records, err := LoadAllIterationRecords(logsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first, so the first record seen per task is its latest
+	sort.SliceStable(records, func(i, j int) bool {
+		return recordTime(records[i]).After(recordTime(records[j]))
+	})
+
+	// Each task's latest record and latest successful record are protected
+	protected := make(map[*IterationRecord]bool)
+	latest := make(map[string]bool)
+	latestSuccess := make(map[string]bool)
+	for _, record := range records {
+		if !latest[record.TaskID] {
+			latest[record.TaskID] = true
+			protected[record] = true
+		}
+		if record.Outcome == OutcomeSuccess && !latestSuccess[record.TaskID] {
+			latestSuccess[record.TaskID] = true
+			protected[record] = true
+		}
+	}
+
+	// Protected records take their slots first; the newest others fill the rest
+	slots := policy.MaxRecords - len(protected)
+	var pruned []*IterationRecord
+	for _, record := range records {
+		if protected[record] {
+			continue
+		}
+		expired := policy.MaxAge > 0 && now.Sub(recordTime(record)) > policy.MaxAge
+		overLimit := policy.MaxRecords > 0 && slots <= 0
+		if !expired && !overLimit {
+			slots--
+			continue
+		}
+
+		if policy.ArchiveDir != "" {
+			err = ArchiveRecord(logsDir, policy.ArchiveDir, record.IterationID)
+		} else {
+			err = RemoveRecord(logsDir, record.IterationID)
+		}
+		if err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, record)
+	}
+
+	// Report oldest first, like FindOrphanedRecords
+	sort.SliceStable(pruned, func(i, j int) bool {
+		return recordTime(pruned[i]).Before(recordTime(pruned[j]))
+	})
+	return pruned, nil
+}
+
+// recordTime is when a record's iteration ended, or started if it never
+// completed.
+func recordTime(record *IterationRecord) time.Time {
+	if record.EndTime.IsZero() {
+		return record.StartTime
+	}
+	return record.EndTime
+}
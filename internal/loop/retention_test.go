@@ -0,0 +1,112 @@
+package loop
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneRecords(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	// Task a: an old success then old failures; task b: a recent failure
+	setup := func(t *testing.T) string {
+		logsDir := t.TempDir()
+		for _, r := range []struct {
+			id, task string
+			outcome  IterationOutcome
+			age      time.Duration
+		}{
+			{"a-success", "task-a", OutcomeSuccess, 40 * day},
+			{"a-fail-1", "task-a", OutcomeFailed, 35 * day},
+			{"a-fail-2", "task-a", OutcomeFailed, 31 * day},
+			{"b-fail-1", "task-b", OutcomeFailed, 3 * day},
+			{"b-fail-2", "task-b", OutcomeFailed, 2 * day},
+			{"b-fail-3", "task-b", OutcomeFailed, day},
+		} {
+			record := NewIterationRecord(r.task)
+			record.IterationID = r.id
+			record.StartTime = now.Add(-r.age - time.Minute)
+			record.EndTime = now.Add(-r.age)
+			record.Outcome = r.outcome
+			_, err := SaveRecord(logsDir, record)
+			require.NoError(t, err)
+		}
+		return logsDir
+	}
+
+	remaining := func(t *testing.T, logsDir string) []string {
+		records, err := LoadAllIterationRecords(logsDir)
+		require.NoError(t, err)
+		var ids []string
+		for _, r := range records {
+			ids = append(ids, r.IterationID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	tests := []struct {
+		name       string
+		policy     RetentionPolicy
+		wantPruned []string
+		wantKept   []string
+	}{
+		{
+			name:     "disabled policy keeps everything",
+			wantKept: []string{"a-fail-1", "a-fail-2", "a-success", "b-fail-1", "b-fail-2", "b-fail-3"},
+		},
+		{
+			name:       "prunes by age but keeps each task's latest and latest successful record",
+			policy:     RetentionPolicy{MaxAge: 30 * day},
+			wantPruned: []string{"a-fail-1"},
+			wantKept:   []string{"a-fail-2", "a-success", "b-fail-1", "b-fail-2", "b-fail-3"},
+		},
+		{
+			name:       "prunes beyond the count, oldest first",
+			policy:     RetentionPolicy{MaxRecords: 4},
+			wantPruned: []string{"a-fail-1", "b-fail-1"},
+			wantKept:   []string{"a-fail-2", "a-success", "b-fail-2", "b-fail-3"},
+		},
+		{
+			name:       "protected records may exceed the count",
+			policy:     RetentionPolicy{MaxRecords: 1},
+			wantPruned: []string{"a-fail-1", "b-fail-1", "b-fail-2"},
+			wantKept:   []string{"a-fail-2", "a-success", "b-fail-3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logsDir := setup(t)
+
+			pruned, err := PruneRecords(logsDir, tt.policy, now)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, r := range pruned {
+				ids = append(ids, r.IterationID)
+			}
+			assert.Equal(t, tt.wantPruned, ids)
+			assert.Equal(t, tt.wantKept, remaining(t, logsDir))
+		})
+	}
+
+	t.Run("archives instead of deleting", func(t *testing.T) {
+		logsDir := setup(t)
+		archiveDir := filepath.Join(t.TempDir(), "archive", "logs")
+
+		pruned, err := PruneRecords(logsDir, RetentionPolicy{MaxAge: 30 * day, ArchiveDir: archiveDir}, now)
+		require.NoError(t, err)
+		require.Len(t, pruned, 1)
+
+		assert.FileExists(t, filepath.Join(archiveDir, "iteration-a-fail-1.json"))
+		assert.FileExists(t, filepath.Join(archiveDir, "iteration-a-fail-1.txt"))
+		assert.NoFileExists(t, filepath.Join(logsDir, "iteration-a-fail-1.json"))
+	})
+}
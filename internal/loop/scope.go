@@ -0,0 +1,81 @@
+package loop
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// ScopeMode controls what happens when an iteration changes files outside
+// the task's allowed paths.
+type ScopeMode string
+
+const (
+	// ScopeFail fails the iteration (the default).
+	ScopeFail ScopeMode = "fail"
+	// ScopeWarn reports the files and carries on.
+	ScopeWarn ScopeMode = "warn"
+)
+
+// SetScopeMode sets how changes outside a task's allowed paths are handled.
+// An empty mode fails the iteration.
+func (c *Controller) SetScopeMode(mode ScopeMode) error {
+	switch mode {
+	case "", ScopeFail:
+		c.scopeWarnOnly = false
+		return nil
+	case ScopeWarn:
+		c.scopeWarnOnly = true
+		return nil
+	default:
+		return fmt.Errorf("unknown scope mode %q (want %s or %s)", mode, ScopeFail, ScopeWarn)
+	}
+}
+
+// outOfScopeFiles returns the files that match none of the task's allowed
+// path globs, using the same matching as verifyWhen. Ralph's own state
+// directory is always allowed. A task without allowed paths allows any file.
+func outOfScopeFiles(task *taskstore.Task, files []string) []string {
+	if len(task.AllowedPaths) == 0 {
+		return nil
+	}
+	var outside []string
+	for _, file := range files {
+		if strings.HasPrefix(file, state.RalphDir+"/") {
+			continue
+		}
+		if !slices.ContainsFunc(task.AllowedPaths, func(glob string) bool {
+			return matchesAnyFile(glob, []string{file})
+		}) {
+			outside = append(outside, file)
+		}
+	}
+	return outside
+}
+
+// checkScope checks the iteration's changed files against the task's allowed
+// paths. It reports whether the iteration may continue: violations fail the
+// iteration with feedback, or in warn mode are recorded on the record and
+// shown in the progress output.
+func (c *Controller) checkScope(task *taskstore.Task, record *IterationRecord, files []string) bool {
+	outside := outOfScopeFiles(task, files)
+	if len(outside) == 0 {
+		return true
+	}
+	if c.scopeWarnOnly {
+		for _, f := range outside {
+			if !slices.Contains(record.OutOfScopeFiles, f) {
+				record.OutOfScopeFiles = append(record.OutOfScopeFiles, f)
+			}
+		}
+		c.writeProgress("  ⚠ Modified files outside allowed scope: %s\n", strings.Join(outside, ", "))
+		return true
+	}
+	record.Complete(OutcomeFailed)
+	record.SetFeedback(fmt.Sprintf("modified files outside allowed scope: %s", strings.Join(outside, ", ")))
+	c.handleTaskFailure(task.ID, record)
+	return false
+}
@@ -0,0 +1,88 @@
+package loop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestOutOfScopeFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		files   []string
+		want    []string
+	}{
+		{name: "no allowed paths allows everything", allowed: nil, files: []string{"main.go"}, want: nil},
+		{name: "files under an allowed directory", allowed: []string{"internal/foo/**"}, files: []string{"internal/foo/a.go", "internal/foo/bar/b.go"}, want: nil},
+		{name: "file outside the allowed directory", allowed: []string{"internal/foo/**"}, files: []string{"internal/foo/a.go", "internal/bar/b.go"}, want: []string{"internal/bar/b.go"}},
+		{name: "any of several globs", allowed: []string{"internal/foo/**", "*.md"}, files: []string{"docs/guide.md", "go.mod"}, want: []string{"go.mod"}},
+		{name: "ralph state is always allowed", allowed: []string{"internal/foo/**"}, files: []string{".ralph/progress.md"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &taskstore.Task{ID: "task-1", AllowedPaths: tt.allowed}
+			assert.Equal(t, tt.want, outOfScopeFiles(task, tt.files))
+		})
+	}
+}
+
+func TestController_SetScopeMode(t *testing.T) {
+	ctrl := NewController(ControllerDeps{})
+
+	require.NoError(t, ctrl.SetScopeMode(ScopeWarn))
+	assert.True(t, ctrl.scopeWarnOnly)
+	require.NoError(t, ctrl.SetScopeMode(""))
+	assert.False(t, ctrl.scopeWarnOnly)
+
+	err := ctrl.SetScopeMode("ignore")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ignore")
+}
+
+func TestController_RunIteration_AllowedPaths(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         ScopeMode
+		wantOutcome  IterationOutcome
+		wantFeedback string
+		wantOutside  []string
+	}{
+		{name: "fails by default", mode: "", wantOutcome: OutcomeFailed, wantFeedback: "modified files outside allowed scope: go.mod"},
+		{name: "warn mode records the files", mode: ScopeWarn, wantOutcome: OutcomeSuccess, wantOutside: []string{"go.mod"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+			task.AllowedPaths = []string{"internal/foo/**"}
+			store.addTask(task)
+
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &sessionSequenceRunner{},
+				Verifier:  &mockVerifier{},
+				Git: &mockGitManager{
+					currentCommit: "abc123",
+					hasChanges:    true,
+					changedFiles:  []string{"internal/foo/a.go", "go.mod"},
+					commitHash:    "def456",
+				},
+				LogsDir: t.TempDir(),
+			})
+			require.NoError(t, ctrl.SetScopeMode(tt.mode))
+
+			record := ctrl.runIteration(context.Background(), task)
+
+			assert.Equal(t, tt.wantOutcome, record.Outcome)
+			assert.Equal(t, tt.wantFeedback, record.Feedback)
+			assert.Equal(t, tt.wantOutside, record.OutOfScopeFiles)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/decomposer"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// taskSplitter proposes smaller sub-tasks for a task that keeps failing.
+type taskSplitter interface {
+	Split(ctx context.Context, task *taskstore.Task, failure string) (*decomposer.SplitResult, error)
+}
+
+// SetTaskSplitter splits tasks that exhaust their retries: s proposes
+// sub-tasks, which are saved as blocked children of the failed task so they
+// can be reviewed before they run. A nil splitter (the default) leaves failed
+// tasks as they are.
+func (c *Controller) SetTaskSplitter(s taskSplitter) {
+	c.taskSplitter = s
+}
+
+// splitFailedTask asks the splitter for sub-tasks once task has been marked
+// failed, and returns the cost of the call. Failures to split are reported
+// as warnings; the task stays failed either way.
+func (c *Controller) splitFailedTask(ctx context.Context, task *taskstore.Task, record *IterationRecord) float64 {
+	if c.taskSplitter == nil {
+		return 0
+	}
+	current, err := c.taskStore.Get(task.ID)
+	if err != nil || current.Status != taskstore.StatusFailed {
+		return 0
+	}
+
+	c.writeProgress("  ✂ Asking the agent to split %s into smaller tasks...\n", task.ID)
+	result, err := c.taskSplitter.Split(ctx, current, record.Feedback)
+	if err != nil {
+		c.writeProgress("  ⚠ Could not split %s: %v\n", task.ID, err)
+		return 0
+	}
+
+	for _, sub := range result.Tasks {
+		if _, err := c.taskStore.Get(sub.ID); err == nil {
+			c.writeProgress("  ⚠ Could not split %s: task %s already exists\n", task.ID, sub.ID)
+			return result.TotalCostUSD
+		}
+	}
+
+	ids := make([]string, 0, len(result.Tasks))
+	for _, sub := range result.Tasks {
+		note := fmt.Sprintf("Proposed after %s failed on every attempt. Review, then run 'ralph fix --retry %s' to start it.", task.ID, sub.ID)
+		sub.Description = strings.TrimSpace(sub.Description + "\n\n" + note)
+		sub.Status = taskstore.StatusBlocked
+		if err := c.taskStore.Save(sub); err != nil {
+			c.writeProgress("  ⚠ Could not save sub-task %s: %v\n", sub.ID, err)
+			return result.TotalCostUSD
+		}
+		ids = append(ids, sub.ID)
+	}
+
+	c.writeProgress("  ✂ Split %s into %d blocked sub-task(s) for review: %s\n", task.ID, len(ids), strings.Join(ids, ", "))
+	return result.TotalCostUSD
+}
@@ -0,0 +1,133 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/decomposer"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// stubSplitter returns fixed sub-tasks and records the tasks it was asked to split.
+type stubSplitter struct {
+	tasks []*taskstore.Task
+	err   error
+	asked []string
+}
+
+func (s *stubSplitter) Split(ctx context.Context, task *taskstore.Task, failure string) (*decomposer.SplitResult, error) {
+	s.asked = append(s.asked, task.ID)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &decomposer.SplitResult{Tasks: s.tasks, TotalCostUSD: 0.02}, nil
+}
+
+func TestController_SplitFailedTask(t *testing.T) {
+	newSub := func(id string) *taskstore.Task {
+		return &taskstore.Task{ID: id, Title: "Sub " + id, Status: taskstore.StatusOpen, ParentID: strPtr("task-a"), CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	}
+
+	tests := []struct {
+		name       string
+		splitter   *stubSplitter
+		wantSubs   []string
+		wantOutput string
+	}{
+		{
+			name:       "saves sub-tasks blocked under the failed task",
+			splitter:   &stubSplitter{tasks: []*taskstore.Task{newSub("task-a-1"), newSub("task-a-2")}},
+			wantSubs:   []string{"task-a-1", "task-a-2"},
+			wantOutput: "Split task-a into 2 blocked sub-task(s) for review: task-a-1, task-a-2",
+		},
+		{
+			name:       "warns when the split fails",
+			splitter:   &stubSplitter{err: errors.New("no YAML content found in response")},
+			wantOutput: "Could not split task-a: no YAML content found in response",
+		},
+		{
+			name:       "refuses to overwrite existing tasks",
+			splitter:   &stubSplitter{tasks: []*taskstore.Task{newSub("task-a-1"), newSub("parent")}},
+			wantOutput: "Could not split task-a: task parent already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+			store.addTask(newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent")))
+
+			var out bytes.Buffer
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"}},
+				Verifier: &mockVerifier{
+					results: []verifier.VerificationResult{{Passed: false, Command: []string{"go", "test"}}},
+				},
+				Git: &mockGitManager{
+					currentCommit: "abc",
+					hasChanges:    true,
+					changedFiles:  []string{"f.go"},
+				},
+				LogsDir:        t.TempDir(),
+				ProgressWriter: &out,
+			})
+			ctrl.SetMaxRetries(0)
+			ctrl.SetMaxVerificationRetries(0)
+			ctrl.SetTaskSplitter(tt.splitter)
+
+			result := ctrl.RunOnce(context.Background(), "parent")
+
+			assert.Equal(t, []string{"task-a"}, tt.splitter.asked)
+			assert.Equal(t, taskstore.StatusFailed, store.tasks["task-a"].Status)
+			assert.Contains(t, out.String(), tt.wantOutput)
+			if tt.splitter.err == nil {
+				assert.InDelta(t, 0.02, result.TotalCostUSD, 1e-9, "the split call's cost is counted")
+				budget := ctrl.budget.GetState()
+				assert.InDelta(t, 0.02, budget.TotalCostUSD, 1e-9, "the split call's cost counts against the budget")
+				assert.Equal(t, 1, budget.Iterations, "the split call is not an iteration")
+			}
+			for _, id := range tt.wantSubs {
+				require.Contains(t, store.tasks, id)
+				assert.Equal(t, taskstore.StatusBlocked, store.tasks[id].Status)
+				assert.Contains(t, store.tasks[id].Description, "ralph fix --retry "+id)
+			}
+			if len(tt.wantSubs) == 0 {
+				assert.NotContains(t, store.tasks, "task-a-1")
+			}
+		})
+	}
+
+	t.Run("not asked while retries remain", func(t *testing.T) {
+		store := newMockTaskStore()
+		store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+		store.addTask(newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent")))
+
+		splitter := &stubSplitter{}
+		ctrl := NewController(ControllerDeps{
+			TaskStore: store,
+			Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"}},
+			Verifier: &mockVerifier{
+				results: []verifier.VerificationResult{{Passed: false, Command: []string{"go", "test"}}},
+			},
+			Git:     &mockGitManager{currentCommit: "abc", hasChanges: true, changedFiles: []string{"f.go"}},
+			LogsDir: t.TempDir(),
+		})
+		ctrl.SetMaxVerificationRetries(0)
+		ctrl.SetTaskSplitter(splitter)
+
+		ctrl.RunOnce(context.Background(), "parent")
+
+		assert.Empty(t, splitter.asked)
+		assert.Equal(t, taskstore.StatusOpen, store.tasks["task-a"].Status)
+	})
+}
@@ -0,0 +1,106 @@
+package loop
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// StepDecision is the user's answer when single-stepping through the loop.
+type StepDecision string
+
+const (
+	// StepRun runs the selected task.
+	StepRun StepDecision = "run"
+	// StepDecline leaves the selected task open and stops the run.
+	StepDecline StepDecision = "decline"
+	// StepSkip marks the selected task skipped and moves on.
+	StepSkip StepDecision = "skip"
+	// StepQuit stops the run.
+	StepQuit StepDecision = "quit"
+)
+
+// stepper decides whether each selected task runs.
+type stepper interface {
+	Step(task *taskstore.Task) (StepDecision, error)
+}
+
+// SetStepper makes RunLoop ask s before every iteration. A nil stepper (the
+// default) runs every selected task.
+func (c *Controller) SetStepper(s stepper) {
+	c.stepper = s
+}
+
+// PromptStepper asks on a terminal whether to run each selected task.
+type PromptStepper struct {
+	w      io.Writer
+	reader *bufio.Reader
+}
+
+// NewPromptStepper creates a PromptStepper that writes prompts to w and reads
+// answers from r.
+func NewPromptStepper(w io.Writer, r io.Reader) *PromptStepper {
+	return &PromptStepper{w: w, reader: bufio.NewReader(r)}
+}
+
+// Step shows the task and asks until it gets a valid answer. End of input
+// counts as quit.
+func (p *PromptStepper) Step(task *taskstore.Task) (StepDecision, error) {
+	_, _ = fmt.Fprintf(p.w, "Next task: %s (%s)\n", task.Title, task.ID)
+	for {
+		_, _ = fmt.Fprint(p.w, "Run this task? [y/n/skip/quit]: ")
+
+		response, err := p.reader.ReadString('\n')
+		if err != nil && response == "" {
+			if err == io.EOF {
+				_, _ = fmt.Fprintln(p.w)
+				return StepQuit, nil
+			}
+			return "", fmt.Errorf("failed to read step answer: %w", err)
+		}
+
+		switch strings.TrimSpace(strings.ToLower(response)) {
+		case "y", "yes":
+			return StepRun, nil
+		case "n", "no":
+			return StepDecline, nil
+		case "s", "skip":
+			return StepSkip, nil
+		case "q", "quit":
+			return StepQuit, nil
+		}
+		_, _ = fmt.Fprintln(p.w, "Please answer y, n, skip or quit.")
+	}
+}
+
+// stepStopMessage asks the stepper about task and returns a message if the
+// run should stop. A skipped task is marked skipped and reported by skipped.
+func (c *Controller) stepStopMessage(task *taskstore.Task) (msg string, skipped bool) {
+	if c.stepper == nil {
+		return "", false
+	}
+
+	decision, err := c.stepper.Step(task)
+	if err != nil {
+		return fmt.Sprintf("stopped stepping: %v", err), false
+	}
+
+	switch decision {
+	case StepSkip:
+		if err := c.taskStore.UpdateStatus(task.ID, taskstore.StatusSkipped); err != nil {
+			return fmt.Sprintf("failed to skip task %s: %v", task.ID, err), false
+		}
+		c.writeProgress("⏭ Skipped %s\n\n", task.ID)
+		c.lastSelectedID = ""
+		c.consecutiveSelections = 0
+		return "", true
+	case StepDecline:
+		return fmt.Sprintf("stopped before %s (task left open)", task.ID), false
+	case StepQuit:
+		return "stopped by user", false
+	}
+	return "", false
+}
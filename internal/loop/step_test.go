@@ -0,0 +1,135 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestPromptStepper_Step(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		want       StepDecision
+		wantOutput string
+	}{
+		{name: "yes runs", input: "y\n", want: StepRun},
+		{name: "no declines", input: "n\n", want: StepDecline},
+		{name: "skip skips", input: "skip\n", want: StepSkip},
+		{name: "quit quits", input: "QUIT\n", want: StepQuit},
+		{name: "end of input quits", input: "", want: StepQuit},
+		{name: "asks again after an invalid answer", input: "maybe\ny\n", want: StepRun, wantOutput: "Please answer y, n, skip or quit."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			stepper := NewPromptStepper(&out, strings.NewReader(tt.input))
+
+			got, err := stepper.Step(newTestTask("task-a", "Task A", taskstore.StatusOpen, nil))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Contains(t, out.String(), "Next task: Task A (task-a)")
+			assert.Contains(t, out.String(), "Run this task? [y/n/skip/quit]")
+			assert.Contains(t, out.String(), tt.wantOutput)
+		})
+	}
+}
+
+// scriptedStepper answers step prompts from a fixed list.
+type scriptedStepper struct {
+	answers []StepDecision
+}
+
+func (s *scriptedStepper) Step(*taskstore.Task) (StepDecision, error) {
+	answer := s.answers[0]
+	s.answers = s.answers[1:]
+	return answer, nil
+}
+
+func TestController_RunLoop_Step(t *testing.T) {
+	tests := []struct {
+		name          string
+		answers       []StepDecision
+		wantOutcome   RunLoopOutcome
+		wantMessage   string
+		wantCompleted []string
+		wantStatus    map[string]taskstore.TaskStatus
+	}{
+		{
+			name:          "runs confirmed tasks",
+			answers:       []StepDecision{StepRun, StepRun},
+			wantOutcome:   RunOutcomeCompleted,
+			wantCompleted: []string{"task-a", "task-b"},
+			wantStatus:    map[string]taskstore.TaskStatus{"task-a": taskstore.StatusCompleted, "task-b": taskstore.StatusCompleted},
+		},
+		{
+			name:          "skip marks the task skipped and moves on",
+			answers:       []StepDecision{StepSkip, StepRun},
+			wantOutcome:   RunOutcomeCompleted,
+			wantCompleted: []string{"task-b"},
+			wantStatus:    map[string]taskstore.TaskStatus{"task-a": taskstore.StatusSkipped, "task-b": taskstore.StatusCompleted},
+		},
+		{
+			name:          "no stops and leaves the task open",
+			answers:       []StepDecision{StepRun, StepDecline},
+			wantOutcome:   RunOutcomePaused,
+			wantMessage:   "stopped before task-b (task left open)",
+			wantCompleted: []string{"task-a"},
+			wantStatus:    map[string]taskstore.TaskStatus{"task-a": taskstore.StatusCompleted, "task-b": taskstore.StatusOpen},
+		},
+		{
+			name:          "quit stops",
+			answers:       []StepDecision{StepQuit},
+			wantOutcome:   RunOutcomePaused,
+			wantMessage:   "stopped by user",
+			wantCompleted: []string{},
+			wantStatus:    map[string]taskstore.TaskStatus{"task-a": taskstore.StatusOpen, "task-b": taskstore.StatusOpen},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent", taskstore.StatusOpen, nil))
+			store.addTask(newTestTask("task-a", "Task A", taskstore.StatusOpen, strPtr("parent")))
+			store.addTask(newTestTask("task-b", "Task B", taskstore.StatusOpen, strPtr("parent")))
+
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "Done"}},
+				Verifier: &mockVerifier{
+					results: []verifier.VerificationResult{{Passed: true, Command: []string{"go", "test"}}},
+				},
+				Git: &mockGitManager{
+					currentCommit: "abc123",
+					hasChanges:    true,
+					changedFiles:  []string{"f.go"},
+					commitHash:    "def456",
+				},
+				LogsDir: t.TempDir(),
+			})
+			stepper := &scriptedStepper{answers: tt.answers}
+			ctrl.SetStepper(stepper)
+
+			result := ctrl.RunLoop(context.Background(), "parent")
+
+			assert.Equal(t, tt.wantOutcome, result.Outcome, result.Message)
+			assert.Contains(t, result.Message, tt.wantMessage)
+			assert.Equal(t, tt.wantCompleted, result.CompletedTasks)
+			for id, status := range tt.wantStatus {
+				assert.Equal(t, status, store.tasks[id].Status, id)
+			}
+			assert.Empty(t, stepper.answers, "every answer should be used")
+		})
+	}
+}
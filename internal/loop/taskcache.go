@@ -0,0 +1,258 @@
+package loop
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/yarlson/ralph/internal/selector"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// versionedStore is a task store that reports a version that changes whenever
+// any task is saved or deleted, including by other processes.
+type versionedStore interface {
+	taskstore.Store
+	Version() (time.Time, error)
+}
+
+// cachedStore keeps the task list and dependency graph in memory between
+// iterations so selection does not re-read and re-parse every task. Writes
+// made through the cache update it in place (status changes keep the graph);
+// any other change to the store shows up as an unexpected version and causes
+// a full reload on the next read. Callers receive copies of cached tasks.
+//
+// The version has the resolution of the filesystem's timestamps. A change
+// made by another process in the same timestamp tick as the cache's last read
+// or write is not noticed until the store changes again; filesystems with
+// nanosecond timestamps make this practically impossible, while those with
+// one- or two-second timestamps can hit it.
+type cachedStore struct {
+	store versionedStore
+
+	mu      sync.Mutex
+	loaded  bool
+	version time.Time
+	order   []string
+	tasks   map[string]*taskstore.Task
+	graph   *selector.Graph
+}
+
+// cacheTaskStore wraps store in a cache if it reports a version; other stores
+// are returned unchanged and read in full on every access.
+func cacheTaskStore(store taskstore.Store) taskstore.Store {
+	if versioned, ok := store.(versionedStore); ok {
+		return &cachedStore{store: versioned}
+	}
+	return store
+}
+
+// Get retrieves a task by its ID.
+func (s *cachedStore) Get(id string) (*taskstore.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, &taskstore.NotFoundError{ID: id}
+	}
+	return copyTask(task), nil
+}
+
+// List retrieves all tasks, in the order the underlying store listed them.
+func (s *cachedStore) List() ([]*taskstore.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	return s.list(), nil
+}
+
+// ListByParent retrieves all tasks with the given parent ID.
+// If parentID is empty, returns tasks with no parent (root tasks).
+func (s *cachedStore) ListByParent(parentID string) ([]*taskstore.Task, error) {
+	tasks, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var children []*taskstore.Task
+	for _, t := range tasks {
+		if parentOf(t) == parentID {
+			children = append(children, t)
+		}
+	}
+	return children, nil
+}
+
+// ListWithGraph returns all tasks and their dependency graph. The graph is
+// rebuilt only after tasks are added, removed, or saved.
+func (s *cachedStore) ListWithGraph() ([]*taskstore.Task, *selector.Graph, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	tasks := s.list()
+	if s.graph == nil {
+		graph, err := selector.BuildGraph(tasks)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to build dependency graph: %w", err)
+		}
+		s.graph = graph
+	}
+	return tasks, s.graph, nil
+}
+
+// Save persists a task and updates the cached copy.
+func (s *cachedStore) Save(task *taskstore.Task) error {
+	return s.write(func() error { return s.store.Save(task) }, func() {
+		if _, exists := s.tasks[task.ID]; !exists {
+			s.order = append(s.order, task.ID)
+		}
+		s.tasks[task.ID] = copyTask(task)
+		s.graph = nil
+	})
+}
+
+// UpdateStatus updates the status of a task. The dependency graph is kept.
+func (s *cachedStore) UpdateStatus(id string, status taskstore.TaskStatus) error {
+	return s.write(func() error { return s.store.UpdateStatus(id, status) }, func() {
+		if task, ok := s.tasks[id]; ok {
+			task.Status = status
+			task.UpdatedAt = time.Now().Truncate(time.Second)
+		}
+	})
+}
+
+// Delete removes a task and drops it from the cache.
+func (s *cachedStore) Delete(id string) error {
+	return s.write(func() error { return s.store.Delete(id) }, func() {
+		delete(s.tasks, id)
+		s.order = slices.DeleteFunc(s.order, func(o string) bool { return o == id })
+		s.graph = nil
+	})
+}
+
+// refresh reloads the cache if it is empty or the store changed since it was
+// loaded. The version is read before listing, so a change made while listing
+// causes another reload on the next read. Caller must hold s.mu.
+func (s *cachedStore) refresh() error {
+	version, err := s.store.Version()
+	if err == nil && s.loaded && version.Equal(s.version) {
+		return nil
+	}
+
+	tasks, listErr := s.store.List()
+	if listErr != nil {
+		s.loaded = false
+		return listErr
+	}
+
+	s.order = make([]string, 0, len(tasks))
+	s.tasks = make(map[string]*taskstore.Task, len(tasks))
+	for _, t := range tasks {
+		s.order = append(s.order, t.ID)
+		s.tasks[t.ID] = t
+	}
+	s.graph = nil
+	s.version = version
+	s.loaded = err == nil
+	return nil
+}
+
+// write performs a write through the store. If nothing else changed the
+// store since it was loaded, apply updates the cache in place and the new
+// version is recorded; otherwise the cache is reloaded on the next read.
+func (s *cachedStore) write(do func() error, apply func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before, err := s.store.Version()
+	current := err == nil && s.loaded && before.Equal(s.version)
+
+	if err := do(); err != nil {
+		s.loaded = false
+		return err
+	}
+
+	after, err := s.store.Version()
+	if !current || err != nil {
+		s.loaded = false
+		return nil
+	}
+	apply()
+	s.version = after
+	return nil
+}
+
+// list returns copies of the cached tasks. Caller must hold s.mu.
+func (s *cachedStore) list() []*taskstore.Task {
+	tasks := make([]*taskstore.Task, 0, len(s.order))
+	for _, id := range s.order {
+		tasks = append(tasks, copyTask(s.tasks[id]))
+	}
+	return tasks
+}
+
+// copyTask returns a deep copy of t that callers may modify without affecting
+// the cache.
+func copyTask(t *taskstore.Task) *taskstore.Task {
+	c := *t
+	if t.ParentID != nil {
+		parentID := *t.ParentID
+		c.ParentID = &parentID
+	}
+	c.DependsOn = slices.Clone(t.DependsOn)
+	c.Acceptance = slices.Clone(t.Acceptance)
+	if t.Verify != nil {
+		c.Verify = make([][]string, len(t.Verify))
+		for i, cmd := range t.Verify {
+			c.Verify[i] = slices.Clone(cmd)
+		}
+	}
+	c.VerifyWhen = slices.Clone(t.VerifyWhen)
+	if t.VerifyExitCodes != nil {
+		c.VerifyExitCodes = make([]map[string]string, len(t.VerifyExitCodes))
+		for i, codes := range t.VerifyExitCodes {
+			c.VerifyExitCodes[i] = maps.Clone(codes)
+		}
+	}
+	c.ContextFiles = slices.Clone(t.ContextFiles)
+	c.AllowedPaths = slices.Clone(t.AllowedPaths)
+	c.Labels = maps.Clone(t.Labels)
+	return &c
+}
+
+func parentOf(t *taskstore.Task) string {
+	if t.ParentID == nil {
+		return ""
+	}
+	return *t.ParentID
+}
+
+// listTasksWithGraph returns all tasks and their dependency graph, reusing the
+// cached graph when the task store supports caching.
+func (c *Controller) listTasksWithGraph() ([]*taskstore.Task, *selector.Graph, error) {
+	if cached, ok := c.taskStore.(*cachedStore); ok {
+		return cached.ListWithGraph()
+	}
+
+	tasks, err := c.taskStore.List()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	graph, err := selector.BuildGraph(tasks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build dependency graph: %w", err)
+	}
+	return tasks, graph, nil
+}
@@ -0,0 +1,139 @@
+package loop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// countingStore counts full reads of the underlying store.
+type countingStore struct {
+	*taskstore.LocalStore
+	lists int
+}
+
+func (s *countingStore) List() ([]*taskstore.Task, error) {
+	s.lists++
+	return s.LocalStore.List()
+}
+
+func newCountingStore(t *testing.T) *countingStore {
+	local, err := taskstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	parent := newTestTask("parent", "Parent", taskstore.StatusOpen, nil)
+	first := newTestTask("first", "First", taskstore.StatusOpen, strPtr("parent"))
+	second := newTestTask("second", "Second", taskstore.StatusOpen, strPtr("parent"))
+	second.DependsOn = []string{"first"}
+	for _, task := range []*taskstore.Task{parent, first, second} {
+		require.NoError(t, local.Save(task))
+	}
+
+	return &countingStore{LocalStore: local}
+}
+
+func TestCachedStore(t *testing.T) {
+	t.Run("reuses tasks and graph across reads", func(t *testing.T) {
+		store := newCountingStore(t)
+		cached := cacheTaskStore(store).(*cachedStore)
+
+		tasks, graph, err := cached.ListWithGraph()
+		require.NoError(t, err)
+		assert.Len(t, tasks, 3)
+
+		_, again, err := cached.ListWithGraph()
+		require.NoError(t, err)
+		assert.Same(t, graph, again)
+		assert.Equal(t, 1, store.lists)
+	})
+
+	t.Run("status updates apply in place and keep the graph", func(t *testing.T) {
+		store := newCountingStore(t)
+		cached := cacheTaskStore(store).(*cachedStore)
+
+		_, graph, err := cached.ListWithGraph()
+		require.NoError(t, err)
+
+		require.NoError(t, cached.UpdateStatus("first", taskstore.StatusCompleted))
+
+		_, again, err := cached.ListWithGraph()
+		require.NoError(t, err)
+		assert.Same(t, graph, again)
+		assert.Equal(t, 1, store.lists)
+
+		task, err := cached.Get("first")
+		require.NoError(t, err)
+		assert.Equal(t, taskstore.StatusCompleted, task.Status)
+
+		onDisk, err := store.Get("first")
+		require.NoError(t, err)
+		assert.Equal(t, taskstore.StatusCompleted, onDisk.Status)
+	})
+
+	t.Run("reloads after external changes", func(t *testing.T) {
+		store := newCountingStore(t)
+		cached := cacheTaskStore(store).(*cachedStore)
+
+		_, graph, err := cached.ListWithGraph()
+		require.NoError(t, err)
+
+		// Written directly to the store, as another ralph process would
+		require.NoError(t, store.Save(newTestTask("third", "Third", taskstore.StatusOpen, strPtr("parent"))))
+		require.NoError(t, store.Delete("second"))
+
+		tasks, again, err := cached.ListWithGraph()
+		require.NoError(t, err)
+		assert.NotSame(t, graph, again)
+		assert.Equal(t, 2, store.lists)
+
+		var ids []string
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		assert.ElementsMatch(t, []string{"parent", "first", "third"}, ids)
+		assert.True(t, again.HasNode("third"))
+		assert.False(t, again.HasNode("second"))
+	})
+
+	t.Run("returns copies", func(t *testing.T) {
+		cached := cacheTaskStore(newCountingStore(t))
+
+		task, err := cached.Get("first")
+		require.NoError(t, err)
+		task.Status = taskstore.StatusFailed
+
+		again, err := cached.Get("first")
+		require.NoError(t, err)
+		assert.Equal(t, taskstore.StatusOpen, again.Status)
+	})
+
+	t.Run("copies are deep", func(t *testing.T) {
+		cached := cacheTaskStore(newCountingStore(t))
+
+		task, err := cached.Get("second")
+		require.NoError(t, err)
+		task.Labels = map[string]string{"area": "core"}
+		require.NoError(t, cached.Save(task))
+
+		task.Labels["area"] = "changed"
+		task, err = cached.Get("second")
+		require.NoError(t, err)
+		task.DependsOn[0] = "changed"
+		*task.ParentID = "changed"
+		task.Labels["area"] = "changed"
+
+		again, err := cached.Get("second")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first"}, again.DependsOn)
+		assert.Equal(t, "parent", *again.ParentID)
+		assert.Equal(t, map[string]string{"area": "core"}, again.Labels)
+	})
+
+	t.Run("stores without a version are not cached", func(t *testing.T) {
+		store := newMockTaskStore()
+		assert.Same(t, taskstore.Store(store), cacheTaskStore(store))
+	})
+}
@@ -0,0 +1,94 @@
+package loop
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/color"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// Verbosity controls how much progress output the controller writes.
+type Verbosity int
+
+const (
+	// VerbosityQuiet suppresses per-step progress; only the caller's final summary remains.
+	VerbosityQuiet Verbosity = -1
+	// VerbosityNormal writes the standard progress lines.
+	VerbosityNormal Verbosity = 0
+	// VerbosityVerbose adds diff stats, selection reasoning, and verification output.
+	VerbosityVerbose Verbosity = 1
+)
+
+// SetVerbosity sets the progress output level.
+func (c *Controller) SetVerbosity(v Verbosity) {
+	c.verbosity = v
+}
+
+// SetColor enables ANSI coloring of progress lines (✓ green, ✗ red, ⚠ yellow).
+func (c *Controller) SetColor(enabled bool) {
+	c.palette = color.New(enabled)
+}
+
+// writeVerbose writes progress output shown only at VerbosityVerbose.
+func (c *Controller) writeVerbose(format string, args ...interface{}) {
+	if c.verbosity < VerbosityVerbose {
+		return
+	}
+	c.writeProgress(format, args...)
+}
+
+// explainSelection writes why task was selected (verbose only).
+func (c *Controller) explainSelection(tasks []*taskstore.Task, task *taskstore.Task) {
+	if c.verbosity < VerbosityVerbose {
+		return
+	}
+	c.writeProgress("→ Selected %s: %s\n", task.ID, strings.Join(c.selectionReasons(tasks, task), "; "))
+}
+
+// selectionReasons explains why task was selected from tasks.
+func (c *Controller) selectionReasons(tasks []*taskstore.Task, task *taskstore.Task) []string {
+	status := make(map[string]taskstore.TaskStatus, len(tasks))
+	for _, t := range tasks {
+		status[t.ID] = t.Status
+	}
+
+	var reasons []string
+	if len(task.DependsOn) == 0 {
+		reasons = append(reasons, "no dependencies")
+	} else {
+		deps := make([]string, 0, len(task.DependsOn))
+		for _, dep := range task.DependsOn {
+			deps = append(deps, fmt.Sprintf("%s (%s)", dep, status[dep]))
+		}
+		reasons = append(reasons, "dependencies satisfied: "+strings.Join(deps, ", "))
+	}
+	if area := task.Labels["area"]; area != "" && c.lastCompleted != nil && c.lastCompleted.Labels["area"] == area {
+		reasons = append(reasons, fmt.Sprintf("same area as last completed task (%s)", area))
+	}
+	if c.selectionRand != nil {
+		reasons = append(reasons, "shuffled among ready tasks")
+	}
+	return reasons
+}
+
+// writeVerificationOutput writes each verification command's output (verbose only).
+func (c *Controller) writeVerificationOutput(results []verifier.VerificationResult) {
+	if c.verbosity < VerbosityVerbose {
+		return
+	}
+
+	for _, r := range results {
+		status := verificationStatus(r.Passed, r.Warning, r.Skipped)
+		c.writeProgress("    $ %s [%s]\n", strings.Join(r.Command, " "), status)
+		if output := strings.TrimRight(r.Output, "\n"); output != "" {
+			c.writeProgress("%s\n", indentLines(output, "      "))
+		}
+	}
+}
+
+// indentLines prefixes every line of s with prefix.
+func indentLines(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
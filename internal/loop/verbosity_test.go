@@ -0,0 +1,143 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestController_Verbosity(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity Verbosity
+		want      []string
+		notWant   []string
+		wantEmpty bool
+	}{
+		{
+			name:      "quiet writes nothing",
+			verbosity: VerbosityQuiet,
+			wantEmpty: true,
+		},
+		{
+			name:      "normal writes progress steps",
+			verbosity: VerbosityNormal,
+			want:      []string{"▶ Task: Child Task", "✓ Verification: 1/1 passed", "✓ Completed in"},
+			notWant:   []string{"→ Selected", "Diff stat", "ok  pkg"},
+		},
+		{
+			name:      "verbose adds selection, diff stat, and verification output",
+			verbosity: VerbosityVerbose,
+			want: []string{
+				"▶ Task: Child Task",
+				"→ Selected child: dependencies satisfied: setup (completed)",
+				"Diff stat:\n     file1.go | 2 +-",
+				"$ go test ./... [PASS]\n      ok  pkg",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+			store.addTask(newTestTask("setup", "Setup", taskstore.StatusCompleted, strPtr("parent")))
+			child := newTestTask("child", "Child Task", taskstore.StatusOpen, strPtr("parent"))
+			child.DependsOn = []string{"setup"}
+			child.Verify = [][]string{{"go", "test", "./..."}}
+			store.addTask(child)
+
+			var out bytes.Buffer
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "done"}},
+				Verifier: &mockVerifier{results: []verifier.VerificationResult{
+					{Passed: true, Command: []string{"go", "test", "./..."}, Output: "ok  pkg\n"},
+				}},
+				Git: &mockGitManager{
+					currentCommit: "abc123",
+					hasChanges:    true,
+					changedFiles:  []string{"file1.go"},
+					diffStat:      " file1.go | 2 +-\n",
+					commitHash:    "def456",
+				},
+				LogsDir:        t.TempDir(),
+				ProgressWriter: &out,
+			})
+			ctrl.SetVerbosity(tt.verbosity)
+
+			result := ctrl.RunLoop(context.Background(), "parent")
+			require.Equal(t, RunOutcomeCompleted, result.Outcome)
+
+			if tt.wantEmpty {
+				assert.Empty(t, out.String())
+			}
+			for _, s := range tt.want {
+				assert.Contains(t, out.String(), s)
+			}
+			for _, s := range tt.notWant {
+				assert.NotContains(t, out.String(), s)
+			}
+		})
+	}
+}
+
+func TestController_VerificationWarnings(t *testing.T) {
+	store := newMockTaskStore()
+	store.addTask(newTestTask("parent", "Parent Task", taskstore.StatusOpen, nil))
+	store.addTask(newTestTask("child", "Child Task", taskstore.StatusOpen, strPtr("parent")))
+
+	var out bytes.Buffer
+	ctrl := NewController(ControllerDeps{
+		TaskStore: store,
+		Claude:    &mockClaudeRunner{response: &claude.ClaudeResponse{SessionID: "sess", FinalText: "done"}},
+		Verifier: &mockVerifier{results: []verifier.VerificationResult{
+			{Passed: true, Command: []string{"go", "test", "./..."}},
+			{Passed: true, Warning: true, Command: []string{"golangci-lint", "run"}, Output: "style issue\n"},
+			{Passed: true, Skipped: true, Command: []string{"e2e"}},
+		}},
+		Git: &mockGitManager{
+			currentCommit: "abc123",
+			hasChanges:    true,
+			changedFiles:  []string{"file1.go"},
+			commitHash:    "def456",
+		},
+		LogsDir:        t.TempDir(),
+		ProgressWriter: &out,
+	})
+	ctrl.SetVerbosity(VerbosityVerbose)
+
+	result := ctrl.RunLoop(context.Background(), "parent")
+
+	require.Equal(t, RunOutcomeCompleted, result.Outcome)
+	assert.Contains(t, out.String(), "✓ Verification: 3/3 passed (1 with warnings)")
+	assert.Contains(t, out.String(), "$ golangci-lint run [WARN]")
+	assert.Contains(t, out.String(), "$ e2e [SKIP]")
+	require.Len(t, result.Records[0].VerificationOutputs, 3)
+	assert.True(t, result.Records[0].VerificationOutputs[1].Warning)
+	assert.True(t, result.Records[0].VerificationOutputs[2].Skipped)
+}
+
+func TestController_SetColor(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		var out bytes.Buffer
+		ctrl := NewController(ControllerDeps{TaskStore: newMockTaskStore(), Git: &mockGitManager{}, ProgressWriter: &out})
+		ctrl.SetColor(enabled)
+
+		ctrl.writeProgress("  ✗ Build failed: %s\n", "exit 1")
+		ctrl.writeProgress("  ⏳ Invoking agent...\n")
+
+		if enabled {
+			assert.Equal(t, "  \x1b[31m✗ Build failed: exit 1\x1b[0m\n  ⏳ Invoking agent...\n", out.String())
+		} else {
+			assert.Equal(t, "  ✗ Build failed: exit 1\n  ⏳ Invoking agent...\n", out.String())
+		}
+	}
+}
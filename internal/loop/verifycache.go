@@ -0,0 +1,251 @@
+package loop
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yarlson/ralph/internal/atomicfile"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// maxVerifyCacheEntries bounds the verify cache; the oldest passes are
+// dropped first.
+const maxVerifyCacheEntries = 1000
+
+// verifyCachedOutput is the output reported for a command served from the cache.
+const verifyCachedOutput = "Not run: this command already passed on an identical working tree (verify cache)."
+
+// treeHasher is a git manager that can hash the current working tree.
+type treeHasher interface {
+	WorkingTreeHash(ctx context.Context) (string, error)
+}
+
+// fingerprinter is a verifier that can identify the settings, such as its
+// environment and shell, that can change whether a command passes.
+type fingerprinter interface {
+	Fingerprint() string
+}
+
+// SetVerifyCache remembers verify commands that passed, keyed by the working
+// tree hash, the verifier's settings and the command, in the file at path. A
+// command that already passed on an identical tree with the same settings,
+// in this run or an earlier one, is not run again. Any change to a tracked or
+// untracked file outside .ralph gives a new tree hash; ignored files are not
+// part of it (see SetVerifyCacheInputs). An empty path (the default) disables
+// the cache.
+func (c *Controller) SetVerifyCache(path string) {
+	c.verifyCachePath = path
+}
+
+// SetVerifyCacheInputs adds the contents of paths, relative to the code
+// directory, to the verify cache key. Use it for files the tree hash leaves
+// out because git ignores them, such as .env or generated code; a directory
+// covers every file under it.
+func (c *Controller) SetVerifyCacheInputs(paths []string) {
+	c.verifyCacheInputs = paths
+}
+
+// verifyCacheEntry records one command that passed on one tree.
+type verifyCacheEntry struct {
+	Tree     string    `json:"tree"`
+	Command  []string  `json:"command"`
+	PassedAt time.Time `json:"passed_at"`
+}
+
+// verifyCacheFile is the on-disk verify cache.
+type verifyCacheFile struct {
+	Entries map[string]verifyCacheEntry `json:"entries"`
+}
+
+// verifyCacheKey identifies command run on tree by a verifier with the
+// given settings fingerprint.
+func verifyCacheKey(tree, settings string, command []string) string {
+	sum := sha256.Sum256([]byte(tree + "\x00" + settings + "\x00" + strings.Join(command, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashVerifyInputs hashes the names and contents of the files at paths,
+// relative to root, walking directories. A missing path hashes as missing,
+// so creating it changes the hash.
+func hashVerifyInputs(root string, paths []string) (string, error) {
+	h := sha256.New()
+	for _, path := range paths {
+		err := filepath.WalkDir(filepath.Join(root, path), func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			data, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, name)
+			if err != nil {
+				return err
+			}
+			sum := sha256.Sum256(data)
+			_, _ = fmt.Fprintf(h, "%s\x00%x\n", filepath.ToSlash(rel), sum)
+			return nil
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			_, _ = fmt.Fprintf(h, "%s\x00missing\n", filepath.ToSlash(path))
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to hash verify cache input %s: %w", path, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// loadVerifyCache reads the verify cache at path. A missing file is an empty cache.
+func loadVerifyCache(path string) (*verifyCacheFile, error) {
+	cache := &verifyCacheFile{Entries: make(map[string]verifyCacheEntry)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return cache, fmt.Errorf("failed to read verify cache: %w", err)
+	}
+	if err := json.Unmarshal(data, cache); err != nil {
+		return &verifyCacheFile{Entries: make(map[string]verifyCacheEntry)}, fmt.Errorf("failed to parse verify cache: %w", err)
+	}
+	if cache.Entries == nil {
+		cache.Entries = make(map[string]verifyCacheEntry)
+	}
+	return cache, nil
+}
+
+// saveVerifyCache writes cache to path, keeping only the newest entries.
+func saveVerifyCache(path string, cache *verifyCacheFile) error {
+	if len(cache.Entries) > maxVerifyCacheEntries {
+		keys := make([]string, 0, len(cache.Entries))
+		for key := range cache.Entries {
+			keys = append(keys, key)
+		}
+		slices.SortFunc(keys, func(a, b string) int {
+			return cache.Entries[b].PassedAt.Compare(cache.Entries[a].PassedAt)
+		})
+		for _, key := range keys[maxVerifyCacheEntries:] {
+			delete(cache.Entries, key)
+		}
+	}
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal verify cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create verify cache directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, data); err != nil {
+		return fmt.Errorf("failed to write verify cache: %w", err)
+	}
+	return nil
+}
+
+// verify runs commands through v, skipping those the verify cache
+// says already passed on the current working tree. Results keep the order of
+// commands. Without a cache, or when the tree cannot be hashed, every command
+// runs.
+func (c *Controller) verify(ctx context.Context, v verifier.Verifier, commands [][]string) ([]verifier.VerificationResult, error) {
+	if c.verifyCachePath == "" {
+		return v.Verify(ctx, commands)
+	}
+	if _, ok := baseGitManager(c.gitManager).(treeHasher); !ok {
+		return v.Verify(ctx, commands)
+	}
+	tree, err := c.gitManager.(treeHasher).WorkingTreeHash(ctx)
+	if err != nil {
+		c.writeProgress("  ⚠ Could not hash the working tree, verify cache not used: %v\n", err)
+		return v.Verify(ctx, commands)
+	}
+	var settings string
+	if f, ok := v.(fingerprinter); ok {
+		settings = f.Fingerprint()
+	}
+	if len(c.verifyCacheInputs) > 0 {
+		inputs, err := hashVerifyInputs(c.codeDir(), c.verifyCacheInputs)
+		if err != nil {
+			c.writeProgress("  ⚠ Verify cache not used: %v\n", err)
+			return v.Verify(ctx, commands)
+		}
+		settings += "\x00" + inputs
+	}
+
+	unlock := c.lockShared()
+	cache, err := loadVerifyCache(c.verifyCachePath)
+	unlock()
+	if err != nil {
+		c.writeProgress("  ⚠ Ignoring verify cache: %v\n", err)
+	}
+
+	results := make([]verifier.VerificationResult, len(commands))
+	var toRun [][]string
+	var toRunIdx []int
+	for i, command := range commands {
+		if _, ok := cache.Entries[verifyCacheKey(tree, settings, command)]; ok {
+			results[i] = verifier.VerificationResult{Passed: true, Command: command, Output: verifyCachedOutput}
+			continue
+		}
+		toRun = append(toRun, command)
+		toRunIdx = append(toRunIdx, i)
+	}
+	if cached := len(commands) - len(toRun); cached > 0 {
+		c.writeProgress("  ⏭ %d of %d verify commands already passed on this tree (cached)\n", cached, len(commands))
+	}
+	if len(toRun) == 0 {
+		return results, nil
+	}
+
+	ran, err := v.Verify(ctx, toRun)
+	if err != nil {
+		return nil, err
+	}
+	if len(ran) != len(toRun) {
+		return nil, fmt.Errorf("verifier returned %d results for %d commands", len(ran), len(toRun))
+	}
+
+	passed := make(map[string]verifyCacheEntry)
+	for j, r := range ran {
+		results[toRunIdx[j]] = r
+		if r.Passed && !r.Skipped && r.Error == "" {
+			passed[verifyCacheKey(tree, settings, toRun[j])] = verifyCacheEntry{Tree: tree, Command: toRun[j], PassedAt: time.Now()}
+		}
+	}
+	if len(passed) > 0 {
+		if err := c.addVerifyCacheEntries(passed); err != nil {
+			c.writeProgress("  ⚠ %v\n", err)
+		}
+	}
+	return results, nil
+}
+
+// addVerifyCacheEntries adds entries to the verify cache file. The file is
+// read again and written under one lock, so passes recorded by other workers
+// since it was first read are kept.
+func (c *Controller) addVerifyCacheEntries(entries map[string]verifyCacheEntry) error {
+	unlock := c.lockShared()
+	defer unlock()
+
+	cache, err := loadVerifyCache(c.verifyCachePath)
+	if err != nil {
+		c.writeProgress("  ⚠ Replacing verify cache: %v\n", err)
+	}
+	maps.Copy(cache.Entries, entries)
+	return saveVerifyCache(c.verifyCachePath, cache)
+}
@@ -0,0 +1,257 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// treeHashGitManager is a mockGitManager that reports a fixed working tree hash.
+type treeHashGitManager struct {
+	mockGitManager
+	tree string
+	err  error
+}
+
+func (m *treeHashGitManager) WorkingTreeHash(ctx context.Context) (string, error) {
+	return m.tree, m.err
+}
+
+// countingVerifier passes every command except those in fail, recording
+// which commands it ran.
+type countingVerifier struct {
+	mockVerifier
+	fail map[string]bool
+	ran  [][]string
+}
+
+func (v *countingVerifier) Verify(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+	results := make([]verifier.VerificationResult, 0, len(commands))
+	for _, command := range commands {
+		v.ran = append(v.ran, command)
+		results = append(results, verifier.VerificationResult{Command: command, Passed: !v.fail[command[0]], Output: "ran"})
+	}
+	return results, nil
+}
+
+// fingerprintVerifier is a countingVerifier with fixed settings.
+type fingerprintVerifier struct {
+	countingVerifier
+	settings string
+}
+
+func (v *fingerprintVerifier) Fingerprint() string {
+	return v.settings
+}
+
+// savingVerifier is a countingVerifier that, like another worker would,
+// saves a cache entry under key while its commands run.
+type savingVerifier struct {
+	countingVerifier
+	path string
+	key  string
+}
+
+func (v *savingVerifier) Verify(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+	cache, err := loadVerifyCache(v.path)
+	if err != nil {
+		return nil, err
+	}
+	cache.Entries[v.key] = verifyCacheEntry{Tree: "tree-2", Command: []string{"build"}, PassedAt: time.Now()}
+	if err := saveVerifyCache(v.path, cache); err != nil {
+		return nil, err
+	}
+	return v.countingVerifier.Verify(ctx, commands)
+}
+
+// runVerify verifies commands through ctrl's verifier.
+func runVerify(ctrl *Controller, commands [][]string) ([]verifier.VerificationResult, error) {
+	return ctrl.verify(context.Background(), ctrl.verifier, commands)
+}
+
+func TestController_VerifyCache(t *testing.T) {
+	commands := [][]string{{"lint"}, {"test", "./..."}}
+
+	newController := func(cachePath string, git *treeHashGitManager, v *countingVerifier) *Controller {
+		ctrl := NewController(ControllerDeps{Verifier: v, Git: git, LogsDir: t.TempDir()})
+		ctrl.SetVerifyCache(cachePath)
+		return ctrl
+	}
+
+	t.Run("skips commands that passed on the same tree in an earlier run", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "verify-cache.json")
+
+		first := &countingVerifier{}
+		results, err := runVerify(newController(cachePath, &treeHashGitManager{tree: "tree-1"}, first), commands)
+		require.NoError(t, err)
+		assert.Equal(t, commands, first.ran)
+		assert.Len(t, results, 2)
+
+		second := &countingVerifier{}
+		results, err = runVerify(newController(cachePath, &treeHashGitManager{tree: "tree-1"}, second), commands)
+		require.NoError(t, err)
+		assert.Empty(t, second.ran)
+		require.Len(t, results, 2)
+		for i, r := range results {
+			assert.True(t, r.Passed)
+			assert.Equal(t, commands[i], r.Command)
+			assert.Equal(t, verifyCachedOutput, r.Output)
+		}
+	})
+
+	t.Run("a changed tree runs everything again", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "verify-cache.json")
+		_, err := runVerify(newController(cachePath, &treeHashGitManager{tree: "tree-1"}, &countingVerifier{}), commands)
+		require.NoError(t, err)
+
+		v := &countingVerifier{}
+		_, err = runVerify(newController(cachePath, &treeHashGitManager{tree: "tree-2"}, v), commands)
+		require.NoError(t, err)
+		assert.Equal(t, commands, v.ran)
+	})
+
+	t.Run("changed verifier settings run everything again", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "verify-cache.json")
+		run := func(v *fingerprintVerifier) {
+			ctrl := NewController(ControllerDeps{Verifier: v, Git: &treeHashGitManager{tree: "tree-1"}, LogsDir: t.TempDir()})
+			ctrl.SetVerifyCache(cachePath)
+			_, err := runVerify(ctrl, commands)
+			require.NoError(t, err)
+		}
+		run(&fingerprintVerifier{settings: `{"env":{"MODE":"a"}}`})
+
+		same := &fingerprintVerifier{settings: `{"env":{"MODE":"a"}}`}
+		run(same)
+		assert.Empty(t, same.ran)
+
+		changed := &fingerprintVerifier{settings: `{"env":{"MODE":"b"}}`}
+		run(changed)
+		assert.Equal(t, commands, changed.ran)
+	})
+
+	t.Run("an edited cache input runs everything again", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "verify-cache.json")
+		workDir := t.TempDir()
+		envPath := filepath.Join(workDir, ".env")
+		require.NoError(t, os.WriteFile(envPath, []byte("MODE=a\n"), 0644))
+		run := func() [][]string {
+			v := &countingVerifier{}
+			// The tree hash stays the same: git ignores these files
+			ctrl := NewController(ControllerDeps{Verifier: v, Git: &treeHashGitManager{tree: "tree-1"}, LogsDir: t.TempDir(), WorkDir: workDir})
+			ctrl.SetVerifyCache(cachePath)
+			ctrl.SetVerifyCacheInputs([]string{".env", "gen"})
+			_, err := runVerify(ctrl, commands)
+			require.NoError(t, err)
+			return v.ran
+		}
+
+		assert.Equal(t, commands, run())
+		assert.Empty(t, run())
+
+		require.NoError(t, os.WriteFile(envPath, []byte("MODE=b\n"), 0644))
+		assert.Equal(t, commands, run(), "an edited ignored file")
+		assert.Empty(t, run())
+
+		require.NoError(t, os.MkdirAll(filepath.Join(workDir, "gen"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, "gen", "api.go"), []byte("package gen\n"), 0644))
+		assert.Equal(t, commands, run(), "a file added under an input directory")
+	})
+
+	t.Run("keeps passes saved while the commands ran", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "verify-cache.json")
+		v := &savingVerifier{path: cachePath, key: verifyCacheKey("tree-2", "", []string{"build"})}
+		ctrl := NewController(ControllerDeps{Verifier: v, Git: &treeHashGitManager{tree: "tree-1"}, LogsDir: t.TempDir()})
+		ctrl.SetVerifyCache(cachePath)
+		_, err := runVerify(ctrl, commands)
+		require.NoError(t, err)
+
+		cache, err := loadVerifyCache(cachePath)
+		require.NoError(t, err)
+		assert.Len(t, cache.Entries, 3)
+		assert.Contains(t, cache.Entries, v.key)
+		assert.Contains(t, cache.Entries, verifyCacheKey("tree-1", "", []string{"lint"}))
+	})
+
+	t.Run("failures are not cached and results keep their order", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "verify-cache.json")
+		_, err := runVerify(newController(cachePath, &treeHashGitManager{tree: "tree-1"}, &countingVerifier{fail: map[string]bool{"test": true}}), commands)
+		require.NoError(t, err)
+
+		v := &countingVerifier{fail: map[string]bool{"test": true}}
+		results, err := runVerify(newController(cachePath, &treeHashGitManager{tree: "tree-1"}, v), commands)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"test", "./..."}}, v.ran)
+		require.Len(t, results, 2)
+		assert.Equal(t, []string{"lint"}, results[0].Command)
+		assert.True(t, results[0].Passed)
+		assert.Equal(t, []string{"test", "./..."}, results[1].Command)
+		assert.False(t, results[1].Passed)
+	})
+
+	t.Run("disabled without a cache path", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "verify-cache.json")
+		v := &countingVerifier{}
+		ctrl := newController("", &treeHashGitManager{tree: "tree-1"}, v)
+		_, err := runVerify(ctrl, commands)
+		require.NoError(t, err)
+		_, err = runVerify(ctrl, commands)
+		require.NoError(t, err)
+
+		assert.Len(t, v.ran, 4)
+		assert.NoFileExists(t, cachePath)
+	})
+
+	t.Run("runs everything when the tree cannot be hashed", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "verify-cache.json")
+		require.NoError(t, saveVerifyCache(cachePath, &verifyCacheFile{Entries: map[string]verifyCacheEntry{
+			verifyCacheKey("", "", []string{"lint"}): {Command: []string{"lint"}},
+		}}))
+
+		v := &countingVerifier{}
+		_, err := runVerify(newController(cachePath, &treeHashGitManager{err: errors.New("not a git repository")}, v), commands)
+		require.NoError(t, err)
+		assert.Equal(t, commands, v.ran)
+	})
+
+	t.Run("a corrupt cache file is ignored and replaced", func(t *testing.T) {
+		cachePath := filepath.Join(t.TempDir(), "verify-cache.json")
+		require.NoError(t, os.WriteFile(cachePath, []byte("{not json"), 0644))
+
+		v := &countingVerifier{}
+		_, err := runVerify(newController(cachePath, &treeHashGitManager{tree: "tree-1"}, v), commands)
+		require.NoError(t, err)
+		assert.Equal(t, commands, v.ran)
+
+		cache, err := loadVerifyCache(cachePath)
+		require.NoError(t, err)
+		assert.Len(t, cache.Entries, 2)
+	})
+}
+
+func TestSaveVerifyCache_KeepsNewestEntries(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "state", "verify-cache.json")
+	cache := &verifyCacheFile{Entries: make(map[string]verifyCacheEntry)}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range maxVerifyCacheEntries + 5 {
+		command := []string{"cmd", strconv.Itoa(i)}
+		cache.Entries[verifyCacheKey("tree", "", command)] = verifyCacheEntry{Tree: "tree", Command: command, PassedAt: base.Add(time.Duration(i) * time.Second)}
+	}
+
+	require.NoError(t, saveVerifyCache(cachePath, cache))
+
+	loaded, err := loadVerifyCache(cachePath)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries, maxVerifyCacheEntries)
+	for _, entry := range loaded.Entries {
+		assert.False(t, entry.PassedAt.Before(base.Add(5*time.Second)), "oldest entries should be dropped")
+	}
+}
@@ -0,0 +1,83 @@
+package loop
+
+import (
+	"path"
+	"strings"
+)
+
+// SetVerifyFullEvery makes every nth iteration that would skip verify commands
+// (because no changed file matches their verifyWhen glob) run them all instead,
+// as a safety net against globs that miss a dependency. Zero disables it.
+func (c *Controller) SetVerifyFullEvery(n int) {
+	c.verifyFullEvery = n
+}
+
+// selectVerifyCommands returns the verify commands to run for changedFiles and
+// whether the periodic full verification overrode the verifyWhen globs. It is
+// called once per iteration and tracks how many iterations in a row skipped
+// commands.
+func (c *Controller) selectVerifyCommands(commands [][]string, when []string, changedFiles []string) ([][]string, bool) {
+	selected := triggeredVerifyCommands(commands, when, changedFiles)
+	if len(selected) == len(commands) {
+		c.skippedVerifyRuns = 0
+		return selected, false
+	}
+
+	if c.verifyFullEvery > 0 && c.skippedVerifyRuns+1 >= c.verifyFullEvery {
+		c.skippedVerifyRuns = 0
+		return commands, true
+	}
+
+	c.skippedVerifyRuns++
+	for i, cmd := range commands {
+		if i < len(when) && when[i] != "" && !matchesAnyFile(when[i], changedFiles) {
+			c.writeVerbose("    skipped %s (no changes match %s)\n", strings.Join(cmd, " "), when[i])
+		}
+	}
+	return selected, false
+}
+
+// triggeredVerifyCommands returns the commands whose verifyWhen glob (by
+// index) matches one of changedFiles. Commands without a glob always run.
+func triggeredVerifyCommands(commands [][]string, when []string, changedFiles []string) [][]string {
+	selected := make([][]string, 0, len(commands))
+	for i, cmd := range commands {
+		if i < len(when) && when[i] != "" && !matchesAnyFile(when[i], changedFiles) {
+			continue
+		}
+		selected = append(selected, cmd)
+	}
+	return selected
+}
+
+// matchesAnyFile reports whether glob matches one of files. A glob without a
+// slash matches the file name in any directory ("*.go"); otherwise it matches
+// the path from the repository root ("docs/*.md"). A trailing "/**" matches
+// everything under a directory ("web/**"). Malformed globs match every file,
+// so a typo never skips verification.
+func matchesAnyFile(glob string, files []string) bool {
+	dir, underDir := strings.CutSuffix(glob, "/**")
+	if _, err := path.Match(dir, ""); err != nil {
+		return true
+	}
+
+	for _, file := range files {
+		if underDir {
+			for d := path.Dir(file); d != "." && d != "/"; d = path.Dir(d) {
+				if matched, _ := path.Match(dir, d); matched {
+					return true
+				}
+			}
+			continue
+		}
+
+		name := file
+		if !strings.Contains(glob, "/") {
+			name = path.Base(file)
+		}
+		if matched, _ := path.Match(glob, name); matched {
+			return true
+		}
+	}
+	return false
+}
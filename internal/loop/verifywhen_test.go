@@ -0,0 +1,138 @@
+package loop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+func TestMatchesAnyFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		glob  string
+		files []string
+		want  bool
+	}{
+		{name: "extension in any directory", glob: "*.go", files: []string{"README.md", "internal/loop/controller.go"}, want: true},
+		{name: "extension not changed", glob: "*.go", files: []string{"README.md", "docs/guide.md"}, want: false},
+		{name: "path from repository root", glob: "docs/*.md", files: []string{"docs/guide.md"}, want: true},
+		{name: "path does not match other directories", glob: "docs/*.md", files: []string{"README.md"}, want: false},
+		{name: "everything under a directory", glob: "web/**", files: []string{"web/src/app/main.ts"}, want: true},
+		{name: "nothing under a directory", glob: "web/**", files: []string{"webapp/main.ts", "main.go"}, want: false},
+		{name: "malformed glob matches", glob: "[", files: []string{"README.md"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchesAnyFile(tt.glob, tt.files))
+		})
+	}
+}
+
+func TestController_SelectVerifyCommands(t *testing.T) {
+	goTest := []string{"go", "test", "./..."}
+	lint := []string{"markdownlint", "."}
+	commands := [][]string{goTest, lint}
+	when := []string{"*.go"}
+
+	t.Run("skips commands whose glob matches no changed file", func(t *testing.T) {
+		ctrl := NewController(ControllerDeps{})
+
+		selected, full := ctrl.selectVerifyCommands(commands, when, []string{"README.md"})
+
+		assert.Equal(t, [][]string{lint}, selected)
+		assert.False(t, full)
+	})
+
+	t.Run("runs commands whose glob matches", func(t *testing.T) {
+		ctrl := NewController(ControllerDeps{})
+
+		selected, full := ctrl.selectVerifyCommands(commands, when, []string{"README.md", "main.go"})
+
+		assert.Equal(t, commands, selected)
+		assert.False(t, full)
+	})
+
+	t.Run("periodically runs everything", func(t *testing.T) {
+		ctrl := NewController(ControllerDeps{})
+		ctrl.SetVerifyFullEvery(3)
+
+		var fulls []bool
+		for range 6 {
+			_, full := ctrl.selectVerifyCommands(commands, when, []string{"README.md"})
+			fulls = append(fulls, full)
+		}
+
+		assert.Equal(t, []bool{false, false, true, false, false, true}, fulls)
+	})
+
+	t.Run("a full run resets the count", func(t *testing.T) {
+		ctrl := NewController(ControllerDeps{})
+		ctrl.SetVerifyFullEvery(2)
+
+		_, _ = ctrl.selectVerifyCommands(commands, when, []string{"README.md"})
+		_, _ = ctrl.selectVerifyCommands(commands, when, []string{"main.go"})
+		_, full := ctrl.selectVerifyCommands(commands, when, []string{"README.md"})
+
+		assert.False(t, full)
+	})
+}
+
+func TestController_RunIteration_VerifyWhen(t *testing.T) {
+	build := []string{"go", "build", "./..."}
+	goTest := []string{"go", "test", "./..."}
+
+	tests := []struct {
+		name         string
+		changedFiles []string
+		wantCommands [][]string
+	}{
+		{name: "docs-only change skips code verification", changedFiles: []string{"README.md"}, wantCommands: nil},
+		{name: "code change runs build and tests", changedFiles: []string{"README.md", "main.go"}, wantCommands: [][]string{build, goTest}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTaskStore()
+			task := newTestTask("task-1", "Task 1", taskstore.StatusOpen, nil)
+			task.Verify = [][]string{goTest}
+			task.VerifyWhen = []string{"*.go"}
+			store.addTask(task)
+
+			var ran [][]string
+			verifierMock := &mockVerifier{
+				verifyFn: func(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+					var results []verifier.VerificationResult
+					for _, cmd := range commands {
+						ran = append(ran, cmd)
+						results = append(results, verifier.VerificationResult{Passed: true, Command: cmd})
+					}
+					return results, nil
+				},
+			}
+
+			ctrl := NewController(ControllerDeps{
+				TaskStore: store,
+				Claude:    &sessionSequenceRunner{},
+				Verifier:  verifierMock,
+				Git: &mockGitManager{
+					currentCommit: "abc123",
+					hasChanges:    true,
+					changedFiles:  tt.changedFiles,
+					commitHash:    "def456",
+				},
+				LogsDir: t.TempDir(),
+			})
+			ctrl.SetBuildFirst(build)
+
+			record := ctrl.runIteration(context.Background(), task)
+
+			assert.Equal(t, tt.wantCommands, ran)
+			assert.Equal(t, OutcomeSuccess, record.Outcome)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package loop
+
+import (
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// gitWrapper is a git manager the controller wraps around another to change
+// some of its behavior. Wrappers forward every optional method, so a type
+// assertion on them always succeeds whether or not the wrapped manager
+// supports the method.
+type gitWrapper interface {
+	Unwrap() git.Manager
+}
+
+// baseGitManager returns the manager underneath any wrappers, for checking
+// which optional methods are actually supported.
+func baseGitManager(m git.Manager) git.Manager {
+	for {
+		w, ok := m.(gitWrapper)
+		if !ok {
+			return m
+		}
+		m = w.Unwrap()
+	}
+}
+
+// verifierWrapper is a verifier the controller wraps around another. Like
+// git wrappers, it forwards every optional method.
+type verifierWrapper interface {
+	Unwrap() verifier.Verifier
+}
+
+// baseVerifier returns the verifier underneath any wrappers, for checking
+// which optional methods are actually supported.
+func baseVerifier(v verifier.Verifier) verifier.Verifier {
+	for {
+		w, ok := v.(verifierWrapper)
+		if !ok {
+			return v
+		}
+		v = w.Unwrap()
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/yarlson/ralph/internal/atomicfile"
 )
 
 // ProgressFile manages the .ralph/progress.md file for tracking iteration history.
@@ -90,14 +92,14 @@ func (p *ProgressFile) AppendIteration(entry IterationEntry) error {
 	// Format the entry
 	formatted := entry.Format(time.Now())
 
-	// Open file for appending (create if not exists)
-	f, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("opening progress file: %w", err)
+	// Read existing content (create if not exists)
+	existing, err := os.ReadFile(p.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading progress file: %w", err)
 	}
-	defer func() { _ = f.Close() }()
 
-	if _, err := f.WriteString(formatted); err != nil {
+	// Rewrite atomically so a crash never leaves a half-appended entry
+	if err := atomicfile.WriteFile(p.path, append(existing, formatted...)); err != nil {
 		return fmt.Errorf("appending to progress file: %w", err)
 	}
 
@@ -190,7 +192,7 @@ func (p *ProgressFile) UpdateCodebasePatterns(patterns string) error {
 		return fmt.Errorf("replacing patterns section: %w", err)
 	}
 
-	if err := os.WriteFile(p.path, []byte(updated), 0644); err != nil {
+	if err := atomicfile.WriteFile(p.path, []byte(updated)); err != nil {
 		return fmt.Errorf("writing progress file: %w", err)
 	}
 
@@ -329,7 +331,7 @@ func (p *ProgressFile) EnforceMaxSize(opts SizeOptions) (bool, error) {
 		}
 	}
 
-	if err := os.WriteFile(p.path, []byte(sb.String()), 0644); err != nil {
+	if err := atomicfile.WriteFile(p.path, []byte(sb.String())); err != nil {
 		return false, fmt.Errorf("writing pruned progress file: %w", err)
 	}
 
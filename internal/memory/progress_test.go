@@ -546,3 +546,23 @@ func TestIterationEntry_Format(t *testing.T) {
 		assert.NotContains(t, formatted, "**Learnings:**")
 	})
 }
+
+func TestProgressFile_AppendIteration_FailedWriteLeavesFileIntact(t *testing.T) {
+	tmpDir := t.TempDir()
+	progressPath := filepath.Join(tmpDir, "progress.md")
+
+	pf := NewProgressFile(progressPath)
+	require.NoError(t, pf.Init("Test Feature", "parent"))
+	original, err := os.ReadFile(progressPath)
+	require.NoError(t, err)
+
+	// Block the temp file path so the append fails mid-write
+	require.NoError(t, os.Mkdir(progressPath+".tmp", 0755))
+
+	err = pf.AppendIteration(IterationEntry{TaskID: "task-1", TaskTitle: "Task", Outcome: "Success"})
+	require.Error(t, err)
+
+	current, err := os.ReadFile(progressPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(original), string(current))
+}
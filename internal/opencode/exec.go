@@ -5,14 +5,18 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/procenv"
+	"github.com/yarlson/ralph/internal/redact"
 	"github.com/yarlson/ralph/internal/stream"
 )
 
@@ -24,6 +28,8 @@ type SubprocessRunner struct {
 	TaskID       string
 	streamOutput bool
 	streamOpts   stream.Options
+	env          map[string]string // passed-through variables set on every invocation
+	redactor     *redact.Redactor  // removes passed-through values from the raw log
 }
 
 const (
@@ -53,6 +59,15 @@ func (r *SubprocessRunner) WithStreamOutput(opts stream.Options) *SubprocessRunn
 	return r
 }
 
+// WithEnvPassthrough limits every invocation's environment to a base set of
+// variables (PATH, HOME and the like) plus env, and keeps env's values out of
+// the raw NDJSON log.
+func (r *SubprocessRunner) WithEnvPassthrough(env map[string]string) *SubprocessRunner {
+	r.env = env
+	r.redactor = redact.New(slices.Collect(maps.Values(env))...)
+	return r
+}
+
 // Run executes OpenCode with the given request and returns the response.
 func (r *SubprocessRunner) Run(ctx context.Context, req claude.ClaudeRequest) (*claude.ClaudeResponse, error) {
 	args := buildArgs(req, r.baseArgs)
@@ -62,11 +77,11 @@ func (r *SubprocessRunner) Run(ctx context.Context, req claude.ClaudeRequest) (*
 		cmd.Dir = req.Cwd
 	}
 
-	if len(req.Env) > 0 {
-		cmd.Env = os.Environ()
-		for k, v := range req.Env {
-			cmd.Env = append(cmd.Env, k+"="+v)
-		}
+	switch {
+	case r.env != nil:
+		cmd.Env = procenv.Restricted(r.env, req.Env)
+	case len(req.Env) > 0:
+		cmd.Env = procenv.Inherited(req.Env)
 	}
 
 	logFilename := generateLogFilename(r.TaskID)
@@ -76,6 +91,7 @@ func (r *SubprocessRunner) Run(ctx context.Context, req claude.ClaudeRequest) (*
 		return nil, fmt.Errorf("failed to create log file %s: %w", logPath, err)
 	}
 	defer func() { _ = logFile.Close() }()
+	logWriter := r.redactor.Writer(logFile)
 
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
@@ -90,7 +106,7 @@ func (r *SubprocessRunner) Run(ctx context.Context, req claude.ClaudeRequest) (*
 	}
 
 	var stdoutBuf bytes.Buffer
-	writers := []io.Writer{logFile, &stdoutBuf}
+	writers := []io.Writer{logWriter, &stdoutBuf}
 
 	var streamPipeWriter *io.PipeWriter
 	var streamDone chan struct{}
@@ -109,6 +125,7 @@ func (r *SubprocessRunner) Run(ctx context.Context, req claude.ClaudeRequest) (*
 
 	multiWriter := io.MultiWriter(writers...)
 	_, copyErr := io.Copy(multiWriter, stdoutPipe)
+	_ = logWriter.Close()
 
 	if streamPipeWriter != nil {
 		_ = streamPipeWriter.Close()
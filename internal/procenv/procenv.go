@@ -0,0 +1,39 @@
+// Package procenv builds the environment of the agent and verify subprocesses.
+package procenv
+
+import "os"
+
+// baseNames are the variables a restricted environment keeps from Ralph's own
+// environment: what shells, toolchains and the agent CLIs need to run.
+var baseNames = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "LANG", "LC_ALL", "LC_CTYPE", "TZ", "TMPDIR",
+	"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME", "XDG_RUNTIME_DIR",
+	// Windows
+	"SYSTEMROOT", "COMSPEC", "PATHEXT", "USERPROFILE", "APPDATA", "LOCALAPPDATA", "TEMP", "TMP",
+}
+
+// Restricted returns the base variables set in Ralph's environment followed by
+// the entries of envs. A name set in several places takes its last value.
+func Restricted(envs ...map[string]string) []string {
+	var env []string
+	for _, name := range baseNames {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return appendEntries(env, envs)
+}
+
+// Inherited returns Ralph's whole environment followed by the entries of envs.
+func Inherited(envs ...map[string]string) []string {
+	return appendEntries(os.Environ(), envs)
+}
+
+func appendEntries(env []string, envs []map[string]string) []string {
+	for _, m := range envs {
+		for k, v := range m {
+			env = append(env, k+"="+v)
+		}
+	}
+	return env
+}
@@ -0,0 +1,28 @@
+package procenv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestricted(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	t.Setenv("UNLISTED_SECRET", "nope")
+
+	env := Restricted(map[string]string{"SERVICE_TOKEN": "tok"}, map[string]string{"EXTRA": "1"})
+
+	assert.Contains(t, env, "PATH=/usr/bin")
+	assert.Contains(t, env, "SERVICE_TOKEN=tok")
+	assert.Contains(t, env, "EXTRA=1")
+	assert.NotContains(t, env, "UNLISTED_SECRET=nope")
+}
+
+func TestInherited(t *testing.T) {
+	t.Setenv("UNLISTED_SECRET", "kept")
+
+	env := Inherited(map[string]string{"EXTRA": "1"})
+
+	assert.Contains(t, env, "UNLISTED_SECRET=kept")
+	assert.Equal(t, "EXTRA=1", env[len(env)-1])
+}
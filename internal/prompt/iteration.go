@@ -34,6 +34,27 @@ type IterationContext struct {
 
 	// IsRetry indicates if this is a retry of a failed task.
 	IsRetry bool
+
+	// CustomInstructions is team-provided guidance from .ralph/prompts/iteration.md.
+	CustomInstructions string
+
+	// CheckpointsEnabled tells the agent it may report completed sub-goals.
+	CheckpointsEnabled bool
+
+	// ContextFiles are the task's reference files, in the task's order.
+	ContextFiles []ContextFile
+}
+
+// ContextFile is a reference file whose contents are shown to the agent.
+type ContextFile struct {
+	// Path is the file path relative to the repository root.
+	Path string
+
+	// Content is the file content.
+	Content string
+
+	// Err explains why the content is unavailable (e.g. "file not found").
+	Err string
 }
 
 // SizeOptions configures the maximum sizes for various prompt components.
@@ -49,6 +70,13 @@ type SizeOptions struct {
 
 	// MaxFailureBytes is the maximum size of the failure output section.
 	MaxFailureBytes int
+
+	// MaxContextFileBytes is the maximum size of each reference file.
+	MaxContextFileBytes int
+
+	// MaxInstructionsBytes is the maximum size of the project instructions
+	// from .ralph/prompts/iteration.md (0 = no limit).
+	MaxInstructionsBytes int
 }
 
 // DefaultSizeOptions returns sensible default size options.
@@ -58,6 +86,9 @@ func DefaultSizeOptions() SizeOptions {
 		MaxPatternsBytes: 2000,
 		MaxDiffBytes:     1000,
 		MaxFailureBytes:  2000,
+
+		MaxContextFileBytes:  2000,
+		MaxInstructionsBytes: 4000,
 	}
 }
 
@@ -75,6 +106,12 @@ func (o SizeOptions) Validate() error {
 	if o.MaxFailureBytes < 0 {
 		return errors.New("max failure bytes cannot be negative")
 	}
+	if o.MaxContextFileBytes < 0 {
+		return errors.New("max context file bytes cannot be negative")
+	}
+	if o.MaxInstructionsBytes < 0 {
+		return errors.New("max instructions bytes cannot be negative")
+	}
 	return nil
 }
 
@@ -159,6 +196,16 @@ func (b *Builder) BuildUserPrompt(ctx IterationContext) (string, error) {
 		sb.WriteString("\n")
 	}
 
+	// Allowed paths
+	if len(ctx.Task.AllowedPaths) > 0 {
+		sb.WriteString("### Allowed Paths\n")
+		sb.WriteString("Only modify files matching these patterns; changes outside them fail the task:\n")
+		for _, p := range ctx.Task.AllowedPaths {
+			_, _ = fmt.Fprintf(&sb, "- `%s`\n", p)
+		}
+		sb.WriteString("\n")
+	}
+
 	// Codebase patterns
 	if ctx.CodebasePatterns != "" {
 		patterns := truncateWithMarker(ctx.CodebasePatterns, b.opts.MaxPatternsBytes)
@@ -195,14 +242,75 @@ func (b *Builder) BuildUserPrompt(ctx IterationContext) (string, error) {
 		sb.WriteString("\n")
 	}
 
-	// Instructions
+	// Instructions and project instructions, rendered before the reference
+	// files so those can be fitted into what is left of the prompt budget
+	var tail strings.Builder
+	b.writeInstructions(&tail, ctx)
+
+	// Reference files
+	if len(ctx.ContextFiles) > 0 {
+		budget := 0
+		if b.opts.MaxPromptBytes > 0 {
+			budget = max(b.opts.MaxPromptBytes-sb.Len()-tail.Len(), 1)
+		}
+		b.writeContextFiles(&sb, ctx.ContextFiles, budget)
+	}
+
+	sb.WriteString(tail.String())
+	return sb.String(), nil
+}
+
+// writeInstructions writes the closing instructions and any project-specific
+// instructions.
+func (b *Builder) writeInstructions(sb *strings.Builder, ctx IterationContext) {
 	sb.WriteString("### Instructions\n")
 	sb.WriteString("1. Implement the task according to the description and acceptance criteria.\n")
 	sb.WriteString("2. Run the verification commands and fix any failures.\n")
 	sb.WriteString("3. Do not commit - the harness will commit after verification.\n")
 	sb.WriteString("4. Update .ralph/progress.md with what changed and learnings.\n")
+	if ctx.CheckpointsEnabled {
+		sb.WriteString("5. If you complete a meaningful sub-goal but the task is not finished, end your response with a line `RALPH_CHECKPOINT: <short summary>` so the harness can commit the progress.\n")
+	}
 
-	return sb.String(), nil
+	// Project-specific instructions
+	if custom := strings.TrimSpace(ctx.CustomInstructions); custom != "" {
+		sb.WriteString("\n### Project Instructions\n")
+		sb.WriteString(truncateWithMarker(custom, b.opts.MaxInstructionsBytes))
+		sb.WriteString("\n")
+	}
+}
+
+// writeContextFiles writes the reference files section. Each file is
+// truncated to MaxContextFileBytes; once budget bytes are used up (0 = no
+// budget), the remaining files are listed by path only.
+func (b *Builder) writeContextFiles(sb *strings.Builder, files []ContextFile, budget int) {
+	var section strings.Builder
+	section.WriteString("### Reference Files\n")
+	section.WriteString("Read these files for context before making changes:\n\n")
+
+	var omitted []string
+	for _, f := range files {
+		var entry string
+		if f.Err != "" {
+			entry = fmt.Sprintf("#### %s\n(%s)\n\n", f.Path, f.Err)
+		} else {
+			entry = fmt.Sprintf("#### %s\n```\n%s\n```\n\n", f.Path, truncateWithMarker(f.Content, b.opts.MaxContextFileBytes))
+		}
+		if budget > 0 && section.Len()+len(entry) > budget {
+			omitted = append(omitted, f.Path)
+			continue
+		}
+		section.WriteString(entry)
+	}
+	if len(omitted) > 0 {
+		section.WriteString("Not included (prompt size limit), read them yourself:\n")
+		for _, path := range omitted {
+			_, _ = fmt.Fprintf(&section, "- `%s`\n", path)
+		}
+		section.WriteString("\n")
+	}
+
+	sb.WriteString(section.String())
 }
 
 // Build builds both system and user prompts from the given context.
@@ -2,6 +2,7 @@
 package prompt
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -58,6 +59,8 @@ func TestSizeOptions_Defaults(t *testing.T) {
 	assert.Equal(t, 2000, opts.MaxPatternsBytes)
 	assert.Equal(t, 1000, opts.MaxDiffBytes)
 	assert.Equal(t, 2000, opts.MaxFailureBytes)
+	assert.Equal(t, 2000, opts.MaxContextFileBytes)
+	assert.Equal(t, 4000, opts.MaxInstructionsBytes)
 }
 
 func TestSizeOptions_Validate(t *testing.T) {
@@ -96,6 +99,11 @@ func TestSizeOptions_Validate(t *testing.T) {
 			opts:    SizeOptions{MaxFailureBytes: -1},
 			wantErr: true,
 		},
+		{
+			name:    "negative max instructions bytes",
+			opts:    SizeOptions{MaxInstructionsBytes: -1},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -471,3 +479,167 @@ func TestBuilderBuildUserPrompt_WithoutAgentsContent(t *testing.T) {
 	// Should not include AGENTS.md section when content is empty
 	assert.NotContains(t, prompt, "### Existing AGENTS.md")
 }
+
+func TestBuilderBuildUserPrompt_CustomInstructions(t *testing.T) {
+	task := &taskstore.Task{
+		ID:          "test-task",
+		Title:       "Test Task",
+		Description: "Test description",
+		Status:      taskstore.StatusOpen,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	tests := []struct {
+		name         string
+		instructions string
+		wantSection  bool
+	}{
+		{name: "appends instructions", instructions: "Prefer table-driven tests.\n", wantSection: true},
+		{name: "omits empty instructions", instructions: "", wantSection: false},
+		{name: "omits whitespace-only instructions", instructions: "  \n\t", wantSection: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt, err := NewBuilder(nil).BuildUserPrompt(IterationContext{
+				Task:               task,
+				CustomInstructions: tt.instructions,
+			})
+			require.NoError(t, err)
+
+			if tt.wantSection {
+				assert.Contains(t, prompt, "### Project Instructions\nPrefer table-driven tests.")
+				assert.Greater(t, strings.Index(prompt, "### Project Instructions"), strings.Index(prompt, "### Instructions"))
+			} else {
+				assert.NotContains(t, prompt, "### Project Instructions")
+			}
+		})
+	}
+}
+
+func TestBuilderBuildUserPrompt_CustomInstructionsLimit(t *testing.T) {
+	task := &taskstore.Task{ID: "test-task", Title: "Test Task", Status: taskstore.StatusOpen}
+	instructions := strings.Repeat("a", 3000)
+
+	// Instructions longer than the patterns budget are kept whole.
+	prompt, err := NewBuilder(nil).BuildUserPrompt(IterationContext{Task: task, CustomInstructions: instructions})
+	require.NoError(t, err)
+	assert.Contains(t, prompt, instructions+"\n")
+
+	opts := DefaultSizeOptions()
+	opts.MaxInstructionsBytes = 100
+	prompt, err = NewBuilder(&opts).BuildUserPrompt(IterationContext{Task: task, CustomInstructions: instructions})
+	require.NoError(t, err)
+	assert.Contains(t, prompt, strings.Repeat("a", 100)+"... [truncated]")
+}
+
+func TestBuilderBuildUserPrompt_Checkpoints(t *testing.T) {
+	task := &taskstore.Task{
+		ID:        "test-task",
+		Title:     "Test Task",
+		Status:    taskstore.StatusOpen,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	prompt, err := NewBuilder(nil).BuildUserPrompt(IterationContext{Task: task})
+	require.NoError(t, err)
+	assert.NotContains(t, prompt, "RALPH_CHECKPOINT")
+
+	prompt, err = NewBuilder(nil).BuildUserPrompt(IterationContext{Task: task, CheckpointsEnabled: true})
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "RALPH_CHECKPOINT: <short summary>")
+}
+
+func TestBuilderBuildUserPrompt_AllowedPaths(t *testing.T) {
+	task := &taskstore.Task{
+		ID:           "test-task",
+		Title:        "Test Task",
+		Status:       taskstore.StatusOpen,
+		AllowedPaths: []string{"internal/foo/**"},
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	prompt, err := NewBuilder(nil).BuildUserPrompt(IterationContext{Task: task})
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "### Allowed Paths\n")
+	assert.Contains(t, prompt, "- `internal/foo/**`\n")
+
+	task.AllowedPaths = nil
+	prompt, err = NewBuilder(nil).BuildUserPrompt(IterationContext{Task: task})
+	require.NoError(t, err)
+	assert.NotContains(t, prompt, "### Allowed Paths")
+}
+
+func TestBuilderBuildUserPrompt_ContextFiles(t *testing.T) {
+	task := &taskstore.Task{
+		ID:        "test-task",
+		Title:     "Test Task",
+		Status:    taskstore.StatusOpen,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name        string
+		opts        SizeOptions
+		files       []ContextFile
+		wantContain []string
+		wantMissing []string
+	}{
+		{
+			name:  "includes file contents before the instructions",
+			opts:  DefaultSizeOptions(),
+			files: []ContextFile{{Path: "internal/selector/graph.go", Content: "package selector"}},
+			wantContain: []string{
+				"### Reference Files\n",
+				"#### internal/selector/graph.go\n```\npackage selector\n```\n",
+			},
+		},
+		{
+			name:        "truncates each file",
+			opts:        SizeOptions{MaxPromptBytes: 8000, MaxContextFileBytes: 5},
+			files:       []ContextFile{{Path: "a.go", Content: "package a"}},
+			wantContain: []string{"packa... [truncated]"},
+			wantMissing: []string{"package a"},
+		},
+		{
+			name:        "notes files that could not be read",
+			opts:        DefaultSizeOptions(),
+			files:       []ContextFile{{Path: "missing.go", Err: "file not found"}},
+			wantContain: []string{"#### missing.go\n(file not found)\n"},
+		},
+		{
+			name: "lists files beyond the prompt budget by path",
+			opts: SizeOptions{MaxPromptBytes: 1000, MaxContextFileBytes: 2000},
+			files: []ContextFile{
+				{Path: "small.go", Content: "package small"},
+				{Path: "large.go", Content: strings.Repeat("x", 1500)},
+			},
+			wantContain: []string{"#### small.go", "Not included (prompt size limit), read them yourself:\n- `large.go`\n"},
+			wantMissing: []string{"#### large.go", "xxxxxxxxxx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			prompt, err := NewBuilder(&opts).BuildUserPrompt(IterationContext{Task: task, ContextFiles: tt.files})
+			require.NoError(t, err)
+
+			for _, want := range tt.wantContain {
+				assert.Contains(t, prompt, want)
+			}
+			for _, missing := range tt.wantMissing {
+				assert.NotContains(t, prompt, missing)
+			}
+			assert.Less(t, strings.Index(prompt, "### Reference Files"), strings.Index(prompt, "### Instructions"))
+		})
+	}
+
+	prompt, err := NewBuilder(nil).BuildUserPrompt(IterationContext{Task: task})
+	require.NoError(t, err)
+	assert.NotContains(t, prompt, "### Reference Files")
+}
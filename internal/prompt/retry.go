@@ -25,6 +25,12 @@ type RetryContext struct {
 	// AttemptNumber is the retry attempt number (1-indexed).
 	// 0 means not set.
 	AttemptNumber int
+
+	// DiffStat is the diff stat of uncommitted changes left by the previous attempt.
+	DiffStat string
+
+	// ChangedFiles lists files with uncommitted changes left by the previous attempt.
+	ChangedFiles []string
 }
 
 // BuildRetrySystemPrompt builds the system prompt for retry iterations.
@@ -97,6 +103,24 @@ func (b *Builder) BuildRetryPrompt(ctx RetryContext) (string, error) {
 		sb.WriteString("\n\n")
 	}
 
+	// Changes carried over from the previous attempt
+	if ctx.DiffStat != "" || len(ctx.ChangedFiles) > 0 {
+		sb.WriteString("### Changes From Previous Attempt\n\n")
+		sb.WriteString("The previous attempt's uncommitted changes are still in the working tree. Build on them rather than starting over.\n\n")
+		if ctx.DiffStat != "" {
+			sb.WriteString("```\n")
+			sb.WriteString(truncateWithMarker(ctx.DiffStat, b.opts.MaxDiffBytes))
+			sb.WriteString("\n```\n\n")
+		}
+		if len(ctx.ChangedFiles) > 0 {
+			sb.WriteString("Changed files:\n")
+			for _, f := range ctx.ChangedFiles {
+				_, _ = fmt.Fprintf(&sb, "- `%s`\n", f)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
 	// Task description (for context)
 	sb.WriteString("### Task Description\n\n")
 	sb.WriteString(ctx.Task.Description)
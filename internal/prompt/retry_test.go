@@ -292,3 +292,22 @@ func TestBuildRetrySystemPrompt_DiffersFromRegular(t *testing.T) {
 	// Retry prompt should be more focused
 	assert.Contains(t, strings.ToLower(retryPrompt), "retry")
 }
+
+func TestBuildRetryPrompt_WithPreviousChanges(t *testing.T) {
+	builder := NewBuilder(nil)
+	task := &taskstore.Task{ID: "test-task", Title: "Test Task", Description: "A test task"}
+
+	prompt, err := builder.BuildRetryPrompt(RetryContext{Task: task})
+	require.NoError(t, err)
+	assert.NotContains(t, prompt, "Changes From Previous Attempt")
+
+	prompt, err = builder.BuildRetryPrompt(RetryContext{
+		Task:         task,
+		DiffStat:     " parser.go | 10 +++++-----",
+		ChangedFiles: []string{"parser.go"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, prompt, "### Changes From Previous Attempt")
+	assert.Contains(t, prompt, "parser.go | 10")
+	assert.Contains(t, prompt, "- `parser.go`")
+}
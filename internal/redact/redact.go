@@ -0,0 +1,96 @@
+// Package redact removes secret values from text before Ralph stores it.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Placeholder replaces each secret value.
+const Placeholder = "[REDACTED]"
+
+// Redactor replaces known secret values in text. A nil Redactor leaves text unchanged.
+type Redactor struct {
+	secrets []string
+}
+
+// New returns a Redactor for the given values. Empty values are ignored.
+// Values are also matched in their JSON-escaped form, as they appear in NDJSON logs.
+func New(values ...string) *Redactor {
+	var secrets []string
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		secrets = append(secrets, v)
+		if escaped, err := json.Marshal(v); err == nil {
+			if s := strings.Trim(string(escaped), `"`); s != v {
+				secrets = append(secrets, s)
+			}
+		}
+	}
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	// Replace longer values first so a secret containing another is fully removed
+	slices.SortFunc(secrets, func(a, b string) int { return len(b) - len(a) })
+	return &Redactor{secrets: slices.Compact(secrets)}
+}
+
+// String returns s with every secret value replaced by Placeholder.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Placeholder)
+	}
+	return s
+}
+
+// Writer returns a writer that redacts each complete line before writing it
+// to w, so a secret split across writes is still found. Close writes any
+// trailing partial line; it does not close w. A nil Redactor writes through.
+func (r *Redactor) Writer(w io.Writer) io.WriteCloser {
+	return &lineWriter{redactor: r, w: w}
+}
+
+// lineWriter buffers partial lines for Redactor.Writer.
+type lineWriter struct {
+	redactor *Redactor
+	w        io.Writer
+	buf      []byte
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	if lw.redactor == nil {
+		return lw.w.Write(p)
+	}
+
+	lw.buf = append(lw.buf, p...)
+	end := bytes.LastIndexByte(lw.buf, '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+
+	lines := lw.redactor.String(string(lw.buf[:end+1]))
+	lw.buf = lw.buf[end+1:]
+	if _, err := io.WriteString(lw.w, lines); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (lw *lineWriter) Close() error {
+	if len(lw.buf) == 0 {
+		return nil
+	}
+	rest := lw.redactor.String(string(lw.buf))
+	lw.buf = nil
+	_, err := io.WriteString(lw.w, rest)
+	return err
+}
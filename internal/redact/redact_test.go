@@ -0,0 +1,63 @@
+package redact
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		input  string
+		want   string
+	}{
+		{name: "replaces every occurrence", values: []string{"tok123"}, input: "a tok123 b tok123", want: "a [REDACTED] b [REDACTED]"},
+		{name: "longer values first", values: []string{"abc", "abcdef"}, input: "x=abcdef", want: "x=[REDACTED]"},
+		{name: "JSON-escaped form", values: []string{`p"w\d`}, input: `{"v":"p\"w\\d"}`, want: `{"v":"[REDACTED]"}`},
+		{name: "text without secrets", values: []string{"tok123"}, input: "nothing here", want: "nothing here"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, New(tt.values...).String(tt.input))
+		})
+	}
+}
+
+func TestNew_NoValues(t *testing.T) {
+	r := New("", "")
+
+	assert.Nil(t, r)
+	assert.Equal(t, "unchanged", r.String("unchanged"))
+}
+
+func TestRedactor_Writer(t *testing.T) {
+	t.Run("redacts secrets split across writes", func(t *testing.T) {
+		var out bytes.Buffer
+		w := New("tok123").Writer(&out)
+
+		for _, chunk := range []string{"line one to", "k123\nline two tok", "123"} {
+			_, err := w.Write([]byte(chunk))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, "line one [REDACTED]\n", out.String())
+
+		require.NoError(t, w.Close())
+		assert.Equal(t, "line one [REDACTED]\nline two [REDACTED]", out.String())
+	})
+
+	t.Run("nil redactor writes through", func(t *testing.T) {
+		var out bytes.Buffer
+		var r *Redactor
+		w := r.Writer(&out)
+
+		_, err := w.Write([]byte("partial"))
+		require.NoError(t, err)
+		assert.Equal(t, "partial", out.String())
+		require.NoError(t, w.Close())
+	})
+}
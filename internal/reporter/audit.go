@@ -0,0 +1,116 @@
+package reporter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// ScopeAudit compares the files a task planned to touch with the files it changed.
+type ScopeAudit struct {
+	// TaskID is the audited task.
+	TaskID string
+
+	// Title is the task title.
+	Title string
+
+	// Planned lists file paths declared in the task description and acceptance criteria.
+	Planned []string
+
+	// Changed lists files changed by the task's successful iterations.
+	Changed []string
+
+	// Undeclared lists changed files the task did not declare.
+	Undeclared []string
+
+	// Skipped lists declared files the task did not change.
+	Skipped []string
+}
+
+// HasDrift reports whether the task's changes differ from its plan.
+func (a *ScopeAudit) HasDrift() bool {
+	return len(a.Undeclared) > 0 || len(a.Skipped) > 0
+}
+
+// AuditTaskScope compares a task's planned file paths with the files changed by
+// its successful iterations. Files under Ralph's own state directory are ignored.
+func AuditTaskScope(store taskstore.Store, logsDir, taskID string) (*ScopeAudit, error) {
+	task, err := store.Get(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	records, err := loop.LoadAllIterationRecords(logsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load iteration records: %w", err)
+	}
+
+	var changed []string
+	found := false
+	for _, record := range records {
+		if record.TaskID != taskID || record.Outcome != loop.OutcomeSuccess {
+			continue
+		}
+		found = true
+		for _, file := range record.FilesChanged {
+			if strings.HasPrefix(file, state.RalphDir+"/") || slices.Contains(changed, file) {
+				continue
+			}
+			changed = append(changed, file)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("task %s has no successful iterations to audit", taskID)
+	}
+
+	audit := &ScopeAudit{
+		TaskID:  task.ID,
+		Title:   task.Title,
+		Planned: taskstore.PlannedFiles(task),
+		Changed: changed,
+	}
+	for _, file := range audit.Changed {
+		if !slices.Contains(audit.Planned, file) {
+			audit.Undeclared = append(audit.Undeclared, file)
+		}
+	}
+	for _, file := range audit.Planned {
+		if !slices.Contains(audit.Changed, file) {
+			audit.Skipped = append(audit.Skipped, file)
+		}
+	}
+
+	return audit, nil
+}
+
+// FormatScopeAudit formats a scope audit for display.
+func FormatScopeAudit(audit *ScopeAudit) string {
+	var sb strings.Builder
+
+	_, _ = fmt.Fprintf(&sb, "Task: %s (%s)\n", audit.TaskID, audit.Title)
+	_, _ = fmt.Fprintf(&sb, "Planned: %d file(s), changed: %d file(s)\n", len(audit.Planned), len(audit.Changed))
+
+	if !audit.HasDrift() {
+		sb.WriteString("\nNo scope drift: changes match the planned files.\n")
+		return sb.String()
+	}
+
+	if len(audit.Undeclared) > 0 {
+		sb.WriteString("\nChanged but not planned:\n")
+		for _, file := range audit.Undeclared {
+			_, _ = fmt.Fprintf(&sb, "  + %s\n", file)
+		}
+	}
+	if len(audit.Skipped) > 0 {
+		sb.WriteString("\nPlanned but not changed:\n")
+		for _, file := range audit.Skipped {
+			_, _ = fmt.Fprintf(&sb, "  - %s\n", file)
+		}
+	}
+
+	return sb.String()
+}
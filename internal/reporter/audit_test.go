@@ -0,0 +1,70 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestAuditTaskScope(t *testing.T) {
+	logsDir := t.TempDir()
+	store := &mockStore{tasks: []*taskstore.Task{
+		{
+			ID:          "task-1",
+			Title:       "Add fix command",
+			Description: "Create cmd/fix.go and modify internal/state/state.go",
+			Acceptance:  []string{"cmd/fix_test.go covers the command"},
+		},
+		{ID: "task-2", Title: "Not run", Description: "Create cmd/other.go"},
+	}}
+
+	records := []*loop.IterationRecord{
+		{IterationID: "iter-1", TaskID: "task-1", Outcome: loop.OutcomeFailed, FilesChanged: []string{"internal/unrelated.go"}},
+		{IterationID: "iter-2", TaskID: "task-1", Outcome: loop.OutcomeSuccess, FilesChanged: []string{"cmd/fix.go", "cmd/fix_test.go", "cmd/root.go", ".ralph/progress.md"}},
+	}
+	for _, r := range records {
+		_, err := loop.SaveRecord(logsDir, r)
+		require.NoError(t, err)
+	}
+
+	t.Run("reports undeclared and skipped files", func(t *testing.T) {
+		audit, err := AuditTaskScope(store, logsDir, "task-1")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"cmd/fix.go", "internal/state/state.go", "cmd/fix_test.go"}, audit.Planned)
+		assert.Equal(t, []string{"cmd/fix.go", "cmd/fix_test.go", "cmd/root.go"}, audit.Changed)
+		assert.Equal(t, []string{"cmd/root.go"}, audit.Undeclared)
+		assert.Equal(t, []string{"internal/state/state.go"}, audit.Skipped)
+		assert.True(t, audit.HasDrift())
+
+		out := FormatScopeAudit(audit)
+		assert.Contains(t, out, "+ cmd/root.go")
+		assert.Contains(t, out, "- internal/state/state.go")
+	})
+
+	t.Run("fails without successful iterations", func(t *testing.T) {
+		_, err := AuditTaskScope(store, logsDir, "task-2")
+		assert.Error(t, err)
+	})
+
+	t.Run("fails for unknown task", func(t *testing.T) {
+		_, err := AuditTaskScope(store, logsDir, "missing")
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatScopeAudit_NoDrift(t *testing.T) {
+	audit := &ScopeAudit{
+		TaskID:  "task-1",
+		Title:   "Task 1",
+		Planned: []string{"cmd/fix.go"},
+		Changed: []string{"cmd/fix.go"},
+	}
+
+	assert.False(t, audit.HasDrift())
+	assert.Contains(t, FormatScopeAudit(audit), "No scope drift")
+}
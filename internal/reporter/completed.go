@@ -0,0 +1,199 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// FileHasher returns the git blob hash of a file at a commit, or in the
+// working tree when ref is empty ("" if the file does not exist there).
+type FileHasher interface {
+	FileHash(ctx context.Context, ref, path string) (string, error)
+}
+
+// UndoneFile is a file whose change by a completed task appears undone.
+type UndoneFile struct {
+	// Path is the file path relative to the repository root.
+	Path string
+
+	// Reason describes how the change was undone.
+	Reason string
+}
+
+// CompletedCheck is the result of checking one completed task's files.
+type CompletedCheck struct {
+	// TaskID is the checked task.
+	TaskID string
+
+	// Title is the task title.
+	Title string
+
+	// IterationID is the successful iteration whose files were checked.
+	IterationID string
+
+	// Files is the number of files checked.
+	Files int
+
+	// Undone lists the files whose change appears undone.
+	Undone []UndoneFile
+}
+
+// CompletedVerification is the result of checking every completed task.
+type CompletedVerification struct {
+	// Checks lists the completed tasks with a successful iteration, by task ID.
+	Checks []CompletedCheck
+
+	// Unchecked lists completed tasks with no successful iteration record
+	// (e.g. completed by hand), whose files are unknown.
+	Unchecked []string
+}
+
+// UndoneTasks returns the checks that found undone files.
+func (v *CompletedVerification) UndoneTasks() []CompletedCheck {
+	var undone []CompletedCheck
+	for _, c := range v.Checks {
+		if len(c.Undone) > 0 {
+			undone = append(undone, c)
+		}
+	}
+	return undone
+}
+
+// VerifyCompleted checks, for each completed task, whether the files changed by
+// the iteration that completed it still reflect that work. A created or
+// modified file that no longer exists, a deleted file that is back, and a file
+// whose content is back to what it was before the task all count as undone.
+// Later edits on top of the task's work do not. Files under Ralph's own state
+// directory are ignored.
+func VerifyCompleted(ctx context.Context, store taskstore.Store, logsDir string, hasher FileHasher) (*CompletedVerification, error) {
+	tasks, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	records, err := loop.LoadAllIterationRecords(logsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load iteration records: %w", err)
+	}
+	latest := make(map[string]*loop.IterationRecord)
+	for _, r := range records {
+		if r.Outcome != loop.OutcomeSuccess {
+			continue
+		}
+		if prev, ok := latest[r.TaskID]; !ok || r.StartTime.After(prev.StartTime) {
+			latest[r.TaskID] = r
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	result := &CompletedVerification{}
+	for _, task := range tasks {
+		if task.Status != taskstore.StatusCompleted {
+			continue
+		}
+		record, ok := latest[task.ID]
+		if !ok {
+			result.Unchecked = append(result.Unchecked, task.ID)
+			continue
+		}
+
+		check, err := checkCompletedRecord(ctx, hasher, record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", task.ID, err)
+		}
+		check.TaskID = task.ID
+		check.Title = task.Title
+		result.Checks = append(result.Checks, *check)
+	}
+
+	return result, nil
+}
+
+// checkCompletedRecord checks the files changed by a successful iteration.
+func checkCompletedRecord(ctx context.Context, hasher FileHasher, record *loop.IterationRecord) (*CompletedCheck, error) {
+	changes := record.FileChanges
+	if len(changes) == 0 {
+		// Records written before statuses were tracked only list paths
+		for _, path := range record.FilesChanged {
+			changes = append(changes, git.FileChange{Path: path, Status: git.FileModified})
+		}
+	}
+
+	check := &CompletedCheck{IterationID: record.IterationID}
+	for _, change := range changes {
+		if strings.HasPrefix(change.Path, state.RalphDir+"/") {
+			continue
+		}
+		check.Files++
+
+		current, err := hasher.FileHash(ctx, "", change.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if change.Status == git.FileDeleted {
+			if current != "" {
+				check.Undone = append(check.Undone, UndoneFile{Path: change.Path, Reason: "restored after the task deleted it"})
+			}
+			continue
+		}
+		if current == "" {
+			check.Undone = append(check.Undone, UndoneFile{Path: change.Path, Reason: "deleted since the task completed"})
+			continue
+		}
+		if reverted(ctx, hasher, record, change.Path, current) {
+			check.Undone = append(check.Undone, UndoneFile{Path: change.Path, Reason: "reverted to its content before the task"})
+		}
+	}
+
+	return check, nil
+}
+
+// reverted reports whether path's current content matches its content before
+// the iteration but not after it. Commits that no longer exist (e.g. after a
+// rebase) cannot be compared, so the file is not reported.
+func reverted(ctx context.Context, hasher FileHasher, record *loop.IterationRecord, path, current string) bool {
+	if record.BaseCommit == "" || record.ResultCommit == "" {
+		return false
+	}
+	before, err := hasher.FileHash(ctx, record.BaseCommit, path)
+	if err != nil || before == "" {
+		return false
+	}
+	after, err := hasher.FileHash(ctx, record.ResultCommit, path)
+	if err != nil {
+		return false
+	}
+	return current == before && current != after
+}
+
+// FormatCompletedVerification formats the result of VerifyCompleted for display.
+func FormatCompletedVerification(v *CompletedVerification) string {
+	var sb strings.Builder
+
+	_, _ = fmt.Fprintf(&sb, "Checked %d completed task(s)\n", len(v.Checks))
+
+	undone := v.UndoneTasks()
+	if len(undone) == 0 {
+		sb.WriteString("\nNo completed work appears undone.\n")
+	}
+	for _, c := range undone {
+		_, _ = fmt.Fprintf(&sb, "\n⚠ %s (%s): %d of %d file(s) appear undone\n", c.TaskID, c.Title, len(c.Undone), c.Files)
+		for _, f := range c.Undone {
+			_, _ = fmt.Fprintf(&sb, "  - %s: %s\n", f.Path, f.Reason)
+		}
+	}
+
+	if len(v.Unchecked) > 0 {
+		_, _ = fmt.Fprintf(&sb, "\nNot checked (no successful iteration): %s\n", strings.Join(v.Unchecked, ", "))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,131 @@
+package reporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// mockHasher maps "ref:path" to a blob hash; missing keys are missing files.
+type mockHasher struct {
+	hashes  map[string]string
+	badRefs map[string]bool
+}
+
+func (m *mockHasher) FileHash(ctx context.Context, ref, path string) (string, error) {
+	if m.badRefs[ref] {
+		return "", errors.New("unknown revision")
+	}
+	return m.hashes[ref+":"+path], nil
+}
+
+func TestVerifyCompleted(t *testing.T) {
+	logsDir := t.TempDir()
+	store := &mockStore{tasks: []*taskstore.Task{
+		{ID: "intact", Title: "Intact work", Status: taskstore.StatusCompleted},
+		{ID: "undone", Title: "Undone work", Status: taskstore.StatusCompleted},
+		{ID: "manual", Title: "Completed by hand", Status: taskstore.StatusCompleted},
+		{ID: "open", Title: "Not done", Status: taskstore.StatusOpen},
+	}}
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []*loop.IterationRecord{
+		{
+			IterationID: "iter-intact", TaskID: "intact", Outcome: loop.OutcomeSuccess, StartTime: start,
+			BaseCommit: "base1", ResultCommit: "result1",
+			FileChanges: []git.FileChange{
+				{Path: "edited-later.go", Status: git.FileModified},
+				{Path: "removed.go", Status: git.FileDeleted},
+				{Path: ".ralph/progress.md", Status: git.FileModified},
+			},
+		},
+		{
+			IterationID: "iter-undone-failed", TaskID: "undone", Outcome: loop.OutcomeFailed, StartTime: start,
+			FilesChanged: []string{"never.go"},
+		},
+		{
+			IterationID: "iter-undone-old", TaskID: "undone", Outcome: loop.OutcomeSuccess, StartTime: start.Add(time.Minute),
+			FilesChanged: []string{"old.go"},
+		},
+		{
+			IterationID: "iter-undone", TaskID: "undone", Outcome: loop.OutcomeSuccess, StartTime: start.Add(2 * time.Minute),
+			BaseCommit: "base2", ResultCommit: "result2",
+			FileChanges: []git.FileChange{
+				{Path: "created.go", Status: git.FileAdded},
+				{Path: "reverted.go", Status: git.FileModified},
+				{Path: "dropped.go", Status: git.FileDeleted},
+				{Path: "kept.go", Status: git.FileModified},
+			},
+		},
+	}
+	for _, r := range records {
+		_, err := loop.SaveRecord(logsDir, r)
+		require.NoError(t, err)
+	}
+
+	hasher := &mockHasher{hashes: map[string]string{
+		":edited-later.go":        "v3",
+		"base1:edited-later.go":   "v1",
+		"result1:edited-later.go": "v2",
+		":reverted.go":            "r1",
+		"base2:reverted.go":       "r1",
+		"result2:reverted.go":     "r2",
+		":dropped.go":             "d1",
+		":kept.go":                "k2",
+		"base2:kept.go":           "k1",
+		"result2:kept.go":         "k2",
+		":old.go":                 "o1",
+	}}
+
+	v, err := VerifyCompleted(context.Background(), store, logsDir, hasher)
+	require.NoError(t, err)
+
+	require.Len(t, v.Checks, 2)
+	assert.Equal(t, "intact", v.Checks[0].TaskID)
+	assert.Equal(t, 2, v.Checks[0].Files)
+	assert.Empty(t, v.Checks[0].Undone)
+
+	undone := v.UndoneTasks()
+	require.Len(t, undone, 1)
+	assert.Equal(t, "undone", undone[0].TaskID)
+	assert.Equal(t, "iter-undone", undone[0].IterationID)
+	assert.Equal(t, []UndoneFile{
+		{Path: "created.go", Reason: "deleted since the task completed"},
+		{Path: "reverted.go", Reason: "reverted to its content before the task"},
+		{Path: "dropped.go", Reason: "restored after the task deleted it"},
+	}, undone[0].Undone)
+	assert.Equal(t, []string{"manual"}, v.Unchecked)
+
+	out := FormatCompletedVerification(v)
+	assert.Contains(t, out, "Checked 2 completed task(s)")
+	assert.Contains(t, out, "⚠ undone (Undone work): 3 of 4 file(s) appear undone")
+	assert.Contains(t, out, "  - reverted.go: reverted to its content before the task")
+	assert.Contains(t, out, "Not checked (no successful iteration): manual")
+
+	t.Run("missing commits skip the content comparison", func(t *testing.T) {
+		hasher.badRefs = map[string]bool{"base2": true}
+		defer func() { hasher.badRefs = nil }()
+
+		v, err := VerifyCompleted(context.Background(), store, logsDir, hasher)
+		require.NoError(t, err)
+
+		undone := v.UndoneTasks()
+		require.Len(t, undone, 1)
+		assert.Len(t, undone[0].Undone, 2)
+	})
+}
+
+func TestFormatCompletedVerification_NothingUndone(t *testing.T) {
+	out := FormatCompletedVerification(&CompletedVerification{Checks: []CompletedCheck{{TaskID: "a", Files: 1}}})
+
+	assert.Contains(t, out, "No completed work appears undone.")
+	assert.NotContains(t, out, "Not checked")
+}
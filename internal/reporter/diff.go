@@ -0,0 +1,90 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/loop"
+)
+
+// maxVerifyOutputLines caps how much of a verify command's output an
+// iteration diff shows.
+const maxVerifyOutputLines = 20
+
+// FormatIterationDiff summarizes what an iteration changed (outcome, cost,
+// changed files and verification results) followed by diff, the patch from
+// its base commit to its result commit, or to the working tree when it made
+// no result commit. Output is shown for verify commands that did not pass,
+// trimmed to the last lines.
+func FormatIterationDiff(record *loop.IterationRecord, diff string) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "Iteration %s (task %s): %s\n", record.IterationID, record.TaskID, record.Outcome)
+	if record.ResultCommit != "" {
+		_, _ = fmt.Fprintf(&sb, "Commits: %s..%s\n", shortCommit(record.BaseCommit), shortCommit(record.ResultCommit))
+	} else {
+		_, _ = fmt.Fprintf(&sb, "Commits: %s..working tree (no result commit)\n", shortCommit(record.BaseCommit))
+	}
+	if cost := record.ClaudeInvocation.TotalCostUSD; cost > 0 {
+		_, _ = fmt.Fprintf(&sb, "Cost: $%.4f\n", cost)
+	}
+
+	if len(record.FilesChanged) > 0 {
+		status := make(map[string]git.FileStatus, len(record.FileChanges))
+		for _, c := range record.FileChanges {
+			status[c.Path] = c.Status
+		}
+		_, _ = fmt.Fprintf(&sb, "\nFiles changed (%d):\n", len(record.FilesChanged))
+		for _, file := range record.FilesChanged {
+			if s, ok := status[file]; ok {
+				_, _ = fmt.Fprintf(&sb, "  - %s (%s)\n", file, s)
+			} else {
+				_, _ = fmt.Fprintf(&sb, "  - %s\n", file)
+			}
+		}
+	}
+
+	if len(record.VerificationOutputs) > 0 {
+		sb.WriteString("\nVerification:\n")
+		for _, vo := range record.VerificationOutputs {
+			_, _ = fmt.Fprintf(&sb, "  - %s - %s\n", strings.Join(vo.Command, " "), vo.Status())
+			if vo.Passed && !vo.Warning {
+				continue
+			}
+			for _, line := range lastLines(strings.TrimRight(vo.Output, "\n"), maxVerifyOutputLines) {
+				_, _ = fmt.Fprintf(&sb, "      %s\n", line)
+			}
+		}
+	}
+
+	sb.WriteString("\n")
+	if diff == "" {
+		sb.WriteString("No differences.\n")
+		return sb.String()
+	}
+	sb.WriteString(diff)
+	if !strings.HasSuffix(diff, "\n") {
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// lastLines returns the last n lines of s, or none when s is empty.
+func lastLines(s string, n int) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// shortCommit abbreviates a commit hash for display.
+func shortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,50 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/loop"
+)
+
+func TestFormatIterationDiff(t *testing.T) {
+	var longOutput strings.Builder
+	for i := 1; i <= 30; i++ {
+		_, _ = fmt.Fprintf(&longOutput, "line %d\n", i)
+	}
+	record := &loop.IterationRecord{
+		IterationID:  "iter-1",
+		TaskID:       "task-a",
+		Outcome:      loop.OutcomeFailed,
+		BaseCommit:   "0123456789abcdef",
+		FilesChanged: []string{"a.go", "b.go"},
+		FileChanges:  []git.FileChange{{Path: "a.go", Status: git.FileAdded}},
+		VerificationOutputs: []loop.VerificationOutput{
+			{Command: []string{"go", "vet"}, Passed: true, Output: "vet output"},
+			{Command: []string{"go", "test"}, Passed: false, Output: longOutput.String()},
+		},
+	}
+
+	out := FormatIterationDiff(record, "diff --git a/a.go b/a.go")
+
+	assert.Contains(t, out, "Iteration iter-1 (task task-a): failed\n")
+	assert.Contains(t, out, "Commits: 0123456..working tree (no result commit)\n")
+	assert.NotContains(t, out, "Cost:", "no cost recorded")
+	assert.Contains(t, out, "Files changed (2):\n  - a.go (added)\n  - b.go\n")
+	assert.Contains(t, out, "  - go vet - PASS\n  - go test - FAIL\n")
+	assert.NotContains(t, out, "vet output", "output is shown for failures only")
+	assert.NotContains(t, out, "line 10\n")
+	assert.Contains(t, out, "      line 11\n")
+	assert.Contains(t, out, "      line 30\n")
+	assert.True(t, strings.HasSuffix(out, "\ndiff --git a/a.go b/a.go\n"))
+
+	record.ResultCommit = "fedcba9876543210"
+	record.VerificationOutputs = nil
+	out = FormatIterationDiff(record, "")
+	assert.Contains(t, out, "Commits: 0123456..fedcba9\n")
+	assert.True(t, strings.HasSuffix(out, "\nNo differences.\n"))
+}
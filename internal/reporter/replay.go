@@ -0,0 +1,157 @@
+package reporter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// replayTimeLayout is how iteration start times are shown in a replay.
+const replayTimeLayout = "15:04:05"
+
+// FormatReplay narrates the run with ID runID from the stored records: each
+// iteration in order with the task selected, why it was selectable, how it
+// ended, its cost and the files it changed. Selection reasons are
+// reconstructed from the records (which tasks had completed by then) and the
+// tasks' dependencies; tasks may be nil. It returns an error when no record
+// belongs to the run.
+func FormatReplay(runID string, records []*loop.IterationRecord, tasks []*taskstore.Task) (string, error) {
+	var run []*loop.IterationRecord
+	for _, r := range records {
+		if r.SessionID == runID {
+			run = append(run, r)
+		}
+	}
+	if len(run) == 0 {
+		return "", fmt.Errorf("run %q not found (see `ralph runs`)", runID)
+	}
+	slices.SortStableFunc(run, func(a, b *loop.IterationRecord) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	runStart := run[0].StartTime
+
+	byID := make(map[string]*taskstore.Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+
+	// Tasks completed before the run began, from earlier runs' records
+	completedBefore := make(map[string]bool)
+	for _, r := range records {
+		if r.SessionID != runID && r.Outcome == loop.OutcomeSuccess && r.EndTime.Before(runStart) {
+			completedBefore[r.TaskID] = true
+		}
+	}
+
+	summary := SummarizeSessions(run)[0]
+
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "Run %s", runID)
+	if summary.ParentTaskID != "" {
+		_, _ = fmt.Fprintf(&sb, " (parent: %s)", summary.ParentTaskID)
+	}
+	_, _ = fmt.Fprintf(&sb, "\nStarted %s, %d iteration(s), $%.2f, outcome: %s\n",
+		runStart.Format(runTimeLayout), summary.Iterations, summary.CostUSD, summary.Outcome)
+
+	completedAt := make(map[string]int) // task ID -> step that completed it in this run
+	attempts := make(map[string]int)
+	var lastCompleted *taskstore.Task
+	firstFailure := 0
+	for i, r := range run {
+		step := i + 1
+		attempts[r.TaskID]++
+		task := byID[r.TaskID]
+
+		_, _ = fmt.Fprintf(&sb, "\n#%d %s %s", step, r.StartTime.Format(replayTimeLayout), r.TaskID)
+		if task != nil && task.Title != "" {
+			_, _ = fmt.Fprintf(&sb, " %q", task.Title)
+		}
+		sb.WriteString("\n")
+
+		reasons := replayReasons(task, attempts[r.TaskID], completedAt, completedBefore, byID, lastCompleted)
+		_, _ = fmt.Fprintf(&sb, "   why:      %s\n", strings.Join(reasons, "; "))
+
+		outcome := fmt.Sprintf("%s in %s, $%.2f", r.Outcome, r.Duration().Round(time.Second), r.ClaudeInvocation.TotalCostUSD)
+		_, _ = fmt.Fprintf(&sb, "   outcome:  %s\n", outcome)
+		if len(r.FilesChanged) > 0 {
+			_, _ = fmt.Fprintf(&sb, "   files:    %s\n", strings.Join(r.FilesChanged, ", "))
+		}
+		if n := len(r.VerificationOutputs); n > 0 {
+			passed := 0
+			for _, vo := range r.VerificationOutputs {
+				if vo.Passed {
+					passed++
+				}
+			}
+			_, _ = fmt.Fprintf(&sb, "   verify:   %d/%d passed\n", passed, n)
+		} else if r.Unverified {
+			sb.WriteString("   verify:   none (task has no verify commands)\n")
+		}
+		if hash := r.ResultCommit; hash != "" {
+			if len(hash) > 7 {
+				hash = hash[:7]
+			}
+			_, _ = fmt.Fprintf(&sb, "   commit:   %s\n", hash)
+		}
+		if r.Outcome != loop.OutcomeSuccess && r.Feedback != "" {
+			feedback, _, _ := strings.Cut(strings.TrimSpace(r.Feedback), "\n")
+			_, _ = fmt.Fprintf(&sb, "   feedback: %s\n", feedback)
+		}
+
+		if r.Outcome == loop.OutcomeSuccess {
+			completedAt[r.TaskID] = step
+			if task != nil {
+				lastCompleted = task
+			}
+		} else if r.Outcome != loop.OutcomeCheckpoint && firstFailure == 0 {
+			firstFailure = step
+		}
+	}
+
+	sb.WriteString("\n")
+	if firstFailure > 0 {
+		r := run[firstFailure-1]
+		_, _ = fmt.Fprintf(&sb, "First failure at #%d: %s (%s)\n", firstFailure, r.TaskID, r.Outcome)
+	} else {
+		sb.WriteString("No failed iterations.\n")
+	}
+	return sb.String(), nil
+}
+
+// replayReasons explains why task was selectable at a step of a replayed run.
+func replayReasons(task *taskstore.Task, attempt int, completedAt map[string]int, completedBefore map[string]bool, byID map[string]*taskstore.Task, lastCompleted *taskstore.Task) []string {
+	var reasons []string
+	if attempt > 1 {
+		reasons = append(reasons, fmt.Sprintf("retry (attempt %d)", attempt))
+	}
+	if task == nil {
+		return append(reasons, "task no longer in the task store")
+	}
+
+	if len(task.DependsOn) == 0 {
+		reasons = append(reasons, "no dependencies")
+	} else {
+		deps := make([]string, 0, len(task.DependsOn))
+		for _, dep := range task.DependsOn {
+			switch {
+			case completedAt[dep] > 0:
+				deps = append(deps, fmt.Sprintf("%s (completed at #%d)", dep, completedAt[dep]))
+			case completedBefore[dep]:
+				deps = append(deps, fmt.Sprintf("%s (completed before this run)", dep))
+			case byID[dep] != nil:
+				deps = append(deps, fmt.Sprintf("%s (no completing iteration recorded; now %s)", dep, byID[dep].Status))
+			default:
+				deps = append(deps, fmt.Sprintf("%s (unknown)", dep))
+			}
+		}
+		reasons = append(reasons, "dependencies satisfied: "+strings.Join(deps, ", "))
+	}
+	if area := task.Labels["area"]; area != "" && lastCompleted != nil && lastCompleted.Labels["area"] == area {
+		reasons = append(reasons, fmt.Sprintf("same area as last completed task (%s)", area))
+	}
+	return reasons
+}
@@ -0,0 +1,77 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestFormatReplay(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	records := []*loop.IterationRecord{
+		{IterationID: "old", SessionID: "earlier", TaskID: "setup", StartTime: at(-60), EndTime: at(-50), Outcome: loop.OutcomeSuccess},
+		{
+			IterationID: "i3", SessionID: "run-1", ParentTaskID: "feature", TaskID: "api",
+			StartTime: at(20), EndTime: at(25), Outcome: loop.OutcomeFailed,
+			Feedback:         "go test failed\nmore detail",
+			ClaudeInvocation: loop.ClaudeInvocationMeta{TotalCostUSD: 0.5},
+		},
+		{
+			IterationID: "i1", SessionID: "run-1", ParentTaskID: "feature", TaskID: "model",
+			StartTime: at(0), EndTime: at(10), Outcome: loop.OutcomeSuccess,
+			FilesChanged:        []string{"model.go", "model_test.go"},
+			VerificationOutputs: []loop.VerificationOutput{{Command: []string{"go", "test"}, Passed: true}},
+			ResultCommit:        "abcdef1234567890",
+			ClaudeInvocation:    loop.ClaudeInvocationMeta{TotalCostUSD: 1},
+		},
+		{
+			IterationID: "i2", SessionID: "run-1", ParentTaskID: "feature", TaskID: "api",
+			StartTime: at(10), EndTime: at(20), Outcome: loop.OutcomeFailed,
+			Feedback:         "build failed",
+			ClaudeInvocation: loop.ClaudeInvocationMeta{TotalCostUSD: 0.5},
+		},
+	}
+	tasks := []*taskstore.Task{
+		{ID: "model", Title: "Add model", DependsOn: []string{"setup"}, Labels: map[string]string{"area": "core"}},
+		{ID: "api", Title: "Add API", DependsOn: []string{"model"}, Labels: map[string]string{"area": "core"}},
+	}
+
+	out, err := FormatReplay("run-1", records, tasks)
+	require.NoError(t, err)
+
+	assert.Equal(t, `Run run-1 (parent: feature)
+Started 2026-03-02 09:00, 3 iteration(s), $2.00, outcome: partial
+
+#1 09:00:00 model "Add model"
+   why:      dependencies satisfied: setup (completed before this run)
+   outcome:  success in 10m0s, $1.00
+   files:    model.go, model_test.go
+   verify:   1/1 passed
+   commit:   abcdef1
+
+#2 09:10:00 api "Add API"
+   why:      dependencies satisfied: model (completed at #1); same area as last completed task (core)
+   outcome:  failed in 10m0s, $0.50
+   feedback: build failed
+
+#3 09:20:00 api "Add API"
+   why:      retry (attempt 2); dependencies satisfied: model (completed at #1); same area as last completed task (core)
+   outcome:  failed in 5m0s, $0.50
+   feedback: go test failed
+
+First failure at #2: api (failed)
+`, out)
+}
+
+func TestFormatReplay_UnknownRun(t *testing.T) {
+	_, err := FormatReplay("missing", []*loop.IterationRecord{{SessionID: "run-1"}}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `run "missing" not found`)
+}
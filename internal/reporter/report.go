@@ -3,11 +3,14 @@ package reporter
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/yarlson/ralph/internal/git"
 	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
 	"github.com/yarlson/ralph/internal/taskstore"
 )
 
@@ -24,6 +27,9 @@ type CommitInfo struct {
 
 	// Timestamp is when the commit was created.
 	Timestamp time.Time
+
+	// Annotations is the --annotate metadata of the iteration that made the commit.
+	Annotations map[string]string
 }
 
 // TaskSummary contains summary information about a task.
@@ -36,6 +42,10 @@ type TaskSummary struct {
 
 	// Outcome is the iteration outcome for this task (if applicable).
 	Outcome string
+
+	// Unverified is true when the task's last successful iteration ran no
+	// verify commands because the task has none.
+	Unverified bool
 }
 
 // BlockedTaskSummary contains information about a blocked task with its reason.
@@ -50,6 +60,26 @@ type BlockedTaskSummary struct {
 	Reason string
 }
 
+// HighTouchTask is a task that needed a person's help to get through: it took
+// more than one attempt, was given feedback, or had its status changed by hand.
+type HighTouchTask struct {
+	// ID is the task identifier.
+	ID string
+
+	// Title is the task title.
+	Title string
+
+	// Attempts is the number of iterations run for the task.
+	Attempts int
+
+	// Feedback is true when feedback or a skip reason was written for the task.
+	Feedback bool
+
+	// ManualChanges lists the manual status changes (retry, skip, approve,
+	// undo) made to the task, oldest first.
+	ManualChanges []string
+}
+
 // Report contains the end-of-feature summary report.
 type Report struct {
 	// ParentTaskID is the ID of the parent task for this feature.
@@ -73,15 +103,32 @@ type Report struct {
 	// SkippedTasks lists all tasks that were skipped.
 	SkippedTasks []TaskSummary
 
+	// AwaitingReviewTasks lists tasks finished without verify commands that
+	// wait for a person to approve them.
+	AwaitingReviewTasks []TaskSummary
+
+	// HighTouchTasks lists the tasks that needed manual intervention, which
+	// hints at where the decomposition fell short.
+	HighTouchTasks []HighTouchTask
+
 	// TotalIterations is the total number of iterations run.
 	TotalIterations int
 
+	// VerifiedIterations is the number of iterations whose verification passed.
+	VerifiedIterations int
+
+	// VerifiedFirstAttempt is how many of those passed without in-iteration retries.
+	VerifiedFirstAttempt int
+
 	// TotalCostUSD is the total cost incurred.
 	TotalCostUSD float64
 
 	// TotalDuration is the total time spent.
 	TotalDuration time.Duration
 
+	// Sessions groups the iterations by the run they belonged to, oldest first.
+	Sessions []SessionSummary
+
 	// StartTime is when the first iteration started.
 	StartTime time.Time
 
@@ -93,6 +140,7 @@ type Report struct {
 type ReportGenerator struct {
 	taskStore  taskstore.Store
 	logsDir    string
+	stateDir   string
 	gitManager git.Manager
 }
 
@@ -105,6 +153,17 @@ func NewReportGenerator(store taskstore.Store, logsDir string, gitManager git.Ma
 	}
 }
 
+// NewReportGeneratorWithStateDir creates a new report generator with state
+// directory, whose feedback files and interventions log flag high-touch tasks.
+func NewReportGeneratorWithStateDir(store taskstore.Store, logsDir, stateDir string, gitManager git.Manager) *ReportGenerator {
+	return &ReportGenerator{
+		taskStore:  store,
+		logsDir:    logsDir,
+		stateDir:   stateDir,
+		gitManager: gitManager,
+	}
+}
+
 // GenerateReport creates a complete feature report for the given parent task.
 func (g *ReportGenerator) GenerateReport(parentTaskID string) (*Report, error) {
 	tasks, err := g.taskStore.List()
@@ -155,24 +214,42 @@ func (g *ReportGenerator) GenerateReport(parentTaskID string) (*Report, error) {
 				Title:   t.Title,
 				Outcome: string(t.Status),
 			})
+		case taskstore.StatusAwaitingReview:
+			report.AwaitingReviewTasks = append(report.AwaitingReviewTasks, TaskSummary{
+				ID:      t.ID,
+				Title:   t.Title,
+				Outcome: string(t.Status),
+			})
 		}
 	}
 
 	// Load iteration records for commits, costs, and timing
+	var records []*loop.IterationRecord
 	if g.logsDir != "" {
-		records, err := loop.LoadAllIterationRecords(g.logsDir)
+		loaded, err := loop.LoadAllIterationRecords(g.logsDir)
 		if err == nil {
+			records = loaded
 			report.TotalIterations = len(records)
 
 			for _, record := range records {
 				report.TotalCostUSD += record.ClaudeInvocation.TotalCostUSD
 
+				// Track how often verification passes without retries
+				if record.VerificationPassedOnAttempt > 0 {
+					report.VerifiedIterations++
+					if record.VerificationPassedOnAttempt == 1 {
+						report.VerifiedFirstAttempt++
+					}
+				}
+
 				// Track commits from successful iterations
 				if record.ResultCommit != "" {
 					commitInfo := CommitInfo{
 						Hash:      record.ResultCommit,
 						TaskID:    record.TaskID,
 						Timestamp: record.EndTime,
+
+						Annotations: record.Annotations,
 					}
 
 					// Fetch commit message if git manager is available
@@ -197,6 +274,9 @@ func (g *ReportGenerator) GenerateReport(parentTaskID string) (*Report, error) {
 				}
 			}
 
+			report.Sessions = SummarizeSessions(records)
+			markUnverified(report.CompletedTasks, records)
+
 			// Calculate total duration
 			if !report.StartTime.IsZero() && !report.EndTime.IsZero() {
 				report.TotalDuration = report.EndTime.Sub(report.StartTime)
@@ -204,9 +284,77 @@ func (g *ReportGenerator) GenerateReport(parentTaskID string) (*Report, error) {
 		}
 	}
 
+	report.HighTouchTasks = g.highTouchTasks(descendants, records)
+
 	return report, nil
 }
 
+// highTouchTasks returns the tasks among descendants that took more than one
+// attempt, were given feedback, or had their status changed by hand.
+func (g *ReportGenerator) highTouchTasks(descendants []*taskstore.Task, records []*loop.IterationRecord) []HighTouchTask {
+	attempts := make(map[string]int)
+	for _, r := range records {
+		attempts[r.TaskID]++
+	}
+
+	feedback := make(map[string]bool)
+	manual := make(map[string][]string)
+	if g.stateDir != "" {
+		// Feedback files are removed once their task succeeds, so the
+		// interventions log is the record of feedback given earlier
+		interventions, err := state.LoadInterventions(filepath.Join(g.stateDir, state.Interventions))
+		if err == nil {
+			for _, in := range interventions {
+				manual[in.TaskID] = append(manual[in.TaskID], in.Action)
+				if in.Feedback {
+					feedback[in.TaskID] = true
+				}
+			}
+		}
+		for _, t := range descendants {
+			for _, kind := range []string{state.FeedbackKindFeedback, state.FeedbackKindSkipReason} {
+				if _, err := os.Stat(filepath.Join(g.stateDir, fmt.Sprintf("%s-%s.txt", kind, t.ID))); err == nil {
+					feedback[t.ID] = true
+				}
+			}
+		}
+	}
+
+	var highTouch []HighTouchTask
+	for _, t := range descendants {
+		if attempts[t.ID] < 2 && !feedback[t.ID] && len(manual[t.ID]) == 0 {
+			continue
+		}
+		highTouch = append(highTouch, HighTouchTask{
+			ID:            t.ID,
+			Title:         t.Title,
+			Attempts:      attempts[t.ID],
+			Feedback:      feedback[t.ID],
+			ManualChanges: manual[t.ID],
+		})
+	}
+	return highTouch
+}
+
+// markUnverified flags the tasks whose last successful iteration ran without
+// verify commands.
+func markUnverified(tasks []TaskSummary, records []*loop.IterationRecord) {
+	last := make(map[string]*loop.IterationRecord)
+	for _, r := range records {
+		if r.Outcome != loop.OutcomeSuccess {
+			continue
+		}
+		if prev, ok := last[r.TaskID]; !ok || r.EndTime.After(prev.EndTime) {
+			last[r.TaskID] = r
+		}
+	}
+	for i := range tasks {
+		if r, ok := last[tasks[i].ID]; ok {
+			tasks[i].Unverified = r.Unverified
+		}
+	}
+}
+
 // gatherDescendants collects all descendant tasks of the given parent.
 func (g *ReportGenerator) gatherDescendants(tasks []*taskstore.Task, parentID string) []*taskstore.Task {
 	// Build parent-to-children map
@@ -268,6 +416,9 @@ func FormatReport(report *Report) string {
 	sb.WriteString("## Summary\n\n")
 	_, _ = fmt.Fprintf(&sb, "- **Iterations:** %d iterations\n", report.TotalIterations)
 	_, _ = fmt.Fprintf(&sb, "- **Total Cost:** $%.2f\n", report.TotalCostUSD)
+	if report.VerifiedIterations > 0 {
+		_, _ = fmt.Fprintf(&sb, "- **Verification:** %d of %d passed on the first attempt\n", report.VerifiedFirstAttempt, report.VerifiedIterations)
+	}
 	if report.TotalDuration > 0 {
 		_, _ = fmt.Fprintf(&sb, "- **Duration:** %s\n", formatDuration(report.TotalDuration))
 	}
@@ -279,6 +430,15 @@ func FormatReport(report *Report) string {
 	}
 	sb.WriteString("\n")
 
+	// Sessions
+	if len(report.Sessions) > 0 {
+		sb.WriteString("## Sessions\n\n")
+		for i, session := range report.Sessions {
+			_, _ = fmt.Fprintf(&sb, "- %s\n", FormatSessionLine(i+1, session))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Commits
 	sb.WriteString("## Commits\n\n")
 	if len(report.Commits) == 0 {
@@ -289,7 +449,11 @@ func FormatReport(report *Report) string {
 			if len(hash) > 7 {
 				hash = hash[:7]
 			}
-			_, _ = fmt.Fprintf(&sb, "- `%s` %s (task: %s)\n", hash, commit.Message, commit.TaskID)
+			if len(commit.Annotations) > 0 {
+				_, _ = fmt.Fprintf(&sb, "- `%s` %s (task: %s; %s)\n", hash, commit.Message, commit.TaskID, loop.FormatAnnotations(commit.Annotations))
+			} else {
+				_, _ = fmt.Fprintf(&sb, "- `%s` %s (task: %s)\n", hash, commit.Message, commit.TaskID)
+			}
 		}
 	}
 	sb.WriteString("\n")
@@ -300,11 +464,43 @@ func FormatReport(report *Report) string {
 		sb.WriteString("No completed tasks.\n")
 	} else {
 		for _, task := range report.CompletedTasks {
+			if task.Unverified {
+				_, _ = fmt.Fprintf(&sb, "- [x] %s (%s) — unverified, no verify commands\n", task.Title, task.ID)
+				continue
+			}
 			_, _ = fmt.Fprintf(&sb, "- [x] %s (%s)\n", task.Title, task.ID)
 		}
 	}
 	sb.WriteString("\n")
 
+	// Tasks awaiting review
+	if len(report.AwaitingReviewTasks) > 0 {
+		sb.WriteString("## Awaiting Review\n\n")
+		for _, task := range report.AwaitingReviewTasks {
+			_, _ = fmt.Fprintf(&sb, "- [?] %s (%s)\n", task.Title, task.ID)
+		}
+		sb.WriteString("\n")
+	}
+
+	// High-touch tasks
+	if len(report.HighTouchTasks) > 0 {
+		sb.WriteString("## High-Touch Tasks\n\n")
+		for _, task := range report.HighTouchTasks {
+			var reasons []string
+			if task.Attempts > 1 {
+				reasons = append(reasons, fmt.Sprintf("%d attempts", task.Attempts))
+			}
+			if task.Feedback {
+				reasons = append(reasons, "feedback given")
+			}
+			if len(task.ManualChanges) > 0 {
+				reasons = append(reasons, "manual: "+strings.Join(task.ManualChanges, ", "))
+			}
+			_, _ = fmt.Fprintf(&sb, "- %s (%s) — %s\n", task.Title, task.ID, strings.Join(reasons, "; "))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Blocked tasks
 	if len(report.BlockedTasks) > 0 {
 		sb.WriteString("## Blocked Tasks\n\n")
@@ -1,6 +1,7 @@
 package reporter
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
 	"github.com/yarlson/ralph/internal/taskstore"
 )
 
@@ -146,6 +148,47 @@ func TestGenerateReportWithCompletedTasks(t *testing.T) {
 	assert.Equal(t, "Task 1", report.CompletedTasks[0].Title)
 }
 
+func TestGenerateReportFlagsUnverifiedTasks(t *testing.T) {
+	parentID := "parent-1"
+	now := time.Now()
+	tasks := []*taskstore.Task{
+		{ID: "verified", Title: "Verified", ParentID: &parentID, Status: taskstore.StatusCompleted, CreatedAt: now, UpdatedAt: now},
+		{ID: "unverified", Title: "Unverified", ParentID: &parentID, Status: taskstore.StatusCompleted, CreatedAt: now, UpdatedAt: now},
+		{ID: "review", Title: "Review", ParentID: &parentID, Status: taskstore.StatusAwaitingReview, CreatedAt: now, UpdatedAt: now},
+	}
+
+	logsDir := t.TempDir()
+	for i, r := range []struct {
+		taskID     string
+		unverified bool
+	}{{"verified", false}, {"unverified", true}, {"review", true}} {
+		_, err := loop.SaveRecord(logsDir, &loop.IterationRecord{
+			IterationID: fmt.Sprintf("iter-%d", i),
+			TaskID:      r.taskID,
+			StartTime:   now.Add(time.Duration(i) * time.Minute),
+			EndTime:     now.Add(time.Duration(i)*time.Minute + 30*time.Second),
+			Outcome:     loop.OutcomeSuccess,
+			Unverified:  r.unverified,
+		})
+		require.NoError(t, err)
+	}
+
+	gen := NewReportGenerator(&mockStore{tasks: tasks}, logsDir, nil)
+	report, err := gen.GenerateReport(parentID)
+	require.NoError(t, err)
+
+	require.Len(t, report.CompletedTasks, 2)
+	assert.False(t, report.CompletedTasks[0].Unverified)
+	assert.True(t, report.CompletedTasks[1].Unverified)
+	require.Len(t, report.AwaitingReviewTasks, 1)
+	assert.Equal(t, "review", report.AwaitingReviewTasks[0].ID)
+
+	out := FormatReport(report)
+	assert.Contains(t, out, "- [x] Verified (verified)\n")
+	assert.Contains(t, out, "- [x] Unverified (unverified) — unverified, no verify commands\n")
+	assert.Contains(t, out, "## Awaiting Review\n\n- [?] Review (review)\n")
+}
+
 func TestGenerateReportWithBlockedTasks(t *testing.T) {
 	parentID := "parent-1"
 	tasks := []*taskstore.Task{
@@ -234,6 +277,7 @@ func TestGenerateReportWithIterationRecords(t *testing.T) {
 		ClaudeInvocation: loop.ClaudeInvocationMeta{
 			TotalCostUSD: 0.50,
 		},
+		VerificationPassedOnAttempt: 1,
 	}
 	record2 := &loop.IterationRecord{
 		IterationID:  "iter-2",
@@ -245,6 +289,7 @@ func TestGenerateReportWithIterationRecords(t *testing.T) {
 		ClaudeInvocation: loop.ClaudeInvocationMeta{
 			TotalCostUSD: 0.75,
 		},
+		VerificationPassedOnAttempt: 3,
 	}
 
 	_, err := loop.SaveRecord(logsDir, record1)
@@ -281,6 +326,8 @@ func TestGenerateReportWithIterationRecords(t *testing.T) {
 	assert.Equal(t, 2, report.TotalIterations)
 	assert.Equal(t, 1.25, report.TotalCostUSD)
 	assert.Len(t, report.Commits, 2)
+	assert.Equal(t, 2, report.VerifiedIterations)
+	assert.Equal(t, 1, report.VerifiedFirstAttempt)
 }
 
 func TestGenerateReportCommitsFromRecords(t *testing.T) {
@@ -591,6 +638,28 @@ func TestFormatReport(t *testing.T) {
 	assert.Contains(t, formatted, "task-3")
 }
 
+func TestFormatReportCommitAnnotations(t *testing.T) {
+	report := &Report{
+		ParentTaskID: "parent-1",
+		Commits: []CommitInfo{
+			{Hash: "abc1234", Message: "feat: Task 1", TaskID: "task-1", Annotations: map[string]string{"pr": "42", "build": "1234"}},
+		},
+	}
+
+	formatted := FormatReport(report)
+
+	assert.Contains(t, formatted, "(task: task-1; build=1234, pr=42)")
+}
+
+func TestFormatReportVerificationAttempts(t *testing.T) {
+	report := &Report{ParentTaskID: "parent-1"}
+	assert.NotContains(t, FormatReport(report), "**Verification:**")
+
+	report.VerifiedIterations = 4
+	report.VerifiedFirstAttempt = 3
+	assert.Contains(t, FormatReport(report), "- **Verification:** 3 of 4 passed on the first attempt\n")
+}
+
 func TestFormatReportMinimal(t *testing.T) {
 	report := &Report{
 		ParentTaskID: "parent-1",
@@ -676,3 +745,51 @@ func (m *mockStore) Delete(id string) error {
 	}
 	return taskstore.ErrNotFound
 }
+
+func TestGenerateReportFlagsHighTouchTasks(t *testing.T) {
+	parentID := "parent-1"
+	now := time.Now()
+	tasks := []*taskstore.Task{
+		{ID: "smooth", Title: "Smooth", ParentID: &parentID, Status: taskstore.StatusCompleted, CreatedAt: now, UpdatedAt: now},
+		{ID: "retried", Title: "Retried", ParentID: &parentID, Status: taskstore.StatusCompleted, CreatedAt: now, UpdatedAt: now},
+		{ID: "approved", Title: "Approved", ParentID: &parentID, Status: taskstore.StatusCompleted, CreatedAt: now, UpdatedAt: now},
+		{ID: "pending", Title: "Pending", ParentID: &parentID, Status: taskstore.StatusOpen, CreatedAt: now, UpdatedAt: now},
+	}
+
+	logsDir := t.TempDir()
+	for i, r := range []struct {
+		taskID  string
+		outcome loop.IterationOutcome
+	}{{"smooth", loop.OutcomeSuccess}, {"retried", loop.OutcomeFailed}, {"retried", loop.OutcomeSuccess}, {"approved", loop.OutcomeSuccess}} {
+		_, err := loop.SaveRecord(logsDir, &loop.IterationRecord{
+			IterationID: fmt.Sprintf("iter-%d", i),
+			TaskID:      r.taskID,
+			StartTime:   now.Add(time.Duration(i) * time.Minute),
+			EndTime:     now.Add(time.Duration(i)*time.Minute + 30*time.Second),
+			Outcome:     r.outcome,
+		})
+		require.NoError(t, err)
+	}
+
+	stateDir := t.TempDir()
+	logPath := filepath.Join(stateDir, state.Interventions)
+	require.NoError(t, state.AppendIntervention(logPath, state.Intervention{TaskID: "retried", Action: state.InterventionRetry, Feedback: true, Time: now}))
+	require.NoError(t, state.AppendIntervention(logPath, state.Intervention{TaskID: "approved", Action: state.InterventionApprove, Time: now}))
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "feedback-pending.txt"), []byte("use the cache"), 0644))
+
+	gen := NewReportGeneratorWithStateDir(&mockStore{tasks: tasks}, logsDir, stateDir, nil)
+	report, err := gen.GenerateReport(parentID)
+	require.NoError(t, err)
+
+	assert.Equal(t, []HighTouchTask{
+		{ID: "retried", Title: "Retried", Attempts: 2, Feedback: true, ManualChanges: []string{"retry"}},
+		{ID: "approved", Title: "Approved", Attempts: 1, ManualChanges: []string{"approve"}},
+		{ID: "pending", Title: "Pending", Feedback: true},
+	}, report.HighTouchTasks)
+
+	out := FormatReport(report)
+	assert.Contains(t, out, "## High-Touch Tasks\n\n")
+	assert.Contains(t, out, "- Retried (retried) — 2 attempts; feedback given; manual: retry\n")
+	assert.Contains(t, out, "- Approved (approved) — manual: approve\n")
+	assert.NotContains(t, out, "(smooth) —")
+}
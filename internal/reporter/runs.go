@@ -0,0 +1,35 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// runTimeLayout is how run start and end times are shown in the runs table.
+const runTimeLayout = "2006-01-02 15:04"
+
+// FormatRuns renders sessions as a table with one run per row, oldest first.
+func FormatRuns(sessions []SessionSummary) string {
+	if len(sessions) == 0 {
+		return "No runs found.\n"
+	}
+
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RUN\tSTARTED\tENDED\tPARENT\tOUTCOME\tITERATIONS\tCOST")
+	for _, s := range sessions {
+		parent := s.ParentTaskID
+		if parent == "" {
+			parent = "-"
+		}
+		ended := "-"
+		if !s.EndTime.IsZero() {
+			ended = s.EndTime.Format(runTimeLayout)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t$%.2f\n",
+			s.ID, s.StartTime.Format(runTimeLayout), ended, parent, s.Outcome, s.Iterations, s.CostUSD)
+	}
+	_ = tw.Flush()
+	return sb.String()
+}
@@ -0,0 +1,22 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatRuns(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "No runs found.\n", FormatRuns(nil))
+	assert.Equal(t,
+		"RUN       STARTED           ENDED             PARENT   OUTCOME    ITERATIONS  COST\n"+
+			"a1b2c3d4  2026-03-02 09:00  2026-03-02 09:30  feature  completed  3           $2.00\n"+
+			"e5f6a7b8  2026-03-03 09:00  -                 -        failed     1           $0.25\n",
+		FormatRuns([]SessionSummary{
+			{ID: "a1b2c3d4", StartTime: monday, EndTime: monday.Add(30 * time.Minute), ParentTaskID: "feature", Outcome: SessionCompleted, Iterations: 3, CostUSD: 2.00},
+			{ID: "e5f6a7b8", StartTime: monday.Add(24 * time.Hour), Outcome: SessionFailed, Iterations: 1, CostUSD: 0.25},
+		}))
+}
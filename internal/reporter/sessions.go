@@ -0,0 +1,146 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yarlson/ralph/internal/loop"
+)
+
+// SessionSummary summarizes the iterations of one session: a single run from
+// start until it completed, paused or stopped.
+type SessionSummary struct {
+	// ID is the session ID stamped on the session's iteration records.
+	ID string `json:"id"`
+
+	// StartTime is when the session's first iteration started.
+	StartTime time.Time `json:"start_time"`
+
+	// EndTime is when the session's last iteration ended.
+	EndTime time.Time `json:"end_time"`
+
+	// ParentTaskID is the parent task the session worked through (empty for
+	// records written before it was tracked).
+	ParentTaskID string `json:"parent_task_id,omitempty"`
+
+	// Outcome is inferred from how each task's last iteration in the session ended.
+	Outcome SessionOutcome `json:"outcome"`
+
+	// Iterations is the number of iterations run in the session.
+	Iterations int `json:"iterations"`
+
+	// CompletedTasks is the number of distinct tasks completed in the session.
+	CompletedTasks int `json:"completed_tasks"`
+
+	// CostUSD is the total agent cost of the session.
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// SessionOutcome summarizes how a session ended.
+type SessionOutcome string
+
+const (
+	// SessionCompleted means every task the session worked on ended in success.
+	SessionCompleted SessionOutcome = "completed"
+	// SessionPartial means some tasks ended in success and some did not.
+	SessionPartial SessionOutcome = "partial"
+	// SessionFailed means no task the session worked on ended in success.
+	SessionFailed SessionOutcome = "failed"
+)
+
+// SummarizeSessions groups records by session, oldest session first. Records
+// written before sessions were tracked have no session ID and are left out.
+func SummarizeSessions(records []*loop.IterationRecord) []SessionSummary {
+	byID := make(map[string]*SessionSummary)
+	completed := make(map[string]map[string]bool)
+	last := make(map[string]map[string]*loop.IterationRecord)
+	for _, record := range records {
+		if record.SessionID == "" {
+			continue
+		}
+
+		session, ok := byID[record.SessionID]
+		if !ok {
+			session = &SessionSummary{ID: record.SessionID, StartTime: record.StartTime}
+			byID[record.SessionID] = session
+			completed[record.SessionID] = make(map[string]bool)
+			last[record.SessionID] = make(map[string]*loop.IterationRecord)
+		}
+		if session.ParentTaskID == "" {
+			session.ParentTaskID = record.ParentTaskID
+		}
+		if prev := last[record.SessionID][record.TaskID]; prev == nil || !record.StartTime.Before(prev.StartTime) {
+			last[record.SessionID][record.TaskID] = record
+		}
+
+		session.Iterations++
+		session.CostUSD += record.ClaudeInvocation.TotalCostUSD
+		if record.StartTime.Before(session.StartTime) {
+			session.StartTime = record.StartTime
+		}
+		if record.EndTime.After(session.EndTime) {
+			session.EndTime = record.EndTime
+		}
+		if record.Outcome == loop.OutcomeSuccess && !completed[record.SessionID][record.TaskID] {
+			completed[record.SessionID][record.TaskID] = true
+			session.CompletedTasks++
+		}
+	}
+
+	sessions := make([]SessionSummary, 0, len(byID))
+	for id, session := range byID {
+		session.Outcome = sessionOutcome(last[id])
+		sessions = append(sessions, *session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].StartTime.Equal(sessions[j].StartTime) {
+			return sessions[i].StartTime.Before(sessions[j].StartTime)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions
+}
+
+// sessionOutcome infers a session's outcome from each task's last iteration.
+func sessionOutcome(lastByTask map[string]*loop.IterationRecord) SessionOutcome {
+	succeeded := 0
+	for _, record := range lastByTask {
+		if record.Outcome == loop.OutcomeSuccess {
+			succeeded++
+		}
+	}
+	switch succeeded {
+	case len(lastByTask):
+		return SessionCompleted
+	case 0:
+		return SessionFailed
+	default:
+		return SessionPartial
+	}
+}
+
+// FormatSessionLine renders a session as a single line, numbered by n.
+func FormatSessionLine(n int, session SessionSummary) string {
+	line := fmt.Sprintf("Session %d (%s): %d task(s), %d iteration(s), $%.2f",
+		n, session.StartTime.Format("Mon Jan 2 15:04"), session.CompletedTasks, session.Iterations, session.CostUSD)
+	if !session.EndTime.IsZero() && session.EndTime.After(session.StartTime) {
+		line += ", " + formatDuration(session.EndTime.Sub(session.StartTime))
+	}
+	return line
+}
+
+// FormatSessions renders sessions one per line, oldest first.
+func FormatSessions(sessions []SessionSummary) string {
+	if len(sessions) == 0 {
+		return "No sessions found.\n"
+	}
+
+	var sb strings.Builder
+	for i, session := range sessions {
+		sb.WriteString(FormatSessionLine(i+1, session))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,91 @@
+package reporter
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/loop"
+)
+
+func TestSummarizeSessions(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	tuesday := monday.Add(24 * time.Hour)
+	record := func(session, task string, start time.Time, outcome loop.IterationOutcome, cost float64) *loop.IterationRecord {
+		return &loop.IterationRecord{
+			IterationID:      session + "-" + task + "-" + start.Format("150405"),
+			TaskID:           task,
+			SessionID:        session,
+			StartTime:        start,
+			EndTime:          start.Add(10 * time.Minute),
+			Outcome:          outcome,
+			ClaudeInvocation: loop.ClaudeInvocationMeta{TotalCostUSD: cost},
+		}
+	}
+
+	sessions := SummarizeSessions([]*loop.IterationRecord{
+		record("tue", "task-c", tuesday, loop.OutcomeSuccess, 1.50),
+		record("mon", "task-a", monday, loop.OutcomeFailed, 0.50),
+		record("mon", "task-a", monday.Add(10*time.Minute), loop.OutcomeSuccess, 1.00),
+		record("mon", "task-b", monday.Add(20*time.Minute), loop.OutcomeSuccess, 0.50),
+		record("", "task-z", monday.Add(-time.Hour), loop.OutcomeSuccess, 9.00),
+	})
+
+	assert.Equal(t, []SessionSummary{
+		{ID: "mon", StartTime: monday, EndTime: monday.Add(30 * time.Minute), Outcome: SessionCompleted, Iterations: 3, CompletedTasks: 2, CostUSD: 2.00},
+		{ID: "tue", StartTime: tuesday, EndTime: tuesday.Add(10 * time.Minute), Outcome: SessionCompleted, Iterations: 1, CompletedTasks: 1, CostUSD: 1.50},
+	}, sessions)
+}
+
+func TestSummarizeSessions_ParentAndOutcome(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	record := func(session, task string, minute int, outcome loop.IterationOutcome) *loop.IterationRecord {
+		return &loop.IterationRecord{
+			IterationID:  session + "-" + task + "-" + strconv.Itoa(minute),
+			TaskID:       task,
+			SessionID:    session,
+			ParentTaskID: "feature",
+			StartTime:    start.Add(time.Duration(minute) * time.Minute),
+			Outcome:      outcome,
+		}
+	}
+
+	sessions := SummarizeSessions([]*loop.IterationRecord{
+		record("partial", "task-a", 0, loop.OutcomeSuccess),
+		record("partial", "task-b", 1, loop.OutcomeFailed),
+		record("failed", "task-c", 2, loop.OutcomeSuccess),
+		record("failed", "task-c", 3, loop.OutcomeBlocked),
+		record("failed", "task-d", 4, loop.OutcomeBudgetExceeded),
+	})
+
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "partial", sessions[0].ID)
+	assert.Equal(t, SessionPartial, sessions[0].Outcome)
+	assert.Equal(t, "feature", sessions[0].ParentTaskID)
+	assert.Equal(t, "failed", sessions[1].ID)
+	assert.Equal(t, SessionFailed, sessions[1].Outcome, "a task's last iteration decides it")
+}
+
+func TestFormatSessions(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "No sessions found.\n", FormatSessions(nil))
+	assert.Equal(t,
+		"Session 1 (Mon Mar 2 09:00): 2 task(s), 3 iteration(s), $2.00, 30.0 minutes\n"+
+			"Session 2 (Tue Mar 3 09:00): 0 task(s), 1 iteration(s), $0.25\n",
+		FormatSessions([]SessionSummary{
+			{ID: "mon", StartTime: monday, EndTime: monday.Add(30 * time.Minute), Iterations: 3, CompletedTasks: 2, CostUSD: 2.00},
+			{ID: "tue", StartTime: monday.Add(24 * time.Hour), Iterations: 1, CostUSD: 0.25},
+		}))
+}
+
+func TestFormatReportSessions(t *testing.T) {
+	report := &Report{ParentTaskID: "parent-1"}
+	assert.NotContains(t, FormatReport(report), "## Sessions")
+
+	report.Sessions = []SessionSummary{{ID: "mon", StartTime: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), Iterations: 1, CompletedTasks: 1, CostUSD: 1.00}}
+	assert.Contains(t, FormatReport(report), "## Sessions\n\n- Session 1 (Mon Mar 2 09:00): 1 task(s), 1 iteration(s), $1.00\n")
+}
@@ -0,0 +1,154 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/selector"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// TaskStats summarizes the shape of a task set, to judge a decomposition
+// before running it.
+type TaskStats struct {
+	// Tasks is the number of tasks.
+	Tasks int
+
+	// Leaves is the number of tasks without children (the ones that run).
+	Leaves int
+
+	// AvgAcceptancePerLeaf is the mean number of acceptance criteria per leaf.
+	AvgAcceptancePerLeaf float64
+
+	// AvgDepth is the mean nesting level of all tasks (a root is level 1).
+	AvgDepth float64
+
+	// MaxDepth is the deepest nesting level.
+	MaxDepth int
+
+	// LeavesWithoutVerify are the leaf task IDs with no verify commands, sorted.
+	LeavesWithoutVerify []string
+
+	// NoDependencies is the number of tasks that depend on no other task.
+	NoDependencies int
+
+	// LargestSiblingGroup is the most children any one task has, or the number
+	// of root tasks if that is larger.
+	LargestSiblingGroup int
+
+	// LargestSiblingParent is the parent of the largest sibling group ("" for
+	// root tasks).
+	LargestSiblingParent string
+}
+
+// ComputeTaskStats computes quality metrics for every task in store. It fails
+// if a task depends on a task that does not exist.
+func ComputeTaskStats(store taskstore.Store) (*TaskStats, error) {
+	tasks, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	graph, err := selector.BuildGraph(tasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build dependency graph: %w", err)
+	}
+
+	stats := &TaskStats{Tasks: len(tasks), LeavesWithoutVerify: []string{}}
+	if len(tasks) == 0 {
+		return stats, nil
+	}
+
+	// Group tasks by parent; tasks whose parent is missing count as roots
+	ids := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		ids[t.ID] = true
+	}
+	siblings := make(map[string]int)
+	for _, t := range tasks {
+		parent := ""
+		if t.ParentID != nil && ids[*t.ParentID] {
+			parent = *t.ParentID
+		}
+		siblings[parent]++
+	}
+
+	depths := taskstore.Depths(tasks)
+	totalDepth, totalAcceptance := 0, 0
+	for _, t := range tasks {
+		depth := depths[t.ID]
+		totalDepth += depth
+		stats.MaxDepth = max(stats.MaxDepth, depth)
+
+		if len(graph.Dependencies(t.ID)) == 0 {
+			stats.NoDependencies++
+		}
+
+		if siblings[t.ID] > 0 {
+			continue
+		}
+		stats.Leaves++
+		totalAcceptance += len(t.Acceptance)
+		if len(t.Verify) == 0 && t.VerifySet == "" {
+			stats.LeavesWithoutVerify = append(stats.LeavesWithoutVerify, t.ID)
+		}
+	}
+	sort.Strings(stats.LeavesWithoutVerify)
+
+	stats.AvgDepth = float64(totalDepth) / float64(len(tasks))
+	if stats.Leaves > 0 {
+		stats.AvgAcceptancePerLeaf = float64(totalAcceptance) / float64(stats.Leaves)
+	}
+
+	// Ties go to the alphabetically first parent, roots first
+	parents := make([]string, 0, len(siblings))
+	for parent := range siblings {
+		parents = append(parents, parent)
+	}
+	sort.Strings(parents)
+	for _, parent := range parents {
+		if siblings[parent] > stats.LargestSiblingGroup {
+			stats.LargestSiblingGroup = siblings[parent]
+			stats.LargestSiblingParent = parent
+		}
+	}
+
+	return stats, nil
+}
+
+// FormatTaskStats renders stats as a two-column table.
+func FormatTaskStats(stats *TaskStats) string {
+	if stats.Tasks == 0 {
+		return "No tasks found.\n"
+	}
+
+	withoutVerify := fmt.Sprintf("%d", len(stats.LeavesWithoutVerify))
+	if len(stats.LeavesWithoutVerify) > 0 {
+		withoutVerify += " (" + strings.Join(stats.LeavesWithoutVerify, ", ") + ")"
+	}
+	largestUnder := "root tasks"
+	if stats.LargestSiblingParent != "" {
+		largestUnder = stats.LargestSiblingParent
+	}
+
+	rows := [][2]string{
+		{"Metric", "Value"},
+		{"Tasks", fmt.Sprintf("%d", stats.Tasks)},
+		{"Leaf tasks", fmt.Sprintf("%d", stats.Leaves)},
+		{"Avg acceptance criteria per leaf", fmt.Sprintf("%.1f", stats.AvgAcceptancePerLeaf)},
+		{"Avg depth", fmt.Sprintf("%.1f (max %d)", stats.AvgDepth, stats.MaxDepth)},
+		{"Leaves without verify commands", withoutVerify},
+		{"Tasks without dependencies", fmt.Sprintf("%d", stats.NoDependencies)},
+		{"Largest sibling group", fmt.Sprintf("%d (%s)", stats.LargestSiblingGroup, largestUnder)},
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row[0]))
+	}
+	var sb strings.Builder
+	for _, row := range rows {
+		_, _ = fmt.Fprintf(&sb, "%-*s  %s\n", width, row[0], row[1])
+	}
+	return sb.String()
+}
@@ -0,0 +1,69 @@
+package reporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestComputeTaskStats(t *testing.T) {
+	parent := func(id string) *string { return &id }
+	store := &mockStore{tasks: []*taskstore.Task{
+		{ID: "root", Title: "Root"},
+		{ID: "epic-a", Title: "Epic A", ParentID: parent("root")},
+		{ID: "epic-b", Title: "Epic B", ParentID: parent("root")},
+		{ID: "a1", Title: "A1", ParentID: parent("epic-a"), Acceptance: []string{"x", "y"}, Verify: [][]string{{"go", "test"}}},
+		{ID: "a2", Title: "A2", ParentID: parent("epic-a"), Acceptance: []string{"x"}, DependsOn: []string{"a1"}},
+		{ID: "a3", Title: "A3", ParentID: parent("epic-a"), DependsOn: []string{"a1"}, Verify: [][]string{{"go", "vet"}}},
+		{ID: "b1", Title: "B1", ParentID: parent("epic-b"), Acceptance: []string{"x", "y", "z"}, DependsOn: []string{"epic-a"}},
+	}}
+
+	stats, err := ComputeTaskStats(store)
+	require.NoError(t, err)
+
+	assert.Equal(t, 7, stats.Tasks)
+	assert.Equal(t, 4, stats.Leaves)
+	assert.InDelta(t, 1.5, stats.AvgAcceptancePerLeaf, 0.001)
+	assert.InDelta(t, 17.0/7.0, stats.AvgDepth, 0.001)
+	assert.Equal(t, 3, stats.MaxDepth)
+	assert.Equal(t, []string{"a2", "b1"}, stats.LeavesWithoutVerify)
+	assert.Equal(t, 4, stats.NoDependencies)
+	assert.Equal(t, 3, stats.LargestSiblingGroup)
+	assert.Equal(t, "epic-a", stats.LargestSiblingParent)
+
+	out := FormatTaskStats(stats)
+	assert.Contains(t, out, "Metric                            Value\n")
+	assert.Contains(t, out, "Avg acceptance criteria per leaf  1.5\n")
+	assert.Contains(t, out, "Avg depth                         2.4 (max 3)\n")
+	assert.Contains(t, out, "Leaves without verify commands    2 (a2, b1)\n")
+	assert.Contains(t, out, "Largest sibling group             3 (epic-a)\n")
+}
+
+func TestComputeTaskStats_EdgeCases(t *testing.T) {
+	t.Run("empty task set", func(t *testing.T) {
+		stats, err := ComputeTaskStats(&mockStore{})
+		require.NoError(t, err)
+		assert.Equal(t, "No tasks found.\n", FormatTaskStats(stats))
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		_, err := ComputeTaskStats(&mockStore{tasks: []*taskstore.Task{
+			{ID: "a", Title: "A", DependsOn: []string{"ghost"}},
+		}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to build dependency graph")
+	})
+
+	t.Run("root tasks form the largest group", func(t *testing.T) {
+		stats, err := ComputeTaskStats(&mockStore{tasks: []*taskstore.Task{
+			{ID: "a", Title: "A"},
+			{ID: "b", Title: "B"},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, stats.LargestSiblingGroup)
+		assert.Contains(t, FormatTaskStats(stats), "Largest sibling group             2 (root tasks)\n")
+	})
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/yarlson/ralph/internal/color"
 	"github.com/yarlson/ralph/internal/loop"
 	"github.com/yarlson/ralph/internal/selector"
 	"github.com/yarlson/ralph/internal/taskstore"
@@ -16,61 +18,66 @@ import (
 // TaskCounts holds the count of tasks in each status.
 type TaskCounts struct {
 	// Total is the total number of descendant tasks under the parent.
-	Total int
+	Total int `json:"total"`
 
 	// Completed is the count of tasks with status "completed".
-	Completed int
+	Completed int `json:"completed"`
 
 	// Ready is the count of tasks that are ready to execute (open, all deps completed, is leaf).
-	Ready int
+	Ready int `json:"ready"`
 
 	// Blocked is the count of tasks with status "blocked".
-	Blocked int
+	Blocked int `json:"blocked"`
 
 	// Failed is the count of tasks with status "failed".
-	Failed int
+	Failed int `json:"failed"`
 
 	// Skipped is the count of tasks with status "skipped".
-	Skipped int
+	Skipped int `json:"skipped"`
+
+	// AwaitingReview is the count of tasks with status "awaiting_review".
+	AwaitingReview int `json:"awaiting_review"`
 }
 
 // LastIterationInfo contains summary information about the last iteration.
 type LastIterationInfo struct {
 	// IterationID is the unique identifier of the iteration.
-	IterationID string
+	IterationID string `json:"iteration_id"`
 
 	// TaskID is the ID of the task that was executed.
-	TaskID string
+	TaskID string `json:"task_id"`
 
 	// TaskTitle is the title of the task that was executed.
-	TaskTitle string
+	TaskTitle string `json:"task_title"`
 
 	// Outcome is the result of the iteration.
-	Outcome loop.IterationOutcome
+	Outcome loop.IterationOutcome `json:"outcome"`
 
 	// EndTime is when the iteration completed.
-	EndTime time.Time
+	EndTime time.Time `json:"end_time"`
 
 	// LogPath is the path to the iteration log file.
-	LogPath string
+	LogPath string `json:"log_path"`
 }
 
-// Status contains all status information for a parent task.
+// Status contains all status information for a parent task. Its JSON form
+// (`ralph status --json`) is meant for scripts, so its field names are
+// stable; NextTask and LastIteration are null when there are none.
 type Status struct {
 	// ParentTaskID is the ID of the parent task being reported on.
-	ParentTaskID string
+	ParentTaskID string `json:"parent_task_id"`
 
 	// Counts holds the task counts by status.
-	Counts TaskCounts
+	Counts TaskCounts `json:"counts"`
 
 	// NextTask is the next task that will be executed (if any).
-	NextTask *taskstore.Task
+	NextTask *taskstore.Task `json:"next_task"`
 
 	// LastIteration contains info about the most recent iteration (if any).
-	LastIteration *LastIterationInfo
+	LastIteration *LastIterationInfo `json:"last_iteration"`
 
 	// NextTaskFeedback is the user feedback for the next task (if any).
-	NextTaskFeedback string
+	NextTaskFeedback string `json:"next_task_feedback"`
 }
 
 // StatusGenerator generates status information for a parent task.
@@ -140,6 +147,8 @@ func (g *StatusGenerator) GetStatus(parentTaskID string) (*Status, error) {
 			status.Counts.Failed++
 		case taskstore.StatusSkipped:
 			status.Counts.Skipped++
+		case taskstore.StatusAwaitingReview:
+			status.Counts.AwaitingReview++
 		}
 	}
 
@@ -228,6 +237,12 @@ func FindLatestIterationRecord(logsDir string) (*loop.IterationRecord, string, e
 
 // FormatStatus formats a status for CLI display.
 func FormatStatus(status *Status) string {
+	return FormatStatusColor(status, color.Palette{})
+}
+
+// FormatStatusColor formats a status for CLI display, coloring nonzero
+// completed, blocked, and failed counts and the last iteration's outcome.
+func FormatStatusColor(status *Status, p color.Palette) string {
 	var sb strings.Builder
 
 	sb.WriteString("## Status\n\n")
@@ -238,11 +253,14 @@ func FormatStatus(status *Status) string {
 	// Task counts
 	sb.WriteString("### Task Counts\n")
 	_, _ = fmt.Fprintf(&sb, "Total: %d\n", status.Counts.Total)
-	_, _ = fmt.Fprintf(&sb, "Completed: %d\n", status.Counts.Completed)
+	_, _ = fmt.Fprintf(&sb, "Completed: %s\n", nonzero(status.Counts.Completed, p.Green))
 	_, _ = fmt.Fprintf(&sb, "Ready: %d\n", status.Counts.Ready)
-	_, _ = fmt.Fprintf(&sb, "Blocked: %d\n", status.Counts.Blocked)
-	_, _ = fmt.Fprintf(&sb, "Failed: %d\n", status.Counts.Failed)
+	_, _ = fmt.Fprintf(&sb, "Blocked: %s\n", nonzero(status.Counts.Blocked, p.Yellow))
+	_, _ = fmt.Fprintf(&sb, "Failed: %s\n", nonzero(status.Counts.Failed, p.Red))
 	_, _ = fmt.Fprintf(&sb, "Skipped: %d\n", status.Counts.Skipped)
+	if status.Counts.AwaitingReview > 0 {
+		_, _ = fmt.Fprintf(&sb, "Awaiting Review: %s\n", nonzero(status.Counts.AwaitingReview, p.Yellow))
+	}
 	sb.WriteString("\n")
 
 	// Next task
@@ -265,7 +283,7 @@ func FormatStatus(status *Status) string {
 		if status.LastIteration.TaskTitle != "" {
 			_, _ = fmt.Fprintf(&sb, "Title: %s\n", status.LastIteration.TaskTitle)
 		}
-		_, _ = fmt.Fprintf(&sb, "Outcome: %s\n", status.LastIteration.Outcome)
+		_, _ = fmt.Fprintf(&sb, "Outcome: %s\n", p.Outcome(string(status.LastIteration.Outcome)))
 		if !status.LastIteration.EndTime.IsZero() {
 			_, _ = fmt.Fprintf(&sb, "Completed: %s\n", status.LastIteration.EndTime.Format(time.RFC3339))
 		}
@@ -276,3 +294,11 @@ func FormatStatus(status *Status) string {
 
 	return sb.String()
 }
+
+// nonzero formats n, colored with paint unless it is zero.
+func nonzero(n int, paint func(string) string) string {
+	if n == 0 {
+		return "0"
+	}
+	return paint(strconv.Itoa(n))
+}
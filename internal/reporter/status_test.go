@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yarlson/ralph/internal/color"
 	"github.com/yarlson/ralph/internal/loop"
 	"github.com/yarlson/ralph/internal/taskstore"
 )
@@ -433,6 +434,22 @@ func TestStatusGenerator_Format(t *testing.T) {
 		assert.Contains(t, formatted, "Total: 0")
 	})
 
+	t.Run("colors nonzero counts and the last outcome", func(t *testing.T) {
+		status := &Status{
+			ParentTaskID:  "parent-1",
+			Counts:        TaskCounts{Total: 3, Completed: 1, Failed: 2},
+			LastIteration: &LastIterationInfo{IterationID: "abc12345", TaskID: "task-1", Outcome: loop.OutcomeFailed},
+		}
+
+		formatted := FormatStatusColor(status, color.New(true))
+
+		assert.Contains(t, formatted, "Completed: \x1b[32m1\x1b[0m")
+		assert.Contains(t, formatted, "Blocked: 0\n")
+		assert.Contains(t, formatted, "Failed: \x1b[31m2\x1b[0m")
+		assert.Contains(t, formatted, "Outcome: \x1b[31mfailed\x1b[0m")
+		assert.NotContains(t, FormatStatus(status), "\x1b[")
+	})
+
 	t.Run("formats status with next task feedback", func(t *testing.T) {
 		nextTask := &taskstore.Task{ID: "task-2", Title: "Next Task"}
 		status := &Status{
@@ -0,0 +1,227 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// VerifyStatus is the result of running a verify command once.
+type VerifyStatus string
+
+const (
+	// VerifyPass means the command exited with status 0.
+	VerifyPass VerifyStatus = "pass"
+	// VerifyFail means the command exited with a non-zero status.
+	VerifyFail VerifyStatus = "fail"
+	// VerifyError means the command could not run (not found, not allowed, timed out).
+	VerifyError VerifyStatus = "error"
+)
+
+// VerifyCheck is the result of one verify command, shared by every task that declares it.
+type VerifyCheck struct {
+	Command  []string
+	Status   VerifyStatus
+	Output   string
+	Error    string
+	Duration time.Duration
+}
+
+// TaskVerifyBaseline lists the checks for one task's verify commands, in order.
+type TaskVerifyBaseline struct {
+	TaskID string
+	Title  string
+	Status taskstore.TaskStatus
+	Checks []*VerifyCheck
+}
+
+// Passed reports whether every verify command of the task passed.
+func (b *TaskVerifyBaseline) Passed() bool {
+	for _, check := range b.Checks {
+		if check.Status != VerifyPass {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyBaseline is the result of running every task's verify commands against
+// the current codebase.
+type VerifyBaseline struct {
+	// Tasks lists tasks with verify commands, in store order.
+	Tasks []*TaskVerifyBaseline
+
+	// Checks lists each distinct command once, in the order it was run.
+	Checks []*VerifyCheck
+}
+
+// Counts returns how many distinct commands passed, failed, and errored.
+func (b *VerifyBaseline) Counts() (passed, failed, errored int) {
+	for _, check := range b.Checks {
+		switch check.Status {
+		case VerifyPass:
+			passed++
+		case VerifyFail:
+			failed++
+		case VerifyError:
+			errored++
+		}
+	}
+	return passed, failed, errored
+}
+
+// commandVerifier runs verification commands.
+type commandVerifier interface {
+	Verify(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error)
+}
+
+// RunVerifyBaseline runs the verify commands of every task once against the
+// current codebase, including the commands of each task's verify set (from
+// sets). Commands declared by several tasks run only once, each with its own
+// timeout (0 = none).
+func RunVerifyBaseline(ctx context.Context, store taskstore.Store, sets map[string][][]string, v commandVerifier, timeout time.Duration) (*VerifyBaseline, error) {
+	tasks, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	baseline := &VerifyBaseline{}
+	checks := make(map[string]*VerifyCheck)
+	for _, task := range tasks {
+		task, err := task.ExpandVerifySet(sets)
+		if err != nil {
+			return nil, err
+		}
+		if len(task.Verify) == 0 {
+			continue
+		}
+
+		tb := &TaskVerifyBaseline{TaskID: task.ID, Title: task.Title, Status: task.Status}
+		for _, command := range task.Verify {
+			key := strings.Join(command, "\x00")
+			check, ok := checks[key]
+			if !ok {
+				check, err = runVerifyCheck(ctx, v, command, timeout)
+				if err != nil {
+					return nil, err
+				}
+				checks[key] = check
+				baseline.Checks = append(baseline.Checks, check)
+			}
+			tb.Checks = append(tb.Checks, check)
+		}
+		baseline.Tasks = append(baseline.Tasks, tb)
+	}
+
+	return baseline, nil
+}
+
+// runVerifyCheck runs a single command and classifies the result.
+func runVerifyCheck(ctx context.Context, v commandVerifier, command []string, timeout time.Duration) (*VerifyCheck, error) {
+	checkCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	results, err := v.Verify(checkCtx, [][]string{command})
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", strings.Join(command, " "), err)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	check := &VerifyCheck{Command: command, Status: VerifyError, Error: "no result"}
+	if len(results) == 1 {
+		r := results[0]
+		check.Output = r.Output
+		check.Error = r.Error
+		check.Duration = r.Duration
+		switch {
+		case r.Error != "":
+			check.Status = VerifyError
+		case r.Passed:
+			check.Status = VerifyPass
+		default:
+			check.Status = VerifyFail
+		}
+	}
+	return check, nil
+}
+
+// FormatVerifyBaseline formats a verify baseline for display. Failing and
+// erroring commands show the tail of their output.
+func FormatVerifyBaseline(baseline *VerifyBaseline) string {
+	var sb strings.Builder
+
+	if len(baseline.Tasks) == 0 {
+		sb.WriteString("No tasks have verify commands.\n")
+		return sb.String()
+	}
+
+	for _, tb := range baseline.Tasks {
+		_, _ = fmt.Fprintf(&sb, "%s (%s) [%s]\n", tb.TaskID, tb.Title, tb.Status)
+		for _, check := range tb.Checks {
+			_, _ = fmt.Fprintf(&sb, "  %s %s\n", verifyStatusMark(check.Status), strings.Join(check.Command, " "))
+		}
+	}
+
+	var problems []*VerifyCheck
+	for _, check := range baseline.Checks {
+		if check.Status != VerifyPass {
+			problems = append(problems, check)
+		}
+	}
+	for _, check := range problems {
+		_, _ = fmt.Fprintf(&sb, "\n%s %s: %s\n", verifyStatusMark(check.Status), strings.Join(check.Command, " "), check.Status)
+		if check.Error != "" {
+			_, _ = fmt.Fprintf(&sb, "    %s\n", check.Error)
+		}
+		if tail := outputTail(check.Output, 10); tail != "" {
+			_, _ = fmt.Fprintf(&sb, "    %s\n", strings.ReplaceAll(tail, "\n", "\n    "))
+		}
+	}
+
+	passed, failed, errored := baseline.Counts()
+	_, _ = fmt.Fprintf(&sb, "\n%d command(s) for %d task(s): %d passed, %d failed, %d error(s)\n",
+		len(baseline.Checks), len(baseline.Tasks), passed, failed, errored)
+
+	var open []string
+	for _, tb := range baseline.Tasks {
+		if !tb.Passed() && tb.Status != taskstore.StatusCompleted && tb.Status != taskstore.StatusSkipped {
+			open = append(open, tb.TaskID)
+		}
+	}
+	if len(open) > 0 {
+		_, _ = fmt.Fprintf(&sb, "Verification already fails before work on: %s\n", strings.Join(open, ", "))
+		sb.WriteString("These tasks may be mis-specified or depend on unfinished work.\n")
+	}
+
+	return sb.String()
+}
+
+func verifyStatusMark(status VerifyStatus) string {
+	switch status {
+	case VerifyPass:
+		return "✓"
+	case VerifyFail:
+		return "✗"
+	default:
+		return "!"
+	}
+}
+
+// outputTail returns the last n lines of output.
+func outputTail(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
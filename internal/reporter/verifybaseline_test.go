@@ -0,0 +1,90 @@
+package reporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+	"github.com/yarlson/ralph/internal/verifier"
+)
+
+// scriptedVerifier returns canned results per command and records what ran.
+type scriptedVerifier struct {
+	results map[string]verifier.VerificationResult
+	ran     []string
+}
+
+func (v *scriptedVerifier) Verify(ctx context.Context, commands [][]string) ([]verifier.VerificationResult, error) {
+	var results []verifier.VerificationResult
+	for _, cmd := range commands {
+		key := strings.Join(cmd, " ")
+		v.ran = append(v.ran, key)
+		r := v.results[key]
+		r.Command = cmd
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func TestRunVerifyBaseline(t *testing.T) {
+	store := &mockStore{tasks: []*taskstore.Task{
+		{ID: "epic", Title: "Epic", Status: taskstore.StatusOpen},
+		{ID: "api", Title: "API", Status: taskstore.StatusOpen, Verify: [][]string{{"go", "test", "./..."}, {"go", "vet", "./..."}}},
+		{ID: "done", Title: "Done", Status: taskstore.StatusCompleted, VerifySet: "go"},
+		{ID: "web", Title: "Web", Status: taskstore.StatusOpen, Verify: [][]string{{"npm", "test"}}},
+	}}
+	v := &scriptedVerifier{results: map[string]verifier.VerificationResult{
+		"go test ./...": {Passed: true},
+		"go vet ./...":  {Passed: false, Output: "vet: undefined: Foo\n"},
+		"npm test":      {Passed: false, Error: `exec: "npm": executable file not found in $PATH`},
+	}}
+
+	sets := map[string][][]string{"go": {{"go", "test", "./..."}}}
+
+	baseline, err := RunVerifyBaseline(context.Background(), store, sets, v, 0)
+	require.NoError(t, err)
+
+	t.Run("runs each distinct command once", func(t *testing.T) {
+		assert.Equal(t, []string{"go test ./...", "go vet ./...", "npm test"}, v.ran)
+		require.Len(t, baseline.Checks, 3)
+	})
+
+	t.Run("classifies results per task", func(t *testing.T) {
+		require.Len(t, baseline.Tasks, 3)
+		assert.Equal(t, "api", baseline.Tasks[0].TaskID)
+		assert.Equal(t, VerifyPass, baseline.Tasks[0].Checks[0].Status)
+		assert.Equal(t, VerifyFail, baseline.Tasks[0].Checks[1].Status)
+		assert.True(t, baseline.Tasks[1].Passed())
+		assert.Equal(t, VerifyError, baseline.Tasks[2].Checks[0].Status)
+
+		passed, failed, errored := baseline.Counts()
+		assert.Equal(t, []int{1, 1, 1}, []int{passed, failed, errored})
+	})
+
+	t.Run("formats failures and open tasks that already fail", func(t *testing.T) {
+		output := FormatVerifyBaseline(baseline)
+
+		assert.Contains(t, output, "api (API) [open]\n  ✓ go test ./...\n  ✗ go vet ./...\n")
+		assert.Contains(t, output, "✗ go vet ./...: fail\n    vet: undefined: Foo\n")
+		assert.Contains(t, output, "! npm test: error\n    exec: \"npm\": executable file not found in $PATH\n")
+		assert.Contains(t, output, "3 command(s) for 3 task(s): 1 passed, 1 failed, 1 error(s)")
+		assert.Contains(t, output, "Verification already fails before work on: api, web\n")
+	})
+}
+
+func TestRunVerifyBaseline_UnknownVerifySet(t *testing.T) {
+	store := &mockStore{tasks: []*taskstore.Task{
+		{ID: "api", Title: "API", Status: taskstore.StatusOpen, VerifySet: "rust"},
+	}}
+
+	_, err := RunVerifyBaseline(context.Background(), store, nil, &scriptedVerifier{}, 0)
+	require.ErrorIs(t, err, taskstore.ErrUnknownVerifySet)
+}
+
+func TestFormatVerifyBaseline_NoVerifyCommands(t *testing.T) {
+	assert.Equal(t, "No tasks have verify commands.\n", FormatVerifyBaseline(&VerifyBaseline{}))
+}
@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/config"
+	gitpkg "github.com/yarlson/ralph/internal/git"
+	"github.com/yarlson/ralph/internal/state"
+)
+
+// RunIsolated runs the loop in a temporary clone of the repository at workDir
+// so the working directory is never touched. The clone gets a copy of the
+// .ralph state. Afterwards the branch the run worked on is fetched back into
+// the repository without being checked out; if that is not possible, fetch
+// instructions are printed instead. The clone is removed once its branch has
+// been fetched back, and kept for inspection otherwise.
+func RunIsolated(ctx context.Context, workDir string, cfg *config.Config, parentTaskID string, opts Options, stdout, stderr io.Writer) error {
+	source := gitpkg.NewShellManager(workDir, config.DefaultBranchPrefix)
+
+	if files, err := source.GetChangedFiles(ctx); err == nil {
+		for _, f := range files {
+			if !strings.HasPrefix(f, state.RalphDir+"/") {
+				_, _ = fmt.Fprintln(stderr, "warning: uncommitted changes are not part of the isolated clone")
+				break
+			}
+		}
+	}
+
+	tmp, err := os.MkdirTemp("", "ralph-isolated-")
+	if err != nil {
+		return fmt.Errorf("failed to create isolated clone directory: %w", err)
+	}
+	cloneDir := filepath.Join(tmp, "repo")
+	if err := source.Clone(ctx, cloneDir); err != nil {
+		_ = os.RemoveAll(tmp)
+		return fmt.Errorf("failed to clone repository: %w", err)
+	}
+	if err := copyDir(state.RalphDirPath(workDir), state.RalphDirPath(cloneDir)); err != nil {
+		_ = os.RemoveAll(tmp)
+		return fmt.Errorf("failed to copy ralph state into the isolated clone: %w", err)
+	}
+	_, _ = fmt.Fprintf(stdout, "Running in isolated clone: %s\n", cloneDir)
+
+	runErr := Run(ctx, cloneDir, cfg, parentTaskID, opts, stdout, stderr)
+
+	branch, err := gitpkg.NewShellManager(cloneDir, config.DefaultBranchPrefix).GetCurrentBranch(ctx)
+	if err != nil || branch == "HEAD" {
+		_, _ = fmt.Fprintf(stdout, "\nIsolated clone kept at %s\n", cloneDir)
+		return runErr
+	}
+
+	// The run's own context may be cancelled by an interrupt; fetching the
+	// work back should still happen.
+	if err := source.FetchBranch(context.WithoutCancel(ctx), cloneDir, branch); err != nil {
+		_, _ = fmt.Fprintf(stdout, "\nCould not fetch branch %s back into the repository: %v\n", branch, err)
+		_, _ = fmt.Fprintf(stdout, "To fetch it yourself:\n  git fetch %s %s\n", cloneDir, branch)
+		_, _ = fmt.Fprintf(stdout, "Isolated clone kept at %s\n", cloneDir)
+		return runErr
+	}
+	_, _ = fmt.Fprintf(stdout, "\n✓ Fetched branch %s from the isolated clone (not checked out)\n", branch)
+
+	if runErr != nil {
+		_, _ = fmt.Fprintf(stdout, "Isolated clone kept at %s\n", cloneDir)
+		return runErr
+	}
+	if err := os.RemoveAll(tmp); err != nil {
+		_, _ = fmt.Fprintf(stderr, "warning: failed to remove isolated clone %s: %v\n", cloneDir, err)
+	}
+	return nil
+}
+
+// copyDir copies the files under src into dst, creating directories as needed.
+// A missing src copies nothing.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == src && os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestRunIsolated(t *testing.T) {
+	workDir := t.TempDir()
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(workDir))
+	defer func() { _ = os.Chdir(originalDir) }()
+
+	runCmd(t, workDir, "git", "init", "-b", "main")
+	runCmd(t, workDir, "git", "config", "user.email", "test@example.com")
+	runCmd(t, workDir, "git", "config", "user.name", "Test User")
+	runCmd(t, workDir, "git", "config", "commit.gpgsign", "false")
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "README.md"), []byte("# Test\n"), 0644))
+	runCmd(t, workDir, "git", "add", "README.md")
+	runCmd(t, workDir, "git", "commit", "-m", "initial commit")
+
+	mockClaude := filepath.Join(t.TempDir(), "mock-claude.sh")
+	script := `#!/bin/bash
+echo '{"type":"system","subtype":"init","session_id":"test-session","model":"test-model"}'
+echo "change" >> output.txt
+echo '{"type":"result","subtype":"success","result":"done","total_cost_usd":0.0100,"usage":{"input_tokens":1,"output_tokens":1}}'
+`
+	require.NoError(t, os.WriteFile(mockClaude, []byte(script), 0755))
+
+	cfg, err := config.LoadConfigWithFile("")
+	require.NoError(t, err)
+	cfg.Claude.Command = []string{mockClaude}
+	cfg.Claude.Args = nil
+
+	store, err := taskstore.NewLocalStore(filepath.Join(workDir, config.DefaultTasksPath))
+	require.NoError(t, err)
+	now := time.Now().Truncate(time.Second)
+	parent := &taskstore.Task{ID: "parent-task", Title: "Parent Task", Status: taskstore.StatusOpen, CreatedAt: now, UpdatedAt: now}
+	child := &taskstore.Task{ID: "child-task", Title: "Child Task", ParentID: &parent.ID, Status: taskstore.StatusOpen, Verify: [][]string{{"echo", "ok"}}, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, store.Save(parent))
+	require.NoError(t, store.Save(child))
+
+	var stdout, stderr bytes.Buffer
+	err = Run(context.Background(), workDir, cfg, parent.ID, Options{MaxIterations: 1, Branch: "isolated-work", Isolated: true}, &stdout, &stderr)
+	require.NoError(t, err, stderr.String())
+
+	output := stdout.String()
+	assert.Contains(t, output, "Running in isolated clone:")
+	assert.Contains(t, output, "📝 Committed:")
+	assert.Contains(t, output, "✓ Fetched branch ralph/isolated-work from the isolated clone (not checked out)")
+
+	assert.NoFileExists(t, filepath.Join(workDir, "output.txt"), "the working directory is untouched")
+	branch, err := exec.Command("git", "-C", workDir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "main", strings.TrimSpace(string(branch)))
+
+	show, err := exec.Command("git", "-C", workDir, "show", "ralph/isolated-work:output.txt").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "change\n", string(show))
+
+	cloneLine := output[strings.Index(output, "Running in isolated clone: "):]
+	cloneDir := strings.TrimSpace(strings.SplitN(strings.TrimPrefix(cloneLine, "Running in isolated clone: "), "\n", 2)[0])
+	assert.NoDirExists(t, cloneDir, "the clone is removed once its branch is fetched back")
+}
+
+func TestCopyDir(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "logs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "tasks.json"), []byte("tasks"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "logs", "iter.json"), []byte("iter"), 0600))
+
+	dst := filepath.Join(t.TempDir(), "copy")
+	require.NoError(t, copyDir(src, dst))
+
+	data, err := os.ReadFile(filepath.Join(dst, "logs", "iter.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "iter", string(data))
+	info, err := os.Stat(filepath.Join(dst, "logs", "iter.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, copyDir(filepath.Join(src, "missing"), filepath.Join(t.TempDir(), "none")), "a missing source copies nothing")
+}
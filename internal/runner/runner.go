@@ -5,20 +5,29 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
+	"math/rand/v2"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
 
 	"golang.org/x/term"
 
 	"github.com/yarlson/ralph/internal/claude"
+	"github.com/yarlson/ralph/internal/color"
 	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/decomposer"
+	"github.com/yarlson/ralph/internal/eventsock"
 	gitpkg "github.com/yarlson/ralph/internal/git"
 	"github.com/yarlson/ralph/internal/loop"
 	"github.com/yarlson/ralph/internal/memory"
 	"github.com/yarlson/ralph/internal/opencode"
 	"github.com/yarlson/ralph/internal/provider"
+	"github.com/yarlson/ralph/internal/redact"
 	"github.com/yarlson/ralph/internal/selector"
 	"github.com/yarlson/ralph/internal/state"
 	"github.com/yarlson/ralph/internal/taskstore"
@@ -28,16 +37,55 @@ import (
 // Options configures a run.
 type Options struct {
 	Once          bool
+	Focus         string // Run only this task through its full retry lifecycle
 	MaxIterations int
+	MaxCostUSD    float64 // Stop once the run has cost this much (0 = unlimited)
+	BudgetWarnAt  float64 // Warn once spending reaches this fraction of MaxCostUSD (0 = never)
 	Branch        string
 	Stream        bool // Stream agent output to console
 	Provider      string
+	Shuffle       bool   // Randomize selection among ready tasks
+	ShuffleSeed   *int64 // Seed for Shuffle (nil = derive from current time)
+	Force         bool   // Clear gutter history left by a previous run
+
+	ContinueOnFailure bool              // Attempt dependents of permanently failed tasks
+	Annotations       map[string]string // Metadata attached to every iteration record
+	StashDirty        bool              // Stash uncommitted changes at start instead of refusing
+	EventSocket       string            // Unix socket path for streaming run events
+	EventLog          string            // File to append JSON Lines run events to
+	Quiet             bool              // Suppress per-step progress, keep the final summary
+	Verbose           bool              // Add diff stats, selection reasoning, and verification output
+	ProfileRun        bool              // Time each iteration phase and print a breakdown
+	NoColor           bool              // Never color progress output
+	Step              bool              // Ask before each iteration (interactive terminals only)
+	Stdin             io.Reader         // Answers to Step prompts
+	NoVerifyCache     bool              // Always run verify commands, even on a tree they passed on before
+	Isolated          bool              // Run in a temporary clone and fetch the resulting branch back
+	Concurrency       int               // Independent ready tasks to run at once, each in a git worktree (< 2 = one at a time)
 }
 
 // Run executes the main iteration loop.
 func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID string, opts Options, stdout, stderr io.Writer) error {
+	// One ralph at a time per repository; the isolated clone's copy of the
+	// lock holds this process's PID and is shared.
+	lock, err := state.AcquireLock(filepath.Join(workDir, config.DefaultRepoRoot))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+
+	if opts.Isolated {
+		opts.Isolated = false
+		return RunIsolated(ctx, workDir, cfg, parentTaskID, opts, stdout, stderr)
+	}
+
 	repoRoot := filepath.Join(workDir, config.DefaultRepoRoot)
 
+	// Limits changed with `ralph budget set` belong to the previous run
+	if err := loop.ClearBudgetOverride(state.BudgetOverrideFilePath(repoRoot)); err != nil {
+		return err
+	}
+
 	// Check if paused - auto-resume if so
 	paused, err := state.IsPaused(repoRoot)
 	if err == nil && paused {
@@ -46,12 +94,13 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 		}
 
 		tasksPath := filepath.Join(repoRoot, config.DefaultTasksPath)
-		store, storeErr := taskstore.NewLocalStore(tasksPath)
+		store, storeErr := taskstore.OpenStore(cfg.Tasks.Backend, tasksPath)
 		var taskTitle string
 		if storeErr == nil {
 			if parentTask, getErr := store.Get(parentTaskID); getErr == nil {
 				taskTitle = parentTask.Title
 			}
+			_ = taskstore.CloseStore(store)
 		}
 
 		if taskTitle != "" {
@@ -66,6 +115,13 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 		return fmt.Errorf("failed to create .ralph directory: %w", err)
 	}
 
+	// Discard gutter history if the user has dealt with the stuck condition
+	if opts.Force {
+		if err := state.ClearGutterState(repoRoot); err != nil {
+			return err
+		}
+	}
+
 	providerName, err := provider.Resolve(opts.Provider, cfg.Provider)
 	if err != nil {
 		return err
@@ -73,10 +129,11 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 
 	// Open task store
 	tasksPath := filepath.Join(repoRoot, config.DefaultTasksPath)
-	store, err := taskstore.NewLocalStore(tasksPath)
+	store, err := taskstore.OpenStore(cfg.Tasks.Backend, tasksPath)
 	if err != nil {
 		return fmt.Errorf("failed to open task store: %w", err)
 	}
+	defer func() { _ = taskstore.CloseStore(store) }()
 
 	// Validate parent task exists
 	_, err = store.Get(parentTaskID)
@@ -105,6 +162,9 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 		}
 	}
 
+	// Forward configured secrets to the agent and verify commands
+	passthrough := passthroughEnv(cfg.Claude.EnvPassthrough, stderr)
+
 	// Create Claude or OpenCode runner
 	var claudeRunner claude.Runner
 	switch providerName {
@@ -123,6 +183,9 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 		if len(openCodeArgs) > 0 {
 			openCodeRunner.WithBaseArgs(openCodeArgs)
 		}
+		if len(cfg.Claude.EnvPassthrough) > 0 {
+			openCodeRunner.WithEnvPassthrough(passthrough)
+		}
 		claudeRunner = openCodeRunner
 	default:
 		claudeCommand := "claude"
@@ -139,6 +202,9 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 		if len(claudeArgs) > 0 {
 			claudeSubprocess.WithBaseArgs(claudeArgs)
 		}
+		if len(cfg.Claude.EnvPassthrough) > 0 {
+			claudeSubprocess.WithEnvPassthrough(passthrough)
+		}
 		claudeRunner = claudeSubprocess
 	}
 
@@ -147,15 +213,83 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 	if cfg.Safety.Sandbox && len(cfg.Safety.AllowedCommands) > 0 {
 		ver.SetAllowedCommands(cfg.Safety.AllowedCommands)
 	}
+	verifyEnv, err := verifyEnv(passthrough, cfg.Verify.Env)
+	if err != nil {
+		return err
+	}
+	ver.SetEnv(verifyEnv)
+	ver.SetRestrictEnv(len(cfg.Claude.EnvPassthrough) > 0)
+	ver.SetShell(cfg.Verify.Shell)
+	if cfg.Verify.Timeout < 0 {
+		return fmt.Errorf("invalid verify.timeout %s: must not be negative", cfg.Verify.Timeout)
+	}
+	ver.SetCommandTimeout(cfg.Verify.Timeout)
+	ver.SetParallelism(cfg.Verify.Parallel)
+	exitCodes := make(verifier.ExitCodes, len(cfg.Verify.ExitCodes))
+	for _, m := range cfg.Verify.ExitCodes {
+		if len(m.Command) == 0 {
+			return fmt.Errorf("invalid verify.exit_codes: an entry has no command")
+		}
+		codes, err := verifier.ParseExitCodeMapping(m.ExitCodes)
+		if err != nil {
+			return fmt.Errorf("invalid verify.exit_codes for %v: %w", m.Command, err)
+		}
+		exitCodes.Set(m.Command, codes)
+	}
+	ver.SetExitCodes(exitCodes)
 
 	// Create git manager
 	gitManager := gitpkg.NewShellManager(repoRoot, config.DefaultBranchPrefix)
+	gitManager.SetSigning(cfg.Git.SignCommits, cfg.Git.SigningKey)
+
+	// Mark tasks whose branches were merged as completed
+	if cfg.Git.MergedStatus.Enabled {
+		merged := cfg.Git.MergedStatus
+		reconciled, err := taskstore.ReconcileMerged(ctx, store, gitManager, merged.Target, merged.Branches)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "warning: failed to read task status from merged branches: %v\n", err)
+		}
+		for _, t := range reconciled {
+			_, _ = fmt.Fprintf(stdout, "✓ %s completed (branch merged into %s)\n", t.ID, merged.Target)
+		}
+	}
+
+	// Prune old iteration records so the logs directory stays bounded
+	retention := loop.RetentionPolicy{
+		MaxAge:     time.Duration(cfg.Logs.RetentionDays) * 24 * time.Hour,
+		MaxRecords: cfg.Logs.MaxRecords,
+	}
+	if cfg.Logs.Archive {
+		retention.ArchiveDir = state.LogsArchiveDirPath(repoRoot)
+	}
+	pruned, err := loop.PruneRecords(logsDir, retention, time.Now())
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "warning: failed to prune iteration records: %v\n", err)
+	}
+	if len(pruned) > 0 {
+		action := "deleted"
+		if retention.ArchiveDir != "" {
+			action = "archived"
+		}
+		_, _ = fmt.Fprintf(stdout, "🧹 Pruned %d old iteration record(s) (%s)\n", len(pruned), action)
+	}
 
 	streamWriter := io.Writer(nil)
 	if opts.Stream {
 		streamWriter = stdout
 	}
 
+	// Append structured events to a file for external monitors if requested
+	eventWriter := io.Writer(nil)
+	if opts.EventLog != "" {
+		eventLog, err := os.OpenFile(opts.EventLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open event log: %w", err)
+		}
+		defer func() { _ = eventLog.Close() }()
+		eventWriter = eventLog
+	}
+
 	// Build controller dependencies
 	deps := loop.ControllerDeps{
 		TaskStore:      store,
@@ -168,6 +302,7 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 		WorkDir:        repoRoot,
 		ProgressWriter: stdout,
 		StreamWriter:   streamWriter,
+		EventWriter:    eventWriter,
 	}
 
 	// Create controller
@@ -181,7 +316,39 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 	if opts.MaxIterations > 0 {
 		budgetLimits.MaxIterations = opts.MaxIterations
 	}
+	if opts.MaxCostUSD < 0 {
+		return fmt.Errorf("invalid --max-cost %v: must not be negative", opts.MaxCostUSD)
+	}
+	budgetLimits.MaxCostUSD = opts.MaxCostUSD
+	if opts.BudgetWarnAt < 0 || opts.BudgetWarnAt >= 1 {
+		return fmt.Errorf("invalid --budget-warn-at %v: must be a fraction between 0 and 1", opts.BudgetWarnAt)
+	}
+	if opts.BudgetWarnAt > 0 && opts.MaxCostUSD == 0 {
+		_, _ = fmt.Fprintln(stderr, "warning: --budget-warn-at has no effect without --max-cost")
+	}
+	budgetLimits.WarnAtFraction = opts.BudgetWarnAt
+	if cfg.Budget.MaxInputTokens < 0 {
+		return fmt.Errorf("invalid budget.max_input_tokens %d: must not be negative", cfg.Budget.MaxInputTokens)
+	}
+	if cfg.Budget.MaxOutputTokens < 0 {
+		return fmt.Errorf("invalid budget.max_output_tokens %d: must not be negative", cfg.Budget.MaxOutputTokens)
+	}
+	budgetLimits.MaxInputTokens = cfg.Budget.MaxInputTokens
+	budgetLimits.MaxOutputTokens = cfg.Budget.MaxOutputTokens
 	controller.SetBudgetLimits(budgetLimits)
+	if cfg.Budget.Persist {
+		tracker, err := loop.NewBudgetTrackerFromState(state.StateDirPath(repoRoot), parentTaskID, budgetLimits)
+		if err != nil {
+			return err
+		}
+		controller.SetBudgetTracker(tracker)
+	}
+	if err := controller.SetBudgetAllocation(loop.BudgetAllocation(cfg.Budget.PerTaskAllocation)); err != nil {
+		return fmt.Errorf("invalid budget.per_task_allocation: %w", err)
+	}
+	if cfg.Budget.PerTaskAllocation == string(loop.AllocationProportional) && opts.MaxCostUSD == 0 {
+		_, _ = fmt.Fprintln(stderr, "warning: budget.per_task_allocation has no effect without --max-cost")
+	}
 
 	// Configure gutter detection
 	gutterConfig := loop.GutterConfig{
@@ -199,17 +366,139 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 	// Configure max retries
 	controller.SetMaxRetries(config.DefaultMaxRetries)
 	controller.SetMaxVerificationRetries(config.DefaultMaxVerificationRetries)
+	if cfg.Run.RetryBackoff < 0 {
+		return fmt.Errorf("invalid run.retry_backoff %s: must not be negative", cfg.Run.RetryBackoff)
+	}
+	controller.SetRetryBackoff(cfg.Run.RetryBackoff, cfg.Run.RetryBackoffFactor)
 
 	// Configure branch override if specified
 	if opts.Branch != "" {
 		controller.SetBranchOverride(opts.Branch)
 	}
+	controller.SetProtectedBranches(cfg.Git.ProtectedBranches)
+	controller.SetRequireCleanTree(cfg.Git.RequireClean)
+	controller.SetGitAutoInit(cfg.Git.AutoInit)
+	controller.SetRequireAllCompleted(cfg.Run.RequireAllCompleted)
+	controller.SetRequireVerifyCommands(cfg.Verify.RequireCommands)
+	controller.SetBuildFirst(cfg.Verify.BuildFirst)
+	if !opts.NoVerifyCache {
+		controller.SetVerifyCache(state.VerifyCacheFilePath(repoRoot))
+		controller.SetVerifyCacheInputs(cfg.Verify.CacheInputs)
+	}
+	controller.SetVerifySets(cfg.Verify.Sets)
+	controller.SetFormatCommand(cfg.Git.FormatCommand)
+	controller.SetVerifyFullEvery(cfg.Verify.FullEvery)
+	controller.SetMaxConcurrency(opts.Concurrency)
+	if len(cfg.Selector.ExternalCommand) > 0 {
+		controller.SetExternalSelector(selector.NewExternalCommand(cfg.Selector.ExternalCommand, repoRoot))
+	}
+	controller.SetRedactor(redact.New(slices.Collect(maps.Values(passthrough))...))
+	if opts.StashDirty {
+		controller.SetStashDirty(true)
+	}
 
 	// Configure sandbox mode if enabled
 	if cfg.Safety.Sandbox {
 		controller.SetSandboxMode(cfg.Safety.Sandbox, cfg.Safety.AllowedCommands)
 	}
 
+	// Plan tasks before implementing them when enabled (or labelled planning: true)
+	controller.SetPlanning(loop.PlanningConfig{Enabled: cfg.Planning.Enabled, Model: cfg.Planning.Model})
+
+	// Retries build on the previous attempt's changes unless disabled
+	controller.SetPreserveChanges(cfg.Retry.PreserveChanges)
+	if err := controller.SetUnrecoverablePatterns(cfg.Retry.UnrecoverablePatterns); err != nil {
+		return fmt.Errorf("invalid retry.unrecoverable_patterns: %w", err)
+	}
+	if cfg.Retry.AutoSplitOnFailure {
+		controller.SetTaskSplitter(decomposer.NewSplitter(claudeRunner, repoRoot, ""))
+	}
+
+	if err := controller.SetCommitMode(loop.CommitMode(cfg.Git.CommitMode)); err != nil {
+		return fmt.Errorf("invalid git.commit_mode: %w", err)
+	}
+	commitTemplate, err := gitpkg.ParseCommitTemplate(cfg.Git.CommitTemplate)
+	if err != nil {
+		return fmt.Errorf("invalid git.commit_template: %w", err)
+	}
+	controller.SetCommitTemplate(commitTemplate)
+	controller.SetPush(loop.PushConfig{
+		PushOnComplete: cfg.Git.PushOnComplete,
+		Remote:         cfg.Git.PushRemote,
+		PRCommand:      cfg.Git.PRCommand,
+	})
+	if len(cfg.Git.PRCommand) > 0 && !cfg.Git.PushOnComplete {
+		_, _ = fmt.Fprintln(stderr, "warning: git.pr_command has no effect without git.push_on_complete")
+	}
+	if err := controller.SetScopeMode(loop.ScopeMode(cfg.Safety.OutOfScope)); err != nil {
+		return fmt.Errorf("invalid safety.out_of_scope: %w", err)
+	}
+	if cfg.Git.CommitMode == string(loop.CommitPerRun) && cfg.Experimental.Checkpoints {
+		_, _ = fmt.Fprintln(stderr, "warning: experimental.checkpoints is ignored with git.commit_mode per_run")
+	}
+
+	// Enable experimental checkpoint commits
+	if cfg.Experimental.Checkpoints {
+		controller.SetCheckpoints(true)
+	}
+
+	// Configure randomized task selection if requested
+	if opts.Shuffle {
+		seed := time.Now().UnixNano()
+		if opts.ShuffleSeed != nil {
+			seed = *opts.ShuffleSeed
+		}
+		controller.SetSelectionRand(rand.New(rand.NewPCG(uint64(seed), uint64(seed))))
+		_, _ = fmt.Fprintf(stdout, "Shuffling task selection (seed: %d)\n", seed)
+	}
+
+	// Stamp external metadata onto iteration records
+	if len(opts.Annotations) > 0 {
+		controller.SetAnnotations(opts.Annotations)
+	}
+
+	// Keep going past failed tasks if requested
+	if opts.ContinueOnFailure {
+		controller.SetContinueOnFailure(true)
+		_, _ = fmt.Fprintf(stdout, "Continuing past failures: dependents of failed tasks will still be attempted\n")
+	}
+
+	// Configure progress verbosity
+	switch {
+	case opts.Quiet:
+		controller.SetVerbosity(loop.VerbosityQuiet)
+	case opts.Verbose:
+		controller.SetVerbosity(loop.VerbosityVerbose)
+	}
+
+	// Color outcomes when writing to a terminal
+	controller.SetColor(color.Enabled(stdout, opts.NoColor))
+
+	// Time prompt, agent, verification, and git phases if requested
+	if opts.ProfileRun {
+		controller.SetProfile(true)
+	}
+
+	// Ask before each iteration when single-stepping on a terminal
+	if opts.Step {
+		if opts.Stdin != nil && IsTerminal(opts.Stdin) {
+			controller.SetStepper(loop.NewPromptStepper(stdout, opts.Stdin))
+		} else {
+			_, _ = fmt.Fprintln(stderr, "warning: --step needs an interactive terminal; running without prompts")
+		}
+	}
+
+	// Stream structured events to local tools if requested
+	if opts.EventSocket != "" {
+		server, err := eventsock.Listen(opts.EventSocket)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = server.Close() }()
+		controller.SetEventSink(server)
+		_, _ = fmt.Fprintf(stdout, "Streaming events on %s\n", opts.EventSocket)
+	}
+
 	// Set up context with signal handling for graceful shutdown
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -223,10 +512,18 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 	}()
 
 	// Run the loop
-	_, _ = fmt.Fprintf(stdout, "Starting ralph loop for parent task: %s\n\n", parentTaskID)
+	if !opts.Quiet {
+		if opts.Focus != "" {
+			_, _ = fmt.Fprintf(stdout, "Starting ralph loop for parent task: %s (focusing on %s)\n\n", parentTaskID, opts.Focus)
+		} else {
+			_, _ = fmt.Fprintf(stdout, "Starting ralph loop for parent task: %s\n\n", parentTaskID)
+		}
+	}
 
 	var result loop.RunResult
-	if opts.Once {
+	if opts.Focus != "" {
+		result = controller.RunFocus(ctx, parentTaskID, opts.Focus)
+	} else if opts.Once {
 		result = controller.RunOnce(ctx, parentTaskID)
 	} else {
 		result = controller.RunLoop(ctx, parentTaskID)
@@ -234,6 +531,9 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 
 	// Output result
 	_, _ = fmt.Fprintf(stdout, "\n%s", FormatRunResult(result))
+	if result.Outcome == loop.RunOutcomeGutterDetected {
+		_, _ = fmt.Fprintf(stdout, "\nGutter history is kept across runs. Fix the underlying issue, then rerun with --force to clear it.\n")
+	}
 
 	// Return error if the outcome indicates failure
 	if result.Outcome == loop.RunOutcomeError {
@@ -243,6 +543,38 @@ func Run(ctx context.Context, workDir string, cfg *config.Config, parentTaskID s
 	return nil
 }
 
+// passthroughEnv reads the named environment variables for forwarding to
+// subprocesses. Unset variables are skipped with a warning.
+func passthroughEnv(names []string, stderr io.Writer) map[string]string {
+	env := make(map[string]string, len(names))
+	for _, name := range names {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			_, _ = fmt.Fprintf(stderr, "warning: claude.env_passthrough: %s is not set\n", name)
+			continue
+		}
+		env[name] = value
+	}
+	return env
+}
+
+// verifyEnv returns the environment added to verify commands: the passthrough
+// variables overridden by the KEY=value entries of verify.env.
+func verifyEnv(passthrough map[string]string, entries []string) (map[string]string, error) {
+	env := maps.Clone(passthrough)
+	if env == nil {
+		env = make(map[string]string, len(entries))
+	}
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid verify.env entry %q: want KEY=value", entry)
+		}
+		env[name] = value
+	}
+	return env, nil
+}
+
 // FormatRunResult formats a RunResult for CLI output.
 func FormatRunResult(result loop.RunResult) string {
 	output := fmt.Sprintf("## Run Result: %s\n\n", result.Outcome)
@@ -254,6 +586,12 @@ func FormatRunResult(result loop.RunResult) string {
 	if len(result.FailedTasks) > 0 {
 		output += fmt.Sprintf("- Failed tasks: %d failed\n", len(result.FailedTasks))
 	}
+	if len(result.UnverifiedTasks) > 0 {
+		output += fmt.Sprintf("- Unverified: %d completed without verify commands\n", len(result.UnverifiedTasks))
+	}
+	if len(result.AwaitingReviewTasks) > 0 {
+		output += fmt.Sprintf("- Awaiting review: %d finished without verify commands\n", len(result.AwaitingReviewTasks))
+	}
 
 	if result.TotalCostUSD > 0 {
 		output += fmt.Sprintf("- Total cost: $%.4f\n", result.TotalCostUSD)
@@ -263,9 +601,27 @@ func FormatRunResult(result loop.RunResult) string {
 		output += fmt.Sprintf("- Elapsed time: %s\n", result.ElapsedTime.Round(1000000000))
 	}
 
+	if result.RunCommit != "" {
+		output += fmt.Sprintf("- Commit: %s\n", result.RunCommit)
+	}
+	if result.PullRequestURL != "" {
+		output += fmt.Sprintf("- Pull request: %s\n", result.PullRequestURL)
+	}
+
 	if len(result.CompletedTasks) > 0 {
 		output += "\n### Completed Tasks\n"
 		for _, taskID := range result.CompletedTasks {
+			if slices.Contains(result.UnverifiedTasks, taskID) {
+				output += fmt.Sprintf("- %s (unverified)\n", taskID)
+				continue
+			}
+			output += fmt.Sprintf("- %s\n", taskID)
+		}
+	}
+
+	if len(result.AwaitingReviewTasks) > 0 {
+		output += "\n### Awaiting Review\n"
+		for _, taskID := range result.AwaitingReviewTasks {
 			output += fmt.Sprintf("- %s\n", taskID)
 		}
 	}
@@ -277,6 +633,45 @@ func FormatRunResult(result loop.RunResult) string {
 		}
 	}
 
+	if len(result.Profile) > 0 {
+		output += "\n" + formatProfileTable(result.Profile)
+	}
+
+	return output
+}
+
+// formatProfileTable formats per-iteration phase timings with a total row
+// showing each phase's share of the profiled time.
+func formatProfileTable(profiles []loop.IterationProfile) string {
+	output := "### Profile\n"
+	output += "| # | Task |"
+	for _, phase := range loop.Phases() {
+		output += fmt.Sprintf(" %s |", phase)
+	}
+	output += " other | total |\n"
+	output += "|---|------|" + strings.Repeat("---|", len(loop.Phases())+2) + "\n"
+
+	for i, p := range profiles {
+		output += fmt.Sprintf("| %d | %s |", i+1, p.TaskID)
+		for _, phase := range loop.Phases() {
+			output += fmt.Sprintf(" %s |", p.Phases[phase].Round(time.Millisecond))
+		}
+		output += fmt.Sprintf(" %s | %s |\n", p.Other().Round(time.Millisecond), p.Total.Round(time.Millisecond))
+	}
+
+	sum := loop.SumProfiles(profiles)
+	share := func(d time.Duration) string {
+		if sum.Total <= 0 {
+			return d.Round(time.Millisecond).String()
+		}
+		return fmt.Sprintf("%s (%.0f%%)", d.Round(time.Millisecond), 100*float64(d)/float64(sum.Total))
+	}
+	output += "| | **Total** |"
+	for _, phase := range loop.Phases() {
+		output += fmt.Sprintf(" %s |", share(sum.Phases[phase]))
+	}
+	output += fmt.Sprintf(" %s | %s |\n", share(sum.Other()), sum.Total.Round(time.Millisecond))
+
 	return output
 }
 
@@ -289,7 +684,7 @@ func IsTerminal(r io.Reader) bool {
 }
 
 // ValidateTaskHasReadyLeaves checks that a task has at least one ready leaf descendant.
-func ValidateTaskHasReadyLeaves(store *taskstore.LocalStore, taskID string) error {
+func ValidateTaskHasReadyLeaves(store taskstore.Store, taskID string) error {
 	allTasks, err := store.List()
 	if err != nil {
 		return fmt.Errorf("failed to list tasks: %w", err)
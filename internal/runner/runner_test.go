@@ -1,8 +1,11 @@
 package runner
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -13,6 +16,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/loop"
+	"github.com/yarlson/ralph/internal/state"
 	"github.com/yarlson/ralph/internal/taskstore"
 )
 
@@ -81,6 +86,137 @@ echo '{"type":"result","subtype":"success","result":"done","total_cost_usd":0.01
 	assert.Contains(t, output, "📝 Committed:")
 }
 
+func TestRun_VerifyEnvFromConfig(t *testing.T) {
+	workDir, cfg, store := setupRunRepo(t)
+	parent := saveRunTasks(t, store, []string{`test "$DATABASE_URL" = postgres://localhost/test`})
+	cfg.Verify.Env = []string{"DATABASE_URL=postgres://localhost/test"}
+	cfg.Verify.Shell = []string{"sh", "-c"}
+
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), workDir, cfg, parent.ID, Options{Once: true}, &stdout, &stderr)
+	require.NoError(t, err)
+
+	assert.Contains(t, stdout.String(), "✓ Verification: 1/1 passed")
+
+	cfg.Verify.Env = []string{"DATABASE_URL"}
+	err = Run(context.Background(), workDir, cfg, parent.ID, Options{Once: true}, &stdout, &stderr)
+	assert.EqualError(t, err, `invalid verify.env entry "DATABASE_URL": want KEY=value`)
+}
+
+// TestRunLockHolderProcess is not a real test: the lock test runs the test
+// binary with it to hold the run lock in another process.
+func TestRunLockHolderProcess(t *testing.T) {
+	root := os.Getenv("RALPH_TEST_LOCK_ROOT")
+	if root == "" {
+		t.Skip("helper process for the lock test")
+	}
+	if _, err := state.AcquireLock(root); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("locked")
+	_, _ = io.Copy(io.Discard, os.Stdin)
+}
+
+func TestRun_RefusesWhileAnotherRalphHoldsTheLock(t *testing.T) {
+	workDir, cfg, store := setupRunRepo(t)
+	parent := saveRunTasks(t, store, []string{"echo", "ok"})
+
+	holder := exec.Command(os.Args[0], "-test.run=^TestRunLockHolderProcess$")
+	holder.Env = append(os.Environ(), "RALPH_TEST_LOCK_ROOT="+workDir)
+	stdin, err := holder.StdinPipe()
+	require.NoError(t, err)
+	out, err := holder.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, holder.Start())
+	defer func() { _ = holder.Process.Kill() }()
+	line, err := bufio.NewReader(out).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "locked\n", line)
+
+	var stdout, stderr bytes.Buffer
+	err = Run(context.Background(), workDir, cfg, parent.ID, Options{Once: true}, &stdout, &stderr)
+	assert.EqualError(t, err, fmt.Sprintf("another ralph is running (pid %d)", holder.Process.Pid))
+	assert.Empty(t, stdout.String())
+
+	require.NoError(t, stdin.Close())
+	require.NoError(t, holder.Wait())
+	require.NoError(t, Run(context.Background(), workDir, cfg, parent.ID, Options{Once: true}, &stdout, &stderr))
+	info, err := state.ReadLock(workDir)
+	require.NoError(t, err)
+	assert.Nil(t, info, "the lock is released")
+}
+
+func TestVerifyEnv(t *testing.T) {
+	env, err := verifyEnv(map[string]string{"TOKEN": "secret", "MODE": "ci"}, []string{"MODE=test", "DSN=a=b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"TOKEN": "secret", "MODE": "test", "DSN": "a=b"}, env)
+
+	_, err = verifyEnv(nil, []string{"=value"})
+	assert.Error(t, err)
+}
+
+// setupRunRepo creates a git repository, in which the test then runs, with
+// an agent that appends to output.txt, and the task store Run reads.
+func setupRunRepo(t *testing.T) (string, *config.Config, *taskstore.LocalStore) {
+	t.Helper()
+	workDir := t.TempDir()
+
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(workDir))
+	t.Cleanup(func() { _ = os.Chdir(originalDir) })
+
+	runCmd(t, workDir, "git", "init")
+	runCmd(t, workDir, "git", "config", "user.email", "test@example.com")
+	runCmd(t, workDir, "git", "config", "user.name", "Test User")
+	runCmd(t, workDir, "git", "config", "commit.gpgsign", "false")
+
+	mockClaude := filepath.Join(workDir, "mock-claude.sh")
+	script := `#!/bin/bash
+echo '{"type":"system","subtype":"init","session_id":"test-session","model":"test-model"}'
+echo '{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"working"}]}}'
+echo "change" >> output.txt
+echo '{"type":"result","subtype":"success","result":"done","total_cost_usd":0.0100,"usage":{"input_tokens":1,"output_tokens":1}}'
+`
+	require.NoError(t, os.WriteFile(mockClaude, []byte(script), 0755))
+
+	cfg, err := config.LoadConfigWithFile("")
+	require.NoError(t, err)
+	cfg.Claude.Command = []string{mockClaude}
+	cfg.Claude.Args = nil
+
+	store, err := taskstore.NewLocalStore(filepath.Join(workDir, config.DefaultTasksPath))
+	require.NoError(t, err)
+	return workDir, cfg, store
+}
+
+// saveRunTasks saves a parent task with one child verified by verify.
+func saveRunTasks(t *testing.T, store *taskstore.LocalStore, verify []string) *taskstore.Task {
+	t.Helper()
+	now := time.Now().Truncate(time.Second)
+	parent := &taskstore.Task{
+		ID:        "parent-task",
+		Title:     "Parent Task",
+		Status:    taskstore.StatusOpen,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	child := &taskstore.Task{
+		ID:        "child-task",
+		Title:     "Child Task",
+		ParentID:  &parent.ID,
+		Status:    taskstore.StatusOpen,
+		Verify:    [][]string{verify},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	require.NoError(t, store.Save(parent))
+	require.NoError(t, store.Save(child))
+	return parent
+}
+
 func runCmd(t *testing.T, dir string, name string, args ...string) {
 	t.Helper()
 	cmd := exec.Command(name, args...)
@@ -91,3 +227,35 @@ func runCmd(t *testing.T, dir string, name string, args ...string) {
 		t.Fatalf("command failed: %s %v\n%s", name, args, string(output))
 	}
 }
+
+func TestFormatRunResult_Profile(t *testing.T) {
+	result := loop.RunResult{
+		Outcome: loop.RunOutcomeCompleted,
+		Profile: []loop.IterationProfile{
+			{TaskID: "task-a", Total: 4 * time.Second, Phases: map[loop.Phase]time.Duration{loop.PhaseAgent: 3 * time.Second}},
+			{TaskID: "task-b", Total: 4 * time.Second, Phases: map[loop.Phase]time.Duration{loop.PhaseAgent: time.Second, loop.PhaseGit: time.Second}},
+		},
+	}
+
+	output := FormatRunResult(result)
+
+	assert.Contains(t, output, "### Profile\n| # | Task | prompt | agent | verification | git | other | total |")
+	assert.Contains(t, output, "| 1 | task-a | 0s | 3s | 0s | 0s | 1s | 4s |")
+	assert.Contains(t, output, "| | **Total** | 0s (0%) | 4s (50%) | 0s (0%) | 1s (12%) | 3s (38%) | 8s |")
+
+	assert.NotContains(t, FormatRunResult(loop.RunResult{}), "### Profile")
+}
+
+func TestFormatRunResult_RunCommit(t *testing.T) {
+	output := FormatRunResult(loop.RunResult{Outcome: loop.RunOutcomeCompleted, RunCommit: "abc123"})
+	assert.Contains(t, output, "- Commit: abc123\n")
+
+	assert.NotContains(t, FormatRunResult(loop.RunResult{}), "Commit:")
+}
+
+func TestFormatRunResult_PullRequest(t *testing.T) {
+	output := FormatRunResult(loop.RunResult{Outcome: loop.RunOutcomeCompleted, PullRequestURL: "https://example.com/pr/1"})
+	assert.Contains(t, output, "- Pull request: https://example.com/pr/1\n")
+
+	assert.NotContains(t, FormatRunResult(loop.RunResult{}), "Pull request:")
+}
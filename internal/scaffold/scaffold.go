@@ -0,0 +1,76 @@
+// Package scaffold writes starter project files from embedded templates.
+package scaffold
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrFileExists indicates a template file would overwrite an existing file.
+var ErrFileExists = errors.New("file already exists")
+
+//go:embed templates
+var templates embed.FS
+
+// templatesDir is the root of the embedded templates.
+const templatesDir = "templates"
+
+// Names returns the available template names in sorted order.
+func Names() []string {
+	entries, err := templates.ReadDir(templatesDir)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply writes the files of the named template into dir and returns the paths
+// written. Existing files are not overwritten unless force is set; in that case
+// nothing is written and ErrFileExists is returned.
+func Apply(dir, name string, force bool) ([]string, error) {
+	root := path.Join(templatesDir, name)
+	entries, err := templates.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+
+	if !force {
+		for _, e := range entries {
+			target := filepath.Join(dir, e.Name())
+			if _, err := os.Stat(target); err == nil {
+				return nil, fmt.Errorf("%w: %s (use --force to overwrite)", ErrFileExists, target)
+			}
+		}
+	}
+
+	var written []string
+	for _, e := range entries {
+		data, err := fs.ReadFile(templates, path.Join(root, e.Name()))
+		if err != nil {
+			return written, fmt.Errorf("failed to read template file %s: %w", e.Name(), err)
+		}
+
+		target := filepath.Join(dir, e.Name())
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		written = append(written, target)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,62 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/config"
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, []string{"generic", "go", "node"}, Names())
+}
+
+func TestApply_TemplatesAreValid(t *testing.T) {
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			written, err := Apply(dir, name, false)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{filepath.Join(dir, "ralph.yaml"), filepath.Join(dir, "tasks.yaml")}, written)
+
+			_, err = config.LoadConfigFromPath(filepath.Join(dir, "ralph.yaml"))
+			require.NoError(t, err)
+
+			store, err := taskstore.NewLocalStore(filepath.Join(dir, config.DefaultTasksPath))
+			require.NoError(t, err)
+			result, err := taskstore.ImportFromYAML(store, filepath.Join(dir, "tasks.yaml"))
+			require.NoError(t, err)
+			assert.Empty(t, result.Errors)
+			assert.Equal(t, 2, result.Imported)
+		})
+	}
+}
+
+func TestApply_UnknownTemplate(t *testing.T) {
+	_, err := Apply(t.TempDir(), "cobol", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "available: generic, go, node")
+}
+
+func TestApply_ExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "ralph.yaml")
+	require.NoError(t, os.WriteFile(existing, []byte("provider: opencode\n"), 0644))
+
+	_, err := Apply(dir, "go", false)
+	require.ErrorIs(t, err, ErrFileExists)
+	_, statErr := os.Stat(filepath.Join(dir, "tasks.yaml"))
+	assert.True(t, os.IsNotExist(statErr), "nothing is written when a file exists")
+
+	_, err = Apply(dir, "go", true)
+	require.NoError(t, err)
+	data, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "golangci-lint")
+}
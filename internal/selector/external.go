@@ -0,0 +1,98 @@
+package selector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+// DefaultExternalTimeout bounds how long an external selection command may run.
+const DefaultExternalTimeout = 30 * time.Second
+
+// ExternalRequest is the JSON document written to an external selection
+// command's stdin.
+type ExternalRequest struct {
+	// ParentID is the parent task whose descendants are being worked on.
+	ParentID string `json:"parent_id"`
+
+	// LastCompletedID is the task completed most recently in this run, if any.
+	LastCompletedID string `json:"last_completed_id,omitempty"`
+
+	// Ready lists the tasks that may be selected, in built-in selection order.
+	Ready []*taskstore.Task `json:"ready"`
+}
+
+// ExternalCommand delegates task selection to a user command. The command
+// reads an ExternalRequest as JSON on stdin and prints the chosen task ID on
+// stdout.
+type ExternalCommand struct {
+	command []string
+	workDir string
+	timeout time.Duration
+}
+
+// NewExternalCommand creates an ExternalCommand that runs command in workDir
+// (the current directory if empty).
+func NewExternalCommand(command []string, workDir string) *ExternalCommand {
+	return &ExternalCommand{
+		command: command,
+		workDir: workDir,
+		timeout: DefaultExternalTimeout,
+	}
+}
+
+// SetTimeout sets how long the command may run (0 = no limit).
+func (e *ExternalCommand) SetTimeout(timeout time.Duration) {
+	e.timeout = timeout
+}
+
+// Select runs the command and returns the task ID it printed. It fails if the
+// command fails, prints nothing, or picks a task that is not in req.Ready.
+func (e *ExternalCommand) Select(ctx context.Context, req ExternalRequest) (string, error) {
+	if len(e.command) == 0 {
+		return "", errors.New("no selection command configured")
+	}
+
+	input, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ready tasks: %w", err)
+	}
+
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, e.command[0], e.command[1:]...)
+	cmd.Dir = e.workDir
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("selection command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("selection command failed: %w", err)
+	}
+
+	id := strings.TrimSpace(stdout.String())
+	if id == "" {
+		return "", errors.New("selection command printed no task ID")
+	}
+	for _, t := range req.Ready {
+		if t.ID == id {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("selection command chose %q, which is not a ready task", id)
+}
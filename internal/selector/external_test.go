@@ -0,0 +1,113 @@
+package selector
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yarlson/ralph/internal/taskstore"
+)
+
+func TestExternalCommand_Select(t *testing.T) {
+	parent := "parent"
+	req := ExternalRequest{
+		ParentID:        parent,
+		LastCompletedID: "done",
+		Ready: []*taskstore.Task{
+			makeTaskWithLabels("a", taskstore.StatusOpen, &parent, nil, nil),
+			makeTaskWithLabels("b", taskstore.StatusOpen, &parent, nil, map[string]string{"priority": "high"}),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr string
+	}{
+		{name: "returns the printed task ID", script: "echo b", want: "b"},
+		{name: "trims whitespace", script: "printf '  a\\n\\n'", want: "a"},
+		{name: "command fails", script: "echo boom >&2; exit 3", wantErr: "boom"},
+		{name: "prints nothing", script: "true", wantErr: "printed no task ID"},
+		{name: "picks a task that is not ready", script: "echo zzz", wantErr: "not a ready task"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := NewExternalCommand([]string{"sh", "-c", tt.script}, t.TempDir())
+
+			id, err := ext.Select(context.Background(), req)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, id)
+		})
+	}
+
+	t.Run("writes the request as JSON on stdin", func(t *testing.T) {
+		dir := t.TempDir()
+		ext := NewExternalCommand([]string{"sh", "-c", "cat > input.json; echo a"}, dir)
+
+		_, err := ext.Select(context.Background(), req)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(dir, "input.json"))
+		require.NoError(t, err)
+		var got struct {
+			ParentID        string           `json:"parent_id"`
+			LastCompletedID string           `json:"last_completed_id"`
+			Ready           []map[string]any `json:"ready"`
+		}
+		require.NoError(t, json.Unmarshal(data, &got))
+		assert.Equal(t, "parent", got.ParentID)
+		assert.Equal(t, "done", got.LastCompletedID)
+		require.Len(t, got.Ready, 2)
+		assert.Equal(t, "b", got.Ready[1]["id"])
+		assert.Equal(t, map[string]any{"priority": "high"}, got.Ready[1]["labels"])
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		ext := NewExternalCommand([]string{"sleep", "5"}, "")
+		ext.SetTimeout(50 * time.Millisecond)
+
+		_, err := ext.Select(context.Background(), req)
+		require.Error(t, err)
+	})
+
+	t.Run("requires a command", func(t *testing.T) {
+		_, err := NewExternalCommand(nil, "").Select(context.Background(), req)
+		require.Error(t, err)
+	})
+}
+
+func TestReadyLeaves(t *testing.T) {
+	parent := "parent"
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := []*taskstore.Task{
+		makeTaskWithTime("parent", taskstore.StatusOpen, nil, nil, base),
+		makeTaskWithTime("late", taskstore.StatusOpen, &parent, nil, base.Add(2*time.Hour)),
+		makeTaskWithTime("early", taskstore.StatusOpen, &parent, nil, base.Add(time.Hour)),
+		makeTaskWithTime("blocked", taskstore.StatusOpen, &parent, []string{"late"}, base),
+		makeTaskWithTime("done", taskstore.StatusCompleted, &parent, nil, base),
+	}
+	graph, err := BuildGraph(tasks)
+	require.NoError(t, err)
+
+	ready := ReadyLeaves(tasks, graph, parent, SelectOptions{})
+
+	var ids []string
+	for _, task := range ready {
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, []string{"early", "late"}, ids)
+	assert.Nil(t, ReadyLeaves(tasks, graph, "", SelectOptions{}))
+}
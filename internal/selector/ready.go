@@ -8,6 +8,11 @@ import (
 // A task is ready if all of its dependencies (from dependsOn) are completed.
 // Returns a map from task ID to ready status.
 func ComputeReady(tasks []*taskstore.Task, graph *Graph) map[string]bool {
+	return computeReady(tasks, graph, false)
+}
+
+// computeReady is ComputeReady with the option to treat failed dependencies as satisfied.
+func computeReady(tasks []*taskstore.Task, graph *Graph, failedDepsSatisfied bool) map[string]bool {
 	// Build a status lookup map
 	statusByID := make(map[string]taskstore.TaskStatus)
 	for _, t := range tasks {
@@ -16,15 +21,16 @@ func ComputeReady(tasks []*taskstore.Task, graph *Graph) map[string]bool {
 
 	ready := make(map[string]bool)
 	for _, t := range tasks {
-		ready[t.ID] = isTaskReady(t, statusByID, graph)
+		ready[t.ID] = isTaskReady(t, statusByID, graph, failedDepsSatisfied)
 	}
 
 	return ready
 }
 
 // isTaskReady checks if a single task is ready.
-// A task is ready if it has no dependencies, or all its dependencies are completed.
-func isTaskReady(task *taskstore.Task, statusByID map[string]taskstore.TaskStatus, graph *Graph) bool {
+// A task is ready if it has no dependencies, or all its dependencies are completed
+// (or failed, when failedDepsSatisfied is set).
+func isTaskReady(task *taskstore.Task, statusByID map[string]taskstore.TaskStatus, graph *Graph, failedDepsSatisfied bool) bool {
 	deps := graph.Dependencies(task.ID)
 	if len(deps) == 0 {
 		return true
@@ -36,6 +42,9 @@ func isTaskReady(task *taskstore.Task, statusByID map[string]taskstore.TaskStatu
 			// Dependency not found in task list - treat as not ready
 			return false
 		}
+		if status == taskstore.StatusFailed && failedDepsSatisfied {
+			continue
+		}
 		if status != taskstore.StatusCompleted {
 			return false
 		}
@@ -1,6 +1,7 @@
 package selector
 
 import (
+	"math/rand/v2"
 	"sort"
 
 	"github.com/yarlson/ralph/internal/taskstore"
@@ -8,8 +9,9 @@ import (
 
 // SelectNext selects the next ready leaf task from descendants of the given parent.
 // It uses the following heuristics:
-// 1. Prefer tasks in the same "area" as the last completed task
-// 2. Otherwise, use deterministic ordering: createdAt (ascending), then ID (alphabetically)
+// 1. Prefer the highest priority (P0, then P1, then P2; unset counts as P1)
+// 2. Among those, prefer tasks in the same "area" as the last completed task
+// 3. Otherwise, use deterministic ordering: createdAt (ascending), then ID (alphabetically)
 //
 // Parameters:
 //   - tasks: all tasks to consider
@@ -19,33 +21,44 @@ import (
 //
 // Returns nil if no ready leaf task is found among descendants.
 func SelectNext(tasks []*taskstore.Task, graph *Graph, parentID string, lastCompleted *taskstore.Task) *taskstore.Task {
-	if parentID == "" {
-		return nil
-	}
+	return SelectNextWithRand(tasks, graph, parentID, lastCompleted, nil)
+}
 
-	// Get all descendants of the parent
-	descendants := getDescendants(tasks, parentID)
-	if len(descendants) == 0 {
-		return nil
-	}
+// SelectNextWithRand is like SelectNext, but when rng is non-nil it shuffles the
+// ready leaves of each priority before applying area preference, so equally eligible tasks are
+// picked in a random (but seed-reproducible) order. A nil rng keeps the
+// deterministic ordering of SelectNext.
+func SelectNextWithRand(tasks []*taskstore.Task, graph *Graph, parentID string, lastCompleted *taskstore.Task, rng *rand.Rand) *taskstore.Task {
+	return SelectNextWithOptions(tasks, graph, parentID, lastCompleted, SelectOptions{Rand: rng})
+}
 
-	// Build graph from descendants only (to filter properly)
-	// We need to use the full graph for dependency checking but filter to descendants
-	readyLeaves := getReadyLeavesFromSubset(descendants, graph)
+// SelectOptions configures optional task selection behavior.
+type SelectOptions struct {
+	// Rand shuffles equally eligible ready leaves when non-nil.
+	Rand *rand.Rand
+
+	// FailedDepsSatisfied treats failed dependencies as satisfied, so dependents
+	// of a permanently failed task can still be attempted.
+	FailedDepsSatisfied bool
+}
+
+// SelectNextWithOptions is like SelectNext with optional behavior from opts.
+// The zero SelectOptions behaves exactly like SelectNext.
+func SelectNextWithOptions(tasks []*taskstore.Task, graph *Graph, parentID string, lastCompleted *taskstore.Task, opts SelectOptions) *taskstore.Task {
+	readyLeaves := ReadyLeaves(tasks, graph, parentID, opts)
 	if len(readyLeaves) == 0 {
 		return nil
 	}
 
-	// Sort ready leaves by deterministic ordering first
-	sortTasksDeterministically(readyLeaves)
-
-	// Apply area preference if lastCompleted has an area label
+	// Apply area preference if lastCompleted has an area label, without
+	// passing over a higher-priority task
 	lastArea := getArea(lastCompleted)
 	if lastArea != "" {
-		// Find tasks with matching area
+		// Find top-priority tasks with matching area
+		topRank := readyLeaves[0].Priority.Rank()
 		var matchingArea []*taskstore.Task
 		for _, t := range readyLeaves {
-			if getArea(t) == lastArea {
+			if t.Priority.Rank() == topRank && getArea(t) == lastArea {
 				matchingArea = append(matchingArea, t)
 			}
 		}
@@ -60,6 +73,44 @@ func SelectNext(tasks []*taskstore.Task, graph *Graph, parentID string, lastComp
 	return readyLeaves[0]
 }
 
+// ReadyLeaves returns the ready leaf tasks under parentID that SelectNext
+// chooses from, in selection order before area preference: priority, then
+// createdAt, then ID, with each priority shuffled if opts.Rand is set.
+func ReadyLeaves(tasks []*taskstore.Task, graph *Graph, parentID string, opts SelectOptions) []*taskstore.Task {
+	if parentID == "" {
+		return nil
+	}
+
+	// Get all descendants of the parent
+	descendants := getDescendants(tasks, parentID)
+	if len(descendants) == 0 {
+		return nil
+	}
+
+	// Build graph from descendants only (to filter properly)
+	// We need to use the full graph for dependency checking but filter to descendants
+	readyLeaves := getReadyLeavesFromSubset(descendants, graph, opts.FailedDepsSatisfied)
+	if len(readyLeaves) == 0 {
+		return nil
+	}
+
+	// Sort ready leaves by deterministic ordering first
+	sortTasksDeterministically(readyLeaves)
+
+	// Optionally shuffle for exploration (sorted first so a seed is
+	// reproducible), keeping higher priorities first
+	if opts.Rand != nil {
+		opts.Rand.Shuffle(len(readyLeaves), func(i, j int) {
+			readyLeaves[i], readyLeaves[j] = readyLeaves[j], readyLeaves[i]
+		})
+		sort.SliceStable(readyLeaves, func(i, j int) bool {
+			return readyLeaves[i].Priority.Rank() < readyLeaves[j].Priority.Rank()
+		})
+	}
+
+	return readyLeaves
+}
+
 // getDescendants returns all tasks that are descendants of the given parent.
 // A descendant is any task that has the parent as an ancestor (direct or indirect parent).
 func getDescendants(tasks []*taskstore.Task, parentID string) []*taskstore.Task {
@@ -87,7 +138,7 @@ func getDescendants(tasks []*taskstore.Task, parentID string) []*taskstore.Task
 
 // getReadyLeavesFromSubset returns ready leaf tasks from the given subset of tasks.
 // Uses the provided graph for dependency checking.
-func getReadyLeavesFromSubset(tasks []*taskstore.Task, graph *Graph) []*taskstore.Task {
+func getReadyLeavesFromSubset(tasks []*taskstore.Task, graph *Graph, failedDepsSatisfied bool) []*taskstore.Task {
 	// Build status lookup map for ready computation
 	statusByID := make(map[string]taskstore.TaskStatus)
 	for _, t := range tasks {
@@ -97,7 +148,7 @@ func getReadyLeavesFromSubset(tasks []*taskstore.Task, graph *Graph) []*taskstor
 	// We also need to check statuses from the full graph for dependencies outside subset
 	// However, ComputeReady already handles this by checking graph.Dependencies
 
-	ready := ComputeReady(tasks, graph)
+	ready := computeReady(tasks, graph, failedDepsSatisfied)
 
 	var result []*taskstore.Task
 	for _, t := range tasks {
@@ -122,10 +173,15 @@ func getReadyLeavesFromSubset(tasks []*taskstore.Task, graph *Graph) []*taskstor
 	return result
 }
 
-// sortTasksDeterministically sorts tasks by createdAt (ascending), then ID (alphabetically).
+// sortTasksDeterministically sorts tasks by priority (highest first), then
+// createdAt (ascending), then ID (alphabetically).
 func sortTasksDeterministically(tasks []*taskstore.Task) {
 	sort.Slice(tasks, func(i, j int) bool {
-		// First compare by createdAt
+		// First compare by priority
+		if ri, rj := tasks[i].Priority.Rank(), tasks[j].Priority.Rank(); ri != rj {
+			return ri < rj
+		}
+		// Then compare by createdAt
 		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
 			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
 		}
@@ -1,6 +1,8 @@
 package selector
 
 import (
+	"fmt"
+	"math/rand/v2"
 	"testing"
 	"time"
 
@@ -104,6 +106,67 @@ func TestSelectNext_MultipleReadyLeaves_SameCreatedAt_OrderByID(t *testing.T) {
 	assert.Equal(t, "child-a", selected.ID, "should select task with alphabetically first ID when createdAt is equal")
 }
 
+func TestSelectNext_PriorityOrder(t *testing.T) {
+	baseTime := time.Date(2026, 1, 16, 10, 0, 0, 0, time.UTC)
+	withPriority := func(task *taskstore.Task, p taskstore.Priority) *taskstore.Task {
+		task.Priority = p
+		return task
+	}
+
+	tests := []struct {
+		name          string
+		tasks         []*taskstore.Task
+		lastCompleted *taskstore.Task
+		want          string
+	}{
+		{
+			name: "P0 before an older P2",
+			tasks: []*taskstore.Task{
+				withPriority(makeTaskWithTime("low", taskstore.StatusOpen, strPtr("root"), nil, baseTime), taskstore.PriorityP2),
+				withPriority(makeTaskWithTime("critical", taskstore.StatusOpen, strPtr("root"), nil, baseTime.Add(time.Hour)), taskstore.PriorityP0),
+			},
+			want: "critical",
+		},
+		{
+			name: "unset priority ranks as P1",
+			tasks: []*taskstore.Task{
+				withPriority(makeTaskWithTime("low", taskstore.StatusOpen, strPtr("root"), nil, baseTime), taskstore.PriorityP2),
+				makeTaskWithTime("unset", taskstore.StatusOpen, strPtr("root"), nil, baseTime.Add(time.Hour)),
+			},
+			want: "unset",
+		},
+		{
+			name: "same priority falls back to createdAt",
+			tasks: []*taskstore.Task{
+				withPriority(makeTaskWithTime("newer", taskstore.StatusOpen, strPtr("root"), nil, baseTime.Add(time.Hour)), taskstore.PriorityP0),
+				withPriority(makeTaskWithTime("older", taskstore.StatusOpen, strPtr("root"), nil, baseTime), taskstore.PriorityP0),
+			},
+			want: "older",
+		},
+		{
+			name: "area preference does not pass over a higher priority",
+			tasks: []*taskstore.Task{
+				withPriority(makeTaskWithLabels("core-low", taskstore.StatusOpen, strPtr("root"), nil, map[string]string{"area": "core"}), taskstore.PriorityP2),
+				withPriority(makeTaskWithLabels("ui-critical", taskstore.StatusOpen, strPtr("root"), nil, map[string]string{"area": "ui"}), taskstore.PriorityP0),
+			},
+			lastCompleted: makeTaskWithLabels("done", taskstore.StatusCompleted, strPtr("root"), nil, map[string]string{"area": "core"}),
+			want:          "ui-critical",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := append([]*taskstore.Task{makeTask("root", taskstore.StatusOpen, nil, nil)}, tt.tasks...)
+			graph, err := BuildGraph(tasks)
+			require.NoError(t, err)
+
+			selected := SelectNext(tasks, graph, "root", tt.lastCompleted)
+			require.NotNil(t, selected)
+			assert.Equal(t, tt.want, selected.ID)
+		})
+	}
+}
+
 func TestSelectNext_AreaPreference_SameAreaAsLastCompleted(t *testing.T) {
 	tasks := []*taskstore.Task{
 		makeTask("root", taskstore.StatusOpen, nil, nil),
@@ -320,3 +383,128 @@ func TestSelectNext_AreaPreferenceTieBreaker(t *testing.T) {
 	// 2. Among core tasks, it has the earliest createdAt
 	assert.Equal(t, "core-a", selected.ID, "should use deterministic ordering within preferred area")
 }
+
+func TestSelectNextWithRand_NilRandIsDeterministic(t *testing.T) {
+	baseTime := time.Date(2026, 1, 16, 10, 0, 0, 0, time.UTC)
+	tasks := []*taskstore.Task{
+		makeTask("root", taskstore.StatusOpen, nil, nil),
+		makeTaskWithTime("child-b", taskstore.StatusOpen, strPtr("root"), nil, baseTime.Add(2*time.Hour)),
+		makeTaskWithTime("child-a", taskstore.StatusOpen, strPtr("root"), nil, baseTime.Add(1*time.Hour)),
+	}
+
+	graph, err := BuildGraph(tasks)
+	require.NoError(t, err)
+
+	selected := SelectNextWithRand(tasks, graph, "root", nil, nil)
+	require.NotNil(t, selected)
+	assert.Equal(t, "child-a", selected.ID)
+}
+
+func TestSelectNextWithRand_SeedIsReproducible(t *testing.T) {
+	baseTime := time.Date(2026, 1, 16, 10, 0, 0, 0, time.UTC)
+	tasks := []*taskstore.Task{makeTask("root", taskstore.StatusOpen, nil, nil)}
+	for i := 0; i < 10; i++ {
+		tasks = append(tasks, makeTaskWithTime(fmt.Sprintf("child-%d", i), taskstore.StatusOpen, strPtr("root"), nil, baseTime.Add(time.Duration(i)*time.Hour)))
+	}
+
+	graph, err := BuildGraph(tasks)
+	require.NoError(t, err)
+
+	first := SelectNextWithRand(tasks, graph, "root", nil, rand.New(rand.NewPCG(42, 42)))
+	second := SelectNextWithRand(tasks, graph, "root", nil, rand.New(rand.NewPCG(42, 42)))
+	require.NotNil(t, first)
+	assert.Equal(t, first.ID, second.ID)
+
+	// Across several seeds, shuffling must pick something other than the deterministic first task
+	seen := make(map[string]bool)
+	for seed := uint64(0); seed < 20; seed++ {
+		seen[SelectNextWithRand(tasks, graph, "root", nil, rand.New(rand.NewPCG(seed, seed))).ID] = true
+	}
+	assert.Greater(t, len(seen), 1)
+}
+
+func TestSelectNextWithRand_KeepsAreaPreference(t *testing.T) {
+	tasks := []*taskstore.Task{
+		makeTaskWithLabels("root", taskstore.StatusOpen, nil, nil, nil),
+		makeTaskWithLabels("core-1", taskstore.StatusOpen, strPtr("root"), nil, map[string]string{"area": "core"}),
+		makeTaskWithLabels("ui-1", taskstore.StatusOpen, strPtr("root"), nil, map[string]string{"area": "ui"}),
+		makeTaskWithLabels("ui-2", taskstore.StatusOpen, strPtr("root"), nil, map[string]string{"area": "ui"}),
+	}
+	lastCompleted := makeTaskWithLabels("done", taskstore.StatusCompleted, strPtr("root"), nil, map[string]string{"area": "core"})
+
+	graph, err := BuildGraph(tasks)
+	require.NoError(t, err)
+
+	for seed := uint64(0); seed < 10; seed++ {
+		selected := SelectNextWithRand(tasks, graph, "root", lastCompleted, rand.New(rand.NewPCG(seed, seed)))
+		require.NotNil(t, selected)
+		assert.Equal(t, "core-1", selected.ID)
+	}
+}
+
+func TestSelectNextWithRand_KeepsPriority(t *testing.T) {
+	tasks := []*taskstore.Task{makeTask("root", taskstore.StatusOpen, nil, nil)}
+	for i := 0; i < 5; i++ {
+		task := makeTask(fmt.Sprintf("low-%d", i), taskstore.StatusOpen, strPtr("root"), nil)
+		task.Priority = taskstore.PriorityP2
+		tasks = append(tasks, task)
+	}
+	critical := makeTask("critical", taskstore.StatusOpen, strPtr("root"), nil)
+	critical.Priority = taskstore.PriorityP0
+	tasks = append(tasks, critical)
+
+	graph, err := BuildGraph(tasks)
+	require.NoError(t, err)
+
+	for seed := uint64(0); seed < 10; seed++ {
+		selected := SelectNextWithRand(tasks, graph, "root", nil, rand.New(rand.NewPCG(seed, seed)))
+		require.NotNil(t, selected)
+		assert.Equal(t, "critical", selected.ID)
+	}
+}
+
+func TestSelectNextWithOptions_FailedDepsSatisfied(t *testing.T) {
+	tasks := []*taskstore.Task{
+		makeTask("root", taskstore.StatusOpen, nil, nil),
+		makeTask("failed", taskstore.StatusFailed, strPtr("root"), nil),
+		makeTask("dependent", taskstore.StatusOpen, strPtr("root"), []string{"failed"}),
+	}
+
+	graph, err := BuildGraph(tasks)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		opts     SelectOptions
+		expected string
+	}{
+		{name: "failed dependency blocks by default", opts: SelectOptions{}, expected: ""},
+		{name: "failed dependency satisfied when enabled", opts: SelectOptions{FailedDepsSatisfied: true}, expected: "dependent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := SelectNextWithOptions(tasks, graph, "root", nil, tt.opts)
+			if tt.expected == "" {
+				assert.Nil(t, selected)
+				return
+			}
+			require.NotNil(t, selected)
+			assert.Equal(t, tt.expected, selected.ID)
+		})
+	}
+}
+
+func TestSelectNextWithOptions_OpenDepStillBlocks(t *testing.T) {
+	tasks := []*taskstore.Task{
+		makeTask("root", taskstore.StatusOpen, nil, nil),
+		makeTask("blocked", taskstore.StatusBlocked, strPtr("root"), nil),
+		makeTask("dependent", taskstore.StatusOpen, strPtr("root"), []string{"blocked"}),
+	}
+
+	graph, err := BuildGraph(tasks)
+	require.NoError(t, err)
+
+	selected := SelectNextWithOptions(tasks, graph, "root", nil, SelectOptions{FailedDepsSatisfied: true})
+	assert.Nil(t, selected)
+}
@@ -0,0 +1,88 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kinds of per-task guidance files kept in the state directory.
+const (
+	FeedbackKindFeedback   = "feedback"
+	FeedbackKindSkipReason = "skip-reason"
+)
+
+// feedbackKinds lists the file name prefixes of per-task guidance files.
+var feedbackKinds = []string{FeedbackKindFeedback, FeedbackKindSkipReason}
+
+// FeedbackFile is a per-task guidance file (feedback-<id>.txt or skip-reason-<id>.txt).
+type FeedbackFile struct {
+	TaskID  string
+	Kind    string
+	Path    string
+	Content string
+}
+
+// ListFeedbackFiles returns all feedback and skip-reason files in the state
+// directory, sorted by task ID then kind. A missing state directory yields no files.
+func ListFeedbackFiles(root string) ([]FeedbackFile, error) {
+	stateDir := StateDirPath(root)
+	entries, err := os.ReadDir(stateDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read state directory: %w", err)
+	}
+
+	var files []FeedbackFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		for _, kind := range feedbackKinds {
+			taskID, ok := strings.CutPrefix(entry.Name(), kind+"-")
+			if !ok || !strings.HasSuffix(taskID, ".txt") {
+				continue
+			}
+			taskID = strings.TrimSuffix(taskID, ".txt")
+
+			path := filepath.Join(stateDir, entry.Name())
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			files = append(files, FeedbackFile{TaskID: taskID, Kind: kind, Path: path, Content: string(data)})
+			break
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].TaskID != files[j].TaskID {
+			return files[i].TaskID < files[j].TaskID
+		}
+		return files[i].Kind < files[j].Kind
+	})
+
+	return files, nil
+}
+
+// RemoveFeedbackFiles removes the feedback and skip-reason files for taskID and
+// returns the paths that were removed.
+func RemoveFeedbackFiles(root, taskID string) ([]string, error) {
+	var removed []string
+	for _, kind := range feedbackKinds {
+		path := filepath.Join(StateDirPath(root), fmt.Sprintf("%s-%s.txt", kind, taskID))
+		if err := os.Remove(path); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,58 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFeedbackFiles(t *testing.T) {
+	t.Run("missing state directory yields no files", func(t *testing.T) {
+		files, err := ListFeedbackFiles(t.TempDir())
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("lists feedback and skip reasons sorted by task", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, EnsureRalphDir(tmpDir))
+		stateDir := StateDirPath(tmpDir)
+		require.NoError(t, os.WriteFile(filepath.Join(stateDir, "skip-reason-task-b.txt"), []byte("not needed"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(stateDir, "feedback-task-b.txt"), []byte("use the cache"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(stateDir, "feedback-task-a.txt"), []byte("check nil"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(stateDir, "gutter.json"), []byte("{}"), 0644))
+
+		files, err := ListFeedbackFiles(tmpDir)
+		require.NoError(t, err)
+		require.Len(t, files, 3)
+
+		assert.Equal(t, FeedbackFile{TaskID: "task-a", Kind: FeedbackKindFeedback, Path: filepath.Join(stateDir, "feedback-task-a.txt"), Content: "check nil"}, files[0])
+		assert.Equal(t, "task-b", files[1].TaskID)
+		assert.Equal(t, FeedbackKindFeedback, files[1].Kind)
+		assert.Equal(t, "task-b", files[2].TaskID)
+		assert.Equal(t, FeedbackKindSkipReason, files[2].Kind)
+	})
+}
+
+func TestRemoveFeedbackFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, EnsureRalphDir(tmpDir))
+	stateDir := StateDirPath(tmpDir)
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "feedback-task-a.txt"), []byte("hint"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "skip-reason-task-a.txt"), []byte("why"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "feedback-task-b.txt"), []byte("keep"), 0644))
+
+	removed, err := RemoveFeedbackFiles(tmpDir, "task-a")
+	require.NoError(t, err)
+	assert.Len(t, removed, 2)
+	assert.NoFileExists(t, filepath.Join(stateDir, "feedback-task-a.txt"))
+	assert.NoFileExists(t, filepath.Join(stateDir, "skip-reason-task-a.txt"))
+	assert.FileExists(t, filepath.Join(stateDir, "feedback-task-b.txt"))
+
+	removed, err = RemoveFeedbackFiles(tmpDir, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
@@ -0,0 +1,87 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Manual actions recorded in the interventions log.
+const (
+	InterventionRetry   = "retry"
+	InterventionSkip    = "skip"
+	InterventionApprove = "approve"
+	InterventionUndo    = "undo"
+)
+
+// Intervention is a manual change to a task's status, such as `ralph fix
+// --retry`. The log of interventions outlives the feedback files, which are
+// removed once a task succeeds.
+type Intervention struct {
+	TaskID string    `json:"task_id"`
+	Action string    `json:"action"`
+	From   string    `json:"from,omitempty"`
+	To     string    `json:"to,omitempty"`
+	Time   time.Time `json:"time"`
+
+	// Feedback is true when the change came with feedback or a skip reason
+	// for the task.
+	Feedback bool `json:"feedback,omitempty"`
+}
+
+// AppendIntervention adds an intervention to the log at path.
+func AppendIntervention(path string, intervention Intervention) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	data, err := json.Marshal(intervention)
+	if err != nil {
+		return fmt.Errorf("failed to marshal intervention: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open interventions log: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write interventions log: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close interventions log: %w", err)
+	}
+	return nil
+}
+
+// LoadInterventions reads the log at path, oldest first. A missing log
+// yields no interventions.
+func LoadInterventions(path string) ([]Intervention, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open interventions log: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var interventions []Intervention
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var intervention Intervention
+		if err := json.Unmarshal(scanner.Bytes(), &intervention); err != nil {
+			return nil, fmt.Errorf("failed to parse interventions log: %w", err)
+		}
+		interventions = append(interventions, intervention)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read interventions log: %w", err)
+	}
+	return interventions, nil
+}
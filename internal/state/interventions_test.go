@@ -0,0 +1,32 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterventions(t *testing.T) {
+	t.Run("missing log yields no interventions", func(t *testing.T) {
+		interventions, err := LoadInterventions(filepath.Join(t.TempDir(), Interventions))
+		require.NoError(t, err)
+		assert.Empty(t, interventions)
+	})
+
+	t.Run("appends and loads in order", func(t *testing.T) {
+		path := InterventionsFilePath(t.TempDir())
+		now := time.Now().UTC().Truncate(time.Second)
+		retry := Intervention{TaskID: "task-1", Action: InterventionRetry, From: "failed", To: "open", Time: now, Feedback: true}
+		approve := Intervention{TaskID: "task-2", Action: InterventionApprove, From: "awaiting_review", To: "completed", Time: now}
+
+		require.NoError(t, AppendIntervention(path, retry))
+		require.NoError(t, AppendIntervention(path, approve))
+
+		interventions, err := LoadInterventions(path)
+		require.NoError(t, err)
+		assert.Equal(t, []Intervention{retry, approve}, interventions)
+	})
+}
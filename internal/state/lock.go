@@ -0,0 +1,153 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockFile is the name of the file that marks a running ralph process.
+const LockFile = "lock"
+
+// LockFilePath returns the path to the lock held by a running ralph process.
+func LockFilePath(root string) string {
+	return filepath.Join(root, RalphDir, StateDir, LockFile)
+}
+
+// LockInfo is the content of the lock file.
+type LockInfo struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// LockedError is returned when another process holds the lock.
+type LockedError struct {
+	PID int
+}
+
+func (e *LockedError) Error() string {
+	if e.PID <= 0 {
+		return "another ralph is running"
+	}
+	return fmt.Sprintf("another ralph is running (pid %d)", e.PID)
+}
+
+// Lock is a held lock file. Release unlocks it.
+type Lock struct {
+	file *os.File
+}
+
+// AcquireLock takes the lock for the repository at root, so that two ralph
+// processes never mutate the same tasks and git state at once. The lock is
+// an operating system lock on the lock file, which is released when the
+// holding process exits however it exits, so the file's content never
+// decides who holds it. A lock this process already holds is shared: the
+// returned lock's Release leaves it for the outer holder to release.
+func AcquireLock(root string) (*Lock, error) {
+	if err := os.MkdirAll(StateDirPath(root), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	path := LockFilePath(root)
+	data, err := json.Marshal(LockInfo{PID: os.Getpid(), StartedAt: time.Now().UTC()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal lock: %w", err)
+	}
+
+	// The file stays in place between holders; if it was removed while we
+	// locked it, lock the one now at path instead.
+	for range 3 {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open lock file: %w", err)
+		}
+		locked, err := tryLockFile(file)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if !locked {
+			_ = file.Close()
+			holder, err := ReadLock(root)
+			if err != nil {
+				return nil, err
+			}
+			if holder != nil && holder.PID == os.Getpid() {
+				return &Lock{}, nil
+			}
+			if holder == nil {
+				return nil, &LockedError{}
+			}
+			return nil, &LockedError{PID: holder.PID}
+		}
+
+		if !isFileAt(file, path) {
+			_ = file.Close()
+			continue
+		}
+		if err := file.Truncate(0); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to write lock file: %w", err)
+		}
+		if _, err := file.WriteAt(data, 0); err != nil {
+			_ = file.Truncate(0)
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to write lock file: %w", err)
+		}
+		return &Lock{file: file}, nil
+	}
+	return nil, fmt.Errorf("failed to acquire lock file %s", path)
+}
+
+// isFileAt reports whether file is still the file at path.
+func isFileAt(file *os.File, path string) bool {
+	opened, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(opened, current)
+}
+
+// ReadLock returns the holder recorded in the lock file, or nil when there is
+// no lock or its content cannot be read as a lock. A process that crashed
+// while holding the lock leaves its record behind until the next holder.
+func ReadLock(root string) (*LockInfo, error) {
+	data, err := os.ReadFile(LockFilePath(root))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+	var info LockInfo
+	if err := json.Unmarshal(data, &info); err != nil || info.PID <= 0 {
+		return nil, nil
+	}
+	return &info, nil
+}
+
+// Release empties the lock file, so ReadLock reports no holder, and unlocks
+// it. The file itself is left for the next holder. It is safe to call more
+// than once.
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	truncErr := l.file.Truncate(0)
+	closeErr := l.file.Close()
+	l.file = nil
+	if truncErr != nil {
+		return fmt.Errorf("failed to clear lock file: %w", truncErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to release lock file: %w", closeErr)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package state
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLock(t *testing.T, root string, pid int) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(StateDirPath(root), 0755))
+	data, err := json.Marshal(LockInfo{PID: pid, StartedAt: time.Now().UTC()})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(LockFilePath(root), data, 0644))
+}
+
+// deadPID returns the PID of a process that has already exited.
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command("true")
+	require.NoError(t, cmd.Run())
+	return cmd.Process.Pid
+}
+
+// TestLockHolderProcess is not a real test: startLockHolder runs the test
+// binary with it to hold the lock in another process.
+func TestLockHolderProcess(t *testing.T) {
+	root := os.Getenv("RALPH_TEST_LOCK_ROOT")
+	if root == "" {
+		t.Skip("helper process for lock tests")
+	}
+	if _, err := AcquireLock(root); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("locked")
+	_, _ = io.Copy(io.Discard, os.Stdin)
+}
+
+// startLockHolder starts another process that holds the lock for root until
+// it is killed or the test ends.
+func startLockHolder(t *testing.T, root string) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLockHolderProcess$")
+	cmd.Env = append(os.Environ(), "RALPH_TEST_LOCK_ROOT="+root)
+	stdin, err := cmd.StdinPipe()
+	require.NoError(t, err)
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		_ = stdin.Close()
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "locked\n", line)
+	return cmd
+}
+
+func TestAcquireLock(t *testing.T) {
+	t.Run("writes PID and timestamp and releases", func(t *testing.T) {
+		root := t.TempDir()
+
+		lock, err := AcquireLock(root)
+		require.NoError(t, err)
+
+		info, err := ReadLock(root)
+		require.NoError(t, err)
+		require.NotNil(t, info)
+		assert.Equal(t, os.Getpid(), info.PID)
+		assert.WithinDuration(t, time.Now(), info.StartedAt, time.Minute)
+
+		require.NoError(t, lock.Release())
+		info, err = ReadLock(root)
+		require.NoError(t, err)
+		assert.Nil(t, info)
+		require.NoError(t, lock.Release())
+
+		again, err := AcquireLock(root)
+		require.NoError(t, err)
+		require.NoError(t, again.Release())
+	})
+
+	t.Run("refuses a lock held by another process", func(t *testing.T) {
+		root := t.TempDir()
+		holder := startLockHolder(t, root)
+
+		_, err := AcquireLock(root)
+		require.Error(t, err)
+		var locked *LockedError
+		require.ErrorAs(t, err, &locked)
+		assert.Equal(t, holder.Process.Pid, locked.PID)
+		assert.EqualError(t, err, fmt.Sprintf("another ralph is running (pid %d)", holder.Process.Pid))
+
+		info, err := ReadLock(root)
+		require.NoError(t, err)
+		require.NotNil(t, info)
+		assert.Equal(t, holder.Process.Pid, info.PID, "the holder's record is left alone")
+	})
+
+	t.Run("takes over once the holder is killed", func(t *testing.T) {
+		root := t.TempDir()
+		holder := startLockHolder(t, root)
+		require.NoError(t, holder.Process.Kill())
+		_ = holder.Wait()
+
+		lock, err := AcquireLock(root)
+		require.NoError(t, err)
+		info, err := ReadLock(root)
+		require.NoError(t, err)
+		assert.Equal(t, os.Getpid(), info.PID)
+		require.NoError(t, lock.Release())
+	})
+
+	t.Run("refuses a held lock whose record is not written yet", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(StateDirPath(root), 0755))
+		file, err := os.OpenFile(LockFilePath(root), os.O_CREATE|os.O_RDWR, 0644)
+		require.NoError(t, err)
+		defer func() { _ = file.Close() }()
+		locked, err := tryLockFile(file)
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		_, err = AcquireLock(root)
+		assert.EqualError(t, err, "another ralph is running")
+	})
+
+	t.Run("takes over a lock file whose process exited", func(t *testing.T) {
+		root := t.TempDir()
+		writeLock(t, root, deadPID(t))
+
+		lock, err := AcquireLock(root)
+		require.NoError(t, err)
+		info, err := ReadLock(root)
+		require.NoError(t, err)
+		assert.Equal(t, os.Getpid(), info.PID)
+		require.NoError(t, lock.Release())
+	})
+
+	t.Run("takes over a lock file whose PID was reused", func(t *testing.T) {
+		root := t.TempDir()
+		writeLock(t, root, os.Getppid())
+
+		lock, err := AcquireLock(root)
+		require.NoError(t, err, "only the file lock decides who holds it")
+		require.NoError(t, lock.Release())
+	})
+
+	t.Run("takes over an unreadable lock file", func(t *testing.T) {
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(StateDirPath(root), 0755))
+		require.NoError(t, os.WriteFile(LockFilePath(root), []byte("garbage"), 0644))
+
+		lock, err := AcquireLock(root)
+		require.NoError(t, err)
+		require.NoError(t, lock.Release())
+	})
+
+	t.Run("nested acquire in the same process leaves the outer lock", func(t *testing.T) {
+		root := t.TempDir()
+
+		outer, err := AcquireLock(root)
+		require.NoError(t, err)
+		inner, err := AcquireLock(root)
+		require.NoError(t, err)
+
+		require.NoError(t, inner.Release())
+		info, err := ReadLock(root)
+		require.NoError(t, err)
+		require.NotNil(t, info, "the outer lock is still held")
+		assert.Equal(t, os.Getpid(), info.PID)
+		require.NoError(t, outer.Release())
+		info, err = ReadLock(root)
+		require.NoError(t, err)
+		assert.Nil(t, info)
+	})
+}
@@ -6,18 +6,31 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/yarlson/ralph/internal/config"
 )
 
 // Directory names for the .ralph structure.
 const (
-	RalphDir        = ".ralph"
-	TasksDir        = "tasks"
-	StateDir        = "state"
-	LogsDir         = "logs"
-	ClaudeLogsDir   = "claude"
-	OpenCodeLogsDir = "opencode"
-	ArchiveDir      = "archive"
-	PausedFile      = "paused"
+	RalphDir          = ".ralph"
+	TasksDir          = "tasks"
+	StateDir          = "state"
+	LogsDir           = "logs"
+	ClaudeLogsDir     = "claude"
+	OpenCodeLogsDir   = "opencode"
+	ArchiveDir        = "archive"
+	PromptsDir        = "prompts"
+	PausedFile        = "paused"
+	GutterFile        = "gutter.json"
+	BudgetOverride    = "budget-override.json"
+	Budget            = "budget.json"
+	DecomposeCache    = "decompose-cache.yaml"
+	DecomposeSections = "decompose-sections.yaml"
+	VerifyCache       = "verify-cache.json"
+	Interventions     = "interventions.jsonl"
+	ParentTaskIDFile  = "parent-task-id"
+	StagedRun         = "staged-run.json"
 )
 
 // RalphDirPath returns the path to the .ralph directory.
@@ -55,6 +68,21 @@ func ArchiveDirPath(root string) string {
 	return filepath.Join(root, RalphDir, ArchiveDir)
 }
 
+// LogsArchiveDirPath returns the path to the directory for archived iteration records.
+func LogsArchiveDirPath(root string) string {
+	return filepath.Join(root, RalphDir, ArchiveDir, LogsDir)
+}
+
+// PromptsDirPath returns the path to the optional prompt customization directory.
+func PromptsDirPath(root string) string {
+	return filepath.Join(root, RalphDir, PromptsDir)
+}
+
+// IterationPromptFilePath returns the path to the custom initial iteration prompt.
+func IterationPromptFilePath(root string) string {
+	return filepath.Join(root, RalphDir, PromptsDir, "iteration.md")
+}
+
 // EnsureRalphDir creates the .ralph directory structure if it doesn't exist.
 // It creates the following directories:
 //   - .ralph/
@@ -100,7 +128,7 @@ func PausedFilePath(root string) string {
 
 // ParentTaskIDFilePath returns the path to the stored parent task ID file in state dir.
 func ParentTaskIDFilePath(root string) string {
-	return filepath.Join(root, RalphDir, StateDir, "parent-task-id")
+	return filepath.Join(root, RalphDir, StateDir, ParentTaskIDFile)
 }
 
 // GetStoredParentTaskID reads the stored parent task ID from state.
@@ -174,3 +202,116 @@ func SetPaused(root string, paused bool) error {
 	}
 	return nil
 }
+
+// BudgetOverrideFilePath returns the path to the budget limits changed while
+// a run is in progress.
+func BudgetOverrideFilePath(root string) string {
+	return filepath.Join(root, RalphDir, StateDir, BudgetOverride)
+}
+
+// InterventionsFilePath returns the path to the log of manual task status
+// changes.
+func InterventionsFilePath(root string) string {
+	return filepath.Join(root, RalphDir, StateDir, Interventions)
+}
+
+// GutterStateFilePath returns the path to the persisted gutter detection state.
+func GutterStateFilePath(root string) string {
+	return filepath.Join(root, RalphDir, StateDir, GutterFile)
+}
+
+// DecomposeCacheFilePath returns the path to the cached decomposition YAML
+// that failed validation.
+func DecomposeCacheFilePath(root string) string {
+	return filepath.Join(root, RalphDir, StateDir, DecomposeCache)
+}
+
+// DecomposeSectionsFilePath returns the path to the checkpoint of completed
+// sections of a sectioned decomposition.
+func DecomposeSectionsFilePath(root string) string {
+	return filepath.Join(root, RalphDir, StateDir, DecomposeSections)
+}
+
+// VerifyCacheFilePath returns the path to the cache of verify commands that
+// passed, keyed by working tree hash.
+func VerifyCacheFilePath(root string) string {
+	return filepath.Join(root, RalphDir, StateDir, VerifyCache)
+}
+
+// StagedRunFilePath returns the path to the tasks a per_run mode run staged
+// but did not commit.
+func StagedRunFilePath(root string) string {
+	return filepath.Join(root, RalphDir, StateDir, StagedRun)
+}
+
+// ClearGutterState removes the persisted gutter detection state.
+// It is not an error if no state has been persisted.
+func ClearGutterState(root string) error {
+	err := os.Remove(GutterStateFilePath(root))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove gutter state: %w", err)
+	}
+	return nil
+}
+
+// BranchFilePath returns the path to the stored feature branch of a parent task.
+func BranchFilePath(root, parentTaskID string) string {
+	return filepath.Join(root, RalphDir, StateDir, "branch-"+parentTaskID)
+}
+
+// GetStoredBranch reads the feature branch stored for a parent task.
+// Returns empty string if no branch has been stored.
+func GetStoredBranch(root, parentTaskID string) (string, error) {
+	data, err := os.ReadFile(BranchFilePath(root, parentTaskID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading stored branch: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SetStoredBranch stores the feature branch for a parent task, so later runs
+// continue on it even if the parent title changes.
+func SetStoredBranch(root, parentTaskID, branch string) error {
+	stateDir := StateDirPath(root)
+	if _, err := os.Stat(stateDir); os.IsNotExist(err) {
+		return fmt.Errorf(".ralph/state directory does not exist")
+	}
+
+	if err := os.WriteFile(BranchFilePath(root, parentTaskID), []byte(branch), 0644); err != nil {
+		return fmt.Errorf("writing stored branch: %w", err)
+	}
+	return nil
+}
+
+// RenameTaskStateFiles migrates per-task state from oldID to newID: the
+// feedback-<id>.txt, skip-reason-<id>.txt and branch-<id> files in the state directory, and
+// the stored parent task ID files if they name oldID.
+func RenameTaskStateFiles(root, oldID, newID string) error {
+	stateDir := StateDirPath(root)
+	for _, pattern := range []string{"feedback-%s.txt", "skip-reason-%s.txt", "branch-%s"} {
+		oldPath := filepath.Join(stateDir, fmt.Sprintf(pattern, oldID))
+		newPath := filepath.Join(stateDir, fmt.Sprintf(pattern, newID))
+		if err := os.Rename(oldPath, newPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rename %s: %w", oldPath, err)
+		}
+	}
+
+	parentFiles := []string{
+		ParentTaskIDFilePath(root),
+		filepath.Join(root, config.DefaultParentIDFile),
+	}
+	for _, path := range parentFiles {
+		data, err := os.ReadFile(path)
+		if err != nil || strings.TrimSpace(string(data)) != oldID {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(newID), 0644); err != nil {
+			return fmt.Errorf("failed to update %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
@@ -135,6 +135,24 @@ func TestPausedFilePath(t *testing.T) {
 	assert.Equal(t, expected, PausedFilePath(root))
 }
 
+func TestLogsArchiveDirPath(t *testing.T) {
+	assert.Equal(t, "/some/project/.ralph/archive/logs", LogsArchiveDirPath("/some/project"))
+}
+
+func TestDecomposeCacheFilePath(t *testing.T) {
+	root := "/some/project"
+	expected := "/some/project/.ralph/state/decompose-cache.yaml"
+	assert.Equal(t, expected, DecomposeCacheFilePath(root))
+}
+
+func TestDecomposeSectionsFilePath(t *testing.T) {
+	assert.Equal(t, "/some/project/.ralph/state/decompose-sections.yaml", DecomposeSectionsFilePath("/some/project"))
+}
+
+func TestVerifyCacheFilePath(t *testing.T) {
+	assert.Equal(t, "/some/project/.ralph/state/verify-cache.json", VerifyCacheFilePath("/some/project"))
+}
+
 func TestIsPaused(t *testing.T) {
 	t.Run("returns error when state dir does not exist", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -290,3 +308,85 @@ func TestSetStoredParentTaskID(t *testing.T) {
 		assert.Equal(t, "task-2", taskID)
 	})
 }
+
+func TestClearGutterState(t *testing.T) {
+	t.Run("removes persisted state", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, EnsureRalphDir(tmpDir))
+		require.NoError(t, os.WriteFile(GutterStateFilePath(tmpDir), []byte("{}"), 0644))
+
+		require.NoError(t, ClearGutterState(tmpDir))
+
+		_, err := os.Stat(GutterStateFilePath(tmpDir))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("succeeds when nothing persisted", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, EnsureRalphDir(tmpDir))
+
+		assert.NoError(t, ClearGutterState(tmpDir))
+	})
+}
+
+func TestStoredBranch(t *testing.T) {
+	t.Run("returns empty string when no branch is stored", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, EnsureRalphDir(tmpDir))
+
+		branch, err := GetStoredBranch(tmpDir, "parent")
+		require.NoError(t, err)
+		assert.Equal(t, "", branch)
+	})
+
+	t.Run("stores branch per parent task", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, EnsureRalphDir(tmpDir))
+
+		require.NoError(t, SetStoredBranch(tmpDir, "parent-a", "feature-a"))
+		require.NoError(t, SetStoredBranch(tmpDir, "parent-b", "feature-b"))
+
+		branch, err := GetStoredBranch(tmpDir, "parent-a")
+		require.NoError(t, err)
+		assert.Equal(t, "feature-a", branch)
+		assert.FileExists(t, filepath.Join(StateDirPath(tmpDir), "branch-parent-b"))
+	})
+
+	t.Run("returns error when state dir does not exist", func(t *testing.T) {
+		err := SetStoredBranch(t.TempDir(), "parent", "feature")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ".ralph/state")
+	})
+}
+
+func TestRenameTaskStateFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, EnsureRalphDir(tmpDir))
+	stateDir := StateDirPath(tmpDir)
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "feedback-old.txt"), []byte("hint"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "skip-reason-old.txt"), []byte("why"), 0644))
+	require.NoError(t, SetStoredParentTaskID(tmpDir, "old"))
+	require.NoError(t, SetStoredBranch(tmpDir, "old", "feature-old"))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, RalphDir, "parent-task-id"), []byte("other"), 0644))
+
+	require.NoError(t, RenameTaskStateFiles(tmpDir, "old", "new"))
+
+	assert.NoFileExists(t, filepath.Join(stateDir, "feedback-old.txt"))
+	assert.FileExists(t, filepath.Join(stateDir, "feedback-new.txt"))
+	assert.FileExists(t, filepath.Join(stateDir, "skip-reason-new.txt"))
+
+	branch, err := GetStoredBranch(tmpDir, "new")
+	require.NoError(t, err)
+	assert.Equal(t, "feature-old", branch)
+
+	stored, err := GetStoredParentTaskID(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, "new", stored)
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, RalphDir, "parent-task-id"))
+	require.NoError(t, err)
+	assert.Equal(t, "other", string(data), "unrelated parent ID is left alone")
+
+	// Nothing to migrate is not an error
+	assert.NoError(t, RenameTaskStateFiles(tmpDir, "missing", "renamed"))
+}
@@ -0,0 +1,65 @@
+package taskstore
+
+import "fmt"
+
+// LintOptions enables optional checks in LintTaskSetWithOptions.
+type LintOptions struct {
+	// MaxDepth warns about tasks nested more than MaxDepth levels deep, where
+	// a task without a parent is level 1 (0 = unlimited).
+	MaxDepth int
+}
+
+// LintTaskSetWithOptions is LintTaskSet with the optional checks in opts,
+// which are reported as warnings.
+func LintTaskSetWithOptions(tasks []*Task, opts LintOptions) *LintResult {
+	result := LintTaskSet(tasks)
+	result.Warnings = append(result.Warnings, DepthWarnings(tasks, opts.MaxDepth)...)
+	return result
+}
+
+// DepthWarnings reports the tasks nested more than maxDepth levels deep, in
+// input order. A task without a parent (or whose parent is not in tasks) is
+// level 1. It returns nil when maxDepth is 0.
+func DepthWarnings(tasks []*Task, maxDepth int) []LintWarning {
+	if maxDepth <= 0 {
+		return nil
+	}
+
+	depths := Depths(tasks)
+	var warnings []LintWarning
+	for _, t := range tasks {
+		if depth := depths[t.ID]; depth > maxDepth {
+			warnings = append(warnings, LintWarning{
+				TaskID:  t.ID,
+				Warning: fmt.Sprintf("nested %d levels deep (max %d)", depth, maxDepth),
+			})
+		}
+	}
+	return warnings
+}
+
+// Depths returns the nesting level of each task, where a task without a
+// parent (or whose parent is not in tasks) is level 1. Parent cycles stop at
+// the first repeated task.
+func Depths(tasks []*Task) map[string]int {
+	byID := make(map[string]*Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+
+	depths := make(map[string]int, len(tasks))
+	for _, t := range tasks {
+		depth := 1
+		seen := map[string]bool{t.ID: true}
+		for cur := t; cur.ParentID != nil; depth++ {
+			parent, ok := byID[*cur.ParentID]
+			if !ok || seen[parent.ID] {
+				break
+			}
+			seen[parent.ID] = true
+			cur = parent
+		}
+		depths[t.ID] = depth
+	}
+	return depths
+}
@@ -0,0 +1,69 @@
+package taskstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepthWarnings(t *testing.T) {
+	newTask := func(id string, parentID *string) *Task {
+		return &Task{
+			ID:          id,
+			Title:       id,
+			Description: "desc",
+			Status:      StatusOpen,
+			ParentID:    parentID,
+			Verify:      [][]string{{"go", "test"}},
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+	}
+	strPtr := func(s string) *string { return &s }
+
+	tasks := []*Task{
+		newTask("root", nil),
+		newTask("epic", strPtr("root")),
+		newTask("story", strPtr("epic")),
+		newTask("leaf", strPtr("story")),
+		newTask("orphan", strPtr("missing")),
+	}
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		want     []LintWarning
+	}{
+		{name: "unlimited", maxDepth: 0},
+		{name: "within the limit", maxDepth: 4},
+		{
+			name:     "deeper than the limit",
+			maxDepth: 2,
+			want: []LintWarning{
+				{TaskID: "story", Warning: "nested 3 levels deep (max 2)"},
+				{TaskID: "leaf", Warning: "nested 4 levels deep (max 2)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DepthWarnings(tasks, tt.maxDepth))
+		})
+	}
+
+	t.Run("reported as lint warnings", func(t *testing.T) {
+		result := LintTaskSetWithOptions(tasks[:4], LintOptions{MaxDepth: 3})
+
+		assert.True(t, result.Valid)
+		assert.Contains(t, result.Warnings, LintWarning{TaskID: "leaf", Warning: "nested 4 levels deep (max 3)"})
+	})
+
+	t.Run("parent cycles terminate", func(t *testing.T) {
+		a := newTask("a", strPtr("b"))
+		b := newTask("b", strPtr("a"))
+
+		assert.Equal(t, map[string]int{"a": 2, "b": 2}, Depths([]*Task{a, b}))
+	})
+}
@@ -0,0 +1,144 @@
+package taskstore
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// TaskChange describes a task present in both task sets whose definition differs.
+type TaskChange struct {
+	Old    *Task
+	New    *Task
+	Fields []string
+}
+
+// TaskDiff describes the differences between two task sets.
+type TaskDiff struct {
+	Added   []*Task
+	Removed []*Task
+	Changed []TaskChange
+}
+
+// Empty returns true if the task sets are equivalent.
+func (d *TaskDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffTasks compares two task sets by ID. Only the task definition is
+// compared (title, description, parent, dependencies, acceptance, verify,
+// labels); status and timestamps are ignored. Results are sorted by ID.
+func DiffTasks(oldTasks, newTasks []*Task) *TaskDiff {
+	oldByID := make(map[string]*Task, len(oldTasks))
+	for _, t := range oldTasks {
+		oldByID[t.ID] = t
+	}
+	newByID := make(map[string]*Task, len(newTasks))
+	for _, t := range newTasks {
+		newByID[t.ID] = t
+	}
+
+	diff := &TaskDiff{}
+	for _, t := range newTasks {
+		old, exists := oldByID[t.ID]
+		if !exists {
+			diff.Added = append(diff.Added, t)
+			continue
+		}
+		if fields := changedFields(old, t); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, TaskChange{Old: old, New: t, Fields: fields})
+		}
+	}
+	for _, t := range oldTasks {
+		if _, exists := newByID[t.ID]; !exists {
+			diff.Removed = append(diff.Removed, t)
+		}
+	}
+
+	byID := func(tasks []*Task) {
+		sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	}
+	byID(diff.Added)
+	byID(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].New.ID < diff.Changed[j].New.ID })
+
+	return diff
+}
+
+// changedFields returns the names of the definition fields that differ between a and b.
+func changedFields(a, b *Task) []string {
+	var fields []string
+	if a.Title != b.Title {
+		fields = append(fields, "title")
+	}
+	if a.Description != b.Description {
+		fields = append(fields, "description")
+	}
+	if parentOf(a) != parentOf(b) {
+		fields = append(fields, "parentId")
+	}
+	if !slices.Equal(a.DependsOn, b.DependsOn) {
+		fields = append(fields, "dependsOn")
+	}
+	if !slices.Equal(a.Acceptance, b.Acceptance) {
+		fields = append(fields, "acceptance")
+	}
+	if !slices.EqualFunc(a.Verify, b.Verify, slices.Equal) || !slices.Equal(a.VerifyWhen, b.VerifyWhen) || a.VerifySet != b.VerifySet ||
+		!slices.EqualFunc(a.VerifyExitCodes, b.VerifyExitCodes, maps.Equal) {
+		fields = append(fields, "verify")
+	}
+	if !maps.Equal(a.Labels, b.Labels) {
+		fields = append(fields, "labels")
+	}
+	if !slices.Equal(a.ContextFiles, b.ContextFiles) {
+		fields = append(fields, "contextFiles")
+	}
+	if !slices.Equal(a.AllowedPaths, b.AllowedPaths) {
+		fields = append(fields, "allowedPaths")
+	}
+	return fields
+}
+
+func parentOf(t *Task) string {
+	if t.ParentID == nil {
+		return ""
+	}
+	return *t.ParentID
+}
+
+// ReplaceTasks replaces oldTasks in the store with newTasks: tasks only in
+// oldTasks are deleted and newTasks are saved. Tasks present in both keep
+// their status and creation time. Tasks in neither set are left untouched.
+func ReplaceTasks(store Store, oldTasks, newTasks []*Task) error {
+	oldByID := make(map[string]*Task, len(oldTasks))
+	for _, t := range oldTasks {
+		oldByID[t.ID] = t
+	}
+
+	now := time.Now()
+	keep := make(map[string]bool, len(newTasks))
+	for _, t := range newTasks {
+		keep[t.ID] = true
+		if old, exists := oldByID[t.ID]; exists {
+			t.Status = old.Status
+			t.CreatedAt = old.CreatedAt
+		}
+		t.UpdatedAt = now
+		if err := store.Save(t); err != nil {
+			return fmt.Errorf("failed to save task %s: %w", t.ID, err)
+		}
+	}
+
+	for _, t := range oldTasks {
+		if keep[t.ID] {
+			continue
+		}
+		if err := store.Delete(t.ID); err != nil {
+			return fmt.Errorf("failed to delete task %s: %w", t.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package taskstore
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTasks(t *testing.T) {
+	root := newTestTask("root")
+	kept := newTestTask("kept")
+	kept.ParentID = &root.ID
+	edited := newTestTask("edited")
+	edited.Verify = [][]string{{"go", "test"}}
+	removed := newTestTask("removed")
+
+	newRoot := newTestTask("root")
+	newRoot.Status = StatusCompleted // status is not part of the definition
+	newKept := newTestTask("kept")
+	newKept.ParentID = &newRoot.ID
+	newEdited := newTestTask("edited")
+	newEdited.Title = "Edited title"
+	newEdited.Verify = [][]string{{"go", "test", "./..."}}
+	newEdited.DependsOn = []string{"kept"}
+	added := newTestTask("added")
+
+	diff := DiffTasks(
+		[]*Task{root, kept, edited, removed},
+		[]*Task{newRoot, newKept, newEdited, added},
+	)
+
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "added", diff.Added[0].ID)
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, "removed", diff.Removed[0].ID)
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, "edited", diff.Changed[0].New.ID)
+	assert.Equal(t, []string{"title", "dependsOn", "verify"}, diff.Changed[0].Fields)
+	assert.False(t, diff.Empty())
+
+	assert.True(t, DiffTasks([]*Task{root, kept}, []*Task{newRoot, newKept}).Empty())
+}
+
+func TestReplaceTasks(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	done := newTestTask("done")
+	done.Status = StatusCompleted
+	gone := newTestTask("gone")
+	unrelated := newTestTask("unrelated")
+	for _, task := range []*Task{done, gone, unrelated} {
+		require.NoError(t, store.Save(task))
+	}
+
+	replacement := newTestTask("done")
+	replacement.Title = "Rewritten"
+	fresh := newTestTask("fresh")
+
+	require.NoError(t, ReplaceTasks(store, []*Task{done, gone}, []*Task{replacement, fresh}))
+
+	got, err := store.Get("done")
+	require.NoError(t, err)
+	assert.Equal(t, "Rewritten", got.Title)
+	assert.Equal(t, StatusCompleted, got.Status, "existing status is kept")
+
+	_, err = store.Get("fresh")
+	require.NoError(t, err)
+
+	_, err = store.Get("unrelated")
+	require.NoError(t, err, "tasks outside the replaced set are kept")
+
+	_, err = store.Get("gone")
+	var notFound *NotFoundError
+	assert.True(t, errors.As(err, &notFound))
+}
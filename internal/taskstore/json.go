@@ -0,0 +1,91 @@
+package taskstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ParseJSON parses a JSON task list into a YAMLFile. The list is either an
+// object with a "tasks" array, as in YAML task files, or a bare array of
+// tasks. Fields have the same names as in YAML (e.g. parentId, dependsOn).
+func ParseJSON(data []byte) (*YAMLFile, error) {
+	var file YAMLFile
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &file.Tasks); err != nil {
+			return nil, err
+		}
+		return &file, nil
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// ImportFromJSON reads tasks from a JSON file and imports them into the
+// store like ImportFromYAML.
+func ImportFromJSON(store Store, path string) (*ImportResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON file: %w", err)
+	}
+
+	file, err := ParseJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	return importYAMLTasks(store, file.Tasks, false), nil
+}
+
+// ImportFromFile imports tasks from a JSON file when path ends in .json,
+// and from a YAML file otherwise.
+func ImportFromFile(store Store, path string) (*ImportResult, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ImportFromJSON(store, path)
+	}
+	return ImportFromYAML(store, path)
+}
+
+// ReimportFromFile imports a task file like ImportFromFile, except that tasks
+// already in the store keep their status and creation time. A task file that
+// was edited mid-run can be imported again without resetting progress.
+// Tasks in previous, the IDs listed by the last import of the file, that the
+// file no longer lists are deleted from the store.
+func ReimportFromFile(store Store, path string, previous []string) (*ImportResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task file: %w", err)
+	}
+
+	parse := ParseYAML
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		parse = ParseJSON
+	}
+	file, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse task file: %w", err)
+	}
+
+	result := importYAMLTasks(store, file.Tasks, true)
+	for _, id := range previous {
+		if slices.Contains(result.IDs, id) {
+			continue
+		}
+		if err := store.Delete(id); err != nil {
+			var notFound *NotFoundError
+			if errors.As(err, &notFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to remove task %q: %w", id, err)
+		}
+		result.Removed = append(result.Removed, id)
+	}
+	return result, nil
+}
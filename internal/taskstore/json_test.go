@@ -0,0 +1,164 @@
+package taskstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"object with tasks", `{"tasks": [{"id": "root", "title": "Root"}, {"id": "leaf", "title": "Leaf", "parentId": "root", "dependsOn": ["other"], "verify": [["go", "test", "./..."]], "labels": {"area": "core"}}]}`},
+		{"bare array", `[{"id": "root", "title": "Root"}, {"id": "leaf", "title": "Leaf", "parentId": "root", "dependsOn": ["other"], "verify": [["go", "test", "./..."]], "labels": {"area": "core"}}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ParseJSON([]byte(tt.content))
+			require.NoError(t, err)
+			require.Len(t, file.Tasks, 2)
+
+			leaf := file.Tasks[1]
+			assert.Equal(t, "leaf", leaf.ID)
+			assert.Equal(t, "root", leaf.ParentID)
+			assert.Equal(t, []string{"other"}, leaf.DependsOn)
+			assert.Equal(t, [][]string{{"go", "test", "./..."}}, leaf.Verify)
+			assert.Equal(t, map[string]string{"area": "core"}, leaf.Labels)
+		})
+	}
+
+	_, err := ParseJSON([]byte(`{"tasks": [`))
+	assert.Error(t, err)
+}
+
+func TestImportFromFile_JSON(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "spec.json")
+	content := `{"tasks": [
+  {"id": "root", "title": "Root"},
+  {"id": "leaf", "title": "Leaf", "parentId": "root", "status": "completed"},
+  {"id": "bad", "title": "Bad", "status": "unknown"}
+]}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	result, err := ImportFromFile(store, path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "bad", result.Errors[0].ID)
+
+	leaf, err := store.Get("leaf")
+	require.NoError(t, err)
+	require.NotNil(t, leaf.ParentID)
+	assert.Equal(t, "root", *leaf.ParentID)
+	assert.Equal(t, StatusCompleted, leaf.Status)
+
+	root, err := store.Get("root")
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, root.Status, "status defaults to open")
+}
+
+func TestImportFromJSON_InvalidJSON(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "spec.json")
+	require.NoError(t, os.WriteFile(path, []byte("tasks:\n  - id: a\n"), 0644))
+
+	_, err = ImportFromJSON(store, path)
+	assert.ErrorContains(t, err, "failed to parse JSON")
+}
+
+func TestReimportFromFile_KeepsProgress(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "tasks.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`tasks:
+  - id: root
+    title: Root
+  - id: first
+    title: First
+    parentId: root
+`), 0644))
+	_, err = ImportFromFile(store, path)
+	require.NoError(t, err)
+	require.NoError(t, store.UpdateStatus("first", StatusCompleted))
+
+	require.NoError(t, os.WriteFile(path, []byte(`tasks:
+  - id: root
+    title: Root
+  - id: first
+    title: First, renamed
+    parentId: root
+  - id: second
+    title: Second
+    parentId: root
+`), 0644))
+	result, err := ReimportFromFile(store, path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Imported)
+	assert.Empty(t, result.Errors)
+
+	first, err := store.Get("first")
+	require.NoError(t, err)
+	assert.Equal(t, "First, renamed", first.Title)
+	assert.Equal(t, StatusCompleted, first.Status, "existing tasks keep their status")
+
+	second, err := store.Get("second")
+	require.NoError(t, err)
+	assert.Equal(t, StatusOpen, second.Status)
+
+	require.NoError(t, os.WriteFile(path, []byte("tasks: ["), 0644))
+	_, err = ReimportFromFile(store, path, nil)
+	assert.ErrorContains(t, err, "failed to parse task file")
+}
+
+func TestReimportFromFile_RemovesDroppedTasks(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "tasks.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`tasks:
+  - id: root
+    title: Root
+  - id: first
+    title: First
+    parentId: root
+  - id: second
+    title: Second
+    parentId: root
+`), 0644))
+	imported, err := ImportFromFile(store, path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root", "first", "second"}, imported.IDs)
+	now := time.Now()
+	require.NoError(t, store.Save(&Task{ID: "other", Title: "Not from the file", Status: StatusOpen, CreatedAt: now, UpdatedAt: now}))
+
+	require.NoError(t, os.WriteFile(path, []byte(`tasks:
+  - id: root
+    title: Root
+  - id: first
+    title: First
+    parentId: root
+`), 0644))
+	result, err := ReimportFromFile(store, path, imported.IDs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root", "first"}, result.IDs)
+	assert.Equal(t, []string{"second"}, result.Removed)
+
+	_, err = store.Get("second")
+	var notFound *NotFoundError
+	assert.ErrorAs(t, err, &notFound, "a task dropped from the file is removed")
+	_, err = store.Get("other")
+	assert.NoError(t, err, "tasks the file never listed are kept")
+}
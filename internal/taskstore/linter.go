@@ -2,8 +2,11 @@ package taskstore
 
 import (
 	"fmt"
+	"path"
 	"sort"
 	"strings"
+
+	"github.com/yarlson/ralph/internal/verifier"
 )
 
 // LintError represents a validation error for a specific task.
@@ -80,9 +83,75 @@ func LintTaskWithWarnings(task *Task) ([]string, error) {
 		warnings = append(warnings, "acceptance criteria missing (recommended for verification)")
 	}
 
+	// Every verifyWhen glob must belong to a verify command and be well formed
+	if len(task.VerifyWhen) > len(task.Verify) {
+		return warnings, fmt.Errorf("verifyWhen has %d entries but there are only %d verify commands", len(task.VerifyWhen), len(task.Verify))
+	}
+	for _, glob := range task.VerifyWhen {
+		if _, err := path.Match(strings.TrimSuffix(glob, "/**"), ""); err != nil {
+			return warnings, fmt.Errorf("verifyWhen glob %q is invalid: %w", glob, err)
+		}
+	}
+
+	// Every verifyExitCodes mapping must belong to a verify command and be well formed
+	if len(task.VerifyExitCodes) > len(task.Verify) {
+		return warnings, fmt.Errorf("verifyExitCodes has %d entries but there are only %d verify commands", len(task.VerifyExitCodes), len(task.Verify))
+	}
+	for i, codes := range task.VerifyExitCodes {
+		if _, err := verifier.ParseExitCodeMapping(codes); err != nil {
+			return warnings, fmt.Errorf("verifyExitCodes for %v: %w", task.Verify[i], err)
+		}
+	}
+
+	// Context files are read from inside the repository only
+	for _, file := range task.ContextFiles {
+		if clean := path.Clean(file); file == "" || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return warnings, fmt.Errorf("contextFiles path %q must be relative to the repository root", file)
+		}
+	}
+
+	// Allowed path globs are matched against repository-relative paths
+	for _, glob := range task.AllowedPaths {
+		if _, err := path.Match(strings.TrimSuffix(glob, "/**"), ""); err != nil {
+			return warnings, fmt.Errorf("allowedPaths glob %q is invalid: %w", glob, err)
+		}
+	}
+
+	// Warn about shell syntax in verify commands, which run without a shell (non-fatal)
+	for _, cmd := range task.Verify {
+		if op, ok := findShellOperator(cmd); ok {
+			warnings = append(warnings, fmt.Sprintf(
+				"verify command %q uses shell operator %q but runs without a shell; split it into separate verify commands",
+				strings.Join(cmd, " "), op))
+		}
+	}
+
 	return warnings, nil
 }
 
+// shellOperators are shell control and redirection operators that have no
+// effect in an argv-style verify command. Longer operators come first.
+var shellOperators = []string{"&&", "||", "|", ">", "<", ";"}
+
+// findShellOperator reports the first shell operator in cmd that looks like
+// shell syntax: a token that is an operator, or contains whitespace and an
+// operator (e.g. "go test && go vet"). Tokens like "TestA|TestB" are allowed
+// since they are common as regex arguments, and explicit "sh -c" commands are skipped.
+func findShellOperator(cmd []string) (string, bool) {
+	if len(cmd) >= 2 && (cmd[0] == "sh" || cmd[0] == "bash") && cmd[1] == "-c" {
+		return "", false
+	}
+
+	for _, token := range cmd {
+		for _, op := range shellOperators {
+			if token == op || (strings.ContainsAny(token, " \t") && strings.Contains(token, op)) {
+				return op, true
+			}
+		}
+	}
+	return "", false
+}
+
 // LintTaskSet validates an entire set of tasks.
 // It checks for:
 // - Individual task validity
@@ -165,7 +234,7 @@ func LintTaskSet(tasks []*Task) *LintResult {
 	// Check leaf tasks have verify commands
 	for _, task := range tasks {
 		if isLeafTask(tasks, task.ID) {
-			if len(task.Verify) == 0 {
+			if len(task.Verify) == 0 && task.VerifySet == "" {
 				result.Valid = false
 				result.Errors = append(result.Errors, LintError{
 					TaskID: task.ID,
@@ -1,6 +1,7 @@
 package taskstore
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -57,6 +58,178 @@ func TestLintTask_MissingAcceptance(t *testing.T) {
 	assert.Contains(t, warnings[0], "acceptance criteria")
 }
 
+func TestLintTask_ShellOperatorsInVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		verify []string
+		wantOp string
+	}{
+		{name: "operators in a single token", verify: []string{"go test ./... && go vet ./..."}, wantOp: "&&"},
+		{name: "operator as its own token", verify: []string{"go", "test", "|", "tee", "out.txt"}, wantOp: "|"},
+		{name: "redirect", verify: []string{"go", "test", ">", "out.txt"}, wantOp: ">"},
+		{name: "semicolon", verify: []string{"make lint; make test"}, wantOp: ";"},
+		{name: "plain argv", verify: []string{"go", "test", "./..."}},
+		{name: "regex argument", verify: []string{"go", "test", "-run", "TestA|TestB"}},
+		{name: "explicit shell", verify: []string{"sh", "-c", "go test && go vet"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				ID:          "test-1",
+				Title:       "Test Task",
+				Description: "A test task",
+				Status:      StatusOpen,
+				Acceptance:  []string{"works"},
+				Verify:      [][]string{tt.verify},
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			}
+
+			warnings, err := LintTaskWithWarnings(task)
+			require.NoError(t, err)
+			if tt.wantOp == "" {
+				assert.Empty(t, warnings)
+				return
+			}
+			require.Len(t, warnings, 1)
+			assert.Contains(t, warnings[0], fmt.Sprintf("shell operator %q", tt.wantOp))
+			assert.Contains(t, warnings[0], "split it into separate verify commands")
+		})
+	}
+}
+
+func TestLintTask_VerifyWhen(t *testing.T) {
+	tests := []struct {
+		name    string
+		when    []string
+		wantErr string
+	}{
+		{name: "glob per command", when: []string{"*.go", ""}},
+		{name: "directory glob", when: []string{"web/**"}},
+		{name: "more globs than commands", when: []string{"*.go", "*.md", "*.ts"}, wantErr: "verifyWhen has 3 entries but there are only 2 verify commands"},
+		{name: "malformed glob", when: []string{"[a-"}, wantErr: `verifyWhen glob "[a-" is invalid`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				ID:          "test-1",
+				Title:       "Test Task",
+				Description: "A test task",
+				Status:      StatusOpen,
+				Acceptance:  []string{"works"},
+				Verify:      [][]string{{"go", "test", "./..."}, {"markdownlint", "."}},
+				VerifyWhen:  tt.when,
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			}
+
+			_, err := LintTaskWithWarnings(task)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLintTask_VerifyExitCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		codes   []map[string]string
+		wantErr string
+	}{
+		{name: "mapping per command", codes: []map[string]string{nil, {"2": "warn", "3": "skip"}}},
+		{name: "more mappings than commands", codes: []map[string]string{nil, nil, {"2": "warn"}}, wantErr: "verifyExitCodes has 3 entries but there are only 2 verify commands"},
+		{name: "unknown result", codes: []map[string]string{{"2": "maybe"}}, wantErr: `verifyExitCodes for [go test ./...]: exit code 2 has unknown result "maybe"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				ID:              "test-1",
+				Title:           "Test Task",
+				Description:     "A test task",
+				Status:          StatusOpen,
+				Acceptance:      []string{"works"},
+				Verify:          [][]string{{"go", "test", "./..."}, {"golangci-lint", "run"}},
+				VerifyExitCodes: tt.codes,
+				CreatedAt:       time.Now(),
+				UpdatedAt:       time.Now(),
+			}
+
+			_, err := LintTaskWithWarnings(task)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLintTask_AllowedPaths(t *testing.T) {
+	task := &Task{
+		ID:           "test-1",
+		Title:        "Test Task",
+		Description:  "A test task",
+		Status:       StatusOpen,
+		Acceptance:   []string{"works"},
+		Verify:       [][]string{{"go", "test", "./..."}},
+		AllowedPaths: []string{"internal/foo/**", "*.md"},
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	_, err := LintTaskWithWarnings(task)
+	require.NoError(t, err)
+
+	task.AllowedPaths = []string{"internal/[foo/**"}
+	_, err = LintTaskWithWarnings(task)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `allowedPaths glob "internal/[foo/**" is invalid`)
+}
+
+func TestLintTask_ContextFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		wantErr string
+	}{
+		{name: "repository paths", files: []string{"internal/selector/graph.go", "./README.md"}},
+		{name: "absolute path", files: []string{"/etc/passwd"}, wantErr: `contextFiles path "/etc/passwd" must be relative to the repository root`},
+		{name: "escapes the repository", files: []string{"docs/../../secrets.txt"}, wantErr: `contextFiles path "docs/../../secrets.txt" must be relative`},
+		{name: "empty path", files: []string{""}, wantErr: `contextFiles path "" must be relative`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				ID:           "test-1",
+				Title:        "Test Task",
+				Description:  "A test task",
+				Status:       StatusOpen,
+				Acceptance:   []string{"works"},
+				Verify:       [][]string{{"go", "test", "./..."}},
+				ContextFiles: tt.files,
+				CreatedAt:    time.Now(),
+				UpdatedAt:    time.Now(),
+			}
+
+			_, err := LintTaskWithWarnings(task)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
 func TestLintTask_MissingVerifyOnLeaf(t *testing.T) {
 	// For this test, we need to pass the context that this is a leaf task
 	// We'll test this in LintTaskSet
@@ -31,6 +31,19 @@ func (s *LocalStore) taskPath(id string) string {
 	return filepath.Join(s.dir, id+".json")
 }
 
+// Version returns the modification time of the tasks directory. Saves and
+// deletes rename or remove a file in it, so any change made through a
+// LocalStore, in this process or another, yields a new version unless it
+// falls in the same filesystem timestamp tick as the previous change. Task
+// files edited in place by other tools are not detected.
+func (s *LocalStore) Version() (time.Time, error) {
+	info, err := os.Stat(s.dir)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat tasks directory: %w", err)
+	}
+	return info.ModTime(), nil
+}
+
 // Get retrieves a task by its ID.
 func (s *LocalStore) Get(id string) (*Task, error) {
 	s.mu.RLock()
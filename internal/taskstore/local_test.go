@@ -365,3 +365,36 @@ func newTestStore(t *testing.T) *LocalStore {
 	require.NoError(t, err)
 	return store
 }
+
+func TestLocalStore_Version(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	steps := []struct {
+		name  string
+		write func() error
+	}{
+		{name: "save", write: func() error { return store.Save(newTestTask("task-1")) }},
+		{name: "update status", write: func() error { return store.UpdateStatus("task-1", StatusCompleted) }},
+		{name: "delete", write: func() error { return store.Delete("task-1") }},
+	}
+
+	version, err := store.Version()
+	require.NoError(t, err)
+
+	for _, step := range steps {
+		require.NoError(t, step.write(), step.name)
+
+		next, err := store.Version()
+		require.NoError(t, err)
+		assert.NotEqual(t, version, next, "%s should change the version", step.name)
+		version = next
+	}
+
+	// Reads leave the version unchanged
+	_, err = store.List()
+	require.NoError(t, err)
+	unchanged, err := store.Version()
+	require.NoError(t, err)
+	assert.Equal(t, version, unchanged)
+}
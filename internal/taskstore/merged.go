@@ -0,0 +1,61 @@
+package taskstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MergeChecker reports whether a branch has been merged into a target branch.
+type MergeChecker interface {
+	BranchMergedInto(ctx context.Context, branch, target string) (bool, error)
+}
+
+// ReconcileMerged marks tasks completed when a branch named after them has
+// been merged into target. Each pattern is a branch name in which "{id}" is
+// replaced by the task ID (e.g. "{id}", "origin/feature/{id}"). Completed and
+// skipped tasks are left alone. Returns the tasks that were marked completed.
+func ReconcileMerged(ctx context.Context, store Store, checker MergeChecker, target string, patterns []string) ([]*Task, error) {
+	tasks, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	var reconciled []*Task
+	for _, t := range tasks {
+		if t.Status == StatusCompleted || t.Status == StatusSkipped {
+			continue
+		}
+
+		merged, err := taskBranchMerged(ctx, checker, t.ID, target, patterns)
+		if err != nil {
+			return reconciled, err
+		}
+		if !merged {
+			continue
+		}
+
+		if err := store.UpdateStatus(t.ID, StatusCompleted); err != nil {
+			return reconciled, fmt.Errorf("failed to mark %s completed: %w", t.ID, err)
+		}
+		t.Status = StatusCompleted
+		reconciled = append(reconciled, t)
+	}
+
+	return reconciled, nil
+}
+
+// taskBranchMerged reports whether any branch for taskID is merged into target.
+func taskBranchMerged(ctx context.Context, checker MergeChecker, taskID, target string, patterns []string) (bool, error) {
+	for _, pattern := range patterns {
+		branch := strings.ReplaceAll(pattern, "{id}", taskID)
+		merged, err := checker.BranchMergedInto(ctx, branch, target)
+		if err != nil {
+			return false, fmt.Errorf("failed to check branch %s: %w", branch, err)
+		}
+		if merged {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,91 @@
+package taskstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMergeChecker reports the branches in merged as merged into "main".
+type fakeMergeChecker struct {
+	merged  map[string]bool
+	err     error
+	checked []string
+}
+
+func (f *fakeMergeChecker) BranchMergedInto(_ context.Context, branch, target string) (bool, error) {
+	f.checked = append(f.checked, branch)
+	if f.err != nil {
+		return false, f.err
+	}
+	return target == "main" && f.merged[branch], nil
+}
+
+func TestReconcileMerged(t *testing.T) {
+	setup := func(t *testing.T) *LocalStore {
+		store, err := NewLocalStore(t.TempDir())
+		require.NoError(t, err)
+
+		open := newTestTask("open")
+		failed := newTestTask("failed")
+		failed.Status = StatusFailed
+		skipped := newTestTask("skipped")
+		skipped.Status = StatusSkipped
+		unmerged := newTestTask("unmerged")
+		for _, task := range []*Task{open, failed, skipped, unmerged} {
+			require.NoError(t, store.Save(task))
+		}
+		return store
+	}
+
+	t.Run("marks tasks with merged branches completed", func(t *testing.T) {
+		store := setup(t)
+		checker := &fakeMergeChecker{merged: map[string]bool{
+			"open":                  true,
+			"origin/feature/failed": true,
+			"skipped":               true,
+		}}
+
+		reconciled, err := ReconcileMerged(context.Background(), store, checker, "main", []string{"{id}", "origin/feature/{id}"})
+		require.NoError(t, err)
+
+		var ids []string
+		for _, task := range reconciled {
+			ids = append(ids, task.ID)
+		}
+		assert.ElementsMatch(t, []string{"open", "failed"}, ids)
+
+		for id, want := range map[string]TaskStatus{
+			"open":     StatusCompleted,
+			"failed":   StatusCompleted,
+			"skipped":  StatusSkipped,
+			"unmerged": StatusOpen,
+		} {
+			task, err := store.Get(id)
+			require.NoError(t, err)
+			assert.Equal(t, want, task.Status, id)
+		}
+		assert.NotContains(t, checker.checked, "skipped")
+	})
+
+	t.Run("uses the target branch", func(t *testing.T) {
+		store := setup(t)
+		checker := &fakeMergeChecker{merged: map[string]bool{"open": true}}
+
+		reconciled, err := ReconcileMerged(context.Background(), store, checker, "develop", []string{"{id}"})
+		require.NoError(t, err)
+		assert.Empty(t, reconciled)
+	})
+
+	t.Run("returns checker errors", func(t *testing.T) {
+		store := setup(t)
+		checker := &fakeMergeChecker{err: errors.New("bad revision")}
+
+		_, err := ReconcileMerged(context.Background(), store, checker, "main", []string{"{id}"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "bad revision")
+	})
+}
@@ -3,6 +3,7 @@ package taskstore
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -17,6 +18,10 @@ const (
 	StatusBlocked    TaskStatus = "blocked"
 	StatusFailed     TaskStatus = "failed"
 	StatusSkipped    TaskStatus = "skipped"
+
+	// StatusAwaitingReview marks a task the agent finished without any verify
+	// command to check it (see verify.require_commands).
+	StatusAwaitingReview TaskStatus = "awaiting_review"
 )
 
 // validStatuses contains all valid status values for quick lookup.
@@ -27,6 +32,8 @@ var validStatuses = map[TaskStatus]bool{
 	StatusBlocked:    true,
 	StatusFailed:     true,
 	StatusSkipped:    true,
+
+	StatusAwaitingReview: true,
 }
 
 // IsValid returns true if the status is a valid TaskStatus value.
@@ -34,6 +41,49 @@ func (s TaskStatus) IsValid() bool {
 	return validStatuses[s]
 }
 
+// Priority ranks otherwise equally ready tasks. It mirrors the "priority"
+// label the decomposer emits.
+type Priority string
+
+// Valid priority values, highest first.
+const (
+	PriorityP0 Priority = "P0"
+	PriorityP1 Priority = "P1"
+	PriorityP2 Priority = "P2"
+)
+
+// priorityRanks orders priorities; a lower rank is selected first.
+var priorityRanks = map[Priority]int{
+	PriorityP0: 0,
+	PriorityP1: 1,
+	PriorityP2: 2,
+}
+
+// IsValid returns true if the priority is a valid Priority value.
+func (p Priority) IsValid() bool {
+	_, ok := priorityRanks[p]
+	return ok
+}
+
+// Rank returns the selection rank of the priority, 0 being the highest. An
+// unset priority ranks as P1, the decomposer's default.
+func (p Priority) Rank() int {
+	if rank, ok := priorityRanks[p]; ok {
+		return rank
+	}
+	return priorityRanks[PriorityP1]
+}
+
+// PriorityFromLabel returns the priority named by a "priority" label value
+// ("P0", "p1", ...), or "" if it names none.
+func PriorityFromLabel(label string) Priority {
+	p := Priority(strings.ToUpper(strings.TrimSpace(label)))
+	if !p.IsValid() {
+		return ""
+	}
+	return p
+}
+
 // Task represents a unit of work in the Ralph task hierarchy.
 type Task struct {
 	// ID is the unique identifier for the task.
@@ -60,9 +110,34 @@ type Task struct {
 	// Verify lists the commands to run for verification (e.g., [["go", "test", "./..."]]).
 	Verify [][]string `json:"verify,omitempty"`
 
+	// VerifySet names a configured set of verify commands (verify.sets) that
+	// run before the task's own Verify commands.
+	VerifySet string `json:"verify_set,omitempty"`
+
+	// VerifyWhen holds an optional file glob for each Verify command, by index
+	// (e.g., ["*.go"]). A command with a glob runs only if a changed file matches
+	// it; commands without one always run.
+	VerifyWhen []string `json:"verify_when,omitempty"`
+
+	// VerifyExitCodes holds an optional exit code mapping for each Verify
+	// command, by index (e.g., [{}, {"2": "warn", "3": "skip"}]). A mapped
+	// nonzero exit code counts as pass, warn, skip or fail instead of failing.
+	VerifyExitCodes []map[string]string `json:"verify_exit_codes,omitempty"`
+
+	// ContextFiles are repository paths whose contents are included in the
+	// iteration prompt as reference material.
+	ContextFiles []string `json:"context_files,omitempty"`
+
+	// AllowedPaths are file globs (e.g., ["internal/foo/**"]) the task's
+	// changes must stay within. An empty list allows any file.
+	AllowedPaths []string `json:"allowed_paths,omitempty"`
+
 	// Labels is a map of key-value pairs for categorization (e.g., {"area": "core"}).
 	Labels map[string]string `json:"labels,omitempty"`
 
+	// Priority ranks the task among other ready tasks; unset ranks as P1.
+	Priority Priority `json:"priority,omitempty"`
+
 	// CreatedAt is when the task was created.
 	CreatedAt time.Time `json:"created_at"`
 
@@ -85,6 +160,10 @@ func (t *Task) Validate() error {
 		return fmt.Errorf("task status is invalid: %q", t.Status)
 	}
 
+	if t.Priority != "" && !t.Priority.IsValid() {
+		return fmt.Errorf("task priority is invalid: %q", t.Priority)
+	}
+
 	if t.CreatedAt.IsZero() {
 		return fmt.Errorf("task created_at is required")
 	}
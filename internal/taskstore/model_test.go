@@ -149,6 +149,45 @@ func TestTask_Validate_InvalidStatus(t *testing.T) {
 	assert.Contains(t, err.Error(), "status")
 }
 
+func TestTask_Validate_InvalidPriority(t *testing.T) {
+	task := &Task{
+		ID:        "task-1",
+		Title:     "Test Task",
+		Status:    StatusOpen,
+		Priority:  Priority("urgent"),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err := task.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "priority")
+}
+
+func TestPriorityFromLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		want  Priority
+	}{
+		{label: "P0", want: PriorityP0},
+		{label: " p2 ", want: PriorityP2},
+		{label: "high", want: ""},
+		{label: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			assert.Equal(t, tt.want, PriorityFromLabel(tt.label))
+		})
+	}
+}
+
+func TestPriority_Rank(t *testing.T) {
+	assert.Less(t, PriorityP0.Rank(), PriorityP1.Rank())
+	assert.Less(t, PriorityP1.Rank(), PriorityP2.Rank())
+	assert.Equal(t, PriorityP1.Rank(), Priority("").Rank())
+}
+
 func TestTask_Validate_EmptyStatus(t *testing.T) {
 	task := &Task{
 		ID:        "task-1",
@@ -0,0 +1,57 @@
+package taskstore
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// pathTokenRe matches candidate file path tokens such as cmd/fix.go or README.md.
+var pathTokenRe = regexp.MustCompile(`[A-Za-z0-9_./-]+\.[A-Za-z][A-Za-z0-9]*`)
+
+// urlRe matches URLs, which are removed before scanning for paths.
+var urlRe = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://\S+`)
+
+// knownFileExts lists extensions accepted for paths without a directory
+// component, so prose like "e.g." or "Node.js" is not mistaken for a file.
+var knownFileExts = map[string]bool{
+	".go": true, ".mod": true, ".sum": true, ".md": true, ".txt": true,
+	".yaml": true, ".yml": true, ".json": true, ".toml": true,
+	".ts": true, ".tsx": true, ".js": true, ".jsx": true, ".py": true,
+	".rs": true, ".sql": true, ".sh": true, ".html": true, ".css": true,
+}
+
+// PlannedFiles extracts the file paths a task declares in its description and
+// acceptance criteria (e.g. "Create cmd/fix.go"). Paths are returned in order
+// of first appearance without duplicates.
+func PlannedFiles(task *Task) []string {
+	texts := append([]string{task.Description}, task.Acceptance...)
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		text = urlRe.ReplaceAllString(text, "")
+		for _, token := range pathTokenRe.FindAllString(text, -1) {
+			file := strings.TrimPrefix(strings.TrimRight(token, "."), "./")
+			if !isPlannedPath(file) || seen[file] {
+				continue
+			}
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
+// isPlannedPath reports whether token looks like a file path rather than prose
+// or a Go package pattern.
+func isPlannedPath(token string) bool {
+	if strings.Contains(token, "...") {
+		return false
+	}
+	if strings.Contains(token, "/") {
+		return true
+	}
+	return knownFileExts[path.Ext(token)]
+}
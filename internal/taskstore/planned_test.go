@@ -0,0 +1,46 @@
+package taskstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlannedFiles(t *testing.T) {
+	tests := []struct {
+		name       string
+		desc       string
+		acceptance []string
+		want       []string
+	}{
+		{
+			name: "create and modify paths",
+			desc: "Create cmd/fix.go and modify internal/state/state.go.",
+			want: []string{"cmd/fix.go", "internal/state/state.go"},
+		},
+		{
+			name:       "acceptance criteria and duplicates",
+			desc:       "Modify `internal/handler/handler.go`",
+			acceptance: []string{"internal/handler/handler.go implements HandleRequest", "internal/handler/handler_test.go contains tests", "README.md documents the flag"},
+			want:       []string{"internal/handler/handler.go", "internal/handler/handler_test.go", "README.md"},
+		},
+		{
+			name:       "ignores prose, urls, and package patterns",
+			desc:       "Follow the style guide, e.g. see https://example.com/docs.html, then run go test ./internal/...",
+			acceptance: []string{"go test ./... passes"},
+			want:       nil,
+		},
+		{
+			name: "strips leading dot slash",
+			desc: "Update ./scripts/build.sh",
+			want: []string{"scripts/build.sh"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{ID: "t", Title: "T", Description: tt.desc, Acceptance: tt.acceptance}
+			assert.Equal(t, tt.want, PlannedFiles(task))
+		})
+	}
+}
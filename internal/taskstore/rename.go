@@ -0,0 +1,100 @@
+package taskstore
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RenameTask changes a task's ID from oldID to newID and rewrites every
+// parentId and dependsOn reference to it. The renamed task set is linted
+// before anything is written; the rename is refused if it would introduce
+// lint errors. The old task is deleted only after every write succeeded; if
+// any write fails, the tasks already written are restored so the store is
+// left as it was. Returns the other tasks whose references were rewritten.
+func RenameTask(store Store, oldID, newID string) ([]*Task, error) {
+	if newID == "" || strings.ContainsAny(newID, `/\`) || strings.TrimSpace(newID) != newID {
+		return nil, fmt.Errorf("invalid task ID %q", newID)
+	}
+	if oldID == newID {
+		return nil, fmt.Errorf("task %s already has that ID", oldID)
+	}
+
+	tasks, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	var renamed *Task
+	for _, t := range tasks {
+		switch t.ID {
+		case oldID:
+			renamed = t
+		case newID:
+			return nil, fmt.Errorf("task %s already exists", newID)
+		}
+	}
+	if renamed == nil {
+		return nil, &NotFoundError{ID: oldID}
+	}
+
+	before := LintTaskSet(tasks)
+
+	now := time.Now()
+	renamed.ID = newID
+	renamed.UpdatedAt = now
+
+	var updated, originals []*Task
+	for _, t := range tasks {
+		if t == renamed {
+			continue
+		}
+		isChild := t.ParentID != nil && *t.ParentID == oldID
+		i := slices.Index(t.DependsOn, oldID)
+		if !isChild && i < 0 {
+			continue
+		}
+		original := *t
+		original.DependsOn = slices.Clone(t.DependsOn)
+		originals = append(originals, &original)
+		if isChild {
+			parentID := newID
+			t.ParentID = &parentID
+		}
+		if i >= 0 {
+			t.DependsOn[i] = newID
+		}
+		t.UpdatedAt = now
+		updated = append(updated, t)
+	}
+
+	if after := LintTaskSet(tasks); len(after.Errors) > len(before.Errors) {
+		return nil, fmt.Errorf("rename would leave the task set invalid: %w", after.Error())
+	}
+
+	// rollback restores the first n rewritten tasks and drops the new ID.
+	rollback := func(n int) {
+		for _, t := range originals[:n] {
+			_ = store.Save(t)
+		}
+		_ = store.Delete(newID)
+	}
+
+	if err := store.Save(renamed); err != nil {
+		rollback(0)
+		return nil, fmt.Errorf("failed to save task %s: %w", newID, err)
+	}
+	for i, t := range updated {
+		if err := store.Save(t); err != nil {
+			rollback(i)
+			return nil, fmt.Errorf("failed to save task %s: %w", t.ID, err)
+		}
+	}
+	if err := store.Delete(oldID); err != nil {
+		rollback(len(updated))
+		return nil, fmt.Errorf("failed to delete task %s: %w", oldID, err)
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,120 @@
+package taskstore
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameTask(t *testing.T) {
+	setup := func(t *testing.T) *LocalStore {
+		store, err := NewLocalStore(t.TempDir())
+		require.NoError(t, err)
+
+		root := newTestTask("root")
+		old := newTestTask("old")
+		old.ParentID = &root.ID
+		child := newTestTask("child")
+		child.ParentID = &old.ID
+		dependent := newTestTask("dependent")
+		dependent.DependsOn = []string{"other", "old"}
+		other := newTestTask("other")
+		for _, task := range []*Task{root, old, child, dependent, other} {
+			require.NoError(t, store.Save(task))
+		}
+		return store
+	}
+
+	t.Run("renames task and rewrites references", func(t *testing.T) {
+		store := setup(t)
+
+		updated, err := RenameTask(store, "old", "new")
+		require.NoError(t, err)
+
+		var ids []string
+		for _, u := range updated {
+			ids = append(ids, u.ID)
+		}
+		assert.ElementsMatch(t, []string{"child", "dependent"}, ids)
+
+		_, err = store.Get("old")
+		var notFound *NotFoundError
+		assert.True(t, errors.As(err, &notFound))
+
+		renamed, err := store.Get("new")
+		require.NoError(t, err)
+		assert.Equal(t, "root", *renamed.ParentID)
+
+		child, err := store.Get("child")
+		require.NoError(t, err)
+		assert.Equal(t, "new", *child.ParentID)
+
+		dependent, err := store.Get("dependent")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"other", "new"}, dependent.DependsOn)
+	})
+
+	t.Run("rejects existing target ID", func(t *testing.T) {
+		store := setup(t)
+
+		_, err := RenameTask(store, "old", "other")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already exists")
+
+		_, err = store.Get("old")
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects unknown task", func(t *testing.T) {
+		store := setup(t)
+
+		_, err := RenameTask(store, "missing", "new")
+		var notFound *NotFoundError
+		assert.True(t, errors.As(err, &notFound))
+	})
+
+	t.Run("rejects invalid IDs", func(t *testing.T) {
+		store := setup(t)
+
+		for _, id := range []string{"", "a/b", " padded", "old"} {
+			_, err := RenameTask(store, "old", id)
+			assert.Error(t, err, id)
+		}
+	})
+
+	t.Run("failed write restores the original tasks", func(t *testing.T) {
+		store := setup(t)
+		failing := &failingSaveStore{Store: store, failID: "dependent"}
+
+		_, err := RenameTask(failing, "old", "new")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to save task dependent")
+
+		all, err := store.List()
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"root", "old", "child", "dependent", "other"}, taskIDs(all))
+
+		child, err := store.Get("child")
+		require.NoError(t, err)
+		assert.Equal(t, "old", *child.ParentID)
+
+		dependent, err := store.Get("dependent")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"other", "old"}, dependent.DependsOn)
+	})
+}
+
+// failingSaveStore fails every Save of the task with failID.
+type failingSaveStore struct {
+	Store
+	failID string
+}
+
+func (s *failingSaveStore) Save(task *Task) error {
+	if task.ID == s.failID {
+		return errors.New("disk full")
+	}
+	return s.Store.Save(task)
+}
@@ -0,0 +1,231 @@
+package taskstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// sqliteSchema creates the tasks table. Each row holds the task as JSON, in
+// the same form LocalStore writes to files, with the parent and status
+// copied into indexed columns for filtered queries.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS tasks (
+	id        TEXT PRIMARY KEY,
+	parent_id TEXT,
+	status    TEXT NOT NULL,
+	data      TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS tasks_parent_id ON tasks (parent_id);
+CREATE INDEX IF NOT EXISTS tasks_status ON tasks (status);
+`
+
+// SQLiteStore implements the Store interface with a single SQLite database,
+// which keeps large task sets fast to list and out of git as one file.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens the SQLite database at path, creating it and its
+// directory if they do not exist. The database uses WAL journaling and waits
+// for locks held by other processes instead of failing.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create tasks database directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tasks database: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create tasks table: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Get retrieves a task by its ID.
+func (s *SQLiteStore) Get(id string) (*Task, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM tasks WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &NotFoundError{ID: id}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task: %w", err)
+	}
+	return decodeTask(data)
+}
+
+// List retrieves all tasks, ordered by ID like LocalStore.
+func (s *SQLiteStore) List() ([]*Task, error) {
+	return s.query(`SELECT data FROM tasks ORDER BY id`)
+}
+
+// ListByParent retrieves all tasks with the given parent ID.
+// If parentID is empty, returns tasks with no parent (root tasks).
+func (s *SQLiteStore) ListByParent(parentID string) ([]*Task, error) {
+	if parentID == "" {
+		return s.query(`SELECT data FROM tasks WHERE parent_id IS NULL ORDER BY id`)
+	}
+	return s.query(`SELECT data FROM tasks WHERE parent_id = ? ORDER BY id`, parentID)
+}
+
+// ListByStatus retrieves all tasks with the given status.
+func (s *SQLiteStore) ListByStatus(status TaskStatus) ([]*Task, error) {
+	return s.query(`SELECT data FROM tasks WHERE status = ? ORDER BY id`, string(status))
+}
+
+// query runs a SELECT of the data column and decodes every row.
+func (s *SQLiteStore) query(query string, args ...any) ([]*Task, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tasks []*Task
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read task: %w", err)
+		}
+		task, err := decodeTask(data)
+		if err != nil {
+			// Skip rows that can't be parsed, like LocalStore skips files
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// Save persists a task to the store.
+// If a task with the same ID exists, it is updated.
+func (s *SQLiteStore) Save(task *Task) error {
+	task.UpdatedAt = time.Now().Truncate(time.Second)
+
+	if err := task.Validate(); err != nil {
+		return &ValidationError{ID: task.ID, Reason: err.Error()}
+	}
+
+	return s.writeTask(s.db, task)
+}
+
+// execer is the part of *sql.DB and *sql.Tx that writeTask needs.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// writeTask inserts or replaces task.
+func (s *SQLiteStore) writeTask(db execer, task *Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	_, err = db.Exec(`INSERT INTO tasks (id, parent_id, status, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET parent_id = excluded.parent_id, status = excluded.status, data = excluded.data`,
+		task.ID, task.ParentID, string(task.Status), string(data))
+	if err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatus updates only the status of an existing task.
+func (s *SQLiteStore) UpdateStatus(id string, status TaskStatus) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var data string
+	err = tx.QueryRow(`SELECT data FROM tasks WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &NotFoundError{ID: id}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read task: %w", err)
+	}
+	task, err := decodeTask(data)
+	if err != nil {
+		return err
+	}
+
+	task.Status = status
+	task.UpdatedAt = time.Now().Truncate(time.Second)
+	if err := s.writeTask(tx, task); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit status update: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a task by its ID.
+func (s *SQLiteStore) Delete(id string) error {
+	result, err := s.db.Exec(`DELETE FROM tasks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	if n == 0 {
+		return &NotFoundError{ID: id}
+	}
+	return nil
+}
+
+// importFrom copies every task in src into the store in one transaction,
+// keeping their timestamps.
+func (s *SQLiteStore) importFrom(src Store) error {
+	tasks, err := src.List()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, task := range tasks {
+		if err := s.writeTask(tx, task); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit import: %w", err)
+	}
+	return nil
+}
+
+// decodeTask parses a task stored as JSON.
+func decodeTask(data string) (*Task, error) {
+	var task Task
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		return nil, fmt.Errorf("failed to parse task: %w", err)
+	}
+	return &task, nil
+}
@@ -0,0 +1,244 @@
+package taskstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "tasks.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestSQLiteStore_CRUD(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	parent := "root"
+	task := newTestTask("child")
+	task.ParentID = &parent
+	task.DependsOn = []string{"other"}
+	task.Labels = map[string]string{"area": "core"}
+	task.Verify = [][]string{{"go", "test", "./..."}}
+	require.NoError(t, store.Save(newTestTask("root")))
+	require.NoError(t, store.Save(task))
+
+	got, err := store.Get("child")
+	require.NoError(t, err)
+	assert.Equal(t, "Test Task child", got.Title)
+	require.NotNil(t, got.ParentID)
+	assert.Equal(t, "root", *got.ParentID)
+	assert.Equal(t, []string{"other"}, got.DependsOn)
+	assert.Equal(t, map[string]string{"area": "core"}, got.Labels)
+	assert.Equal(t, [][]string{{"go", "test", "./..."}}, got.Verify)
+
+	task.Title = "Renamed"
+	require.NoError(t, store.Save(task))
+	got, err = store.Get("child")
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", got.Title, "saving an existing ID updates it")
+
+	require.NoError(t, store.UpdateStatus("child", StatusCompleted))
+	got, err = store.Get("child")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, got.Status)
+
+	require.NoError(t, store.Delete("child"))
+	_, err = store.Get("child")
+	var notFound *NotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "child", notFound.ID)
+}
+
+func TestSQLiteStore_Errors(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	_, err := store.Get("missing")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(store.UpdateStatus("missing", StatusCompleted), ErrNotFound))
+	assert.True(t, errors.Is(store.Delete("missing"), ErrNotFound))
+
+	invalid := newTestTask("bad")
+	invalid.Title = ""
+	assert.True(t, errors.Is(store.Save(invalid), ErrValidation))
+}
+
+func TestSQLiteStore_Queries(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	root, api := "root", "api"
+	tasks := []*Task{newTestTask("root"), newTestTask("api"), newTestTask("api-b"), newTestTask("api-a"), newTestTask("other")}
+	tasks[1].ParentID = &root
+	tasks[2].ParentID = &api
+	tasks[3].ParentID = &api
+	tasks[3].Status = StatusCompleted
+	for _, task := range tasks {
+		require.NoError(t, store.Save(task))
+	}
+
+	all, err := store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "api-a", "api-b", "other", "root"}, taskIDs(all), "listed in ID order")
+
+	roots, err := store.ListByParent("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other", "root"}, taskIDs(roots))
+
+	children, err := store.ListByParent("api")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api-a", "api-b"}, taskIDs(children))
+
+	completed, err := store.ListByStatus(StatusCompleted)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api-a"}, taskIDs(completed))
+
+	parentChanged := newTestTask("api-b")
+	require.NoError(t, store.Save(parentChanged))
+	children, err = store.ListByParent("api")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api-a"}, taskIDs(children), "the parent index follows updates")
+}
+
+func TestSQLiteStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "tasks.db")
+
+	store, err := NewSQLiteStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Save(newTestTask("a")))
+	require.NoError(t, store.Close())
+
+	reopened, err := NewSQLiteStore(path)
+	require.NoError(t, err)
+	defer func() { _ = reopened.Close() }()
+	got, err := reopened.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", got.ID)
+}
+
+func TestSQLiteStore_ConcurrentWrites(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			assert.NoError(t, store.Save(newTestTask(fmt.Sprintf("task-%02d", i))))
+		})
+	}
+	wg.Wait()
+
+	all, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, all, 20)
+}
+
+func TestSQLiteStore_ListManyTasks(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	tasks := make([]*Task, 0, 1000)
+	for i := range 1000 {
+		tasks = append(tasks, newTestTask(fmt.Sprintf("task-%04d", i)))
+	}
+	require.NoError(t, store.importFrom(&sliceStore{tasks: tasks}))
+
+	start := time.Now()
+	all, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, all, 1000)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+// sliceStore is a read-only Store over a fixed task list.
+type sliceStore struct {
+	Store
+	tasks []*Task
+}
+
+func (s *sliceStore) List() ([]*Task, error) {
+	return s.tasks, nil
+}
+
+func TestOpenStore(t *testing.T) {
+	t.Run("local by default", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "tasks")
+
+		for _, backend := range []string{"", BackendLocal} {
+			store, err := OpenStore(backend, dir)
+			require.NoError(t, err)
+			assert.IsType(t, &LocalStore{}, store)
+		}
+	})
+
+	t.Run("sqlite imports existing task files once", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "tasks")
+		local, err := NewLocalStore(dir)
+		require.NoError(t, err)
+		done := newTestTask("done")
+		done.Status = StatusCompleted
+		require.NoError(t, local.Save(done))
+		require.NoError(t, local.Save(newTestTask("open")))
+
+		store, err := OpenStore(BackendSQLite, dir)
+		require.NoError(t, err)
+		sqlite, ok := store.(*SQLiteStore)
+		require.True(t, ok)
+		assert.FileExists(t, dir+".db")
+
+		all, err := sqlite.List()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"done", "open"}, taskIDs(all))
+		assert.Equal(t, StatusCompleted, all[0].Status)
+		require.NoError(t, sqlite.Delete("open"))
+		require.NoError(t, sqlite.Close())
+
+		reopened, err := OpenStore(BackendSQLite, dir)
+		require.NoError(t, err)
+		all, err = reopened.List()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"done"}, taskIDs(all), "files are not imported again")
+		require.NoError(t, reopened.(*SQLiteStore).Close())
+	})
+
+	t.Run("sqlite without task files", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "tasks")
+
+		store, err := OpenStore(BackendSQLite, dir)
+		require.NoError(t, err)
+		defer func() { _ = store.(*SQLiteStore).Close() }()
+		all, err := store.List()
+		require.NoError(t, err)
+		assert.Empty(t, all)
+		_, err = os.Stat(dir)
+		assert.True(t, os.IsNotExist(err), "the tasks directory is not created")
+	})
+
+	t.Run("unknown backend", func(t *testing.T) {
+		_, err := OpenStore("postgres", t.TempDir())
+		assert.ErrorContains(t, err, `unknown tasks backend "postgres"`)
+	})
+}
+
+func TestCloseStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tasks")
+
+	local, err := OpenStore(BackendLocal, dir)
+	require.NoError(t, err)
+	require.NoError(t, CloseStore(local))
+	_, err = local.List()
+	assert.NoError(t, err, "local stores hold nothing open")
+
+	sqlite, err := OpenStore(BackendSQLite, dir)
+	require.NoError(t, err)
+	require.NoError(t, CloseStore(sqlite))
+	_, err = sqlite.List()
+	assert.Error(t, err, "the database is closed")
+}
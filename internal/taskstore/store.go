@@ -3,6 +3,8 @@ package taskstore
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
 )
 
 // Error types for TaskStore operations.
@@ -70,3 +72,66 @@ type Store interface {
 	// Returns NotFoundError if the task does not exist.
 	Delete(id string) error
 }
+
+// Task store backends, selected with the tasks.backend setting.
+const (
+	// BackendLocal stores each task as a JSON file (LocalStore).
+	BackendLocal = "local"
+	// BackendSQLite stores all tasks in one SQLite database (SQLiteStore).
+	BackendSQLite = "sqlite"
+)
+
+// OpenStore opens the task store for backend. The local backend keeps tasks
+// in dir; the sqlite backend keeps them in the database dir + ".db" (so
+// .ralph/tasks becomes .ralph/tasks.db). When that database is first
+// created, tasks already saved as files in dir are copied into it.
+func OpenStore(backend, dir string) (Store, error) {
+	switch backend {
+	case "", BackendLocal:
+		store, err := NewLocalStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case BackendSQLite:
+		return openSQLiteStore(dir)
+	default:
+		return nil, fmt.Errorf("unknown tasks backend %q (want %q or %q)", backend, BackendLocal, BackendSQLite)
+	}
+}
+
+// CloseStore releases what store holds open, such as the database of a
+// SQLiteStore. Stores that hold nothing open are left as they are.
+func CloseStore(store Store) error {
+	if c, ok := store.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// openSQLiteStore opens dir + ".db", importing the task files in dir when
+// the database is new.
+func openSQLiteStore(dir string) (*SQLiteStore, error) {
+	path := dir + ".db"
+	_, statErr := os.Stat(path)
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		return nil, err
+	}
+	if !os.IsNotExist(statErr) {
+		return store, nil
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		return store, nil
+	}
+	if err := store.importFrom(&LocalStore{dir: dir}); err != nil {
+		// Remove the new database so the import is tried again next time
+		_ = store.Close()
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			_ = os.Remove(path + suffix)
+		}
+		return nil, fmt.Errorf("failed to import task files into %s: %w", path, err)
+	}
+	return store, nil
+}
@@ -0,0 +1,127 @@
+package taskstore
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Descendants returns the tasks below rootID (not including it), breadth
+// first, so every task comes after its parent.
+func Descendants(tasks []*Task, rootID string) []*Task {
+	children := make(map[string][]*Task)
+	for _, t := range tasks {
+		if t.ParentID != nil {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		}
+	}
+
+	var descendants []*Task
+	queue := children[rootID]
+	for len(queue) > 0 {
+		task := queue[0]
+		queue = queue[1:]
+		descendants = append(descendants, task)
+		queue = append(queue, children[task.ID]...)
+	}
+	return descendants
+}
+
+// MergeSubtree replaces the descendants of rootID in tasks with subtree and
+// returns the merged task set. Every task in subtree must sit below rootID,
+// and its ID must not belong to a task outside the replaced subtree. The
+// merged set is linted as a whole, so dependencies on removed tasks and
+// cycles through the rest of the tree are reported.
+func MergeSubtree(tasks []*Task, rootID string, subtree []*Task) ([]*Task, error) {
+	replaced := make(map[string]bool)
+	for _, t := range Descendants(tasks, rootID) {
+		replaced[t.ID] = true
+	}
+
+	var merged []*Task
+	kept := make(map[string]bool)
+	for _, t := range tasks {
+		if !replaced[t.ID] {
+			merged = append(merged, t)
+			kept[t.ID] = true
+		}
+	}
+	if !kept[rootID] {
+		return nil, fmt.Errorf("task %q not found", rootID)
+	}
+
+	byID := make(map[string]*Task, len(subtree))
+	for _, t := range subtree {
+		if kept[t.ID] {
+			return nil, fmt.Errorf("task %q already exists outside %s", t.ID, rootID)
+		}
+		byID[t.ID] = t
+	}
+	for _, t := range subtree {
+		if !underRoot(t, rootID, byID) {
+			return nil, fmt.Errorf("task %q is not a descendant of %s", t.ID, rootID)
+		}
+	}
+
+	merged = append(merged, subtree...)
+	if result := LintTaskSet(merged); !result.Valid {
+		return nil, result.Error()
+	}
+	return merged, nil
+}
+
+// AppendSubtree adds tasks below parentID in tasks and returns the merged
+// task set, leaving every existing task as is. Each added task must sit below
+// parentID (under it directly, under one of its existing descendants, or
+// under another added task), and its ID must not already be taken. The merged
+// set is linted as a whole, so added tasks may depend on existing ones.
+func AppendSubtree(tasks []*Task, parentID string, added []*Task) ([]*Task, error) {
+	existing := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		existing[t.ID] = true
+	}
+	if !existing[parentID] {
+		return nil, fmt.Errorf("task %q not found", parentID)
+	}
+
+	byID := make(map[string]*Task, len(added))
+	for _, t := range Descendants(tasks, parentID) {
+		byID[t.ID] = t
+	}
+	for _, t := range added {
+		if existing[t.ID] {
+			return nil, fmt.Errorf("task %q already exists; new tasks need new IDs", t.ID)
+		}
+		if _, dup := byID[t.ID]; dup {
+			return nil, fmt.Errorf("task %q is defined more than once", t.ID)
+		}
+		byID[t.ID] = t
+	}
+	for _, t := range added {
+		if !underRoot(t, parentID, byID) {
+			return nil, fmt.Errorf("task %q is not a descendant of %s", t.ID, parentID)
+		}
+	}
+
+	merged := append(slices.Clone(tasks), added...)
+	if result := LintTaskSet(merged); !result.Valid {
+		return nil, result.Error()
+	}
+	return merged, nil
+}
+
+// underRoot reports whether following t's parents through byID reaches rootID.
+func underRoot(t *Task, rootID string, byID map[string]*Task) bool {
+	seen := map[string]bool{t.ID: true}
+	for cur := t; cur.ParentID != nil; {
+		if *cur.ParentID == rootID {
+			return true
+		}
+		parent, ok := byID[*cur.ParentID]
+		if !ok || seen[parent.ID] {
+			return false
+		}
+		seen[parent.ID] = true
+		cur = parent
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package taskstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSubtreeTask(id, parentID string, dependsOn ...string) *Task {
+	t := newTestTask(id)
+	if parentID != "" {
+		t.ParentID = &parentID
+	}
+	t.Description = "Description of " + id
+	t.Verify = [][]string{{"go", "test", "./..."}}
+	t.DependsOn = dependsOn
+	return t
+}
+
+func taskIDs(tasks []*Task) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+func subtreeFixture() []*Task {
+	return []*Task{
+		newSubtreeTask("root", ""),
+		newSubtreeTask("api", "root"),
+		newSubtreeTask("api-a", "api"),
+		newSubtreeTask("api-b", "api", "api-a"),
+		newSubtreeTask("ui", "root"),
+		newSubtreeTask("ui-a", "ui", "api-b"),
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	tasks := subtreeFixture()
+
+	assert.Equal(t, []string{"api", "ui", "api-a", "api-b", "ui-a"}, taskIDs(Descendants(tasks, "root")))
+	assert.Equal(t, []string{"api-a", "api-b"}, taskIDs(Descendants(tasks, "api")))
+	assert.Empty(t, Descendants(tasks, "ui-a"))
+}
+
+func TestMergeSubtree(t *testing.T) {
+	t.Run("replaces the subtree and keeps the rest", func(t *testing.T) {
+		merged, err := MergeSubtree(subtreeFixture(), "api", []*Task{
+			newSubtreeTask("api-b", "api"),
+			newSubtreeTask("api-c", "api-b"),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"root", "api", "ui", "ui-a", "api-b", "api-c"}, taskIDs(merged))
+	})
+
+	tests := []struct {
+		name    string
+		rootID  string
+		subtree []*Task
+		wantErr string
+	}{
+		{
+			name:    "unknown root",
+			rootID:  "missing",
+			wantErr: `task "missing" not found`,
+		},
+		{
+			name:    "ID of a task outside the subtree",
+			rootID:  "api",
+			subtree: []*Task{newSubtreeTask("ui-a", "api")},
+			wantErr: `task "ui-a" already exists outside api`,
+		},
+		{
+			name:    "task outside the subtree",
+			rootID:  "api",
+			subtree: []*Task{newSubtreeTask("api-x", "ui")},
+			wantErr: `task "api-x" is not a descendant of api`,
+		},
+		{
+			name:    "dependency of the rest of the tree removed",
+			rootID:  "api",
+			subtree: []*Task{newSubtreeTask("api-a", "api")},
+			wantErr: "api-b",
+		},
+		{
+			name:    "cycle through the rest of the tree",
+			rootID:  "api",
+			subtree: []*Task{newSubtreeTask("api-b", "api", "ui-a")},
+			wantErr: "cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MergeSubtree(subtreeFixture(), tt.rootID, tt.subtree)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestAppendSubtree(t *testing.T) {
+	t.Run("adds tasks and keeps the existing ones", func(t *testing.T) {
+		tasks := subtreeFixture()
+		tasks[2].Status = StatusCompleted
+
+		merged, err := AppendSubtree(tasks, "root", []*Task{
+			newSubtreeTask("docs", "root", "api-b"),
+			newSubtreeTask("docs-a", "docs"),
+			newSubtreeTask("api-c", "api", "api-a"),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"root", "api", "api-a", "api-b", "ui", "ui-a", "docs", "docs-a", "api-c"}, taskIDs(merged))
+		assert.Equal(t, StatusCompleted, merged[2].Status)
+		assert.Len(t, tasks, 6, "input slice is not modified")
+	})
+
+	tests := []struct {
+		name     string
+		parentID string
+		added    []*Task
+		wantErr  string
+	}{
+		{
+			name:     "unknown parent",
+			parentID: "missing",
+			wantErr:  `task "missing" not found`,
+		},
+		{
+			name:     "ID collision with an existing task",
+			parentID: "root",
+			added:    []*Task{newSubtreeTask("api-a", "api")},
+			wantErr:  `task "api-a" already exists`,
+		},
+		{
+			name:     "ID defined twice",
+			parentID: "root",
+			added:    []*Task{newSubtreeTask("docs", "root"), newSubtreeTask("docs", "root")},
+			wantErr:  `task "docs" is defined more than once`,
+		},
+		{
+			name:     "task outside the parent",
+			parentID: "api",
+			added:    []*Task{newSubtreeTask("ui-b", "ui")},
+			wantErr:  `task "ui-b" is not a descendant of api`,
+		},
+		{
+			name:     "dependency on an unknown task",
+			parentID: "root",
+			added:    []*Task{newSubtreeTask("docs", "root", "missing")},
+			wantErr:  "missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := AppendSubtree(subtreeFixture(), tt.parentID, tt.added)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
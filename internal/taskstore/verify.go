@@ -0,0 +1,38 @@
+package taskstore
+
+import (
+	"fmt"
+)
+
+// FillMissingVerify sets commands as the verify commands of every leaf task
+// that has none, and persists each updated task. Existing verify commands are
+// never overwritten. Returns the tasks that were updated.
+func FillMissingVerify(store Store, commands [][]string) ([]*Task, error) {
+	if len(commands) == 0 {
+		return nil, nil
+	}
+
+	tasks, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	var updated []*Task
+	for _, task := range tasks {
+		if len(task.Verify) > 0 || task.VerifySet != "" || !isLeafTask(tasks, task.ID) {
+			continue
+		}
+
+		task.Verify = make([][]string, len(commands))
+		for i, cmd := range commands {
+			task.Verify[i] = append([]string(nil), cmd...)
+		}
+
+		if err := store.Save(task); err != nil {
+			return updated, fmt.Errorf("failed to save task %s: %w", task.ID, err)
+		}
+		updated = append(updated, task)
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,58 @@
+package taskstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFillMissingVerify(t *testing.T) {
+	goTest := [][]string{{"go", "test", "./..."}}
+
+	t.Run("fills only leaf tasks without verify", func(t *testing.T) {
+		store, err := NewLocalStore(t.TempDir())
+		require.NoError(t, err)
+
+		parent := newTestTask("parent")
+		bare := newTestTask("bare")
+		bare.ParentID = &parent.ID
+		existing := newTestTask("existing")
+		existing.ParentID = &parent.ID
+		existing.Verify = [][]string{{"make", "check"}}
+		for _, task := range []*Task{parent, bare, existing} {
+			require.NoError(t, store.Save(task))
+		}
+
+		updated, err := FillMissingVerify(store, goTest)
+		require.NoError(t, err)
+		require.Len(t, updated, 1)
+		assert.Equal(t, "bare", updated[0].ID)
+
+		got, err := store.Get("bare")
+		require.NoError(t, err)
+		assert.Equal(t, goTest, got.Verify)
+
+		got, err = store.Get("existing")
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"make", "check"}}, got.Verify)
+
+		got, err = store.Get("parent")
+		require.NoError(t, err)
+		assert.Empty(t, got.Verify)
+	})
+
+	t.Run("does nothing without commands", func(t *testing.T) {
+		store, err := NewLocalStore(t.TempDir())
+		require.NoError(t, err)
+		require.NoError(t, store.Save(newTestTask("bare")))
+
+		updated, err := FillMissingVerify(store, nil)
+		require.NoError(t, err)
+		assert.Empty(t, updated)
+
+		got, err := store.Get("bare")
+		require.NoError(t, err)
+		assert.Empty(t, got.Verify)
+	})
+}
@@ -0,0 +1,45 @@
+package taskstore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownVerifySet indicates a task names a verify set that is not configured.
+var ErrUnknownVerifySet = errors.New("unknown verify set")
+
+// ExpandVerifySet returns a copy of the task whose Verify commands are its
+// named verify set followed by its own commands, with VerifySet cleared. The
+// set's commands get no verifyWhen glob, so they always run, and no
+// verifyExitCodes mapping. Set names match
+// case-insensitively, since config keys are lowercased. A task without a
+// verify set is returned as is.
+func (t *Task) ExpandVerifySet(sets map[string][][]string) (*Task, error) {
+	if t.VerifySet == "" {
+		return t, nil
+	}
+
+	set, ok := sets[t.VerifySet]
+	if !ok {
+		set, ok = sets[strings.ToLower(t.VerifySet)]
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w %q in task %s", ErrUnknownVerifySet, t.VerifySet, t.ID)
+	}
+
+	expanded := *t
+	expanded.VerifySet = ""
+	expanded.Verify = make([][]string, 0, len(set)+len(t.Verify))
+	for _, cmd := range set {
+		expanded.Verify = append(expanded.Verify, append([]string(nil), cmd...))
+	}
+	expanded.Verify = append(expanded.Verify, t.Verify...)
+	if len(t.VerifyWhen) > 0 {
+		expanded.VerifyWhen = append(make([]string, len(set)), t.VerifyWhen...)
+	}
+	if len(t.VerifyExitCodes) > 0 {
+		expanded.VerifyExitCodes = append(make([]map[string]string, len(set)), t.VerifyExitCodes...)
+	}
+	return &expanded, nil
+}
@@ -0,0 +1,85 @@
+package taskstore
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTask_ExpandVerifySet(t *testing.T) {
+	sets := map[string][][]string{
+		"go": {{"go", "test", "./..."}, {"go", "vet", "./..."}},
+	}
+
+	tests := []struct {
+		name       string
+		task       *Task
+		wantVerify [][]string
+		wantWhen   []string
+		wantCodes  []map[string]string
+	}{
+		{
+			name:       "no set keeps the task's commands",
+			task:       &Task{ID: "a", Verify: [][]string{{"make", "lint"}}},
+			wantVerify: [][]string{{"make", "lint"}},
+		},
+		{
+			name:       "set commands run before the task's own",
+			task:       &Task{ID: "a", VerifySet: "go", Verify: [][]string{{"make", "lint"}}},
+			wantVerify: [][]string{{"go", "test", "./..."}, {"go", "vet", "./..."}, {"make", "lint"}},
+		},
+		{
+			name:       "verifyWhen globs stay with the task's commands",
+			task:       &Task{ID: "a", VerifySet: "go", Verify: [][]string{{"npm", "test"}}, VerifyWhen: []string{"web/**"}},
+			wantVerify: [][]string{{"go", "test", "./..."}, {"go", "vet", "./..."}, {"npm", "test"}},
+			wantWhen:   []string{"", "", "web/**"},
+		},
+		{
+			name:       "verifyExitCodes stay with the task's commands",
+			task:       &Task{ID: "a", VerifySet: "go", Verify: [][]string{{"golangci-lint", "run"}}, VerifyExitCodes: []map[string]string{{"2": "warn"}}},
+			wantVerify: [][]string{{"go", "test", "./..."}, {"go", "vet", "./..."}, {"golangci-lint", "run"}},
+			wantCodes:  []map[string]string{nil, nil, {"2": "warn"}},
+		},
+		{
+			name:       "set names match case-insensitively",
+			task:       &Task{ID: "a", VerifySet: "Go"},
+			wantVerify: [][]string{{"go", "test", "./..."}, {"go", "vet", "./..."}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, err := tt.task.ExpandVerifySet(sets)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantVerify, expanded.Verify)
+			assert.Equal(t, tt.wantWhen, expanded.VerifyWhen)
+			assert.Equal(t, tt.wantCodes, expanded.VerifyExitCodes)
+			assert.Empty(t, expanded.VerifySet)
+
+			again, err := expanded.ExpandVerifySet(sets)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVerify, again.Verify, "expanding twice must not repeat the set")
+		})
+	}
+
+	t.Run("unknown set", func(t *testing.T) {
+		_, err := (&Task{ID: "a", VerifySet: "rust"}).ExpandVerifySet(sets)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrUnknownVerifySet))
+		assert.Contains(t, err.Error(), `unknown verify set "rust" in task a`)
+	})
+
+	t.Run("does not modify the task or the set", func(t *testing.T) {
+		task := &Task{ID: "a", VerifySet: "go", Verify: [][]string{{"make"}}}
+		expanded, err := task.ExpandVerifySet(sets)
+		require.NoError(t, err)
+		expanded.Verify[0][0] = "changed"
+
+		assert.Equal(t, "go", task.VerifySet)
+		assert.Equal(t, [][]string{{"make"}}, task.Verify)
+		assert.Equal(t, "go", sets["go"][0][0])
+	})
+}
@@ -8,23 +8,29 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// YAMLTask represents a task as defined in a YAML file.
+// YAMLTask represents a task as defined in a YAML (or JSON) task file.
 // Field names match the YAML structure (e.g., parentId instead of parent_id).
 type YAMLTask struct {
-	ID          string            `yaml:"id"`
-	Title       string            `yaml:"title"`
-	Description string            `yaml:"description,omitempty"`
-	ParentID    string            `yaml:"parentId,omitempty"`
-	DependsOn   []string          `yaml:"dependsOn,omitempty"`
-	Status      string            `yaml:"status,omitempty"`
-	Acceptance  []string          `yaml:"acceptance,omitempty"`
-	Verify      [][]string        `yaml:"verify,omitempty"`
-	Labels      map[string]string `yaml:"labels,omitempty"`
+	ID          string            `yaml:"id" json:"id"`
+	Title       string            `yaml:"title" json:"title"`
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	ParentID    string            `yaml:"parentId,omitempty" json:"parentId,omitempty"`
+	DependsOn   []string          `yaml:"dependsOn,omitempty" json:"dependsOn,omitempty"`
+	Status      string            `yaml:"status,omitempty" json:"status,omitempty"`
+	Acceptance  []string          `yaml:"acceptance,omitempty" json:"acceptance,omitempty"`
+	Verify      [][]string        `yaml:"verify,omitempty" json:"verify,omitempty"`
+	VerifySet   string            `yaml:"verifySet,omitempty" json:"verifySet,omitempty"`
+	VerifyWhen  []string          `yaml:"verifyWhen,omitempty" json:"verifyWhen,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	VerifyExitCodes []map[string]string `yaml:"verifyExitCodes,omitempty" json:"verifyExitCodes,omitempty"`
+	ContextFiles    []string            `yaml:"contextFiles,omitempty" json:"contextFiles,omitempty"`
+	AllowedPaths    []string            `yaml:"allowedPaths,omitempty" json:"allowedPaths,omitempty"`
 }
 
 // YAMLFile represents the structure of a tasks YAML file.
 type YAMLFile struct {
-	Tasks []YAMLTask `yaml:"tasks"`
+	Tasks []YAMLTask `yaml:"tasks" json:"tasks"`
 }
 
 // ParseYAML parses YAML content into a YAMLFile structure.
@@ -47,6 +53,8 @@ type ImportError struct {
 type ImportResult struct {
 	Imported int
 	Errors   []ImportError
+	IDs      []string // Every task ID the file lists, whether imported or not
+	Removed  []string // Tasks deleted because the file no longer lists them
 }
 
 // ImportFromYAML reads tasks from a YAML file and imports them into the store.
@@ -63,9 +71,18 @@ func ImportFromYAML(store Store, path string) (*ImportResult, error) {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
+	return importYAMLTasks(store, yamlFile.Tasks, false), nil
+}
+
+// importYAMLTasks saves tasks to the store, skipping and reporting those
+// that fail validation. With keepProgress, tasks already in the store keep
+// their status and creation time.
+func importYAMLTasks(store Store, tasks []YAMLTask, keepProgress bool) *ImportResult {
 	result := &ImportResult{}
 
-	for _, yt := range yamlFile.Tasks {
+	for _, yt := range tasks {
+		result.IDs = append(result.IDs, yt.ID)
+
 		task, err := convertYAMLTask(yt)
 		if err != nil {
 			result.Errors = append(result.Errors, ImportError{
@@ -75,6 +92,13 @@ func ImportFromYAML(store Store, path string) (*ImportResult, error) {
 			continue
 		}
 
+		if keepProgress {
+			if existing, err := store.Get(task.ID); err == nil {
+				task.Status = existing.Status
+				task.CreatedAt = existing.CreatedAt
+			}
+		}
+
 		if err := store.Save(task); err != nil {
 			result.Errors = append(result.Errors, ImportError{
 				ID:     task.ID,
@@ -86,7 +110,7 @@ func ImportFromYAML(store Store, path string) (*ImportResult, error) {
 		result.Imported++
 	}
 
-	return result, nil
+	return result
 }
 
 // convertYAMLTask converts a YAMLTask to a Task, applying defaults and validation.
@@ -100,9 +124,16 @@ func convertYAMLTask(yt YAMLTask) (*Task, error) {
 		DependsOn:   yt.DependsOn,
 		Acceptance:  yt.Acceptance,
 		Verify:      yt.Verify,
+		VerifySet:   yt.VerifySet,
+		VerifyWhen:  yt.VerifyWhen,
 		Labels:      yt.Labels,
+		Priority:    PriorityFromLabel(yt.Labels["priority"]),
 		CreatedAt:   now,
 		UpdatedAt:   now,
+
+		VerifyExitCodes: yt.VerifyExitCodes,
+		ContextFiles:    yt.ContextFiles,
+		AllowedPaths:    yt.AllowedPaths,
 	}
 
 	// Handle optional ParentID
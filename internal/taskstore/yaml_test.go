@@ -25,8 +25,18 @@ func TestImportFromYAML_ValidTasks(t *testing.T) {
       - "Criterion 1"
     verify:
       - ["go", "test", "./..."]
+    verifySet: go
+    verifyWhen:
+      - "*.go"
+    verifyExitCodes:
+      - {2: warn, 3: skip}
     labels:
       area: core
+      priority: p0
+    contextFiles:
+      - internal/selector/graph.go
+    allowedPaths:
+      - internal/selector/**
   - id: task-2
     title: "Second Task"
     parentId: task-1
@@ -60,7 +70,13 @@ func TestImportFromYAML_ValidTasks(t *testing.T) {
 	assert.Empty(t, task1.DependsOn)
 	assert.Equal(t, []string{"Criterion 1"}, task1.Acceptance)
 	assert.Equal(t, [][]string{{"go", "test", "./..."}}, task1.Verify)
+	assert.Equal(t, "go", task1.VerifySet)
+	assert.Equal(t, []string{"*.go"}, task1.VerifyWhen)
+	assert.Equal(t, []map[string]string{{"2": "warn", "3": "skip"}}, task1.VerifyExitCodes)
 	assert.Equal(t, "core", task1.Labels["area"])
+	assert.Equal(t, PriorityP0, task1.Priority)
+	assert.Equal(t, []string{"internal/selector/graph.go"}, task1.ContextFiles)
+	assert.Equal(t, []string{"internal/selector/**"}, task1.AllowedPaths)
 
 	// Check task-2
 	task2, err := store.Get("task-2")
@@ -0,0 +1,188 @@
+package taskstore
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// YAMLSource is a parsed tasks YAML file to merge. Name identifies it in
+// errors and is the source of its prefix for renamed IDs.
+type YAMLSource struct {
+	Name string
+	File *YAMLFile
+}
+
+// MergeYAMLOptions configures MergeYAML.
+type MergeYAMLOptions struct {
+	// Prefix renames a task whose ID is already taken by an earlier file to
+	// "<file>-<id>", where <file> is the file's base name without extension,
+	// and rewrites the references to it in its own file. Without Prefix an ID
+	// collision is an error.
+	Prefix bool
+
+	// RootTitle, if set, adds a root task with this title (and an ID derived
+	// from it) as the parent of every file's top-level tasks.
+	RootTitle string
+}
+
+// IDCollisionError reports a task ID defined by more than one merged file.
+type IDCollisionError struct {
+	ID     string
+	Source string
+	Other  string
+}
+
+func (e *IDCollisionError) Error() string {
+	return fmt.Sprintf("task %s in %s is also defined in %s", e.ID, e.Source, e.Other)
+}
+
+// YAMLRename is a task ID changed by MergeYAML to avoid a collision.
+type YAMLRename struct {
+	Source string
+	OldID  string
+	NewID  string
+}
+
+// MergeYAMLResult is the outcome of MergeYAML.
+type MergeYAMLResult struct {
+	// File holds the merged tasks: the root task (if any) followed by each
+	// source's tasks in order.
+	File *YAMLFile
+
+	// Renames lists the IDs changed to avoid collisions.
+	Renames []YAMLRename
+
+	// Warnings are the non-fatal lint findings of the merged set.
+	Warnings []LintWarning
+}
+
+// MergeYAML combines separately authored task files into one. IDs must be
+// unique across the files unless opts.Prefix is set; a collision is an
+// IDCollisionError. The merged set is linted as a whole, so dependencies on
+// tasks that exist in no file, cycles across files and orphaned parents are
+// reported as errors.
+func MergeYAML(sources []YAMLSource, opts MergeYAMLOptions) (*MergeYAMLResult, error) {
+	result := &MergeYAMLResult{File: &YAMLFile{}}
+	definedIn := make(map[string]string) // task ID -> source name
+
+	for _, src := range sources {
+		own := make(map[string]bool, len(src.File.Tasks))
+		for _, yt := range src.File.Tasks {
+			if own[yt.ID] {
+				return nil, fmt.Errorf("task %s is defined twice in %s", yt.ID, src.Name)
+			}
+			own[yt.ID] = true
+		}
+
+		renames := make(map[string]string)
+		for _, yt := range src.File.Tasks {
+			other, taken := definedIn[yt.ID]
+			if !taken {
+				continue
+			}
+			if !opts.Prefix {
+				return nil, &IDCollisionError{ID: yt.ID, Source: src.Name, Other: other}
+			}
+			newID := sourcePrefix(src.Name) + "-" + yt.ID
+			if _, clash := definedIn[newID]; clash || own[newID] {
+				return nil, fmt.Errorf("cannot rename task %s in %s: %s is also taken", yt.ID, src.Name, newID)
+			}
+			renames[yt.ID] = newID
+			result.Renames = append(result.Renames, YAMLRename{Source: src.Name, OldID: yt.ID, NewID: newID})
+		}
+
+		for _, yt := range src.File.Tasks {
+			merged := yt
+			merged.ID = renamedID(renames, yt.ID)
+			merged.ParentID = renamedID(renames, yt.ParentID)
+			merged.DependsOn = make([]string, 0, len(yt.DependsOn))
+			for _, dep := range yt.DependsOn {
+				merged.DependsOn = append(merged.DependsOn, renamedID(renames, dep))
+			}
+			if len(merged.DependsOn) == 0 {
+				merged.DependsOn = nil
+			}
+			definedIn[merged.ID] = src.Name
+			result.File.Tasks = append(result.File.Tasks, merged)
+		}
+	}
+
+	if opts.RootTitle != "" {
+		root, err := mergeRoot(sources, opts.RootTitle, definedIn)
+		if err != nil {
+			return nil, err
+		}
+		for i := range result.File.Tasks {
+			if result.File.Tasks[i].ParentID == "" {
+				result.File.Tasks[i].ParentID = root.ID
+			}
+		}
+		result.File.Tasks = slices.Insert(result.File.Tasks, 0, root)
+	}
+
+	tasks := make([]*Task, 0, len(result.File.Tasks))
+	for _, yt := range result.File.Tasks {
+		task, err := convertYAMLTask(yt)
+		if err != nil {
+			return nil, fmt.Errorf("task %s is invalid: %w", yt.ID, err)
+		}
+		tasks = append(tasks, task)
+	}
+	lint := LintTaskSet(tasks)
+	if err := lint.Error(); err != nil {
+		return nil, fmt.Errorf("merged tasks are invalid: %w", err)
+	}
+	result.Warnings = lint.Warnings
+
+	return result, nil
+}
+
+// mergeRoot builds the shared root task of a merge.
+func mergeRoot(sources []YAMLSource, title string, definedIn map[string]string) (YAMLTask, error) {
+	id := IDFromTitle(title)
+	if id == "" {
+		id = "root"
+	}
+	if other, taken := definedIn[id]; taken {
+		return YAMLTask{}, fmt.Errorf("root task ID %s is already defined in %s", id, other)
+	}
+
+	names := make([]string, 0, len(sources))
+	for _, src := range sources {
+		names = append(names, filepath.Base(src.Name))
+	}
+	return YAMLTask{
+		ID:          id,
+		Title:       title,
+		Description: fmt.Sprintf("Combined tasks from %s.", strings.Join(names, ", ")),
+	}, nil
+}
+
+// renamedID returns id's new ID if it was renamed, or id itself.
+func renamedID(renames map[string]string, id string) string {
+	if newID, ok := renames[id]; ok {
+		return newID
+	}
+	return id
+}
+
+// sourcePrefix is the ID prefix for tasks renamed in the file at name.
+func sourcePrefix(name string) string {
+	base := filepath.Base(name)
+	if prefix := IDFromTitle(strings.TrimSuffix(base, filepath.Ext(base))); prefix != "" {
+		return prefix
+	}
+	return "merged"
+}
+
+// IDFromTitle turns a title into a lowercase, hyphen-separated task ID
+// ("Billing Service" becomes "billing-service"). It returns "" when the
+// title has no letters or digits.
+func IDFromTitle(title string) string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	return strings.Join(fields, "-")
+}
@@ -0,0 +1,102 @@
+package taskstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func yamlTask(id, parentID string, dependsOn ...string) YAMLTask {
+	return YAMLTask{
+		ID:          id,
+		Title:       id,
+		Description: "Implement " + id,
+		ParentID:    parentID,
+		DependsOn:   dependsOn,
+		Acceptance:  []string{"works"},
+		Verify:      [][]string{{"go", "test", "./..."}},
+	}
+}
+
+func TestMergeYAML(t *testing.T) {
+	api := YAMLSource{Name: "specs/api.yaml", File: &YAMLFile{Tasks: []YAMLTask{
+		yamlTask("api", ""),
+		yamlTask("setup", "api"),
+		yamlTask("endpoints", "api", "setup"),
+	}}}
+	web := YAMLSource{Name: "specs/web.yaml", File: &YAMLFile{Tasks: []YAMLTask{
+		yamlTask("web", ""),
+		yamlTask("setup", "web"),
+		yamlTask("pages", "web", "setup", "endpoints"),
+	}}}
+
+	t.Run("combines files with unique IDs", func(t *testing.T) {
+		other := YAMLSource{Name: "web.yaml", File: &YAMLFile{Tasks: []YAMLTask{yamlTask("web", "", "endpoints")}}}
+
+		result, err := MergeYAML([]YAMLSource{api, other}, MergeYAMLOptions{})
+		require.NoError(t, err)
+
+		assert.Len(t, result.File.Tasks, 4)
+		assert.Empty(t, result.Renames)
+	})
+
+	t.Run("rejects colliding IDs", func(t *testing.T) {
+		_, err := MergeYAML([]YAMLSource{api, web}, MergeYAMLOptions{})
+
+		var collision *IDCollisionError
+		require.ErrorAs(t, err, &collision)
+		assert.Equal(t, "setup", collision.ID)
+		assert.Equal(t, "specs/api.yaml", collision.Other)
+	})
+
+	t.Run("prefixes colliding IDs and their references", func(t *testing.T) {
+		result, err := MergeYAML([]YAMLSource{api, web}, MergeYAMLOptions{Prefix: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, []YAMLRename{{Source: "specs/web.yaml", OldID: "setup", NewID: "web-setup"}}, result.Renames)
+		pages := result.File.Tasks[5]
+		assert.Equal(t, "pages", pages.ID)
+		assert.Equal(t, []string{"web-setup", "endpoints"}, pages.DependsOn)
+		assert.Equal(t, "web", result.File.Tasks[4].ParentID)
+		assert.Equal(t, []string{"setup", "endpoints"}, web.File.Tasks[2].DependsOn, "sources are not modified")
+	})
+
+	t.Run("adds a shared root", func(t *testing.T) {
+		result, err := MergeYAML([]YAMLSource{api, web}, MergeYAMLOptions{Prefix: true, RootTitle: "Launch v2"})
+		require.NoError(t, err)
+
+		root := result.File.Tasks[0]
+		assert.Equal(t, "launch-v2", root.ID)
+		assert.Equal(t, "Combined tasks from api.yaml, web.yaml.", root.Description)
+		assert.Equal(t, "launch-v2", result.File.Tasks[1].ParentID)
+		assert.Equal(t, "launch-v2", result.File.Tasks[4].ParentID)
+		assert.Equal(t, "api", result.File.Tasks[2].ParentID)
+	})
+
+	t.Run("lints the merged set", func(t *testing.T) {
+		cyclic := YAMLSource{Name: "web.yaml", File: &YAMLFile{Tasks: []YAMLTask{
+			yamlTask("web", ""),
+			yamlTask("pages", "web", "setup", "missing"),
+		}}}
+
+		_, err := MergeYAML([]YAMLSource{api, cyclic}, MergeYAMLOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "merged tasks are invalid")
+		assert.Contains(t, err.Error(), "missing")
+	})
+
+	t.Run("rejects duplicate IDs within a file", func(t *testing.T) {
+		dup := YAMLSource{Name: "dup.yaml", File: &YAMLFile{Tasks: []YAMLTask{yamlTask("a", ""), yamlTask("a", "")}}}
+
+		_, err := MergeYAML([]YAMLSource{dup}, MergeYAMLOptions{Prefix: true})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "task a is defined twice in dup.yaml")
+	})
+}
+
+func TestIDFromTitle(t *testing.T) {
+	assert.Equal(t, "launch-v2", IDFromTitle("Launch v2!"))
+	assert.Equal(t, "billing-service", IDFromTitle("  Billing  Service "))
+	assert.Equal(t, "", IDFromTitle("--"))
+}
@@ -0,0 +1,80 @@
+package verifier
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// ExitCodeResult is how a verify command's exit code is interpreted.
+type ExitCodeResult string
+
+const (
+	// ExitCodePass treats the exit code as a pass.
+	ExitCodePass ExitCodeResult = "pass"
+	// ExitCodeWarn treats the exit code as a pass with a warning.
+	ExitCodeWarn ExitCodeResult = "warn"
+	// ExitCodeSkip treats the exit code as the command having skipped its checks.
+	ExitCodeSkip ExitCodeResult = "skip"
+	// ExitCodeFail treats the exit code as a failure (the default for nonzero codes).
+	ExitCodeFail ExitCodeResult = "fail"
+)
+
+// ExitCodes maps a verify command, by its full argv, to how its nonzero exit
+// codes are interpreted, so `go vet` and `go test` can be mapped differently.
+// Codes that are not listed fail.
+type ExitCodes map[string]map[int]ExitCodeResult
+
+// exitCodesKey identifies command in ExitCodes.
+func exitCodesKey(command []string) string {
+	return strings.Join(command, "\x00")
+}
+
+// Set maps the exit codes of command, replacing any earlier mapping for it.
+func (c ExitCodes) Set(command []string, codes map[int]ExitCodeResult) {
+	c[exitCodesKey(command)] = codes
+}
+
+// ParseExitCodeMapping converts a configured mapping of exit codes to
+// results, such as {"2": "warn", "3": "skip"}, for ExitCodes.Set.
+func ParseExitCodeMapping(raw map[string]string) (map[int]ExitCodeResult, error) {
+	parsed := make(map[int]ExitCodeResult, len(raw))
+	for code, result := range raw {
+		n, err := strconv.Atoi(strings.TrimSpace(code))
+		if err != nil || n <= 0 || n > 255 {
+			return nil, fmt.Errorf("exit code %q must be a number from 1 to 255", code)
+		}
+		switch r := ExitCodeResult(strings.ToLower(strings.TrimSpace(result))); r {
+		case ExitCodePass, ExitCodeWarn, ExitCodeSkip, ExitCodeFail:
+			parsed[n] = r
+		default:
+			return nil, fmt.Errorf("exit code %d has unknown result %q (want pass, warn, skip or fail)", n, result)
+		}
+	}
+	return parsed, nil
+}
+
+// lookup returns how command's exit code is interpreted, and whether it is mapped.
+func (c ExitCodes) lookup(command []string, code int) (ExitCodeResult, bool) {
+	result, ok := c[exitCodesKey(command)][code]
+	return result, ok
+}
+
+// SetExitCodes sets how nonzero exit codes of specific commands are
+// interpreted. A command exiting with a code mapped to warn or skip passes,
+// with Warning or Skipped set on its result. Nil keeps every nonzero exit a failure.
+func (r *CommandRunner) SetExitCodes(codes ExitCodes) {
+	r.exitCodes = codes
+}
+
+// WithExitCodes returns a copy of the runner that also applies codes, such as
+// a task's own mappings. They take precedence over the runner's mappings for
+// the same command.
+func (r *CommandRunner) WithExitCodes(codes ExitCodes) Verifier {
+	clone := *r
+	clone.exitCodes = make(ExitCodes, len(r.exitCodes)+len(codes))
+	maps.Copy(clone.exitCodes, r.exitCodes)
+	maps.Copy(clone.exitCodes, codes)
+	return &clone
+}
@@ -0,0 +1,121 @@
+package verifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExitCodeMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     map[string]string
+		want    map[int]ExitCodeResult
+		wantErr string
+	}{
+		{
+			name: "parses codes and results",
+			raw:  map[string]string{"2": "warn", "3": "SKIP", "4": "pass", "5": "fail"},
+			want: map[int]ExitCodeResult{2: ExitCodeWarn, 3: ExitCodeSkip, 4: ExitCodePass, 5: ExitCodeFail},
+		},
+		{
+			name: "empty",
+			raw:  nil,
+			want: map[int]ExitCodeResult{},
+		},
+		{
+			name:    "rejects a non-numeric code",
+			raw:     map[string]string{"two": "warn"},
+			wantErr: `exit code "two" must be a number from 1 to 255`,
+		},
+		{
+			name:    "rejects exit code zero",
+			raw:     map[string]string{"0": "fail"},
+			wantErr: `exit code "0" must be a number from 1 to 255`,
+		},
+		{
+			name:    "rejects an unknown result",
+			raw:     map[string]string{"2": "maybe"},
+			wantErr: `exit code 2 has unknown result "maybe"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExitCodeMapping(tt.raw)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCommandRunner_ExitCodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     []string
+		wantPassed  bool
+		wantWarning bool
+		wantSkipped bool
+	}{
+		{name: "mapped to warn", command: []string{"sh", "-c", "exit 2"}, wantPassed: true, wantWarning: true},
+		{name: "mapped to skip", command: []string{"sh", "-c", "exit 3"}, wantPassed: true, wantSkipped: true},
+		{name: "mapped to pass", command: []string{"sh", "-c", "exit 4"}, wantPassed: true},
+		{name: "unmapped code fails", command: []string{"sh", "-c", "exit 1"}},
+		{name: "mapped to fail", command: []string{"sh", "-c", "exit 5"}},
+		{name: "zero passes", command: []string{"sh", "-c", "exit 0"}, wantPassed: true},
+		{name: "other commands of the same executable are unaffected", command: []string{"sh", "-c", "exit 2 # other"}},
+	}
+
+	runner := NewCommandRunner(t.TempDir())
+	codes := ExitCodes{}
+	for _, code := range []string{"2", "3", "4", "5", "1", "0"} {
+		codes.Set([]string{"sh", "-c", "exit " + code}, map[int]ExitCodeResult{2: ExitCodeWarn, 3: ExitCodeSkip, 4: ExitCodePass, 5: ExitCodeFail})
+	}
+	runner.SetExitCodes(codes)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := runner.Verify(context.Background(), [][]string{tt.command})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+
+			assert.Equal(t, tt.wantPassed, results[0].Passed)
+			assert.Equal(t, tt.wantWarning, results[0].Warning)
+			assert.Equal(t, tt.wantSkipped, results[0].Skipped)
+			assert.Empty(t, results[0].Error)
+		})
+	}
+}
+
+func TestCommandRunner_WithExitCodes(t *testing.T) {
+	vet := []string{"sh", "-c", "exit 2 # vet"}
+	test := []string{"sh", "-c", "exit 2 # test"}
+
+	runner := NewCommandRunner(t.TempDir())
+	global := ExitCodes{}
+	global.Set(vet, map[int]ExitCodeResult{2: ExitCodeSkip})
+	global.Set(test, map[int]ExitCodeResult{2: ExitCodeSkip})
+	runner.SetExitCodes(global)
+
+	task := ExitCodes{}
+	task.Set(test, map[int]ExitCodeResult{2: ExitCodeWarn})
+	withTask := runner.WithExitCodes(task)
+
+	results, err := withTask.Verify(context.Background(), [][]string{vet, test})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Skipped, "the runner's mapping still applies")
+	assert.True(t, results[1].Warning, "the added mapping takes precedence")
+
+	results, err = runner.Verify(context.Background(), [][]string{test})
+	require.NoError(t, err)
+	assert.True(t, results[0].Skipped, "the original runner is unchanged")
+	assert.NotEqual(t, runner.Fingerprint(), withTask.(*CommandRunner).Fingerprint())
+}
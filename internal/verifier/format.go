@@ -0,0 +1,212 @@
+package verifier
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// OutputFormat identifies how verification command output is structured.
+type OutputFormat string
+
+const (
+	// FormatRaw is unstructured command output.
+	FormatRaw OutputFormat = "raw"
+	// FormatGoTestJSON is the event stream produced by `go test -json`.
+	FormatGoTestJSON OutputFormat = "gotest-json"
+	// FormatJUnit is a JUnit XML report.
+	FormatJUnit OutputFormat = "junit"
+)
+
+// TestStatus is the outcome of a single test case.
+type TestStatus string
+
+const (
+	// TestPassed indicates the test passed.
+	TestPassed TestStatus = "pass"
+	// TestFailed indicates the test failed.
+	TestFailed TestStatus = "fail"
+	// TestSkipped indicates the test was skipped.
+	TestSkipped TestStatus = "skip"
+)
+
+// TestCase is a single test result parsed from structured verification output.
+type TestCase struct {
+	// Package is the Go package or JUnit suite containing the test.
+	Package string `json:"package,omitempty"`
+
+	// Name is the test name.
+	Name string `json:"name"`
+
+	// Status is the test outcome.
+	Status TestStatus `json:"status"`
+
+	// Output is the test's own output (failures only, to keep records small).
+	Output string `json:"output,omitempty"`
+}
+
+// DetectFormat infers the output format from the command and its output.
+// It returns FormatRaw when no structured format is recognized.
+func DetectFormat(command []string, output string) OutputFormat {
+	if len(command) >= 2 && command[0] == "go" && command[1] == "test" && slices.Contains(command, "-json") {
+		return FormatGoTestJSON
+	}
+
+	trimmed := strings.TrimSpace(output)
+	if strings.HasPrefix(trimmed, "<?xml") || strings.HasPrefix(trimmed, "<testsuite") {
+		return FormatJUnit
+	}
+
+	return FormatRaw
+}
+
+// ParseTestCases parses test-level results from output in the given format.
+// Returns nil, nil for FormatRaw.
+func ParseTestCases(format OutputFormat, output string) ([]TestCase, error) {
+	switch format {
+	case FormatGoTestJSON:
+		return parseGoTestJSON(output)
+	case FormatJUnit:
+		return parseJUnit(output)
+	default:
+		return nil, nil
+	}
+}
+
+// goTestEvent is a single event from `go test -json` (see `go doc test2json`).
+type goTestEvent struct {
+	Action  string `json:"Action"`
+	Package string `json:"Package"`
+	Test    string `json:"Test"`
+	Output  string `json:"Output"`
+}
+
+// parseGoTestJSON parses a `go test -json` event stream.
+// Non-JSON lines (e.g. build errors) are ignored.
+func parseGoTestJSON(output string) ([]TestCase, error) {
+	var cases []TestCase
+	outputs := make(map[string]*strings.Builder)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var event goTestEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			continue
+		}
+		if event.Test == "" {
+			continue
+		}
+
+		key := event.Package + "." + event.Test
+		switch event.Action {
+		case "output":
+			if outputs[key] == nil {
+				outputs[key] = &strings.Builder{}
+			}
+			outputs[key].WriteString(event.Output)
+		case "pass", "fail", "skip":
+			tc := TestCase{
+				Package: event.Package,
+				Name:    event.Test,
+				Status:  TestStatus(event.Action),
+			}
+			if tc.Status == TestFailed && outputs[key] != nil {
+				tc.Output = outputs[key].String()
+			}
+			delete(outputs, key)
+			cases = append(cases, tc)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read go test output: %w", err)
+	}
+
+	return cases, nil
+}
+
+// junitTestSuites is the root of a JUnit report with multiple suites.
+type junitTestSuites struct {
+	Suites []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite is a single JUnit test suite.
+type junitTestSuite struct {
+	Name  string          `xml:"name,attr"`
+	Cases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single JUnit test case.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Error     *junitMessage `xml:"error"`
+	Skipped   *junitMessage `xml:"skipped"`
+}
+
+// junitMessage is a failure, error, or skipped element.
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// parseJUnit parses a JUnit XML report with either <testsuites> or <testsuite> as root.
+func parseJUnit(output string) ([]TestCase, error) {
+	data := []byte(strings.TrimSpace(output))
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		return nil, fmt.Errorf("failed to parse JUnit XML: %w", err)
+	}
+
+	// A bare <testsuite> root decodes into no suites
+	if len(suites.Suites) == 0 {
+		var suite junitTestSuite
+		if err := xml.Unmarshal(data, &suite); err != nil {
+			return nil, fmt.Errorf("failed to parse JUnit XML: %w", err)
+		}
+		suites.Suites = []junitTestSuite{suite}
+	}
+
+	var cases []TestCase
+	for _, suite := range suites.Suites {
+		for _, c := range suite.Cases {
+			pkg := c.ClassName
+			if pkg == "" {
+				pkg = suite.Name
+			}
+
+			tc := TestCase{Package: pkg, Name: c.Name, Status: TestPassed}
+			switch {
+			case c.Failure != nil:
+				tc.Status = TestFailed
+				tc.Output = junitMessageText(c.Failure)
+			case c.Error != nil:
+				tc.Status = TestFailed
+				tc.Output = junitMessageText(c.Error)
+			case c.Skipped != nil:
+				tc.Status = TestSkipped
+			}
+			cases = append(cases, tc)
+		}
+	}
+
+	return cases, nil
+}
+
+// junitMessageText returns the body of a JUnit message, falling back to its message attribute.
+func junitMessageText(m *junitMessage) string {
+	if body := strings.TrimSpace(m.Body); body != "" {
+		return body
+	}
+	return m.Message
+}
@@ -0,0 +1,127 @@
+package verifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		output  string
+		want    OutputFormat
+	}{
+		{
+			name:    "go test with -json",
+			command: []string{"go", "test", "-json", "./..."},
+			want:    FormatGoTestJSON,
+		},
+		{
+			name:    "go test without -json",
+			command: []string{"go", "test", "./..."},
+			output:  "ok  \tpkg\t0.01s",
+			want:    FormatRaw,
+		},
+		{
+			name:    "junit with xml declaration",
+			command: []string{"npm", "test"},
+			output:  "\n<?xml version=\"1.0\"?>\n<testsuites></testsuites>",
+			want:    FormatJUnit,
+		},
+		{
+			name:    "junit bare testsuite",
+			command: []string{"pytest", "--junitxml=/dev/stdout"},
+			output:  "<testsuite name=\"s\"></testsuite>",
+			want:    FormatJUnit,
+		},
+		{
+			name:    "plain output",
+			command: []string{"make", "lint"},
+			output:  "all good",
+			want:    FormatRaw,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectFormat(tt.command, tt.output))
+		})
+	}
+}
+
+func TestParseTestCases_GoTestJSON(t *testing.T) {
+	output := `{"Action":"run","Package":"example.com/pkg","Test":"TestA"}
+{"Action":"output","Package":"example.com/pkg","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"pass","Package":"example.com/pkg","Test":"TestA"}
+{"Action":"run","Package":"example.com/pkg","Test":"TestB"}
+{"Action":"output","Package":"example.com/pkg","Test":"TestB","Output":"    b_test.go:10: boom\n"}
+{"Action":"fail","Package":"example.com/pkg","Test":"TestB"}
+{"Action":"skip","Package":"example.com/pkg","Test":"TestC"}
+# example.com/other
+other.go:1:1: syntax error
+{"Action":"fail","Package":"example.com/pkg"}
+`
+
+	cases, err := ParseTestCases(FormatGoTestJSON, output)
+	require.NoError(t, err)
+	require.Len(t, cases, 3)
+
+	assert.Equal(t, TestCase{Package: "example.com/pkg", Name: "TestA", Status: TestPassed}, cases[0])
+	assert.Equal(t, TestFailed, cases[1].Status)
+	assert.Equal(t, "TestB", cases[1].Name)
+	assert.Contains(t, cases[1].Output, "boom")
+	assert.Equal(t, TestSkipped, cases[2].Status)
+}
+
+func TestParseTestCases_JUnit(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{
+			name: "testsuites root",
+			output: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="suite">
+    <testcase name="passes" classname="pkg.Mod"/>
+    <testcase name="fails" classname="pkg.Mod"><failure message="expected 1">assert failed</failure></testcase>
+    <testcase name="skips" classname="pkg.Mod"><skipped/></testcase>
+  </testsuite>
+</testsuites>`,
+		},
+		{
+			name: "testsuite root",
+			output: `<testsuite name="suite">
+  <testcase name="passes" classname="pkg.Mod"/>
+  <testcase name="fails" classname="pkg.Mod"><failure message="expected 1">assert failed</failure></testcase>
+  <testcase name="skips" classname="pkg.Mod"><skipped/></testcase>
+</testsuite>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cases, err := ParseTestCases(FormatJUnit, tt.output)
+			require.NoError(t, err)
+			require.Len(t, cases, 3)
+
+			assert.Equal(t, TestCase{Package: "pkg.Mod", Name: "passes", Status: TestPassed}, cases[0])
+			assert.Equal(t, TestCase{Package: "pkg.Mod", Name: "fails", Status: TestFailed, Output: "assert failed"}, cases[1])
+			assert.Equal(t, TestSkipped, cases[2].Status)
+		})
+	}
+}
+
+func TestParseTestCases_InvalidJUnit(t *testing.T) {
+	_, err := ParseTestCases(FormatJUnit, "<testsuite><testcase")
+	assert.Error(t, err)
+}
+
+func TestParseTestCases_Raw(t *testing.T) {
+	cases, err := ParseTestCases(FormatRaw, "anything")
+	require.NoError(t, err)
+	assert.Nil(t, cases)
+}
@@ -3,20 +3,37 @@ package verifier
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/yarlson/ralph/internal/procenv"
 )
 
 // DefaultMaxOutputSize is the default maximum output size in bytes (1MB).
 const DefaultMaxOutputSize = 1024 * 1024
 
+// waitDelay bounds how long the children of a killed command may keep its
+// output open before the runner stops waiting for them.
+const waitDelay = 5 * time.Second
+
 // CommandRunner implements the Verifier interface by executing commands as subprocesses.
 type CommandRunner struct {
 	workDir         string
 	allowedCommands map[string]bool
 	maxOutputSize   int
+	env             map[string]string
+	restrictEnv     bool
+	exitCodes       ExitCodes
+	commandTimeout  time.Duration
+	parallelism     int
+	shell           []string
 }
 
 // NewCommandRunner creates a new CommandRunner with the specified working directory.
@@ -48,13 +65,76 @@ func (r *CommandRunner) SetMaxOutputSize(size int) {
 	r.maxOutputSize = size
 }
 
-// Verify executes the given commands sequentially and returns results for each.
-// Commands are executed in order, and execution continues even if a command fails.
+// SetEnv sets environment variables on every command, in addition to the
+// inherited environment.
+func (r *CommandRunner) SetEnv(env map[string]string) {
+	r.env = env
+}
+
+// SetRestrictEnv makes commands inherit only a base set of variables (PATH,
+// HOME and the like) instead of the whole environment. Variables set with
+// SetEnv are added on top.
+func (r *CommandRunner) SetRestrictEnv(restrict bool) {
+	r.restrictEnv = restrict
+}
+
+// SetShell runs every command through shell, e.g. ["bash", "-lc"]. A command
+// of one element is passed to the shell as a script, so it can use pipes and
+// variables; longer commands are quoted word by word. An empty shell (the
+// default) executes commands directly.
+func (r *CommandRunner) SetShell(shell []string) {
+	r.shell = shell
+}
+
+// SetCommandTimeout limits how long each command may run. A command still
+// running after timeout is killed and reported as failed, like any other
+// failing command. Zero (the default) leaves commands bounded only by the
+// context.
+func (r *CommandRunner) SetCommandTimeout(timeout time.Duration) {
+	r.commandTimeout = timeout
+}
+
+// SetParallelism sets how many commands Verify runs at once. Values below 2
+// (the default) run them one after another. Results keep the order of the
+// commands either way.
+func (r *CommandRunner) SetParallelism(n int) {
+	r.parallelism = n
+}
+
+// Fingerprint identifies the settings that can change whether a command
+// passes: the added environment, whether the environment is restricted, the
+// shell, the timeout and the exit code mappings. Runners with the same
+// fingerprint run a command the same way.
+func (r *CommandRunner) Fingerprint() string {
+	data, _ := json.Marshal(struct {
+		Env         map[string]string `json:"env"`
+		RestrictEnv bool              `json:"restrict_env"`
+		Shell       []string          `json:"shell"`
+		Timeout     time.Duration     `json:"timeout"`
+		ExitCodes   ExitCodes         `json:"exit_codes"`
+	}{r.env, r.restrictEnv, r.shell, r.commandTimeout, r.exitCodes})
+	return string(data)
+}
+
+// InDir returns a copy of the runner that runs commands in dir.
+func (r *CommandRunner) InDir(dir string) Verifier {
+	clone := *r
+	clone.workDir = dir
+	return &clone
+}
+
+// Verify executes the given commands and returns results for each, in the
+// order of commands. Commands run one after another unless a parallelism is
+// set, and execution continues even if a command fails.
 func (r *CommandRunner) Verify(ctx context.Context, commands [][]string) ([]VerificationResult, error) {
 	if ctx == nil {
 		return nil, errors.New("context cannot be nil")
 	}
 
+	if r.parallelism > 1 && len(commands) > 1 {
+		return r.verifyParallel(ctx, commands), nil
+	}
+
 	results := make([]VerificationResult, 0, len(commands))
 
 	for _, cmdArgs := range commands {
@@ -65,6 +145,23 @@ func (r *CommandRunner) Verify(ctx context.Context, commands [][]string) ([]Veri
 	return results, nil
 }
 
+// verifyParallel runs up to parallelism commands at once, storing each
+// result at its command's index.
+func (r *CommandRunner) verifyParallel(ctx context.Context, commands [][]string) []VerificationResult {
+	results := make([]VerificationResult, len(commands))
+	slots := make(chan struct{}, r.parallelism)
+	var wg sync.WaitGroup
+	for i, cmdArgs := range commands {
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			results[i] = r.runCommand(ctx, cmdArgs)
+		})
+	}
+	wg.Wait()
+	return results
+}
+
 // VerifyTask is a convenience method that delegates to Verify.
 func (r *CommandRunner) VerifyTask(ctx context.Context, commands [][]string) ([]VerificationResult, error) {
 	return r.Verify(ctx, commands)
@@ -81,6 +178,7 @@ func (r *CommandRunner) runCommand(ctx context.Context, cmdArgs []string) Verifi
 			Command:  cmdArgs,
 			Output:   "error: empty command",
 			Duration: time.Since(start),
+			Error:    "empty command",
 		}
 	}
 
@@ -94,18 +192,38 @@ func (r *CommandRunner) runCommand(ctx context.Context, cmdArgs []string) Verifi
 				Command:  cmdArgs,
 				Output:   fmt.Sprintf("error: command %q is not allowed", baseName),
 				Duration: time.Since(start),
+				Error:    fmt.Sprintf("command %q is not allowed", baseName),
 			}
 		}
 	}
 
-	// Create command with context
-	cmd := exec.CommandContext(ctx, baseName, cmdArgs[1:]...)
+	// Create command with context, limited to the command timeout
+	cmdCtx := ctx
+	if r.commandTimeout > 0 {
+		var cancel context.CancelFunc
+		cmdCtx, cancel = context.WithTimeout(ctx, r.commandTimeout)
+		defer cancel()
+	}
+	argv := cmdArgs
+	if len(r.shell) > 0 {
+		argv = append(slices.Clone(r.shell), shellScript(cmdArgs))
+	}
+	cmd := exec.CommandContext(cmdCtx, argv[0], argv[1:]...)
+	cmd.WaitDelay = waitDelay
 
 	// Set working directory if specified
 	if r.workDir != "" {
 		cmd.Dir = r.workDir
 	}
 
+	// Add configured environment variables
+	switch {
+	case r.restrictEnv:
+		cmd.Env = procenv.Restricted(r.env)
+	case len(r.env) > 0:
+		cmd.Env = procenv.Inherited(r.env)
+	}
+
 	// Capture combined stdout and stderr
 	var output bytes.Buffer
 	cmd.Stdout = &output
@@ -118,15 +236,58 @@ func (r *CommandRunner) runCommand(ctx context.Context, cmdArgs []string) Verifi
 	// Get output, potentially truncated
 	outputStr := r.truncateOutput(output.String())
 
-	// Determine if command passed (exit code 0)
-	passed := err == nil
-
-	return VerificationResult{
-		Passed:   passed,
+	result := VerificationResult{
+		Passed:   err == nil, // exit code 0
 		Command:  cmdArgs,
 		Output:   outputStr,
 		Duration: duration,
 	}
+
+	// A command that never exited normally is an error rather than a failure
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case ctx.Err() == nil && cmdCtx.Err() != nil:
+		result.Error = fmt.Sprintf("timed out after %s", r.commandTimeout)
+		result.Output = strings.TrimRight(outputStr, "\n") + fmt.Sprintf("\n[killed: command timed out after %s]", r.commandTimeout)
+	case ctx.Err() != nil:
+		result.Error = ctx.Err().Error()
+	case !errors.As(err, &exitErr):
+		result.Error = err.Error()
+	default:
+		// Some tools use nonzero exit codes for outcomes other than failure
+		switch mapped, _ := r.exitCodes.lookup(cmdArgs, exitErr.ExitCode()); mapped {
+		case ExitCodePass:
+			result.Passed = true
+		case ExitCodeWarn:
+			result.Passed = true
+			result.Warning = true
+		case ExitCodeSkip:
+			result.Passed = true
+			result.Skipped = true
+		}
+	}
+
+	return result
+}
+
+// shellSafe matches words that need no quoting in a POSIX shell.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellScript turns a command into the script a shell runs for it.
+func shellScript(cmdArgs []string) string {
+	if len(cmdArgs) == 1 {
+		return cmdArgs[0]
+	}
+	words := make([]string, len(cmdArgs))
+	for i, arg := range cmdArgs {
+		if shellSafe.MatchString(arg) {
+			words[i] = arg
+		} else {
+			words[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(words, " ")
 }
 
 // truncateOutput truncates the output if it exceeds maxOutputSize.
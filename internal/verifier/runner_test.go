@@ -167,6 +167,75 @@ func TestCommandRunner_Timeout(t *testing.T) {
 		assert.False(t, results[0].Passed)
 		assert.Less(t, elapsed, 5*time.Second, "should have been cancelled quickly")
 	})
+
+	t.Run("kills a command that exceeds the command timeout", func(t *testing.T) {
+		runner := NewCommandRunner("")
+		runner.SetCommandTimeout(100 * time.Millisecond)
+
+		start := time.Now()
+		results, err := runner.Verify(context.Background(), [][]string{
+			{"sh", "-c", "echo started; exec sleep 999"},
+			{"echo", "next"},
+		})
+		elapsed := time.Since(start)
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.False(t, results[0].Passed)
+		assert.Equal(t, "timed out after 100ms", results[0].Error)
+		assert.Equal(t, "started\n[killed: command timed out after 100ms]", results[0].Output)
+		assert.True(t, results[1].Passed, "later commands still run")
+		assert.Less(t, elapsed, 8*time.Second)
+	})
+
+	t.Run("InDir keeps the command timeout", func(t *testing.T) {
+		runner := NewCommandRunner("")
+		runner.SetCommandTimeout(50 * time.Millisecond)
+
+		results, err := runner.InDir(t.TempDir()).Verify(context.Background(), [][]string{{"sleep", "999"}})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.False(t, results[0].Passed)
+		assert.Contains(t, results[0].Output, "timed out after 50ms")
+	})
+}
+
+func TestCommandRunner_Parallelism(t *testing.T) {
+	t.Run("runs commands at once in command order", func(t *testing.T) {
+		runner := NewCommandRunner("")
+		runner.SetParallelism(3)
+
+		start := time.Now()
+		results, err := runner.Verify(context.Background(), [][]string{
+			{"sh", "-c", "sleep 1; echo lint"},
+			{"sh", "-c", "sleep 1; echo typecheck; exit 1"},
+			{"sh", "-c", "echo test"},
+		})
+		elapsed := time.Since(start)
+
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, "lint\n", results[0].Output)
+		assert.Equal(t, "typecheck\n", results[1].Output)
+		assert.False(t, results[1].Passed)
+		assert.Equal(t, "test\n", results[2].Output)
+		assert.Less(t, elapsed, 1900*time.Millisecond, "commands overlap")
+	})
+
+	t.Run("limits how many run at once", func(t *testing.T) {
+		runner := NewCommandRunner("")
+		runner.SetParallelism(2)
+
+		start := time.Now()
+		results, err := runner.Verify(context.Background(), [][]string{
+			{"sleep", "0.5"}, {"sleep", "0.5"}, {"sleep", "0.5"},
+		})
+		elapsed := time.Since(start)
+
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.GreaterOrEqual(t, elapsed, time.Second)
+	})
 }
 
 func TestCommandRunner_WorkingDirectory(t *testing.T) {
@@ -184,6 +253,113 @@ func TestCommandRunner_WorkingDirectory(t *testing.T) {
 	})
 }
 
+func TestCommandRunner_Env(t *testing.T) {
+	t.Run("sets configured variables", func(t *testing.T) {
+		runner := NewCommandRunner(t.TempDir())
+		runner.SetEnv(map[string]string{"SERVICE_TOKEN": "tok123"})
+
+		results, err := runner.Verify(context.Background(), [][]string{{"sh", "-c", "echo $SERVICE_TOKEN"}})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		assert.True(t, results[0].Passed)
+		assert.Equal(t, "tok123\n", results[0].Output)
+	})
+
+	t.Run("restricted environment drops unlisted variables", func(t *testing.T) {
+		t.Setenv("UNLISTED_SECRET", "nope")
+		runner := NewCommandRunner(t.TempDir())
+		runner.SetEnv(map[string]string{"SERVICE_TOKEN": "tok123"})
+		runner.SetRestrictEnv(true)
+
+		results, err := runner.Verify(context.Background(), [][]string{{"sh", "-c", "echo $SERVICE_TOKEN$UNLISTED_SECRET"}})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		assert.True(t, results[0].Passed)
+		assert.Equal(t, "tok123\n", results[0].Output)
+	})
+}
+
+func TestCommandRunner_Shell(t *testing.T) {
+	t.Run("runs commands through the shell", func(t *testing.T) {
+		runner := NewCommandRunner(t.TempDir())
+		runner.SetShell([]string{"sh", "-c"})
+		runner.SetEnv(map[string]string{"GREETING": "hello"})
+
+		results, err := runner.Verify(context.Background(), [][]string{
+			{"echo $GREETING | tr a-z A-Z"},
+			{"printf", "%s|", "it's", "two words", "$GREETING"},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		assert.Equal(t, "HELLO\n", results[0].Output, "a one-element command is a script")
+		assert.Equal(t, "it's|two words|$GREETING|", results[1].Output, "words are quoted")
+		assert.Equal(t, []string{"printf", "%s|", "it's", "two words", "$GREETING"}, results[1].Command)
+	})
+
+	t.Run("allowlist applies to the command, not the shell", func(t *testing.T) {
+		runner := NewCommandRunner(t.TempDir())
+		runner.SetShell([]string{"sh", "-c"})
+		runner.SetAllowedCommands([]string{"echo"})
+
+		results, err := runner.Verify(context.Background(), [][]string{
+			{"echo", "ok"},
+			{"sh", "-c", "true"},
+			{"echo ok; true"},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+
+		assert.True(t, results[0].Passed)
+		assert.False(t, results[1].Passed)
+		assert.False(t, results[2].Passed, "scripts cannot be checked against the allowlist")
+	})
+}
+
+func TestCommandRunner_Fingerprint(t *testing.T) {
+	base := NewCommandRunner(t.TempDir())
+	base.SetEnv(map[string]string{"A": "1", "B": "2"})
+	same := NewCommandRunner(t.TempDir())
+	same.SetEnv(map[string]string{"B": "2", "A": "1"})
+	same.SetParallelism(4)
+	assert.Equal(t, base.Fingerprint(), same.Fingerprint(), "the directory and parallelism do not change results")
+	assert.Equal(t, base.Fingerprint(), base.InDir(t.TempDir()).(*CommandRunner).Fingerprint())
+
+	changes := map[string]func(r *CommandRunner){
+		"env":          func(r *CommandRunner) { r.SetEnv(map[string]string{"A": "1", "B": "3"}) },
+		"restrict env": func(r *CommandRunner) { r.SetRestrictEnv(true) },
+		"shell":        func(r *CommandRunner) { r.SetShell([]string{"bash", "-lc"}) },
+		"timeout":      func(r *CommandRunner) { r.SetCommandTimeout(time.Minute) },
+		"exit codes":   func(r *CommandRunner) { r.SetExitCodes(ExitCodes{"lint": {2: ExitCodePass}}) },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			r := NewCommandRunner(t.TempDir())
+			r.SetEnv(map[string]string{"A": "1", "B": "2"})
+			change(r)
+			assert.NotEqual(t, base.Fingerprint(), r.Fingerprint())
+		})
+	}
+}
+
+func TestShellScript(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"go test ./... | tee out.txt"}, "go test ./... | tee out.txt"},
+		{[]string{"go", "test", "./..."}, "go test ./..."},
+		{[]string{"grep", "-q", "a b", "f.txt"}, "grep -q 'a b' f.txt"},
+		{[]string{"echo", "it's"}, `echo 'it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, shellScript(tt.args))
+	}
+}
+
 func TestCommandRunner_Allowlist(t *testing.T) {
 	t.Run("allows commands when no allowlist set", func(t *testing.T) {
 		runner := NewCommandRunner("")
@@ -285,6 +461,18 @@ func TestCommandRunner_InvalidCommand(t *testing.T) {
 		require.Len(t, results, 1)
 
 		assert.False(t, results[0].Passed)
+		assert.Contains(t, results[0].Error, "executable file not found")
+	})
+
+	t.Run("failing command is not an error", func(t *testing.T) {
+		runner := NewCommandRunner("")
+
+		results, err := runner.Verify(context.Background(), [][]string{{"false"}})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		assert.False(t, results[0].Passed)
+		assert.Empty(t, results[0].Error)
 	})
 
 	t.Run("handles empty command", func(t *testing.T) {
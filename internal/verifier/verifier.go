@@ -19,6 +19,16 @@ type VerificationResult struct {
 
 	// Duration is how long the command took to execute.
 	Duration time.Duration `json:"duration"`
+
+	// Error describes why the command could not run to completion (empty, not
+	// allowed, not found, cancelled). Empty when it exited, whether or not it passed.
+	Error string `json:"error,omitempty"`
+
+	// Warning indicates the command passed with an exit code mapped to warn.
+	Warning bool `json:"warning,omitempty"`
+
+	// Skipped indicates the command passed with an exit code mapped to skip.
+	Skipped bool `json:"skipped,omitempty"`
 }
 
 // Verifier defines the interface for running verification commands.